@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/mailer"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	"github.com/abdullahnettoor/tastybites/internal/printing"
+)
+
+// configCheck is the outcome of validating one part of the configuration.
+type configCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+func configCommand(args []string) error {
+	if len(args) == 0 {
+		return usagef("config: want validate or print")
+	}
+	switch args[0] {
+	case "validate":
+		return validateConfig(args[1:])
+	case "print":
+		return printConfig(args[1:])
+	default:
+		return usagef("config: unknown command %q", args[0])
+	}
+}
+
+// printConfig lists the settings as loaded, so the layers can be checked.
+func printConfig(args []string) error {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", false, "hide the values of secrets")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Settings(*redacted)
+	rows := make([][]string, len(settings))
+	for i, s := range settings {
+		rows[i] = []string{s.Key, s.Value, s.Source}
+	}
+	return out.table(settings, []string{"KEY", "VALUE", "SOURCE"}, rows)
+}
+
+func validateConfig(args []string) error {
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Build every component that checks its settings, without connecting to
+	// the database or starting the server.
+	checks := []struct {
+		name  string
+		check func(cfg *config.Config) error
+	}{
+		{"pricing", func(cfg *config.Config) error {
+			_, err := pricing.NewSettings(&cfg.PricingConfig)
+			return err
+		}},
+		{"payments", func(cfg *config.Config) error {
+			_, err := payments.NewGateway(&cfg.PaymentsConfig)
+			return err
+		}},
+		{"mail", func(cfg *config.Config) error {
+			_, err := mailer.NewMailer(&cfg.MailConfig)
+			return err
+		}},
+		{"printing", func(cfg *config.Config) error {
+			router, err := printing.NewRouter(&cfg.PrintingConfig)
+			if err != nil {
+				return err
+			}
+			spooler, err := printing.NewSpooler(router, &cfg.PrintingConfig)
+			if err != nil {
+				return err
+			}
+			spooler.Close()
+			return nil
+		}},
+		{"events", func(cfg *config.Config) error {
+			bus, err := events.NewBus(&cfg.EventsConfig)
+			if err != nil {
+				return err
+			}
+			bus.Close()
+			return nil
+		}},
+	}
+
+	results := make([]configCheck, 0, len(checks))
+	rows := make([][]string, 0, len(checks))
+	failed := 0
+	for _, c := range checks {
+		result := configCheck{Name: c.name, OK: true}
+		status := "ok"
+		if err := c.check(cfg); err != nil {
+			result.OK, result.Error = false, err.Error()
+			status = "error: " + err.Error()
+			failed++
+		}
+		results = append(results, result)
+		rows = append(rows, []string{c.name, status})
+	}
+	if err := out.table(results, []string{"SECTION", "STATUS"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %d sections have errors", errInvalidConfig, failed)
+	}
+	return nil
+}
@@ -1,44 +1,188 @@
+// Command tastybites runs the API server and the operational tasks around it:
+// migrations, seeding, user and table maintenance and exports.
 package main
 
 import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
-	"github.com/abdullahnettoor/tastybites/internal/api"
 	"github.com/abdullahnettoor/tastybites/internal/config"
-	"github.com/abdullahnettoor/tastybites/internal/repo"
-	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/models"
 )
 
+const usage = `Usage: tastybites [-output table|json] [-config FILE] [-set KEY=VALUE]... <command> [arguments]
+
+Commands:
+  serve                               start the API server (the default)
+  migrate up                          apply every pending migration
+  migrate down [N|all]                roll back the last N migrations (default 1)
+  migrate status                      list the migrations and whether they are applied
+  migrate goto V                      migrate up or down to version V
+  migrate force V                     record version V as the last applied, running no SQL
+  seed                                load the sample data into a database without users
+  user create -name N -email E [-role R] [-password P | -password-stdin]
+  user reset-password -email E [-password P | -password-stdin]
+  table reset ID                      complete the open orders of a table and free it
+  menu export [-format csv|json]      print the whole menu, or write it as an import file
+  menu import [-dry-run] [-archive-missing] [-format csv|json] FILE
+                                      create, update and archive menu items by SKU
+  orders export -from DATE [-to DATE] print the orders placed in a date range
+  config validate                     check the configuration without starting anything
+  config print [-redacted]            list every setting with its value and where it came from
+
+Settings come from, each overriding the ones before: the defaults, the YAML
+or TOML file of -config or TASTYBITES_CONFIG, the TASTYBITES_* variables and
+-set, e.g. -set server.port=9090. NAME_FILE reads variable NAME from a file.
+
+Exit codes:
+  0  success
+  1  the command failed
+  2  invalid command line
+  3  the user, table or order does not exist
+  4  invalid input or configuration
+`
+
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitInvalid  = 4
+)
+
+// errInvalidConfig marks configuration errors, so scripts can tell them from
+// failures of the command itself.
+var errInvalidConfig = errors.New("invalid configuration")
+
+// usageError is a mistake on the command line.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// out is where commands print their results, in the format of -output.
+var out output
+
+// configOptions holds -config and -set, for loadConfig.
+var configOptions = config.Options{Overrides: make(map[string]string)}
+
+// overrideFlag collects repeated -set KEY=VALUE flags.
+type overrideFlag map[string]string
+
+func (f overrideFlag) String() string { return "" }
+
+func (f overrideFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", s)
+	}
+	f[key] = value
+	return nil
+}
+
 func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	format := flag.String("output", "table", "output format: table or json")
+	flag.StringVar(&configOptions.File, "config", "", "YAML or TOML config file")
+	flag.Var(overrideFlag(configOptions.Overrides), "set", "override a setting, KEY=VALUE; may be repeated")
+	flag.Parse()
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid output format %q, want table or json\n", *format)
+		os.Exit(exitUsage)
+	}
+	out = output{format: *format, w: os.Stdout}
 
-	// Load the configuration
-	config, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	os.Exit(exitCode(run(flag.Args())))
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return serve(nil)
 	}
-	// Set up the database connection
-	repository, err := repo.NewRepository(&config.DBConfig)
-	if err != nil {
-		log.Fatalf("Failed to initialize repository: %v", err)
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "serve":
+		return serve(args)
+	case "migrate":
+		return migrateCommand(args)
+	case "seed":
+		return seedCommand(args)
+	case "user":
+		return userCommand(args)
+	case "table":
+		return tableCommand(args)
+	case "menu":
+		return menuCommand(args)
+	case "orders":
+		return ordersCommand(args)
+	case "config":
+		return configCommand(args)
+	case "help":
+		flag.Usage()
+		return nil
+	default:
+		return usagef("unknown command %q", command)
 	}
+}
 
-	// Set up the use cases
-	userUsecase := usecases.NewUserUsecase(repository)
-	orderUsecase := usecases.NewOrderUsecase(repository)
-	menuUsecase := usecases.NewMenuUsecase(repository)
-	tableUsecase := usecases.NewTableUsecase(repository)
+// exitCode reports err on stderr and picks the exit code for it.
+func exitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
 
-	// Initialize the application
-	app, err := api.NewApp(config, repository)
-	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		flag.Usage()
+		return exitUsage
+	}
+
+	log.Println("Error:", err)
+	switch {
+	case errors.Is(err, models.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return exitNotFound
+	case errors.Is(err, models.ErrInvalidInput), errors.Is(err, errInvalidConfig):
+		return exitInvalid
+	default:
+		return exitFailure
 	}
+}
 
-	// Initialize the routes
-	app.InitializeRoutes(userUsecase, orderUsecase, menuUsecase, tableUsecase)
+// parseFlags parses the flags of a subcommand, which takes exactly nargs
+// positional arguments, or any number when nargs is negative.
+func parseFlags(flags *flag.FlagSet, args []string, nargs int) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usagef("%s: %v", flags.Name(), err)
+	}
+	if nargs >= 0 && flags.NArg() != nargs {
+		return usagef("%s: want %d arguments, got %d", flags.Name(), nargs, flags.NArg())
+	}
+	return nil
+}
 
-	if err := app.Start(); err != nil {
-		log.Fatalf("Failed to start the application: %v", err)
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(configOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidConfig, err)
 	}
-	log.Printf("Server started on %s:%d", config.ServerConfig.Host, config.ServerConfig.Port)
+	// Promotion hours, receipts and fiscal years follow the restaurant's clock
+	time.Local = cfg.RestaurantConfig.Location()
+	return cfg, nil
 }
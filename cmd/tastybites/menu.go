@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/menufile"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+)
+
+func menuCommand(args []string) error {
+	if len(args) == 0 {
+		return usagef("menu: want export or import")
+	}
+	switch args[0] {
+	case "export":
+		return exportMenu(args[1:])
+	case "import":
+		return importMenu(args[1:])
+	default:
+		return usagef("menu: unknown command %q", args[0])
+	}
+}
+
+// openMenuUsecase connects to the configured database and blob storage.
+// The caller closes the repository when done.
+func openMenuUsecase() (usecases.MenuIUsecase, interfaces.Repository, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	repository, err := openRepository(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	blobStore, err := storage.NewBlobStore(&cfg.StorageConfig)
+	if err != nil {
+		repository.Close()
+		return nil, nil, fmt.Errorf("failed to initialize blob storage: %w", err)
+	}
+	return usecases.NewMenuUsecase(repository, blobStore), repository, nil
+}
+
+func exportMenu(args []string) error {
+	flags := flag.NewFlagSet("menu export", flag.ContinueOnError)
+	format := flags.String("format", "", "write an import file, csv or json, instead of the listing")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	var fileFormat menufile.Format
+	if *format != "" {
+		f, err := menufile.ParseFormat(*format)
+		if err != nil {
+			return usagef("menu export: -format must be csv or json")
+		}
+		fileFormat = f
+	}
+
+	menuUsecase, repository, err := openMenuUsecase()
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	rows, err := menuUsecase.ExportMenu(context.Background())
+	if err != nil {
+		return err
+	}
+	if fileFormat != "" {
+		return menufile.Write(out.w, fileFormat, rows)
+	}
+
+	listing := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		stock := "-"
+		if row.Stock != nil {
+			stock = strconv.Itoa(*row.Stock)
+		}
+		available := "yes"
+		if !row.Available {
+			available = "archived"
+		}
+		listing = append(listing, []string{row.SKU, row.Name, row.Category, money(row.Price), stock, available})
+	}
+	return out.table(rows, []string{"SKU", "NAME", "CATEGORY", "PRICE", "STOCK", "AVAILABLE"}, listing)
+}
+
+func importMenu(args []string) error {
+	flags := flag.NewFlagSet("menu import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or json; taken from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "only print what would change")
+	archiveMissing := flags.Bool("archive-missing", false, "archive the items the file leaves out")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+	path := flags.Arg(0)
+	raw := *format
+	if raw == "" && path != "-" {
+		raw = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	fileFormat, err := menufile.ParseFormat(raw)
+	if err != nil {
+		return usagef("menu import: cannot tell the format of %s, use -format csv or json", path)
+	}
+
+	var file io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open menu file: %w", err)
+		}
+		defer f.Close()
+		file = f
+	}
+	in := models.MenuImport{DryRun: *dryRun, ArchiveMissing: *archiveMissing}
+	in.Rows, in.Errors, err = menufile.Read(file, fileFormat)
+	if err != nil {
+		return err
+	}
+
+	menuUsecase, repository, err := openMenuUsecase()
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	result, err := menuUsecase.ImportMenu(context.Background(), in)
+	if err != nil {
+		return err
+	}
+	if err := printImportResult(result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%w: the menu file has %d errors, nothing was imported", models.ErrInvalidInput, len(result.Errors))
+	}
+	return nil
+}
+
+// printImportResult lists the changes, then the errors, and sums them up.
+func printImportResult(result models.MenuImportResult) error {
+	rows := make([][]string, 0, len(result.Changes)+len(result.Errors))
+	for _, change := range result.Changes {
+		rows = append(rows, []string{importRow(change.Row), change.SKU, string(change.Action), strings.Join(change.Fields, ", ")})
+	}
+	for _, e := range result.Errors {
+		detail := e.Message
+		if e.Field != "" {
+			detail = e.Field + ": " + detail
+		}
+		rows = append(rows, []string{importRow(e.Row), e.SKU, "error", detail})
+	}
+	if err := out.table(result, []string{"ROW", "SKU", "ACTION", "DETAIL"}, rows); err != nil {
+		return err
+	}
+	if out.format == "json" {
+		return nil
+	}
+
+	var err error
+	switch {
+	case len(result.Errors) > 0:
+		_, err = fmt.Fprintln(out.w, "Nothing was imported")
+	case result.DryRun:
+		_, err = fmt.Fprintf(out.w, "Dry run: would create %d, update %d and archive %d items, %d unchanged\n",
+			result.Created, result.Updated, result.Archived, result.Unchanged)
+	default:
+		_, err = fmt.Fprintf(out.w, "Created %d, updated %d and archived %d items, %d unchanged\n",
+			result.Created, result.Updated, result.Archived, result.Unchanged)
+	}
+	return err
+}
+
+// importRow shows the row number, or a dash for items the file left out.
+func importRow(row int) string {
+	if row == 0 {
+		return "-"
+	}
+	return strconv.Itoa(row)
+}
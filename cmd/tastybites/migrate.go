@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/db"
+	"github.com/abdullahnettoor/tastybites/internal/migrate"
+	pgrepo "github.com/abdullahnettoor/tastybites/internal/repo/postgres"
+)
+
+// openMigrator connects to the configured Postgres database. The other
+// drivers bring their schema and sample data along, so there is nothing to
+// migrate for them. closeDB disconnects.
+func openMigrator() (migrator *migrate.Migrator, closeDB func(), err error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.DBConfig.Driver != "postgres" {
+		return nil, nil, fmt.Errorf("%w: migrations are for the postgres driver, the %s driver sets up its own database",
+			errInvalidConfig, cfg.DBConfig.Driver)
+	}
+
+	pool, conn, err := pgrepo.Open(&cfg.DBConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	closeDB = func() {
+		conn.Close()
+		pool.Close()
+	}
+	migrator, err = migrate.New(conn, db.Migrations)
+	if err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrator, closeDB, nil
+}
+
+func migrateCommand(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	if err := parseFlags(flags, args, -1); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 || flags.NArg() > 2 {
+		return usagef("migrate: want up, down [N|all], status, goto V or force V")
+	}
+	command, arg := flags.Arg(0), flags.Arg(1)
+
+	// Check the arguments before connecting
+	version, steps := 0, 1
+	switch command {
+	case "up", "status":
+		if arg != "" {
+			return usagef("migrate %s takes no arguments", command)
+		}
+	case "down":
+		if arg == "all" {
+			steps = 0
+		} else if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				return usagef("migrate down: invalid number of migrations %q", arg)
+			}
+			steps = n
+		}
+	case "goto", "force":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return usagef("migrate %s: want a migration version, got %q", command, arg)
+		}
+		version = n
+	default:
+		return usagef("migrate: unknown command %q", command)
+	}
+
+	migrator, closeDB, err := openMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		return out.result(map[string]int{"applied": applied}, "Applied %d migrations", applied)
+
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+		return out.result(map[string]int{"reverted": reverted}, "Rolled back %d migrations", reverted)
+
+	case "goto":
+		changed, err := migrator.Goto(ctx, version)
+		if err != nil {
+			return err
+		}
+		return out.result(map[string]int{"version": version, "changed": changed},
+			"Migrated to version %03d, %d migrations changed", version, changed)
+
+	case "force":
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		return out.result(map[string]int{"version": version}, "Recorded version %03d as the last applied migration", version)
+
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(statuses))
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Missing {
+				appliedAt += " (not in this binary)"
+			}
+			rows = append(rows, []string{fmt.Sprintf("%03d", s.Version), s.Name, appliedAt})
+		}
+		return out.table(statuses, []string{"VERSION", "NAME", "APPLIED AT"}, rows)
+	}
+}
+
+func seedCommand(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	migrator, closeDB, err := openMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	seeded, err := migrator.Seed(context.Background(), db.Seed)
+	if err != nil {
+		return err
+	}
+	if !seeded {
+		return out.result(map[string]bool{"seeded": false}, "The database already has users, the sample data was not loaded")
+	}
+	return out.result(map[string]bool{"seeded": true}, "Loaded the sample data")
+}
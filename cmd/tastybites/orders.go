@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+)
+
+const dateLayout = "2006-01-02"
+
+func ordersCommand(args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return usagef("orders: want export -from DATE [-to DATE]")
+	}
+
+	flags := flag.NewFlagSet("orders export", flag.ContinueOnError)
+	fromStr := flags.String("from", "", "first day, YYYY-MM-DD in UTC")
+	toStr := flags.String("to", "", "last day, YYYY-MM-DD in UTC; defaults to today")
+	if err := parseFlags(flags, args[1:], 0); err != nil {
+		return err
+	}
+	if *fromStr == "" {
+		return usagef("orders export: -from is required")
+	}
+	from, err := time.Parse(dateLayout, *fromStr)
+	if err != nil {
+		return usagef("orders export: invalid -from date %q, want YYYY-MM-DD", *fromStr)
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if *toStr != "" {
+		if to, err = time.Parse(dateLayout, *toStr); err != nil {
+			return usagef("orders export: invalid -to date %q, want YYYY-MM-DD", *toStr)
+		}
+	}
+	if to.Before(from) {
+		return usagef("orders export: -to is before -from")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repository, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	userUsecase := usecases.NewUserUsecase(repository)
+
+	// -to names the last day, so the range ends at the start of the next one
+	orders, err := userUsecase.GetOrdersBetween(context.Background(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	rows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		rows = append(rows, []string{
+			strconv.Itoa(order.ID), order.CreatedAt, strconv.Itoa(order.UserID), strconv.Itoa(order.TableID),
+			string(order.Status), strconv.Itoa(len(order.Items)), money(order.TotalPrice),
+		})
+	}
+	return out.table(orders, []string{"ID", "PLACED AT", "USER", "TABLE", "STATUS", "LINES", "TOTAL"}, rows)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output prints command results as aligned tables for people or as JSON for
+// scripts.
+type output struct {
+	format string // "table" or "json"
+	w      io.Writer
+}
+
+// table prints v as JSON, or the rows under the header as a table.
+func (o output) table(v any, header []string, rows [][]string) error {
+	if o.format == "json" {
+		return o.json(v)
+	}
+	w := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// result prints v as JSON, or the message as a line of text.
+func (o output) result(v any, format string, args ...any) error {
+	if o.format == "json" {
+		return o.json(v)
+	}
+	_, err := fmt.Fprintf(o.w, format+"\n", args...)
+	return err
+}
+
+func (o output) json(v any) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// money formats an amount in cents.
+func money(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
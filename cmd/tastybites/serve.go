@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/abdullahnettoor/tastybites/internal/api"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/health"
+	"github.com/abdullahnettoor/tastybites/internal/lifecycle"
+	"github.com/abdullahnettoor/tastybites/internal/mailer"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	"github.com/abdullahnettoor/tastybites/internal/printing"
+	"github.com/abdullahnettoor/tastybites/internal/repo"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/webhooks"
+)
+
+// serve runs the API server with every background worker until SIGINT or
+// SIGTERM, then stops them in the reverse order they were started.
+func serve(args []string) (err error) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	// Load the configuration
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// Everything started is stopped on the way out, also when a later part
+	// fails to start
+	components := lifecycle.NewManager()
+	defer func() {
+		if err != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), config.ServerConfig.ShutdownTimeout)
+			defer cancel()
+			components.Shutdown(ctx)
+		}
+	}()
+
+	// Set up the database connection
+	repository, err := repo.NewRepository(&config.DBConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	components.Add("database", func(context.Context) error { return repository.Close() })
+	// Pool statistics for GET /admin/metrics
+	if pool, ok := repository.(interfaces.PoolStatsReporter); ok {
+		expvar.Publish("dbPool", expvar.Func(func() any { return pool.PoolStats() }))
+	}
+
+	// Set up blob storage for uploaded images
+	blobStore, err := storage.NewBlobStore(&config.StorageConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize blob storage: %w", err)
+	}
+
+	// Tax, service charge and rounding rules for order totals
+	pricingSettings, err := pricing.NewSettings(&config.PricingConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load pricing settings: %w", err)
+	}
+
+	// Set up the payment gateway
+	paymentGateway, err := payments.NewGateway(&config.PaymentsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize payment gateway: %w", err)
+	}
+
+	// Set up the mailer for receipts
+	receiptMailer, err := mailer.NewMailer(&config.MailConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mailer: %w", err)
+	}
+	restaurant := models.RestaurantDetails{
+		Name:    config.RestaurantConfig.Name,
+		Address: config.RestaurantConfig.Address,
+		Phone:   config.RestaurantConfig.Phone,
+		Email:   config.RestaurantConfig.Email,
+		TaxID:   config.RestaurantConfig.TaxID,
+	}
+
+	// Set up the printers and the print spooler
+	printRouter, err := printing.NewRouter(&config.PrintingConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize printers: %w", err)
+	}
+	spooler, err := printing.NewSpooler(printRouter, &config.PrintingConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize print spooler: %w", err)
+	}
+	components.AddCloser("print spooler", spooler.Close)
+
+	// Set up the event bus for real-time updates
+	eventBus, err := events.NewBus(&config.EventsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize event bus: %w", err)
+	}
+	components.AddCloser("event bus", eventBus.Close)
+
+	// Set up the dispatcher that sends outbox events to webhooks
+	dispatcher, err := webhooks.NewDispatcher(repository, &config.WebhooksConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize webhook dispatcher: %w", err)
+	}
+	components.AddCloser("webhook dispatcher", dispatcher.Close)
+
+	// Checks behind /healthz and /readyz. A stopped worker needs a restart;
+	// the database comes back by itself, so it only fails readiness
+	healthChecks := health.NewRegistry(config.ServerConfig.HealthCheckTimeout)
+	healthChecks.AddLiveness("print spooler", spooler.Check)
+	healthChecks.AddLiveness("event bus", eventBus.Check)
+	healthChecks.AddLiveness("webhook dispatcher", dispatcher.Check)
+	healthChecks.AddReadiness("database", repository.Ping)
+	healthChecks.AddReadiness("migrations", repository.CheckMigrations)
+
+	// Set up the use cases
+	userUsecase := usecases.NewUserUsecase(repository)
+	receiptUsecase := usecases.NewReceiptUsecase(repository, restaurant, config.RestaurantConfig.FiscalYearStartMonth, config.PaymentsConfig.Currency, receiptMailer)
+	printUsecase := usecases.NewPrintUsecase(repository, spooler, receiptUsecase)
+	kitchenUsecase := usecases.NewKitchenUsecase(repository, printUsecase, eventBus)
+	orderUsecase := usecases.NewOrderUsecase(repository, pricingSettings, kitchenUsecase, eventBus)
+	menuUsecase := usecases.NewMenuUsecase(repository, blobStore)
+	tableUsecase := usecases.NewTableUsecase(repository, eventBus)
+	bundleUsecase := usecases.NewBundleUsecase(repository)
+	promotionUsecase := usecases.NewPromotionUsecase(repository)
+	taxUsecase := usecases.NewTaxUsecase(repository)
+	paymentUsecase := usecases.NewPaymentUsecase(repository, paymentGateway, config.PaymentsConfig.Currency, eventBus)
+	splitUsecase := usecases.NewSplitUsecase(repository, pricingSettings)
+	refundUsecase := usecases.NewRefundUsecase(repository, paymentGateway, pricingSettings, config.PaymentsConfig.RefundApprovalThreshold, eventBus)
+	webhookUsecase := usecases.NewWebhookUsecase(repository)
+
+	// Initialize the application
+	app, err := api.NewApp(config, repository)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+
+	// Initialize the routes
+	app.InitializeRoutes(userUsecase, orderUsecase, menuUsecase, tableUsecase, bundleUsecase, promotionUsecase, taxUsecase, paymentUsecase, splitUsecase, refundUsecase, receiptUsecase, printUsecase, kitchenUsecase, webhookUsecase, eventBus, healthChecks)
+
+	// Event streams only end when the bus closes, so close it as soon as
+	// the server starts draining
+	app.Server.RegisterOnShutdown(eventBus.Close)
+
+	listener, err := app.Listen()
+	if err != nil {
+		return fmt.Errorf("failed to start the application: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(listener) }()
+	components.Add("HTTP server", app.Shutdown)
+	log.Printf("Server started on %s", listener.Addr())
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	// A second signal ends the process right away
+	stop()
+	healthChecks.Drain()
+
+	log.Printf("Shutting down, waiting up to %s for work in flight", config.ServerConfig.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ServerConfig.ShutdownTimeout)
+	defer cancel()
+	if err := components.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down cleanly: %w", err)
+	}
+	log.Println("Server stopped")
+	return nil
+}
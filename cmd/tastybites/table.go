@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+)
+
+func tableCommand(args []string) error {
+	if len(args) == 0 || args[0] != "reset" {
+		return usagef("table: want reset ID")
+	}
+
+	flags := flag.NewFlagSet("table reset", flag.ContinueOnError)
+	if err := parseFlags(flags, args[1:], 1); err != nil {
+		return err
+	}
+	tableID, err := strconv.Atoi(flags.Arg(0))
+	if err != nil || tableID < 1 {
+		return usagef("table reset: invalid table ID %q", flags.Arg(0))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repository, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	// Events only reach subscribers of this process; integrations still
+	// hear about the reset through the outbox.
+	eventBus, err := events.NewBus(&cfg.EventsConfig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidConfig, err)
+	}
+	defer eventBus.Close()
+	tableUsecase := usecases.NewTableUsecase(repository, eventBus)
+
+	ctx := context.Background()
+	if err := tableUsecase.ResetTableStatus(ctx, tableID); err != nil {
+		return err
+	}
+	table, err := repository.GetTableById(ctx, tableID)
+	if err != nil {
+		return err
+	}
+	return out.table(table, []string{"ID", "NAME", "SEATS", "STATUS"},
+		[][]string{{strconv.Itoa(table.ID), table.Name, strconv.Itoa(table.Seats), string(table.Status)}})
+}
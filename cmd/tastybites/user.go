@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+)
+
+// openRepository connects to the configured database.
+func openRepository(cfg *config.Config) (interfaces.Repository, error) {
+	repository, err := repo.NewRepository(&cfg.DBConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	return repository, nil
+}
+
+func userCommand(args []string) error {
+	if len(args) == 0 {
+		return usagef("user: want create or reset-password")
+	}
+	switch args[0] {
+	case "create":
+		return createUser(args[1:])
+	case "reset-password":
+		return resetPassword(args[1:])
+	default:
+		return usagef("user: unknown command %q", args[0])
+	}
+}
+
+func createUser(args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	name := flags.String("name", "", "display name")
+	email := flags.String("email", "", "email address to log in with")
+	role := flags.String("role", string(models.UserRoleUser), "user, admin, manager or kitchen")
+	password := flags.String("password", "", "password; prefer -password-stdin, flags show up in ps")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		return usagef("user create: -name and -email are required")
+	}
+	switch models.UserRole(*role) {
+	case models.UserRoleUser, models.UserRoleAdmin, models.UserRoleManager, models.UserRoleKitchen:
+	default:
+		return fmt.Errorf("%w: unknown role %q", models.ErrInvalidInput, *role)
+	}
+	pass, err := readPassword(*password, *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repository, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	userUsecase := usecases.NewUserUsecase(repository)
+
+	ctx := context.Background()
+	id, err := userUsecase.CreateUser(ctx, models.User{Name: *name, Email: *email, Password: pass, Role: *role})
+	if err != nil {
+		return err
+	}
+	user, err := userUsecase.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printUser(user)
+}
+
+func resetPassword(args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the user")
+	password := flags.String("password", "", "new password; prefer -password-stdin, flags show up in ps")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *email == "" {
+		return usagef("user reset-password: -email is required")
+	}
+	pass, err := readPassword(*password, *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repository, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repository.Close()
+	userUsecase := usecases.NewUserUsecase(repository)
+
+	ctx := context.Background()
+	if err := userUsecase.ResetPassword(ctx, *email, pass); err != nil {
+		return err
+	}
+	user, err := repository.GetUserByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+	return printUser(user)
+}
+
+// readPassword takes the password from the flag or from stdin, but not both.
+func readPassword(password string, fromStdin bool) (string, error) {
+	if fromStdin {
+		if password != "" {
+			return "", usagef("use either -password or -password-stdin")
+		}
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read password from stdin: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return "", fmt.Errorf("%w: the password is empty", models.ErrInvalidInput)
+	}
+	return password, nil
+}
+
+func printUser(user models.User) error {
+	user.Password = ""
+	return out.table(user, []string{"ID", "NAME", "EMAIL", "ROLE"},
+		[][]string{{strconv.Itoa(user.ID), user.Name, user.Email, user.Role}})
+}
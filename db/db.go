@@ -0,0 +1,29 @@
+// Package db embeds the Postgres schema migrations and the sample data, so the
+// binary can migrate a database without the SQL files next to it.
+package db
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+//go:embed seed/*.sql
+var seed embed.FS
+
+// Migrations holds the versioned migrations, each a NNN_name.up.sql file and
+// the NNN_name.down.sql file that reverts it.
+var Migrations = sub(migrations, "migrations")
+
+// Seed holds the sample data scripts, applied in file name order.
+var Seed = sub(seed, "seed")
+
+func sub(fsys fs.FS, dir string) fs.FS {
+	f, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
@@ -0,0 +1,9 @@
+-- TastyBites: drop the base schema
+
+DROP TABLE IF EXISTS public.order_items;
+DROP TABLE IF EXISTS public.orders;
+DROP TABLE IF EXISTS public.tables;
+DROP TABLE IF EXISTS public.menu_items;
+DROP TABLE IF EXISTS public.users;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,13 @@
+-- TastyBites: drop dietary tags, allergen declarations and nutrition facts
+
+DROP TABLE IF EXISTS public.menu_item_dietary_tags;
+DROP TABLE IF EXISTS public.menu_item_allergens;
+
+ALTER TABLE public.menu_items
+    DROP COLUMN IF EXISTS spicy_level,
+    DROP COLUMN IF EXISTS kcal,
+    DROP COLUMN IF EXISTS protein_g,
+    DROP COLUMN IF EXISTS carbs_g,
+    DROP COLUMN IF EXISTS fat_g,
+    DROP COLUMN IF EXISTS sugar_g,
+    DROP COLUMN IF EXISTS salt_g;
@@ -0,0 +1,43 @@
+-- TastyBites: dietary tags, allergen declarations and nutrition facts
+
+-- =============================================================================
+-- MENU ITEMS: SPICY LEVEL AND NUTRITION
+-- =============================================================================
+
+ALTER TABLE public.menu_items
+    ADD COLUMN IF NOT EXISTS spicy_level SMALLINT NOT NULL DEFAULT 0 CHECK (spicy_level BETWEEN 0 AND 3),
+    ADD COLUMN IF NOT EXISTS kcal INTEGER CHECK (kcal >= 0),
+    ADD COLUMN IF NOT EXISTS protein_g NUMERIC(6,1) CHECK (protein_g >= 0),
+    ADD COLUMN IF NOT EXISTS carbs_g NUMERIC(6,1) CHECK (carbs_g >= 0),
+    ADD COLUMN IF NOT EXISTS fat_g NUMERIC(6,1) CHECK (fat_g >= 0),
+    ADD COLUMN IF NOT EXISTS sugar_g NUMERIC(6,1) CHECK (sugar_g >= 0),
+    ADD COLUMN IF NOT EXISTS salt_g NUMERIC(6,2) CHECK (salt_g >= 0);
+
+-- =============================================================================
+-- MENU ITEM ALLERGENS TABLE
+-- =============================================================================
+
+-- One row per declared allergen (EU FIC 14 major allergens)
+CREATE TABLE IF NOT EXISTS public.menu_item_allergens (
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE CASCADE,
+    allergen VARCHAR(20) NOT NULL CHECK (allergen IN (
+        'celery', 'gluten', 'crustaceans', 'eggs', 'fish', 'lupin', 'milk',
+        'molluscs', 'mustard', 'tree_nuts', 'peanuts', 'sesame', 'soybeans', 'sulphites'
+    )),
+    PRIMARY KEY (menu_item_id, allergen)
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_item_allergens_allergen ON public.menu_item_allergens(allergen);
+
+-- =============================================================================
+-- MENU ITEM DIETARY TAGS TABLE
+-- =============================================================================
+
+-- The "spicy" tag is derived from menu_items.spicy_level and is not stored here
+CREATE TABLE IF NOT EXISTS public.menu_item_dietary_tags (
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE CASCADE,
+    tag VARCHAR(20) NOT NULL CHECK (tag IN ('vegan', 'vegetarian', 'halal')),
+    PRIMARY KEY (menu_item_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_item_dietary_tags_tag ON public.menu_item_dietary_tags(tag);
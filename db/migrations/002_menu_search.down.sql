@@ -0,0 +1,6 @@
+-- TastyBites: drop full-text and fuzzy menu search
+
+DROP INDEX IF EXISTS public.idx_menu_items_name_trgm;
+ALTER TABLE public.menu_items DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed; other databases on the server may use it
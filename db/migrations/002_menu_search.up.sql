@@ -0,0 +1,20 @@
+-- TastyBites: full-text and fuzzy menu search
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- =============================================================================
+-- MENU ITEMS: SEARCH VECTOR
+-- =============================================================================
+
+-- Weighted document: name (A) > category (B) > description (C)
+ALTER TABLE public.menu_items
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_search_vector ON public.menu_items USING GIN (search_vector);
+
+-- Trigram index for typo tolerant matching on names ("margarita" -> "Margherita Pizza")
+CREATE INDEX IF NOT EXISTS idx_menu_items_name_trgm ON public.menu_items USING GIN (name gin_trgm_ops);
@@ -0,0 +1,6 @@
+-- TastyBites: drop uploaded menu item image variants
+
+ALTER TABLE public.menu_items
+    DROP COLUMN IF EXISTS image_medium_url,
+    DROP COLUMN IF EXISTS image_thumbnail_url,
+    DROP COLUMN IF EXISTS image_key;
@@ -0,0 +1,8 @@
+-- TastyBites: uploaded menu item images with resized variants
+
+-- image_url holds the large variant; the smaller variants fall back to it
+-- for items that only have a hotlinked image.
+ALTER TABLE public.menu_items
+    ADD COLUMN IF NOT EXISTS image_medium_url VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS image_thumbnail_url VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS image_key VARCHAR(255); -- blob storage prefix of uploaded variants
@@ -0,0 +1,15 @@
+-- TastyBites: drop combos, bundles and set meals
+
+DROP TABLE IF EXISTS public.order_item_components;
+
+-- Bundle lines have no menu item, so they go before menu_item_id is required again
+DELETE FROM public.order_items WHERE bundle_id IS NOT NULL;
+
+ALTER TABLE public.order_items
+    DROP CONSTRAINT IF EXISTS order_items_item_or_bundle,
+    DROP COLUMN IF EXISTS bundle_id,
+    ALTER COLUMN menu_item_id SET NOT NULL;
+
+DROP TABLE IF EXISTS public.bundle_slot_items;
+DROP TABLE IF EXISTS public.bundle_slots;
+DROP TABLE IF EXISTS public.bundles;
@@ -0,0 +1,73 @@
+-- TastyBites: combos, bundles and set meals
+
+-- =============================================================================
+-- BUNDLES TABLE
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.bundles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    pricing_mode VARCHAR(20) NOT NULL CHECK (pricing_mode IN ('fixed', 'discount')),
+    price INTEGER CHECK (price > 0), -- Price in cents, fixed pricing only
+    discount_percent INTEGER CHECK (discount_percent BETWEEN 1 AND 100),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (pricing_mode = 'fixed' AND price IS NOT NULL) OR
+        (pricing_mode = 'discount' AND discount_percent IS NOT NULL)
+    )
+);
+
+CREATE TRIGGER update_bundles_updated_at
+    BEFORE UPDATE ON public.bundles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- =============================================================================
+-- BUNDLE SLOTS TABLES
+-- =============================================================================
+
+-- A slot allows either any item of a category or an explicit list of items
+CREATE TABLE IF NOT EXISTS public.bundle_slots (
+    id SERIAL PRIMARY KEY,
+    bundle_id INTEGER NOT NULL REFERENCES public.bundles(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50),
+    min_choices INTEGER NOT NULL DEFAULT 1,
+    max_choices INTEGER NOT NULL DEFAULT 1,
+    position INTEGER NOT NULL DEFAULT 0,
+    CHECK (min_choices >= 0 AND max_choices > 0 AND max_choices >= min_choices)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bundle_slots_bundle_id ON public.bundle_slots(bundle_id);
+
+CREATE TABLE IF NOT EXISTS public.bundle_slot_items (
+    slot_id INTEGER NOT NULL REFERENCES public.bundle_slots(id) ON DELETE CASCADE,
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (slot_id, menu_item_id)
+);
+
+-- =============================================================================
+-- ORDER ITEMS: BUNDLE LINES AND COMPONENTS
+-- =============================================================================
+
+-- A line is either a single menu item or a bundle
+ALTER TABLE public.order_items
+    ALTER COLUMN menu_item_id DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS bundle_id INTEGER REFERENCES public.bundles(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT order_items_item_or_bundle CHECK ((menu_item_id IS NULL) <> (bundle_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_order_items_bundle_id ON public.order_items(bundle_id);
+
+-- The items chosen for each slot of a bundle line, quantity is per bundle
+CREATE TABLE IF NOT EXISTS public.order_item_components (
+    id SERIAL PRIMARY KEY,
+    order_item_id INTEGER NOT NULL REFERENCES public.order_items(id) ON DELETE CASCADE,
+    bundle_slot_id INTEGER REFERENCES public.bundle_slots(id) ON DELETE SET NULL,
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_components_order_item_id ON public.order_item_components(order_item_id);
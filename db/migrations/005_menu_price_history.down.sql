@@ -0,0 +1,3 @@
+-- TastyBites: drop menu price history and scheduled price changes
+
+DROP TABLE IF EXISTS public.menu_item_prices;
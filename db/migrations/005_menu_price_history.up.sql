@@ -0,0 +1,27 @@
+-- TastyBites: menu price history and scheduled price changes
+
+-- =============================================================================
+-- MENU ITEM PRICES TABLE
+-- =============================================================================
+
+-- Every price a menu item has had or will have. The price in effect at a given
+-- moment is the entry with the latest effective_from not after that moment;
+-- menu_items.price is only a fallback for items without any history.
+CREATE TABLE IF NOT EXISTS public.menu_item_prices (
+    id SERIAL PRIMARY KEY,
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0), -- Price in cents
+    effective_from TIMESTAMPTZ NOT NULL,
+    created_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (menu_item_id, effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_item_prices_item_effective ON public.menu_item_prices(menu_item_id, effective_from DESC);
+
+-- Seed the history with the current prices
+INSERT INTO public.menu_item_prices (menu_item_id, price, effective_from, note)
+SELECT id, price, coalesce(created_at, CURRENT_TIMESTAMP), 'Initial price'
+FROM public.menu_items
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- TastyBites: drop the promotions engine
+
+DROP TABLE IF EXISTS public.order_discounts;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS coupon_code,
+    DROP COLUMN IF EXISTS subtotal,
+    DROP COLUMN IF EXISTS discount_total;
+
+DROP TABLE IF EXISTS public.promotions;
@@ -0,0 +1,70 @@
+-- TastyBites: promotions engine (coupons and automatic discounts)
+
+-- =============================================================================
+-- PROMOTIONS TABLE
+-- =============================================================================
+
+-- Coupons have a code; automatic promotions (happy hour, buy 2 get 1) do not
+CREATE TABLE IF NOT EXISTS public.promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    code VARCHAR(50) UNIQUE CHECK (code = upper(code)),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percent', 'fixed', 'buy_x_get_y')),
+    value INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0), -- percent, or cents for fixed discounts
+    buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    free_quantity INTEGER NOT NULL DEFAULT 0 CHECK (free_quantity >= 0),
+    category VARCHAR(50), -- only items of this category qualify
+    min_spend INTEGER NOT NULL DEFAULT 0 CHECK (min_spend >= 0), -- in cents
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    daily_start TIME, -- daily window, e.g. 17:00-19:00 for happy hour
+    daily_end TIME,
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0), -- 0 means unlimited
+    per_user_limit INTEGER NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (type <> 'percent' OR value BETWEEN 1 AND 100),
+    CHECK (type <> 'fixed' OR value > 0),
+    CHECK (type <> 'buy_x_get_y' OR (buy_quantity > 0 AND free_quantity > 0)),
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at),
+    CHECK ((daily_start IS NULL) = (daily_end IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON public.promotions(active);
+
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON public.promotions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- =============================================================================
+-- ORDERS: DISCOUNT TOTALS
+-- =============================================================================
+
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS subtotal INTEGER NOT NULL DEFAULT 0 CHECK (subtotal >= 0), -- in cents, before discounts
+    ADD COLUMN IF NOT EXISTS discount_total INTEGER NOT NULL DEFAULT 0 CHECK (discount_total >= 0);
+
+UPDATE public.orders SET subtotal = total_price WHERE subtotal = 0;
+
+-- =============================================================================
+-- ORDER DISCOUNTS TABLE
+-- =============================================================================
+
+-- Discount breakdown of an order; also the source of promotion usage counts
+CREATE TABLE IF NOT EXISTS public.order_discounts (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE CASCADE,
+    promotion_id INTEGER NOT NULL REFERENCES public.promotions(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(50),
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, promotion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_promotion_id ON public.order_discounts(promotion_id);
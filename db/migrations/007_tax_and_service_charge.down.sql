@@ -0,0 +1,13 @@
+-- TastyBites: drop tax rates, service charge and tips
+
+DROP TABLE IF EXISTS public.order_taxes;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS tax_inclusive,
+    DROP COLUMN IF EXISTS tax_total,
+    DROP COLUMN IF EXISTS guests,
+    DROP COLUMN IF EXISTS service_charge,
+    DROP COLUMN IF EXISTS tip;
+
+DROP TABLE IF EXISTS public.tax_rate_categories;
+DROP TABLE IF EXISTS public.tax_rates;
@@ -0,0 +1,55 @@
+-- TastyBites: tax rates per menu category, service charge and tips
+
+-- =============================================================================
+-- TAX RATES TABLE
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.tax_rates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    rate INTEGER NOT NULL CHECK (rate BETWEEN 0 AND 10000), -- in basis points, 500 = 5%
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one rate applies to categories without their own rate
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_rates_default ON public.tax_rates(is_default) WHERE is_default;
+
+CREATE TRIGGER update_tax_rates_updated_at
+    BEFORE UPDATE ON public.tax_rates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Each menu category falls under at most one rate
+CREATE TABLE IF NOT EXISTS public.tax_rate_categories (
+    category VARCHAR(50) PRIMARY KEY,
+    tax_rate_id INTEGER NOT NULL REFERENCES public.tax_rates(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rate_categories_tax_rate_id ON public.tax_rate_categories(tax_rate_id);
+
+-- =============================================================================
+-- ORDERS: PRICE BREAKDOWN
+-- =============================================================================
+
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS tax_total INTEGER NOT NULL DEFAULT 0 CHECK (tax_total >= 0), -- in cents
+    ADD COLUMN IF NOT EXISTS guests INTEGER NOT NULL DEFAULT 1 CHECK (guests >= 0),
+    ADD COLUMN IF NOT EXISTS service_charge INTEGER NOT NULL DEFAULT 0 CHECK (service_charge >= 0),
+    ADD COLUMN IF NOT EXISTS tip INTEGER NOT NULL DEFAULT 0 CHECK (tip >= 0);
+
+-- Tax charged per rate; name and rate are copied so the order keeps them
+CREATE TABLE IF NOT EXISTS public.order_taxes (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER REFERENCES public.tax_rates(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    rate INTEGER NOT NULL CHECK (rate BETWEEN 0 AND 10000),
+    taxable INTEGER NOT NULL CHECK (taxable >= 0), -- net amount taxed, in cents
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    UNIQUE (order_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_taxes_order_id ON public.order_taxes(order_id);
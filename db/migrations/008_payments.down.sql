@@ -0,0 +1,12 @@
+-- TastyBites: drop payments and payment gateway webhooks
+
+DROP TABLE IF EXISTS public.payment_webhook_events;
+DROP TABLE IF EXISTS public.payments;
+
+-- Fold the payment driven statuses back into the ones the base schema knows
+UPDATE public.orders SET status = 'pending' WHERE status = 'paid';
+UPDATE public.orders SET status = 'cancelled' WHERE status = 'refunded';
+
+ALTER TABLE public.orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE public.orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('pending', 'completed', 'cancelled'));
@@ -0,0 +1,58 @@
+-- TastyBites: payments and payment gateway webhooks
+
+-- =============================================================================
+-- ORDERS: PAYMENT DRIVEN STATUSES
+-- =============================================================================
+
+ALTER TABLE public.orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE public.orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('pending', 'paid', 'refunded', 'completed', 'cancelled'));
+
+-- =============================================================================
+-- PAYMENTS TABLE
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.payments (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE RESTRICT,
+    provider VARCHAR(50) NOT NULL,
+    reference VARCHAR(100), -- the gateway's id, unknown until it answers
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN
+        ('pending', 'authorized', 'captured', 'partially_refunded', 'refunded', 'voided', 'failed')),
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    captured INTEGER NOT NULL DEFAULT 0 CHECK (captured >= 0 AND captured <= amount),
+    refunded INTEGER NOT NULL DEFAULT 0 CHECK (refunded >= 0 AND refunded <= captured),
+    currency CHAR(3) NOT NULL,
+    failure_reason TEXT,
+    idempotency_key VARCHAR(255),
+    created_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, reference),
+    UNIQUE (order_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON public.payments(order_id);
+
+CREATE TRIGGER update_payments_updated_at
+    BEFORE UPDATE ON public.payments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- =============================================================================
+-- PAYMENT WEBHOOK EVENTS TABLE
+-- =============================================================================
+
+-- Every delivery is recorded once per provider event id; redeliveries of a
+-- processed event are acknowledged without being applied again
+CREATE TABLE IF NOT EXISTS public.payment_webhook_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP,
+    UNIQUE (provider, event_id)
+);
@@ -0,0 +1,7 @@
+-- TastyBites: drop split bills
+
+ALTER TABLE public.payments DROP COLUMN IF EXISTS check_id;
+
+DROP TABLE IF EXISTS public.order_check_taxes;
+DROP TABLE IF EXISTS public.order_check_items;
+DROP TABLE IF EXISTS public.order_checks;
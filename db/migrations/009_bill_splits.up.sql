@@ -0,0 +1,51 @@
+-- TastyBites: split bills
+
+-- =============================================================================
+-- ORDER CHECKS TABLE
+-- =============================================================================
+
+-- A split order has two or more checks that share its breakdown; amounts in cents
+CREATE TABLE IF NOT EXISTS public.order_checks (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    label VARCHAR(50) NOT NULL,
+    seat INTEGER CHECK (seat > 0),
+    split_mode VARCHAR(10) NOT NULL CHECK (split_mode IN ('items', 'seats', 'even')),
+    subtotal INTEGER NOT NULL DEFAULT 0,
+    discount_total INTEGER NOT NULL DEFAULT 0,
+    tax_total INTEGER NOT NULL DEFAULT 0,
+    service_charge INTEGER NOT NULL DEFAULT 0,
+    tip INTEGER NOT NULL DEFAULT 0 CHECK (tip >= 0),
+    total_price INTEGER NOT NULL DEFAULT 0 CHECK (total_price >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS public.order_check_items (
+    check_id INTEGER NOT NULL REFERENCES public.order_checks(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES public.order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (check_id, order_item_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.order_check_taxes (
+    id SERIAL PRIMARY KEY,
+    check_id INTEGER NOT NULL REFERENCES public.order_checks(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER REFERENCES public.tax_rates(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    rate INTEGER NOT NULL,
+    taxable INTEGER NOT NULL,
+    amount INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_checks_order_id ON public.order_checks(order_id);
+
+-- =============================================================================
+-- PAYMENTS: PER CHECK
+-- =============================================================================
+
+ALTER TABLE public.payments ADD COLUMN IF NOT EXISTS check_id INTEGER
+    REFERENCES public.order_checks(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_check_id ON public.payments(check_id);
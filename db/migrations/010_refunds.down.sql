@@ -0,0 +1,11 @@
+-- TastyBites: drop refunds, reason codes and stock movements
+
+DROP TABLE IF EXISTS public.stock_movements;
+DROP TABLE IF EXISTS public.refund_items;
+DROP TABLE IF EXISTS public.refunds;
+
+ALTER TABLE public.menu_items DROP COLUMN IF EXISTS stock_quantity;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS refunded_total,
+    DROP COLUMN IF EXISTS cash_refunded;
@@ -0,0 +1,61 @@
+-- TastyBites: refunds, reason codes and stock restored by refunds
+
+-- =============================================================================
+-- ORDERS AND MENU ITEMS
+-- =============================================================================
+
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS refunded_total INTEGER NOT NULL DEFAULT 0 CHECK (refunded_total >= 0),
+    ADD COLUMN IF NOT EXISTS cash_refunded INTEGER NOT NULL DEFAULT 0 CHECK (cash_refunded >= 0);
+
+-- NULL means stock is not tracked for the item
+ALTER TABLE public.menu_items ADD COLUMN IF NOT EXISTS stock_quantity INTEGER CHECK (stock_quantity >= 0);
+
+-- =============================================================================
+-- REFUNDS TABLES
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE RESTRICT,
+    payment_id INTEGER REFERENCES public.payments(id) ON DELETE RESTRICT,
+    method VARCHAR(10) NOT NULL CHECK (method IN ('gateway', 'cash')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending_approval', 'processing', 'completed', 'rejected', 'failed')),
+    reason VARCHAR(30) NOT NULL CHECK (reason IN
+        ('quality', 'wrong_item', 'missing_item', 'late', 'duplicate_charge', 'customer_request', 'other')),
+    note TEXT,
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    failure_reason TEXT,
+    requested_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    approved_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.refund_items (
+    refund_id INTEGER NOT NULL REFERENCES public.refunds(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES public.order_items(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (refund_id, order_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON public.refunds(order_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON public.refunds(status);
+CREATE INDEX IF NOT EXISTS idx_refunds_processed_at ON public.refunds(processed_at);
+
+-- =============================================================================
+-- STOCK MOVEMENTS TABLE
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.stock_movements (
+    id SERIAL PRIMARY KEY,
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL, -- positive when stock comes back
+    reason VARCHAR(30) NOT NULL,
+    refund_id INTEGER REFERENCES public.refunds(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_menu_item_id ON public.stock_movements(menu_item_id);
@@ -0,0 +1,4 @@
+-- TastyBites: drop tax invoices
+
+DROP TABLE IF EXISTS public.invoices;
+DROP TABLE IF EXISTS public.invoice_sequences;
@@ -0,0 +1,30 @@
+-- TastyBites: tax invoices numbered without gaps per fiscal year
+
+-- =============================================================================
+-- INVOICE SEQUENCES TABLE
+-- =============================================================================
+
+-- One counter row per fiscal year. Postgres sequences can skip values on
+-- rollback, so the counter is bumped inside the transaction that issues the
+-- invoice and both commit or neither does.
+CREATE TABLE IF NOT EXISTS public.invoice_sequences (
+    fiscal_year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL CHECK (last_number > 0)
+);
+
+-- =============================================================================
+-- INVOICES TABLE
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.invoices (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES public.orders(id) ON DELETE RESTRICT,
+    fiscal_year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    number VARCHAR(30) NOT NULL UNIQUE, -- e.g. INV-2024-000042
+    customer_name VARCHAR(200) NOT NULL,
+    customer_tax_id VARCHAR(50),
+    customer_address TEXT,
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (fiscal_year, sequence)
+);
@@ -0,0 +1,3 @@
+-- TastyBites: drop cooking notes on order lines
+
+ALTER TABLE public.order_items DROP COLUMN IF EXISTS note;
@@ -0,0 +1,3 @@
+-- TastyBites: cooking notes on order lines, printed on kitchen tickets
+
+ALTER TABLE public.order_items ADD COLUMN IF NOT EXISTS note VARCHAR(200);
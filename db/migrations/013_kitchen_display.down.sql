@@ -0,0 +1,17 @@
+-- TastyBites: drop the kitchen display system
+
+DROP TABLE IF EXISTS public.kitchen_ticket_items;
+DROP TABLE IF EXISTS public.kitchen_tickets;
+
+ALTER TABLE public.orders DROP COLUMN IF EXISTS kitchen_status;
+ALTER TABLE public.menu_items DROP COLUMN IF EXISTS station_id;
+
+DROP TABLE IF EXISTS public.kitchen_category_stations;
+DROP TABLE IF EXISTS public.kitchen_stations;
+
+-- Kitchen staff lose their role rather than their account
+UPDATE public.users SET role = 'user' WHERE role = 'kitchen';
+
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE public.users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('user', 'admin', 'manager'));
@@ -0,0 +1,70 @@
+-- TastyBites: kitchen display system with stations, tickets and bump status
+
+-- =============================================================================
+-- KITCHEN STAFF
+-- =============================================================================
+
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE public.users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('user', 'admin', 'manager', 'kitchen'));
+
+-- =============================================================================
+-- STATIONS AND ROUTING
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.kitchen_stations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE, -- gets items nothing else routes
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one default station
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kitchen_stations_default ON public.kitchen_stations(is_default) WHERE is_default;
+
+-- Categories are matched case-insensitively; store them lower case
+CREATE TABLE IF NOT EXISTS public.kitchen_category_stations (
+    category VARCHAR(50) PRIMARY KEY,
+    station_id INTEGER NOT NULL REFERENCES public.kitchen_stations(id) ON DELETE CASCADE
+);
+
+-- An item's own station wins over its category's
+ALTER TABLE public.menu_items
+    ADD COLUMN IF NOT EXISTS station_id INTEGER REFERENCES public.kitchen_stations(id) ON DELETE SET NULL;
+
+-- =============================================================================
+-- TICKETS
+-- =============================================================================
+
+-- NULL until the order is sent to the kitchen
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS kitchen_status VARCHAR(20)
+    CHECK (kitchen_status IN ('queued', 'preparing', 'ready'));
+
+CREATE TABLE IF NOT EXISTS public.kitchen_tickets (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES public.orders(id) ON DELETE CASCADE,
+    station_id INTEGER NOT NULL REFERENCES public.kitchen_stations(id) ON DELETE RESTRICT,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    bumped_at TIMESTAMP,
+    UNIQUE (order_id, station_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kitchen_tickets_station_status ON public.kitchen_tickets(station_id, status, created_at);
+
+CREATE TABLE IF NOT EXISTS public.kitchen_ticket_items (
+    id SERIAL PRIMARY KEY,
+    ticket_id INTEGER NOT NULL REFERENCES public.kitchen_tickets(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES public.order_items(id) ON DELETE CASCADE,
+    menu_item_id INTEGER NOT NULL REFERENCES public.menu_items(id) ON DELETE RESTRICT,
+    bundle_id INTEGER REFERENCES public.bundles(id) ON DELETE SET NULL, -- set for bundle components
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready')),
+    bumped_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kitchen_ticket_items_ticket_id ON public.kitchen_ticket_items(ticket_id);
+
+-- Tickets of items without a routed category go to the default station
+INSERT INTO public.kitchen_stations (name, is_default) VALUES ('kitchen', TRUE)
+ON CONFLICT (name) DO NOTHING;
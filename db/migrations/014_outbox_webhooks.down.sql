@@ -0,0 +1,6 @@
+-- TastyBites: drop the transactional outbox and outgoing webhooks
+
+DROP TABLE IF EXISTS public.webhook_delivery_attempts;
+DROP TABLE IF EXISTS public.webhook_deliveries;
+DROP TABLE IF EXISTS public.webhook_endpoints;
+DROP TABLE IF EXISTS public.outbox_events;
@@ -0,0 +1,59 @@
+-- TastyBites: transactional outbox and outgoing webhooks for integrations
+
+-- =============================================================================
+-- OUTBOX TABLE
+-- =============================================================================
+
+-- Written in the same transaction as the change it describes, so an event
+-- exists exactly when the change was committed
+CREATE TABLE IF NOT EXISTS public.outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMP -- NULL until deliveries were created for it
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undispatched ON public.outbox_events(id) WHERE dispatched_at IS NULL;
+
+-- =============================================================================
+-- WEBHOOK TABLES
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS public.webhook_endpoints (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    description VARCHAR(200),
+    secret VARCHAR(100) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}', -- empty means every event
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id INTEGER NOT NULL REFERENCES public.webhook_endpoints(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES public.outbox_events(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'retrying', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    UNIQUE (endpoint_id, event_id)
+);
+
+-- One row per attempt, the delivery log
+CREATE TABLE IF NOT EXISTS public.webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES public.webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER, -- NULL when no response came back
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON public.webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'retrying');
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id ON public.webhook_deliveries(endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON public.webhook_delivery_attempts(delivery_id);
@@ -0,0 +1,5 @@
+-- TastyBites: drop menu item SKUs and availability
+
+DROP INDEX IF EXISTS public.idx_menu_items_sku;
+ALTER TABLE public.menu_items DROP COLUMN IF EXISTS available;
+ALTER TABLE public.menu_items DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,11 @@
+-- TastyBites: stable SKUs and availability for menu import and export
+
+-- The SKU matches imported rows to menu items; existing items get one from
+-- their id
+ALTER TABLE public.menu_items ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+UPDATE public.menu_items SET sku = 'ITEM-' || id WHERE sku IS NULL;
+ALTER TABLE public.menu_items ALTER COLUMN sku SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_items_sku ON public.menu_items(sku);
+
+-- Archived items stay on record for past orders but cannot be ordered
+ALTER TABLE public.menu_items ADD COLUMN IF NOT EXISTS available BOOLEAN NOT NULL DEFAULT TRUE;
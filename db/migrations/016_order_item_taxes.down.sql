@@ -0,0 +1,3 @@
+-- TastyBites: drop the taxes of order lines
+
+DROP TABLE IF EXISTS public.order_item_taxes;
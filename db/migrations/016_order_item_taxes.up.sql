@@ -0,0 +1,12 @@
+-- TastyBites: the part of each order line taxed at each rate, so refunds
+-- follow the rates the order was priced at
+
+CREATE TABLE IF NOT EXISTS public.order_item_taxes (
+    id SERIAL PRIMARY KEY,
+    order_item_id INTEGER NOT NULL REFERENCES public.order_items(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER REFERENCES public.tax_rates(id) ON DELETE SET NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0), -- gross, for the whole line, in cents
+    UNIQUE (order_item_id, tax_rate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_taxes_order_item_id ON public.order_item_taxes(order_item_id);
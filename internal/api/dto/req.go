@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/abdullahnettoor/tastybites/internal/models"
+import (
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
 
 type UserRegisterRequest struct {
 	Name     string `json:"name"`
@@ -35,10 +39,312 @@ type CreateOrderRequest struct {
 	TableID int         `json:"tableId"`
 	ItemsID []int       `json:"itemsId"`
 	Items   []OrderItem `json:"items"`
+
+	CouponCode string `json:"couponCode,omitempty"`
+	Guests     int    `json:"guests,omitempty"`     // party size, defaults to 1
+	Tip        int    `json:"tip,omitempty"`        // in cents
+	TipPercent int    `json:"tipPercent,omitempty"` // or as a percentage
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code"`
 }
 
 type OrderItem struct {
 	ItemID   int     `json:"itemId"`
 	Quantity int     `json:"quantity"`
-	Price    float64 `json:"price"` // Assuming price is provided in the request
+	Price    float64 `json:"price"` // Ignored, lines are priced by the server
+	Note     string  `json:"note"`  // cooking instructions, e.g. "no onions"
+
+	// Bundle lines set BundleID and Choices instead of ItemID and Price
+	BundleID int            `json:"bundleId,omitempty"`
+	Choices  []BundleChoice `json:"choices,omitempty"`
+}
+
+type BundleChoice struct {
+	SlotID   int `json:"slotId"`
+	ItemID   int `json:"itemId"`
+	Quantity int `json:"quantity"` // defaults to 1
+}
+
+func ToOrderItemModel(item OrderItem) models.OrderItem {
+	if item.BundleID == 0 {
+		return models.OrderItem{
+			MenuItemID: item.ItemID,
+			Quantity:   item.Quantity,
+			Note:       item.Note,
+		}
+	}
+
+	bundleID := item.BundleID
+	choices := make([]models.BundleChoice, len(item.Choices))
+	for i, c := range item.Choices {
+		if c.Quantity == 0 {
+			c.Quantity = 1
+		}
+		choices[i] = models.BundleChoice{SlotID: c.SlotID, MenuItemID: c.ItemID, Quantity: c.Quantity}
+	}
+	return models.OrderItem{
+		BundleID: &bundleID,
+		Quantity: item.Quantity,
+		Choices:  choices,
+		Note:     item.Note,
+	}
+}
+
+type CreateBundleRequest struct {
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	PricingMode     string              `json:"pricingMode"` // "fixed" or "discount"
+	Price           int                 `json:"price"`       // in cents, for fixed pricing
+	DiscountPercent int                 `json:"discountPercent"`
+	Slots           []BundleSlotRequest `json:"slots"`
+}
+
+type BundleSlotRequest struct {
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	ItemIDs    []int  `json:"itemIds"`
+	MinChoices int    `json:"minChoices"` // both default to 1
+	MaxChoices int    `json:"maxChoices"`
+}
+
+func ToBundleModel(req CreateBundleRequest) models.Bundle {
+	bundle := models.Bundle{
+		Name:            req.Name,
+		Description:     req.Description,
+		PricingMode:     models.BundlePricingMode(req.PricingMode),
+		Price:           req.Price,
+		DiscountPercent: req.DiscountPercent,
+		Active:          true,
+		Slots:           make([]models.BundleSlot, len(req.Slots)),
+	}
+	for i, slot := range req.Slots {
+		if slot.MinChoices == 0 && slot.MaxChoices == 0 {
+			slot.MinChoices, slot.MaxChoices = 1, 1
+		}
+		bundle.Slots[i] = models.BundleSlot{
+			Name:        slot.Name,
+			Category:    slot.Category,
+			MenuItemIDs: slot.ItemIDs,
+			MinChoices:  slot.MinChoices,
+			MaxChoices:  slot.MaxChoices,
+		}
+	}
+	return bundle
+}
+
+type SchedulePriceRequest struct {
+	Price         int        `json:"price"`         // in cents
+	EffectiveFrom *time.Time `json:"effectiveFrom"` // RFC 3339, defaults to now
+	Note          string     `json:"note"`
+}
+
+type CreatePromotionRequest struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Code         string     `json:"code"` // leave empty for an automatic promotion
+	Type         string     `json:"type"`
+	Value        int        `json:"value"`
+	BuyQuantity  int        `json:"buyQuantity"`
+	FreeQuantity int        `json:"freeQuantity"`
+	Category     string     `json:"category"`
+	MinSpend     int        `json:"minSpend"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	DailyStart   string     `json:"dailyStart"`
+	DailyEnd     string     `json:"dailyEnd"`
+	UsageLimit   int        `json:"usageLimit"`
+	PerUserLimit int        `json:"perUserLimit"`
+	Stackable    bool       `json:"stackable"`
+}
+
+func ToPromotionModel(req CreatePromotionRequest) models.Promotion {
+	return models.Promotion{
+		Name:         req.Name,
+		Description:  req.Description,
+		Code:         req.Code,
+		Type:         models.PromotionType(req.Type),
+		Value:        req.Value,
+		BuyQuantity:  req.BuyQuantity,
+		FreeQuantity: req.FreeQuantity,
+		Category:     req.Category,
+		MinSpend:     req.MinSpend,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		DailyStart:   req.DailyStart,
+		DailyEnd:     req.DailyEnd,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		Stackable:    req.Stackable,
+		Active:       true,
+	}
+}
+
+type UpdatePromotionRequest struct {
+	Active bool `json:"active"`
+}
+
+type CreateTaxRateRequest struct {
+	Name       string   `json:"name"`
+	Rate       int      `json:"rate"` // in basis points, 500 = 5%
+	Default    bool     `json:"default"`
+	Categories []string `json:"categories"`
+}
+
+func ToTaxRateModel(req CreateTaxRateRequest) models.TaxRate {
+	return models.TaxRate{
+		Name:       req.Name,
+		Rate:       req.Rate,
+		Default:    req.Default,
+		Categories: req.Categories,
+	}
+}
+
+type CreatePaymentRequest struct {
+	Amount        int    `json:"amount"` // in cents, defaults to what is left to pay
+	Source        string `json:"source"` // card token, e.g. "fake_ok" for the fake gateway
+	AuthorizeOnly bool   `json:"authorizeOnly"`
+}
+
+// PaymentAmountRequest is used to capture or refund part of a payment; a zero
+// amount means all of it.
+type PaymentAmountRequest struct {
+	Amount int `json:"amount"`
+}
+
+type SplitOrderRequest struct {
+	Mode   string              `json:"mode"`  // items, seats or even
+	Count  int                 `json:"count"` // number of checks for an even split
+	Checks []SplitCheckRequest `json:"checks"`
+}
+
+type SplitCheckRequest struct {
+	Seat       int              `json:"seat"`
+	Items      []SplitCheckItem `json:"items"`
+	Tip        int              `json:"tip"`        // in cents
+	TipPercent int              `json:"tipPercent"` // of the check's discounted amount
+}
+
+type SplitCheckItem struct {
+	OrderItemID int `json:"orderItemId"`
+	Quantity    int `json:"quantity"`
+}
+
+func ToSplitModel(req SplitOrderRequest) models.SplitRequest {
+	split := models.SplitRequest{
+		Mode:   models.SplitMode(req.Mode),
+		Count:  req.Count,
+		Checks: make([]models.CheckRequest, len(req.Checks)),
+	}
+	for i, c := range req.Checks {
+		items := make([]models.CheckItem, len(c.Items))
+		for j, item := range c.Items {
+			items[j] = models.CheckItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		}
+		split.Checks[i] = models.CheckRequest{
+			Seat:       c.Seat,
+			Items:      items,
+			Tip:        c.Tip,
+			TipPercent: c.TipPercent,
+		}
+	}
+	return split
+}
+
+type CreateRefundRequest struct {
+	Method    string              `json:"method"`    // gateway or cash
+	PaymentID int                 `json:"paymentId"` // optional, for gateway refunds
+	Reason    string              `json:"reason"`
+	Note      string              `json:"note"`
+	Amount    int                 `json:"amount"` // in cents, defaults to the lines or everything left
+	Items     []RefundItemRequest `json:"items"`
+	Restock   bool                `json:"restock"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID int `json:"orderItemId"`
+	Quantity    int `json:"quantity"`
+}
+
+func ToRefundModel(req CreateRefundRequest) models.Refund {
+	refund := models.Refund{
+		Method:  models.RefundMethod(req.Method),
+		Reason:  models.RefundReason(req.Reason),
+		Note:    req.Note,
+		Amount:  req.Amount,
+		Items:   make([]models.RefundItem, len(req.Items)),
+		Restock: req.Restock,
+	}
+	if refund.Method == "" {
+		refund.Method = models.RefundMethodGateway
+	}
+	if req.PaymentID != 0 {
+		refund.PaymentID = &req.PaymentID
+	}
+	for i, item := range req.Items {
+		refund.Items[i] = models.RefundItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+	}
+	return refund
+}
+
+type RejectRefundRequest struct {
+	Note string `json:"note"`
+}
+
+type IssueInvoiceRequest struct {
+	CustomerName    string `json:"customerName"`
+	CustomerTaxID   string `json:"customerTaxId"`
+	CustomerAddress string `json:"customerAddress"`
+}
+
+func ToInvoiceModel(req IssueInvoiceRequest) models.Invoice {
+	return models.Invoice{
+		CustomerName:    req.CustomerName,
+		CustomerTaxID:   req.CustomerTaxID,
+		CustomerAddress: req.CustomerAddress,
+	}
+}
+
+type EmailReceiptRequest struct {
+	Email string `json:"email"`
+}
+
+type ReprintRequest struct {
+	Kind    string `json:"kind"`    // kitchen or receipt
+	Station string `json:"station"` // optional, kitchen tickets of one station only
+}
+
+type CreateStationRequest struct {
+	Name      string `json:"name"`
+	IsDefault bool   `json:"isDefault"` // receives items with no route
+}
+
+func (r CreateStationRequest) ToStationModel() models.Station {
+	return models.Station{
+		Name:      r.Name,
+		IsDefault: r.IsDefault,
+	}
+}
+
+type StationRouteRequest struct {
+	StationID int `json:"stationId"` // 0 removes the route
+}
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"eventTypes"` // empty subscribes to every event
+}
+
+func (r CreateWebhookRequest) ToWebhookEndpointModel() models.WebhookEndpoint {
+	return models.WebhookEndpoint{
+		URL:         r.URL,
+		Description: r.Description,
+		EventTypes:  r.EventTypes,
+	}
+}
+
+type UpdateWebhookRequest struct {
+	Active bool `json:"active"`
 }
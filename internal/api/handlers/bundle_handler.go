@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type bundleHandler struct {
+	BundleUsecase usecases.BundleIUsecase
+}
+
+func NewBundleHandler(bundleUsecase usecases.BundleIUsecase) *bundleHandler {
+	return &bundleHandler{
+		BundleUsecase: bundleUsecase,
+	}
+}
+
+func (h *bundleHandler) GetBundles(w http.ResponseWriter, r *http.Request) {
+	bundles, err := h.BundleUsecase.GetActiveBundles(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, bundles)
+}
+
+func (h *bundleHandler) GetBundle(w http.ResponseWriter, r *http.Request) {
+	bundleId, err := strconv.Atoi(r.PathValue("bundleId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid bundle ID format")
+		return
+	}
+
+	bundle, err := h.BundleUsecase.GetBundle(r.Context(), bundleId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, bundle)
+}
+
+func (h *bundleHandler) CreateBundle(w http.ResponseWriter, r *http.Request) {
+	var bundleReq dto.CreateBundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&bundleReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	bundleId, err := h.BundleUsecase.CreateBundle(r.Context(), dto.ToBundleModel(bundleReq))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Bundle created successfully", map[string]int{"bundleId": bundleId})
+}
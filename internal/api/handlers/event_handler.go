@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/auth"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+	"github.com/abdullahnettoor/tastybites/internal/websocket"
+)
+
+const (
+	sseRetry        = 3000        // how long browsers wait before reconnecting, in milliseconds
+	streamTicketTTL = time.Minute // how long a stream ticket opens a stream
+)
+
+type eventHandler struct {
+	Bus            *events.Bus
+	secretKey      string
+	allowedOrigins []string // pages that may open a WebSocket
+}
+
+func NewEventHandler(bus *events.Bus, secretKey string, allowedOrigins []string) *eventHandler {
+	return &eventHandler{
+		Bus:            bus,
+		secretKey:      secretKey,
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// StreamTicket issues a ticket that opens the event streams for a minute
+// as ?ticket=, for browsers that cannot send the Authorization header. A
+// stream stays open after its ticket expires; reconnecting takes a new one.
+func (h *eventHandler) StreamTicket(w http.ResponseWriter, r *http.Request) {
+	userId, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	role, err := utils.GetRoleFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	ticket, expiresAt, err := auth.CreateStreamTicket(h.secretKey, role, userId, streamTicketTTL)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to create stream ticket")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{
+		"ticket":    ticket,
+		"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// Stream sends events as Server-Sent Events. A client resumes with the
+// Last-Event-ID header, or ?lastEventId= where it cannot set headers.
+func (h *eventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	principal, lastEventID, ok := eventSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// A stream outlives any write timeout the server has
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sub := h.Bus.Subscribe(principal, lastEventID)
+	defer sub.Close()
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	if sub.Missed {
+		writeSSE(w, events.Event{Type: events.Reset, Time: time.Now().UTC()})
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("failed to flush event stream: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.Bus.Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				return
+			}
+			writeSSE(w, e)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("failed to encode event %d: %v", e.ID, err)
+		return
+	}
+	if e.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", e.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
+
+// WebSocket sends the same events as Stream, one JSON text message each.
+// Browsers cannot set headers on a WebSocket, so it resumes with
+// ?lastEventId= only, and only pages on the allowed origins may open it.
+func (h *eventHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	principal, lastEventID, ok := eventSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r, h.allowedOrigins)
+	if err != nil {
+		log.Printf("failed to upgrade to websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := h.Bus.Subscribe(principal, lastEventID)
+	defer sub.Close()
+
+	// Client messages are not used, but reading answers pings and notices
+	// when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(e events.Event) bool {
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("failed to encode event %d: %v", e.ID, err)
+			return true
+		}
+		return conn.WriteText(data) == nil
+	}
+	if sub.Missed && !send(events.Event{Type: events.Reset, Time: time.Now().UTC()}) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.Bus.Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case e, ok := <-sub.C:
+			if !ok || !send(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if conn.Ping() != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventSubscription reads who is listening and where they resume from.
+// Kitchen screens can narrow their stream with ?station=.
+func eventSubscription(w http.ResponseWriter, r *http.Request) (events.Principal, uint64, bool) {
+	userId, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return events.Principal{}, 0, false
+	}
+	role, err := utils.GetRoleFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return events.Principal{}, 0, false
+	}
+	principal := events.Principal{UserID: userId, Role: role}
+
+	if s := r.URL.Query().Get("station"); s != "" {
+		if principal.StationID, err = strconv.Atoi(s); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid station ID format")
+			return events.Principal{}, 0, false
+		}
+	}
+
+	var lastEventID uint64
+	s := r.Header.Get("Last-Event-ID")
+	if s == "" {
+		s = r.URL.Query().Get("lastEventId")
+	}
+	if s != "" {
+		if lastEventID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return events.Principal{}, 0, false
+		}
+	}
+	return principal, lastEventID, true
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/middlewares"
+	"github.com/abdullahnettoor/tastybites/internal/auth"
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const testSecret = "test-secret"
+
+// newEventServer serves the event routes the way routes.go does.
+func newEventServer(t *testing.T, replaySize int) (*events.Bus, *httptest.Server) {
+	t.Helper()
+	bus, err := events.NewBus(&config.EventsConfig{ReplaySize: replaySize, Heartbeat: 15 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bus.Close)
+
+	h := NewEventHandler(bus, testSecret, []string{"https://tastybites.com"})
+	a := middlewares.NewAuth(testSecret)
+	mux := http.NewServeMux()
+	mux.Handle("GET /events", a.AuthenticateStream(http.HandlerFunc(h.Stream)))
+	mux.Handle("POST /events/ticket", a.Authenticate(http.HandlerFunc(h.StreamTicket)))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return bus, srv
+}
+
+// streamTicket logs in as a customer and asks for a ticket, as a browser
+// does before opening an EventSource.
+func streamTicket(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	token, _, err := auth.CreateToken(testSecret, string(models.UserRoleUser), 3, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/events/ticket", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Ticket    string `json:"ticket"`
+		ExpiresAt string `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || resp.StatusCode != http.StatusCreated || body.Ticket == "" {
+		t.Fatalf("POST /events/ticket: %d %+v, %v", resp.StatusCode, body, err)
+	}
+	if _, err := time.Parse(time.RFC3339, body.ExpiresAt); err != nil {
+		t.Errorf("expiresAt %q: %v", body.ExpiresAt, err)
+	}
+	return body.Ticket
+}
+
+// sseEvent is one event read off a stream.
+type sseEvent struct {
+	id, event, data string
+}
+
+// openStream opens GET /events and reads past the retry advice.
+func openStream(t *testing.T, srv *httptest.Server, query url.Values, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events?"+query.Encode(), nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	br := bufio.NewReader(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return resp, br
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	if retry := readSSE(t, br); retry.event != "" || retry.data != "" {
+		t.Errorf("stream opened with %+v, want the retry advice", retry)
+	}
+	return resp, br
+}
+
+// readSSE reads up to the blank line that ends an event.
+func readSSE(t *testing.T, br *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return e
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+func TestStreamResumes(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID string // header
+		query       string // ?lastEventId=
+		want        []string
+	}{
+		{"new stream", "", "", []string{"6"}},
+		{"Last-Event-ID", "2", "", []string{"3", "5", "6"}},
+		{"query", "", "3", []string{"5", "6"}},
+		{"header wins", "3", "1", []string{"5", "6"}},
+		{"fell out of the buffer", "1", "", []string{"reset", "6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, srv := newEventServer(t, 3)
+			bus.Publish(events.Event{Type: events.TableReset, Public: true}) // 1
+			bus.Publish(events.Event{Type: events.OrderCreated, UserID: 3})  // 2
+			bus.Publish(events.Event{Type: events.OrderCreated, UserID: 3})  // 3
+			bus.Publish(events.Event{Type: events.OrderCreated, UserID: 4})  // 4, someone else's
+			bus.Publish(events.Event{Type: events.TableReset, Public: true}) // 5
+
+			query := url.Values{"ticket": {streamTicket(t, srv)}}
+			if tt.query != "" {
+				query.Set("lastEventId", tt.query)
+			}
+			_, br := openStream(t, srv, query, tt.lastEventID)
+
+			// Replayed events arrive before the stream goes live, so one
+			// published now comes after them
+			bus.Publish(events.Event{Type: events.OrderStatusChanged, UserID: 3}) // 6
+
+			var got []string
+			for len(got) < len(tt.want) {
+				e := readSSE(t, br)
+				if e.event == string(events.Reset) {
+					got = append(got, "reset")
+					continue
+				}
+				var decoded events.Event
+				if err := json.Unmarshal([]byte(e.data), &decoded); err != nil || e.id == "" || e.event != string(decoded.Type) {
+					t.Fatalf("event %+v, %v", e, err)
+				}
+				if e.id == "4" {
+					t.Fatal("customer was sent another customer's order")
+				}
+				got = append(got, e.id)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("received %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamRejects(t *testing.T) {
+	_, srv := newEventServer(t, 3)
+	ticket := streamTicket(t, srv)
+	tests := []struct {
+		name        string
+		query       url.Values
+		lastEventID string
+		want        int
+	}{
+		{"no ticket", url.Values{}, "", http.StatusUnauthorized},
+		{"bad ticket", url.Values{"ticket": {"nonsense"}}, "", http.StatusUnauthorized},
+		{"bad Last-Event-ID", url.Values{"ticket": {ticket}}, "abc", http.StatusBadRequest},
+		{"bad station", url.Values{"ticket": {ticket}, "station": {"grill"}}, "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, _ := openStream(t, srv, tt.query, tt.lastEventID)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+	}
+}
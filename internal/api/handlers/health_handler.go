@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/abdullahnettoor/tastybites/internal/health"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type healthHandler struct {
+	Registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *healthHandler {
+	return &healthHandler{
+		Registry: registry,
+	}
+}
+
+// Live answers the liveness probe: 200 while the process works, 503 once it
+// needs a restart.
+func (h *healthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, h.Registry.Live(r.Context()))
+}
+
+// Ready answers the readiness probe: 200 while the server can take
+// requests, 503 while a dependency such as the database is down or the
+// server is shutting down.
+func (h *healthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, h.Registry.Ready(r.Context()))
+}
+
+func writeHealthReport(w http.ResponseWriter, report health.Report) {
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSONResponse(w, status, report)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type kitchenHandler struct {
+	KitchenUsecase usecases.KitchenIUsecase
+}
+
+func NewKitchenHandler(kitchenUsecase usecases.KitchenIUsecase) *kitchenHandler {
+	return &kitchenHandler{
+		KitchenUsecase: kitchenUsecase,
+	}
+}
+
+func (h *kitchenHandler) GetStations(w http.ResponseWriter, r *http.Request) {
+	stations, err := h.KitchenUsecase.GetStations(r.Context())
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, stations)
+}
+
+func (h *kitchenHandler) CreateStation(w http.ResponseWriter, r *http.Request) {
+	var stationReq dto.CreateStationRequest
+	if err := json.NewDecoder(r.Body).Decode(&stationReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	station, err := h.KitchenUsecase.CreateStation(r.Context(), stationReq.ToStationModel())
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Station created successfully", station)
+}
+
+func (h *kitchenHandler) RouteCategory(w http.ResponseWriter, r *http.Request) {
+	var routeReq dto.StationRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&routeReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.KitchenUsecase.RouteCategory(r.Context(), r.PathValue("category"), routeReq.StationID); err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Category route updated", nil)
+}
+
+func (h *kitchenHandler) RouteMenuItem(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid menu item ID format")
+		return
+	}
+
+	var routeReq dto.StationRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&routeReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.KitchenUsecase.RouteMenuItem(r.Context(), itemId, routeReq.StationID); err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Menu item route updated", nil)
+}
+
+// SendToKitchen sends an order whose tickets failed to be created when it
+// was placed.
+func (h *kitchenHandler) SendToKitchen(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	tickets, err := h.KitchenUsecase.SendToKitchen(r.Context(), orderId)
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
+// GetStationQueue lists the station's open tickets, or ?status=done for the
+// recently bumped ones.
+func (h *kitchenHandler) GetStationQueue(w http.ResponseWriter, r *http.Request) {
+	stationId, err := strconv.Atoi(r.PathValue("stationId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid station ID format")
+		return
+	}
+
+	status := models.TicketStatus(r.URL.Query().Get("status"))
+	tickets, err := h.KitchenUsecase.GetStationQueue(r.Context(), stationId, status)
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
+func (h *kitchenHandler) GetOrderTickets(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	tickets, err := h.KitchenUsecase.GetOrderTickets(r.Context(), orderId)
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
+func (h *kitchenHandler) BumpTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateTicket(w, r, h.KitchenUsecase.BumpTicket)
+}
+
+func (h *kitchenHandler) RecallTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateTicket(w, r, h.KitchenUsecase.RecallTicket)
+}
+
+func (h *kitchenHandler) BumpItem(w http.ResponseWriter, r *http.Request) {
+	h.updateItem(w, r, h.KitchenUsecase.BumpItem)
+}
+
+func (h *kitchenHandler) RecallItem(w http.ResponseWriter, r *http.Request) {
+	h.updateItem(w, r, h.KitchenUsecase.RecallItem)
+}
+
+func (h *kitchenHandler) updateTicket(w http.ResponseWriter, r *http.Request, update func(ctx context.Context, id int) (models.KitchenTicket, error)) {
+	ticketId, err := strconv.Atoi(r.PathValue("ticketId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid ticket ID format")
+		return
+	}
+
+	ticket, err := update(r.Context(), ticketId)
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, ticket)
+}
+
+func (h *kitchenHandler) updateItem(w http.ResponseWriter, r *http.Request, update func(ctx context.Context, id int) (models.KitchenTicket, error)) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid ticket item ID format")
+		return
+	}
+
+	ticket, err := update(r.Context(), itemId)
+	if err != nil {
+		writeKitchenError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, ticket)
+}
+
+func writeKitchenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,8 +1,21 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/imaging"
+	"github.com/abdullahnettoor/tastybites/internal/menufile"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
 	"github.com/abdullahnettoor/tastybites/internal/usecases"
 	"github.com/abdullahnettoor/tastybites/internal/utils"
 )
@@ -19,7 +32,13 @@ func NewMenuHandler(menuUsecase usecases.MenuIUsecase) *menuHandler {
 
 func (h *menuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
 
-	allMenuItems, err := h.MenuUsecase.GetAllMenuItems(r.Context())
+	filter, err := parseMenuFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	allMenuItems, err := h.MenuUsecase.GetMenuItems(r.Context(), filter)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -28,6 +47,344 @@ func (h *menuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSONResponse(w, http.StatusOK, allMenuItems)
 }
 
+func (h *menuHandler) SearchMenuItems(w http.ResponseWriter, r *http.Request) {
+	query := models.MenuSearchQuery{
+		Text: r.URL.Query().Get("q"),
+	}
+
+	var err error
+	if query.Page, err = parseOptionalInt(r.URL.Query().Get("page")); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid page")
+		return
+	}
+	if query.Limit, err = parseOptionalInt(r.URL.Query().Get("limit")); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	result, err := h.MenuUsecase.SearchMenuItems(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+func (h *menuHandler) UploadMenuItemImage(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid menu item ID format")
+		return
+	}
+
+	// Leave some room for the multipart envelope around the file
+	r.Body = http.MaxBytesReader(w, r.Body, usecases.MaxImageUploadBytes+1<<20)
+	if err := r.ParseMultipartForm(usecases.MaxImageUploadBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Image is too large")
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Image file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, usecases.MaxImageUploadBytes+1))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Failed to read image")
+		return
+	}
+
+	images, err := h.MenuUsecase.UploadMenuItemImage(r.Context(), itemId, data)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		utils.WriteErrorResponse(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	case errors.Is(err, imaging.ErrTooLarge):
+		utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	case err != nil:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Image uploaded successfully", images)
+}
+
+// ServeImage serves stored image variants. Keys are content addressed, so
+// responses may be cached indefinitely.
+func (h *menuHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
+	body, info, err := h.MenuUsecase.OpenImage(r.Context(), r.PathValue("key"))
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			http.NotFound(w, r)
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", info.ETag)
+
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, info.Key, info.LastModified, seeker)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
+
+func (h *menuHandler) ScheduleMenuItemPrice(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid menu item ID format")
+		return
+	}
+
+	var priceReq dto.SchedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&priceReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	userId, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	price := models.MenuItemPrice{
+		MenuItemID: itemId,
+		Price:      priceReq.Price,
+		CreatedBy:  &userId,
+		Note:       priceReq.Note,
+	}
+	if priceReq.EffectiveFrom != nil {
+		price.EffectiveFrom = *priceReq.EffectiveFrom
+	}
+
+	priceId, err := h.MenuUsecase.ScheduleMenuItemPrice(r.Context(), price)
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Price change scheduled successfully", map[string]int{"priceId": priceId})
+}
+
+func (h *menuHandler) GetPriceTimeline(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid menu item ID format")
+		return
+	}
+
+	timeline, err := h.MenuUsecase.GetPriceTimeline(r.Context(), itemId)
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, timeline)
+}
+
+func (h *menuHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(r.PathValue("itemId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid menu item ID format")
+		return
+	}
+	priceId, err := strconv.Atoi(r.PathValue("priceId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid price ID format")
+		return
+	}
+
+	if err := h.MenuUsecase.CancelScheduledPrice(r.Context(), itemId, priceId); err != nil {
+		writeMenuError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Scheduled price change cancelled", nil)
+}
+
+// maxMenuFileBytes caps the size of menu import files.
+const maxMenuFileBytes = 5 << 20
+
+// ExportMenu downloads the whole menu as ?format=csv or json, the default.
+func (h *menuHandler) ExportMenu(w http.ResponseWriter, r *http.Request) {
+	format, err := menufile.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := h.MenuUsecase.ExportMenu(r.Context())
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := menufile.Write(&body, format, rows); err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+format.Filename()+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// ImportMenu reads a menu file from the request body. The format comes from
+// ?format= or else the Content-Type; ?dryRun=true only reports the diff and
+// ?archiveMissing=true archives the items the file leaves out. A file with
+// invalid rows changes nothing and is answered with the errors.
+func (h *menuHandler) ImportMenu(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rawFormat := query.Get("format")
+	if rawFormat == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		rawFormat = string(menufile.FormatCSV)
+	}
+	format, err := menufile.ParseFormat(rawFormat)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	in := models.MenuImport{}
+	if in.DryRun, err = parseOptionalBool(query.Get("dryRun")); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid dryRun")
+		return
+	}
+	if in.ArchiveMissing, err = parseOptionalBool(query.Get("archiveMissing")); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid archiveMissing")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMenuFileBytes)
+	defer r.Body.Close()
+	in.Rows, in.Errors, err = menufile.Read(r.Body, format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Menu file is too large")
+			return
+		}
+		writeMenuError(w, err)
+		return
+	}
+
+	result, err := h.MenuUsecase.ImportMenu(r.Context(), in)
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		utils.WriteJSONResponse(w, http.StatusUnprocessableEntity, result)
+	case result.DryRun:
+		utils.WriteSuccessResponse(w, http.StatusOK, "Dry run, nothing was changed", result)
+	default:
+		utils.WriteSuccessResponse(w, http.StatusOK, "Menu imported successfully", result)
+	}
+}
+
+// writeMenuError maps usecase errors to HTTP status codes.
+func writeMenuError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseOptionalInt parses a query value, treating an empty value as zero.
+func parseOptionalInt(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// parseOptionalBool parses a query flag, treating an empty value as false.
+func parseOptionalBool(raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// parseMenuFilter reads ?category=, ?exclude_allergens=a,b, ?tags=a,b and ?max_spicy=n
+func parseMenuFilter(query url.Values) (models.MenuFilter, error) {
+	filter := models.MenuFilter{
+		Category: query.Get("category"),
+	}
+
+	for _, raw := range splitList(query.Get("exclude_allergens")) {
+		allergen, err := models.ParseAllergen(raw)
+		if err != nil {
+			return models.MenuFilter{}, err
+		}
+		filter.ExcludeAllergens = append(filter.ExcludeAllergens, allergen)
+	}
+
+	for _, raw := range splitList(query.Get("tags")) {
+		tag, err := models.ParseDietaryTag(raw)
+		if err != nil {
+			return models.MenuFilter{}, err
+		}
+		filter.Tags = append(filter.Tags, tag)
+	}
+
+	if raw := query.Get("max_spicy"); raw != "" {
+		level, err := strconv.Atoi(raw)
+		if err != nil || level < 0 || level > models.MaxSpicyLevel {
+			return models.MenuFilter{}, fmt.Errorf("%w: max_spicy must be between 0 and %d", models.ErrInvalidInput, models.MaxSpicyLevel)
+		}
+		filter.MaxSpicyLevel = &level
+	}
+
+	return filter, nil
+}
+
+// splitList splits a comma separated query value, dropping blanks.
+func splitList(raw string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
 func (h *menuHandler) GetAllTables(w http.ResponseWriter, r *http.Request) {
 	allTables, err := h.MenuUsecase.GetAvailableTables(r.Context())
 	if err != nil {
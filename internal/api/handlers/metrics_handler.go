@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+type metricsHandler struct{}
+
+func NewMetricsHandler() *metricsHandler {
+	return &metricsHandler{}
+}
+
+// Metrics writes the published expvar variables, such as dbPool and the
+// memory statistics, as one JSON object in the format of /debug/vars. The
+// command line is left out, as it may hold secrets set with -set.
+func (h *metricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprint(w, "{")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			fmt.Fprint(w, ",")
+		}
+		first = false
+		fmt.Fprintf(w, "\n%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprint(w, "\n}\n")
+}
@@ -3,8 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/api/dto"
 	"github.com/abdullahnettoor/tastybites/internal/models"
@@ -26,15 +29,22 @@ func NewOrderHandler(orderUsecase usecases.OrderIUsecase, userUsecase usecases.U
 	}
 }
 
+// AdminGetAllOrders lists one page of every user's orders; see
+// parseOrderListQuery for the parameters.
 func (h *orderHandler) AdminGetAllOrders(w http.ResponseWriter, r *http.Request) {
-
-	allOrders, err := h.OrderUsecase.GetAllOrders(r.Context())
+	query, err := parseOrderListQuery(r.URL.Query())
+	if err == nil && r.URL.Query().Has("userId") {
+		query.UserID, err = strconv.Atoi(r.URL.Query().Get("userId"))
+		if err != nil {
+			err = fmt.Errorf("%w: invalid userId", models.ErrInvalidInput)
+		}
+	}
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusOK, allOrders)
+	h.writeOrderPage(w, r, query)
 }
 
 func (h *orderHandler) GetOrderByTableId(w http.ResponseWriter, r *http.Request) {
@@ -88,11 +98,7 @@ func (h *orderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	oItems := make([]models.OrderItem, len(orderReq.Items))
 	for i, item := range orderReq.Items {
-		oItems[i] = models.OrderItem{
-			MenuItemID: item.ItemID,
-			Quantity:   item.Quantity,
-			Price:      item.Price,
-		}
+		oItems[i] = dto.ToOrderItemModel(item)
 	}
 
 	order := models.Order{
@@ -101,12 +107,24 @@ func (h *orderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		Items:   oItems,
 		TableID: orderReq.TableID,
 		Status:  models.OrderStatusPending,
+
+		CouponCode: orderReq.CouponCode,
+		Guests:     orderReq.Guests,
+		Tip:        orderReq.Tip,
+		TipPercent: orderReq.TipPercent,
 	}
 
 	order.CalculateTotalPrice()
 
 	orderId, err := h.OrderUsecase.CreateOrder(r.Context(), order)
 	if err != nil {
+		if writeCouponError(w, err) {
+			return
+		}
+		if errors.Is(err, models.ErrInvalidInput) || errors.Is(err, models.ErrNotFound) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -114,6 +132,78 @@ func (h *orderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	utils.WriteSuccessResponse(w, http.StatusCreated, "Order created successfully", orderId)
 }
 
+func (h *orderHandler) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	var couponReq dto.ApplyCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&couponReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	h.updateCoupon(w, r, couponReq.Code)
+}
+
+func (h *orderHandler) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	h.updateCoupon(w, r, "")
+}
+
+// updateCoupon applies the code to the order in the path, or removes the
+// current coupon when code is empty, and writes the repriced order.
+func (h *orderHandler) updateCoupon(w http.ResponseWriter, r *http.Request, code string) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var order models.Order
+	if code == "" {
+		order, err = h.OrderUsecase.RemoveCoupon(r.Context(), orderId, userID)
+	} else {
+		order, err = h.OrderUsecase.ApplyCoupon(r.Context(), orderId, userID, code)
+	}
+	if err != nil {
+		if writeCouponError(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, models.ErrInvalidInput):
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrNotFound):
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrOrderSplit):
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, order)
+}
+
+// writeCouponError writes the response for coupon and promotion errors and
+// reports whether err was one of them.
+func writeCouponError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrCouponNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrCouponNotApplicable):
+		utils.WriteErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, models.ErrPromotionLimitReached):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
 func (h *orderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	userId, err := utils.GetUserIDFromContext(r)
 	if err != nil {
@@ -121,15 +211,68 @@ func (h *orderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allOrders, err := h.OrderUsecase.GetOrdersByUser(r.Context(), userId)
-	if err != nil && errors.Is(err, models.ErrIsEmpty) {
-		utils.WriteErrorResponse(w, http.StatusNotFound, "No orders found")
+	query, err := parseOrderListQuery(r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query.UserID = userId
+
+	h.writeOrderPage(w, r, query)
+}
+
+func (h *orderHandler) writeOrderPage(w http.ResponseWriter, r *http.Request, query models.OrderListQuery) {
+	page, err := h.OrderUsecase.ListOrders(r.Context(), query, r.URL.Query().Get("cursor"))
+	if errors.Is(err, models.ErrInvalidInput) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
 	} else if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusOK, allOrders)
+	utils.WriteJSONResponse(w, http.StatusOK, page)
+}
+
+// parseOrderListQuery reads ?status=, ?tableId=, ?from= and ?to= (RFC 3339
+// or YYYY-MM-DD, a date `to` taking in the whole day), ?minTotal= in cents,
+// ?sort=createdAt|total with a leading - for descending, and ?limit=.
+func parseOrderListQuery(values url.Values) (models.OrderListQuery, error) {
+	query := models.OrderListQuery{Status: models.OrderStatus(values.Get("status"))}
+
+	var err error
+	if query.TableID, err = parseOptionalInt(values.Get("tableId")); err != nil {
+		return models.OrderListQuery{}, fmt.Errorf("%w: invalid tableId", models.ErrInvalidInput)
+	}
+	if query.MinTotal, err = parseOptionalInt(values.Get("minTotal")); err != nil {
+		return models.OrderListQuery{}, fmt.Errorf("%w: invalid minTotal", models.ErrInvalidInput)
+	}
+	if query.Limit, err = parseOptionalInt(values.Get("limit")); err != nil {
+		return models.OrderListQuery{}, fmt.Errorf("%w: invalid limit", models.ErrInvalidInput)
+	}
+	if query.SortBy, query.Desc, err = models.ParseOrderSort(values.Get("sort")); err != nil {
+		return models.OrderListQuery{}, err
+	}
+
+	for name, dest := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, value)
+			if err == nil && name == "to" {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		if err != nil {
+			return models.OrderListQuery{}, fmt.Errorf("%w: invalid %s date", models.ErrInvalidInput, name)
+		}
+		*dest = t
+	}
+
+	return query, nil
 }
 
 func (h *orderHandler) UpdateTableStatus(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+// maxWebhookBytes caps the size of a webhook delivery.
+const maxWebhookBytes = 1 << 20
+
+type paymentHandler struct {
+	PaymentUsecase usecases.PaymentIUsecase
+}
+
+func NewPaymentHandler(paymentUsecase usecases.PaymentIUsecase) *paymentHandler {
+	return &paymentHandler{
+		PaymentUsecase: paymentUsecase,
+	}
+}
+
+func (h *paymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var paymentReq dto.CreatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&paymentReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	payment, err := h.PaymentUsecase.CreatePayment(r.Context(), models.PaymentRequest{
+		OrderID:        orderId,
+		UserID:         userID,
+		Amount:         paymentReq.Amount,
+		Source:         paymentReq.Source,
+		AuthorizeOnly:  paymentReq.AuthorizeOnly,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	// Declines are recorded like any other outcome
+	if payment.Status == models.PaymentStatusFailed {
+		utils.WriteJSONResponse(w, http.StatusPaymentRequired, payment)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, payment)
+}
+
+func (h *paymentHandler) GetOrderPayments(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	payments, err := h.PaymentUsecase.GetOrderPayments(r.Context(), orderId, userID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, payments)
+}
+
+func (h *paymentHandler) AdminGetOrderPayments(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	payments, err := h.PaymentUsecase.GetOrderPayments(r.Context(), orderId, 0)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, payments)
+}
+
+func (h *paymentHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	h.updatePayment(w, r, func(paymentId, amount int) (models.Payment, error) {
+		return h.PaymentUsecase.CapturePayment(r.Context(), paymentId, amount)
+	})
+}
+
+func (h *paymentHandler) VoidPayment(w http.ResponseWriter, r *http.Request) {
+	h.updatePayment(w, r, func(paymentId, _ int) (models.Payment, error) {
+		return h.PaymentUsecase.VoidPayment(r.Context(), paymentId)
+	})
+}
+
+// updatePayment runs an admin action on the payment in the path, with an
+// optional amount in the body.
+func (h *paymentHandler) updatePayment(w http.ResponseWriter, r *http.Request, action func(paymentId, amount int) (models.Payment, error)) {
+	paymentId, err := strconv.Atoi(r.PathValue("paymentId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid payment ID format")
+		return
+	}
+
+	var amountReq dto.PaymentAmountRequest
+	if err := json.NewDecoder(r.Body).Decode(&amountReq); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	payment, err := action(paymentId, amountReq.Amount)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, payment)
+}
+
+func (h *paymentHandler) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Webhook payload too large")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.PaymentUsecase.HandleWebhook(r.Context(), r.PathValue("provider"), payload, r.Header); err != nil {
+		if errors.Is(err, models.ErrInvalidSignature) {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Webhook processed", nil)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrPaymentConflict), errors.Is(err, models.ErrPaymentNotAllowed), errors.Is(err, models.ErrOrderSplit):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrGatewayFailure):
+		utils.WriteErrorResponse(w, http.StatusBadGateway, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
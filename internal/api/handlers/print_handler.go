@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/printing"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type printHandler struct {
+	PrintUsecase usecases.PrintIUsecase
+}
+
+func NewPrintHandler(printUsecase usecases.PrintIUsecase) *printHandler {
+	return &printHandler{
+		PrintUsecase: printUsecase,
+	}
+}
+
+func (h *printHandler) Reprint(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	var reprintReq dto.ReprintRequest
+	if err := json.NewDecoder(r.Body).Decode(&reprintReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	jobs, err := h.PrintUsecase.Reprint(r.Context(), orderId, models.PrintJobKind(reprintReq.Kind), reprintReq.Station)
+	if err != nil {
+		writePrintError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusAccepted, jobs)
+}
+
+// GetPrintJobs lists recent print jobs, of one order with ?orderId=.
+func (h *printHandler) GetPrintJobs(w http.ResponseWriter, r *http.Request) {
+	orderId := 0
+	if s := r.URL.Query().Get("orderId"); s != "" {
+		var err error
+		if orderId, err = strconv.Atoi(s); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+	}
+
+	jobs, err := h.PrintUsecase.GetPrintJobs(r.Context(), orderId)
+	if err != nil {
+		writePrintError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, jobs)
+}
+
+func writePrintError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, printing.ErrQueueFull):
+		utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
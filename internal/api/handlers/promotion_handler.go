@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type promotionHandler struct {
+	PromotionUsecase usecases.PromotionIUsecase
+}
+
+func NewPromotionHandler(promotionUsecase usecases.PromotionIUsecase) *promotionHandler {
+	return &promotionHandler{
+		PromotionUsecase: promotionUsecase,
+	}
+}
+
+func (h *promotionHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.PromotionUsecase.GetAllPromotions(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, promotions)
+}
+
+func (h *promotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var promotionReq dto.CreatePromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&promotionReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	promotionId, err := h.PromotionUsecase.CreatePromotion(r.Context(), dto.ToPromotionModel(promotionReq))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Promotion created successfully", map[string]int{"promotionId": promotionId})
+}
+
+func (h *promotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionId, err := strconv.Atoi(r.PathValue("promotionId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid promotion ID format")
+		return
+	}
+
+	var promotionReq dto.UpdatePromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&promotionReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.PromotionUsecase.SetPromotionActive(r.Context(), promotionId, promotionReq.Active); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Promotion updated successfully", nil)
+}
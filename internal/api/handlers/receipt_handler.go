@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/receipts"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type receiptHandler struct {
+	ReceiptUsecase usecases.ReceiptIUsecase
+}
+
+func NewReceiptHandler(receiptUsecase usecases.ReceiptIUsecase) *receiptHandler {
+	return &receiptHandler{
+		ReceiptUsecase: receiptUsecase,
+	}
+}
+
+// GetReceipt renders the receipt of one of the user's orders. The format
+// query parameter picks text (the default), html, pdf or escpos.
+func (h *receiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	h.writeReceipt(w, r, userID)
+}
+
+func (h *receiptHandler) AdminGetReceipt(w http.ResponseWriter, r *http.Request) {
+	h.writeReceipt(w, r, 0)
+}
+
+func (h *receiptHandler) writeReceipt(w http.ResponseWriter, r *http.Request, userID int) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+	format, err := receipts.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	receipt, err := h.ReceiptUsecase.GetReceipt(r.Context(), orderId, userID)
+	if err != nil {
+		writeReceiptError(w, err)
+		return
+	}
+
+	// Render fully before writing so a failure can still become an error response
+	var body bytes.Buffer
+	if err := receipts.Render(&body, format, receipt); err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	switch format {
+	case receipts.FormatPDF:
+		w.Header().Set("Content-Disposition", `inline; filename="`+receipts.Filename(receipt, format)+`"`)
+	case receipts.FormatESCPOS:
+		w.Header().Set("Content-Disposition", `attachment; filename="`+receipts.Filename(receipt, format)+`"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+func (h *receiptHandler) IssueInvoice(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var invoiceReq dto.IssueInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&invoiceReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	invoice, err := h.ReceiptUsecase.IssueInvoice(r.Context(), orderId, userID, dto.ToInvoiceModel(invoiceReq))
+	if err != nil {
+		writeReceiptError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, invoice)
+}
+
+func (h *receiptHandler) EmailReceipt(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var emailReq dto.EmailReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&emailReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.ReceiptUsecase.EmailReceipt(r.Context(), orderId, userID, emailReq.Email); err != nil {
+		writeReceiptError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusAccepted, "Receipt sent", nil)
+}
+
+func writeReceiptError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrOrderNotPaid):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type refundHandler struct {
+	RefundUsecase usecases.RefundIUsecase
+}
+
+func NewRefundHandler(refundUsecase usecases.RefundIUsecase) *refundHandler {
+	return &refundHandler{
+		RefundUsecase: refundUsecase,
+	}
+}
+
+func (h *refundHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var refundReq dto.CreateRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&refundReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	refund := dto.ToRefundModel(refundReq)
+	refund.OrderID = orderId
+	refund.RequestedBy = userID
+	refund, err = h.RefundUsecase.CreateRefund(r.Context(), refund)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	// Refunds waiting for a manager are accepted but not done yet
+	if refund.Status == models.RefundStatusPendingApproval {
+		utils.WriteJSONResponse(w, http.StatusAccepted, refund)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, refund)
+}
+
+func (h *refundHandler) GetOrderRefunds(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	refunds, err := h.RefundUsecase.GetOrderRefunds(r.Context(), orderId)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, refunds)
+}
+
+func (h *refundHandler) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	status := models.RefundStatus(r.URL.Query().Get("status"))
+	refunds, err := h.RefundUsecase.GetRefunds(r.Context(), status)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, refunds)
+}
+
+func (h *refundHandler) ApproveRefund(w http.ResponseWriter, r *http.Request) {
+	refundId, err := strconv.Atoi(r.PathValue("refundId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid refund ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	refund, err := h.RefundUsecase.ApproveRefund(r.Context(), refundId, userID)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, refund)
+}
+
+func (h *refundHandler) RejectRefund(w http.ResponseWriter, r *http.Request) {
+	refundId, err := strconv.Atoi(r.PathValue("refundId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid refund ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var rejectReq dto.RejectRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&rejectReq); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	refund, err := h.RefundUsecase.RejectRefund(r.Context(), refundId, userID, rejectReq.Note)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, refund)
+}
+
+// GetSalesReport sums sales and refunds between from and to (RFC 3339 or
+// YYYY-MM-DD), the last 30 days by default.
+func (h *refundHandler) GetSalesReport(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	for name, dest := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, value)
+		}
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid "+name+" date")
+			return
+		}
+		*dest = t
+	}
+
+	report, err := h.RefundUsecase.GetSalesReport(r.Context(), from, to)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, report)
+}
+
+func writeRefundError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrRefundNotAllowed), errors.Is(err, models.ErrPaymentConflict):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrGatewayFailure):
+		utils.WriteErrorResponse(w, http.StatusBadGateway, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
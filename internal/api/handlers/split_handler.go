@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type splitHandler struct {
+	SplitUsecase   usecases.SplitIUsecase
+	PaymentUsecase usecases.PaymentIUsecase
+}
+
+func NewSplitHandler(splitUsecase usecases.SplitIUsecase, paymentUsecase usecases.PaymentIUsecase) *splitHandler {
+	return &splitHandler{
+		SplitUsecase:   splitUsecase,
+		PaymentUsecase: paymentUsecase,
+	}
+}
+
+func (h *splitHandler) PreviewSplit(w http.ResponseWriter, r *http.Request) {
+	h.split(w, r, false)
+}
+
+func (h *splitHandler) SplitOrder(w http.ResponseWriter, r *http.Request) {
+	h.split(w, r, true)
+}
+
+func (h *splitHandler) split(w http.ResponseWriter, r *http.Request, save bool) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var splitReq dto.SplitOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&splitReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	var checks []models.Check
+	if save {
+		checks, err = h.SplitUsecase.SplitOrder(r.Context(), orderId, userID, dto.ToSplitModel(splitReq))
+	} else {
+		checks, err = h.SplitUsecase.PreviewSplit(r.Context(), orderId, userID, dto.ToSplitModel(splitReq))
+	}
+	if err != nil {
+		writeSplitError(w, err)
+		return
+	}
+
+	if save {
+		utils.WriteJSONResponse(w, http.StatusCreated, checks)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, checks)
+}
+
+func (h *splitHandler) GetOrderChecks(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	checks, err := h.SplitUsecase.GetOrderChecks(r.Context(), orderId, userID)
+	if err != nil {
+		writeSplitError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, checks)
+}
+
+func (h *splitHandler) AdminGetOrderChecks(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	checks, err := h.SplitUsecase.GetOrderChecks(r.Context(), orderId, 0)
+	if err != nil {
+		writeSplitError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, checks)
+}
+
+func (h *splitHandler) MergeChecks(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.SplitUsecase.MergeChecks(r.Context(), orderId, userID); err != nil {
+		writeSplitError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Checks merged", nil)
+}
+
+func (h *splitHandler) PayCheck(w http.ResponseWriter, r *http.Request) {
+	orderId, err := strconv.Atoi(r.PathValue("orderId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid order ID format")
+		return
+	}
+	checkId, err := strconv.Atoi(r.PathValue("checkId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid check ID format")
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var paymentReq dto.CreatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&paymentReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	payment, err := h.PaymentUsecase.CreatePayment(r.Context(), models.PaymentRequest{
+		OrderID:        orderId,
+		CheckID:        checkId,
+		UserID:         userID,
+		Amount:         paymentReq.Amount,
+		Source:         paymentReq.Source,
+		AuthorizeOnly:  paymentReq.AuthorizeOnly,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	if payment.Status == models.PaymentStatusFailed {
+		utils.WriteJSONResponse(w, http.StatusPaymentRequired, payment)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusCreated, payment)
+}
+
+func writeSplitError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
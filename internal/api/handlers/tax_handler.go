@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type taxHandler struct {
+	TaxUsecase usecases.TaxIUsecase
+}
+
+func NewTaxHandler(taxUsecase usecases.TaxIUsecase) *taxHandler {
+	return &taxHandler{
+		TaxUsecase: taxUsecase,
+	}
+}
+
+func (h *taxHandler) GetTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.TaxUsecase.GetTaxRates(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, rates)
+}
+
+func (h *taxHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var rateReq dto.CreateTaxRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&rateReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	rateId, err := h.TaxUsecase.CreateTaxRate(r.Context(), dto.ToTaxRateModel(rateReq))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Tax rate created successfully", map[string]int{"taxRateId": rateId})
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/api/dto"
@@ -15,12 +14,16 @@ import (
 type userHandler struct {
 	UserUsecase  usecases.UserIUsecase
 	TableUsecase usecases.TableIUsecase
+	secretKey    string        // signs the tokens issued at login
+	tokenTTL     time.Duration // how long they stay valid
 }
 
-func NewUserHandler(userUsecase usecases.UserIUsecase, tableUsecase usecases.TableIUsecase) *userHandler {
+func NewUserHandler(userUsecase usecases.UserIUsecase, tableUsecase usecases.TableIUsecase, secretKey string, tokenTTL time.Duration) *userHandler {
 	return &userHandler{
 		UserUsecase:  userUsecase,
 		TableUsecase: tableUsecase,
+		secretKey:    secretKey,
+		tokenTTL:     tokenTTL,
 	}
 }
 
@@ -45,9 +48,7 @@ func (h *userHandler) UserLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-
-	token, _, err := auth.CreateToken(secretKey, user.Role, user.ID, time.Now().UTC().Sub(time.Time{}), time.Hour*24)
+	token, _, err := auth.CreateToken(h.secretKey, user.Role, user.ID, h.tokenTTL, nil)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/api/dto"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/usecases"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+type webhookHandler struct {
+	WebhookUsecase usecases.WebhookIUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecases.WebhookIUsecase) *webhookHandler {
+	return &webhookHandler{
+		WebhookUsecase: webhookUsecase,
+	}
+}
+
+func (h *webhookHandler) GetEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.WebhookUsecase.GetEndpoints(r.Context())
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, endpoints)
+}
+
+// CreateEndpoint registers an endpoint. The response carries its signing
+// secret, which is not shown again.
+func (h *webhookHandler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	var webhookReq dto.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	endpoint, err := h.WebhookUsecase.CreateEndpoint(r.Context(), webhookReq.ToWebhookEndpointModel())
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Webhook created successfully", endpoint)
+}
+
+func (h *webhookHandler) UpdateEndpoint(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(r.PathValue("webhookId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid webhook ID format")
+		return
+	}
+
+	var webhookReq dto.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	endpoint, err := h.WebhookUsecase.SetEndpointActive(r.Context(), webhookId, webhookReq.Active)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusOK, "Webhook updated successfully", endpoint)
+}
+
+// GetDeliveries returns the delivery log of an endpoint, optionally only
+// ?status=dead and so on.
+func (h *webhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(r.PathValue("webhookId"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid webhook ID format")
+		return
+	}
+
+	status := models.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	deliveries, err := h.WebhookUsecase.GetDeliveries(r.Context(), webhookId, status)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, deliveries)
+}
+
+func (h *webhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryId, err := strconv.ParseInt(r.PathValue("deliveryId"), 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid delivery ID format")
+		return
+	}
+
+	if err := h.WebhookUsecase.RetryDelivery(r.Context(), deliveryId); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, http.StatusAccepted, "Delivery scheduled for retry", nil)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
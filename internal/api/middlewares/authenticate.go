@@ -4,14 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/abdullahnettoor/tastybites/internal/auth"
 	"github.com/abdullahnettoor/tastybites/internal/utils"
 )
 
-func Authenticate(next http.Handler) http.Handler {
+// Auth checks the bearer tokens of requests, which must be signed with the
+// secret tokens are issued with.
+type Auth struct {
+	secretKey string
+}
+
+func NewAuth(secretKey string) *Auth {
+	return &Auth{secretKey: secretKey}
+}
+
+func (a *Auth) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenStr := r.Header.Get("Authorization")
 		tokenParts := strings.Split(tokenStr, " ")
@@ -19,28 +28,56 @@ func Authenticate(next http.Handler) http.Handler {
 			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token format")
 			return
 		}
-		secretKey := os.Getenv("JWT_SECRET_KEY")
-		isValid, claims := auth.IsValidToken(secretKey, tokenParts[1])
-		if !isValid {
-			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token")
-			return
-		}
-		customClaims, ok := claims.(*auth.CustomClaims)
-		if !ok {
-			utils.WriteErrorResponse(w, http.StatusForbidden, "invalid token claims type")
+		a.serveWithClaims(w, r, next, tokenParts[1], false)
+	})
+}
+
+// AuthenticateStream is Authenticate for the event streams. Besides the
+// Authorization header it takes a stream ticket as ?ticket=, since browsers
+// cannot set headers on an EventSource or a WebSocket.
+func (a *Auth) AuthenticateStream(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			a.Authenticate(next).ServeHTTP(w, r)
 			return
 		}
-		role := customClaims.Role
-		userId := customClaims.UserId
-		if userId == 0 {
-			utils.WriteErrorResponse(w, http.StatusForbidden, "userId not found in token claims")
+		ticket := r.URL.Query().Get("ticket")
+		if ticket == "" {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token format")
 			return
 		}
-		fmt.Println("Claims:", customClaims)
-		newCtx := context.WithValue(r.Context(), "userId", userId)
-		newCtx = context.WithValue(newCtx, "role", role)
-		r = r.WithContext(newCtx)
-
-		next.ServeHTTP(w, r)
+		a.serveWithClaims(w, r, next, ticket, true)
 	})
 }
+
+// serveWithClaims checks the token and passes the request on with the user
+// and role it carries. Stream tickets are only accepted where ticket is set,
+// and only there.
+func (a *Auth) serveWithClaims(w http.ResponseWriter, r *http.Request, next http.Handler, token string, ticket bool) {
+	isValid, claims := auth.IsValidToken(a.secretKey, token)
+	if !isValid {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	customClaims, ok := claims.(*auth.CustomClaims)
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusForbidden, "invalid token claims type")
+		return
+	}
+	if customClaims.IsStreamTicket() != ticket {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	role := customClaims.Role
+	userId := customClaims.UserId
+	if userId == 0 {
+		utils.WriteErrorResponse(w, http.StatusForbidden, "userId not found in token claims")
+		return
+	}
+	fmt.Println("Claims:", customClaims)
+	newCtx := context.WithValue(r.Context(), "userId", userId)
+	newCtx = context.WithValue(newCtx, "role", role)
+	r = r.WithContext(newCtx)
+
+	next.ServeHTTP(w, r)
+}
@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/auth"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+const testSecret = "test-secret"
+
+// whoami answers with the user the middleware let through.
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	userId, err := utils.GetUserIDFromContext(r)
+	if err != nil {
+		w.WriteHeader(http.StatusTeapot)
+		return
+	}
+	role, _ := utils.GetRoleFromContext(r)
+	w.Header().Set("X-User", fmt.Sprintf("%d %s", userId, role))
+})
+
+func TestAuthenticateStream(t *testing.T) {
+	login, _, err := auth.CreateToken(testSecret, string(models.UserRoleUser), 3, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket, _, err := auth.CreateStreamTicket(testSecret, string(models.UserRoleUser), 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := auth.CreateStreamTicket(testSecret, string(models.UserRoleUser), 3, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, _, err := auth.CreateStreamTicket("other-secret", string(models.UserRoleUser), 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAuth(testSecret)
+	tests := []struct {
+		name   string
+		stream bool // AuthenticateStream rather than Authenticate
+		header string
+		query  string
+		want   int
+	}{
+		{"login token as bearer", false, "Bearer " + login, "", http.StatusOK},
+		{"ticket as bearer", false, "Bearer " + ticket, "", http.StatusUnauthorized},
+		{"ticket in query of an API route", false, "", "?ticket=" + ticket, http.StatusUnauthorized},
+		{"stream, login token as bearer", true, "Bearer " + login, "", http.StatusOK},
+		{"stream, ticket as bearer", true, "Bearer " + ticket, "", http.StatusUnauthorized},
+		{"stream, ticket in query", true, "", "?ticket=" + ticket, http.StatusOK},
+		{"stream, login token in query", true, "", "?ticket=" + login, http.StatusUnauthorized},
+		{"stream, expired ticket", true, "", "?ticket=" + expired, http.StatusUnauthorized},
+		{"stream, forged ticket", true, "", "?ticket=" + forged, http.StatusUnauthorized},
+		{"stream, nothing", true, "", "", http.StatusUnauthorized},
+		{"stream, header wins over query", true, "Bearer nonsense", "?ticket=" + ticket, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		h := a.Authenticate(whoami)
+		if tt.stream {
+			h = a.AuthenticateStream(whoami)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusOK && w.Header().Get("X-User") != "3 "+string(models.UserRoleUser) {
+			t.Errorf("%s: passed on as %q", tt.name, w.Header().Get("X-User"))
+		}
+	}
+}
@@ -2,14 +2,13 @@ package middlewares
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/abdullahnettoor/tastybites/internal/auth"
 	"github.com/abdullahnettoor/tastybites/internal/utils"
 )
 
-func AuthorizeAdmin(next http.Handler) http.Handler {
+func (a *Auth) AuthorizeAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenStr := r.Header.Get("Authorization")
 		tokenParts := strings.Split(tokenStr, " ")
@@ -17,8 +16,7 @@ func AuthorizeAdmin(next http.Handler) http.Handler {
 			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token format")
 			return
 		}
-		secretKey := os.Getenv("JWT_SECRET_KEY")
-		isValid, claims := auth.IsValidToken(secretKey, tokenParts[1])
+		isValid, claims := auth.IsValidToken(a.secretKey, tokenParts[1])
 		if !isValid {
 			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token")
 			return
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/auth"
+	"github.com/abdullahnettoor/tastybites/internal/utils"
+)
+
+// AuthorizeManager lets managers and admins through.
+func (a *Auth) AuthorizeManager(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenStr := r.Header.Get("Authorization")
+		tokenParts := strings.Split(tokenStr, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" || tokenParts[1] == "" {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token format")
+			return
+		}
+		isValid, claims := auth.IsValidToken(a.secretKey, tokenParts[1])
+		if !isValid {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		customClaims, ok := claims.(*auth.CustomClaims)
+		if !ok {
+			utils.WriteErrorResponse(w, http.StatusForbidden, "invalid token claims type")
+			return
+		}
+		role := customClaims.Role
+		if role != "manager" && role != "admin" {
+			utils.WriteErrorResponse(w, http.StatusForbidden, "access denied: manager role required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
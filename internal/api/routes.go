@@ -5,6 +5,8 @@ import (
 
 	"github.com/abdullahnettoor/tastybites/internal/api/handlers"
 	"github.com/abdullahnettoor/tastybites/internal/api/middlewares"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/health"
 	"github.com/abdullahnettoor/tastybites/internal/usecases"
 )
 
@@ -38,34 +40,132 @@ func (app *application) InitializeRoutes(
 	orderUsecase usecases.OrderIUsecase,
 	menuUsecase usecases.MenuIUsecase,
 	tableUsecase usecases.TableIUsecase,
+	bundleUsecase usecases.BundleIUsecase,
+	promotionUsecase usecases.PromotionIUsecase,
+	taxUsecase usecases.TaxIUsecase,
+	paymentUsecase usecases.PaymentIUsecase,
+	splitUsecase usecases.SplitIUsecase,
+	refundUsecase usecases.RefundIUsecase,
+	receiptUsecase usecases.ReceiptIUsecase,
+	printUsecase usecases.PrintIUsecase,
+	kitchenUsecase usecases.KitchenIUsecase,
+	webhookUsecase usecases.WebhookIUsecase,
+	eventBus *events.Bus,
+	healthChecks *health.Registry,
 ) {
 
+	auth := middlewares.NewAuth(app.Config.JWTConfig.Secret)
 	publicGroup := app.NewRouteGroup()
-	adminGroup := app.NewRouteGroup(middlewares.Authenticate, middlewares.AuthorizeAdmin) // Admin protected routes
-	userGroup := app.NewRouteGroup(middlewares.Authenticate)                              // User protected routes
+	adminGroup := app.NewRouteGroup(auth.Authenticate, auth.AuthorizeAdmin)     // Admin protected routes
+	userGroup := app.NewRouteGroup(auth.Authenticate)                           // User protected routes
+	managerGroup := app.NewRouteGroup(auth.Authenticate, auth.AuthorizeManager) // Manager protected routes
+	kitchenGroup := app.NewRouteGroup(auth.Authenticate, auth.AuthorizeKitchen) // Kitchen display routes
+	streamGroup := app.NewRouteGroup(auth.AuthenticateStream)                   // Event streams, which also take a stream ticket
 
 	// Handlers
-	userHandler := handlers.NewUserHandler(userUsecase, tableUsecase)
+	userHandler := handlers.NewUserHandler(userUsecase, tableUsecase, app.Config.JWTConfig.Secret, app.Config.JWTConfig.TTL)
 	menuHandler := handlers.NewMenuHandler(menuUsecase)
 	orderHandler := handlers.NewOrderHandler(orderUsecase, userUsecase, tableUsecase)
+	bundleHandler := handlers.NewBundleHandler(bundleUsecase)
+	promotionHandler := handlers.NewPromotionHandler(promotionUsecase)
+	taxHandler := handlers.NewTaxHandler(taxUsecase)
+	paymentHandler := handlers.NewPaymentHandler(paymentUsecase)
+	splitHandler := handlers.NewSplitHandler(splitUsecase, paymentUsecase)
+	refundHandler := handlers.NewRefundHandler(refundUsecase)
+	receiptHandler := handlers.NewReceiptHandler(receiptUsecase)
+	printHandler := handlers.NewPrintHandler(printUsecase)
+	kitchenHandler := handlers.NewKitchenHandler(kitchenUsecase)
+	webhookHandler := handlers.NewWebhookHandler(webhookUsecase)
+	eventHandler := handlers.NewEventHandler(eventBus, app.Config.JWTConfig.Secret, app.Config.CORSConfig.AllowedOrigins)
+	healthHandler := handlers.NewHealthHandler(healthChecks)
+	metricsHandler := handlers.NewMetricsHandler()
 
 	// Public routes
 	publicGroup.HandleFunc("GET /ping", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("pong"))
 	})
+	publicGroup.HandleFunc("GET /healthz", healthHandler.Live)
+	publicGroup.HandleFunc("GET /readyz", healthHandler.Ready)
 	publicGroup.HandleFunc("POST /login", userHandler.UserLogin)
 	publicGroup.HandleFunc("POST /register", userHandler.UserRegister)
 	publicGroup.HandleFunc("GET /menu", menuHandler.GetAllMenuItems)
+	publicGroup.HandleFunc("GET /menu/search", menuHandler.SearchMenuItems)
 	publicGroup.HandleFunc("GET /tables", userHandler.GetAvailableTables)
+	publicGroup.HandleFunc("GET /images/{key...}", menuHandler.ServeImage)
+	publicGroup.HandleFunc("GET /bundles", bundleHandler.GetBundles)
+	publicGroup.HandleFunc("GET /bundles/{bundleId}", bundleHandler.GetBundle)
+	publicGroup.HandleFunc("POST /webhooks/payments/{provider}", paymentHandler.PaymentWebhook)
 
 	// Authenticated user routes
+	streamGroup.HandleFunc("GET /events", eventHandler.Stream)
+	streamGroup.HandleFunc("GET /ws", eventHandler.WebSocket)
+	userGroup.HandleFunc("POST /events/ticket", eventHandler.StreamTicket)
 	userGroup.HandleFunc("GET /orders", orderHandler.GetUserOrders)
 	userGroup.HandleFunc("POST /orders", orderHandler.CreateOrder)
+	userGroup.HandleFunc("POST /orders/{orderId}/coupon", orderHandler.ApplyCoupon)
+	userGroup.HandleFunc("DELETE /orders/{orderId}/coupon", orderHandler.RemoveCoupon)
+	userGroup.HandleFunc("GET /orders/{orderId}/payments", paymentHandler.GetOrderPayments)
+	userGroup.HandleFunc("POST /orders/{orderId}/payments", paymentHandler.CreatePayment)
+	userGroup.HandleFunc("POST /orders/{orderId}/split/preview", splitHandler.PreviewSplit)
+	userGroup.HandleFunc("POST /orders/{orderId}/split", splitHandler.SplitOrder)
+	userGroup.HandleFunc("DELETE /orders/{orderId}/split", splitHandler.MergeChecks)
+	userGroup.HandleFunc("GET /orders/{orderId}/checks", splitHandler.GetOrderChecks)
+	userGroup.HandleFunc("POST /orders/{orderId}/checks/{checkId}/payments", splitHandler.PayCheck)
+	userGroup.HandleFunc("GET /orders/{orderId}/receipt", receiptHandler.GetReceipt)
+	userGroup.HandleFunc("POST /orders/{orderId}/receipt/email", receiptHandler.EmailReceipt)
+	userGroup.HandleFunc("POST /orders/{orderId}/invoice", receiptHandler.IssueInvoice)
 
 	// Admin routes
 	adminGroup.HandleFunc("GET /admin/orders", orderHandler.AdminGetAllOrders)
+	adminGroup.HandleFunc("GET /admin/metrics", metricsHandler.Metrics)
 	adminGroup.HandleFunc("GET /admin/tables/", orderHandler.GetOrderByTableId)
 	adminGroup.HandleFunc("PATCH /admin/tables/{tableId}", orderHandler.UpdateTableStatus)
+	adminGroup.HandleFunc("GET /admin/menu/export", menuHandler.ExportMenu)
+	adminGroup.HandleFunc("POST /admin/menu/import", menuHandler.ImportMenu)
+	adminGroup.HandleFunc("POST /admin/menu/{itemId}/image", menuHandler.UploadMenuItemImage)
+	adminGroup.HandleFunc("GET /admin/menu/{itemId}/prices", menuHandler.GetPriceTimeline)
+	adminGroup.HandleFunc("POST /admin/menu/{itemId}/prices", menuHandler.ScheduleMenuItemPrice)
+	adminGroup.HandleFunc("DELETE /admin/menu/{itemId}/prices/{priceId}", menuHandler.CancelScheduledPrice)
+	adminGroup.HandleFunc("POST /admin/bundles", bundleHandler.CreateBundle)
+	adminGroup.HandleFunc("GET /admin/promotions", promotionHandler.GetPromotions)
+	adminGroup.HandleFunc("POST /admin/promotions", promotionHandler.CreatePromotion)
+	adminGroup.HandleFunc("PATCH /admin/promotions/{promotionId}", promotionHandler.UpdatePromotion)
+	adminGroup.HandleFunc("GET /admin/tax-rates", taxHandler.GetTaxRates)
+	adminGroup.HandleFunc("POST /admin/tax-rates", taxHandler.CreateTaxRate)
+	adminGroup.HandleFunc("GET /admin/orders/{orderId}/payments", paymentHandler.AdminGetOrderPayments)
+	adminGroup.HandleFunc("GET /admin/orders/{orderId}/checks", splitHandler.AdminGetOrderChecks)
+	adminGroup.HandleFunc("GET /admin/orders/{orderId}/refunds", refundHandler.GetOrderRefunds)
+	adminGroup.HandleFunc("POST /admin/orders/{orderId}/refunds", refundHandler.CreateRefund)
+	adminGroup.HandleFunc("GET /admin/orders/{orderId}/receipt", receiptHandler.AdminGetReceipt)
+	adminGroup.HandleFunc("POST /admin/orders/{orderId}/reprint", printHandler.Reprint)
+	adminGroup.HandleFunc("GET /admin/print-jobs", printHandler.GetPrintJobs)
+	adminGroup.HandleFunc("POST /admin/kds/stations", kitchenHandler.CreateStation)
+	adminGroup.HandleFunc("PUT /admin/kds/categories/{category}", kitchenHandler.RouteCategory)
+	adminGroup.HandleFunc("PUT /admin/menu/{itemId}/station", kitchenHandler.RouteMenuItem)
+	adminGroup.HandleFunc("POST /admin/orders/{orderId}/kitchen", kitchenHandler.SendToKitchen)
+	adminGroup.HandleFunc("GET /admin/webhooks", webhookHandler.GetEndpoints)
+	adminGroup.HandleFunc("POST /admin/webhooks", webhookHandler.CreateEndpoint)
+	adminGroup.HandleFunc("PATCH /admin/webhooks/{webhookId}", webhookHandler.UpdateEndpoint)
+	adminGroup.HandleFunc("GET /admin/webhooks/{webhookId}/deliveries", webhookHandler.GetDeliveries)
+	adminGroup.HandleFunc("POST /admin/webhook-deliveries/{deliveryId}/retry", webhookHandler.RetryDelivery)
+	adminGroup.HandleFunc("GET /admin/refunds", refundHandler.GetRefunds)
+	adminGroup.HandleFunc("GET /admin/reports/sales", refundHandler.GetSalesReport)
+	adminGroup.HandleFunc("POST /admin/payments/{paymentId}/capture", paymentHandler.CapturePayment)
+	adminGroup.HandleFunc("POST /admin/payments/{paymentId}/void", paymentHandler.VoidPayment)
+
+	// Manager routes
+	managerGroup.HandleFunc("GET /manager/refunds", refundHandler.GetRefunds)
+	managerGroup.HandleFunc("POST /manager/refunds/{refundId}/approve", refundHandler.ApproveRefund)
+	managerGroup.HandleFunc("POST /manager/refunds/{refundId}/reject", refundHandler.RejectRefund)
+
+	// Kitchen display routes
+	kitchenGroup.HandleFunc("GET /kds/stations", kitchenHandler.GetStations)
+	kitchenGroup.HandleFunc("GET /kds/stations/{stationId}/tickets", kitchenHandler.GetStationQueue)
+	kitchenGroup.HandleFunc("GET /kds/orders/{orderId}/tickets", kitchenHandler.GetOrderTickets)
+	kitchenGroup.HandleFunc("POST /kds/tickets/{ticketId}/bump", kitchenHandler.BumpTicket)
+	kitchenGroup.HandleFunc("POST /kds/tickets/{ticketId}/recall", kitchenHandler.RecallTicket)
+	kitchenGroup.HandleFunc("POST /kds/items/{itemId}/bump", kitchenHandler.BumpItem)
+	kitchenGroup.HandleFunc("POST /kds/items/{itemId}/recall", kitchenHandler.RecallItem)
 
 }
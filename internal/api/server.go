@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"strconv"
 
@@ -27,21 +29,44 @@ func NewApp(config *config.Config, repo interfaces.Repository) (*application, er
 
 	handler := middlewares.MiddlewareChain(
 		mux,
+		middlewares.LimitBody(int64(config.ServerConfig.MaxBodyBytes)),
 		middlewares.RecoverPanic,
-		middlewares.CORS,
+		middlewares.CORS(config.CORSConfig.AllowedOrigins),
 		middlewares.LogReq,
 	)
 
 	app.Server = &http.Server{
-		Addr:    config.ServerConfig.Host + ":" + strconv.Itoa(config.ServerConfig.Port),
-		Handler: handler,
+		Addr:              config.ServerConfig.Host + ":" + strconv.Itoa(config.ServerConfig.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: config.ServerConfig.ReadHeaderTimeout,
+		ReadTimeout:       config.ServerConfig.ReadTimeout,
+		WriteTimeout:      config.ServerConfig.WriteTimeout,
+		IdleTimeout:       config.ServerConfig.IdleTimeout,
+		MaxHeaderBytes:    config.ServerConfig.MaxHeaderBytes,
 	}
 
 	return app, nil
 }
 
-func (app *application) Start() error {
-	if err := app.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+// Listen takes the server's address, so that a port already in use is
+// reported before anything is served.
+func (app *application) Listen() (net.Listener, error) {
+	return net.Listen("tcp", app.Server.Addr)
+}
+
+// Serve answers requests on the listener until Shutdown.
+func (app *application) Serve(listener net.Listener) error {
+	if err := app.Server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for those in flight. The
+// connections still open when ctx is done are closed.
+func (app *application) Shutdown(ctx context.Context) error {
+	if err := app.Server.Shutdown(ctx); err != nil {
+		app.Server.Close()
 		return err
 	}
 	return nil
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -69,3 +70,32 @@ func IsValidToken(secretKey, tokenString string) (bool, interface{}) {
 		return false, nil
 	}
 }
+
+// StreamAudience marks a stream ticket, a short-lived token that opens the
+// event streams from browsers, which cannot send an Authorization header
+// with EventSource or WebSocket. It is passed in the query string, so it is
+// accepted there only and not in place of a login token.
+const StreamAudience = "events"
+
+// CreateStreamTicket issues a stream ticket for the user.
+func CreateStreamTicket(secretKey, role string, userId int, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := &CustomClaims{
+		Role:   role,
+		UserId: userId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Audience:  jwt.ClaimStrings{StreamAudience},
+		},
+	}
+	ticket, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return ticket, expiresAt, nil
+}
+
+// IsStreamTicket reports whether the claims are those of a stream ticket.
+func (c *CustomClaims) IsStreamTicket() bool {
+	return slices.Contains(c.Audience, StreamAudience)
+}
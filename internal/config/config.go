@@ -1,86 +1,169 @@
 package config
 
-import (
-	"os"
-	"strconv"
-)
+import "time"
 
+// Config is the whole configuration. Every setting has a key, the section
+// and field names joined by a dot as in "db.host", which it is set by in a
+// config file and on the command line, and most have an environment
+// variable. The default is in the default tag.
 type Config struct {
-	DBConfig     DBConfig
-	ServerConfig ServerConfig
+	DBConfig         DBConfig         `key:"db"`
+	ServerConfig     ServerConfig     `key:"server"`
+	JWTConfig        JWTConfig        `key:"jwt"`
+	CORSConfig       CORSConfig       `key:"cors"`
+	StorageConfig    StorageConfig    `key:"storage"`
+	PricingConfig    PricingConfig    `key:"pricing"`
+	PaymentsConfig   PaymentsConfig   `key:"payments"`
+	RestaurantConfig RestaurantConfig `key:"restaurant"`
+	MailConfig       MailConfig       `key:"mail"`
+	PrintingConfig   PrintingConfig   `key:"printing"`
+	EventsConfig     EventsConfig     `key:"events"`
+	WebhooksConfig   WebhooksConfig   `key:"webhooks"`
+
+	sources map[string]string // where each setting came from, by key
 }
 
 type DBConfig struct {
-	Driver string // e.g., "postgres", "mongodb", etc.
+	Driver string `key:"driver" env:"TASTYBITES_DB_DRIVER" default:"postgres"` // e.g., "postgres", "mongodb", etc.
 	// connection details
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Database string
+	Host     string `key:"host" env:"TASTYBITES_DB_HOST" default:"localhost"`
+	Port     int    `key:"port" env:"TASTYBITES_DB_PORT" default:"5432"`
+	Username string `key:"username" env:"TASTYBITES_DB_USERNAME"`
+	Password string `key:"password" env:"TASTYBITES_DB_PASSWORD" secret:"true"`
+	Database string `key:"database" env:"TASTYBITES_DB_DATABASE" default:"tastybites"`
+	Path     string `key:"path" env:"TASTYBITES_DB_PATH" default:"tastybites.db"` // database file of the sqlite driver
+
+	AutoMigrate bool `key:"autoMigrate" env:"TASTYBITES_DB_AUTO_MIGRATE" default:"false"` // apply pending migrations on startup (postgres)
+	Seed        bool `key:"seed" env:"TASTYBITES_DB_SEED" default:"false"`                // load the sample data into an empty database after migrating
+
+	// Connection pool (postgres). Connections are closed after their
+	// lifetime, or once idle that long while there are more than minConns
+	MaxConns        int           `key:"maxConns" env:"TASTYBITES_DB_MAX_CONNS,TASTYBITES_DB_MAX_OPEN_CONNS" default:"25"`
+	MinConns        int           `key:"minConns" env:"TASTYBITES_DB_MIN_CONNS" default:"0"`
+	ConnMaxLifetime time.Duration `key:"connMaxLifetime" env:"TASTYBITES_DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `key:"connMaxIdleTime" env:"TASTYBITES_DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	// How queries are sent (postgres): cache_statement prepares each one
+	// once per connection; cache_describe, describe_exec, exec and
+	// simple_protocol keep no prepared statements on the server, for
+	// poolers such as PgBouncer in transaction mode
+	StatementCacheMode string `key:"statementCacheMode" env:"TASTYBITES_DB_STATEMENT_CACHE_MODE" default:"cache_statement"`
+
+	// TLS (postgres), as libpq's sslmode and sslrootcert
+	SSLMode string `key:"sslMode" env:"TASTYBITES_DB_SSLMODE" default:"prefer"` // disable, allow, prefer, require, verify-ca or verify-full
+	CAFile  string `key:"caFile" env:"TASTYBITES_DB_CA_FILE"`                   // PEM certificates the server's is checked against
+
+	// How long startup keeps retrying a database that does not answer yet
+	// (postgres); 0 tries once
+	ConnectTimeout time.Duration `key:"connectTimeout" env:"TASTYBITES_DB_CONNECT_TIMEOUT" default:"30s"`
 }
 
 type ServerConfig struct {
-	Host string
-	Port int
+	Host string `key:"host" env:"TASTYBITES_SERVER_HOST" default:"localhost"`
+	Port int    `key:"port" env:"TASTYBITES_SERVER_PORT" default:"8080"`
+
+	// Limits on a single request; 0 means no limit. Event streams lift the
+	// write timeout for themselves
+	ReadHeaderTimeout time.Duration `key:"readHeaderTimeout" env:"TASTYBITES_SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	ReadTimeout       time.Duration `key:"readTimeout" env:"TASTYBITES_SERVER_READ_TIMEOUT" default:"15s"`
+	WriteTimeout      time.Duration `key:"writeTimeout" env:"TASTYBITES_SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `key:"idleTimeout" env:"TASTYBITES_SERVER_IDLE_TIMEOUT" default:"2m"`             // how long keep-alive connections wait for the next request
+	MaxHeaderBytes    int           `key:"maxHeaderBytes" env:"TASTYBITES_SERVER_MAX_HEADER_BYTES" default:"1048576"` // 0 for Go's default of 1 MiB
+	MaxBodyBytes      int           `key:"maxBodyBytes" env:"TASTYBITES_SERVER_MAX_BODY_BYTES" default:"8388608"`     // larger bodies are refused with 413
+
+	// How long a shutdown waits for requests and background work in flight
+	ShutdownTimeout time.Duration `key:"shutdownTimeout" env:"TASTYBITES_SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+	// How long each check of /healthz and /readyz may take before it fails
+	HealthCheckTimeout time.Duration `key:"healthCheckTimeout" env:"TASTYBITES_SERVER_HEALTH_CHECK_TIMEOUT" default:"2s"`
 }
 
-func LoadConfig() (*Config, error) {
+// JWTConfig signs the tokens handed out at login.
+type JWTConfig struct {
+	Secret string        `key:"secret" env:"TASTYBITES_JWT_SECRET,JWT_SECRET_KEY" secret:"true"`
+	TTL    time.Duration `key:"ttl" env:"TASTYBITES_JWT_TTL" default:"24h"` // how long a token stays valid
+}
 
-	dbDriver := os.Getenv("TASTYBITES_DB_DRIVER")
-	if dbDriver == "" {
-		dbDriver = "postgres" // Default value if not set
-	}
-	dbHost := os.Getenv("TASTYBITES_DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost" // Default value if not set
-	}
-	dbPortStr := os.Getenv("TASTYBITES_DB_PORT")
-	if dbPortStr == "" {
-		dbPortStr = "5432" // Default value if not set
-	}
-	dbPort, err := strconv.Atoi(dbPortStr)
-	if err != nil {
-		return nil, err // Return error if conversion fails
-	}
-	dbUsername := os.Getenv("TASTYBITES_DB_USERNAME")
-	if dbUsername == "" {
-		dbUsername = "postgres" // Default value if not set
-	}
-	dbPassword := os.Getenv("TASTYBITES_DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = "postgres" // Default value if not set
-	}
-	dbDatabase := os.Getenv("TASTYBITES_DB_DATABASE")
-	if dbDatabase == "" {
-		dbDatabase = "tastybites" // Default value if not set
-	}
-	serverHost := os.Getenv("TASTYBITES_SERVER_HOST")
-	if serverHost == "" {
-		serverHost = "localhost" // Default value if not set
-	}
-	serverPortStr := os.Getenv("TASTYBITES_SERVER_PORT")
-	if serverPortStr == "" {
-		serverPortStr = "8080" // Default value if not set
-	}
-	serverPort, err := strconv.Atoi(serverPortStr)
+// CORSConfig lists the browser origins allowed to call the API.
+type CORSConfig struct {
+	AllowedOrigins []string `key:"allowedOrigins" env:"TASTYBITES_CORS_ALLOWED_ORIGINS" default:"*"` // e.g. "https://tastybites.com", or "*" for any
+}
+
+type StorageConfig struct {
+	Driver  string `key:"driver" env:"TASTYBITES_STORAGE_DRIVER" default:"local"`      // e.g., "local"
+	Dir     string `key:"dir" env:"TASTYBITES_STORAGE_DIR" default:"./data/blobs"`     // root directory for the local driver
+	BaseURL string `key:"baseURL" env:"TASTYBITES_STORAGE_BASE_URL" default:"/images"` // public URL prefix blobs are served from
+}
+
+type PricingConfig struct {
+	PricesIncludeTax       bool   `key:"pricesIncludeTax" env:"TASTYBITES_PRICES_INCLUDE_TAX" default:"false"`          // menu prices already include tax
+	Rounding               string `key:"rounding" env:"TASTYBITES_ROUNDING" default:"half_up"`                          // half_up, half_even, down or up
+	ServiceChargePercent   string `key:"serviceChargePercent" env:"TASTYBITES_SERVICE_CHARGE_PERCENT" default:"10"`     // e.g. "12.5"
+	ServiceChargeMinGuests int    `key:"serviceChargeMinGuests" env:"TASTYBITES_SERVICE_CHARGE_MIN_GUESTS" default:"6"` // party size the service charge starts at
+}
+
+type PaymentsConfig struct {
+	Provider       string `key:"provider" env:"TASTYBITES_PAYMENTS_PROVIDER" default:"fake"`                                        // e.g., "fake"
+	Currency       string `key:"currency" env:"TASTYBITES_PAYMENTS_CURRENCY" default:"USD"`                                         // ISO 4217 code payments are taken in
+	WebhookSecret  string `key:"webhookSecret" env:"TASTYBITES_PAYMENTS_WEBHOOK_SECRET" default:"dev-webhook-secret" secret:"true"` // shared secret webhooks are signed with
+	FakeMode       string `key:"fakeMode" env:"TASTYBITES_PAYMENTS_FAKE_MODE" default:"approve"`                                    // approve, decline, error or async
+	FakeWebhookURL string `key:"fakeWebhookURL" env:"TASTYBITES_PAYMENTS_FAKE_WEBHOOK_URL"`                                         // where the fake gateway delivers async outcomes
+
+	// Refunds above this many cents need a manager's approval
+	RefundApprovalThreshold int `key:"refundApprovalThreshold" env:"TASTYBITES_REFUND_APPROVAL_THRESHOLD" default:"2000"`
+}
+
+// RestaurantConfig is printed on receipts and invoices.
+type RestaurantConfig struct {
+	Name                 string `key:"name" env:"TASTYBITES_RESTAURANT_NAME" default:"TastyBites"`
+	Address              string `key:"address" env:"TASTYBITES_RESTAURANT_ADDRESS"`
+	Phone                string `key:"phone" env:"TASTYBITES_RESTAURANT_PHONE"`
+	Email                string `key:"email" env:"TASTYBITES_RESTAURANT_EMAIL"`
+	TaxID                string `key:"taxId" env:"TASTYBITES_RESTAURANT_TAX_ID"`                                  // VAT or GST registration number
+	FiscalYearStartMonth int    `key:"fiscalYearStartMonth" env:"TASTYBITES_FISCAL_YEAR_START_MONTH" default:"1"` // 1 for January; invoice numbers restart every fiscal year
+
+	// IANA time zone the restaurant keeps its hours in, e.g. "Europe/London".
+	// Promotion hours, receipts and fiscal years follow it
+	Timezone string `key:"timezone" env:"TASTYBITES_TIMEZONE" default:"Local"`
+}
+
+// Location is the restaurant's time zone, or the machine's when it does not
+// load.
+func (c *RestaurantConfig) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
 	if err != nil {
-		return nil, err // Return error if conversion fails
+		return time.Local
 	}
+	return loc
+}
+
+type MailConfig struct {
+	Driver   string `key:"driver" env:"TASTYBITES_MAIL_DRIVER" default:"log"` // "log" or "smtp"
+	Host     string `key:"host" env:"TASTYBITES_SMTP_HOST" default:"localhost"`
+	Port     int    `key:"port" env:"TASTYBITES_SMTP_PORT" default:"587"`
+	Username string `key:"username" env:"TASTYBITES_SMTP_USERNAME"`
+	Password string `key:"password" env:"TASTYBITES_SMTP_PASSWORD" secret:"true"`
+	From     string `key:"from" env:"TASTYBITES_MAIL_FROM" default:"receipts@tastybites.local"`
+}
+
+// PrintingConfig names the thermal printers. A kitchen station prints on
+// the printer with its name, or on the default printer.
+type PrintingConfig struct {
+	Printers       string        `key:"printers" env:"TASTYBITES_PRINTERS" default:"kitchen=file://./data/prints/kitchen.bin,receipt=file://./data/prints/receipt.bin"` // name=address pairs, e.g. "kitchen=tcp://10.0.0.20:9100,bar=file://./data/prints/bar.bin"
+	DefaultPrinter string        `key:"defaultPrinter" env:"TASTYBITES_DEFAULT_PRINTER" default:"kitchen"`                                                              // printer for stations without one of their own
+	ReceiptPrinter string        `key:"receiptPrinter" env:"TASTYBITES_RECEIPT_PRINTER" default:"receipt"`                                                              // printer for customer receipts
+	MaxAttempts    int           `key:"maxAttempts" env:"TASTYBITES_PRINT_MAX_ATTEMPTS" default:"5"`                                                                    // tries per job before it is marked failed
+	RetryDelay     time.Duration `key:"retryDelay" env:"TASTYBITES_PRINT_RETRY_DELAY" default:"2s"`                                                                     // wait before the first retry, doubled after each one, e.g. "2s"
+}
+
+// EventsConfig tunes the real-time event streams.
+type EventsConfig struct {
+	ReplaySize int           `key:"replaySize" env:"TASTYBITES_EVENTS_REPLAY_SIZE" default:"1000"` // events kept for clients resuming with Last-Event-ID
+	Heartbeat  time.Duration `key:"heartbeat" env:"TASTYBITES_EVENTS_HEARTBEAT" default:"15s"`     // keep-alive interval of idle streams, e.g. "15s"
+}
 
-	// Load configuration from environment variables or a config file
-	return &Config{
-		DBConfig: DBConfig{
-			Driver: dbDriver,
-			Host:   dbHost,
-			Port:   dbPort,
-			Username: dbUsername,
-			Password: dbPassword,
-			Database: dbDatabase,
-		},
-		ServerConfig: ServerConfig{
-			Host: serverHost,
-			Port: serverPort,
-		},
-	}, nil
+// WebhooksConfig tunes delivery of outbox events to webhook endpoints.
+type WebhooksConfig struct {
+	MaxAttempts  int           `key:"maxAttempts" env:"TASTYBITES_WEBHOOK_MAX_ATTEMPTS" default:"8"`    // tries per delivery before it is marked dead
+	RetryDelay   time.Duration `key:"retryDelay" env:"TASTYBITES_WEBHOOK_RETRY_DELAY" default:"30s"`    // wait before the first retry, doubled after each one, e.g. "30s"
+	PollInterval time.Duration `key:"pollInterval" env:"TASTYBITES_WEBHOOK_POLL_INTERVAL" default:"2s"` // how often the outbox and due deliveries are checked, e.g. "2s"
+	Timeout      time.Duration `key:"timeout" env:"TASTYBITES_WEBHOOK_TIMEOUT" default:"10s"`           // how long an endpoint has to answer, e.g. "10s"
 }
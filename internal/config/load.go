@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options says where LoadConfig looks for settings besides the defaults and
+// the environment.
+type Options struct {
+	File      string            // YAML or TOML config file; TASTYBITES_CONFIG when empty
+	Overrides map[string]string // settings by key, from the command line
+}
+
+// Sources of a setting besides the config file and environment variables.
+const (
+	SourceDefault  = "default"
+	SourceOverride = "-set"
+)
+
+// Redacted is shown instead of the value of a secret.
+const Redacted = "[redacted]"
+
+// setting is one field of the configuration and the ways it is set.
+type setting struct {
+	key    string
+	env    []string // the first one set wins; later ones are older names
+	def    string
+	secret bool
+	value  reflect.Value
+}
+
+// settings lists the fields of cfg in the order they are declared.
+func settings(cfg *Config) []setting {
+	var all []setting
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := range sections.NumField() {
+		section, ok := sections.Type().Field(i).Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		fields := sections.Field(i)
+		for j := range fields.NumField() {
+			field := fields.Type().Field(j)
+			name, ok := field.Tag.Lookup("key")
+			if !ok {
+				continue
+			}
+			s := setting{
+				key:    section + "." + name,
+				def:    field.Tag.Get("default"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  fields.Field(j),
+			}
+			if env := field.Tag.Get("env"); env != "" {
+				s.env = strings.Split(env, ",")
+			}
+			all = append(all, s)
+		}
+	}
+	return all
+}
+
+// LoadConfig builds the configuration from the defaults, the config file,
+// the environment and the overrides, each taking precedence over the ones
+// before, and validates it. Every problem found along the way is reported
+// at once, as Problems.
+func LoadConfig(opts Options) (*Config, error) {
+	cfg := &Config{sources: make(map[string]string)}
+	all := settings(cfg)
+	byKey := make(map[string]setting, len(all))
+	var problems Problems
+	failed := make(map[string]bool) // keys that could not be set, not to be validated
+	set := func(s setting, raw, source string) {
+		if err := setValue(s.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (from %s): %v", s.key, source, err))
+			failed[s.key] = true
+			return
+		}
+		cfg.sources[s.key] = source
+	}
+
+	for _, s := range all {
+		byKey[s.key] = s
+		set(s, s.def, SourceDefault)
+	}
+
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("TASTYBITES_CONFIG")
+	}
+	if file != "" {
+		values, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			s, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s (from %s): unknown setting", key, file))
+				continue
+			}
+			set(s, values[key], file)
+		}
+	}
+
+	for _, s := range all {
+		raw, source, err := lookupEnv(s.env)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", s.key, err))
+			failed[s.key] = true
+		} else if source != "" {
+			set(s, raw, source)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(opts.Overrides)) {
+		s, ok := byKey[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s (from %s): unknown setting", key, SourceOverride))
+			continue
+		}
+		set(s, opts.Overrides[key], SourceOverride)
+	}
+
+	problems = append(problems, cfg.validate(failed)...)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return cfg, nil
+}
+
+// lookupEnv finds the first of the variables that is set, either directly
+// or through a NAME_FILE variable naming a file that holds the value, as
+// secrets are usually mounted into containers. An empty variable counts as
+// not set.
+func lookupEnv(names []string) (value, source string, err error) {
+	for _, name := range names {
+		value, path := os.Getenv(name), os.Getenv(name+"_FILE")
+		switch {
+		case value != "" && path != "":
+			return "", "", fmt.Errorf("set either %s or %s_FILE, not both", name, name)
+		case value != "":
+			return value, name, nil
+		case path != "":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", "", fmt.Errorf("%s_FILE: %w", name, err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), name + "_FILE", nil
+		}
+	}
+	return "", "", nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, want true or false", raw)
+		}
+		v.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, want a number with a unit such as 30s or 5m", raw)
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case []string:
+		return strings.Join(x, ",")
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// Setting is one setting as loaded.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // "default", the config file, the environment variable or "-set"
+}
+
+// Settings lists every setting in the order of the sections. With redact,
+// the secrets that are set show as Redacted.
+func (c *Config) Settings(redact bool) []Setting {
+	all := settings(c)
+	list := make([]Setting, len(all))
+	for i, s := range all {
+		value := formatValue(s.value)
+		if redact && s.secret && value != "" {
+			value = Redacted
+		}
+		list[i] = Setting{Key: s.key, Value: value, Source: c.sources[s.key]}
+	}
+	return list
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zones for machines without a zoneinfo database
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Problems lists everything wrong with the configuration, so that it can
+// all be fixed in one go.
+type Problems []string
+
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0]
+	}
+	return fmt.Sprintf("%d problems:\n  %s", len(p), strings.Join(p, "\n  "))
+}
+
+// minSecretLength is the shortest JWT secret accepted.
+const minSecretLength = 16
+
+// validate checks the settings against each other and the values they can
+// take, except the failed ones that could not be set at all. Components
+// check what only they can, such as the printer addresses, when they are
+// built.
+func (c *Config) validate(failed map[string]bool) Problems {
+	var problems Problems
+	check := func(ok bool, key, format string, args ...any) {
+		if ok || failed[key] {
+			return
+		}
+		if source := c.sources[key]; source != "" && source != SourceDefault {
+			key += " (from " + source + ")"
+		}
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+	}
+	port := func(key string, port int) {
+		check(port >= 1 && port <= 65535, key, "must be between 1 and 65535, got %d", port)
+	}
+	notNegative := func(key string, n int64) {
+		check(n >= 0, key, "must not be negative")
+	}
+
+	db := c.DBConfig
+	oneOf("db.driver", db.Driver, "postgres", "sqlite", "memory")
+	switch db.Driver {
+	case "postgres":
+		check(db.Host != "", "db.host", "is required")
+		port("db.port", db.Port)
+		check(db.Username != "", "db.username", "is required")
+		check(db.Database != "", "db.database", "is required")
+		check(db.MaxConns >= 1, "db.maxConns", "must be at least 1, got %d", db.MaxConns)
+		notNegative("db.minConns", int64(db.MinConns))
+		check(db.MinConns <= db.MaxConns, "db.minConns", "must not be more than db.maxConns (%d)", db.MaxConns)
+		check(db.ConnMaxLifetime > 0, "db.connMaxLifetime", "must be positive")
+		check(db.ConnMaxIdleTime > 0, "db.connMaxIdleTime", "must be positive")
+		oneOf("db.statementCacheMode", db.StatementCacheMode, "cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol")
+		oneOf("db.sslMode", db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+		if db.CAFile != "" {
+			_, err := os.Stat(db.CAFile)
+			check(err == nil, "db.caFile", "%v", err)
+		}
+		notNegative("db.connectTimeout", int64(db.ConnectTimeout))
+	case "sqlite":
+		check(db.Path != "", "db.path", "is required")
+	}
+
+	server := c.ServerConfig
+	port("server.port", server.Port)
+	notNegative("server.readHeaderTimeout", int64(server.ReadHeaderTimeout))
+	notNegative("server.readTimeout", int64(server.ReadTimeout))
+	notNegative("server.writeTimeout", int64(server.WriteTimeout))
+	notNegative("server.idleTimeout", int64(server.IdleTimeout))
+	notNegative("server.maxHeaderBytes", int64(server.MaxHeaderBytes))
+	notNegative("server.maxBodyBytes", int64(server.MaxBodyBytes))
+	check(server.ShutdownTimeout > 0, "server.shutdownTimeout", "must be positive")
+	check(server.HealthCheckTimeout > 0, "server.healthCheckTimeout", "must be positive")
+
+	jwt := c.JWTConfig
+	if jwt.Secret == "" {
+		check(false, "jwt.secret", "is required, set TASTYBITES_JWT_SECRET or TASTYBITES_JWT_SECRET_FILE")
+	} else {
+		check(len(jwt.Secret) >= minSecretLength, "jwt.secret", "must be at least %d characters", minSecretLength)
+	}
+	check(jwt.TTL > 0, "jwt.ttl", "must be positive")
+
+	for _, origin := range c.CORSConfig.AllowedOrigins {
+		check(validOrigin(origin), "cors.allowedOrigins", "%q is not an origin such as https://tastybites.com, or *", origin)
+	}
+
+	oneOf("storage.driver", c.StorageConfig.Driver, "local")
+	check(c.StorageConfig.Dir != "", "storage.dir", "is required")
+
+	pricing := c.PricingConfig
+	_, err := models.ParseRoundingMode(pricing.Rounding)
+	check(err == nil, "pricing.rounding", "must be one of half_up, half_even, down or up, got %q", pricing.Rounding)
+	percent, err := strconv.ParseFloat(pricing.ServiceChargePercent, 64)
+	check(err == nil && percent >= 0 && percent <= 100, "pricing.serviceChargePercent",
+		"must be a percentage between 0 and 100, got %q", pricing.ServiceChargePercent)
+	notNegative("pricing.serviceChargeMinGuests", int64(pricing.ServiceChargeMinGuests))
+
+	payments := c.PaymentsConfig
+	oneOf("payments.provider", payments.Provider, "fake")
+	check(len(payments.Currency) == 3 && strings.ToUpper(payments.Currency) == payments.Currency, "payments.currency",
+		"must be a three letter ISO 4217 code such as USD, got %q", payments.Currency)
+	check(payments.WebhookSecret != "", "payments.webhookSecret", "is required")
+	if payments.Provider == "fake" {
+		oneOf("payments.fakeMode", payments.FakeMode, "approve", "decline", "error", "async")
+	}
+	if payments.FakeWebhookURL != "" {
+		u, err := url.Parse(payments.FakeWebhookURL)
+		check(err == nil && u.Host != "", "payments.fakeWebhookURL", "must be an absolute URL, got %q", payments.FakeWebhookURL)
+	}
+	notNegative("payments.refundApprovalThreshold", int64(payments.RefundApprovalThreshold))
+
+	restaurant := c.RestaurantConfig
+	check(restaurant.Name != "", "restaurant.name", "is required")
+	check(restaurant.FiscalYearStartMonth >= 1 && restaurant.FiscalYearStartMonth <= 12, "restaurant.fiscalYearStartMonth",
+		"must be between 1 and 12, got %d", restaurant.FiscalYearStartMonth)
+	_, err = time.LoadLocation(restaurant.Timezone)
+	check(err == nil, "restaurant.timezone", "unknown time zone %q, want a name such as Europe/London", restaurant.Timezone)
+
+	mail := c.MailConfig
+	oneOf("mail.driver", mail.Driver, "log", "smtp")
+	if mail.Driver == "smtp" {
+		check(mail.Host != "", "mail.host", "is required")
+		port("mail.port", mail.Port)
+	}
+	check(strings.Contains(mail.From, "@"), "mail.from", "must be an email address, got %q", mail.From)
+
+	check(c.PrintingConfig.MaxAttempts >= 1, "printing.maxAttempts", "must be at least 1, got %d", c.PrintingConfig.MaxAttempts)
+	check(c.PrintingConfig.RetryDelay > 0, "printing.retryDelay", "must be positive")
+
+	check(c.EventsConfig.ReplaySize >= 1, "events.replaySize", "must be at least 1, got %d", c.EventsConfig.ReplaySize)
+	check(c.EventsConfig.Heartbeat > 0, "events.heartbeat", "must be positive")
+
+	webhooks := c.WebhooksConfig
+	check(webhooks.MaxAttempts >= 1, "webhooks.maxAttempts", "must be at least 1, got %d", webhooks.MaxAttempts)
+	check(webhooks.RetryDelay > 0, "webhooks.retryDelay", "must be positive")
+	check(webhooks.PollInterval > 0, "webhooks.pollInterval", "must be positive")
+	check(webhooks.Timeout > 0, "webhooks.timeout", "must be positive")
+	return problems
+}
+
+// validOrigin accepts "*" or a scheme and host without a path.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == ""
+}
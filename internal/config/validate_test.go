@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validOverrides are the settings without a usable default.
+func validOverrides() map[string]string {
+	return map[string]string{
+		"db.username":            "tastybites",
+		"jwt.secret":             "0123456789abcdef",
+		"payments.webhookSecret": "whsec_test",
+	}
+}
+
+func TestLoadConfigDurations(t *testing.T) {
+	overrides := validOverrides()
+	overrides["webhooks.timeout"] = "1m30s"
+	cfg, err := LoadConfig(Options{Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  string
+		got  time.Duration
+		want time.Duration
+	}{
+		{"printing.retryDelay", cfg.PrintingConfig.RetryDelay, 2 * time.Second},
+		{"events.heartbeat", cfg.EventsConfig.Heartbeat, 15 * time.Second},
+		{"webhooks.retryDelay", cfg.WebhooksConfig.RetryDelay, 30 * time.Second},
+		{"webhooks.pollInterval", cfg.WebhooksConfig.PollInterval, 2 * time.Second},
+		{"webhooks.timeout", cfg.WebhooksConfig.Timeout, 90 * time.Second},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.key, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigProblems(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       string
+	}{
+		{"printing.retryDelay", "0s", "printing.retryDelay (from -set): must be positive"},
+		{"printing.retryDelay", "soon", "printing.retryDelay (from -set): invalid duration"},
+		{"events.heartbeat", "-15s", "events.heartbeat (from -set): must be positive"},
+		{"webhooks.retryDelay", "0", "webhooks.retryDelay (from -set): must be positive"},
+		{"webhooks.pollInterval", "2", "webhooks.pollInterval (from -set): invalid duration"},
+		{"webhooks.timeout", "-1s", "webhooks.timeout (from -set): must be positive"},
+		{"webhooks.maxAttempts", "0", "webhooks.maxAttempts (from -set): must be at least 1"},
+	}
+	for _, tt := range tests {
+		overrides := validOverrides()
+		overrides[tt.key] = tt.value
+		_, err := LoadConfig(Options{Overrides: overrides})
+		var problems Problems
+		if !errors.As(err, &problems) {
+			t.Errorf("%s=%s: LoadConfig() error = %v, want Problems", tt.key, tt.value, err)
+			continue
+		}
+		if len(problems) != 1 || !strings.HasPrefix(problems[0], tt.want) {
+			t.Errorf("%s=%s: problems = %q, want one starting with %q", tt.key, tt.value, problems, tt.want)
+		}
+	}
+
+	// Everything is reported at once
+	overrides := validOverrides()
+	overrides["events.heartbeat"] = "0s"
+	overrides["webhooks.timeout"] = "later"
+	_, err := LoadConfig(Options{Overrides: overrides})
+	var problems Problems
+	if !errors.As(err, &problems) || len(problems) != 2 ||
+		!slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, "events.heartbeat") }) ||
+		!slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, "webhooks.timeout") }) {
+		t.Errorf("LoadConfig() error = %v, want problems with events.heartbeat and webhooks.timeout", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package escpos builds byte streams for ESC/POS thermal printers. Text is
+// sent in Windows-1252, which most printers support as a code page.
+package escpos
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	esc = 0x1b
+	gs  = 0x1d
+)
+
+type Align byte
+
+const (
+	AlignLeft   Align = 0
+	AlignCenter Align = 1
+	AlignRight  Align = 2
+)
+
+// Size is a character size for SetSize.
+type Size byte
+
+const (
+	SizeNormal       Size = 0x00
+	SizeDoubleHeight Size = 0x01
+	SizeDouble       Size = 0x11 // double width and height, half the columns
+)
+
+// codePage1252 selects WPC1252 on Epson compatible printers.
+const codePage1252 = 16
+
+// Builder accumulates printer commands and text.
+type Builder struct {
+	buf bytes.Buffer
+}
+
+// NewBuilder starts a document by resetting the printer and selecting the
+// code page.
+func NewBuilder() *Builder {
+	b := &Builder{}
+	b.buf.Write([]byte{esc, '@', esc, 't', codePage1252})
+	return b
+}
+
+func (b *Builder) Align(a Align) *Builder {
+	b.buf.Write([]byte{esc, 'a', byte(a)})
+	return b
+}
+
+func (b *Builder) Bold(on bool) *Builder {
+	b.buf.Write([]byte{esc, 'E', boolByte(on)})
+	return b
+}
+
+func (b *Builder) SetSize(s Size) *Builder {
+	b.buf.Write([]byte{gs, '!', byte(s)})
+	return b
+}
+
+// Line prints text followed by a line feed.
+func (b *Builder) Line(text string) *Builder {
+	b.buf.Write(encode(text))
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Feed advances the paper by n lines.
+func (b *Builder) Feed(n int) *Builder {
+	b.buf.Write([]byte{esc, 'd', byte(min(max(n, 0), 255))})
+	return b
+}
+
+// Cut feeds past the cutter and does a partial cut.
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{gs, 'V', 66, 3})
+	return b
+}
+
+// Beep sounds the buzzer of kitchen printers that have one.
+func (b *Builder) Beep(times int) *Builder {
+	b.buf.Write([]byte{esc, 'B', byte(min(max(times, 1), 9)), 2})
+	return b
+}
+
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func boolByte(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+// cp1252 holds the Windows-1252 characters outside Latin-1.
+var cp1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts text to Windows-1252, replacing control characters and
+// anything the code page lacks.
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range strings.ReplaceAll(text, "\n", " ") {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case cp1252[r] != 0:
+			out = append(out, cp1252[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package escpos
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	got := NewBuilder().
+		Align(AlignCenter).
+		SetSize(SizeDouble).
+		Bold(true).
+		Line("GRILL").
+		Bold(false).
+		Feed(3).
+		Cut().
+		Beep(2).
+		Bytes()
+
+	want := []byte{
+		esc, '@', esc, 't', 16, // reset, code page 1252
+		esc, 'a', 1, // center
+		gs, '!', 0x11, // double size
+		esc, 'E', 1, // bold on
+		'G', 'R', 'I', 'L', 'L', '\n',
+		esc, 'E', 0, // bold off
+		esc, 'd', 3, // feed 3 lines
+		gs, 'V', 66, 3, // partial cut
+		esc, 'B', 2, 2, // beep twice
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Bytes() = % x\nwant      % x", got, want)
+	}
+}
+
+func TestBuilderClampsArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *Builder
+		want []byte
+	}{
+		{"negative feed", (&Builder{}).Feed(-2), []byte{esc, 'd', 0}},
+		{"long feed", (&Builder{}).Feed(1000), []byte{esc, 'd', 255}},
+		{"no beeps", (&Builder{}).Beep(0), []byte{esc, 'B', 1, 2}},
+		{"many beeps", (&Builder{}).Beep(20), []byte{esc, 'B', 9, 2}},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Bytes(); !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: % x, want % x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text string
+		want []byte
+	}{
+		{"Burger x2", []byte("Burger x2")},
+		{"Crème brûlée", []byte{'C', 'r', 0xe8, 'm', 'e', ' ', 'b', 'r', 0xfb, 'l', 0xe9, 'e'}},
+		{"€5 – “hot”", []byte{0x80, '5', ' ', 0x96, ' ', 0x93, 'h', 'o', 't', 0x94}},
+		{"no\nonions", []byte("no onions")},  // one line per Line call
+		{"tab\there", []byte("tab?here")},    // control characters
+		{"寿司 🍣", []byte{'?', '?', ' ', '?'}}, // outside the code page
+	}
+	for _, tt := range tests {
+		if got := encode(tt.text); !bytes.Equal(got, tt.want) {
+			t.Errorf("encode(%q) = % x, want % x", tt.text, got, tt.want)
+		}
+	}
+}
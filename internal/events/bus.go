@@ -0,0 +1,180 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+// subscriberBuffer is how far a subscriber may fall behind before it is
+// dropped. A dropped client reconnects and catches up from the replay buffer.
+const subscriberBuffer = 64
+
+// Bus fans published events out to subscribers and keeps the latest ones
+// for replay. It is safe for concurrent use.
+type Bus struct {
+	heartbeat time.Duration
+
+	mu     sync.Mutex
+	lastID uint64
+	replay []Event // ring of the latest events, oldest at next once full
+	next   int
+	full   bool
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBus(cfg *config.EventsConfig) (*Bus, error) {
+	if cfg.ReplaySize < 1 {
+		return nil, fmt.Errorf("event replay size must be at least 1, got %d", cfg.ReplaySize)
+	}
+	if cfg.Heartbeat <= 0 {
+		return nil, fmt.Errorf("event heartbeat must be positive, got %s", cfg.Heartbeat)
+	}
+	return &Bus{
+		heartbeat: cfg.Heartbeat,
+		replay:    make([]Event, cfg.ReplaySize),
+		subs:      make(map[*Subscription]struct{}),
+	}, nil
+}
+
+// Heartbeat is how often idle streams send a keep-alive.
+func (b *Bus) Heartbeat() time.Duration {
+	return b.heartbeat
+}
+
+// Publish numbers the event and sends it to every subscriber allowed to see
+// it. It never blocks; subscribers that cannot keep up are dropped.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b.replay[b.next] = e
+	b.next = (b.next + 1) % len(b.replay)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for sub := range b.subs {
+		if !sub.principal.Allows(e) {
+			continue
+		}
+		select {
+		case sub.c <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe starts delivering events the principal may see. With a non-zero
+// lastEventID the retained events after it are delivered first; if some of
+// them are no longer retained the subscription reports Missed.
+func (b *Bus) Subscribe(p Principal, lastEventID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	missed := false
+	if lastEventID != 0 {
+		retained := b.retained()
+		switch {
+		case lastEventID > b.lastID:
+			// An id from before a restart; nothing after it is known
+			missed = true
+		case len(retained) > 0 && lastEventID < retained[0].ID-1:
+			missed = true
+		}
+		if !missed {
+			for _, e := range retained {
+				if e.ID > lastEventID && p.Allows(e) {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+	}
+
+	sub := &Subscription{
+		bus:       b,
+		principal: p,
+		c:         make(chan Event, len(backlog)+subscriberBuffer),
+		Missed:    missed,
+	}
+	sub.C = sub.c
+	for _, e := range backlog {
+		sub.c <- e
+	}
+	if b.closed {
+		close(sub.c)
+	} else {
+		b.subs[sub] = struct{}{}
+	}
+	return sub
+}
+
+// Close ends every subscription; later events are discarded.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// Check fails once the bus is closed.
+func (b *Bus) Check(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("event bus is closed")
+	}
+	return nil
+}
+
+// retained returns the replay buffer oldest first. The caller holds the lock.
+func (b *Bus) retained() []Event {
+	if !b.full {
+		return b.replay[:b.next]
+	}
+	return append(append([]Event(nil), b.replay[b.next:]...), b.replay[:b.next]...)
+}
+
+// drop removes a subscriber and closes its channel. The caller holds the lock.
+func (b *Bus) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Subscription receives events on C until it is closed, either by Close or
+// by the bus when the subscriber falls behind.
+type Subscription struct {
+	C <-chan Event
+	// Missed is set when events after the requested id were already
+	// discarded, so the client has to load the current state again.
+	Missed bool
+
+	bus       *Bus
+	principal Principal
+	c         chan Event
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func newTestBus(t *testing.T, replaySize int) *Bus {
+	t.Helper()
+	b, err := NewBus(&config.EventsConfig{ReplaySize: replaySize, Heartbeat: 15 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+var admin = Principal{UserID: 1, Role: string(models.UserRoleAdmin)}
+
+// publish sends n public events.
+func publish(b *Bus, n int) {
+	for range n {
+		b.Publish(Event{Type: TableReset, Public: true})
+	}
+}
+
+// received drains what is waiting on the subscription.
+func received(sub *Subscription) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPublishNumbersAndDelivers(t *testing.T) {
+	b := newTestBus(t, 10)
+	sub := b.Subscribe(admin, 0)
+	defer sub.Close()
+
+	b.Publish(Event{Type: OrderCreated, UserID: 3})
+	b.Publish(Event{Type: TableReset, Public: true})
+
+	var got []Event
+	for range 2 {
+		got = append(got, <-sub.C)
+	}
+	if got[0].ID != 1 || got[1].ID != 2 || got[0].Type != OrderCreated || got[0].Time.IsZero() {
+		t.Errorf("received %+v", got)
+	}
+	if sub.Missed {
+		t.Error("a new subscription reports missed events")
+	}
+}
+
+func TestSubscribeReplays(t *testing.T) {
+	tests := []struct {
+		name        string
+		replaySize  int
+		published   int
+		lastEventID uint64
+		want        []uint64
+		missed      bool
+	}{
+		{"from the start", 10, 5, 0, nil, false},
+		{"after an id", 10, 5, 2, []uint64{3, 4, 5}, false},
+		{"up to date", 10, 5, 5, nil, false},
+		{"oldest retained is next", 3, 5, 2, []uint64{3, 4, 5}, false},
+		{"wrapped ring", 3, 7, 4, []uint64{5, 6, 7}, false},
+		{"fell out of the buffer", 3, 5, 1, nil, true},
+		{"id from before a restart", 10, 5, 99, nil, true},
+		{"id on an empty bus", 10, 0, 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBus(t, tt.replaySize)
+			publish(b, tt.published)
+			sub := b.Subscribe(admin, tt.lastEventID)
+			defer sub.Close()
+			if got := received(sub); !equalIDs(got, tt.want) || sub.Missed != tt.missed {
+				t.Errorf("Subscribe(%d) replayed %v, missed %v; want %v, missed %v", tt.lastEventID, got, sub.Missed, tt.want, tt.missed)
+			}
+
+			// Live events follow the replay
+			publish(b, 1)
+			if got := received(sub); len(got) != 1 || got[0] != uint64(tt.published+1) {
+				t.Errorf("live event %v after the replay", got)
+			}
+		})
+	}
+}
+
+func TestSubscribeReplaysOnlyAllowedEvents(t *testing.T) {
+	b := newTestBus(t, 10)
+	b.Publish(Event{Type: OrderCreated, UserID: 3})                    // 1
+	b.Publish(Event{Type: OrderCreated, UserID: 4})                    // 2
+	b.Publish(Event{Type: TicketCreated, Kitchen: true})               // 3
+	b.Publish(Event{Type: TableReset, Public: true})                   // 4
+	b.Publish(Event{Type: OrderStatusChanged, UserID: 3})              // 5
+	b.Publish(Event{Type: TicketUpdated, Kitchen: true, StationID: 2}) // 6
+
+	customer := b.Subscribe(Principal{UserID: 3, Role: string(models.UserRoleUser)}, 1)
+	defer customer.Close()
+	if got := received(customer); !equalIDs(got, []uint64{4, 5}) {
+		t.Errorf("customer replayed %v, want [4 5]", got)
+	}
+	kitchen := b.Subscribe(Principal{UserID: 9, Role: string(models.UserRoleKitchen), StationID: 1}, 1)
+	defer kitchen.Close()
+	if got := received(kitchen); !equalIDs(got, []uint64{3, 4}) {
+		t.Errorf("station 1 replayed %v, want [3 4]", got)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	customer := Principal{UserID: 3, Role: string(models.UserRoleUser)}
+	kitchen := Principal{UserID: 9, Role: string(models.UserRoleKitchen)}
+	grill := Principal{UserID: 9, Role: string(models.UserRoleKitchen), StationID: 2}
+	manager := Principal{UserID: 5, Role: string(models.UserRoleManager)}
+	adminAtGrill := Principal{UserID: 1, Role: string(models.UserRoleAdmin), StationID: 2}
+
+	ownOrder := Event{UserID: 3}
+	otherOrder := Event{UserID: 4}
+	anyTicket := Event{Kitchen: true}
+	grillTicket := Event{Kitchen: true, StationID: 2}
+	barTicket := Event{Kitchen: true, StationID: 3}
+	public := Event{Public: true}
+	nobodys := Event{}
+
+	tests := []struct {
+		name  string
+		p     Principal
+		e     Event
+		allow bool
+	}{
+		{"customer, own order", customer, ownOrder, true},
+		{"customer, other order", customer, otherOrder, false},
+		{"customer, ticket", customer, anyTicket, false},
+		{"customer, public", customer, public, true},
+		{"customer, no owner", customer, nobodys, false},
+		{"anonymous, no owner", Principal{}, nobodys, false},
+		{"kitchen, ticket", kitchen, anyTicket, true},
+		{"kitchen, station ticket", kitchen, barTicket, true},
+		{"kitchen, order", kitchen, ownOrder, false},
+		{"kitchen, public", kitchen, public, true},
+		{"station, own ticket", grill, grillTicket, true},
+		{"station, other station", grill, barTicket, false},
+		{"station, ticket of no station", grill, anyTicket, true},
+		{"manager, order", manager, otherOrder, true},
+		{"manager, ticket", manager, barTicket, true},
+		{"admin narrowed to a station", adminAtGrill, barTicket, false},
+		{"admin narrowed, order", adminAtGrill, otherOrder, true},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Allows(tt.e); got != tt.allow {
+			t.Errorf("%s: Allows() = %v, want %v", tt.name, got, tt.allow)
+		}
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	b := newTestBus(t, 1000)
+	slow := b.Subscribe(admin, 0)
+	fast := b.Subscribe(admin, 0)
+	defer fast.Close()
+
+	for i := range subscriberBuffer + 1 {
+		b.Publish(Event{Type: TableReset, Public: true})
+		if i < subscriberBuffer {
+			<-fast.C
+		}
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber got %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+	if e, ok := <-fast.C; !ok || e.ID != subscriberBuffer+1 {
+		t.Errorf("fast subscriber got %+v, %v", e, ok)
+	}
+
+	// It catches up by resuming from the last id it saw
+	again := b.Subscribe(admin, subscriberBuffer)
+	defer again.Close()
+	if got := received(again); len(got) != 1 || got[0] != subscriberBuffer+1 || again.Missed {
+		t.Errorf("resumed with %v, missed %v", got, again.Missed)
+	}
+	slow.Close() // closing twice is harmless
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	b, err := NewBus(&config.EventsConfig{ReplaySize: 10, Heartbeat: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := b.Subscribe(admin, 0)
+	b.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription still open after Close")
+	}
+	b.Publish(Event{Type: TableReset, Public: true})
+	late := b.Subscribe(admin, 0)
+	if _, ok := <-late.C; ok {
+		t.Error("subscribing to a closed bus gave an open subscription")
+	}
+	if b.Check(context.Background()) == nil {
+		t.Error("Check() of a closed bus succeeded")
+	}
+}
+
+func TestNewBusRejects(t *testing.T) {
+	for _, cfg := range []config.EventsConfig{
+		{ReplaySize: 0, Heartbeat: time.Second},
+		{ReplaySize: 10, Heartbeat: 0},
+	} {
+		if _, err := NewBus(&cfg); err == nil {
+			t.Errorf("NewBus(%+v) succeeded", cfg)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package events is an in-process bus for domain events. Usecases publish
+// what happened and the API streams it to clients over Server-Sent Events and
+// WebSockets. The latest events are kept so a client that reconnects with the
+// id of the last event it saw gets what it missed.
+package events
+
+import (
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type Type string
+
+const (
+	OrderCreated              Type = "order.created"
+	OrderStatusChanged        Type = "order.status_changed"
+	OrderKitchenStatusChanged Type = "order.kitchen_status_changed"
+	TableReset                Type = "table.reset"
+	MenuItemSoldOut           Type = "menu.item_sold_out"
+	TicketCreated             Type = "kitchen.ticket_created"
+	TicketUpdated             Type = "kitchen.ticket_updated"
+
+	// Reset tells a client that events it asked for are gone and it should
+	// load the current state again. It is never stored.
+	Reset Type = "reset"
+)
+
+// Event is what is sent to clients. The fields without a JSON name decide
+// who may see it.
+type Event struct {
+	ID   uint64    `json:"id"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+
+	UserID    int  `json:"-"` // customer the event is about
+	Kitchen   bool `json:"-"` // kitchen staff see it
+	StationID int  `json:"-"` // kitchen station the event is about
+	Public    bool `json:"-"` // everyone sees it
+}
+
+// Principal is who is listening, with optional narrowing of what they get.
+type Principal struct {
+	UserID    int
+	Role      string
+	StationID int // only this station's kitchen events, when set
+}
+
+// Allows reports whether the principal may see the event. Customers see
+// their own orders, kitchen staff see tickets and admins see everything;
+// public events go to all of them.
+func (p Principal) Allows(e Event) bool {
+	if p.StationID != 0 && e.StationID != 0 && e.StationID != p.StationID {
+		return false
+	}
+	if e.Public {
+		return true
+	}
+	switch models.UserRole(p.Role) {
+	case models.UserRoleAdmin, models.UserRoleManager:
+		return true
+	case models.UserRoleKitchen:
+		return e.Kitchen
+	}
+	return e.UserID != 0 && e.UserID == p.UserID
+}
+
+// OrderEvent is about an order, seen by its customer.
+func OrderEvent(t Type, order models.Order) Event {
+	return Event{Type: t, Data: order, UserID: order.UserID}
+}
+
+// TicketEvent is about a kitchen ticket, seen by the kitchen.
+func TicketEvent(t Type, ticket models.KitchenTicket) Event {
+	return Event{Type: t, Data: ticket, Kitchen: true, StationID: ticket.StationID}
+}
+
+// TableEvent is about a table, whose availability anyone may see.
+func TableEvent(t Type, tableId int) Event {
+	return Event{Type: t, Data: map[string]int{"tableId": tableId}, Public: true}
+}
+
+// MenuItemEvent is about a menu item, which anyone may see.
+func MenuItemEvent(t Type, item models.MenuItem) Event {
+	return Event{Type: t, Data: item, Public: true}
+}
@@ -0,0 +1,157 @@
+// Package health runs the checks behind the liveness and readiness probes.
+//
+// Liveness asks whether the process works at all and should be restarted
+// when it fails, so its checks only cover what a restart fixes, such as a
+// background worker that stopped. Readiness asks whether the process can
+// serve requests right now; it runs the liveness checks as well as those of
+// the dependencies, such as the database, that come back by themselves.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Check reports what is wrong with one part, or nil when it works. It
+// should return once ctx is done.
+type Check func(ctx context.Context) error
+
+// Status of a check and of a whole report.
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a probe; it is failing when any check is.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry holds the checks of both probes. It is safe for concurrent use.
+type Registry struct {
+	timeout time.Duration
+
+	mu        sync.Mutex
+	liveness  []namedCheck
+	readiness []namedCheck
+	draining  bool
+}
+
+// NewRegistry returns a registry that fails any check taking longer than
+// timeout.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// AddLiveness registers a check of both probes.
+func (r *Registry) AddLiveness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadiness registers a check of the readiness probe only.
+func (r *Registry) AddReadiness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, namedCheck{name: name, check: check})
+}
+
+// Drain fails the readiness probe from now on, so the server is taken out
+// of rotation while it shuts down. Liveness is not affected.
+func (r *Registry) Drain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+}
+
+// Live runs the liveness checks.
+func (r *Registry) Live(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append([]namedCheck(nil), r.liveness...)
+	r.mu.Unlock()
+	return r.run(ctx, checks)
+}
+
+// Ready runs the liveness and readiness checks, or fails without running
+// them once the registry is draining.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append(append([]namedCheck(nil), r.liveness...), r.readiness...)
+	draining := r.draining
+	r.mu.Unlock()
+	if draining {
+		return Report{Status: StatusFailing, Checks: []Result{{Name: "server", Status: StatusFailing, Error: "shutting down"}}}
+	}
+	return r.run(ctx, checks)
+}
+
+// run runs the checks at once and lists their results in the order they
+// were registered.
+func (r *Registry) run(ctx context.Context, checks []namedCheck) Report {
+	report := Report{Status: StatusOK, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = r.runOne(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+// runOne gives up on a check that overruns the timeout, even one that
+// ignores its context.
+func (r *Registry) runOne(ctx context.Context, c namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no answer within %s", r.timeout)
+	}
+
+	result := Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status, result.Error = StatusFailing, err.Error()
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func failing(err error) Check {
+	return func(ctx context.Context) error { return err }
+}
+
+func statuses(r Report) []string {
+	var list []string
+	for _, c := range r.Checks {
+		list = append(list, c.Name+" "+c.Status)
+	}
+	return list
+}
+
+func TestLivenessAndReadiness(t *testing.T) {
+	r := NewRegistry(time.Second)
+	r.AddLiveness("spooler", ok)
+	r.AddReadiness("database", failing(errors.New("connection refused")))
+	r.AddLiveness("event bus", ok)
+	r.AddReadiness("migrations", ok)
+
+	// Liveness ignores the database, which comes back without a restart
+	live := r.Live(context.Background())
+	if !live.OK() || strings.Join(statuses(live), ", ") != "spooler ok, event bus ok" {
+		t.Errorf("Live() = %+v", live)
+	}
+
+	ready := r.Ready(context.Background())
+	if ready.OK() || ready.Status != StatusFailing {
+		t.Errorf("Ready() = %+v, want failing", ready)
+	}
+	want := "spooler ok, event bus ok, database failing, migrations ok"
+	if got := strings.Join(statuses(ready), ", "); got != want {
+		t.Errorf("Ready() checks %s, want %s", got, want)
+	}
+	if db := ready.Checks[2]; db.Error != "connection refused" {
+		t.Errorf("database check %+v", db)
+	}
+
+	// A stopped worker fails both
+	r.AddLiveness("dispatcher", failing(errors.New("stopped")))
+	if r.Live(context.Background()).OK() {
+		t.Error("Live() passed with a stopped worker")
+	}
+}
+
+func TestNoChecks(t *testing.T) {
+	r := NewRegistry(time.Second)
+	for _, report := range []Report{r.Live(context.Background()), r.Ready(context.Background())} {
+		if !report.OK() || len(report.Checks) != 0 {
+			t.Errorf("report %+v, want ok without checks", report)
+		}
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	const timeout = 50 * time.Millisecond
+	r := NewRegistry(timeout)
+	r.AddReadiness("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	r.AddReadiness("stuck", func(ctx context.Context) error {
+		<-release // ignores its context
+		return nil
+	})
+	r.AddReadiness("fast", ok)
+
+	start := time.Now()
+	report := r.Ready(context.Background())
+	elapsed := time.Since(start)
+
+	// The checks run at once, so the probe takes one timeout rather than two
+	if elapsed < timeout || elapsed > timeout+time.Second {
+		t.Errorf("Ready() took %s with a %s timeout", elapsed, timeout)
+	}
+	if report.OK() {
+		t.Fatalf("Ready() = %+v, want failing", report)
+	}
+	for _, c := range report.Checks[:2] {
+		if c.Status != StatusFailing || c.Error != "no answer within 50ms" || c.LatencyMs < 50 {
+			t.Errorf("check %+v, want failing after the timeout", c)
+		}
+	}
+	if fast := report.Checks[2]; fast.Status != StatusOK || fast.LatencyMs >= 50 {
+		t.Errorf("check %+v, want ok", fast)
+	}
+}
+
+func TestReadyFailsWhileDraining(t *testing.T) {
+	r := NewRegistry(time.Second)
+	called := false
+	r.AddLiveness("spooler", ok)
+	r.AddReadiness("database", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !r.Ready(context.Background()).OK() {
+		t.Fatal("Ready() failed before draining")
+	}
+
+	called = false
+	r.Drain()
+	ready := r.Ready(context.Background())
+	if ready.OK() || len(ready.Checks) != 1 || ready.Checks[0].Error != "shutting down" {
+		t.Errorf("Ready() while draining = %+v", ready)
+	}
+	if called {
+		t.Error("Ready() ran the checks while draining")
+	}
+	if live := r.Live(context.Background()); !live.OK() {
+		t.Errorf("Live() while draining = %+v, want ok", live)
+	}
+}
@@ -0,0 +1,139 @@
+// Package imaging validates uploaded images and produces resized variants
+// using only the standard library.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoders
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image is too large")
+)
+
+// AllowedContentTypes are the sniffed content types accepted for upload.
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// MaxPixels guards against decompression bombs: a tiny file can declare
+// enormous dimensions.
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+// Variant is a named target size. Images are scaled down to fit inside
+// MaxWidth x MaxHeight, keeping their aspect ratio; they are never scaled up.
+type Variant struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Decode sniffs and validates the content type and dimensions before
+// decoding the full image.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if !AllowedContentTypes[contentType] {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, contentType, nil
+}
+
+// Fit scales src down so that it fits inside the variant's bounds.
+func Fit(src image.Image, v Variant) image.Image {
+	b := src.Bounds()
+	w, h := fitSize(b.Dx(), b.Dy(), v.MaxWidth, v.MaxHeight)
+	if w == b.Dx() && h == b.Dy() {
+		return flatten(src)
+	}
+	return resize(flatten(src), w, h)
+}
+
+// EncodeJPEG writes img as a JPEG. All variants are stored as JPEG since
+// they are photos and transparency is flattened away.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	// Compare w/maxW and h/maxH without floating point
+	if w*maxH >= h*maxW {
+		nh := h * maxW / w
+		return maxW, max(nh, 1)
+	}
+	nw := w * maxH / h
+	return max(nw, 1), maxH
+}
+
+// flatten draws src onto an opaque white RGBA canvas, dropping transparency.
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// resize downsamples with an area-averaging (box) filter, which gives good
+// results for the shrink-only resizing we need.
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					bl += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
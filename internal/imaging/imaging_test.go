@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDecodeSniffsContentType(t *testing.T) {
+	img := solid(4, 3, color.RGBA{R: 200, A: 255})
+	encode := map[string]func(*bytes.Buffer) error{
+		"image/png":  func(b *bytes.Buffer) error { return png.Encode(b, img) },
+		"image/jpeg": func(b *bytes.Buffer) error { return jpeg.Encode(b, img, nil) },
+		"image/gif":  func(b *bytes.Buffer) error { return gif.Encode(b, img, nil) },
+	}
+	for want, enc := range encode {
+		var buf bytes.Buffer
+		if err := enc(&buf); err != nil {
+			t.Fatalf("encode %s: %v", want, err)
+		}
+		decoded, contentType, err := Decode(buf.Bytes())
+		if err != nil {
+			t.Fatalf("Decode(%s) failed: %v", want, err)
+		}
+		if contentType != want {
+			t.Errorf("Decode(%s) sniffed %s", want, contentType)
+		}
+		if b := decoded.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+			t.Errorf("Decode(%s) bounds = %v, want 4x3", want, b)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	// A GIF header declaring a 65535x65535 screen and nothing else
+	bomb := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+	var truncated bytes.Buffer
+	png.Encode(&truncated, solid(2, 2, color.Black))
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("hello, world"), ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrUnsupportedType},
+		{"pdf", []byte("%PDF-1.4\n"), ErrUnsupportedType},
+		{"too many pixels", bomb, ErrTooLarge},
+		{"corrupt png", truncated.Bytes()[:20], ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		w, h, maxW, maxH int
+		wantW, wantH     int
+	}{
+		{400, 200, 100, 100, 100, 50},  // wide
+		{200, 400, 100, 100, 50, 100},  // tall
+		{300, 300, 100, 50, 50, 50},    // square into a wide box
+		{80, 60, 100, 100, 80, 60},     // already fits, never scaled up
+		{100, 100, 100, 100, 100, 100}, // exact
+		{1000, 1, 100, 100, 100, 1},    // never rounds down to zero
+		{1, 1000, 100, 100, 1, 100},
+	}
+	for _, tt := range tests {
+		w, h := fitSize(tt.w, tt.h, tt.maxW, tt.maxH)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitSize(%d, %d, %d, %d) = %dx%d, want %dx%d",
+				tt.w, tt.h, tt.maxW, tt.maxH, w, h, tt.wantW, tt.wantH)
+		}
+		if w > tt.maxW || h > tt.maxH {
+			t.Errorf("fitSize(%d, %d, %d, %d) = %dx%d overflows the box",
+				tt.w, tt.h, tt.maxW, tt.maxH, w, h)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	out := Fit(solid(640, 480, red), Variant{Name: "thumb", MaxWidth: 160, MaxHeight: 160})
+	if b := out.Bounds(); b.Dx() != 160 || b.Dy() != 120 {
+		t.Fatalf("Fit() bounds = %v, want 160x120", b)
+	}
+	// A box filter keeps a solid color
+	if got := color.RGBAModel.Convert(out.At(80, 60)); got != red {
+		t.Errorf("Fit() pixel = %v, want %v", got, red)
+	}
+
+	// Transparency is flattened onto white, and small images keep their size
+	out = Fit(image.NewRGBA(image.Rect(0, 0, 10, 10)), Variant{MaxWidth: 100, MaxHeight: 100})
+	if b := out.Bounds(); b.Dx() != 10 || b.Dy() != 10 {
+		t.Fatalf("Fit() bounds = %v, want 10x10", b)
+	}
+	if got := color.RGBAModel.Convert(out.At(5, 5)); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Fit() transparent pixel = %v, want white", got)
+	}
+
+	// Variants are encoded as JPEG
+	var buf bytes.Buffer
+	if err := EncodeJPEG(&buf, out); err != nil {
+		t.Fatalf("EncodeJPEG() failed: %v", err)
+	}
+	if _, contentType, err := Decode(buf.Bytes()); err != nil || contentType != "image/jpeg" {
+		t.Errorf("EncodeJPEG() output decoded as %q, %v", contentType, err)
+	}
+}
+
+func TestFitHonoursSourceOrigin(t *testing.T) {
+	// A sub-image does not start at (0, 0)
+	src := solid(20, 20, color.RGBA{B: 255, A: 255}).SubImage(image.Rect(5, 5, 15, 15))
+	out := Fit(src, Variant{MaxWidth: 5, MaxHeight: 5})
+	if b := out.Bounds(); b != image.Rect(0, 0, 5, 5) {
+		t.Fatalf("Fit() bounds = %v, want (0,0)-(5,5)", b)
+	}
+	if got := color.RGBAModel.Convert(out.At(2, 2)); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("Fit() pixel = %v, want blue", got)
+	}
+}
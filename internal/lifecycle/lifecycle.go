@@ -0,0 +1,81 @@
+// Package lifecycle stops the parts of the server in order when it shuts
+// down.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// StopFunc stops a component. It returns once the component has finished
+// its work in flight, or with ctx's error when ctx is done first.
+type StopFunc func(ctx context.Context) error
+
+// Manager holds the components to stop, in the order they were started.
+type Manager struct {
+	mu         sync.Mutex
+	components []component
+}
+
+type component struct {
+	name string
+	stop StopFunc
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Add registers a component. It is stopped before every component added
+// earlier, which it may depend on.
+func (m *Manager) Add(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// AddCloser registers a component whose Close waits for its work to end,
+// without a deadline of its own. Shutdown stops waiting for it once its
+// context is done.
+func (m *Manager) AddCloser(name string, closeFunc func()) {
+	m.Add(name, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			closeFunc()
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Shutdown stops the components, the last added first. A component that
+// fails or overruns ctx is given up on and the next one is stopped anyway;
+// the errors are returned together. Calling Shutdown again does nothing.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	components := m.components
+	m.components = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		start := time.Now()
+		if err := c.stop(ctx); err != nil {
+			log.Printf("failed to stop %s: %v", c.name, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.name, err))
+			continue
+		}
+		log.Printf("Stopped %s in %s", c.name, time.Since(start).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder notes the order components are stopped in.
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) stop(name string, err error) StopFunc {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stopped = append(r.stopped, name)
+		return err
+	}
+}
+
+func (r *recorder) order() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.stopped)
+}
+
+func TestShutdownInReverseOrder(t *testing.T) {
+	var r recorder
+	m := NewManager()
+	m.Add("database", r.stop("database", nil))
+	m.Add("outbox", r.stop("outbox", nil))
+	m.AddCloser("event bus", func() { r.stop("event bus", nil)(context.Background()) })
+	m.Add("http", r.stop("http", nil))
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.order(), []string{"http", "event bus", "outbox", "database"}; !slices.Equal(got, want) {
+		t.Errorf("stopped %v, want %v", got, want)
+	}
+
+	// Everything is stopped once
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.order(); len(got) != 4 {
+		t.Errorf("a second shutdown stopped %v", got[4:])
+	}
+}
+
+func TestShutdownCarriesOnAfterFailures(t *testing.T) {
+	var r recorder
+	errOutbox := errors.New("outbox stuck")
+	errHTTP := errors.New("listener gone")
+	m := NewManager()
+	m.Add("database", r.stop("database", nil))
+	m.Add("outbox", r.stop("outbox", errOutbox))
+	m.Add("http", r.stop("http", errHTTP))
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, errOutbox) || !errors.Is(err, errHTTP) {
+		t.Errorf("got %v, want both failures", err)
+	}
+	if got, want := r.order(), []string{"http", "outbox", "database"}; !slices.Equal(got, want) {
+		t.Errorf("stopped %v, want %v", got, want)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	var r recorder
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager()
+	m.Add("database", r.stop("database", nil))
+	m.AddCloser("workers", func() { <-release }) // never finishes in time
+	m.Add("http", func(ctx context.Context) error {
+		<-ctx.Done() // waits for requests until the deadline
+		return ctx.Err()
+	})
+
+	const timeout = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	err := m.Shutdown(ctx)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the deadline exceeded", err)
+	}
+	if elapsed < timeout || elapsed > timeout+time.Second {
+		t.Errorf("shutdown took %s with a %s timeout", elapsed, timeout)
+	}
+	// Components after the one that overran are still stopped
+	if got := r.order(); !slices.Equal(got, []string{"database"}) {
+		t.Errorf("stopped %v, want the database", got)
+	}
+}
@@ -0,0 +1,60 @@
+// Package mailer sends email. Receipts are mailed through the Mailer
+// interface so the transport can be swapped or stubbed.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is an email with a plain text body, an optional HTML alternative
+// and attachments.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+func NewMailer(cfg *config.MailConfig) (Mailer, error) {
+
+	switch cfg.Driver {
+	case "log":
+		return NewLogMailer(), nil
+	case "smtp":
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	default:
+		return nil, errors.New("unsupported mail driver: " + cfg.Driver)
+	}
+}
+
+// logMailer only logs what it would have sent, for development.
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(ctx context.Context, msg Message) error {
+	names := make([]string, len(msg.Attachments))
+	for i, a := range msg.Attachments {
+		names[i] = a.Filename
+	}
+	log.Printf("mail to %s: %q, attachments: [%s]", strings.Join(msg.To, ", "), msg.Subject, strings.Join(names, ", "))
+	return nil
+}
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type smtpMailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer sends through an SMTP server, authenticating with PLAIN when
+// a username is set. net/smtp upgrades to TLS when the server offers it.
+func NewSMTPMailer(host string, port int, username, password, from string) Mailer {
+	m := &smtpMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		host: host,
+		from: from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("failed to send mail: no recipients")
+	}
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp has no context support, so run it aside and give up on cancel
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(m.addr, m.auth, m.from, msg.To, body) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send mail: %w", ctx.Err())
+	}
+}
+
+// build writes the message as multipart/mixed holding a multipart/alternative
+// body and the base64 encoded attachments.
+func (m *smtpMailer) build(msg Message) ([]byte, error) {
+	mixed, err := boundary()
+	if err != nil {
+		return nil, err
+	}
+	alternative, err := boundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", m.from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/mixed; boundary="`+mixed+`"`)
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", mixed, alternative)
+	writePart(&b, alternative, "text/plain; charset=utf-8", "", []byte(msg.Text))
+	if msg.HTML != "" {
+		writePart(&b, alternative, "text/html; charset=utf-8", "", []byte(msg.HTML))
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", alternative)
+
+	for _, a := range msg.Attachments {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})
+		writePart(&b, mixed, a.ContentType, disposition, a.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", mixed)
+	return b.Bytes(), nil
+}
+
+func writePart(b *bytes.Buffer, boundary, contentType, disposition string, data []byte) {
+	fmt.Fprintf(b, "--%s\r\nContent-Type: %s\r\nContent-Transfer-Encoding: base64\r\n", boundary, contentType)
+	if disposition != "" {
+		fmt.Fprintf(b, "Content-Disposition: %s\r\n", disposition)
+	}
+	b.WriteString("\r\n")
+
+	// Lines of at most 76 characters
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+}
+
+func boundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate mime boundary: %w", err)
+	}
+	return fmt.Sprintf("tastybites-%x", buf), nil
+}
@@ -0,0 +1,201 @@
+package menufile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// columns is the CSV header, named like the JSON fields. Every column must
+// be present, in any order, so a missing column cannot clear a field.
+var columns = []string{"sku", "name", "description", "category", "price", "available", "stock", "spicyLevel", "allergens", "dietaryTags"}
+
+// listSeparator separates allergens and dietary tags within a cell.
+const listSeparator = ";"
+
+// WriteCSV writes the rows under a header row.
+func WriteCSV(w io.Writer, rows []models.MenuImportRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		stock := ""
+		if row.Stock != nil {
+			stock = strconv.Itoa(*row.Stock)
+		}
+		allergens := make([]string, len(row.Allergens))
+		for i, a := range row.Allergens {
+			allergens[i] = string(a)
+		}
+		tags := make([]string, len(row.DietaryTags))
+		for i, t := range row.DietaryTags {
+			tags[i] = string(t)
+		}
+		record := []string{
+			row.SKU,
+			row.Name,
+			row.Description,
+			row.Category,
+			strconv.Itoa(row.Price),
+			strconv.FormatBool(row.Available),
+			stock,
+			strconv.Itoa(row.SpicyLevel),
+			strings.Join(allergens, listSeparator),
+			strings.Join(tags, listSeparator),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads rows under a header row. Rows are numbered by their line in
+// the file, so the first row after the header is row 2. Empty cells leave
+// optional fields at their defaults: available, stock not tracked, not
+// spicy and no allergens or tags.
+func ReadCSV(r io.Reader) ([]models.MenuImportRow, []models.MenuImportError, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("%w: the menu file is empty", models.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid CSV header: %w", models.ErrInvalidInput, err)
+	}
+	index, err := columnIndex(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []models.MenuImportRow
+	var errs []models.MenuImportError
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			errs = append(errs, models.MenuImportError{Row: parseErr.Line,
+				Message: fmt.Sprintf("want %d columns, got %d", len(index), len(record))})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid CSV: %w", models.ErrInvalidInput, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row, rowErrs := parseRecord(line, func(column string) string {
+			return strings.TrimSpace(record[index[column]])
+		})
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, errs, nil
+}
+
+// columnIndex maps each column name to its position in the header.
+func columnIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")) // spreadsheets like to add a BOM
+		known := false
+		for _, column := range columns {
+			if strings.EqualFold(name, column) {
+				name, known = column, true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown CSV column %q", models.ErrInvalidInput, name)
+		}
+		if _, dup := index[name]; dup {
+			return nil, fmt.Errorf("%w: CSV column %q appears twice", models.ErrInvalidInput, name)
+		}
+		index[name] = i
+	}
+	for _, column := range columns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: CSV column %q is missing", models.ErrInvalidInput, column)
+		}
+	}
+	return index, nil
+}
+
+// parseRecord converts the cells of one line, reporting every cell that
+// does not parse.
+func parseRecord(line int, cell func(column string) string) (models.MenuImportRow, []models.MenuImportError) {
+	row := models.MenuImportRow{
+		Row:         line,
+		SKU:         cell("sku"),
+		Name:        cell("name"),
+		Description: cell("description"),
+		Category:    cell("category"),
+		Available:   true,
+	}
+
+	var errs []models.MenuImportError
+	fail := func(column, format string, args ...any) {
+		errs = append(errs, models.MenuImportError{Row: line, SKU: row.SKU, Field: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if raw := cell("price"); raw == "" {
+		fail("price", "price is required")
+	} else if price, err := strconv.Atoi(raw); err != nil {
+		fail("price", "price must be a whole number of cents, got %q", raw)
+	} else {
+		row.Price = price
+	}
+	if raw := cell("available"); raw != "" {
+		available, err := strconv.ParseBool(raw)
+		if err != nil {
+			fail("available", "available must be true or false, got %q", raw)
+		}
+		row.Available = available
+	}
+	if raw := cell("stock"); raw != "" {
+		stock, err := strconv.Atoi(raw)
+		if err != nil {
+			fail("stock", "stock must be a whole number, got %q", raw)
+		}
+		row.Stock = &stock
+	}
+	if raw := cell("spicyLevel"); raw != "" {
+		level, err := strconv.Atoi(raw)
+		if err != nil {
+			fail("spicyLevel", "spicy level must be a whole number, got %q", raw)
+		}
+		row.SpicyLevel = level
+	}
+	for _, raw := range splitList(cell("allergens")) {
+		row.Allergens = append(row.Allergens, models.Allergen(raw))
+	}
+	for _, raw := range splitList(cell("dietaryTags")) {
+		row.DietaryTags = append(row.DietaryTags, models.DietaryTag(raw))
+	}
+	return row, errs
+}
+
+// splitList splits a list cell, dropping blanks.
+func splitList(raw string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, listSeparator) {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
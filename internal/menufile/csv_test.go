@@ -0,0 +1,179 @@
+package menufile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const header = "sku,name,description,category,price,available,stock,spicyLevel,allergens,dietaryTags\n"
+
+func intPtr(n int) *int { return &n }
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		wantRows []models.MenuImportRow
+		wantErrs []models.MenuImportError
+	}{
+		{
+			name: "every field",
+			file: header + `PIZ-1,Margherita,"Tomato, mozzarella",Pizza,1299,false,12,1, Gluten ;milk;,vegetarian` + "\n",
+			wantRows: []models.MenuImportRow{{
+				Row: 2, SKU: "PIZ-1", Name: "Margherita", Description: "Tomato, mozzarella", Category: "Pizza",
+				Price: 1299, Available: false, Stock: intPtr(12), SpicyLevel: 1,
+				Allergens:   []models.Allergen{models.AllergenGluten, models.AllergenMilk},
+				DietaryTags: []models.DietaryTag{models.DietaryTagVegetarian},
+			}},
+		},
+		{
+			name:     "defaults for empty cells",
+			file:     header + " DRK-1 , Lemonade ,,Drinks, 399 ,,,,,\n",
+			wantRows: []models.MenuImportRow{{Row: 2, SKU: "DRK-1", Name: "Lemonade", Category: "Drinks", Price: 399, Available: true}},
+		},
+		{
+			name: "columns in any order and case",
+			file: "\ufeffName,SKU,price,Category,description,available,stock,SPICYLEVEL,allergens,dietarytags\n" +
+				"Iced Tea,DRK-2,349,Drinks,,true,,,,\n",
+			wantRows: []models.MenuImportRow{{Row: 2, SKU: "DRK-2", Name: "Iced Tea", Category: "Drinks", Price: 349, Available: true}},
+		},
+		{
+			name: "the same name twice is up to the import",
+			file: header + "A-1,Soup,,Soups,500,,,,,\nA-2,Soup,,Soups,600,,,,,\n",
+			wantRows: []models.MenuImportRow{
+				{Row: 2, SKU: "A-1", Name: "Soup", Category: "Soups", Price: 500, Available: true},
+				{Row: 3, SKU: "A-2", Name: "Soup", Category: "Soups", Price: 600, Available: true},
+			},
+		},
+		{
+			name: "rows are numbered by line",
+			file: header + "A-1,Soup,\"Two\nlines\",Soups,500,,,,,\nA-2,Bread,,Starters,300,,,,,\n",
+			wantRows: []models.MenuImportRow{
+				{Row: 2, SKU: "A-1", Name: "Soup", Description: "Two\nlines", Category: "Soups", Price: 500, Available: true},
+				{Row: 4, SKU: "A-2", Name: "Bread", Category: "Starters", Price: 300, Available: true},
+			},
+		},
+		{
+			name:     "too few and too many columns",
+			file:     header + "A-1,Soup,,Soups,500\nA-2,Bread,,Starters,300,,,,,,extra\nA-3,Tea,,Drinks,200,,,,,\n",
+			wantRows: []models.MenuImportRow{{Row: 4, SKU: "A-3", Name: "Tea", Category: "Drinks", Price: 200, Available: true}},
+			wantErrs: []models.MenuImportError{
+				{Row: 2, Message: "want 10 columns, got 5"},
+				{Row: 3, Message: "want 10 columns, got 11"},
+			},
+		},
+		{
+			name: "every bad cell of a row",
+			file: header + "A-1,Soup,,Soups,5.00,yes please,lots,hot,,\n",
+			wantErrs: []models.MenuImportError{
+				{Row: 2, SKU: "A-1", Field: "price", Message: `price must be a whole number of cents, got "5.00"`},
+				{Row: 2, SKU: "A-1", Field: "available", Message: `available must be true or false, got "yes please"`},
+				{Row: 2, SKU: "A-1", Field: "stock", Message: `stock must be a whole number, got "lots"`},
+				{Row: 2, SKU: "A-1", Field: "spicyLevel", Message: `spicy level must be a whole number, got "hot"`},
+			},
+		},
+		{
+			name:     "header only",
+			file:     header,
+			wantRows: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, errs, err := ReadCSV(strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatalf("ReadCSV() failed: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows %+v, want %+v", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Errorf("errors %+v, want %+v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReadCSVPrices(t *testing.T) {
+	tests := []struct {
+		price   string
+		want    int
+		wantErr string
+	}{
+		{"1299", 1299, ""},
+		{" 1299 ", 1299, ""},
+		{"+1299", 1299, ""},
+		{"0", 0, ""},   // left to the import to reject
+		{"-5", -5, ""}, // likewise
+		{"", 0, "price is required"},
+		{"12.99", 0, `price must be a whole number of cents, got "12.99"`},
+		{"1,299", 0, `price must be a whole number of cents, got "1,299"`},
+		{"$12", 0, `price must be a whole number of cents, got "$12"`},
+		{"1e3", 0, `price must be a whole number of cents, got "1e3"`},
+		{"99999999999999999999", 0, `price must be a whole number of cents, got "99999999999999999999"`},
+	}
+	for _, tt := range tests {
+		file := header + `A-1,Soup,,Soups,"` + tt.price + `",,,,,` + "\n"
+		rows, errs, err := ReadCSV(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("price %q: %v", tt.price, err)
+		}
+		if tt.wantErr != "" {
+			if len(rows) != 0 || len(errs) != 1 || errs[0].Field != "price" || errs[0].Message != tt.wantErr {
+				t.Errorf("price %q: rows %+v, errors %+v, want %q", tt.price, rows, errs, tt.wantErr)
+			}
+			continue
+		}
+		if len(errs) != 0 || len(rows) != 1 || rows[0].Price != tt.want {
+			t.Errorf("price %q: rows %+v, errors %+v, want %d", tt.price, rows, errs, tt.want)
+		}
+	}
+}
+
+func TestReadCSVRejectsFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"empty", "", "the menu file is empty"},
+		{"unknown column", strings.Replace(header, "stock", "inventory", 1), `unknown CSV column "inventory"`},
+		{"missing column", strings.Replace(header, ",dietaryTags", "", 1), `CSV column "dietaryTags" is missing`},
+		{"duplicate column", strings.Replace(header, "stock", "Price", 1), `CSV column "price" appears twice`},
+		{"bare quote", header + "A-1,Soup \"hot\",,Soups,500,,,,,\n", "invalid CSV"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadCSV(strings.NewReader(tt.file))
+			if !errors.Is(err, models.ErrInvalidInput) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want invalid input with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	rows := []models.MenuImportRow{
+		{Row: 2, SKU: "PIZ-1", Name: "Margherita", Description: `Tomato, "fresh" mozzarella`, Category: "Pizza",
+			Price: 1299, Available: true, Stock: intPtr(0), SpicyLevel: 2,
+			Allergens:   []models.Allergen{models.AllergenGluten, models.AllergenMilk},
+			DietaryTags: []models.DietaryTag{models.DietaryTagVegetarian, models.DietaryTagHalal}},
+		{Row: 3, SKU: "DRK-1", Name: "Lemonade", Category: "Drinks", Price: 399, Available: false},
+	}
+	var b bytes.Buffer
+	if err := WriteCSV(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+	got, errs, err := ReadCSV(&b)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("ReadCSV() = %+v, %v", errs, err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("read back %+v, want %+v", got, rows)
+	}
+}
@@ -0,0 +1,54 @@
+package menufile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// WriteJSON writes the rows as an indented JSON array.
+func WriteJSON(w io.Writer, rows []models.MenuImportRow) error {
+	if rows == nil {
+		rows = []models.MenuImportRow{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
+
+// ReadJSON reads a JSON array of rows. Rows are numbered from 1 in array
+// order; an element of the wrong shape is reported against its row.
+func ReadJSON(r io.Reader) ([]models.MenuImportRow, []models.MenuImportError, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid JSON menu file: %w", models.ErrInvalidInput, err)
+	}
+
+	rows := make([]models.MenuImportRow, 0, len(raw))
+	var errs []models.MenuImportError
+	for i, msg := range raw {
+		var row models.MenuImportRow
+		if err := json.Unmarshal(msg, &row); err != nil {
+			errs = append(errs, rowError(i+1, err))
+			continue
+		}
+		row.Row = i + 1
+		rows = append(rows, row)
+	}
+	return rows, errs, nil
+}
+
+// rowError describes why an element is not a row without naming Go types.
+func rowError(row int, err error) models.MenuImportError {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return models.MenuImportError{Row: row, Message: err.Error()}
+	}
+	if typeErr.Field == "" {
+		return models.MenuImportError{Row: row, Message: fmt.Sprintf("want a menu item object, got a JSON %s", typeErr.Value)}
+	}
+	return models.MenuImportError{Row: row, Field: typeErr.Field, Message: fmt.Sprintf("%s cannot be a JSON %s", typeErr.Field, typeErr.Value)}
+}
@@ -0,0 +1,103 @@
+package menufile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		wantRows []models.MenuImportRow
+		wantErrs []models.MenuImportError
+	}{
+		{
+			name:     "empty",
+			file:     `[]`,
+			wantRows: []models.MenuImportRow{},
+		},
+		{
+			name: "available unless it says otherwise",
+			file: `[{"sku": "A-1", "name": "Soup", "category": "Soups", "price": 500},
+				{"sku": "A-2", "name": "Bread", "category": "Starters", "price": 300, "available": false, "stock": 4}]`,
+			wantRows: []models.MenuImportRow{
+				{Row: 1, SKU: "A-1", Name: "Soup", Category: "Soups", Price: 500, Available: true},
+				{Row: 2, SKU: "A-2", Name: "Bread", Category: "Starters", Price: 300, Stock: intPtr(4)},
+			},
+		},
+		{
+			name: "the same name twice is up to the import",
+			file: `[{"sku": "A-1", "name": "Soup", "category": "Soups", "price": 500},
+				{"sku": "A-2", "name": "Soup", "category": "Soups", "price": 600}]`,
+			wantRows: []models.MenuImportRow{
+				{Row: 1, SKU: "A-1", Name: "Soup", Category: "Soups", Price: 500, Available: true},
+				{Row: 2, SKU: "A-2", Name: "Soup", Category: "Soups", Price: 600, Available: true},
+			},
+		},
+		{
+			name: "malformed rows",
+			file: `["Soup", {"sku": "A-2", "price": "5.00"}, {"sku": "A-3", "price": 5.5},
+				{"sku": "A-4", "name": "Tea", "category": "Drinks", "price": 200}, 42, {"sku": "A-6", "allergens": "milk"}]`,
+			wantRows: []models.MenuImportRow{{Row: 4, SKU: "A-4", Name: "Tea", Category: "Drinks", Price: 200, Available: true}},
+			wantErrs: []models.MenuImportError{
+				{Row: 1, Message: "want a menu item object, got a JSON string"},
+				{Row: 2, Field: "price", Message: "price cannot be a JSON string"},
+				{Row: 3, Field: "price", Message: "price cannot be a JSON number 5.5"},
+				{Row: 5, Message: "want a menu item object, got a JSON number"},
+				{Row: 6, Field: "allergens", Message: "allergens cannot be a JSON string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, errs, err := ReadJSON(strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatalf("ReadJSON() failed: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows %+v, want %+v", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Errorf("errors %+v, want %+v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReadJSONRejectsFile(t *testing.T) {
+	for _, file := range []string{``, `{"sku": "A-1"}`, `[{"sku": "A-1"}`, `not json`} {
+		if _, _, err := ReadJSON(strings.NewReader(file)); !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("ReadJSON(%q) = %v, want invalid input", file, err)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJSON(&b, nil); err != nil || b.String() != "[]\n" {
+		t.Fatalf("WriteJSON(nil) = %q, %v", b.String(), err)
+	}
+
+	rows := []models.MenuImportRow{
+		{Row: 1, SKU: "PIZ-1", Name: "Margherita", Category: "Pizza", Price: 1299, Available: true, Stock: intPtr(0),
+			Allergens: []models.Allergen{models.AllergenGluten}, DietaryTags: []models.DietaryTag{models.DietaryTagVegetarian}},
+		{Row: 2, SKU: "DRK-1", Name: "Lemonade", Category: "Drinks", Price: 399, Available: false},
+	}
+	b.Reset()
+	if err := WriteJSON(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+	got, errs, err := ReadJSON(&b)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("ReadJSON() = %+v, %v", errs, err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("read back %+v, want %+v", got, rows)
+	}
+}
@@ -0,0 +1,59 @@
+// Package menufile reads and writes the menu as CSV or JSON for bulk
+// import and export. Both formats carry the same fields; CSV has a header
+// row and separates list values with semicolons.
+package menufile
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: menu file format must be csv or json", models.ErrInvalidInput)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Filename is a download name for an export in the format.
+func (f Format) Filename() string {
+	return "menu." + string(f)
+}
+
+// Write writes the rows in the given format.
+func Write(w io.Writer, f Format, rows []models.MenuImportRow) error {
+	if f == FormatCSV {
+		return WriteCSV(w, rows)
+	}
+	return WriteJSON(w, rows)
+}
+
+// Read reads a file in the given format. Rows that cannot be read come back
+// as errors next to the rows that could; an error is returned when the file
+// as a whole is unreadable.
+func Read(r io.Reader, f Format) ([]models.MenuImportRow, []models.MenuImportError, error) {
+	if f == FormatCSV {
+		return ReadCSV(r)
+	}
+	return ReadJSON(r)
+}
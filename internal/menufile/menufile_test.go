@@ -0,0 +1,22 @@
+package menufile
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatJSON, false},
+		{"json", FormatJSON, false},
+		{"CSV", FormatCSV, false},
+		{"xlsx", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.s)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.s, got, err)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+// Package migrate applies versioned SQL migrations to a Postgres database and
+// reports which of them are applied.
+//
+// A migration is a pair of files, NNN_name.up.sql and NNN_name.down.sql, where
+// NNN is its version. Applied versions are recorded in schema_migrations. Each
+// migration runs in its own transaction, and every command holds a Postgres
+// advisory lock so two runners, e.g. two API replicas starting together, never
+// migrate the same database at once.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockID is the pg_advisory_lock key every runner takes; the value is arbitrary
+// but must not change between releases.
+const lockID int64 = 7_304_118_337
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrNoChange is returned by Down when no migration is applied.
+var ErrNoChange = errors.New("no migration to roll back")
+
+// Migration is one schema change and the script that reverts it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration is applied. Versions recorded in the
+// database but unknown to this binary are listed as applied with Missing set.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	Missing   bool       `json:"missing,omitempty"`
+}
+
+// Load reads the migrations in the root of fsys, ordered by version. Every
+// version needs both an up and a down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, want NNN_name.up.sql or NNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s needs both an up and a down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator runs the migrations of one database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads the migrations in fsys for the database db.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration and returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	if len(m.migrations) == 0 {
+		return 0, nil
+	}
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the last steps applied migrations, or all of them when steps
+// is zero or less, and returns how many it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := sortedVersions(applied)
+		if len(versions) == 0 {
+			return ErrNoChange
+		}
+		if steps <= 0 || steps > len(versions) {
+			steps = len(versions)
+		}
+		for i := len(versions) - 1; i >= len(versions)-steps; i-- {
+			mig, err := m.find(versions[i])
+			if err != nil {
+				return err
+			}
+			if err := run(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Goto migrates up or down until version is the last applied migration and
+// returns how many migrations it applied or rolled back.
+func (m *Migrator) Goto(ctx context.Context, version int) (int, error) {
+	if _, err := m.find(version); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := sortedVersions(applied)
+		for i := len(versions) - 1; i >= 0 && versions[i] > version; i-- {
+			mig, err := m.find(versions[i])
+			if err != nil {
+				return err
+			}
+			if err := run(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			count++
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok || mig.Version > version {
+				continue
+			}
+			if err := run(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Force records version and every migration before it as applied, and the
+// ones after it as not applied, without running any SQL. It baselines a
+// database whose schema was created some other way and repairs the record
+// after a migration failed halfway through.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if _, err := m.find(version); err != nil {
+		return err
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > $1`, version); err != nil {
+			return fmt.Errorf("failed to clear migrations after version %d: %w", version, err)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+				ON CONFLICT (version) DO NOTHING`, mig.Version, mig.Name)
+			if err != nil {
+				return fmt.Errorf("failed to record migration %03d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit forced version: %w", err)
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and whether it is applied, followed by
+// applied versions this binary does not know about.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]Status, 0, len(m.migrations))
+		for _, mig := range m.migrations {
+			status := Status{Version: mig.Version, Name: mig.Name}
+			if row, ok := applied[mig.Version]; ok {
+				status.Applied, status.AppliedAt = true, &row.appliedAt
+				delete(applied, mig.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, version := range sortedVersions(applied) {
+			row := applied[version]
+			statuses = append(statuses, Status{Version: version, Name: row.name, Applied: true, AppliedAt: &row.appliedAt, Missing: true})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending lists the known migrations that are not applied. Unlike Status it
+// does not take the lock, so it answers while another runner migrates and is
+// cheap enough for health checks.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Seed runs the scripts in the root of fsys, in file name order and in one
+// transaction, as long as the database has no users yet. It reports whether
+// it loaded them.
+func (m *Migrator) Seed(ctx context.Context, fsys fs.FS) (bool, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return false, fmt.Errorf("failed to list seed scripts: %w", err)
+	}
+	sort.Strings(names)
+
+	seeded := false
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		var hasUsers bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public.users)`).Scan(&hasUsers); err != nil {
+			return fmt.Errorf("failed to check for existing data: %w", err)
+		}
+		if hasUsers {
+			return nil
+		}
+
+		for _, name := range names {
+			script, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return fmt.Errorf("failed to read seed script %s: %w", name, err)
+			}
+			if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+				return fmt.Errorf("failed to apply seed script %s: %w", name, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit seed data: %w", err)
+		}
+		seeded = true
+		return nil
+	})
+	return seeded, err
+}
+
+func (m *Migrator) find(version int) (Migration, error) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("unknown migration version %d", version)
+}
+
+// withLock runs fn on a connection that holds the advisory lock, after making
+// sure schema_migrations exists. Session level locks belong to a connection,
+// so everything runs on that one connection rather than the pool.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			log.Println("Failed to release migration lock:", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS public.schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+type appliedRow struct {
+	name      string
+	appliedAt time.Time
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]appliedRow, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedRow)
+	for rows.Next() {
+		var version int
+		var row appliedRow
+		if err := rows.Scan(&version, &row.name, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+func sortedVersions(applied map[int]appliedRow) []int {
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// run applies or reverts one migration and updates schema_migrations in the
+// same transaction.
+func run(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+			return fmt.Errorf("failed to apply migration %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+	} else {
+		if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+			return fmt.Errorf("failed to revert migration %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if up {
+		log.Printf("Applied migration %03d_%s", mig.Version, mig.Name)
+	} else {
+		log.Printf("Reverted migration %03d_%s", mig.Version, mig.Name)
+	}
+	return nil
+}
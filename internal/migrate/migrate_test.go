@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/abdullahnettoor/tastybites/db"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load(db.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations are embedded")
+	}
+	if first := migrations[0]; first.Version != 0 || first.Name != "create_tables" {
+		t.Errorf("first migration %03d_%s, want 000_create_tables", first.Version, first.Name)
+	}
+	for i, m := range migrations {
+		// Versions run without gaps, so a skipped number is a missing file
+		if m.Version != i {
+			t.Errorf("migration %d has version %d", i, m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %03d_%s has an empty script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_tips.up.sql":       {Data: []byte("up 10")},
+		"10_tips.down.sql":     {Data: []byte("down 10")},
+		"002_menu.up.sql":      {Data: []byte("up 2")},
+		"002_menu.down.sql":    {Data: []byte("down 2")},
+		"9_orders.down.sql":    {Data: []byte("down 9")},
+		"9_orders.up.sql":      {Data: []byte("up 9")},
+		"archive/1_x.up.sql":   {Data: []byte("ignored")},
+		"000_create.up.sql":    {Data: []byte("up 0")},
+		"000_create.down.sql":  {Data: []byte("down 0")},
+		"archive/1_x.down.sql": {Data: []byte("ignored")},
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 0, Name: "create", Up: "up 0", Down: "down 0"},
+		{Version: 2, Name: "menu", Up: "up 2", Down: "down 2"},
+		{Version: 9, Name: "orders", Up: "up 9", Down: "down 9"},
+		{Version: 10, Name: "tips", Up: "up 10", Down: "down 10"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("got %+v, want %+v", migrations, want)
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+
+	if empty, err := Load(fstest.MapFS{}); err != nil || len(empty) != 0 {
+		t.Errorf("Load() of no files = %+v, %v", empty, err)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"no version", []string{"create.up.sql"}, `invalid migration file name "create.up.sql"`},
+		{"not up or down", []string{"001_create.sql"}, `invalid migration file name "001_create.sql"`},
+		{"dash in the name", []string{"001_create-tables.up.sql"}, `invalid migration file name "001_create-tables.up.sql"`},
+		{"readme", []string{"001_a.up.sql", "001_a.down.sql", "README.md"}, `invalid migration file name "README.md"`},
+		{"no down", []string{"001_a.up.sql"}, "migration 001_a needs both an up and a down script"},
+		{"no up", []string{"001_a.down.sql"}, "migration 001_a needs both an up and a down script"},
+		{"version used twice", []string{"001_a.up.sql", "001_a.down.sql", "1_b.up.sql", "1_b.down.sql"}, "migration version 1 is used by both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+			_, err := Load(fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import "fmt"
+
+type BundlePricingMode string
+
+const (
+	// BundlePricingFixed charges Bundle.Price whatever is chosen.
+	BundlePricingFixed BundlePricingMode = "fixed"
+	// BundlePricingDiscount charges the chosen items' prices less DiscountPercent.
+	BundlePricingDiscount BundlePricingMode = "discount"
+)
+
+func (m BundlePricingMode) String() string {
+	return string(m)
+}
+
+// Bundle is a combo or set meal, e.g. "Pizza + drink + dessert for $18",
+// made up of slots the guest fills with menu items.
+type Bundle struct {
+	ID              int               `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	PricingMode     BundlePricingMode `json:"pricingMode"`
+	Price           int               `json:"price,omitempty"` // in cents, fixed pricing only
+	DiscountPercent int               `json:"discountPercent,omitempty"`
+	Active          bool              `json:"active"`
+	Slots           []BundleSlot      `json:"slots"`
+	CreatedAt       string            `json:"createdAt"`
+	UpdatedAt       string            `json:"updatedAt"`
+}
+
+// BundleSlot is one choice within a bundle, e.g. "choose 1 pizza". The
+// allowed items are MenuItemIDs if set, otherwise any item in Category.
+type BundleSlot struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Category    string `json:"category,omitempty"`
+	MenuItemIDs []int  `json:"menuItemIds,omitempty"`
+	MinChoices  int    `json:"minChoices"`
+	MaxChoices  int    `json:"maxChoices"`
+}
+
+// BundleChoice is the guest's pick for a slot.
+type BundleChoice struct {
+	SlotID     int `json:"slotId"`
+	MenuItemID int `json:"menuItemId"`
+	Quantity   int `json:"quantity"`
+}
+
+func (s BundleSlot) Allows(item MenuItem) bool {
+	if len(s.MenuItemIDs) > 0 {
+		for _, id := range s.MenuItemIDs {
+			if id == item.ID {
+				return true
+			}
+		}
+		return false
+	}
+	return s.Category != "" && s.Category == item.Category
+}
+
+// Validate checks the bundle definition itself.
+func (b Bundle) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("%w: bundle name is required", ErrInvalidInput)
+	}
+	switch b.PricingMode {
+	case BundlePricingFixed:
+		if b.Price <= 0 {
+			return fmt.Errorf("%w: fixed price bundles need a positive price", ErrInvalidInput)
+		}
+	case BundlePricingDiscount:
+		if b.DiscountPercent <= 0 || b.DiscountPercent > 100 {
+			return fmt.Errorf("%w: discount must be between 1 and 100 percent", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown pricing mode %q", ErrInvalidInput, b.PricingMode)
+	}
+	if len(b.Slots) == 0 {
+		return fmt.Errorf("%w: bundle needs at least one slot", ErrInvalidInput)
+	}
+	for _, slot := range b.Slots {
+		if slot.Name == "" {
+			return fmt.Errorf("%w: slot name is required", ErrInvalidInput)
+		}
+		if slot.Category == "" && len(slot.MenuItemIDs) == 0 {
+			return fmt.Errorf("%w: slot %q needs a category or menu items", ErrInvalidInput, slot.Name)
+		}
+		if slot.MinChoices < 0 || slot.MaxChoices < 1 || slot.MaxChoices < slot.MinChoices {
+			return fmt.Errorf("%w: slot %q has invalid choice limits", ErrInvalidInput, slot.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateChoices checks the guest's picks against the slots. items must
+// contain every chosen menu item.
+func (b Bundle) ValidateChoices(choices []BundleChoice, items map[int]MenuItem) error {
+	counts := make(map[int]int, len(b.Slots))
+	for _, choice := range choices {
+		slot, ok := b.slot(choice.SlotID)
+		if !ok {
+			return fmt.Errorf("%w: slot %d is not part of bundle %q", ErrInvalidInput, choice.SlotID, b.Name)
+		}
+		if choice.Quantity < 1 {
+			return fmt.Errorf("%w: quantity for slot %q must be positive", ErrInvalidInput, slot.Name)
+		}
+		item, ok := items[choice.MenuItemID]
+		if !ok || !slot.Allows(item) {
+			return fmt.Errorf("%w: menu item %d cannot be chosen for %q", ErrInvalidInput, choice.MenuItemID, slot.Name)
+		}
+		counts[slot.ID] += choice.Quantity
+	}
+
+	for _, slot := range b.Slots {
+		if n := counts[slot.ID]; n < slot.MinChoices || n > slot.MaxChoices {
+			if slot.MinChoices == slot.MaxChoices {
+				return fmt.Errorf("%w: choose %d for %q", ErrInvalidInput, slot.MinChoices, slot.Name)
+			}
+			return fmt.Errorf("%w: choose between %d and %d for %q", ErrInvalidInput, slot.MinChoices, slot.MaxChoices, slot.Name)
+		}
+	}
+	return nil
+}
+
+// PriceFor returns the price in cents of one bundle with the given choices.
+// Choices must have been validated.
+func (b Bundle) PriceFor(choices []BundleChoice, items map[int]MenuItem) int {
+	if b.PricingMode == BundlePricingFixed {
+		return b.Price
+	}
+	sum := 0
+	for _, choice := range choices {
+		sum += items[choice.MenuItemID].Price * choice.Quantity
+	}
+	// Round half up to the nearest cent
+	return (sum*(100-b.DiscountPercent) + 50) / 100
+}
+
+func (b Bundle) slot(id int) (BundleSlot, bool) {
+	for _, s := range b.Slots {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return BundleSlot{}, false
+}
@@ -8,4 +8,8 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 
 	ErrIsEmpty = errors.New("data is empty")
+
+	ErrInvalidInput = errors.New("invalid input")
+
+	ErrNotFound = errors.New("not found")
 )
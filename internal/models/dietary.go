@@ -0,0 +1,148 @@
+package models
+
+import "fmt"
+
+// Allergen is one of the 14 major allergens that must be declared under EU FIC.
+type Allergen string
+
+const (
+	AllergenCelery      Allergen = "celery"
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMilk        Allergen = "milk"
+	AllergenMolluscs    Allergen = "molluscs"
+	AllergenMustard     Allergen = "mustard"
+	AllergenTreeNuts    Allergen = "tree_nuts"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenSulphites   Allergen = "sulphites"
+)
+
+// AllAllergens lists every allergen we accept, in the order they appear on the EU list.
+var AllAllergens = []Allergen{
+	AllergenCelery,
+	AllergenGluten,
+	AllergenCrustaceans,
+	AllergenEggs,
+	AllergenFish,
+	AllergenLupin,
+	AllergenMilk,
+	AllergenMolluscs,
+	AllergenMustard,
+	AllergenTreeNuts,
+	AllergenPeanuts,
+	AllergenSesame,
+	AllergenSoybeans,
+	AllergenSulphites,
+}
+
+func (a Allergen) String() string {
+	return string(a)
+}
+
+func (a Allergen) IsValid() bool {
+	for _, known := range AllAllergens {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAllergen validates a raw allergen name, e.g. from a query string.
+func ParseAllergen(s string) (Allergen, error) {
+	a := Allergen(s)
+	if !a.IsValid() {
+		return "", fmt.Errorf("%w: unknown allergen %q", ErrInvalidInput, s)
+	}
+	return a, nil
+}
+
+type DietaryTag string
+
+const (
+	DietaryTagVegan      DietaryTag = "vegan"
+	DietaryTagVegetarian DietaryTag = "vegetarian"
+	DietaryTagHalal      DietaryTag = "halal"
+	DietaryTagSpicy      DietaryTag = "spicy"
+)
+
+var AllDietaryTags = []DietaryTag{
+	DietaryTagVegan,
+	DietaryTagVegetarian,
+	DietaryTagHalal,
+	DietaryTagSpicy,
+}
+
+func (t DietaryTag) String() string {
+	return string(t)
+}
+
+func (t DietaryTag) IsValid() bool {
+	for _, known := range AllDietaryTags {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func ParseDietaryTag(s string) (DietaryTag, error) {
+	t := DietaryTag(s)
+	if !t.IsValid() {
+		return "", fmt.Errorf("%w: unknown dietary tag %q", ErrInvalidInput, s)
+	}
+	return t, nil
+}
+
+// Spicy levels run from 0 (not spicy) to MaxSpicyLevel (very hot).
+const MaxSpicyLevel = 3
+
+// NutritionFacts are per serving. Any field may be left out if unknown.
+type NutritionFacts struct {
+	Kcal         *int     `json:"kcal,omitempty"`
+	ProteinGrams *float64 `json:"proteinGrams,omitempty"`
+	CarbsGrams   *float64 `json:"carbsGrams,omitempty"`
+	FatGrams     *float64 `json:"fatGrams,omitempty"`
+	SugarGrams   *float64 `json:"sugarGrams,omitempty"`
+	SaltGrams    *float64 `json:"saltGrams,omitempty"`
+}
+
+// IsEmpty reports whether no nutrition value has been declared.
+func (n NutritionFacts) IsEmpty() bool {
+	return n.Kcal == nil && n.ProteinGrams == nil && n.CarbsGrams == nil && n.FatGrams == nil &&
+		n.SugarGrams == nil && n.SaltGrams == nil
+}
+
+// MenuFilter narrows down the menu for guests with dietary requirements.
+// The zero value matches every item.
+type MenuFilter struct {
+	Category         string
+	ExcludeAllergens []Allergen
+	Tags             []DietaryTag // item must carry all of these
+	MaxSpicyLevel    *int
+}
+
+func (f MenuFilter) Matches(item MenuItem) bool {
+	if f.Category != "" && item.Category != f.Category {
+		return false
+	}
+	for _, excluded := range f.ExcludeAllergens {
+		if item.HasAllergen(excluded) {
+			return false
+		}
+	}
+	for _, tag := range f.Tags {
+		if !item.HasTag(tag) {
+			return false
+		}
+	}
+	if f.MaxSpicyLevel != nil && item.SpicyLevel > *f.MaxSpicyLevel {
+		return false
+	}
+	return true
+}
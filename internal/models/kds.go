@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KitchenStatus rolls the readiness of an order's kitchen items up to the
+// order. It is empty until the order is sent to the kitchen.
+type KitchenStatus string
+
+const (
+	KitchenStatusQueued    KitchenStatus = "queued"    // nothing is ready yet
+	KitchenStatusPreparing KitchenStatus = "preparing" // some items are ready
+	KitchenStatusReady     KitchenStatus = "ready"     // every item is ready
+)
+
+type TicketStatus string
+
+const (
+	TicketStatusOpen TicketStatus = "open"
+	TicketStatusDone TicketStatus = "done" // every item was bumped
+)
+
+func (s TicketStatus) Valid() bool {
+	return s == TicketStatusOpen || s == TicketStatusDone
+}
+
+type TicketItemStatus string
+
+const (
+	TicketItemPending TicketItemStatus = "pending"
+	TicketItemReady   TicketItemStatus = "ready"
+)
+
+// Station is a part of the kitchen, such as the grill or the bar, with its
+// own queue of tickets.
+type Station struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	IsDefault  bool     `json:"isDefault"`  // gets items nothing else routes
+	Categories []string `json:"categories"` // menu categories routed here
+	CreatedAt  string   `json:"createdAt"`
+}
+
+func (s *Station) Validate() error {
+	s.Name = strings.ToLower(strings.TrimSpace(s.Name))
+	if s.Name == "" || len(s.Name) > 50 {
+		return fmt.Errorf("%w: station name must be 1 to 50 characters", ErrInvalidInput)
+	}
+	return nil
+}
+
+// StationRouting decides which station prepares a menu item: the item's own
+// station, else its category's, else the default station.
+type StationRouting struct {
+	DefaultStationID int
+	Categories       map[string]int // lower case category to station id
+	Items            map[int]int    // menu item id to station id
+}
+
+func (r StationRouting) StationFor(item MenuItem) int {
+	if id, ok := r.Items[item.ID]; ok {
+		return id
+	}
+	if id, ok := r.Categories[strings.ToLower(item.Category)]; ok {
+		return id
+	}
+	return r.DefaultStationID
+}
+
+// KitchenTicket is what one station has to prepare for an order.
+type KitchenTicket struct {
+	ID        int          `json:"id"`
+	OrderID   int          `json:"orderId"`
+	StationID int          `json:"stationId"`
+	Station   string       `json:"station"`
+	Status    TicketStatus `json:"status"`
+	Table     string       `json:"table"`
+	Guests    int          `json:"guests"`
+	Items     []TicketItem `json:"items"`
+	OrderedAt string       `json:"orderedAt"`
+	CreatedAt string       `json:"createdAt"`
+	BumpedAt  string       `json:"bumpedAt,omitempty"`
+	Reprint   bool         `json:"-"` // printed again on request
+}
+
+type TicketItem struct {
+	ID          int              `json:"id"`
+	OrderItemID int              `json:"orderItemId"`
+	MenuItemID  int              `json:"menuItemId"`
+	BundleID    *int             `json:"bundleId,omitempty"`
+	Name        string           `json:"name"`
+	Quantity    int              `json:"quantity"`
+	Modifiers   []string         `json:"modifiers,omitempty"` // e.g. the bundle an item was chosen for
+	Note        string           `json:"note,omitempty"`
+	Status      TicketItemStatus `json:"status"`
+	BumpedAt    string           `json:"bumpedAt,omitempty"`
+}
+
+// KitchenTickets splits the order's items by the station that prepares
+// them, one ticket per station in station id order. Bundle lines are split
+// into their components, which may go to different stations.
+func (o Order) KitchenTickets(menuItems map[int]MenuItem, routing StationRouting) []KitchenTicket {
+	byStation := make(map[int]*KitchenTicket)
+	add := func(item TicketItem) {
+		stationID := routing.StationFor(menuItems[item.MenuItemID])
+		ticket, ok := byStation[stationID]
+		if !ok {
+			ticket = &KitchenTicket{OrderID: o.ID, StationID: stationID, Status: TicketStatusOpen}
+			byStation[stationID] = ticket
+		}
+		item.Status = TicketItemPending
+		ticket.Items = append(ticket.Items, item)
+	}
+
+	for _, line := range o.Items {
+		if !line.IsBundle() {
+			add(TicketItem{OrderItemID: line.ID, MenuItemID: line.MenuItemID, Quantity: line.Quantity, Note: line.Note})
+			continue
+		}
+		for _, c := range line.Components {
+			add(TicketItem{
+				OrderItemID: line.ID,
+				MenuItemID:  c.MenuItemID,
+				BundleID:    line.BundleID,
+				Quantity:    c.Quantity * line.Quantity,
+				Note:        line.Note,
+			})
+		}
+	}
+
+	tickets := make([]KitchenTicket, 0, len(byStation))
+	for _, ticket := range byStation {
+		tickets = append(tickets, *ticket)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].StationID < tickets[j].StationID })
+	return tickets
+}
@@ -1,14 +1,58 @@
 package models
 
 type MenuItem struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       int    `json:"price"`
-	Category    string `json:"category"`
-	ImageURL    string `json:"imageUrl"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
-
-	// this can be extended with available options, stock management, etc.
-}
\ No newline at end of file
+	ID          int        `json:"id"`
+	SKU         string     `json:"sku"` // stable id used to match imported rows
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       int        `json:"price"`
+	Category    string     `json:"category"`
+	Images      MenuImages `json:"images"`
+	CreatedAt   string     `json:"createdAt"`
+	UpdatedAt   string     `json:"updatedAt"`
+
+	// Dietary information
+	Allergens   []Allergen      `json:"allergens"`
+	DietaryTags []DietaryTag    `json:"dietaryTags"`
+	SpicyLevel  int             `json:"spicyLevel"`
+	Nutrition   *NutritionFacts `json:"nutrition,omitempty"`
+
+	Stock     *int `json:"stock,omitempty"` // nil when stock is not tracked
+	Available bool `json:"available"`       // false once archived; archived items cannot be ordered
+
+	// this can be extended with available options etc.
+}
+
+// MenuImages holds the URLs of each image variant. Items that only have a
+// hotlinked image use the same URL for every variant.
+type MenuImages struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+
+	// Key is the blob storage prefix of uploaded variants, empty for hotlinked images
+	Key string `json:"-"`
+}
+
+func (m MenuItem) HasAllergen(allergen Allergen) bool {
+	for _, a := range m.Allergens {
+		if a == allergen {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether the item carries the tag. The spicy tag is derived
+// from SpicyLevel rather than stored.
+func (m MenuItem) HasTag(tag DietaryTag) bool {
+	if tag == DietaryTagSpicy {
+		return m.SpicyLevel > 0
+	}
+	for _, t := range m.DietaryTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxSKULength is the longest SKU the database stores.
+const MaxSKULength = 64
+
+// MenuImportRow is one menu item in an import or export file, matched to
+// the menu by its SKU. Prices are in cents like everywhere else.
+type MenuImportRow struct {
+	Row         int          `json:"-"` // position in the file, for error reports
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Category    string       `json:"category"`
+	Price       int          `json:"price"`
+	Available   bool         `json:"available"`
+	Stock       *int         `json:"stock"` // nil when stock is not tracked
+	SpicyLevel  int          `json:"spicyLevel"`
+	Allergens   []Allergen   `json:"allergens"`
+	DietaryTags []DietaryTag `json:"dietaryTags"`
+}
+
+// UnmarshalJSON treats a row without "available" as available, so files
+// written by hand only need to mention archived items.
+func (r *MenuImportRow) UnmarshalJSON(data []byte) error {
+	type plain MenuImportRow
+	row := plain{Available: true}
+	if err := json.Unmarshal(data, &row); err != nil {
+		return err
+	}
+	*r = MenuImportRow(row)
+	return nil
+}
+
+// NewMenuImportRow is the row an export writes for the item.
+func NewMenuImportRow(item MenuItem) MenuImportRow {
+	row := MenuImportRow{
+		SKU:         item.SKU,
+		Name:        item.Name,
+		Description: item.Description,
+		Category:    item.Category,
+		Price:       item.Price,
+		Available:   item.Available,
+		SpicyLevel:  item.SpicyLevel,
+		Allergens:   item.Allergens,
+		DietaryTags: item.DietaryTags,
+	}
+	if item.Stock != nil {
+		stock := *item.Stock
+		row.Stock = &stock
+	}
+	return row
+}
+
+// Validate reports every problem with the row rather than just the first.
+func (r MenuImportRow) Validate() []MenuImportError {
+	var errs []MenuImportError
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, MenuImportError{Row: r.Row, SKU: r.SKU, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case r.SKU == "":
+		fail("sku", "SKU is required")
+	case len(r.SKU) > MaxSKULength:
+		fail("sku", "SKU must be at most %d characters", MaxSKULength)
+	case strings.TrimSpace(r.SKU) != r.SKU || strings.ContainsAny(r.SKU, " \t\r\n"):
+		fail("sku", "SKU must not contain spaces")
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		fail("name", "name is required")
+	}
+	if strings.TrimSpace(r.Category) == "" {
+		fail("category", "category is required")
+	}
+	if r.Price <= 0 {
+		fail("price", "price must be positive")
+	}
+	if r.Stock != nil && *r.Stock < 0 {
+		fail("stock", "stock must not be negative")
+	}
+	if r.SpicyLevel < 0 || r.SpicyLevel > MaxSpicyLevel {
+		fail("spicyLevel", "spicy level must be between 0 and %d", MaxSpicyLevel)
+	}
+	for _, a := range r.Allergens {
+		if !a.IsValid() {
+			fail("allergens", "unknown allergen %q", a)
+		}
+	}
+	for _, t := range r.DietaryTags {
+		switch {
+		case t == DietaryTagSpicy:
+			fail("dietaryTags", "the spicy tag follows from spicyLevel and cannot be set")
+		case !t.IsValid():
+			fail("dietaryTags", "unknown dietary tag %q", t)
+		}
+	}
+	return errs
+}
+
+type MenuImportAction string
+
+const (
+	MenuImportCreate  MenuImportAction = "create"
+	MenuImportUpdate  MenuImportAction = "update"
+	MenuImportArchive MenuImportAction = "archive"
+)
+
+// MenuImport is an import file as read, with the rows that could not be
+// read at all reported in Errors.
+type MenuImport struct {
+	Rows   []MenuImportRow
+	Errors []MenuImportError
+
+	DryRun bool
+	// ArchiveMissing archives the items the file leaves out. Otherwise the
+	// file only adds to and changes the menu.
+	ArchiveMissing bool
+}
+
+// MenuImportChange is one line of the diff between an import file and the
+// menu.
+type MenuImportChange struct {
+	Row    int              `json:"row,omitempty"` // zero for items missing from the file
+	SKU    string           `json:"sku"`
+	Action MenuImportAction `json:"action"`
+	Fields []string         `json:"fields,omitempty"` // what an update changes, named as in the file
+
+	// Item is the menu item as it is to be stored. Updates and archives
+	// carry the id of the existing item.
+	Item MenuItem `json:"-"`
+}
+
+// Changes reports whether the update changes the field.
+func (c MenuImportChange) Changes(field string) bool {
+	for _, f := range c.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// MenuImportError is a problem with one row, or with the file when Row is
+// zero.
+type MenuImportError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e MenuImportError) Error() string {
+	var b strings.Builder
+	if e.Row > 0 {
+		fmt.Fprintf(&b, "row %d: ", e.Row)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, "%s: ", e.Field)
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// MenuImportResult is the diff of an import and whether it was applied.
+// Nothing is applied when there are errors or on a dry run.
+type MenuImportResult struct {
+	DryRun    bool               `json:"dryRun"`
+	Applied   bool               `json:"applied"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Archived  int                `json:"archived"`
+	Unchanged int                `json:"unchanged"`
+	Changes   []MenuImportChange `json:"changes"`
+	Errors    []MenuImportError  `json:"errors"`
+}
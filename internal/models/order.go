@@ -6,6 +6,8 @@ const (
 	OrderStatusPending   OrderStatus = "pending"
 	OrderStatusCompleted OrderStatus = "completed"
 	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusPaid      OrderStatus = "paid"     // payments cover the total
+	OrderStatusRefunded  OrderStatus = "refunded" // everything paid was refunded
 )
 
 func (os OrderStatus) String() string {
@@ -15,26 +17,101 @@ func (os OrderStatus) String() string {
 type Order struct {
 	ID         int         `json:"id"`
 	UserID     int         `json:"userId"`
-	Status     OrderStatus `json:"status"`  // e.g., "pending", "completed", "cancelled"
-	ItemsID    []int       `json:"itemsId"` // List of item IDs in the order
-	Items      []OrderItem `json:"items"`   // List of items in the order
-	TotalPrice float64     `json:"totalPrice"`
+	Status     OrderStatus `json:"status"`     // e.g., "pending", "completed", "cancelled"
+	ItemsID    []int       `json:"itemsId"`    // List of item IDs in the order
+	Items      []OrderItem `json:"items"`      // List of items in the order
+	TotalPrice int         `json:"totalPrice"` // grand total in cents
 	TableID    int         `json:"tableId"`
 	CreatedAt  string      `json:"createdAt"`
 	UpdatedAt  string      `json:"updatedAt"`
+
+	// Price breakdown in cents, always computed on the server
+	Subtotal      int             `json:"subtotal"` // sum of the lines at menu prices
+	DiscountTotal int             `json:"discountTotal"`
+	CouponCode    string          `json:"couponCode,omitempty"`
+	Discounts     []OrderDiscount `json:"discounts"`
+	TaxInclusive  bool            `json:"taxInclusive"` // menu prices already include tax
+	Taxes         []OrderTax      `json:"taxes"`
+	TaxTotal      int             `json:"taxTotal"`
+	Guests        int             `json:"guests"`
+	ServiceCharge int             `json:"serviceCharge"`
+	Tip           int             `json:"tip"`
+	TipPercent    int             `json:"-"`             // as requested, Tip is worked out from it
+	RefundedTotal int             `json:"refundedTotal"` // completed refunds, any method
+	CashRefunded  int             `json:"cashRefunded"`  // the part of RefundedTotal paid out in cash
+	KitchenStatus KitchenStatus   `json:"kitchenStatus,omitempty"`
 }
 
 type OrderItem struct {
-	MenuItemID int     `json:"menuItemId"`
-	Quantity   int     `json:"quantity"`
-	Price      float64 `json:"price"` // Price per item
+	ID         int    `json:"id,omitempty"`
+	MenuItemID int    `json:"menuItemId"` // zero for bundle lines
+	Quantity   int    `json:"quantity"`
+	Price      int    `json:"price"`          // Price per item, in cents
+	Note       string `json:"note,omitempty"` // cooking instructions for the kitchen
+
+	// Bundle lines are charged at the bundle price; Components records what
+	// was actually chosen so the kitchen and stock see the real items.
+	BundleID   *int                 `json:"bundleId,omitempty"`
+	Choices    []BundleChoice       `json:"-"` // as requested, before validation
+	Components []OrderItemComponent `json:"components,omitempty"`
+
+	Taxes []LineTax `json:"-"` // the line's price per tax rate, see LineTax
 	// we can extend this with item level discounts or fields like cooking instructions etc...
 }
 
+// OrderItemComponent is a menu item chosen for a bundle slot. Quantity is
+// per bundle, so the kitchen makes Quantity times the line's quantity.
+type OrderItemComponent struct {
+	SlotID     int `json:"slotId"`
+	MenuItemID int `json:"menuItemId"`
+	Quantity   int `json:"quantity"`
+}
+
+func (i OrderItem) IsBundle() bool {
+	return i.BundleID != nil
+}
+
+// KitchenItem is a real menu item to prepare (and take out of stock).
+type KitchenItem struct {
+	MenuItemID int  `json:"menuItemId"`
+	Quantity   int  `json:"quantity"`
+	BundleID   *int `json:"bundleId,omitempty"`
+}
+
+// KitchenItems expands bundle lines into their components.
+func (o Order) KitchenItems() []KitchenItem {
+	var items []KitchenItem
+	for _, item := range o.Items {
+		if !item.IsBundle() {
+			items = append(items, KitchenItem{MenuItemID: item.MenuItemID, Quantity: item.Quantity})
+			continue
+		}
+		for _, c := range item.Components {
+			items = append(items, KitchenItem{
+				MenuItemID: c.MenuItemID,
+				Quantity:   c.Quantity * item.Quantity,
+				BundleID:   item.BundleID,
+			})
+		}
+	}
+	return items
+}
+
+// CalculateTotalPrice totals the lines and discounts and adds the tax,
+// service charge and tip already worked out on the order.
 func (o *Order) CalculateTotalPrice() {
-	total := 0.0
+	subtotal := 0
 	for _, item := range o.Items {
-		total += item.Price * float64(item.Quantity)
+		subtotal += item.Price * item.Quantity
+	}
+	discount := 0
+	for _, d := range o.Discounts {
+		discount += d.Amount
+	}
+	o.Subtotal = subtotal
+	o.DiscountTotal = min(discount, subtotal)
+	o.TotalPrice = subtotal - o.DiscountTotal + o.ServiceCharge + o.Tip
+	if !o.TaxInclusive {
+		o.TotalPrice += o.TaxTotal
 	}
-	o.TotalPrice = total
 }
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOrderListLimit = 20
+	MaxOrderListLimit     = 100
+)
+
+// Fields orders can be listed by.
+const (
+	OrderSortCreatedAt = "createdAt"
+	OrderSortTotal     = "total"
+)
+
+// OrderListQuery selects one page of orders. Zero filters match every
+// order.
+type OrderListQuery struct {
+	Status   OrderStatus
+	TableID  int
+	UserID   int
+	From     time.Time // placed at or after
+	To       time.Time // placed before
+	MinTotal int       // in cents
+
+	SortBy string // OrderSortCreatedAt or OrderSortTotal, ties broken by id
+	Desc   bool
+	Limit  int
+	After  *OrderCursor // the last order of the previous page
+}
+
+// ParseOrderSort reads a sort such as "createdAt" or "-total", where a
+// leading minus sorts in descending order. Empty lists the newest first.
+func ParseOrderSort(s string) (sortBy string, desc bool, err error) {
+	if s == "" {
+		return OrderSortCreatedAt, true, nil
+	}
+	desc = strings.HasPrefix(s, "-")
+	sortBy = strings.TrimPrefix(s, "-")
+	if sortBy != OrderSortCreatedAt && sortBy != OrderSortTotal {
+		return "", false, fmt.Errorf("%w: sort must be createdAt or total, optionally preceded by -, got %q", ErrInvalidInput, s)
+	}
+	return sortBy, desc, nil
+}
+
+// Sort is the sort of the query as ParseOrderSort reads it.
+func (q OrderListQuery) Sort() string {
+	if q.Desc {
+		return "-" + q.SortBy
+	}
+	return q.SortBy
+}
+
+// Normalize fills in defaults and clamps the page size.
+func (q *OrderListQuery) Normalize() {
+	if q.SortBy == "" {
+		q.SortBy, q.Desc = OrderSortCreatedAt, true
+	}
+	if q.Limit < 1 {
+		q.Limit = DefaultOrderListLimit
+	}
+	if q.Limit > MaxOrderListLimit {
+		q.Limit = MaxOrderListLimit
+	}
+}
+
+// Matches reports whether the order passes the filters, for repositories
+// that filter in Go.
+func (q OrderListQuery) Matches(order Order, placedAt time.Time) bool {
+	return (q.Status == "" || order.Status == q.Status) &&
+		(q.TableID == 0 || order.TableID == q.TableID) &&
+		(q.UserID == 0 || order.UserID == q.UserID) &&
+		(q.From.IsZero() || !placedAt.Before(q.From)) &&
+		(q.To.IsZero() || placedAt.Before(q.To)) &&
+		order.TotalPrice >= q.MinTotal
+}
+
+// OrderCursor is where a page ends: the sort key and id of its last order.
+// It only continues a listing with the same sort.
+type OrderCursor struct {
+	Sort      string    `json:"sort"`
+	CreatedAt time.Time `json:"createdAt"`
+	Total     int       `json:"total,omitempty"`
+	ID        int       `json:"id"`
+}
+
+// CursorAfter is the cursor of a page that ends with order, which was
+// placed at placedAt.
+func (q OrderListQuery) CursorAfter(order Order, placedAt time.Time) OrderCursor {
+	cursor := OrderCursor{Sort: q.Sort(), ID: order.ID}
+	if q.SortBy == OrderSortTotal {
+		cursor.Total = order.TotalPrice
+	} else {
+		cursor.CreatedAt = placedAt
+	}
+	return cursor
+}
+
+// String encodes the cursor for a URL.
+func (c OrderCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseOrderCursor decodes a cursor String made for a listing sorted by
+// sort.
+func ParseOrderCursor(s, sort string) (OrderCursor, error) {
+	var cursor OrderCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID < 1 {
+		return OrderCursor{}, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	if cursor.Sort != sort {
+		return OrderCursor{}, fmt.Errorf("%w: the cursor is for sort %q, not %q", ErrInvalidInput, cursor.Sort, sort)
+	}
+	// A cursor holds the key of its sort and nothing else
+	byTotal := strings.TrimPrefix(sort, "-") == OrderSortTotal
+	if byTotal && (cursor.Total < 0 || !cursor.CreatedAt.IsZero()) ||
+		!byTotal && (cursor.CreatedAt.IsZero() || cursor.Total != 0) {
+		return OrderCursor{}, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	return cursor, nil
+}
+
+// OrderPage is one page of a listing. NextCursor is empty on the last page.
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	Total      int     `json:"total"` // orders matching the filters on all pages
+	Limit      int     `json:"limit"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
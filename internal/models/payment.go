@@ -0,0 +1,192 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPaymentConflict   = errors.New("payment is not in a state that allows this")
+	ErrGatewayFailure    = errors.New("payment gateway failure")
+	ErrInvalidSignature  = errors.New("invalid webhook signature")
+	ErrPaymentNotAllowed = errors.New("order cannot take payments")
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending" // waiting for the gateway, e.g. 3-D Secure
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusCaptured          PaymentStatus = "captured"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusVoided            PaymentStatus = "voided"
+	PaymentStatusFailed            PaymentStatus = "failed"
+)
+
+func (s PaymentStatus) String() string {
+	return string(s)
+}
+
+// paymentTransitions lists the statuses a payment may move to from each status.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:           {PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusFailed, PaymentStatusVoided},
+	PaymentStatusAuthorized:        {PaymentStatusCaptured, PaymentStatusVoided, PaymentStatusFailed},
+	PaymentStatusCaptured:          {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+}
+
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Payment is one attempt to pay (part of) an order through a gateway.
+// Amounts are in cents.
+type Payment struct {
+	ID             int           `json:"id"`
+	OrderID        int           `json:"orderId"`
+	CheckID        *int          `json:"checkId,omitempty"` // set when the bill is split
+	Provider       string        `json:"provider"`
+	Reference      string        `json:"reference,omitempty"` // the gateway's id for the payment
+	Status         PaymentStatus `json:"status"`
+	Amount         int           `json:"amount"`
+	Captured       int           `json:"captured"`
+	Refunded       int           `json:"refunded"`
+	Currency       string        `json:"currency"`
+	FailureReason  string        `json:"failureReason,omitempty"`
+	IdempotencyKey string        `json:"-"`
+	CreatedBy      int           `json:"createdBy"`
+	CreatedAt      string        `json:"createdAt"`
+	UpdatedAt      string        `json:"updatedAt"`
+}
+
+// Committed is the part of the payment that counts towards the order: held
+// or captured money, less refunds.
+func (p Payment) Committed() int {
+	switch p.Status {
+	case PaymentStatusPending, PaymentStatusAuthorized:
+		return p.Amount
+	case PaymentStatusCaptured, PaymentStatusPartiallyRefunded, PaymentStatusRefunded:
+		return p.Captured - p.Refunded
+	}
+	return 0
+}
+
+// Balance is what is still to be paid on the order, counting payments that
+// are pending or authorized as well as captured ones. Refunds lower what is
+// owed as much as what was paid, so they never reopen a balance.
+func (o Order) Balance(payments []Payment) int {
+	committed := 0
+	for _, p := range payments {
+		committed += p.Committed()
+	}
+	return max(o.TotalPrice-o.RefundedTotal-(committed-o.CashRefunded), 0)
+}
+
+// StatusAfterPayments is the order status implied by its payments and
+// refunds. Orders that were completed or cancelled keep their status.
+// Payments on a split bill are capped at each check's balance, so the order is
+// only paid once every check is.
+func (o Order) StatusAfterPayments(payments []Payment) OrderStatus {
+	switch o.Status {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusRefunded:
+	default:
+		return o.Status
+	}
+
+	captured, net := 0, -o.CashRefunded
+	for _, p := range payments {
+		captured += p.Captured
+		net += p.Captured - p.Refunded
+	}
+	switch {
+	case captured > 0 && net <= 0:
+		return OrderStatusRefunded
+	case o.TotalPrice > 0 && net >= o.TotalPrice-o.RefundedTotal:
+		return OrderStatusPaid
+	default:
+		return OrderStatusPending
+	}
+}
+
+// BalanceFor is what is left to pay on the order, or on its check checkId.
+// Once the bill is split, every payment has to be for one of the checks.
+func (o Order) BalanceFor(checkId int, checks []Check, payments []Payment) (int, error) {
+	if checkId == 0 {
+		if len(checks) > 0 {
+			return 0, fmt.Errorf("%w: pay one of the order's checks", ErrOrderSplit)
+		}
+		return o.Balance(payments), nil
+	}
+	for _, c := range checks {
+		if c.ID == checkId {
+			c.ApplyPayments(payments)
+			return c.Balance, nil
+		}
+	}
+	return 0, fmt.Errorf("check not found with id %d: %w", checkId, ErrNotFound)
+}
+
+// CheckPaymentFits is the check repositories make, atomically with
+// recording the payment, that a new payment is for a pending order and
+// no more than is left to pay on it or its check. Two payments started
+// together thus cannot both take the same balance.
+func (o Order) CheckPaymentFits(p Payment, checks []Check, payments []Payment) error {
+	if o.Status != OrderStatusPending {
+		return fmt.Errorf("%w: order is %s", ErrPaymentNotAllowed, o.Status)
+	}
+	checkId := 0
+	if p.CheckID != nil {
+		checkId = *p.CheckID
+	}
+	balance, err := o.BalanceFor(checkId, checks, payments)
+	if err != nil {
+		return err
+	}
+	if p.Amount > balance {
+		return fmt.Errorf("%w: only %d cents are left to pay", ErrPaymentConflict, balance)
+	}
+	return nil
+}
+
+// Refundable is the money taken on the order that has not been refunded.
+func (o Order) Refundable(payments []Payment) int {
+	net := -o.CashRefunded
+	for _, p := range payments {
+		net += p.Captured - p.Refunded
+	}
+	return max(net, 0)
+}
+
+// PaymentWebhookEvent is a notification received from a gateway. Events are
+// recorded by provider and event id so a redelivery is only processed once.
+type PaymentWebhookEvent struct {
+	ID            int           `json:"id"`
+	Provider      string        `json:"provider"`
+	EventID       string        `json:"eventId"`
+	Type          string        `json:"type"`
+	Reference     string        `json:"reference"`
+	Status        PaymentStatus `json:"status"`
+	Amount        int           `json:"amount"`
+	FailureReason string        `json:"failureReason,omitempty"`
+	Payload       []byte        `json:"-"`
+	ReceivedAt    time.Time     `json:"receivedAt"`
+}
+
+// PaymentRequest asks to pay Amount of an order, or whatever is left to pay
+// when Amount is zero.
+type PaymentRequest struct {
+	OrderID        int
+	CheckID        int // required once the bill is split
+	UserID         int
+	Amount         int
+	Source         string // card token or other payment method reference
+	AuthorizeOnly  bool   // hold the funds and capture later
+	IdempotencyKey string
+}
@@ -0,0 +1,19 @@
+package models
+
+// PoolStats describes a database connection pool at one moment. The counts
+// are since the pool was opened.
+type PoolStats struct {
+	MaxConns          int `json:"maxConns"`
+	TotalConns        int `json:"totalConns"` // idle, acquired and being opened
+	IdleConns         int `json:"idleConns"`
+	AcquiredConns     int `json:"acquiredConns"`
+	ConstructingConns int `json:"constructingConns"`
+
+	AcquireCount            int64 `json:"acquireCount"`
+	AcquireDurationMs       int64 `json:"acquireDurationMs"`    // spent waiting for connections in total
+	EmptyAcquireCount       int64 `json:"emptyAcquireCount"`    // acquires that waited as no connection was idle
+	CanceledAcquireCount    int64 `json:"canceledAcquireCount"` // acquires given up before a connection was free
+	NewConnsCount           int64 `json:"newConnsCount"`
+	MaxLifetimeDestroyCount int64 `json:"maxLifetimeDestroyCount"` // connections closed for their age
+	MaxIdleDestroyCount     int64 `json:"maxIdleDestroyCount"`     // connections closed for being idle
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type PriceStatus string
+
+const (
+	PriceStatusPast      PriceStatus = "past"
+	PriceStatusCurrent   PriceStatus = "current"
+	PriceStatusScheduled PriceStatus = "scheduled"
+)
+
+// MenuItemPrice is one entry of a menu item's price history. A price applies
+// from EffectiveFrom until the next entry takes over.
+type MenuItemPrice struct {
+	ID            int         `json:"id"`
+	MenuItemID    int         `json:"menuItemId"`
+	Price         int         `json:"price"` // in cents
+	EffectiveFrom time.Time   `json:"effectiveFrom"`
+	CreatedBy     *int        `json:"createdBy,omitempty"` // nil for prices predating the history
+	Note          string      `json:"note,omitempty"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	Status        PriceStatus `json:"status"`
+}
+
+type PriceTimeline struct {
+	MenuItemID   int             `json:"menuItemId"`
+	CurrentPrice int             `json:"currentPrice"`
+	Entries      []MenuItemPrice `json:"entries"`
+}
+
+// NewPriceTimeline marks each entry as past, current or scheduled relative to
+// now. entries must be sorted by EffectiveFrom.
+func NewPriceTimeline(menuItemID, basePrice int, entries []MenuItemPrice, now time.Time) PriceTimeline {
+	timeline := PriceTimeline{
+		MenuItemID:   menuItemID,
+		CurrentPrice: basePrice,
+		Entries:      entries,
+	}
+	current := -1
+	for i := range entries {
+		if entries[i].EffectiveFrom.After(now) {
+			entries[i].Status = PriceStatusScheduled
+			continue
+		}
+		entries[i].Status = PriceStatusPast
+		current = i
+	}
+	if current >= 0 {
+		entries[current].Status = PriceStatusCurrent
+		timeline.CurrentPrice = entries[current].Price
+	}
+	return timeline
+}
@@ -0,0 +1,36 @@
+package models
+
+type PrintJobKind string
+
+const (
+	PrintJobKitchen PrintJobKind = "kitchen" // a kitchen ticket for one station
+	PrintJobReceipt PrintJobKind = "receipt"
+)
+
+func (k PrintJobKind) Valid() bool {
+	return k == PrintJobKitchen || k == PrintJobReceipt
+}
+
+type PrintJobStatus string
+
+const (
+	PrintJobQueued   PrintJobStatus = "queued"
+	PrintJobRetrying PrintJobStatus = "retrying" // the last attempt failed, another one is scheduled
+	PrintJobPrinted  PrintJobStatus = "printed"
+	PrintJobFailed   PrintJobStatus = "failed" // gave up after the last attempt
+)
+
+// PrintJob is a document sent to a printer. Data is the ESC/POS byte stream.
+type PrintJob struct {
+	ID        int            `json:"id"`
+	OrderID   int            `json:"orderId"`
+	Kind      PrintJobKind   `json:"kind"`
+	Station   string         `json:"station"`
+	Printer   string         `json:"printer"`
+	Status    PrintJobStatus `json:"status"`
+	Attempts  int            `json:"attempts"`
+	LastError string         `json:"lastError,omitempty"`
+	CreatedAt string         `json:"createdAt"`
+	PrintedAt string         `json:"printedAt,omitempty"`
+	Data      []byte         `json:"-"`
+}
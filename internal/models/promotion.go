@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCouponNotFound        = errors.New("coupon not found")
+	ErrCouponNotApplicable   = errors.New("coupon not applicable")
+	ErrPromotionLimitReached = errors.New("promotion usage limit reached")
+)
+
+type PromotionType string
+
+const (
+	PromotionTypePercent  PromotionType = "percent"     // Value percent off
+	PromotionTypeFixed    PromotionType = "fixed"       // Value cents off
+	PromotionTypeBuyXGetY PromotionType = "buy_x_get_y" // every BuyQuantity+FreeQuantity units, the cheapest FreeQuantity are free
+)
+
+func (t PromotionType) String() string {
+	return string(t)
+}
+
+// Promotion is either a coupon, redeemed by entering Code, or an automatic
+// rule applied to every order it matches (Code is empty).
+type Promotion struct {
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Code         string        `json:"code,omitempty"`
+	Type         PromotionType `json:"type"`
+	Value        int           `json:"value,omitempty"`
+	BuyQuantity  int           `json:"buyQuantity,omitempty"`
+	FreeQuantity int           `json:"freeQuantity,omitempty"`
+
+	// Category restricts the promotion to items of that category
+	Category string `json:"category,omitempty"`
+	MinSpend int    `json:"minSpend,omitempty"` // in cents, on the order subtotal
+
+	// Validity window, and an optional daily window such as "17:00"-"19:00"
+	StartsAt   *time.Time `json:"startsAt,omitempty"`
+	EndsAt     *time.Time `json:"endsAt,omitempty"`
+	DailyStart string     `json:"dailyStart,omitempty"`
+	DailyEnd   string     `json:"dailyEnd,omitempty"`
+
+	UsageLimit   int `json:"usageLimit,omitempty"`   // orders in total, 0 is unlimited
+	PerUserLimit int `json:"perUserLimit,omitempty"` // orders per user, 0 is unlimited
+
+	// Stackable promotions combine with each other; a non-stackable one is
+	// only ever applied on its own.
+	Stackable bool   `json:"stackable"`
+	Active    bool   `json:"active"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+func (p Promotion) IsCoupon() bool {
+	return p.Code != ""
+}
+
+// NormalizeCouponCode makes coupon codes case and whitespace insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p Promotion) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: promotion name is required", ErrInvalidInput)
+	}
+	switch p.Type {
+	case PromotionTypePercent:
+		if p.Value < 1 || p.Value > 100 {
+			return fmt.Errorf("%w: percent must be between 1 and 100", ErrInvalidInput)
+		}
+	case PromotionTypeFixed:
+		if p.Value < 1 {
+			return fmt.Errorf("%w: fixed discount must be positive", ErrInvalidInput)
+		}
+	case PromotionTypeBuyXGetY:
+		if p.BuyQuantity < 1 || p.FreeQuantity < 1 {
+			return fmt.Errorf("%w: buy and free quantities must be positive", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown promotion type %q", ErrInvalidInput, p.Type)
+	}
+	if p.MinSpend < 0 || p.UsageLimit < 0 || p.PerUserLimit < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidInput)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: promotion must end after it starts", ErrInvalidInput)
+	}
+	if (p.DailyStart == "") != (p.DailyEnd == "") {
+		return fmt.Errorf("%w: daily window needs both a start and an end", ErrInvalidInput)
+	}
+	if p.DailyStart != "" {
+		if _, err := parseClock(p.DailyStart); err != nil {
+			return err
+		}
+		if _, err := parseClock(p.DailyEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActiveAt reports whether the promotion's validity and daily windows
+// include t. The daily window is evaluated in t's location.
+func (p Promotion) ActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	if p.DailyStart == "" {
+		return true
+	}
+
+	start, err1 := parseClock(p.DailyStart)
+	end, err2 := parseClock(p.DailyEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// Window wraps past midnight, e.g. 22:00-02:00
+	return minute >= start || minute < end
+}
+
+// parseClock parses "HH:MM" into minutes past midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time of day %q, expected HH:MM", ErrInvalidInput, s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// PromotionUsage counts the orders a promotion has been applied to.
+type PromotionUsage struct {
+	Total   int
+	ForUser int
+}
+
+// OrderDiscount is one line of an order's discount breakdown.
+type OrderDiscount struct {
+	PromotionID int    `json:"promotionId"`
+	Name        string `json:"name"`
+	Code        string `json:"code,omitempty"`
+	Amount      int    `json:"amount"` // in cents
+}
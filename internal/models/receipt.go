@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrOrderNotPaid = errors.New("order is not paid")
+
+// Invoice is a tax invoice issued for a paid order. Numbers run without gaps
+// within each fiscal year.
+type Invoice struct {
+	ID              int    `json:"id"`
+	OrderID         int    `json:"orderId"`
+	FiscalYear      int    `json:"fiscalYear"`
+	Sequence        int    `json:"sequence"`
+	Number          string `json:"number"` // e.g. INV-2024-000042
+	CustomerName    string `json:"customerName"`
+	CustomerTaxID   string `json:"customerTaxId,omitempty"`
+	CustomerAddress string `json:"customerAddress,omitempty"`
+	IssuedAt        string `json:"issuedAt"`
+}
+
+func (i *Invoice) Validate() error {
+	i.CustomerName = strings.TrimSpace(i.CustomerName)
+	i.CustomerTaxID = strings.TrimSpace(i.CustomerTaxID)
+	i.CustomerAddress = strings.TrimSpace(i.CustomerAddress)
+	if i.CustomerName == "" {
+		return fmt.Errorf("%w: invoices need the customer's name", ErrInvalidInput)
+	}
+	if len(i.CustomerName) > 200 || len(i.CustomerTaxID) > 50 || len(i.CustomerAddress) > 500 {
+		return fmt.Errorf("%w: customer details are too long", ErrInvalidInput)
+	}
+	return nil
+}
+
+// FiscalYear is the year the fiscal year containing t started in.
+func FiscalYear(t time.Time, startMonth int) int {
+	if startMonth > 1 && int(t.Month()) < startMonth {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+func InvoiceNumber(fiscalYear, sequence int) string {
+	return fmt.Sprintf("INV-%d-%06d", fiscalYear, sequence)
+}
+
+// RestaurantDetails head every receipt.
+type RestaurantDetails struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+	TaxID   string `json:"taxId,omitempty"`
+}
+
+// Receipt is everything printed on a receipt or invoice. Amounts are in
+// cents.
+type Receipt struct {
+	Restaurant RestaurantDetails `json:"restaurant"`
+	Invoice    *Invoice          `json:"invoice,omitempty"`
+	Order      Order             `json:"order"`
+	Lines      []ReceiptLine     `json:"lines"`
+	Payments   []Payment         `json:"payments"` // captured ones only
+	Refunds    []Refund          `json:"refunds"`  // completed ones only
+	Paid       int               `json:"paid"`
+	Balance    int               `json:"balance"`
+	Currency   string            `json:"currency"`
+	PrintedAt  time.Time         `json:"printedAt"`
+}
+
+type ReceiptLine struct {
+	Name       string   `json:"name"`
+	Quantity   int      `json:"quantity"`
+	UnitPrice  int      `json:"unitPrice"`
+	Total      int      `json:"total"`
+	Components []string `json:"components,omitempty"` // what was chosen for a bundle
+}
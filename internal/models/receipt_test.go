@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFiscalYear(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		t          time.Time
+		startMonth int
+		want       int
+	}{
+		{"calendar year, first day", date(2025, time.January, 1), 1, 2025},
+		{"calendar year, last day", date(2025, time.December, 31), 1, 2025},
+		{"unset start month", date(2025, time.March, 15), 0, 2025},
+		{"april start, last day before", date(2025, time.March, 31), 4, 2024},
+		{"april start, first day", date(2025, time.April, 1), 4, 2025},
+		{"april start, december", date(2025, time.December, 31), 4, 2025},
+		{"april start, january", date(2026, time.January, 1), 4, 2025},
+		{"july start, june", date(2025, time.June, 30), 7, 2024},
+		{"july start, july", date(2025, time.July, 1), 7, 2025},
+		{"december start, november", date(2025, time.November, 30), 12, 2024},
+		{"december start, december", date(2025, time.December, 1), 12, 2025},
+	}
+	for _, tt := range tests {
+		if got := FiscalYear(tt.t, tt.startMonth); got != tt.want {
+			t.Errorf("%s: FiscalYear(%s, %d) = %d, want %d", tt.name, tt.t.Format("2006-01-02"), tt.startMonth, got, tt.want)
+		}
+	}
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	tests := []struct {
+		year, sequence int
+		want           string
+	}{
+		{2024, 1, "INV-2024-000001"},
+		{2024, 42, "INV-2024-000042"},
+		{2025, 1234567, "INV-2025-1234567"},
+	}
+	for _, tt := range tests {
+		if got := InvoiceNumber(tt.year, tt.sequence); got != tt.want {
+			t.Errorf("InvoiceNumber(%d, %d) = %q, want %q", tt.year, tt.sequence, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrRefundNotAllowed = errors.New("refund is not allowed")
+
+type RefundReason string
+
+const (
+	RefundReasonQuality         RefundReason = "quality" // burnt, cold, undercooked...
+	RefundReasonWrongItem       RefundReason = "wrong_item"
+	RefundReasonMissingItem     RefundReason = "missing_item"
+	RefundReasonLate            RefundReason = "late"
+	RefundReasonDuplicateCharge RefundReason = "duplicate_charge"
+	RefundReasonCustomerRequest RefundReason = "customer_request"
+	RefundReasonOther           RefundReason = "other"
+)
+
+var refundReasons = []RefundReason{
+	RefundReasonQuality, RefundReasonWrongItem, RefundReasonMissingItem, RefundReasonLate,
+	RefundReasonDuplicateCharge, RefundReasonCustomerRequest, RefundReasonOther,
+}
+
+func (r RefundReason) Valid() bool {
+	for _, reason := range refundReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+type RefundMethod string
+
+const (
+	RefundMethodGateway RefundMethod = "gateway" // back to the card through the payment gateway
+	RefundMethodCash    RefundMethod = "cash"    // handed out from the till and only recorded
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPendingApproval RefundStatus = "pending_approval"
+	RefundStatusProcessing      RefundStatus = "processing" // approved, money on its way back
+	RefundStatusCompleted       RefundStatus = "completed"
+	RefundStatusRejected        RefundStatus = "rejected"
+	RefundStatusFailed          RefundStatus = "failed"
+)
+
+// Refund gives money back on an order, either as an amount or for order
+// lines. Amounts are in cents.
+type Refund struct {
+	ID            int          `json:"id"`
+	OrderID       int          `json:"orderId"`
+	PaymentID     *int         `json:"paymentId,omitempty"` // the payment refunded through the gateway
+	Method        RefundMethod `json:"method"`
+	Status        RefundStatus `json:"status"`
+	Reason        RefundReason `json:"reason"`
+	Note          string       `json:"note,omitempty"`
+	Amount        int          `json:"amount"`
+	Items         []RefundItem `json:"items"`
+	Restock       bool         `json:"restock"`
+	FailureReason string       `json:"failureReason,omitempty"`
+	RequestedBy   int          `json:"requestedBy"`
+	ApprovedBy    int          `json:"approvedBy,omitempty"`
+	CreatedAt     string       `json:"createdAt"`
+	ProcessedAt   string       `json:"processedAt,omitempty"`
+}
+
+// RefundItem refunds Quantity units of an order line; Amount is the line's
+// share of what was charged for those units.
+type RefundItem struct {
+	OrderItemID int `json:"orderItemId"`
+	Quantity    int `json:"quantity"`
+	Amount      int `json:"amount"`
+}
+
+func (r *Refund) Validate() error {
+	r.Note = strings.TrimSpace(r.Note)
+	if !r.Reason.Valid() {
+		return fmt.Errorf("%w: unknown refund reason %q", ErrInvalidInput, r.Reason)
+	}
+	if r.Reason == RefundReasonOther && r.Note == "" {
+		return fmt.Errorf("%w: a note is required for other refunds", ErrInvalidInput)
+	}
+	switch r.Method {
+	case RefundMethodGateway:
+	case RefundMethodCash:
+		if r.PaymentID != nil {
+			return fmt.Errorf("%w: cash refunds are not made against a payment", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: refund method must be gateway or cash", ErrInvalidInput)
+	}
+	if r.Amount < 0 {
+		return fmt.Errorf("%w: refund amount must not be negative", ErrInvalidInput)
+	}
+	for _, item := range r.Items {
+		if item.Quantity < 1 {
+			return fmt.Errorf("%w: refunded quantity must be at least 1", ErrInvalidInput)
+		}
+	}
+	if r.Restock && len(r.Items) == 0 {
+		return fmt.Errorf("%w: only line refunds can restock", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Open reports whether the refund still counts against what can be refunded.
+func (r Refund) Open() bool {
+	return r.Status == RefundStatusPendingApproval || r.Status == RefundStatusProcessing
+}
+
+// RefundedQuantities sums the units of each order line that completed or
+// open refunds already cover.
+func RefundedQuantities(refunds []Refund) map[int]int {
+	quantities := make(map[int]int)
+	for _, r := range refunds {
+		if r.Status == RefundStatusRejected || r.Status == RefundStatusFailed {
+			continue
+		}
+		for _, item := range r.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+	return quantities
+}
+
+// StockMovement records a change to a menu item's stock.
+type StockMovement struct {
+	ID         int    `json:"id"`
+	MenuItemID int    `json:"menuItemId"`
+	Quantity   int    `json:"quantity"` // positive when stock comes back
+	Reason     string `json:"reason"`
+	RefundID   int    `json:"refundId,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// SalesReport sums what was sold and refunded over a period, in cents.
+type SalesReport struct {
+	From            string               `json:"from"`
+	To              string               `json:"to"`
+	Orders          int                  `json:"orders"`
+	GrossSales      int                  `json:"grossSales"`
+	Refunds         int                  `json:"refunds"`
+	NetSales        int                  `json:"netSales"`
+	RefundsByReason map[RefundReason]int `json:"refundsByReason"`
+	RefundsByMethod map[RefundMethod]int `json:"refundsByMethod"`
+	PendingRefunds  int                  `json:"pendingRefunds"` // waiting for approval
+}
@@ -0,0 +1,53 @@
+package models
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// Highlights are HTML: the text is escaped and the matches are wrapped
+	// in these markers.
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+type MenuSearchQuery struct {
+	Text  string
+	Page  int // 1-based
+	Limit int
+}
+
+// Normalize fills in defaults and clamps the page size.
+func (q *MenuSearchQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+}
+
+func (q MenuSearchQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+type MenuSearchHit struct {
+	Item       MenuItem            `json:"item"`
+	Rank       float64             `json:"rank"`
+	Highlights MenuSearchHighlight `json:"highlights"`
+}
+
+type MenuSearchHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type MenuSearchResult struct {
+	Query string          `json:"query"`
+	Hits  []MenuSearchHit `json:"hits"`
+	Total int             `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrOrderSplit = errors.New("order bill is split")
+
+type SplitMode string
+
+const (
+	SplitByItem SplitMode = "items" // each check gets the line items assigned to it
+	SplitBySeat SplitMode = "seats" // like items, with one check per seat
+	SplitEvenly SplitMode = "even"  // every check pays an equal share
+)
+
+type CheckStatus string
+
+const (
+	CheckStatusOpen CheckStatus = "open"
+	CheckStatusPaid CheckStatus = "paid"
+)
+
+// Check is one part of a split bill. Its amounts are shares of the order's
+// breakdown, so the checks of an order always add up to the order exactly.
+type Check struct {
+	ID            int         `json:"id"`
+	OrderID       int         `json:"orderId"`
+	Number        int         `json:"number"`
+	Label         string      `json:"label"`
+	Mode          SplitMode   `json:"mode"`
+	Seat          int         `json:"seat,omitempty"`
+	Items         []CheckItem `json:"items"`
+	Subtotal      int         `json:"subtotal"`
+	DiscountTotal int         `json:"discountTotal"`
+	Taxes         []OrderTax  `json:"taxes"`
+	TaxTotal      int         `json:"taxTotal"`
+	ServiceCharge int         `json:"serviceCharge"`
+	Tip           int         `json:"tip"`
+	TotalPrice    int         `json:"totalPrice"`
+	Paid          int         `json:"paid"` // captured less refunded
+	Balance       int         `json:"balance"`
+	Status        CheckStatus `json:"status"`
+}
+
+// CheckItem assigns Quantity units of an order line to a check.
+type CheckItem struct {
+	OrderItemID int `json:"orderItemId"`
+	Quantity    int `json:"quantity"`
+}
+
+// ApplyPayments works out what was paid on the check from the order's
+// payments and whether the check is settled.
+func (c *Check) ApplyPayments(payments []Payment) {
+	committed := 0
+	c.Paid = 0
+	for _, p := range payments {
+		if p.CheckID == nil || *p.CheckID != c.ID {
+			continue
+		}
+		committed += p.Committed()
+		c.Paid += p.Captured - p.Refunded
+	}
+	c.Balance = max(c.TotalPrice-committed, 0)
+	c.Status = CheckStatusOpen
+	if c.TotalPrice > 0 && c.Paid >= c.TotalPrice {
+		c.Status = CheckStatusPaid
+	}
+}
+
+// SplitRequest describes how to split an order's bill. Even splits take
+// Count checks, or one per entry in Checks when those only carry tips.
+type SplitRequest struct {
+	Mode   SplitMode
+	Count  int
+	Checks []CheckRequest
+}
+
+type CheckRequest struct {
+	Seat       int
+	Items      []CheckItem
+	Tip        int
+	TipPercent int
+}
+
+// Validate checks the request against the order: item and seat splits must
+// assign every unit of every line to exactly one check.
+func (r *SplitRequest) Validate(order Order) error {
+	switch r.Mode {
+	case SplitEvenly:
+		if r.Count == 0 {
+			r.Count = len(r.Checks)
+		}
+		if r.Count < 2 || r.Count > 50 {
+			return fmt.Errorf("%w: an even split needs between 2 and 50 checks", ErrInvalidInput)
+		}
+		if len(r.Checks) != 0 && len(r.Checks) != r.Count {
+			return fmt.Errorf("%w: give one entry in checks per check, or none", ErrInvalidInput)
+		}
+		for _, c := range r.Checks {
+			if len(c.Items) > 0 {
+				return fmt.Errorf("%w: an even split does not take items", ErrInvalidInput)
+			}
+		}
+	case SplitByItem, SplitBySeat:
+		if len(r.Checks) < 2 {
+			return fmt.Errorf("%w: a split needs at least 2 checks", ErrInvalidInput)
+		}
+		if err := r.validateItems(order); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: split mode must be items, seats or even", ErrInvalidInput)
+	}
+
+	for _, c := range r.Checks {
+		if c.Tip < 0 || c.TipPercent < 0 || c.TipPercent > 100 {
+			return fmt.Errorf("%w: tip must be between 0 and 100 percent", ErrInvalidInput)
+		}
+		if c.Tip > 0 && c.TipPercent > 0 {
+			return fmt.Errorf("%w: give the tip as an amount or a percentage, not both", ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+func (r *SplitRequest) validateItems(order Order) error {
+	remaining := make(map[int]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.ID] = item.Quantity
+	}
+
+	seats := make(map[int]bool)
+	for _, c := range r.Checks {
+		if r.Mode == SplitBySeat {
+			if c.Seat < 1 {
+				return fmt.Errorf("%w: every check needs a seat number", ErrInvalidInput)
+			}
+			if seats[c.Seat] {
+				return fmt.Errorf("%w: seat %d has more than one check", ErrInvalidInput, c.Seat)
+			}
+			seats[c.Seat] = true
+		}
+		if len(c.Items) == 0 {
+			return fmt.Errorf("%w: every check needs items", ErrInvalidInput)
+		}
+		listed := make(map[int]bool, len(c.Items))
+		for _, item := range c.Items {
+			if listed[item.OrderItemID] {
+				return fmt.Errorf("%w: order item %d is listed twice on a check", ErrInvalidInput, item.OrderItemID)
+			}
+			listed[item.OrderItemID] = true
+			left, ok := remaining[item.OrderItemID]
+			if !ok {
+				return fmt.Errorf("%w: order item %d is not on the order", ErrInvalidInput, item.OrderItemID)
+			}
+			if item.Quantity < 1 || item.Quantity > left {
+				return fmt.Errorf("%w: order item %d is assigned more than ordered", ErrInvalidInput, item.OrderItemID)
+			}
+			remaining[item.OrderItemID] = left - item.Quantity
+		}
+	}
+
+	for id, left := range remaining {
+		if left > 0 {
+			return fmt.Errorf("%w: order item %d is not fully assigned", ErrInvalidInput, id)
+		}
+	}
+	return nil
+}
+
+// HasTips reports whether the checks set their own tips, which then replace
+// the order's tip.
+func (r SplitRequest) HasTips() bool {
+	for _, c := range r.Checks {
+		if c.Tip > 0 || c.TipPercent > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package models
+
+import "fmt"
+
+// BasisPoints is the denominator of rates stored in basis points, so 500 is 5%.
+const BasisPoints = 10000
+
+// TaxRate applies to menu items of the listed categories. Items of any other
+// category fall under the default rate.
+type TaxRate struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Rate       int      `json:"rate"` // in basis points
+	Default    bool     `json:"default"`
+	Categories []string `json:"categories"`
+	CreatedAt  string   `json:"createdAt"`
+	UpdatedAt  string   `json:"updatedAt"`
+}
+
+func (t TaxRate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("%w: tax rate name is required", ErrInvalidInput)
+	}
+	if t.Rate < 0 || t.Rate > BasisPoints {
+		return fmt.Errorf("%w: tax rate must be between 0 and %d basis points", ErrInvalidInput, BasisPoints)
+	}
+	if !t.Default && len(t.Categories) == 0 {
+		return fmt.Errorf("%w: tax rate needs categories unless it is the default", ErrInvalidInput)
+	}
+	return nil
+}
+
+// OrderTax is the tax charged at one rate. Name and Rate are copied from the
+// tax rate so the order keeps them when rates change later.
+type OrderTax struct {
+	TaxRateID int    `json:"taxRateId,omitempty"`
+	Name      string `json:"name"`
+	Rate      int    `json:"rate"`    // in basis points
+	Taxable   int    `json:"taxable"` // net amount the tax was charged on, in cents
+	Amount    int    `json:"amount"`  // in cents
+}
+
+// LineTax is the part of an order line's price that fell under a tax rate
+// when the order was priced, so refunds of the line follow the order's taxes
+// rather than today's rates.
+type LineTax struct {
+	TaxRateID int `json:"taxRateId"`
+	Amount    int `json:"amount"` // gross, for the whole line, in cents
+}
+
+// RoundingMode decides how fractions of a cent are rounded.
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // 0.5 rounds away from zero
+	RoundHalfEven RoundingMode = "half_even" // 0.5 rounds to the even cent
+	RoundDown     RoundingMode = "down"      // always truncate
+	RoundUp       RoundingMode = "up"        // any fraction rounds up
+)
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch m := RoundingMode(s); m {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: unknown rounding mode %q", ErrInvalidInput, s)
+}
+
+// Divide returns num/den rounded according to the mode. Both must not be
+// negative and den must be positive.
+func (m RoundingMode) Divide(num, den int64) int64 {
+	q, r := num/den, num%den
+	if r == 0 {
+		return q
+	}
+	switch m {
+	case RoundDown:
+		return q
+	case RoundUp:
+		return q + 1
+	case RoundHalfEven:
+		if 2*r > den || (2*r == den && q%2 == 1) {
+			return q + 1
+		}
+		return q
+	default:
+		if 2*r >= den {
+			return q + 1
+		}
+		return q
+	}
+}
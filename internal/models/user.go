@@ -6,6 +6,7 @@ const (
 	UserRoleAdmin   UserRole = "admin"
 	UserRoleUser    UserRole = "user"
 	UserRoleManager UserRole = "manager"
+	UserRoleKitchen UserRole = "kitchen"
 )
 
 type User struct {
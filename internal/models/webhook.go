@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Event types written to the outbox and delivered to webhooks. The names
+// match the real-time events.
+const (
+	OutboxOrderCreated              = "order.created"
+	OutboxOrderStatusChanged        = "order.status_changed"
+	OutboxOrderKitchenStatusChanged = "order.kitchen_status_changed"
+	OutboxTableReset                = "table.reset"
+)
+
+var OutboxEventTypes = []string{
+	OutboxOrderCreated, OutboxOrderStatusChanged, OutboxOrderKitchenStatusChanged, OutboxTableReset,
+}
+
+// OutboxEvent is a change recorded together with it. It is also the body of
+// a webhook delivery.
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+// OrderChange is the data of order events after the order was created;
+// order.created carries the whole order.
+type OrderChange struct {
+	OrderID       int           `json:"orderId"`
+	UserID        int           `json:"userId"`
+	TableID       int           `json:"tableId,omitempty"`
+	Status        OrderStatus   `json:"status"`
+	KitchenStatus KitchenStatus `json:"kitchenStatus,omitempty"`
+	TotalPrice    int           `json:"totalPrice"`
+}
+
+// TableChange is the data of table.reset.
+type TableChange struct {
+	TableID           int   `json:"tableId"`
+	CompletedOrderIDs []int `json:"completedOrderIds"`
+}
+
+// WebhookEndpoint is a partner URL that is sent outbox events.
+type WebhookEndpoint struct {
+	ID          int      `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description,omitempty"`
+	Secret      string   `json:"secret,omitempty"` // only shown when the endpoint is created
+	EventTypes  []string `json:"eventTypes"`       // empty for every event
+	Active      bool     `json:"active"`
+	CreatedAt   string   `json:"createdAt"`
+}
+
+func (e *WebhookEndpoint) Validate() error {
+	e.URL = strings.TrimSpace(e.URL)
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidInput)
+	}
+	e.Description = strings.TrimSpace(e.Description)
+	if len(e.Description) > 200 {
+		return fmt.Errorf("%w: description is limited to 200 characters", ErrInvalidInput)
+	}
+	if e.EventTypes == nil {
+		e.EventTypes = []string{}
+	}
+	for _, t := range e.EventTypes {
+		if !validOutboxEventType(t) {
+			return fmt.Errorf("%w: unknown event type %q, must be one of %s", ErrInvalidInput, t, strings.Join(OutboxEventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+func validOutboxEventType(t string) bool {
+	for _, known := range OutboxEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryRetrying  WebhookDeliveryStatus = "retrying"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead" // gave up after too many failures
+)
+
+func (s WebhookDeliveryStatus) Valid() bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliveryRetrying, WebhookDeliveryDelivered, WebhookDeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is one event on its way to one endpoint.
+type WebhookDelivery struct {
+	ID            int64                 `json:"id"`
+	EndpointID    int                   `json:"endpointId"`
+	Event         OutboxEvent           `json:"event"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt string                `json:"nextAttemptAt,omitempty"`
+	LastError     string                `json:"lastError,omitempty"`
+	CreatedAt     string                `json:"createdAt"`
+	DeliveredAt   string                `json:"deliveredAt,omitempty"`
+	Log           []WebhookAttempt      `json:"log,omitempty"`
+
+	// Where and how to send it, filled in for the dispatcher
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookAttempt is an entry of the delivery log.
+type WebhookAttempt struct {
+	Attempt     int    `json:"attempt"`
+	StatusCode  int    `json:"statusCode,omitempty"` // zero when nothing came back
+	Error       string `json:"error,omitempty"`
+	DurationMs  int    `json:"durationMs"`
+	AttemptedAt string `json:"attemptedAt"`
+}
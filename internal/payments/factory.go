@@ -0,0 +1,21 @@
+package payments
+
+import (
+	"errors"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+func NewGateway(cfg *config.PaymentsConfig) (PaymentGateway, error) {
+
+	switch cfg.Provider {
+	case "fake":
+		mode, err := ParseFakeMode(cfg.FakeMode)
+		if err != nil {
+			return nil, err
+		}
+		return NewFakeGateway(mode, cfg.WebhookSecret, cfg.FakeWebhookURL), nil
+	default:
+		return nil, errors.New("unsupported payment provider: " + cfg.Provider)
+	}
+}
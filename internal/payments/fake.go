@@ -0,0 +1,287 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// FakeMode decides how the fake gateway answers.
+type FakeMode string
+
+const (
+	FakeApprove FakeMode = "approve" // every payment goes through
+	FakeDecline FakeMode = "decline" // every payment is declined
+	FakeError   FakeMode = "error"   // the gateway cannot be reached
+	FakeAsync   FakeMode = "async"   // payments stay pending until a webhook arrives
+)
+
+// Sources that force a mode for a single payment, whatever the default is.
+var fakeSources = map[string]FakeMode{
+	"fake_ok":       FakeApprove,
+	"fake_declined": FakeDecline,
+	"fake_error":    FakeError,
+	"fake_async":    FakeAsync,
+}
+
+func ParseFakeMode(s string) (FakeMode, error) {
+	switch m := FakeMode(s); m {
+	case FakeApprove, FakeDecline, FakeError, FakeAsync:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown fake gateway mode %q", s)
+}
+
+// FakeGateway is an in-memory gateway for local development and tests. In
+// async mode it delivers the outcome to WebhookURL after WebhookDelay, signed
+// like a real provider would.
+type FakeGateway struct {
+	Mode          FakeMode
+	WebhookSecret string
+	WebhookURL    string
+	WebhookDelay  time.Duration
+	Client        *http.Client
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	byKey    map[string]string // idempotency key to reference
+}
+
+type fakePayment struct {
+	amount   int
+	captured int
+	refunded int
+	status   models.PaymentStatus
+}
+
+func NewFakeGateway(mode FakeMode, webhookSecret, webhookURL string) *FakeGateway {
+	return &FakeGateway{
+		Mode:          mode,
+		WebhookSecret: webhookSecret,
+		WebhookURL:    webhookURL,
+		WebhookDelay:  2 * time.Second,
+		Client:        &http.Client{Timeout: 10 * time.Second},
+		payments:      make(map[string]*fakePayment),
+		byKey:         make(map[string]string),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	mode := g.Mode
+	if m, ok := fakeSources[req.Source]; ok {
+		mode = m
+	}
+	if mode == FakeError {
+		return Result{}, fmt.Errorf("%w: fake gateway is unavailable", models.ErrGatewayFailure)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		if ref, ok := g.byKey[req.IdempotencyKey]; ok {
+			p := g.payments[ref]
+			return Result{Reference: ref, Status: p.status, Amount: p.amount}, nil
+		}
+	}
+
+	ref := newReference("fake_pay")
+	p := &fakePayment{amount: req.Amount}
+	g.payments[ref] = p
+	if req.IdempotencyKey != "" {
+		g.byKey[req.IdempotencyKey] = ref
+	}
+
+	final := models.PaymentStatusAuthorized
+	if req.Capture {
+		final = models.PaymentStatusCaptured
+	}
+
+	switch mode {
+	case FakeDecline:
+		p.status = models.PaymentStatusFailed
+		return Result{Reference: ref, Status: p.status, Amount: req.Amount, FailureReason: "card declined"}, nil
+	case FakeAsync:
+		p.status = models.PaymentStatusPending
+		go g.settleLater(ref, final)
+		return Result{Reference: ref, Status: p.status, Amount: req.Amount}, nil
+	}
+
+	p.status = final
+	if req.Capture {
+		p.captured = req.Amount
+	}
+	return Result{Reference: ref, Status: p.status, Amount: req.Amount}, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount int) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.lookup(reference)
+	if err != nil {
+		return Result{}, err
+	}
+	if p.status != models.PaymentStatusAuthorized {
+		return Result{Reference: reference, Status: p.status, FailureReason: "payment is not authorized"}, nil
+	}
+	if amount <= 0 || amount > p.amount {
+		return Result{Reference: reference, Status: p.status, FailureReason: "capture exceeds the authorized amount"}, nil
+	}
+	p.captured = amount
+	p.status = models.PaymentStatusCaptured
+	return Result{Reference: reference, Status: p.status, Amount: amount}, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount int) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.lookup(reference)
+	if err != nil {
+		return Result{}, err
+	}
+	if p.status != models.PaymentStatusCaptured && p.status != models.PaymentStatusPartiallyRefunded {
+		return Result{Reference: reference, Status: p.status, FailureReason: "payment is not captured"}, nil
+	}
+	if amount <= 0 || amount > p.captured-p.refunded {
+		return Result{Reference: reference, Status: p.status, FailureReason: "refund exceeds the captured amount"}, nil
+	}
+	p.refunded += amount
+	p.status = models.PaymentStatusPartiallyRefunded
+	if p.refunded == p.captured {
+		p.status = models.PaymentStatusRefunded
+	}
+	return Result{Reference: reference, Status: p.status, Amount: amount}, nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, err := g.lookup(reference)
+	if err != nil {
+		return Result{}, err
+	}
+	if p.status != models.PaymentStatusAuthorized && p.status != models.PaymentStatusPending {
+		return Result{Reference: reference, Status: p.status, FailureReason: "only held payments can be voided"}, nil
+	}
+	p.status = models.PaymentStatusVoided
+	return Result{Reference: reference, Status: p.status, Amount: p.amount}, nil
+}
+
+func (g *FakeGateway) lookup(reference string) (*fakePayment, error) {
+	p, ok := g.payments[reference]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown payment reference %s", models.ErrGatewayFailure, reference)
+	}
+	return p, nil
+}
+
+// fakeEvent is the webhook body the fake gateway sends.
+type fakeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Reference     string               `json:"reference"`
+		Status        models.PaymentStatus `json:"status"`
+		Amount        int                  `json:"amount"`
+		FailureReason string               `json:"failureReason,omitempty"`
+	} `json:"data"`
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, header http.Header) (models.PaymentWebhookEvent, error) {
+	if err := Verify(g.WebhookSecret, payload, header, time.Now()); err != nil {
+		return models.PaymentWebhookEvent{}, err
+	}
+	var e fakeEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return models.PaymentWebhookEvent{}, fmt.Errorf("%w: invalid webhook body: %v", models.ErrInvalidInput, err)
+	}
+	if e.ID == "" || e.Data.Reference == "" {
+		return models.PaymentWebhookEvent{}, fmt.Errorf("%w: webhook event id and reference are required", models.ErrInvalidInput)
+	}
+	return models.PaymentWebhookEvent{
+		Provider:      g.Name(),
+		EventID:       e.ID,
+		Type:          e.Type,
+		Reference:     e.Data.Reference,
+		Status:        e.Data.Status,
+		Amount:        e.Data.Amount,
+		FailureReason: e.Data.FailureReason,
+		Payload:       payload,
+		ReceivedAt:    time.Now(),
+	}, nil
+}
+
+// settleLater completes an async payment and tells the application about it.
+func (g *FakeGateway) settleLater(reference string, status models.PaymentStatus) {
+	time.Sleep(g.WebhookDelay)
+
+	g.mu.Lock()
+	p := g.payments[reference]
+	if p.status != models.PaymentStatusPending {
+		g.mu.Unlock()
+		return
+	}
+	p.status = status
+	if status == models.PaymentStatusCaptured {
+		p.captured = p.amount
+	}
+	amount := p.amount
+	g.mu.Unlock()
+
+	if g.WebhookURL == "" {
+		return
+	}
+	var e fakeEvent
+	e.ID = newReference("fake_evt")
+	e.Type = "payment." + string(status)
+	e.Data.Reference = reference
+	e.Data.Status = status
+	e.Data.Amount = amount
+	if err := g.deliver(e); err != nil {
+		log.Printf("fake gateway: failed to deliver webhook for %s: %v", reference, err)
+	}
+}
+
+func (g *FakeGateway) deliver(e fakeEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(g.WebhookSecret, body, time.Now()))
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint answered %s", resp.Status)
+	}
+	return nil
+}
+
+func newReference(prefix string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,244 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func TestFakeAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    FakeMode
+		source  string
+		capture bool
+		want    models.PaymentStatus
+		wantErr error
+	}{
+		{"approve and capture", FakeApprove, "tok_visa", true, models.PaymentStatusCaptured, nil},
+		{"approve and hold", FakeApprove, "tok_visa", false, models.PaymentStatusAuthorized, nil},
+		{"decline", FakeDecline, "tok_visa", true, models.PaymentStatusFailed, nil},
+		{"async", FakeAsync, "tok_visa", true, models.PaymentStatusPending, nil},
+		{"unreachable", FakeError, "tok_visa", true, "", models.ErrGatewayFailure},
+		{"source declines", FakeApprove, "fake_declined", true, models.PaymentStatusFailed, nil},
+		{"source approves", FakeDecline, "fake_ok", false, models.PaymentStatusAuthorized, nil},
+		{"source fails", FakeApprove, "fake_error", true, "", models.ErrGatewayFailure},
+		{"source goes async", FakeApprove, "fake_async", true, models.PaymentStatusPending, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewFakeGateway(tt.mode, "secret", "")
+			g.WebhookDelay = time.Hour
+			res, err := g.Authorize(context.Background(), AuthorizeRequest{Amount: 1500, Source: tt.source, Capture: tt.capture})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authorize() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authorize() failed: %v", err)
+			}
+			if res.Status != tt.want || res.Amount != 1500 || res.Reference == "" {
+				t.Errorf("Authorize() = %+v, want status %s for 1500", res, tt.want)
+			}
+			if tt.want == models.PaymentStatusFailed && res.FailureReason == "" {
+				t.Error("declined payment has no failure reason")
+			}
+		})
+	}
+}
+
+func TestFakeAuthorizeIdempotent(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway(FakeApprove, "secret", "")
+	req := AuthorizeRequest{Amount: 1000, Capture: true, IdempotencyKey: "payment-1"}
+	first, err := g.Authorize(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Amount = 9999 // a retry returns the original payment whatever it says
+	again, err := g.Authorize(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Errorf("retried Authorize() = %+v, want %+v", again, first)
+	}
+
+	other, _ := g.Authorize(ctx, AuthorizeRequest{Amount: 1000, IdempotencyKey: "payment-2"})
+	if other.Reference == first.Reference {
+		t.Error("a new idempotency key reused the reference")
+	}
+}
+
+func TestFakeLifecycle(t *testing.T) {
+	type step struct {
+		op     string // capture, refund or void
+		amount int
+		want   models.PaymentStatus
+		failed bool // rejected with a failure reason
+	}
+	tests := []struct {
+		name    string
+		capture bool
+		steps   []step
+	}{
+		{"capture all, then refund in parts", false, []step{
+			{"capture", 1000, models.PaymentStatusCaptured, false},
+			{"refund", 300, models.PaymentStatusPartiallyRefunded, false},
+			{"refund", 701, models.PaymentStatusPartiallyRefunded, true},
+			{"refund", 700, models.PaymentStatusRefunded, false},
+			{"refund", 1, models.PaymentStatusRefunded, true},
+		}},
+		{"capture less than authorized", false, []step{
+			{"capture", 600, models.PaymentStatusCaptured, false},
+			{"refund", 600, models.PaymentStatusRefunded, false},
+		}},
+		{"capture too much", false, []step{
+			{"capture", 1001, models.PaymentStatusAuthorized, true},
+			{"capture", 0, models.PaymentStatusAuthorized, true},
+			{"capture", 1000, models.PaymentStatusCaptured, false},
+			{"capture", 1000, models.PaymentStatusCaptured, true},
+		}},
+		{"void a hold", false, []step{
+			{"void", 0, models.PaymentStatusVoided, false},
+			{"capture", 1000, models.PaymentStatusVoided, true},
+			{"refund", 1000, models.PaymentStatusVoided, true},
+		}},
+		{"captured payments cannot be voided", true, []step{
+			{"void", 0, models.PaymentStatusCaptured, true},
+			{"refund", 1000, models.PaymentStatusRefunded, false},
+			{"void", 0, models.PaymentStatusRefunded, true},
+		}},
+		{"holds cannot be refunded", false, []step{
+			{"refund", 500, models.PaymentStatusAuthorized, true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g := NewFakeGateway(FakeApprove, "secret", "")
+			auth, err := g.Authorize(ctx, AuthorizeRequest{Amount: 1000, Capture: tt.capture})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range tt.steps {
+				var res Result
+				switch s.op {
+				case "capture":
+					res, err = g.Capture(ctx, auth.Reference, s.amount)
+				case "refund":
+					res, err = g.Refund(ctx, auth.Reference, s.amount)
+				case "void":
+					res, err = g.Void(ctx, auth.Reference)
+				}
+				if err != nil {
+					t.Fatalf("step %d %s: %v", i, s.op, err)
+				}
+				if res.Status != s.want || (res.FailureReason != "") != s.failed {
+					t.Fatalf("step %d %s %d = %+v, want status %s, rejected %v", i, s.op, s.amount, res, s.want, s.failed)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeUnknownReference(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway(FakeApprove, "secret", "")
+	if _, err := g.Capture(ctx, "fake_pay_nope", 100); !errors.Is(err, models.ErrGatewayFailure) {
+		t.Errorf("Capture() error = %v, want ErrGatewayFailure", err)
+	}
+	if _, err := g.Refund(ctx, "fake_pay_nope", 100); !errors.Is(err, models.ErrGatewayFailure) {
+		t.Errorf("Refund() error = %v, want ErrGatewayFailure", err)
+	}
+	if _, err := g.Void(ctx, "fake_pay_nope"); !errors.Is(err, models.ErrGatewayFailure) {
+		t.Errorf("Void() error = %v, want ErrGatewayFailure", err)
+	}
+}
+
+func TestFakeAsyncDeliversSignedWebhook(t *testing.T) {
+	g := NewFakeGateway(FakeAsync, "whsec_test", "")
+	g.WebhookDelay = 10 * time.Millisecond
+
+	received := make(chan models.PaymentWebhookEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event, err := g.ParseWebhook(body, r.Header)
+		if err != nil {
+			t.Errorf("ParseWebhook() failed: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer srv.Close()
+	g.WebhookURL = srv.URL
+
+	res, err := g.Authorize(context.Background(), AuthorizeRequest{Amount: 2500, Capture: true})
+	if err != nil || res.Status != models.PaymentStatusPending {
+		t.Fatalf("Authorize() = %+v, %v, want pending", res, err)
+	}
+
+	select {
+	case event := <-received:
+		if event.Provider != "fake" || event.EventID == "" || event.Reference != res.Reference ||
+			event.Status != models.PaymentStatusCaptured || event.Amount != 2500 {
+			t.Errorf("webhook event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivered")
+	}
+
+	// The payment settled at the gateway too
+	refund, err := g.Refund(context.Background(), res.Reference, 2500)
+	if err != nil || refund.Status != models.PaymentStatusRefunded {
+		t.Errorf("Refund() after settling = %+v, %v", refund, err)
+	}
+}
+
+func TestFakeParseWebhookRejects(t *testing.T) {
+	g := NewFakeGateway(FakeApprove, "whsec_test", "")
+	signed := func(body string) http.Header {
+		h := http.Header{}
+		h.Set(SignatureHeader, Sign("whsec_test", []byte(body), time.Now()))
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		header http.Header
+		want   error
+	}{
+		{"unsigned", `{"id":"evt_1","data":{"reference":"r"}}`, http.Header{}, models.ErrInvalidSignature},
+		{"not json", `nope`, signed(`nope`), models.ErrInvalidInput},
+		{"no event id", `{"data":{"reference":"r"}}`, signed(`{"data":{"reference":"r"}}`), models.ErrInvalidInput},
+		{"no reference", `{"id":"evt_1"}`, signed(`{"id":"evt_1"}`), models.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.ParseWebhook([]byte(tt.body), tt.header); !errors.Is(err, tt.want) {
+				t.Errorf("ParseWebhook() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFakeMode(t *testing.T) {
+	for _, s := range []string{"approve", "decline", "error", "async"} {
+		if m, err := ParseFakeMode(s); err != nil || string(m) != s {
+			t.Errorf("ParseFakeMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := ParseFakeMode("maybe"); err == nil {
+		t.Error("ParseFakeMode(\"maybe\") succeeded")
+	}
+}
@@ -0,0 +1,41 @@
+// Package payments talks to payment gateways. Gateways authorize, capture,
+// refund and void payments and report asynchronous outcomes through signed
+// webhooks.
+package payments
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// PaymentGateway is implemented by every payment provider. A declined payment
+// is not an error: it comes back as a Result with a failed status. Errors mean
+// the gateway could not be reached or did not understand the request.
+type PaymentGateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, reference string, amount int) (Result, error)
+	Refund(ctx context.Context, reference string, amount int) (Result, error)
+	Void(ctx context.Context, reference string) (Result, error)
+	// ParseWebhook verifies the signature of a webhook delivery and decodes it.
+	ParseWebhook(payload []byte, header http.Header) (models.PaymentWebhookEvent, error)
+}
+
+type AuthorizeRequest struct {
+	OrderID        int
+	Amount         int // in cents
+	Currency       string
+	Source         string // card token or other payment method reference
+	Capture        bool   // capture straight away instead of only holding the funds
+	IdempotencyKey string
+}
+
+// Result is the state of a payment at the gateway after a call.
+type Result struct {
+	Reference     string
+	Status        models.PaymentStatus
+	Amount        int // amount the call applied to, e.g. captured or refunded
+	FailureReason string
+}
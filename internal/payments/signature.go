@@ -0,0 +1,70 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// SignatureHeader carries "t=<unix seconds>,v1=<hex hmac>" where the HMAC is
+// SHA-256 over "<t>.<payload>" with the webhook secret.
+const SignatureHeader = "Tastybites-Signature"
+
+// SignatureTolerance is how old a signed delivery may be, which limits replays.
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns the SignatureHeader value for payload signed at t.
+func Sign(secret string, payload []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, payload)
+}
+
+// Verify checks a SignatureHeader value against payload.
+func Verify(secret string, payload []byte, header http.Header, now time.Time) error {
+	value := header.Get(SignatureHeader)
+	if value == "" {
+		return fmt.Errorf("%w: missing %s header", models.ErrInvalidSignature, SignatureHeader)
+	}
+
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = val
+		case "v1":
+			signatures = append(signatures, val)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed %s header", models.ErrInvalidSignature, SignatureHeader)
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", models.ErrInvalidSignature)
+	}
+
+	expected := signature(secret, ts, payload)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", models.ErrInvalidSignature)
+}
+
+func signature(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"payment.captured"}`)
+	now := time.Unix(1_700_000_000, 0)
+	valid := Sign(secret, payload, now)
+	_, sig, _ := strings.Cut(valid, ",v1=")
+
+	tests := []struct {
+		name    string
+		header  string
+		payload []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", valid, payload, now, false},
+		{"within the tolerance", valid, payload, now.Add(SignatureTolerance), false},
+		{"clock slightly behind", valid, payload, now.Add(-time.Minute), false},
+		{"rotated secrets", "t=1700000000,v1=deadbeef,v1=" + sig, payload, now, false},
+		{"spaces after commas", "t=1700000000, v1=" + sig, payload, now, false},
+		{"missing header", "", payload, now, true},
+		{"tampered payload", valid, []byte(`{"id":"evt_1","type":"payment.refunded"}`), now, true},
+		{"wrong secret", Sign("other", payload, now), payload, now, true},
+		{"too old", valid, payload, now.Add(SignatureTolerance + time.Second), true},
+		{"from the future", valid, payload, now.Add(-SignatureTolerance - time.Second), true},
+		{"timestamp changed", "t=1700000001,v1=" + sig, payload, now, true},
+		{"no timestamp", "v1=" + sig, payload, now, true},
+		{"no signature", "t=1700000000", payload, now, true},
+		{"garbage", "hello", payload, now, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set(SignatureHeader, tt.header)
+			}
+			err := Verify(secret, tt.payload, header, tt.now)
+			if tt.wantErr && !errors.Is(err, models.ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Verify() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	value := Sign("s", []byte("body"), time.Unix(42, 0))
+	ts, sig, ok := strings.Cut(value, ",")
+	if !ok || ts != "t=42" || !strings.HasPrefix(sig, "v1=") || len(sig) != len("v1=")+64 {
+		t.Errorf("Sign() = %q, want t=42,v1=<64 hex digits>", value)
+	}
+}
@@ -0,0 +1,218 @@
+// Package pricing works out the tax, service charge and tip of an order, all
+// in integer cents.
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type Settings struct {
+	PricesIncludeTax bool
+	Rounding         models.RoundingMode
+
+	// Parties of at least ServiceChargeMinGuests pay ServiceChargeRate
+	// (basis points) on the discounted net amount. A zero rate disables it.
+	ServiceChargeRate      int
+	ServiceChargeMinGuests int
+}
+
+func NewSettings(cfg *config.PricingConfig) (Settings, error) {
+	rounding, err := models.ParseRoundingMode(cfg.Rounding)
+	if err != nil {
+		return Settings{}, err
+	}
+	percent, err := strconv.ParseFloat(cfg.ServiceChargePercent, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return Settings{}, fmt.Errorf("invalid service charge percent %q", cfg.ServiceChargePercent)
+	}
+	return Settings{
+		PricesIncludeTax:       cfg.PricesIncludeTax,
+		Rounding:               rounding,
+		ServiceChargeRate:      int(math.Round(percent * 100)),
+		ServiceChargeMinGuests: cfg.ServiceChargeMinGuests,
+	}, nil
+}
+
+// Calculate fills in the tax, service charge, tip and totals of an order whose
+// lines are priced and discounts applied. menuItems resolves the category of
+// every line and bundle component. The tip is taken from order.Tip, or worked
+// out from order.TipPercent of the discounted order.
+func Calculate(order *models.Order, menuItems map[int]models.MenuItem, rates []models.TaxRate, s Settings) error {
+	tipAmount, tipPercent := order.Tip, order.TipPercent
+	if tipAmount < 0 || tipPercent < 0 || tipPercent > 100 {
+		return fmt.Errorf("%w: tip must be between 0 and 100 percent", models.ErrInvalidInput)
+	}
+	if tipAmount > 0 && tipPercent > 0 {
+		return fmt.Errorf("%w: give the tip as an amount or a percentage, not both", models.ErrInvalidInput)
+	}
+	if order.Guests < 0 {
+		return fmt.Errorf("%w: guests must not be negative", models.ErrInvalidInput)
+	}
+
+	order.TaxInclusive = s.PricesIncludeTax
+	order.Taxes = []models.OrderTax{}
+	order.TaxTotal, order.ServiceCharge, order.Tip = 0, 0, 0
+	order.CalculateTotalPrice()
+	afterDiscount := order.Subtotal - order.DiscountTotal
+
+	// Gross amount per tax rate, then the discount spread over them
+	groups := groupByRate(order.Items, menuItems, rates)
+	gross := make([]int, len(groups))
+	for i, g := range groups {
+		gross[i] = g.amount
+	}
+	discounts := allocate(order.DiscountTotal, gross)
+	for i := range order.Items {
+		order.Items[i].Taxes = lineTaxes(groupByRate(order.Items[i:i+1], menuItems, rates))
+	}
+
+	net := 0
+	for i, g := range groups {
+		base := int64(g.amount - discounts[i])
+		var tax, taxable int64
+		if s.PricesIncludeTax {
+			tax = s.Rounding.Divide(base*int64(g.rate.Rate), int64(models.BasisPoints+g.rate.Rate))
+			taxable = base - tax
+		} else {
+			tax = s.Rounding.Divide(base*int64(g.rate.Rate), models.BasisPoints)
+			taxable = base
+		}
+		net += int(taxable)
+		if g.rate.Rate == 0 && g.rate.ID == 0 {
+			continue // no rate configured for these items
+		}
+		order.Taxes = append(order.Taxes, models.OrderTax{
+			TaxRateID: g.rate.ID,
+			Name:      g.rate.Name,
+			Rate:      g.rate.Rate,
+			Taxable:   int(taxable),
+			Amount:    int(tax),
+		})
+		order.TaxTotal += int(tax)
+	}
+
+	if s.ServiceChargeRate > 0 && s.ServiceChargeMinGuests > 0 && order.Guests >= s.ServiceChargeMinGuests {
+		order.ServiceCharge = int(s.Rounding.Divide(int64(net)*int64(s.ServiceChargeRate), models.BasisPoints))
+	}
+
+	order.Tip = tipAmount
+	if tipPercent > 0 {
+		order.Tip = int(s.Rounding.Divide(int64(afterDiscount)*int64(tipPercent), 100))
+	}
+
+	order.CalculateTotalPrice()
+	return nil
+}
+
+type rateGroup struct {
+	rate   models.TaxRate
+	amount int
+}
+
+// groupByRate sums the order lines per tax rate, in the order rates are
+// listed. Bundle lines are split over their components in proportion to the
+// components' menu prices, since those can fall under different rates.
+func groupByRate(items []models.OrderItem, menuItems map[int]models.MenuItem, rates []models.TaxRate) []rateGroup {
+	byCategory := make(map[string]int)
+	fallback := -1
+	for i, rate := range rates {
+		if rate.Default && fallback < 0 {
+			fallback = i
+		}
+		for _, category := range rate.Categories {
+			byCategory[category] = i
+		}
+	}
+	rateFor := func(menuItemID int) int {
+		if i, ok := byCategory[menuItems[menuItemID].Category]; ok {
+			return i
+		}
+		return fallback
+	}
+
+	amounts := make(map[int]int) // rate index, -1 for no rate
+	for _, item := range items {
+		total := item.Price * item.Quantity
+		if !item.IsBundle() || len(item.Components) == 0 {
+			amounts[rateFor(item.MenuItemID)] += total
+			continue
+		}
+		weights := make([]int, len(item.Components))
+		for i, c := range item.Components {
+			weights[i] = menuItems[c.MenuItemID].Price * c.Quantity
+		}
+		for i, share := range allocate(total, weights) {
+			amounts[rateFor(item.Components[i].MenuItemID)] += share
+		}
+	}
+
+	indexes := make([]int, 0, len(amounts))
+	for i := range amounts {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	groups := make([]rateGroup, 0, len(indexes))
+	for _, i := range indexes {
+		g := rateGroup{amount: amounts[i]}
+		if i >= 0 {
+			g.rate = rates[i]
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// lineTaxes records the rate groups of one line, leaving out the part no
+// rate is configured for.
+func lineTaxes(groups []rateGroup) []models.LineTax {
+	taxes := []models.LineTax{}
+	for _, g := range groups {
+		if g.rate.Rate == 0 && g.rate.ID == 0 {
+			continue
+		}
+		taxes = append(taxes, models.LineTax{TaxRateID: g.rate.ID, Amount: g.amount})
+	}
+	return taxes
+}
+
+// allocate splits total in proportion to weights using the largest remainder
+// method, so the shares always add up to total exactly.
+func allocate(total int, weights []int) []int {
+	shares := make([]int, len(weights))
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if len(weights) == 0 || total == 0 {
+		return shares
+	}
+	if sum == 0 {
+		shares[0] = total
+		return shares
+	}
+
+	remainders := make([]int, len(weights))
+	given := 0
+	for i, w := range weights {
+		shares[i] = int(int64(total) * int64(w) / int64(sum))
+		remainders[i] = int(int64(total) * int64(w) % int64(sum))
+		given += shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; given < total; i++ {
+		shares[order[i%len(order)]]++
+		given++
+	}
+	return shares
+}
@@ -0,0 +1,307 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+var testMenu = map[int]models.MenuItem{
+	1: {ID: 1, Name: "Burger", Category: "food", Price: 1000},
+	2: {ID: 2, Name: "Beer", Category: "alcohol", Price: 500},
+	3: {ID: 3, Name: "Fries", Category: "food", Price: 300},
+	4: {ID: 4, Name: "Gift card", Category: "vouchers", Price: 2500},
+}
+
+var (
+	foodRate    = models.TaxRate{ID: 1, Name: "Food", Rate: 500, Default: true, Categories: []string{"food"}}
+	alcoholRate = models.TaxRate{ID: 2, Name: "Alcohol", Rate: 2000, Categories: []string{"alcohol"}}
+	testRates   = []models.TaxRate{foodRate, alcoholRate}
+)
+
+func line(menuItemID, quantity int) models.OrderItem {
+	return models.OrderItem{MenuItemID: menuItemID, Quantity: quantity, Price: testMenu[menuItemID].Price}
+}
+
+func bundleLine(price int, components ...int) models.OrderItem {
+	bundleID := 1
+	item := models.OrderItem{Quantity: 1, Price: price, BundleID: &bundleID}
+	for _, id := range components {
+		item.Components = append(item.Components, models.OrderItemComponent{MenuItemID: id, Quantity: 1})
+	}
+	return item
+}
+
+type taxLine struct{ id, taxable, amount int }
+
+func taxLines(taxes []models.OrderTax) []taxLine {
+	lines := make([]taxLine, len(taxes))
+	for i, tax := range taxes {
+		lines[i] = taxLine{tax.TaxRateID, tax.Taxable, tax.Amount}
+	}
+	return lines
+}
+
+func TestCalculate(t *testing.T) {
+	exclusive := Settings{Rounding: models.RoundHalfUp}
+	inclusive := Settings{Rounding: models.RoundHalfUp, PricesIncludeTax: true}
+	service := Settings{Rounding: models.RoundHalfUp, ServiceChargeRate: 1000, ServiceChargeMinGuests: 6}
+	serviceInclusive := service
+	serviceInclusive.PricesIncludeTax = true
+
+	tests := []struct {
+		name     string
+		order    models.Order
+		rates    []models.TaxRate
+		settings Settings
+
+		taxes         []taxLine
+		taxTotal      int
+		serviceCharge int
+		tip           int
+		total         int
+	}{
+		{
+			name:     "tax on top of the prices",
+			order:    models.Order{Items: []models.OrderItem{line(1, 2), line(2, 1)}},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 2000, 100}, {2, 500, 100}},
+			taxTotal: 200, total: 2700,
+		},
+		{
+			// 2000 * 5/105 = 95.24 and 500 * 20/120 = 83.33
+			name:     "tax included in the prices",
+			order:    models.Order{Items: []models.OrderItem{line(1, 2), line(2, 1)}},
+			settings: inclusive,
+			taxes:    []taxLine{{1, 1905, 95}, {2, 417, 83}},
+			taxTotal: 178, total: 2500,
+		},
+		{
+			// The discount is shared 200/50 by the gross of each rate
+			name: "discount spread over the rates",
+			order: models.Order{
+				Items:     []models.OrderItem{line(1, 2), line(2, 1)},
+				Discounts: []models.OrderDiscount{{Amount: 250}},
+			},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 1800, 90}, {2, 450, 90}},
+			taxTotal: 180, total: 2430,
+		},
+		{
+			// The bundle's 1200 is split 800/400 like the 1000/500 menu prices
+			name:     "bundle split across rates",
+			order:    models.Order{Items: []models.OrderItem{bundleLine(1200, 1, 2)}},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 800, 40}, {2, 400, 80}},
+			taxTotal: 120, total: 1320,
+		},
+		{
+			name:     "bundle split across rates, tax included",
+			order:    models.Order{Items: []models.OrderItem{bundleLine(1200, 1, 2)}},
+			settings: inclusive,
+			taxes:    []taxLine{{1, 762, 38}, {2, 333, 67}},
+			taxTotal: 105, total: 1200,
+		},
+		{
+			name:     "items without a rate and no default are not taxed",
+			order:    models.Order{Items: []models.OrderItem{line(1, 1), line(2, 1)}},
+			rates:    []models.TaxRate{alcoholRate},
+			settings: exclusive,
+			taxes:    []taxLine{{2, 500, 100}},
+			taxTotal: 100, total: 1600,
+		},
+		{
+			name:     "the default rate covers other categories",
+			order:    models.Order{Items: []models.OrderItem{line(4, 1)}},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 2500, 125}},
+			taxTotal: 125, total: 2625,
+		},
+		{
+			// 10% of 2500 - 500, not of 2500
+			name: "tip percent of the discounted amount",
+			order: models.Order{
+				Items:      []models.OrderItem{line(1, 2), line(2, 1)},
+				Discounts:  []models.OrderDiscount{{Amount: 500}},
+				TipPercent: 10,
+			},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 1600, 80}, {2, 400, 80}},
+			taxTotal: 160, tip: 200, total: 2360,
+		},
+		{
+			name:     "tip amount",
+			order:    models.Order{Items: []models.OrderItem{line(3, 1)}, Tip: 75},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 300, 15}},
+			taxTotal: 15, tip: 75, total: 390,
+		},
+		{
+			name:     "service charge for a large party",
+			order:    models.Order{Items: []models.OrderItem{line(1, 2), line(2, 1)}, Guests: 6},
+			settings: service,
+			taxes:    []taxLine{{1, 2000, 100}, {2, 500, 100}},
+			taxTotal: 200, serviceCharge: 250, total: 2950,
+		},
+		{
+			name:     "no service charge below the party size",
+			order:    models.Order{Items: []models.OrderItem{line(1, 2), line(2, 1)}, Guests: 5},
+			settings: service,
+			taxes:    []taxLine{{1, 2000, 100}, {2, 500, 100}},
+			taxTotal: 200, total: 2700,
+		},
+		{
+			// 10% of the net 1905 + 417
+			name:     "service charge on the net of included tax",
+			order:    models.Order{Items: []models.OrderItem{line(1, 2), line(2, 1)}, Guests: 8},
+			settings: serviceInclusive,
+			taxes:    []taxLine{{1, 1905, 95}, {2, 417, 83}},
+			taxTotal: 178, serviceCharge: 232, total: 2732,
+		},
+		{
+			name: "discount larger than the order",
+			order: models.Order{
+				Items:     []models.OrderItem{line(3, 1)},
+				Discounts: []models.OrderDiscount{{Amount: 1000}},
+			},
+			settings: exclusive,
+			taxes:    []taxLine{{1, 0, 0}},
+			total:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates := tt.rates
+			if rates == nil {
+				rates = testRates
+			}
+			order := tt.order
+			if err := Calculate(&order, testMenu, rates, tt.settings); err != nil {
+				t.Fatalf("Calculate() failed: %v", err)
+			}
+			if got := taxLines(order.Taxes); !reflect.DeepEqual(got, tt.taxes) {
+				t.Errorf("taxes = %v, want %v", got, tt.taxes)
+			}
+			if order.TaxTotal != tt.taxTotal || order.ServiceCharge != tt.serviceCharge || order.Tip != tt.tip {
+				t.Errorf("tax, service charge, tip = %d, %d, %d, want %d, %d, %d",
+					order.TaxTotal, order.ServiceCharge, order.Tip, tt.taxTotal, tt.serviceCharge, tt.tip)
+			}
+			if order.TotalPrice != tt.total {
+				t.Errorf("total = %d, want %d", order.TotalPrice, tt.total)
+			}
+			if order.TaxInclusive != tt.settings.PricesIncludeTax {
+				t.Errorf("taxInclusive = %v", order.TaxInclusive)
+			}
+		})
+	}
+}
+
+func TestCalculateRounding(t *testing.T) {
+	// 5% of 1010 is 50.5 and of 1030 is 51.5, of 1012 it is 50.6
+	tests := []struct {
+		mode  models.RoundingMode
+		price int
+		want  int
+	}{
+		{models.RoundHalfUp, 1010, 51},
+		{models.RoundHalfUp, 1030, 52},
+		{models.RoundHalfEven, 1010, 50},
+		{models.RoundHalfEven, 1030, 52},
+		{models.RoundHalfEven, 1012, 51},
+		{models.RoundDown, 1012, 50},
+		{models.RoundUp, 1001, 51},
+		{models.RoundUp, 1000, 50},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.mode, tt.price), func(t *testing.T) {
+			order := models.Order{Items: []models.OrderItem{{MenuItemID: 1, Quantity: 1, Price: tt.price}}}
+			if err := Calculate(&order, testMenu, testRates, Settings{Rounding: tt.mode}); err != nil {
+				t.Fatalf("Calculate() failed: %v", err)
+			}
+			if order.TaxTotal != tt.want {
+				t.Errorf("tax = %d, want %d", order.TaxTotal, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		order models.Order
+	}{
+		{"negative tip", models.Order{Tip: -1}},
+		{"tip percent over 100", models.Order{TipPercent: 101}},
+		{"tip as amount and percent", models.Order{Tip: 100, TipPercent: 10}},
+		{"negative guests", models.Order{Guests: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := tt.order
+			order.Items = []models.OrderItem{line(1, 1)}
+			err := Calculate(&order, testMenu, testRates, Settings{Rounding: models.RoundHalfUp})
+			if !errors.Is(err, models.ErrInvalidInput) {
+				t.Errorf("Calculate() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		total   int
+		weights []int
+		want    []int
+	}{
+		{100, []int{1, 1, 1}, []int{34, 33, 33}},
+		{200, []int{1, 1, 1}, []int{67, 67, 66}},
+		{250, []int{2000, 500}, []int{200, 50}},
+		{1, []int{1, 1, 1, 1}, []int{1, 0, 0, 0}},
+		{10, []int{1, 0, 3}, []int{3, 0, 7}},
+		{10, []int{0, 0}, []int{10, 0}}, // no weights: the first takes it all
+		{0, []int{5, 5}, []int{0, 0}},
+		{7, []int{}, []int{}},
+		{99, []int{7}, []int{99}},
+		{1_000_000_007, []int{3, 3, 3}, []int{333_333_336, 333_333_336, 333_333_335}},
+	}
+	for _, tt := range tests {
+		got := allocate(tt.total, tt.weights)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateSumsToTotal(t *testing.T) {
+	weightSets := [][]int{
+		{1},
+		{1, 2},
+		{3, 3, 3},
+		{1, 1, 1, 1, 1, 1, 1},
+		{999, 1},
+		{1000, 1, 1, 1},
+		{7, 0, 13, 0, 29},
+		{1250, 899, 3, 17, 4400, 1},
+	}
+	for _, weights := range weightSets {
+		for total := 0; total <= 1000; total += 7 {
+			shares := allocate(total, weights)
+			if got := sum(shares); got != total {
+				t.Fatalf("allocate(%d, %v) = %v sums to %d", total, weights, shares, got)
+			}
+			for i, share := range shares {
+				if share < 0 {
+					t.Fatalf("allocate(%d, %v) gives a negative share %d", total, weights, share)
+				}
+				// Each share is within a cent of its exact proportion
+				exact := float64(total) * float64(weights[i]) / float64(sum(weights))
+				if diff := float64(share) - exact; diff <= -1 || diff >= 1 {
+					t.Fatalf("allocate(%d, %v)[%d] = %d, exact %.2f", total, weights, i, share, exact)
+				}
+			}
+		}
+	}
+}
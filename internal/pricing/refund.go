@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// LineRefund is what quantity units of an order line were charged: their
+// price less their share of the discount, plus their share of the tax when
+// tax came on top, and of the service charge. Tips are not refunded with
+// lines.
+//
+// The tax follows the line's part of each rate recorded when the order was
+// priced, so rates or categories changed since do not apply. menuItems and
+// rates only price orders from before lines recorded their taxes.
+func LineRefund(order models.Order, line models.OrderItem, quantity int, menuItems map[int]models.MenuItem, rates []models.TaxRate, s Settings) int {
+	if order.Subtotal == 0 || line.Quantity == 0 {
+		return 0
+	}
+	gross := int64(line.Price * quantity)
+	discount := s.Rounding.Divide(int64(order.DiscountTotal)*gross, int64(order.Subtotal))
+	net := gross - discount
+
+	var tax int64
+	if !order.TaxInclusive {
+		orderAmounts := make(map[int]int)
+		lineAmounts := line.Taxes
+		if taxesRecorded(order.Items) {
+			for _, item := range order.Items {
+				for _, t := range item.Taxes {
+					orderAmounts[t.TaxRateID] += t.Amount
+				}
+			}
+		} else {
+			for _, t := range lineTaxes(groupByRate(order.Items, menuItems, rates)) {
+				orderAmounts[t.TaxRateID] += t.Amount
+			}
+			lineAmounts = lineTaxes(groupByRate([]models.OrderItem{line}, menuItems, rates))
+		}
+		for _, l := range lineAmounts {
+			for _, t := range order.Taxes {
+				if t.TaxRateID == l.TaxRateID && orderAmounts[l.TaxRateID] > 0 {
+					tax += s.Rounding.Divide(int64(t.Amount)*int64(l.Amount)*int64(quantity),
+						int64(orderAmounts[l.TaxRateID])*int64(line.Quantity))
+				}
+			}
+		}
+	}
+
+	var serviceCharge int64
+	if afterDiscount := order.Subtotal - order.DiscountTotal; afterDiscount > 0 {
+		serviceCharge = s.Rounding.Divide(int64(order.ServiceCharge)*net, int64(afterDiscount))
+	}
+	return int(net + tax + serviceCharge)
+}
+
+// taxesRecorded tells whether the order lines carry their taxes.
+func taxesRecorded(items []models.OrderItem) bool {
+	for _, item := range items {
+		if len(item.Taxes) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func TestLineRefund(t *testing.T) {
+	exclusive := Settings{Rounding: models.RoundHalfUp}
+	inclusive := Settings{Rounding: models.RoundHalfUp, PricesIncludeTax: true}
+
+	// Since the sale food went up to 10% and burgers moved under alcohol
+	raisedFood := foodRate
+	raisedFood.Rate = 1000
+	movedBurgers := alcoholRate
+	movedBurgers.Categories = []string{"alcohol", "food"}
+	changedRates := []models.TaxRate{raisedFood, movedBurgers}
+
+	tests := []struct {
+		name     string
+		items    []models.OrderItem
+		settings Settings
+		line     int // index into items
+		quantity int
+		rates    []models.TaxRate // when refunding
+		legacy   bool             // priced before lines recorded their taxes
+		want     int
+	}{
+		// 2 burgers and a beer pay 100 food and 100 alcohol tax
+		{
+			name:  "one of two burgers",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 0, quantity: 1, rates: testRates, want: 1050,
+		},
+		{
+			name:  "both burgers",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 0, quantity: 2, rates: testRates, want: 2100,
+		},
+		{
+			name:  "the beer",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 1, quantity: 1, rates: testRates, want: 600,
+		},
+		{
+			// Today's rates would give the burger 40 of the alcohol tax
+			name:  "rates changed since the sale",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 0, quantity: 1, rates: changedRates, want: 1050,
+		},
+		{
+			name:  "rate removed since the sale",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 1, quantity: 1, rates: []models.TaxRate{foodRate}, want: 600,
+		},
+		{
+			name:  "priced before lines recorded their taxes",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: exclusive,
+			line: 0, quantity: 1, rates: testRates, legacy: true, want: 1050,
+		},
+		{
+			// 800 food and 400 alcohol, as the bundle was split at the sale
+			name:  "bundle across rates changed since the sale",
+			items: []models.OrderItem{bundleLine(1200, 1, 2), line(3, 1)}, settings: exclusive,
+			line: 0, quantity: 1, rates: changedRates, want: 1320,
+		},
+		{
+			name:  "tax included",
+			items: []models.OrderItem{line(1, 2), line(2, 1)}, settings: inclusive,
+			line: 0, quantity: 1, rates: changedRates, want: 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{Items: slices.Clone(tt.items)}
+			if err := Calculate(&order, testMenu, testRates, tt.settings); err != nil {
+				t.Fatalf("Calculate() failed: %v", err)
+			}
+			if tt.legacy {
+				for i := range order.Items {
+					order.Items[i].Taxes = nil
+				}
+			}
+			line := order.Items[tt.line]
+			if got := LineRefund(order, line, tt.quantity, testMenu, tt.rates, tt.settings); got != tt.want {
+				t.Errorf("LineRefund() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateRecordsLineTaxes(t *testing.T) {
+	order := models.Order{Items: []models.OrderItem{line(1, 2), bundleLine(1200, 1, 2), line(4, 1)}}
+	if err := Calculate(&order, testMenu, []models.TaxRate{foodRate, alcoholRate}, Settings{Rounding: models.RoundHalfUp}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]models.LineTax{
+		{{TaxRateID: 1, Amount: 2000}},
+		{{TaxRateID: 1, Amount: 800}, {TaxRateID: 2, Amount: 400}},
+		{{TaxRateID: 1, Amount: 2500}}, // under the default rate
+	}
+	for i, item := range order.Items {
+		if !slices.Equal(item.Taxes, want[i]) {
+			t.Errorf("line %d taxes %+v, want %+v", i, item.Taxes, want[i])
+		}
+	}
+
+	untaxed := models.Order{Items: []models.OrderItem{line(1, 1)}}
+	if err := Calculate(&untaxed, testMenu, []models.TaxRate{alcoholRate}, Settings{Rounding: models.RoundHalfUp}); err != nil {
+		t.Fatal(err)
+	}
+	if len(untaxed.Items[0].Taxes) != 0 {
+		t.Errorf("untaxed line has taxes %+v", untaxed.Items[0].Taxes)
+	}
+}
@@ -0,0 +1,149 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Split divides the breakdown of a priced order over checks. Every amount of
+// the order (subtotal, discount, each tax, service charge and tip) is shared
+// out with the largest remainder method, so the checks add up to the order to
+// the cent. Item and seat checks are weighted by what they were assigned, per
+// tax rate for the taxes; even checks get equal shares. Checks that give their
+// own tips replace the order's tip with the sum of theirs, which is why order
+// is updated as well.
+func Split(order *models.Order, req models.SplitRequest, menuItems map[int]models.MenuItem, rates []models.TaxRate, s Settings) ([]models.Check, error) {
+	if err := req.Validate(*order); err != nil {
+		return nil, err
+	}
+
+	n := req.Count
+	if req.Mode != models.SplitEvenly {
+		n = len(req.Checks)
+	}
+	checks := make([]models.Check, n)
+	gross := make([]int, n)
+	taxWeights := make([][]int, len(order.Taxes))
+	for t := range taxWeights {
+		taxWeights[t] = make([]int, n)
+	}
+
+	lines := make(map[int]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		lines[item.ID] = item
+	}
+
+	for i := range checks {
+		c := &checks[i]
+		c.OrderID = order.ID
+		c.Number = i + 1
+		c.Mode = req.Mode
+		c.Label = fmt.Sprintf("Check %d", c.Number)
+		c.Items = []models.CheckItem{}
+
+		if req.Mode == models.SplitEvenly {
+			gross[i] = 1
+			for t := range taxWeights {
+				taxWeights[t][i] = 1
+			}
+			continue
+		}
+
+		r := req.Checks[i]
+		if req.Mode == models.SplitBySeat {
+			c.Seat = r.Seat
+			c.Label = fmt.Sprintf("Seat %d", r.Seat)
+		}
+		c.Items = r.Items
+
+		items := make([]models.OrderItem, 0, len(r.Items))
+		for _, assigned := range r.Items {
+			line := lines[assigned.OrderItemID]
+			line.Quantity = assigned.Quantity
+			items = append(items, line)
+			gross[i] += line.Price * line.Quantity
+		}
+		byRate := make(map[int]int)
+		for _, g := range groupByRate(items, menuItems, rates) {
+			byRate[g.rate.ID] += g.amount
+		}
+		for t, tax := range order.Taxes {
+			taxWeights[t][i] = byRate[tax.TaxRateID]
+		}
+	}
+
+	subtotals := allocate(order.Subtotal, gross)
+	discounts := allocate(order.DiscountTotal, gross)
+	afterDiscount := make([]int, n)
+	for i := range checks {
+		checks[i].Subtotal = subtotals[i]
+		checks[i].DiscountTotal = discounts[i]
+		checks[i].Taxes = []models.OrderTax{}
+		afterDiscount[i] = subtotals[i] - discounts[i]
+	}
+
+	for t, tax := range order.Taxes {
+		weights := taxWeights[t]
+		if sum(weights) == 0 {
+			weights = gross // the rate's categories changed since the order was placed
+		}
+		taxable := allocate(tax.Taxable, weights)
+		amounts := allocate(tax.Amount, weights)
+		for i := range checks {
+			if taxable[i] == 0 && amounts[i] == 0 {
+				continue // nothing on this check falls under the rate
+			}
+			share := tax
+			share.Taxable, share.Amount = taxable[i], amounts[i]
+			checks[i].Taxes = append(checks[i].Taxes, share)
+			checks[i].TaxTotal += share.Amount
+		}
+	}
+
+	net := make([]int, n)
+	for i, c := range checks {
+		net[i] = c.Subtotal - c.DiscountTotal
+		if order.TaxInclusive {
+			net[i] -= c.TaxTotal
+		}
+	}
+	for i, share := range allocate(order.ServiceCharge, net) {
+		checks[i].ServiceCharge = share
+	}
+
+	if req.HasTips() {
+		order.Tip = 0
+		for i, r := range req.Checks {
+			checks[i].Tip = r.Tip
+			if r.TipPercent > 0 {
+				checks[i].Tip = int(s.Rounding.Divide(int64(afterDiscount[i])*int64(r.TipPercent), 100))
+			}
+			order.Tip += checks[i].Tip
+		}
+		order.CalculateTotalPrice()
+	} else {
+		for i, share := range allocate(order.Tip, afterDiscount) {
+			checks[i].Tip = share
+		}
+	}
+
+	for i := range checks {
+		c := &checks[i]
+		c.TotalPrice = c.Subtotal - c.DiscountTotal + c.ServiceCharge + c.Tip
+		if !order.TaxInclusive {
+			c.TotalPrice += c.TaxTotal
+		}
+		c.Balance = c.TotalPrice
+		c.Status = models.CheckStatusOpen
+	}
+	return checks, nil
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
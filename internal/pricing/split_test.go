@@ -0,0 +1,210 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// pricedOrder is 2 burgers, a beer and 3 fries with a 10% discount, for a
+// party of 6 paying a service charge and a 15% tip.
+func pricedOrder(t *testing.T, s Settings) models.Order {
+	t.Helper()
+	order := models.Order{
+		ID: 1,
+		Items: []models.OrderItem{
+			{ID: 1, MenuItemID: 1, Quantity: 2, Price: 1000},
+			{ID: 2, MenuItemID: 2, Quantity: 1, Price: 500},
+			{ID: 3, MenuItemID: 3, Quantity: 3, Price: 300},
+		},
+		Discounts:  []models.OrderDiscount{{Amount: 340}},
+		Guests:     6,
+		TipPercent: 15,
+	}
+	if err := Calculate(&order, testMenu, testRates, s); err != nil {
+		t.Fatalf("Calculate() failed: %v", err)
+	}
+	return order
+}
+
+// checkTotals asserts that every amount of the checks adds up to the order.
+func checkTotals(t *testing.T, order models.Order, checks []models.Check) {
+	t.Helper()
+	var total models.Check
+	taxable := make(map[int]int)
+	amounts := make(map[int]int)
+	for _, c := range checks {
+		total.Subtotal += c.Subtotal
+		total.DiscountTotal += c.DiscountTotal
+		total.TaxTotal += c.TaxTotal
+		total.ServiceCharge += c.ServiceCharge
+		total.Tip += c.Tip
+		total.TotalPrice += c.TotalPrice
+
+		taxTotal := 0
+		for _, tax := range c.Taxes {
+			taxable[tax.TaxRateID] += tax.Taxable
+			amounts[tax.TaxRateID] += tax.Amount
+			taxTotal += tax.Amount
+		}
+		if taxTotal != c.TaxTotal {
+			t.Errorf("check %d taxes sum to %d, its tax total is %d", c.Number, taxTotal, c.TaxTotal)
+		}
+		want := c.Subtotal - c.DiscountTotal + c.ServiceCharge + c.Tip
+		if !order.TaxInclusive {
+			want += c.TaxTotal
+		}
+		if c.TotalPrice != want || c.Balance != c.TotalPrice || c.Status != models.CheckStatusOpen {
+			t.Errorf("check %d total %d, balance %d, status %s, want %d open",
+				c.Number, c.TotalPrice, c.Balance, c.Status, want)
+		}
+	}
+
+	if total.Subtotal != order.Subtotal || total.DiscountTotal != order.DiscountTotal ||
+		total.TaxTotal != order.TaxTotal || total.ServiceCharge != order.ServiceCharge ||
+		total.Tip != order.Tip || total.TotalPrice != order.TotalPrice {
+		t.Errorf("checks add up to %+v, order is subtotal %d, discount %d, tax %d, service %d, tip %d, total %d",
+			total, order.Subtotal, order.DiscountTotal, order.TaxTotal, order.ServiceCharge, order.Tip, order.TotalPrice)
+	}
+	for _, tax := range order.Taxes {
+		if taxable[tax.TaxRateID] != tax.Taxable || amounts[tax.TaxRateID] != tax.Amount {
+			t.Errorf("rate %d: checks have taxable %d and tax %d, order has %d and %d",
+				tax.TaxRateID, taxable[tax.TaxRateID], amounts[tax.TaxRateID], tax.Taxable, tax.Amount)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	byItem := models.SplitRequest{Mode: models.SplitByItem, Checks: []models.CheckRequest{
+		{Items: []models.CheckItem{{OrderItemID: 1, Quantity: 2}}},
+		{Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}, {OrderItemID: 3, Quantity: 1}}},
+		{Items: []models.CheckItem{{OrderItemID: 3, Quantity: 2}}},
+	}}
+	bySeat := models.SplitRequest{Mode: models.SplitBySeat, Checks: []models.CheckRequest{
+		{Seat: 1, Items: []models.CheckItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 3, Quantity: 1}}},
+		{Seat: 2, Items: []models.CheckItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 3, Quantity: 1}}},
+		{Seat: 3, Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}, {OrderItemID: 3, Quantity: 1}}},
+	}}
+	settings := map[string]Settings{
+		"exclusive": {Rounding: models.RoundHalfUp, ServiceChargeRate: 1250, ServiceChargeMinGuests: 6},
+		"inclusive": {Rounding: models.RoundHalfEven, PricesIncludeTax: true, ServiceChargeRate: 1250, ServiceChargeMinGuests: 6},
+	}
+	requests := map[string]models.SplitRequest{
+		"items":   byItem,
+		"seats":   bySeat,
+		"even 3":  {Mode: models.SplitEvenly, Count: 3},
+		"even 7":  {Mode: models.SplitEvenly, Count: 7},
+		"even 50": {Mode: models.SplitEvenly, Count: 50},
+	}
+	for sName, s := range settings {
+		for rName, req := range requests {
+			t.Run(sName+" "+rName, func(t *testing.T) {
+				order := pricedOrder(t, s)
+				checks, err := Split(&order, req, testMenu, testRates, s)
+				if err != nil {
+					t.Fatalf("Split() failed: %v", err)
+				}
+				checkTotals(t, order, checks)
+			})
+		}
+	}
+}
+
+func TestSplitByItemTaxesFollowTheItems(t *testing.T) {
+	s := Settings{Rounding: models.RoundHalfUp}
+	order := pricedOrder(t, s)
+	req := models.SplitRequest{Mode: models.SplitByItem, Checks: []models.CheckRequest{
+		{Items: []models.CheckItem{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 3, Quantity: 3}}},
+		{Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}}},
+	}}
+	checks, err := Split(&order, req, testMenu, testRates, s)
+	if err != nil {
+		t.Fatalf("Split() failed: %v", err)
+	}
+	checkTotals(t, order, checks)
+
+	// Food only on the first check, alcohol only on the second
+	if got := taxLines(checks[0].Taxes); len(got) != 1 || got[0].id != foodRate.ID {
+		t.Errorf("first check taxes = %v, want food only", got)
+	}
+	if got := taxLines(checks[1].Taxes); len(got) != 1 || got[0].id != alcoholRate.ID {
+		t.Errorf("second check taxes = %v, want alcohol only", got)
+	}
+	if checks[0].Subtotal != 2900 || checks[1].Subtotal != 500 {
+		t.Errorf("subtotals = %d, %d, want 2900, 500", checks[0].Subtotal, checks[1].Subtotal)
+	}
+}
+
+func TestSplitEvenly(t *testing.T) {
+	s := Settings{Rounding: models.RoundHalfUp}
+	order := pricedOrder(t, s)
+	checks, err := Split(&order, models.SplitRequest{Mode: models.SplitEvenly, Count: 3}, testMenu, testRates, s)
+	if err != nil {
+		t.Fatalf("Split() failed: %v", err)
+	}
+	checkTotals(t, order, checks)
+	for i, c := range checks {
+		if c.Number != i+1 || c.Label == "" || c.Mode != models.SplitEvenly {
+			t.Errorf("check %d = %+v", i, c)
+		}
+		// Each amount is shared to within a cent, so totals differ by a few cents
+		if d := c.TotalPrice - checks[0].TotalPrice; d < -3 || d > 3 {
+			t.Errorf("check %d total %d is far from check 1's %d", c.Number, c.TotalPrice, checks[0].TotalPrice)
+		}
+	}
+}
+
+func TestSplitWithCheckTips(t *testing.T) {
+	s := Settings{Rounding: models.RoundHalfUp}
+	order := pricedOrder(t, s)
+	req := models.SplitRequest{Mode: models.SplitByItem, Checks: []models.CheckRequest{
+		{Items: []models.CheckItem{{OrderItemID: 1, Quantity: 2}}, TipPercent: 20},
+		{Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}, {OrderItemID: 3, Quantity: 3}}, Tip: 150},
+	}}
+	checks, err := Split(&order, req, testMenu, testRates, s)
+	if err != nil {
+		t.Fatalf("Split() failed: %v", err)
+	}
+
+	// 20% of the first check after its share of the discount
+	afterDiscount := checks[0].Subtotal - checks[0].DiscountTotal
+	if want := int(s.Rounding.Divide(int64(afterDiscount)*20, 100)); checks[0].Tip != want {
+		t.Errorf("first check tip = %d, want %d", checks[0].Tip, want)
+	}
+	if checks[1].Tip != 150 {
+		t.Errorf("second check tip = %d, want 150", checks[1].Tip)
+	}
+	// The checks' tips replace the order's
+	if order.Tip != checks[0].Tip+150 {
+		t.Errorf("order tip = %d, want %d", order.Tip, checks[0].Tip+150)
+	}
+	checkTotals(t, order, checks)
+}
+
+func TestSplitRejects(t *testing.T) {
+	s := Settings{Rounding: models.RoundHalfUp}
+	tests := []struct {
+		name string
+		req  models.SplitRequest
+	}{
+		{"unknown mode", models.SplitRequest{Mode: "halves", Count: 2}},
+		{"one even check", models.SplitRequest{Mode: models.SplitEvenly, Count: 1}},
+		{"items left over", models.SplitRequest{Mode: models.SplitByItem, Checks: []models.CheckRequest{
+			{Items: []models.CheckItem{{OrderItemID: 1, Quantity: 2}}},
+			{Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}}},
+		}}},
+		{"too many units", models.SplitRequest{Mode: models.SplitByItem, Checks: []models.CheckRequest{
+			{Items: []models.CheckItem{{OrderItemID: 1, Quantity: 3}, {OrderItemID: 3, Quantity: 3}}},
+			{Items: []models.CheckItem{{OrderItemID: 2, Quantity: 1}}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := pricedOrder(t, s)
+			if _, err := Split(&order, tt.req, testMenu, testRates, s); !errors.Is(err, models.ErrInvalidInput) {
+				t.Errorf("Split() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package printing sends kitchen tickets and receipts to thermal printers.
+// Printers are reached over raw TCP (port 9100) or write to a file, each
+// kitchen station is routed to a printer, and a spooler retries failed jobs.
+package printing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPort is the raw printing port of network printers (JetDirect).
+const DefaultPort = "9100"
+
+// Printer takes a complete ESC/POS document.
+type Printer interface {
+	Name() string
+	Print(ctx context.Context, data []byte) error
+}
+
+// NewPrinter builds a printer from an address: tcp://host[:port] for a
+// network printer or file://path for a file sink.
+func NewPrinter(name, address string) (Printer, error) {
+	if path, ok := strings.CutPrefix(address, "file://"); ok && path != "" {
+		return NewFilePrinter(name, path), nil
+	}
+	if host, ok := strings.CutPrefix(address, "tcp://"); ok && host != "" {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, DefaultPort)
+		}
+		return NewNetworkPrinter(name, host), nil
+	}
+	return nil, fmt.Errorf("unsupported printer address for %s: %q", name, address)
+}
+
+type networkPrinter struct {
+	name string
+	addr string
+}
+
+// NewNetworkPrinter prints by writing the document to addr over TCP.
+func NewNetworkPrinter(name, addr string) Printer {
+	return &networkPrinter{name: name, addr: addr}
+}
+
+func (p *networkPrinter) Name() string {
+	return p.name
+}
+
+func (p *networkPrinter) Print(ctx context.Context, data []byte) error {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", p.name, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(10 * time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetWriteDeadline(deadline)
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send to printer %s: %w", p.name, err)
+	}
+	return nil
+}
+
+type filePrinter struct {
+	name string
+	path string
+	mu   sync.Mutex
+}
+
+// NewFilePrinter appends every document to the file at path, for tests and
+// development without a printer.
+func NewFilePrinter(name, path string) Printer {
+	return &filePrinter{name: name, path: path}
+}
+
+func (p *filePrinter) Name() string {
+	return p.name
+}
+
+func (p *filePrinter) Print(ctx context.Context, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create printer directory: %w", err)
+	}
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open printer file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write printer file: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,87 @@
+package printing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+// Router picks the printer for each kitchen station and for receipts.
+type Router struct {
+	printers       map[string]Printer // by name
+	defaultPrinter string
+	receiptPrinter string
+}
+
+func NewRouter(cfg *config.PrintingConfig) (*Router, error) {
+	addresses, err := parsePairs(cfg.Printers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid printers: %w", err)
+	}
+
+	r := &Router{
+		printers:       make(map[string]Printer, len(addresses)),
+		defaultPrinter: cfg.DefaultPrinter,
+		receiptPrinter: cfg.ReceiptPrinter,
+	}
+	for name, address := range addresses {
+		printer, err := NewPrinter(name, address)
+		if err != nil {
+			return nil, err
+		}
+		r.printers[name] = printer
+	}
+	for _, name := range []string{r.defaultPrinter, r.receiptPrinter} {
+		if _, ok := r.printers[name]; !ok {
+			return nil, fmt.Errorf("printer %q is not configured", name)
+		}
+	}
+	return r, nil
+}
+
+// PrinterFor is the printer of a kitchen station: the one with the station's
+// name, else the default printer.
+func (r *Router) PrinterFor(station string) Printer {
+	if p, ok := r.printers[station]; ok {
+		return p
+	}
+	return r.printers[r.defaultPrinter]
+}
+
+func (r *Router) ReceiptPrinter() Printer {
+	return r.printers[r.receiptPrinter]
+}
+
+// Printers lists the configured printers sorted by name.
+func (r *Router) Printers() []Printer {
+	names := make([]string, 0, len(r.printers))
+	for name := range r.printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	printers := make([]Printer, len(names))
+	for i, name := range names {
+		printers[i] = r.printers[name]
+	}
+	return printers
+}
+
+// parsePairs reads comma separated key=value pairs.
+func parsePairs(s string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", part)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
@@ -0,0 +1,134 @@
+package printing
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+func TestNewPrinter(t *testing.T) {
+	tests := []struct {
+		address  string
+		wantAddr string // of a network printer
+		wantPath string // of a file printer
+		wantErr  bool
+	}{
+		{address: "tcp://10.0.0.5", wantAddr: "10.0.0.5:9100"},
+		{address: "tcp://10.0.0.5:9101", wantAddr: "10.0.0.5:9101"},
+		{address: "tcp://printer.local", wantAddr: "printer.local:9100"},
+		{address: "tcp://[fe80::1]:9100", wantAddr: "[fe80::1]:9100"},
+		{address: "file:///tmp/grill.bin", wantPath: "/tmp/grill.bin"},
+		{address: "file://out/grill.bin", wantPath: "out/grill.bin"},
+		{address: "10.0.0.5:9100", wantErr: true},
+		{address: "tcp://", wantErr: true},
+		{address: "file://", wantErr: true},
+		{address: "lpt1", wantErr: true},
+	}
+	for _, tt := range tests {
+		p, err := NewPrinter("grill", tt.address)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewPrinter(%q) succeeded", tt.address)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewPrinter(%q) failed: %v", tt.address, err)
+			continue
+		}
+		if p.Name() != "grill" {
+			t.Errorf("NewPrinter(%q).Name() = %q", tt.address, p.Name())
+		}
+		switch p := p.(type) {
+		case *networkPrinter:
+			if p.addr != tt.wantAddr {
+				t.Errorf("NewPrinter(%q) dials %q, want %q", tt.address, p.addr, tt.wantAddr)
+			}
+		case *filePrinter:
+			if p.path != tt.wantPath {
+				t.Errorf("NewPrinter(%q) writes %q, want %q", tt.address, p.path, tt.wantPath)
+			}
+		}
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	got, err := parsePairs(" kitchen = tcp://10.0.0.5 ,bar=tcp://10.0.0.6:9101,, receipt=file://r.bin ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"kitchen": "tcp://10.0.0.5",
+		"bar":     "tcp://10.0.0.6:9101",
+		"receipt": "file://r.bin",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePairs() = %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"kitchen", "kitchen=", "=tcp://10.0.0.5", "a=b,c"} {
+		if _, err := parsePairs(s); err == nil {
+			t.Errorf("parsePairs(%q) succeeded", s)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRouter(&config.PrintingConfig{
+		Printers: "kitchen=file://" + filepath.Join(dir, "kitchen.bin") +
+			",bar=file://" + filepath.Join(dir, "bar.bin") +
+			",front=file://" + filepath.Join(dir, "front.bin"),
+		DefaultPrinter: "kitchen",
+		ReceiptPrinter: "front",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		station string
+		want    string
+	}{
+		{"bar", "bar"},
+		{"kitchen", "kitchen"},
+		{"grill", "kitchen"}, // no printer of its own
+		{"", "kitchen"},
+	}
+	for _, tt := range tests {
+		if got := r.PrinterFor(tt.station).Name(); got != tt.want {
+			t.Errorf("PrinterFor(%q) = %s, want %s", tt.station, got, tt.want)
+		}
+	}
+	if got := r.ReceiptPrinter().Name(); got != "front" {
+		t.Errorf("ReceiptPrinter() = %s, want front", got)
+	}
+
+	var names []string
+	for _, p := range r.Printers() {
+		names = append(names, p.Name())
+	}
+	if want := []string{"bar", "front", "kitchen"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Printers() = %v, want %v", names, want)
+	}
+}
+
+func TestNewRouterRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PrintingConfig
+	}{
+		{"bad pair", config.PrintingConfig{Printers: "kitchen", DefaultPrinter: "kitchen", ReceiptPrinter: "kitchen"}},
+		{"bad address", config.PrintingConfig{Printers: "kitchen=lpt1", DefaultPrinter: "kitchen", ReceiptPrinter: "kitchen"}},
+		{"unknown default", config.PrintingConfig{Printers: "kitchen=tcp://10.0.0.5", DefaultPrinter: "grill", ReceiptPrinter: "kitchen"}},
+		{"unknown receipt printer", config.PrintingConfig{Printers: "kitchen=tcp://10.0.0.5", DefaultPrinter: "kitchen", ReceiptPrinter: "front"}},
+		{"no printers", config.PrintingConfig{DefaultPrinter: "kitchen", ReceiptPrinter: "kitchen"}},
+	}
+	for _, tt := range tests {
+		if _, err := NewRouter(&tt.cfg); err == nil {
+			t.Errorf("%s: NewRouter() succeeded", tt.name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package printing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const (
+	queueSize    = 100              // jobs waiting per printer
+	historySize  = 500              // finished jobs kept for listing
+	printTimeout = 15 * time.Second // per attempt
+	maxRetryWait = time.Minute
+)
+
+var ErrQueueFull = errors.New("printer queue is full")
+
+// Spooler prints jobs in order on each printer and retries failed ones with
+// exponential backoff. A job that keeps failing holds up the jobs behind it on
+// the same printer, so tickets never come out of order. Jobs only live in
+// memory; anything lost can be reprinted.
+type Spooler struct {
+	router      *Router
+	maxAttempts int
+	retryDelay  time.Duration
+
+	mu     sync.Mutex
+	nextID int
+	jobs   []*models.PrintJob // oldest first
+	queues map[string]chan *models.PrintJob
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running atomic.Int32 // workers still running
+}
+
+// NewSpooler starts a worker for every printer of the router.
+func NewSpooler(router *Router, cfg *config.PrintingConfig) (*Spooler, error) {
+	if cfg.RetryDelay <= 0 {
+		return nil, fmt.Errorf("print retry delay must be positive, got %s", cfg.RetryDelay)
+	}
+	if cfg.MaxAttempts < 1 {
+		return nil, fmt.Errorf("print attempts must be at least 1, got %d", cfg.MaxAttempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Spooler{
+		router:      router,
+		maxAttempts: cfg.MaxAttempts,
+		retryDelay:  cfg.RetryDelay,
+		queues:      make(map[string]chan *models.PrintJob),
+		cancel:      cancel,
+	}
+	for _, printer := range router.Printers() {
+		queue := make(chan *models.PrintJob, queueSize)
+		s.queues[printer.Name()] = queue
+		s.wg.Add(1)
+		s.running.Add(1)
+		go s.work(ctx, printer, queue)
+	}
+	return s, nil
+}
+
+// Submit queues a document: a kitchen ticket for the printer of its station
+// or a receipt for the receipt printer.
+func (s *Spooler) Submit(orderId int, kind models.PrintJobKind, station string, data []byte) (models.PrintJob, error) {
+	printer := s.router.PrinterFor(station)
+	if kind == models.PrintJobReceipt {
+		printer = s.router.ReceiptPrinter()
+	}
+	queue := s.queues[printer.Name()]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	job := &models.PrintJob{
+		ID:        s.nextID,
+		OrderID:   orderId,
+		Kind:      kind,
+		Station:   station,
+		Printer:   printer.Name(),
+		Status:    models.PrintJobQueued,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Data:      data,
+	}
+	select {
+	case queue <- job:
+	default:
+		return models.PrintJob{}, fmt.Errorf("%w: %s", ErrQueueFull, printer.Name())
+	}
+	s.jobs = append(s.jobs, job)
+	if len(s.jobs) > historySize {
+		s.jobs = s.jobs[len(s.jobs)-historySize:]
+	}
+	return *job, nil
+}
+
+// Jobs lists recent jobs, newest first, optionally only those of one order.
+func (s *Spooler) Jobs(orderId int) []models.PrintJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]models.PrintJob, 0, len(s.jobs))
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		if orderId == 0 || s.jobs[i].OrderID == orderId {
+			jobs = append(jobs, *s.jobs[i])
+		}
+	}
+	return jobs
+}
+
+// Close stops the workers; jobs still queued are dropped.
+func (s *Spooler) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Check fails once a printer has no worker, as after Close. A printer that
+// is offline does not fail it; its jobs wait and are retried.
+func (s *Spooler) Check(ctx context.Context) error {
+	if running := int(s.running.Load()); running < len(s.queues) {
+		return fmt.Errorf("%d of %d printer workers stopped", len(s.queues)-running, len(s.queues))
+	}
+	return nil
+}
+
+func (s *Spooler) work(ctx context.Context, printer Printer, queue <-chan *models.PrintJob) {
+	defer s.wg.Done()
+	defer s.running.Add(-1)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-queue:
+			s.print(ctx, printer, job)
+		}
+	}
+}
+
+func (s *Spooler) print(ctx context.Context, printer Printer, job *models.PrintJob) {
+	wait := s.retryDelay
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, printTimeout)
+		err := printer.Print(attemptCtx, job.Data)
+		cancel()
+
+		s.mu.Lock()
+		job.Attempts = attempt
+		switch {
+		case err == nil:
+			job.Status = models.PrintJobPrinted
+			job.LastError = ""
+			job.PrintedAt = time.Now().Format(time.RFC3339)
+			job.Data = nil
+		case attempt == s.maxAttempts:
+			job.Status = models.PrintJobFailed
+			job.LastError = err.Error()
+		default:
+			job.Status = models.PrintJobRetrying
+			job.LastError = err.Error()
+		}
+		status := job.Status
+		s.mu.Unlock()
+
+		if status != models.PrintJobRetrying {
+			if status == models.PrintJobFailed {
+				log.Printf("print job %d for order %d failed on %s: %v", job.ID, job.OrderID, printer.Name(), err)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxRetryWait)
+	}
+}
@@ -0,0 +1,235 @@
+package printing
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func newTestSpooler(t *testing.T, printers string, maxAttempts int) *Spooler {
+	t.Helper()
+	cfg := &config.PrintingConfig{
+		Printers:       printers,
+		DefaultPrinter: "kitchen",
+		ReceiptPrinter: "front",
+		MaxAttempts:    maxAttempts,
+		RetryDelay:     20 * time.Millisecond,
+	}
+	router, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSpooler(router, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitForJob polls the spooler until the job satisfies done.
+func waitForJob(t *testing.T, s *Spooler, id int, done func(models.PrintJob) bool) models.PrintJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, job := range s.Jobs(0) {
+			if job.ID == id && done(job) {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d did not get there: %+v", id, s.Jobs(0))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func isPrinted(job models.PrintJob) bool { return job.Status == models.PrintJobPrinted }
+
+func TestSpoolerPrintsToFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name+".bin") }
+	s := newTestSpooler(t, "kitchen=file://"+path("kitchen")+",bar=file://"+path("bar")+",front=file://"+path("front"), 3)
+
+	grill1 := []byte("grill ticket 1\n")
+	grill2 := []byte("grill ticket 2\n")
+	bar := []byte("bar ticket\n")
+	receipt := []byte("receipt\n")
+
+	var ids []int
+	for _, submit := range []struct {
+		orderId int
+		kind    models.PrintJobKind
+		station string
+		data    []byte
+		printer string
+	}{
+		{7, models.PrintJobKitchen, "grill", grill1, "kitchen"},
+		{7, models.PrintJobKitchen, "bar", bar, "bar"},
+		{8, models.PrintJobKitchen, "grill", grill2, "kitchen"},
+		{7, models.PrintJobReceipt, "bar", receipt, "front"}, // receipts ignore the station
+	} {
+		job, err := s.Submit(submit.orderId, submit.kind, submit.station, submit.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Printer != submit.printer || job.Status != models.PrintJobQueued {
+			t.Errorf("Submit(%s, %s) = %+v, want queued on %s", submit.kind, submit.station, job, submit.printer)
+		}
+		ids = append(ids, job.ID)
+	}
+	for _, id := range ids {
+		job := waitForJob(t, s, id, isPrinted)
+		if job.Attempts != 1 || job.PrintedAt == "" || job.LastError != "" || job.Data != nil {
+			t.Errorf("printed job = %+v", job)
+		}
+	}
+
+	for name, want := range map[string][]byte{
+		"kitchen": append(append([]byte{}, grill1...), grill2...), // in order
+		"bar":     bar,
+		"front":   receipt,
+	} {
+		got, err := os.ReadFile(path(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s printed %q, want %q", name, got, want)
+		}
+	}
+
+	jobs := s.Jobs(7)
+	if len(jobs) != 3 || jobs[0].ID != ids[3] || jobs[2].ID != ids[0] {
+		t.Errorf("Jobs(7) = %+v, want the order's 3 jobs newest first", jobs)
+	}
+}
+
+func TestSpoolerRetriesNetworkPrinter(t *testing.T) {
+	// Reserve a port, then close it so the first attempts are refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := newTestSpooler(t, "kitchen=tcp://"+addr+",front=file://"+filepath.Join(t.TempDir(), "front.bin"), 10)
+	ticket := TicketBytes(testTicket(), time.Now())
+	job, err := s.Submit(42, models.PrintJobKitchen, "grill", ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retrying := waitForJob(t, s, job.ID, func(j models.PrintJob) bool { return j.Status == models.PrintJobRetrying })
+	if retrying.Attempts < 1 || !strings.Contains(retrying.LastError, "kitchen") {
+		t.Errorf("retrying job = %+v", retrying)
+	}
+	if err := s.Check(context.Background()); err != nil {
+		t.Errorf("Check() with the printer offline = %v, want nil", err)
+	}
+
+	// The printer comes back on port 9100's stand-in
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	select {
+	case got := <-received:
+		if !bytes.Equal(got, ticket) {
+			t.Errorf("printer received % x\nwant % x", got, ticket)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("printer received nothing")
+	}
+	printed := waitForJob(t, s, job.ID, isPrinted)
+	if printed.Attempts < 2 || printed.LastError != "" {
+		t.Errorf("printed job = %+v, want printed after a retry", printed)
+	}
+}
+
+func TestSpoolerGivesUp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := newTestSpooler(t, "kitchen=tcp://"+addr+",front=file://"+filepath.Join(t.TempDir(), "front.bin"), 3)
+	first, err := s.Submit(1, models.PrintJobKitchen, "grill", []byte("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Submit(2, models.PrintJobKitchen, "grill", []byte("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isFailed := func(j models.PrintJob) bool { return j.Status == models.PrintJobFailed }
+	for _, id := range []int{first.ID, second.ID} {
+		job := waitForJob(t, s, id, isFailed)
+		if job.Attempts != 3 || job.LastError == "" || job.Data == nil {
+			t.Errorf("failed job = %+v, want 3 attempts with the error and the data kept", job)
+		}
+	}
+}
+
+func TestSpoolerCheck(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSpooler(t, "kitchen=file://"+filepath.Join(dir, "k.bin")+",front=file://"+filepath.Join(dir, "f.bin"), 1)
+	if err := s.Check(context.Background()); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	s.Close()
+	if err := s.Check(context.Background()); err == nil {
+		t.Error("Check() after Close succeeded")
+	}
+}
+
+func TestNewSpoolerRejects(t *testing.T) {
+	cfg := &config.PrintingConfig{
+		Printers:       "kitchen=tcp://10.0.0.5,front=tcp://10.0.0.6",
+		DefaultPrinter: "kitchen",
+		ReceiptPrinter: "front",
+	}
+	router, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		delay    time.Duration
+		attempts int
+	}{
+		{2 * time.Second, 0},
+		{0, 3},
+		{-time.Second, 3},
+	} {
+		cfg.RetryDelay, cfg.MaxAttempts = tt.delay, tt.attempts
+		if s, err := NewSpooler(router, cfg); err == nil {
+			s.Close()
+			t.Errorf("NewSpooler(delay %s, attempts %d) succeeded", tt.delay, tt.attempts)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package printing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/escpos"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const ticketWidth = 48
+
+// TicketBytes renders a kitchen ticket: station and table in large print,
+// then every item with its modifiers and note, then the times.
+func TicketBytes(t models.KitchenTicket, printedAt time.Time) []byte {
+	rule := strings.Repeat("=", ticketWidth)
+	b := escpos.NewBuilder()
+
+	b.Align(escpos.AlignCenter).SetSize(escpos.SizeDouble).Bold(true).Line(strings.ToUpper(t.Station))
+	if t.Reprint {
+		b.SetSize(escpos.SizeNormal).Line("*** REPRINT ***")
+	}
+	b.Align(escpos.AlignLeft).SetSize(escpos.SizeDouble).Line(t.Table)
+	b.SetSize(escpos.SizeNormal).Bold(false)
+	b.Line(fmt.Sprintf("Order #%d  Guests %d", t.OrderID, t.Guests))
+	b.Line("Ordered " + clock(t.OrderedAt))
+	b.Line(rule)
+
+	for _, item := range t.Items {
+		b.SetSize(escpos.SizeDoubleHeight).Bold(true).Line(fmt.Sprintf("%d x %s", item.Quantity, item.Name))
+		b.SetSize(escpos.SizeNormal).Bold(false)
+		for _, m := range item.Modifiers {
+			b.Line("   + " + m)
+		}
+		if item.Note != "" {
+			b.Bold(true).Line("   ! " + item.Note).Bold(false)
+		}
+	}
+
+	b.Line(rule)
+	b.Line("Printed " + printedAt.Format("15:04:05"))
+	return b.Feed(4).Cut().Beep(2).Bytes()
+}
+
+// clock shortens a stored timestamp to the time of day.
+func clock(s string) string {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.Format("15:04")
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package printing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/escpos"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func testTicket() models.KitchenTicket {
+	return models.KitchenTicket{
+		OrderID:   42,
+		Station:   "grill",
+		Table:     "Table 7",
+		Guests:    3,
+		OrderedAt: "2026-03-01T18:25:10Z",
+		Items: []models.TicketItem{
+			{Name: "Burger", Quantity: 2, Modifiers: []string{"Lunch deal"}, Note: "no onions"},
+			{Name: "Crème brûlée", Quantity: 1},
+		},
+	}
+}
+
+func TestTicketBytes(t *testing.T) {
+	printedAt := time.Date(2026, 3, 1, 18, 31, 4, 0, time.UTC)
+	got := TicketBytes(testTicket(), printedAt)
+
+	// Each line is built the way TicketBytes builds it, so the check covers
+	// the styling around the text as well as the text.
+	rule := strings.Repeat("=", ticketWidth)
+	want := [][]byte{
+		escpos.NewBuilder().Bytes(),
+		(&escpos.Builder{}).Align(escpos.AlignCenter).SetSize(escpos.SizeDouble).Bold(true).Line("GRILL").Bytes(),
+		(&escpos.Builder{}).Align(escpos.AlignLeft).SetSize(escpos.SizeDouble).Line("Table 7").Bytes(),
+		(&escpos.Builder{}).Line("Order #42  Guests 3").Line("Ordered 18:25").Line(rule).Bytes(),
+		(&escpos.Builder{}).SetSize(escpos.SizeDoubleHeight).Bold(true).Line("2 x Burger").Bytes(),
+		(&escpos.Builder{}).Line("   + Lunch deal").Bold(true).Line("   ! no onions").Bold(false).Bytes(),
+		(&escpos.Builder{}).SetSize(escpos.SizeDoubleHeight).Bold(true).Line("1 x Crème brûlée").Bytes(),
+		(&escpos.Builder{}).Line(rule).Line("Printed 18:31:04").Feed(4).Cut().Beep(2).Bytes(),
+	}
+	rest := got
+	for i, part := range want {
+		at := bytes.Index(rest, part)
+		if at < 0 {
+			t.Fatalf("part %d % x not found in order in ticket:\n% x", i, part, got)
+		}
+		rest = rest[at+len(part):]
+	}
+	if len(rest) != 0 {
+		t.Errorf("ticket ends with % x after the beep", rest)
+	}
+	if !bytes.HasPrefix(got, want[0]) {
+		t.Errorf("ticket does not start by resetting the printer: % x", got[:8])
+	}
+	if bytes.Contains(got, []byte("REPRINT")) {
+		t.Error("first print is marked as a reprint")
+	}
+}
+
+func TestTicketBytesReprint(t *testing.T) {
+	ticket := testTicket()
+	ticket.Reprint = true
+	got := TicketBytes(ticket, time.Now())
+
+	title := (&escpos.Builder{}).Line("GRILL").SetSize(escpos.SizeNormal).Line("*** REPRINT ***").Bytes()
+	if !bytes.Contains(got, title) {
+		t.Errorf("reprint is not marked under the station:\n% x", got)
+	}
+}
@@ -0,0 +1,213 @@
+// Package promotions works out which coupons and automatic promotions apply
+// to an order and how much each of them takes off.
+package promotions
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type Input struct {
+	Order     models.Order            // lines must already be priced
+	MenuItems map[int]models.MenuItem // used to resolve line categories
+	Automatic []models.Promotion
+	Coupon    *models.Promotion // nil when no coupon was entered
+	Usage     map[int]models.PromotionUsage
+
+	// Automatic rules such as happy hour are judged by when the order was
+	// placed, coupons by when they are redeemed.
+	OrderedAt time.Time
+	Now       time.Time
+}
+
+type candidate struct {
+	promotion models.Promotion
+	amount    int
+}
+
+// Apply returns the discount breakdown for the order. Automatic promotions
+// that do not match are skipped silently; a coupon that cannot be applied is
+// reported as an error so the guest can be told why.
+func Apply(in Input) ([]models.OrderDiscount, error) {
+	subtotal := 0
+	for _, item := range in.Order.Items {
+		subtotal += lineTotal(item)
+	}
+
+	var stackable, exclusive []candidate
+	add := func(c candidate) {
+		if c.promotion.Stackable {
+			stackable = append(stackable, c)
+		} else {
+			exclusive = append(exclusive, c)
+		}
+	}
+
+	automatic := append([]models.Promotion(nil), in.Automatic...)
+	sort.Slice(automatic, func(i, j int) bool { return automatic[i].ID < automatic[j].ID })
+	for _, p := range automatic {
+		if p.IsCoupon() || !p.ActiveAt(in.OrderedAt) || subtotal < p.MinSpend || limitReached(p, in.Usage) {
+			continue
+		}
+		if amount := discountAmount(p, in.Order, in.MenuItems); amount > 0 {
+			add(candidate{promotion: p, amount: amount})
+		}
+	}
+
+	if in.Coupon != nil {
+		c, err := couponCandidate(*in.Coupon, in, subtotal)
+		if err != nil {
+			return nil, err
+		}
+		add(c)
+	}
+
+	best := pickBest(stackable, exclusive)
+	if in.Coupon != nil && !contains(best, in.Coupon.ID) {
+		return nil, fmt.Errorf("%w: a better promotion is already applied to this order", models.ErrCouponNotApplicable)
+	}
+
+	// Never discount more than the order is worth
+	discounts := make([]models.OrderDiscount, 0, len(best))
+	remaining := subtotal
+	for _, c := range best {
+		amount := min(c.amount, remaining)
+		if amount <= 0 {
+			continue
+		}
+		remaining -= amount
+		discounts = append(discounts, models.OrderDiscount{
+			PromotionID: c.promotion.ID,
+			Name:        c.promotion.Name,
+			Code:        c.promotion.Code,
+			Amount:      amount,
+		})
+	}
+	return discounts, nil
+}
+
+func couponCandidate(p models.Promotion, in Input, subtotal int) (candidate, error) {
+	if !p.Active {
+		return candidate{}, fmt.Errorf("%w: coupon %s is no longer active", models.ErrCouponNotApplicable, p.Code)
+	}
+	if p.StartsAt != nil && in.Now.Before(*p.StartsAt) {
+		return candidate{}, fmt.Errorf("%w: coupon %s is not valid yet", models.ErrCouponNotApplicable, p.Code)
+	}
+	if p.EndsAt != nil && !in.Now.Before(*p.EndsAt) {
+		return candidate{}, fmt.Errorf("%w: coupon %s has expired", models.ErrCouponNotApplicable, p.Code)
+	}
+	if !p.ActiveAt(in.Now) {
+		return candidate{}, fmt.Errorf("%w: coupon %s is only valid between %s and %s", models.ErrCouponNotApplicable, p.Code, p.DailyStart, p.DailyEnd)
+	}
+	if subtotal < p.MinSpend {
+		return candidate{}, fmt.Errorf("%w: coupon %s needs a minimum spend of %d cents", models.ErrCouponNotApplicable, p.Code, p.MinSpend)
+	}
+	if limitReached(p, in.Usage) {
+		return candidate{}, fmt.Errorf("%w: coupon %s", models.ErrPromotionLimitReached, p.Code)
+	}
+	amount := discountAmount(p, in.Order, in.MenuItems)
+	if amount <= 0 {
+		return candidate{}, fmt.Errorf("%w: no items in this order qualify for coupon %s", models.ErrCouponNotApplicable, p.Code)
+	}
+	return candidate{promotion: p, amount: amount}, nil
+}
+
+func limitReached(p models.Promotion, usage map[int]models.PromotionUsage) bool {
+	u := usage[p.ID]
+	return (p.UsageLimit > 0 && u.Total >= p.UsageLimit) ||
+		(p.PerUserLimit > 0 && u.ForUser >= p.PerUserLimit)
+}
+
+// pickBest chooses between all stackable promotions together and each
+// exclusive promotion on its own, whichever saves the guest the most.
+func pickBest(stackable, exclusive []candidate) []candidate {
+	best := stackable
+	bestTotal := total(stackable)
+	for _, c := range exclusive {
+		// A coupon is entered last, so on a tie it wins over earlier candidates
+		if c.amount > bestTotal || (c.amount == bestTotal && c.promotion.IsCoupon()) {
+			best = []candidate{c}
+			bestTotal = c.amount
+		}
+	}
+	return best
+}
+
+func total(candidates []candidate) int {
+	sum := 0
+	for _, c := range candidates {
+		sum += c.amount
+	}
+	return sum
+}
+
+func contains(candidates []candidate, promotionID int) bool {
+	for _, c := range candidates {
+		if c.promotion.ID == promotionID {
+			return true
+		}
+	}
+	return false
+}
+
+// discountAmount is what the promotion takes off the order, in cents.
+func discountAmount(p models.Promotion, order models.Order, menuItems map[int]models.MenuItem) int {
+	var lines []models.OrderItem
+	for _, item := range order.Items {
+		if p.Category == "" {
+			lines = append(lines, item)
+			continue
+		}
+		// Bundles are already discounted and have no single category
+		if !item.IsBundle() && menuItems[item.MenuItemID].Category == p.Category {
+			lines = append(lines, item)
+		}
+	}
+
+	eligible := 0
+	for _, item := range lines {
+		eligible += lineTotal(item)
+	}
+	if eligible == 0 {
+		return 0
+	}
+
+	switch p.Type {
+	case models.PromotionTypePercent:
+		return int(math.Round(float64(eligible) * float64(p.Value) / 100))
+	case models.PromotionTypeFixed:
+		return min(p.Value, eligible)
+	case models.PromotionTypeBuyXGetY:
+		return buyXGetYAmount(p, lines)
+	}
+	return 0
+}
+
+// buyXGetYAmount lines up all units from most to least expensive; in every
+// full group of BuyQuantity+FreeQuantity units the cheapest are free.
+func buyXGetYAmount(p models.Promotion, lines []models.OrderItem) int {
+	var units []int
+	for _, item := range lines {
+		for i := 0; i < item.Quantity; i++ {
+			units = append(units, item.Price)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(units)))
+
+	group := p.BuyQuantity + p.FreeQuantity
+	amount := 0
+	for start := 0; start+group <= len(units); start += group {
+		for _, price := range units[start+p.BuyQuantity : start+group] {
+			amount += price
+		}
+	}
+	return amount
+}
+
+func lineTotal(item models.OrderItem) int {
+	return item.Price * item.Quantity
+}
@@ -0,0 +1,367 @@
+package promotions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+var testMenu = map[int]models.MenuItem{
+	1: {ID: 1, Name: "Burger", Category: "food", Price: 1000},
+	2: {ID: 2, Name: "Beer", Category: "drinks", Price: 500},
+	3: {ID: 3, Name: "Fries", Category: "food", Price: 300},
+	4: {ID: 4, Name: "Cake", Category: "desserts", Price: 600},
+}
+
+func line(menuItemID, quantity int) models.OrderItem {
+	return models.OrderItem{MenuItemID: menuItemID, Quantity: quantity, Price: testMenu[menuItemID].Price}
+}
+
+// testOrder is 2 burgers and a beer, 25.00 in all.
+var testOrder = models.Order{UserID: 3, Items: []models.OrderItem{line(1, 2), line(2, 1)}}
+
+func percent(id, value int, stackable bool) models.Promotion {
+	return models.Promotion{ID: id, Name: "Percent", Type: models.PromotionTypePercent, Value: value, Stackable: stackable, Active: true}
+}
+
+func fixed(id, value int, stackable bool) models.Promotion {
+	return models.Promotion{ID: id, Name: "Fixed", Type: models.PromotionTypeFixed, Value: value, Stackable: stackable, Active: true}
+}
+
+func coupon(p models.Promotion) *models.Promotion {
+	p.Code = "SAVE"
+	return &p
+}
+
+// applied is what a discount says, in short.
+type applied struct{ id, amount int }
+
+func appliedDiscounts(discounts []models.OrderDiscount) []applied {
+	got := make([]applied, len(discounts))
+	for i, d := range discounts {
+		got[i] = applied{d.PromotionID, d.Amount}
+	}
+	return got
+}
+
+func TestApply(t *testing.T) {
+	minSpend := func(p models.Promotion, cents int) models.Promotion {
+		p.MinSpend = cents
+		return p
+	}
+	limited := func(p models.Promotion, total, perUser int) models.Promotion {
+		p.UsageLimit, p.PerUserLimit = total, perUser
+		return p
+	}
+	drinks := percent(5, 50, false)
+	drinks.Category = "drinks"
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	inactive := fixed(6, 300, true)
+	inactive.Active = false
+	expired := fixed(7, 300, true)
+	expired.EndsAt = &now
+
+	tests := []struct {
+		name      string
+		order     models.Order
+		automatic []models.Promotion
+		coupon    *models.Promotion
+		usage     map[int]models.PromotionUsage
+		want      []applied
+		wantErr   error
+	}{
+		{
+			name:  "nothing applies",
+			order: testOrder,
+			want:  []applied{},
+		},
+		{
+			name:      "stackable promotions add up, in id order",
+			order:     testOrder,
+			automatic: []models.Promotion{fixed(2, 500, true), percent(1, 10, true)},
+			want:      []applied{{1, 250}, {2, 500}},
+		},
+		{
+			name:      "an exclusive promotion beats a smaller stack",
+			order:     testOrder,
+			automatic: []models.Promotion{percent(1, 10, true), fixed(2, 500, true), percent(3, 40, false)},
+			want:      []applied{{3, 1000}},
+		},
+		{
+			name:      "a stack beats a smaller exclusive promotion",
+			order:     testOrder,
+			automatic: []models.Promotion{percent(1, 10, true), fixed(2, 500, true), drinks},
+			want:      []applied{{1, 250}, {2, 500}},
+		},
+		{
+			name:      "the best of several exclusive promotions",
+			order:     testOrder,
+			automatic: []models.Promotion{fixed(3, 400, false), drinks, fixed(4, 600, false)},
+			want:      []applied{{4, 600}},
+		},
+		{
+			name:      "the first exclusive promotion wins a tie between automatic ones",
+			order:     testOrder,
+			automatic: []models.Promotion{fixed(4, 300, false), fixed(3, 300, false)},
+			want:      []applied{{3, 300}},
+		},
+		{
+			name:      "a coupon wins a tie with an automatic promotion",
+			order:     testOrder,
+			automatic: []models.Promotion{fixed(3, 300, false)},
+			coupon:    coupon(fixed(10, 300, false)),
+			want:      []applied{{10, 300}},
+		},
+		{
+			name:      "a coupon wins a tie with a stack",
+			order:     testOrder,
+			automatic: []models.Promotion{percent(1, 10, true), fixed(2, 50, true)},
+			coupon:    coupon(fixed(10, 300, false)),
+			want:      []applied{{10, 300}},
+		},
+		{
+			name:      "a coupon losing to an automatic promotion is refused",
+			order:     testOrder,
+			automatic: []models.Promotion{fixed(3, 500, false)},
+			coupon:    coupon(fixed(10, 300, false)),
+			wantErr:   models.ErrCouponNotApplicable,
+		},
+		{
+			name:      "a stackable coupon joins the stack",
+			order:     testOrder,
+			automatic: []models.Promotion{percent(1, 10, true)},
+			coupon:    coupon(fixed(10, 100, true)),
+			want:      []applied{{1, 250}, {10, 100}},
+		},
+		{
+			name:      "discounts stop at the subtotal",
+			order:     testOrder,
+			automatic: []models.Promotion{percent(1, 10, true), fixed(2, 5000, true)},
+			want:      []applied{{1, 250}, {2, 2250}},
+		},
+		{
+			name:      "an automatic promotion below its minimum spend is skipped",
+			order:     testOrder,
+			automatic: []models.Promotion{minSpend(percent(1, 10, true), 2501)},
+			want:      []applied{},
+		},
+		{
+			name:      "the minimum spend is inclusive",
+			order:     testOrder,
+			automatic: []models.Promotion{minSpend(percent(1, 10, true), 2500)},
+			want:      []applied{{1, 250}},
+		},
+		{
+			name:    "a coupon below its minimum spend is refused",
+			order:   testOrder,
+			coupon:  coupon(minSpend(fixed(10, 300, true), 3000)),
+			wantErr: models.ErrCouponNotApplicable,
+		},
+		{
+			name:      "an automatic promotion used up is skipped",
+			order:     testOrder,
+			automatic: []models.Promotion{limited(percent(1, 10, true), 5, 0), limited(fixed(2, 100, true), 0, 1)},
+			usage:     map[int]models.PromotionUsage{1: {Total: 5}, 2: {Total: 9, ForUser: 1}},
+			want:      []applied{},
+		},
+		{
+			name:      "an automatic promotion below its limits applies",
+			order:     testOrder,
+			automatic: []models.Promotion{limited(percent(1, 10, true), 5, 2)},
+			usage:     map[int]models.PromotionUsage{1: {Total: 4, ForUser: 1}},
+			want:      []applied{{1, 250}},
+		},
+		{
+			name:    "a coupon used up in total is refused",
+			order:   testOrder,
+			coupon:  coupon(limited(fixed(10, 300, true), 10, 0)),
+			usage:   map[int]models.PromotionUsage{10: {Total: 10}},
+			wantErr: models.ErrPromotionLimitReached,
+		},
+		{
+			name:    "a coupon used up by the guest is refused",
+			order:   testOrder,
+			coupon:  coupon(limited(fixed(10, 300, true), 10, 1)),
+			usage:   map[int]models.PromotionUsage{10: {Total: 3, ForUser: 1}},
+			wantErr: models.ErrPromotionLimitReached,
+		},
+		{
+			name:    "an inactive coupon is refused",
+			order:   testOrder,
+			coupon:  coupon(inactive),
+			wantErr: models.ErrCouponNotApplicable,
+		},
+		{
+			name:    "an expired coupon is refused",
+			order:   testOrder,
+			coupon:  coupon(expired),
+			wantErr: models.ErrCouponNotApplicable,
+		},
+		{
+			name:    "a coupon for items not on the order is refused",
+			order:   testOrder,
+			coupon:  coupon(models.Promotion{ID: 10, Type: models.PromotionTypePercent, Value: 10, Category: "desserts", Active: true}),
+			wantErr: models.ErrCouponNotApplicable,
+		},
+		{
+			// Burgers and fries make 5 units; one group of three has a free fries
+			name:  "buy 2 get 1 over several lines",
+			order: models.Order{Items: []models.OrderItem{line(1, 2), line(3, 3), line(2, 1)}},
+			automatic: []models.Promotion{{
+				ID: 1, Type: models.PromotionTypeBuyXGetY, BuyQuantity: 2, FreeQuantity: 1,
+				Category: "food", Stackable: true, Active: true,
+			}},
+			want: []applied{{1, 300}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discounts, err := Apply(Input{
+				Order:     tt.order,
+				MenuItems: testMenu,
+				Automatic: tt.automatic,
+				Coupon:    tt.coupon,
+				Usage:     tt.usage,
+				OrderedAt: now,
+				Now:       now,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() failed: %v", err)
+			}
+			if got := appliedDiscounts(discounts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyHappyHourAcrossMidnight(t *testing.T) {
+	restaurant := config.RestaurantConfig{Timezone: "Asia/Kolkata"} // UTC+5:30
+	loc := restaurant.Location()
+	if loc.String() != restaurant.Timezone {
+		t.Fatalf("time zone %s did not load", restaurant.Timezone)
+	}
+	lateNight := percent(1, 20, true)
+	lateNight.DailyStart, lateNight.DailyEnd = "22:00", "02:00"
+	lateCoupon := coupon(fixed(10, 300, false))
+	lateCoupon.DailyStart, lateCoupon.DailyEnd = "22:00", "02:00"
+
+	tests := []struct {
+		utc    string
+		active bool
+	}{
+		{"2030-06-01T16:29:00Z", false}, // 21:59 in Kolkata
+		{"2030-06-01T16:30:00Z", true},  // 22:00
+		{"2030-06-01T18:00:00Z", true},  // 23:30
+		{"2030-06-01T18:30:00Z", true},  // midnight
+		{"2030-06-01T20:29:00Z", true},  // 01:59
+		{"2030-06-01T20:30:00Z", false}, // 02:00
+		{"2030-06-01T23:00:00Z", false}, // 04:30, though 23:00 in UTC
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		local := at.In(loc)
+
+		discounts, err := Apply(Input{Order: testOrder, MenuItems: testMenu, Automatic: []models.Promotion{lateNight}, OrderedAt: local, Now: local})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(discounts) == 1; got != tt.active {
+			t.Errorf("ordered at %s (%s): happy hour applied %v, want %v", tt.utc, local.Format("15:04"), got, tt.active)
+		}
+
+		_, err = Apply(Input{Order: testOrder, MenuItems: testMenu, Coupon: lateCoupon, OrderedAt: local, Now: local})
+		if (err == nil) != tt.active {
+			t.Errorf("redeemed at %s (%s): coupon error %v, want it valid %v", tt.utc, local.Format("15:04"), err, tt.active)
+		}
+	}
+
+	// Ordered during happy hour, but repriced after: automatic promotions go
+	// by when the order was placed
+	ordered := time.Date(2030, 6, 1, 23, 0, 0, 0, loc)
+	later := ordered.Add(4 * time.Hour)
+	discounts, err := Apply(Input{Order: testOrder, MenuItems: testMenu, Automatic: []models.Promotion{lateNight}, OrderedAt: ordered, Now: later})
+	if err != nil || len(discounts) != 1 {
+		t.Errorf("repriced after happy hour: %v, %v; want the discount kept", discounts, err)
+	}
+}
+
+func TestPickBest(t *testing.T) {
+	auto := func(id, amount int) candidate {
+		return candidate{promotion: models.Promotion{ID: id}, amount: amount}
+	}
+	code := func(id, amount int) candidate {
+		return candidate{promotion: models.Promotion{ID: id, Code: "SAVE"}, amount: amount}
+	}
+	ids := func(candidates []candidate) []int {
+		got := []int{}
+		for _, c := range candidates {
+			got = append(got, c.promotion.ID)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name      string
+		stackable []candidate
+		exclusive []candidate
+		want      []int
+	}{
+		{"nothing", nil, nil, []int{}},
+		{"the whole stack", []candidate{auto(1, 100), auto(2, 200)}, nil, []int{1, 2}},
+		{"an exclusive one alone", nil, []candidate{auto(3, 100)}, []int{3}},
+		{"stack beats exclusive", []candidate{auto(1, 100), auto(2, 200)}, []candidate{auto(3, 250)}, []int{1, 2}},
+		{"exclusive beats stack", []candidate{auto(1, 100), auto(2, 200)}, []candidate{auto(3, 350)}, []int{3}},
+		{"stack wins a tie with an automatic promotion", []candidate{auto(1, 100), auto(2, 200)}, []candidate{auto(3, 300)}, []int{1, 2}},
+		{"a coupon wins a tie with the stack", []candidate{auto(1, 100), auto(2, 200)}, []candidate{code(10, 300)}, []int{10}},
+		{"a coupon wins a tie with an earlier exclusive one", nil, []candidate{auto(3, 300), code(10, 300)}, []int{10}},
+		{"the largest exclusive one", nil, []candidate{auto(3, 300), auto(4, 500), auto(5, 400)}, []int{4}},
+	}
+	for _, tt := range tests {
+		if got := ids(pickBest(tt.stackable, tt.exclusive)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: pickBest() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuyXGetYAmount(t *testing.T) {
+	buy := func(x, y int) models.Promotion {
+		return models.Promotion{Type: models.PromotionTypeBuyXGetY, BuyQuantity: x, FreeQuantity: y}
+	}
+	tests := []struct {
+		name      string
+		promotion models.Promotion
+		lines     []models.OrderItem
+		want      int
+	}{
+		{"no lines", buy(2, 1), nil, 0},
+		{"not a full group", buy(2, 1), []models.OrderItem{line(1, 2)}, 0},
+		{"one line, one group", buy(2, 1), []models.OrderItem{line(3, 3)}, 300},
+		{"one line, two groups and change", buy(2, 1), []models.OrderItem{line(3, 7)}, 600},
+		// 1000 1000 | 600 free; 300 300 left over
+		{"the cheapest of each group is free", buy(2, 1), []models.OrderItem{line(3, 2), line(1, 2), line(4, 1)}, 600},
+		// 1000 600 500 | 300 300 300 free
+		{"groups across lines", buy(3, 3), []models.OrderItem{line(2, 1), line(3, 3), line(4, 1), line(1, 1)}, 900},
+		{"buy 1 get 1", buy(1, 1), []models.OrderItem{line(1, 1), line(4, 1), line(3, 2)}, 900},
+		// 1000 | 500 500 free; 300 | 300 300 free
+		{"several free per group", buy(1, 2), []models.OrderItem{line(1, 1), line(2, 2), line(3, 3)}, 1600},
+	}
+	for _, tt := range tests {
+		if got := buyXGetYAmount(tt.promotion, tt.lines); got != tt.want {
+			t.Errorf("%s: buyXGetYAmount() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
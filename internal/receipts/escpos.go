@@ -0,0 +1,30 @@
+package receipts
+
+import (
+	"io"
+
+	"github.com/abdullahnettoor/tastybites/internal/escpos"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// ESCPOS renders the receipt for an 80mm thermal printer and cuts the paper.
+func ESCPOS(w io.Writer, r models.Receipt) error {
+	_, err := w.Write(ESCPOSBytes(r))
+	return err
+}
+
+func ESCPOSBytes(r models.Receipt) []byte {
+	b := escpos.NewBuilder()
+	isBold := false
+	for _, l := range layout(r) {
+		if l.bold != isBold {
+			b.Bold(l.bold)
+			isBold = l.bold
+		}
+		b.Line(l.text)
+	}
+	if isBold {
+		b.Bold(false)
+	}
+	return b.Feed(3).Cut().Bytes()
+}
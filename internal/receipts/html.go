@@ -0,0 +1,107 @@
+package receipts
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money":   Money,
+	"neg":     func(cents int) string { return Money(-cents) },
+	"percent": Percent,
+	"time":    formatTime,
+	"reason":  func(r models.RefundReason) string { return strings.ReplaceAll(string(r), "_", " ") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 32rem; margin: 2rem auto; color: #222; }
+header, footer { text-align: center; }
+h1 { margin-bottom: .25rem; }
+table { width: 100%; border-collapse: collapse; margin: 1rem 0; }
+td { padding: .2rem 0; vertical-align: top; }
+td.amount { text-align: right; white-space: nowrap; }
+tr.total td { font-weight: bold; border-top: 1px solid #222; }
+.muted { color: #666; font-size: .9em; }
+</style>
+</head>
+<body>
+{{- with .Receipt}}
+<header>
+<h1>{{.Restaurant.Name}}</h1>
+{{- with .Restaurant.Address}}<div>{{.}}</div>{{end}}
+{{- if or .Restaurant.Phone .Restaurant.Email}}<div>{{.Restaurant.Phone}} {{.Restaurant.Email}}</div>{{end}}
+{{- with .Restaurant.TaxID}}<div>VAT No {{.}}</div>{{end}}
+</header>
+
+<section>
+{{- with .Invoice}}
+<h2>Tax invoice {{.Number}}</h2>
+<div>Issued {{time .IssuedAt}}</div>
+<div>Bill to: {{.CustomerName}}{{with .CustomerAddress}}, {{.}}{{end}}{{with .CustomerTaxID}} (VAT No {{.}}){{end}}</div>
+{{- end}}
+<div>Order #{{.Order.ID}}, {{time .Order.CreatedAt}}{{if .Order.TableID}}, table {{.Order.TableID}}, {{.Order.Guests}} guests{{end}}</div>
+</section>
+
+<table>
+{{- range .Lines}}
+<tr><td>{{.Quantity}} &times; {{.Name}}{{if gt .Quantity 1}} <span class="muted">@ {{money .UnitPrice}}</span>{{end}}
+{{- range .Components}}<div class="muted">&ndash; {{.}}</div>{{end}}</td><td class="amount">{{money .Total}}</td></tr>
+{{- end}}
+</table>
+
+<table>
+<tr><td>Subtotal</td><td class="amount">{{money .Order.Subtotal}}</td></tr>
+{{- range .Order.Discounts}}
+<tr><td>{{.Name}}{{with .Code}} ({{.}}){{end}}</td><td class="amount">{{neg .Amount}}</td></tr>
+{{- end}}
+{{- if .Order.ServiceCharge}}
+<tr><td>Service charge</td><td class="amount">{{money .Order.ServiceCharge}}</td></tr>
+{{- end}}
+{{- $inclusive := .Order.TaxInclusive}}
+{{- range .Order.Taxes}}
+<tr><td>{{if $inclusive}}incl. {{end}}{{.Name}} {{percent .Rate}} on {{money .Taxable}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+{{- if .Order.Tip}}
+<tr><td>Tip</td><td class="amount">{{money .Order.Tip}}</td></tr>
+{{- end}}
+<tr class="total"><td>Total {{.Currency}}</td><td class="amount">{{money .Order.TotalPrice}}</td></tr>
+</table>
+
+<table>
+{{- range .Payments}}
+<tr><td>Paid by {{.Provider}} <span class="muted">{{time .CreatedAt}}</span></td><td class="amount">{{money .Captured}}</td></tr>
+{{- end}}
+{{- range .Refunds}}
+<tr><td>Refund ({{.Method}}, {{reason .Reason}})</td><td class="amount">{{neg .Amount}}</td></tr>
+{{- end}}
+<tr class="total"><td>Balance due</td><td class="amount">{{money .Balance}}</td></tr>
+</table>
+
+<footer>
+{{- if .Order.TaxInclusive}}<div class="muted">Prices include VAT</div>{{end}}
+<p>Thank you for dining with us!</p>
+<div class="muted">Printed {{.PrintedAt.Format "2006-01-02 15:04"}}</div>
+</footer>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders the receipt as a standalone HTML page.
+func HTML(w io.Writer, r models.Receipt) error {
+	data := struct {
+		Title   string
+		Receipt models.Receipt
+	}{Title(r), r}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return nil
+}
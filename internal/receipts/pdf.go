@@ -0,0 +1,120 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Page geometry in points: A4 with the receipt column centred on it.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	pageMargin   = 56
+	fontSize     = 10
+	lineHeight   = 12
+	charWidth    = 6 // Courier glyphs are 600/1000 em wide
+	linesPerPage = (pageHeight - 2*pageMargin) / lineHeight
+)
+
+// PDF renders the receipt as a PDF 1.4 document using the standard Courier
+// fonts, so nothing has to be embedded.
+func PDF(w io.Writer, r models.Receipt) error {
+	lines := layout(r)
+	var pages [][]line
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	doc := &pdfWriter{}
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed objects first, then a page and its contents per page
+	const catalog, pageTree, regular, bold, info, firstPage = 1, 2, 3, 4, 5, 6
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	doc.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pageTree))
+	doc.object(pageTree, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	doc.object(regular, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	doc.object(bold, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	doc.object(info, fmt.Sprintf("<< /Title (%s) /Producer (TastyBites) /CreationDate (D:%s) >>",
+		pdfString(Title(r)), r.PrintedAt.UTC().Format("20060102150405Z")))
+
+	for i, page := range pages {
+		content := pageContent(page)
+		doc.object(firstPage+2*i, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+			pageTree, pageWidth, pageHeight, regular, bold, firstPage+2*i+1))
+		doc.object(firstPage+2*i+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	// Cross-reference table, one fixed width entry per object
+	xref := doc.Len()
+	fmt.Fprintf(doc, "xref\n0 %d\n0000000000 65535 f \n", len(doc.offsets)+1)
+	for _, offset := range doc.offsets {
+		fmt.Fprintf(doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(doc, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(doc.offsets)+1, catalog, info, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
+
+type pdfWriter struct {
+	bytes.Buffer
+	offsets []int // byte offset of each object, by object number - 1
+}
+
+// object writes object n; objects must be written in order.
+func (d *pdfWriter) object(n int, body string) {
+	d.offsets = append(d.offsets, d.Len())
+	fmt.Fprintf(d, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+func pageContent(lines []line) string {
+	var b strings.Builder
+	x := (pageWidth - Width*charWidth) / 2
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, x, pageHeight-pageMargin)
+	isBold := false
+	for _, l := range lines {
+		if l.bold != isBold {
+			font := "/F1"
+			if l.bold {
+				font = "/F2"
+			}
+			fmt.Fprintf(&b, "%s %d Tf\n", font, fontSize)
+			isBold = l.bold
+		}
+		fmt.Fprintf(&b, "(%s) Tj T*\n", pdfString(l.text))
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsi encoding. Latin-1
+// characters map to themselves; anything else becomes a question mark.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
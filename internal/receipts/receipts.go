@@ -0,0 +1,99 @@
+// Package receipts renders receipts and invoices as plain text, HTML and
+// PDF. The PDF writer is self-contained and lays out the same lines as the
+// text receipt.
+package receipts
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatHTML   Format = "html"
+	FormatPDF    Format = "pdf"
+	FormatESCPOS Format = "escpos" // raw bytes for a thermal printer
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "", "txt", FormatText:
+		return FormatText, nil
+	case FormatHTML, FormatPDF, FormatESCPOS:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: receipt format must be text, html, pdf or escpos", models.ErrInvalidInput)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatESCPOS:
+		return "application/octet-stream"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	switch f {
+	case FormatText:
+		return "txt"
+	case FormatESCPOS:
+		return "bin"
+	}
+	return string(f)
+}
+
+// Render writes the receipt in the given format.
+func Render(w io.Writer, f Format, r models.Receipt) error {
+	switch f {
+	case FormatHTML:
+		return HTML(w, r)
+	case FormatPDF:
+		return PDF(w, r)
+	case FormatESCPOS:
+		return ESCPOS(w, r)
+	}
+	_, err := io.WriteString(w, Text(r))
+	return err
+}
+
+// Title is what the document is called: an invoice once one was issued.
+func Title(r models.Receipt) string {
+	if r.Invoice != nil {
+		return "Invoice " + r.Invoice.Number
+	}
+	return fmt.Sprintf("Receipt for order #%d", r.Order.ID)
+}
+
+// Filename is a download name for the receipt in the given format.
+func Filename(r models.Receipt, f Format) string {
+	if r.Invoice != nil {
+		return r.Invoice.Number + "." + f.Extension()
+	}
+	return fmt.Sprintf("receipt-%d.%s", r.Order.ID, f.Extension())
+}
+
+// Money formats cents as a decimal amount, e.g. 1234 as "12.34".
+func Money(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Percent formats basis points, e.g. 1250 as "12.5%".
+func Percent(bp int) string {
+	s := fmt.Sprintf("%d.%02d", bp/100, bp%100)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s + "%"
+}
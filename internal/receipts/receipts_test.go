@@ -0,0 +1,405 @@
+package receipts
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/escpos"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func testReceipt() models.Receipt {
+	return models.Receipt{
+		Restaurant: models.RestaurantDetails{
+			Name:    "TastyBites",
+			Address: "12 Harbour Road, Kochi",
+			Phone:   "+91 484 000 0000",
+			Email:   "hello@tastybites.test",
+			TaxID:   "GB123456789",
+		},
+		Order: models.Order{
+			ID: 42, TableID: 7, Guests: 2, CreatedAt: "2025-04-02T19:30:00Z",
+			Subtotal:  4797,
+			Discounts: []models.OrderDiscount{{Name: "Happy hour", Amount: 300}, {Name: "Welcome", Code: "HELLO", Amount: 100}},
+			Taxes: []models.OrderTax{
+				{Name: "Food", Rate: 500, Taxable: 3998, Amount: 200},
+				{Name: "Alcohol", Rate: 1250, Taxable: 399, Amount: 50},
+			},
+			ServiceCharge: 240,
+			Tip:           500,
+			TotalPrice:    5387,
+		},
+		Lines: []models.ReceiptLine{
+			{Name: "Beef Burger", Quantity: 2, UnitPrice: 1499, Total: 2998},
+			{Name: "Pizza Meal Deal", Quantity: 1, UnitPrice: 1400, Total: 1400, Components: []string{"1 x Pepperoni", "1 x Iced Tea"}},
+			{Name: "Craft Beer", Quantity: 1, UnitPrice: 399, Total: 399},
+		},
+		Payments:  []models.Payment{{Provider: "fake", Captured: 5387, CreatedAt: "2025-04-02T20:15:00Z"}},
+		Refunds:   []models.Refund{{Method: models.RefundMethodCash, Reason: models.RefundReasonWrongItem, Amount: 399}},
+		Paid:      4988,
+		Balance:   0,
+		Currency:  "INR",
+		PrintedAt: time.Date(2025, 4, 2, 20, 16, 0, 0, time.UTC),
+	}
+}
+
+func testInvoice() models.Receipt {
+	r := testReceipt()
+	r.Invoice = &models.Invoice{
+		Number:          "INV-2025-000007",
+		CustomerName:    "Acme Catering Ltd",
+		CustomerTaxID:   "GB987654321",
+		CustomerAddress: "Unit 4, Riverside Industrial Estate, Long Lane, Kochi 682001",
+		IssuedAt:        "2025-04-02T20:20:00Z",
+	}
+	return r
+}
+
+// Lines are padded to Width; the right-hand column ends at column 48.
+const wantReceipt = `                   TastyBites
+             12 Harbour Road, Kochi
+     +91 484 000 0000 hello@tastybites.test
+               VAT No GB123456789
+------------------------------------------------
+Order #42                       2025-04-02 19:30
+Table 7                                 Guests 2
+------------------------------------------------
+2 x Beef Burger                            29.98
+    @ 14.99
+1 x Pizza Meal Deal                        14.00
+    - 1 x Pepperoni
+    - 1 x Iced Tea
+1 x Craft Beer                              3.99
+------------------------------------------------
+Subtotal                                   47.97
+Happy hour                                 -3.00
+Welcome (HELLO)                            -1.00
+Service charge                              2.40
+Food 5% on 39.98                            2.00
+Alcohol 12.5% on 3.99                       0.50
+Tip                                         5.00
+TOTAL INR                                  53.87
+------------------------------------------------
+Paid by fake 2025-04-02 20:15              53.87
+Refund (cash, wrong item)                  -3.99
+Balance due                                 0.00
+------------------------------------------------
+         Thank you for dining with us!
+            Printed 2025-04-02 20:16
+`
+
+const wantInvoice = `                   TastyBites
+             12 Harbour Road, Kochi
+     +91 484 000 0000 hello@tastybites.test
+               VAT No GB123456789
+------------------------------------------------
+TAX INVOICE                      INV-2025-000007
+Issued                          2025-04-02 20:20
+Order #42                       2025-04-02 19:30
+Table 7                                 Guests 2
+Bill to: Acme Catering Ltd
+  Unit 4, Riverside Industrial Estate, Long
+  Lane, Kochi 682001
+  VAT No GB987654321
+------------------------------------------------
+2 x Beef Burger                            29.98
+    @ 14.99
+1 x Pizza Meal Deal                        14.00
+    - 1 x Pepperoni
+    - 1 x Iced Tea
+1 x Craft Beer                              3.99
+------------------------------------------------
+Subtotal                                   47.97
+Happy hour                                 -3.00
+Welcome (HELLO)                            -1.00
+Service charge                              2.40
+Food 5% on 39.98                            2.00
+Alcohol 12.5% on 3.99                       0.50
+Tip                                         5.00
+TOTAL INR                                  53.87
+------------------------------------------------
+Paid by fake 2025-04-02 20:15              53.87
+Refund (cash, wrong item)                  -3.99
+Balance due                                 0.00
+------------------------------------------------
+         Thank you for dining with us!
+            Printed 2025-04-02 20:16
+`
+
+func TestText(t *testing.T) {
+	takeaway := testReceipt()
+	takeaway.Order.TableID = 0
+	takeaway.Order.TaxInclusive = true
+	takeaway.Restaurant = models.RestaurantDetails{Name: "TastyBites"}
+
+	tests := []struct {
+		name    string
+		receipt models.Receipt
+		want    string
+	}{
+		{"receipt", testReceipt(), wantReceipt},
+		{"invoice", testInvoice(), wantInvoice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.receipt); got != tt.want {
+				t.Errorf("Text() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("takeaway with tax included", func(t *testing.T) {
+		got := Text(takeaway)
+		for _, want := range []string{
+			"incl. Food 5% on 39.98                      2.00\n",
+			"               Prices include VAT\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Text() has no %q:\n%s", want, got)
+			}
+		}
+		for _, unwanted := range []string{"Table", "VAT No", "TAX INVOICE"} {
+			if strings.Contains(got, unwanted) {
+				t.Errorf("Text() has %q:\n%s", unwanted, got)
+			}
+		}
+	})
+}
+
+func TestTextFitsWidth(t *testing.T) {
+	r := testInvoice()
+	r.Restaurant.Name = strings.Repeat("Very Long Restaurant Name ", 3)
+	r.Lines = append(r.Lines, models.ReceiptLine{Name: "Crème brûlée with an extraordinarily long name", Quantity: 1, Total: 123456})
+	for _, l := range strings.Split(strings.TrimSuffix(Text(r), "\n"), "\n") {
+		if n := len([]rune(l)); n > Width {
+			t.Errorf("line %q is %d wide", l, n)
+		}
+	}
+	if got, want := columns("1 x Crème brûlée with an extraordinarily long name", "1234.56"), "1 x Crème brûlée with an extraordinaril~ 1234.56"; got != want {
+		t.Errorf("columns() = %q, want %q", got, want)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"", 10, nil},
+		{"one two three", 20, []string{"one two three"}},
+		{"one two three", 7, []string{"one two", "three"}},
+		{"  spaced   out  ", 20, []string{"spaced out"}},
+		{"unbreakable", 5, []string{"unbr~"}},
+	}
+	for _, tt := range tests {
+		if got := wrap(tt.s, tt.width); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	r := testInvoice()
+	r.Invoice.CustomerName = `<script>alert("x")</script>`
+	var b bytes.Buffer
+	if err := HTML(&b, r); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"<title>Invoice INV-2025-000007</title>",
+		"<h2>Tax invoice INV-2025-000007</h2>",
+		"<div>Issued 2025-04-02 20:20</div>",
+		"Bill to: &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;, Unit 4, Riverside Industrial Estate, Long Lane, Kochi 682001 (VAT No GB987654321)",
+		"<div>Order #42, 2025-04-02 19:30, table 7, 2 guests</div>",
+		`<tr><td>2 &times; Beef Burger <span class="muted">@ 14.99</span></td><td class="amount">29.98</td></tr>`,
+		`<div class="muted">&ndash; 1 x Iced Tea</div>`,
+		`<tr><td>Welcome (HELLO)</td><td class="amount">-1.00</td></tr>`,
+		`<tr><td>Alcohol 12.5% on 3.99</td><td class="amount">0.50</td></tr>`,
+		`<tr class="total"><td>Total INR</td><td class="amount">53.87</td></tr>`,
+		`<tr><td>Refund (cash, wrong item)</td><td class="amount">-3.99</td></tr>`,
+		`<tr class="total"><td>Balance due</td><td class="amount">0.00</td></tr>`,
+		`<div class="muted">Printed 2025-04-02 20:16</div>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() has no %q", want)
+		}
+	}
+	if strings.Contains(got, "<script>") || strings.Contains(got, "Prices include VAT") {
+		t.Errorf("HTML() =\n%s", got)
+	}
+
+	b.Reset()
+	if err := HTML(&b, testReceipt()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); !strings.Contains(got, "<title>Receipt for order #42</title>") || strings.Contains(got, "Tax invoice") {
+		t.Errorf("HTML() of a receipt =\n%s", got)
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+	got := ESCPOSBytes(testInvoice())
+
+	if start := escpos.NewBuilder().Bold(true).Bytes(); !bytes.HasPrefix(got, start) {
+		t.Errorf("starts % x, want % x", got[:len(start)], start)
+	}
+	if end := (&escpos.Builder{}).Feed(3).Cut().Bytes(); !bytes.HasSuffix(got, end) {
+		t.Errorf("ends % x, want % x", got[len(got)-len(end):], end)
+	}
+	// The bold lines are the name, the invoice number, the total and the balance
+	if n := bytes.Count(got, []byte{0x1b, 'E', 1}); n != 4 {
+		t.Errorf("bold switched on %d times, want 4", n)
+	}
+	for _, l := range strings.Split(wantInvoice, "\n") {
+		if !bytes.Contains(got, []byte(l)) {
+			t.Errorf("no line %q", l)
+		}
+	}
+}
+
+var xrefEntry = regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`)
+
+func TestPDF(t *testing.T) {
+	long := testInvoice()
+	for range 100 {
+		long.Lines = append(long.Lines, models.ReceiptLine{Name: "Lemonade (fresh)", Quantity: 1, UnitPrice: 299, Total: 299})
+	}
+
+	tests := []struct {
+		name    string
+		receipt models.Receipt
+		pages   int
+	}{
+		{"receipt", testReceipt(), 1},
+		{"invoice", testInvoice(), 1},
+		{"long", long, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := PDF(&b, tt.receipt); err != nil {
+				t.Fatal(err)
+			}
+			doc := b.Bytes()
+			if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+				t.Fatalf("not a PDF:\n%s", doc)
+			}
+			if want := "/Count " + strconv.Itoa(tt.pages) + " >>"; !bytes.Contains(doc, []byte(want)) {
+				t.Errorf("no %q", want)
+			}
+			if want := "/Title (" + pdfString(Title(tt.receipt)) + ")"; !bytes.Contains(doc, []byte(want)) {
+				t.Errorf("no %q", want)
+			}
+
+			// Every cross-reference entry points at its object
+			entries := xrefEntry.FindAllSubmatch(doc, -1)
+			if want := 5 + 2*tt.pages; len(entries) != want {
+				t.Fatalf("%d objects in the cross-reference table, want %d", len(entries), want)
+			}
+			for i, e := range entries {
+				offset, _ := strconv.Atoi(string(e[1]))
+				if want := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(doc[offset:], []byte(want)) {
+					t.Errorf("object %d is not at %d", i+1, offset)
+				}
+			}
+			xref := bytes.Index(doc, []byte("\nxref\n")) + 1
+			if want := "startxref\n" + strconv.Itoa(xref) + "\n"; !bytes.Contains(doc, []byte(want)) {
+				t.Errorf("no %q", want)
+			}
+		})
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct{ s, want string }{
+		{"Fish (large)", `Fish \(large\)`},
+		{`C:\menu`, `C:\\menu`},
+		{"Crème", `Cr\350me`},
+		{"Pizza 🍕", "Pizza ?"},
+	}
+	for _, tt := range tests {
+		if got := pdfString(tt.s); got != tt.want {
+			t.Errorf("pdfString(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatText, false},
+		{"txt", FormatText, false},
+		{"TEXT", FormatText, false},
+		{"html", FormatHTML, false},
+		{"Pdf", FormatPDF, false},
+		{"escpos", FormatESCPOS, false},
+		{"docx", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.s)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.s, got, err)
+		}
+		if err != nil && !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("ParseFormat(%q) error %v, want invalid input", tt.s, err)
+		}
+	}
+}
+
+func TestFilenameAndTitle(t *testing.T) {
+	tests := []struct {
+		receipt  models.Receipt
+		format   Format
+		filename string
+		title    string
+	}{
+		{testReceipt(), FormatText, "receipt-42.txt", "Receipt for order #42"},
+		{testReceipt(), FormatESCPOS, "receipt-42.bin", "Receipt for order #42"},
+		{testInvoice(), FormatPDF, "INV-2025-000007.pdf", "Invoice INV-2025-000007"},
+		{testInvoice(), FormatHTML, "INV-2025-000007.html", "Invoice INV-2025-000007"},
+	}
+	for _, tt := range tests {
+		if got := Filename(tt.receipt, tt.format); got != tt.filename {
+			t.Errorf("Filename(%s) = %q, want %q", tt.format, got, tt.filename)
+		}
+		if got := Title(tt.receipt); got != tt.title {
+			t.Errorf("Title() = %q, want %q", got, tt.title)
+		}
+	}
+}
+
+func TestMoneyAndPercent(t *testing.T) {
+	money := []struct {
+		cents int
+		want  string
+	}{
+		{0, "0.00"}, {5, "0.05"}, {1234, "12.34"}, {-1234, "-12.34"}, {-5, "-0.05"}, {100000, "1000.00"},
+	}
+	for _, tt := range money {
+		if got := Money(tt.cents); got != tt.want {
+			t.Errorf("Money(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+	percent := []struct {
+		bp   int
+		want string
+	}{
+		{0, "0%"}, {500, "5%"}, {1250, "12.5%"}, {1275, "12.75%"}, {2000, "20%"}, {5, "0.05%"},
+	}
+	for _, tt := range percent {
+		if got := Percent(tt.bp); got != tt.want {
+			t.Errorf("Percent(%d) = %q, want %q", tt.bp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package receipts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Width is the number of columns of a text receipt, as on an 80mm printer.
+const Width = 48
+
+type line struct {
+	text string
+	bold bool
+}
+
+// Text renders the receipt as fixed width plain text.
+func Text(r models.Receipt) string {
+	var b strings.Builder
+	for _, l := range layout(r) {
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// layout builds the lines shared by the text and PDF receipts.
+func layout(r models.Receipt) []line {
+	var lines []line
+	add := func(text string) { lines = append(lines, line{text: text}) }
+	bold := func(text string) { lines = append(lines, line{text: text, bold: true}) }
+	rule := func() { add(strings.Repeat("-", Width)) }
+
+	// Restaurant
+	bold(center(r.Restaurant.Name))
+	for _, s := range []string{r.Restaurant.Address, joinNonEmpty("  ", r.Restaurant.Phone, r.Restaurant.Email)} {
+		for _, part := range wrap(s, Width) {
+			add(center(part))
+		}
+	}
+	if r.Restaurant.TaxID != "" {
+		add(center("VAT No " + r.Restaurant.TaxID))
+	}
+	rule()
+
+	// Document
+	if r.Invoice != nil {
+		bold(columns("TAX INVOICE", r.Invoice.Number))
+		add(columns("Issued", formatTime(r.Invoice.IssuedAt)))
+	}
+	add(columns(fmt.Sprintf("Order #%d", r.Order.ID), formatTime(r.Order.CreatedAt)))
+	if r.Order.TableID != 0 {
+		add(columns(fmt.Sprintf("Table %d", r.Order.TableID), fmt.Sprintf("Guests %d", r.Order.Guests)))
+	}
+	if r.Invoice != nil {
+		add("Bill to: " + r.Invoice.CustomerName)
+		for _, part := range wrap(r.Invoice.CustomerAddress, Width-2) {
+			add("  " + part)
+		}
+		if r.Invoice.CustomerTaxID != "" {
+			add("  VAT No " + r.Invoice.CustomerTaxID)
+		}
+	}
+	rule()
+
+	// Lines
+	for _, l := range r.Lines {
+		add(columns(fmt.Sprintf("%d x %s", l.Quantity, l.Name), Money(l.Total)))
+		if l.Quantity > 1 {
+			add("    @ " + Money(l.UnitPrice))
+		}
+		for _, c := range l.Components {
+			add("    - " + c)
+		}
+	}
+	rule()
+
+	// Totals
+	o := r.Order
+	add(columns("Subtotal", Money(o.Subtotal)))
+	for _, d := range o.Discounts {
+		name := d.Name
+		if d.Code != "" {
+			name += " (" + d.Code + ")"
+		}
+		add(columns(name, Money(-d.Amount)))
+	}
+	if o.ServiceCharge != 0 {
+		add(columns("Service charge", Money(o.ServiceCharge)))
+	}
+	for _, t := range o.Taxes {
+		label := fmt.Sprintf("%s %s on %s", t.Name, Percent(t.Rate), Money(t.Taxable))
+		if o.TaxInclusive {
+			label = "incl. " + label
+		}
+		add(columns(label, Money(t.Amount)))
+	}
+	if o.Tip != 0 {
+		add(columns("Tip", Money(o.Tip)))
+	}
+	bold(columns("TOTAL "+r.Currency, Money(o.TotalPrice)))
+	rule()
+
+	// Money in and out
+	for _, p := range r.Payments {
+		add(columns(fmt.Sprintf("Paid by %s %s", p.Provider, formatTime(p.CreatedAt)), Money(p.Captured)))
+	}
+	for _, f := range r.Refunds {
+		add(columns(fmt.Sprintf("Refund (%s, %s)", f.Method, strings.ReplaceAll(string(f.Reason), "_", " ")), Money(-f.Amount)))
+	}
+	bold(columns("Balance due", Money(r.Balance)))
+	rule()
+
+	if o.TaxInclusive {
+		add(center("Prices include VAT"))
+	}
+	add(center("Thank you for dining with us!"))
+	add(center("Printed " + r.PrintedAt.Format("2006-01-02 15:04")))
+	return lines
+}
+
+// columns puts left and right on one line, shortening left to fit.
+func columns(left, right string) string {
+	space := Width - utf8.RuneCountInString(right) - 1
+	left = truncate(left, space)
+	return left + strings.Repeat(" ", Width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right)) + right
+}
+
+func center(s string) string {
+	s = truncate(s, Width)
+	return strings.Repeat(" ", (Width-utf8.RuneCountInString(s))/2) + s
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "~"
+}
+
+// wrap breaks s into lines of at most width runes at spaces.
+func wrap(s string, width int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(s) {
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	for i, l := range lines {
+		lines[i] = truncate(l, width)
+	}
+	return lines
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// formatTime shortens a stored timestamp for printing.
+func formatTime(s string) string {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.Format("2006-01-02 15:04")
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/models"
 )
@@ -11,6 +12,30 @@ type Repository interface {
 	OrderRepository
 	MenuItemRepository
 	TableRepository
+	BundleRepository
+	MenuPriceRepository
+	PromotionRepository
+	TaxRepository
+	PaymentRepository
+	SplitRepository
+	RefundRepository
+	InvoiceRepository
+	KitchenRepository
+	WebhookRepository
+
+	// Close releases the database. The repository cannot be used after.
+	Close() error
+	// Ping checks that the database answers.
+	Ping(ctx context.Context) error
+	// CheckMigrations fails when the database lacks migrations this build
+	// has, naming them.
+	CheckMigrations(ctx context.Context) error
+}
+
+// PoolStatsReporter is implemented by repositories that keep a connection
+// pool of their own.
+type PoolStatsReporter interface {
+	PoolStats() models.PoolStats
 }
 
 // UserRepository defines user-related database operations.
@@ -19,6 +44,8 @@ type UserRepository interface {
 	GetUserById(ctx context.Context, id int) (models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (models.User, error)
 	UpdateUser(ctx context.Context, user models.User) error
+	// UpdateUserPassword replaces the password hash of a user.
+	UpdateUserPassword(ctx context.Context, id int, password string) error
 	DeleteUser(ctx context.Context, id int) error
 }
 
@@ -28,10 +55,17 @@ type OrderRepository interface {
 	GetOrderById(ctx context.Context, id int) (models.Order, error)
 	GetAllOrders(ctx context.Context) ([]models.Order, error)
 	GetOrdersByUser(ctx context.Context, userId int) ([]models.Order, error)
+	// ListOrders returns up to query.Limit orders matching the filters, in
+	// the query's order after query.After, and how many match in all.
+	ListOrders(ctx context.Context, query models.OrderListQuery) ([]models.Order, int, error)
 	UpdateOrder(ctx context.Context, order models.Order) error
 	DeleteOrder(ctx context.Context, id int) error
 	GetOrderByTableId(ctx context.Context, tableId int) (models.Order, error)
-	UpdateOrderStatusByTableId(ctx context.Context, tableId int, status models.OrderStatus) error
+	// UpdateOrderTotals stores the price breakdown of a pending order,
+	// failing with models.ErrPromotionLimitReached when a promotion ran out
+	// in the meantime.
+	UpdateOrderTotals(ctx context.Context, order models.Order) error
+	UpdateOrderStatus(ctx context.Context, id int, status models.OrderStatus) error
 }
 
 // MenuItemRepository defines menu item-related database operations.
@@ -40,8 +74,15 @@ type MenuItemRepository interface {
 	GetMenuItemById(ctx context.Context, id int) (models.MenuItem, error)
 	GetMenuItemsByCategory(ctx context.Context, category string) ([]models.MenuItem, error)
 	UpdateMenuItem(ctx context.Context, item models.MenuItem) error
+	UpdateMenuItemImages(ctx context.Context, id int, images models.MenuImages) error
 	DeleteMenuItem(ctx context.Context, id int) error
 	GetAllMenuItems(ctx context.Context) ([]models.MenuItem, error)
+	// ImportMenu applies the changes of a menu import in one transaction.
+	// Price changes go into the price history and stock changes are recorded
+	// as stock movements.
+	ImportMenu(ctx context.Context, changes []models.MenuImportChange) error
+	// SearchMenuItems returns one page of ranked hits and the total hit count.
+	SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) ([]models.MenuSearchHit, int, error)
 }
 
 // TableRepository defines table-related database operations.
@@ -52,5 +93,139 @@ type TableRepository interface {
 	DeleteTable(ctx context.Context, id int) error
 	GetAllTables(ctx context.Context) ([]models.Table, error)
 	GetTablesByStatus(ctx context.Context, status models.TableStatus) ([]models.Table, error)
-	ResetTableToAvailable(ctx context.Context, tableId int) error
+	// ResetTable completes the table's open orders and frees it, returning
+	// the completed order ids.
+	ResetTable(ctx context.Context, tableId int) ([]int, error)
+}
+
+// BundleRepository defines combo and set meal database operations.
+type BundleRepository interface {
+	CreateBundle(ctx context.Context, bundle models.Bundle) (int, error)
+	GetBundleById(ctx context.Context, id int) (models.Bundle, error)
+	GetAllBundles(ctx context.Context) ([]models.Bundle, error)
+}
+
+// MenuPriceRepository defines menu price history operations.
+type MenuPriceRepository interface {
+	CreateMenuItemPrice(ctx context.Context, price models.MenuItemPrice) (int, error)
+	GetMenuItemPrices(ctx context.Context, menuItemId int) ([]models.MenuItemPrice, error)
+	GetMenuItemPricesAt(ctx context.Context, menuItemIds []int, at time.Time) (map[int]int, error)
+	DeleteScheduledMenuItemPrice(ctx context.Context, menuItemId, priceId int, now time.Time) error
+}
+
+// PromotionRepository defines coupon and automatic promotion operations.
+type PromotionRepository interface {
+	CreatePromotion(ctx context.Context, promotion models.Promotion) (int, error)
+	GetPromotionById(ctx context.Context, id int) (models.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (models.Promotion, error)
+	GetAllPromotions(ctx context.Context) ([]models.Promotion, error)
+	GetAutomaticPromotions(ctx context.Context) ([]models.Promotion, error)
+	SetPromotionActive(ctx context.Context, id int, active bool) error
+	// GetPromotionUsage counts the orders each promotion was applied to,
+	// leaving out excludeOrderId.
+	GetPromotionUsage(ctx context.Context, promotionIds []int, userId, excludeOrderId int) (map[int]models.PromotionUsage, error)
+}
+
+// TaxRepository defines tax rate operations.
+type TaxRepository interface {
+	CreateTaxRate(ctx context.Context, rate models.TaxRate) (int, error)
+	GetTaxRates(ctx context.Context) ([]models.TaxRate, error)
+}
+
+// PaymentRepository defines payment and payment webhook operations.
+type PaymentRepository interface {
+	// CreatePayment records a payment, rechecking atomically that the order
+	// is pending and the amount fits what is left to pay with the payments
+	// recorded so far. It fails with models.ErrPaymentNotAllowed or
+	// models.ErrPaymentConflict otherwise.
+	CreatePayment(ctx context.Context, payment models.Payment) (int, error)
+	GetPaymentById(ctx context.Context, id int) (models.Payment, error)
+	GetPaymentsByOrder(ctx context.Context, orderId int) ([]models.Payment, error)
+	GetPaymentByIdempotencyKey(ctx context.Context, orderId int, key string) (models.Payment, error)
+	GetPaymentByReference(ctx context.Context, provider, reference string) (models.Payment, error)
+	// UpdatePayment saves a payment that was read with status from, failing
+	// with models.ErrPaymentConflict if it changed in the meantime.
+	UpdatePayment(ctx context.Context, payment models.Payment, from models.PaymentStatus) error
+	// RecordWebhookEvent stores a delivery once and reports whether the
+	// event was already processed.
+	RecordWebhookEvent(ctx context.Context, event models.PaymentWebhookEvent) (bool, error)
+	MarkWebhookEventProcessed(ctx context.Context, provider, eventId string) error
+}
+
+// SplitRepository defines split bill operations.
+type SplitRepository interface {
+	// CreateOrderChecks replaces the checks of a pending order without
+	// payments and stores its tip and total along with them.
+	CreateOrderChecks(ctx context.Context, order models.Order, checks []models.Check) error
+	GetOrderChecks(ctx context.Context, orderId int) ([]models.Check, error)
+	// DeleteOrderChecks merges the checks of a pending order without
+	// payments back into it, storing the sum of their tips as its tip.
+	DeleteOrderChecks(ctx context.Context, orderId int) error
+}
+
+// RefundRepository defines refund, stock movement and report operations.
+type RefundRepository interface {
+	CreateRefund(ctx context.Context, refund models.Refund) (int, error)
+	GetRefundById(ctx context.Context, id int) (models.Refund, error)
+	GetRefundsByOrder(ctx context.Context, orderId int) ([]models.Refund, error)
+	// GetRefunds lists refunds with the given status, or all of them.
+	GetRefunds(ctx context.Context, status models.RefundStatus) ([]models.Refund, error)
+	// UpdateRefundStatus saves the status, approver and failure reason of a
+	// refund that was read with status from.
+	UpdateRefundStatus(ctx context.Context, refund models.Refund, from models.RefundStatus) error
+	// CompleteRefund records a processed refund on its order and restocks
+	// what it returned.
+	CompleteRefund(ctx context.Context, refund models.Refund, movements []models.StockMovement) error
+	GetSalesReport(ctx context.Context, from, to time.Time) (models.SalesReport, error)
+}
+
+// InvoiceRepository defines invoice operations.
+type InvoiceRepository interface {
+	// CreateInvoice gives the order the next invoice number of the invoice's
+	// fiscal year, or returns the invoice the order already has.
+	CreateInvoice(ctx context.Context, invoice models.Invoice) (models.Invoice, error)
+	GetInvoiceByOrder(ctx context.Context, orderId int) (models.Invoice, error)
+}
+
+// KitchenRepository defines kitchen station and ticket operations.
+type KitchenRepository interface {
+	CreateStation(ctx context.Context, station models.Station) (int, error)
+	GetStations(ctx context.Context) ([]models.Station, error)
+	GetStationById(ctx context.Context, id int) (models.Station, error)
+	// SetCategoryStation routes a menu category to a station; zero removes
+	// the route.
+	SetCategoryStation(ctx context.Context, category string, stationId int) error
+	// SetMenuItemStation routes one menu item to a station; zero routes it by
+	// its category again.
+	SetMenuItemStation(ctx context.Context, itemId, stationId int) error
+	GetStationRouting(ctx context.Context) (models.StationRouting, error)
+
+	// CreateKitchenTickets stores the tickets of an order unless it already
+	// has some, and queues the order in the kitchen.
+	CreateKitchenTickets(ctx context.Context, orderId int, tickets []models.KitchenTicket) error
+	GetKitchenTicketById(ctx context.Context, id int) (models.KitchenTicket, error)
+	GetKitchenTicketsByOrder(ctx context.Context, orderId int) ([]models.KitchenTicket, error)
+	GetStationTickets(ctx context.Context, stationId int, status models.TicketStatus, limit int) ([]models.KitchenTicket, error)
+	// SetTicketStatus and SetTicketItemStatus bump or recall items and roll
+	// their readiness up to the ticket and the order's kitchen status,
+	// reporting whether that changed. The item's ticket id is returned.
+	SetTicketStatus(ctx context.Context, ticketId int, status models.TicketStatus) (bool, error)
+	SetTicketItemStatus(ctx context.Context, itemId int, status models.TicketItemStatus) (int, bool, error)
+}
+
+// WebhookRepository defines outbox and webhook delivery operations. Outbox
+// events are written by the other operations in their own transactions.
+type WebhookRepository interface {
+	CreateWebhookEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (int, error)
+	GetWebhookEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error)
+	GetWebhookEndpointById(ctx context.Context, id int) (models.WebhookEndpoint, error)
+	SetWebhookEndpointActive(ctx context.Context, id int, active bool) error
+	GetWebhookDeliveries(ctx context.Context, endpointId int, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, deliveryId int64) error
+
+	// DispatchOutbox turns undispatched outbox events into deliveries.
+	DispatchOutbox(ctx context.Context, limit int) (int, error)
+	// ClaimWebhookDeliveries leases due deliveries to the caller.
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, deliveryId int64, attempt models.WebhookAttempt, status models.WebhookDeliveryStatus, retryIn time.Duration) error
 }
@@ -0,0 +1,73 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func copyBundle(b models.Bundle) models.Bundle {
+	b.Slots = slices.Clone(b.Slots)
+	if b.Slots == nil {
+		b.Slots = []models.BundleSlot{}
+	}
+	for i := range b.Slots {
+		b.Slots[i].MenuItemIDs = slices.Clone(b.Slots[i].MenuItemIDs)
+	}
+	return b
+}
+
+// Bundle operations
+func (r *repository) CreateBundle(ctx context.Context, bundle models.Bundle) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range r.bundles {
+		if b.Name == bundle.Name {
+			return 0, fmt.Errorf("failed to create bundle: bundle %q already exists", bundle.Name)
+		}
+	}
+	for _, slot := range bundle.Slots {
+		for _, itemID := range slot.MenuItemIDs {
+			if _, ok := r.menuItems[itemID]; !ok {
+				return 0, fmt.Errorf("failed to add item to bundle slot: menu item %d does not exist", itemID)
+			}
+		}
+	}
+
+	bundle = copyBundle(bundle)
+	bundle.ID = r.nextID("bundles")
+	for i := range bundle.Slots {
+		bundle.Slots[i].ID = r.nextID("bundle_slots")
+		ids := slices.Sorted(slices.Values(bundle.Slots[i].MenuItemIDs))
+		bundle.Slots[i].MenuItemIDs = slices.Compact(ids)
+	}
+	bundle.CreatedAt = timestamp(now())
+	bundle.UpdatedAt = bundle.CreatedAt
+	r.bundles[bundle.ID] = bundle
+	return bundle.ID, nil
+}
+
+func (r *repository) GetBundleById(ctx context.Context, id int) (models.Bundle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.bundles[id]
+	if !ok {
+		return models.Bundle{}, fmt.Errorf("bundle not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return copyBundle(b), nil
+}
+
+func (r *repository) GetAllBundles(ctx context.Context) ([]models.Bundle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bundles := make([]models.Bundle, 0)
+	for _, id := range sortedIDs(r.bundles) {
+		bundles = append(bundles, copyBundle(r.bundles[id]))
+	}
+	return bundles, nil
+}
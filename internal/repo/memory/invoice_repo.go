@@ -0,0 +1,43 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Invoice operations
+
+// CreateInvoice issues the next invoice number of the invoice's fiscal year
+// to its order. An order that already has an invoice gets the existing one.
+func (r *repository) CreateInvoice(ctx context.Context, invoice models.Invoice) (models.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.invoices[invoice.OrderID]; ok {
+		return existing, nil
+	}
+	if _, ok := r.orders[invoice.OrderID]; !ok {
+		return models.Invoice{}, fmt.Errorf("failed to create invoice: order %d does not exist", invoice.OrderID)
+	}
+
+	r.invoiceSeqs[invoice.FiscalYear]++
+	invoice.Sequence = r.invoiceSeqs[invoice.FiscalYear]
+	invoice.Number = models.InvoiceNumber(invoice.FiscalYear, invoice.Sequence)
+	invoice.ID = r.nextID("invoices")
+	invoice.IssuedAt = timestamp(now())
+	r.invoices[invoice.OrderID] = invoice
+	return invoice, nil
+}
+
+func (r *repository) GetInvoiceByOrder(ctx context.Context, orderId int) (models.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, ok := r.invoices[orderId]
+	if !ok {
+		return models.Invoice{}, fmt.Errorf("invoice not found for order %d: %w", orderId, models.ErrNotFound)
+	}
+	return invoice, nil
+}
@@ -0,0 +1,429 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type ticketRow struct {
+	id        int
+	orderID   int
+	stationID int
+	status    models.TicketStatus
+	createdAt time.Time
+	bumpedAt  time.Time
+}
+
+type ticketItemRow struct {
+	id          int
+	ticketID    int
+	orderItemID int
+	menuItemID  int
+	bundleID    *int
+	quantity    int
+	status      models.TicketItemStatus
+	bumpedAt    time.Time
+}
+
+// Station operations
+func (r *repository) CreateStation(ctx context.Context, station models.Station) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.stations {
+		if s.Name == station.Name {
+			return 0, fmt.Errorf("%w: station %q already exists", models.ErrInvalidInput, station.Name)
+		}
+	}
+	// Only one station can be the default
+	if station.IsDefault {
+		for id, s := range r.stations {
+			s.IsDefault = false
+			r.stations[id] = s
+		}
+	}
+	station.ID = r.nextID("kitchen_stations")
+	station.Categories = nil
+	station.CreatedAt = timestamp(now())
+	r.stations[station.ID] = station
+	return station.ID, nil
+}
+
+func (r *repository) station(id int) models.Station {
+	s := r.stations[id]
+	s.Categories = []string{}
+	for _, category := range slices.Sorted(maps.Keys(r.stationRoutes)) {
+		if r.stationRoutes[category] == id {
+			s.Categories = append(s.Categories, category)
+		}
+	}
+	return s
+}
+
+func (r *repository) GetStations(ctx context.Context) ([]models.Station, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stations := make([]models.Station, 0)
+	for _, id := range sortedIDs(r.stations) {
+		stations = append(stations, r.station(id))
+	}
+	return stations, nil
+}
+
+func (r *repository) GetStationById(ctx context.Context, id int) (models.Station, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.stations[id]; !ok {
+		return models.Station{}, fmt.Errorf("station not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return r.station(id), nil
+}
+
+// SetCategoryStation routes a menu category to a station, or removes its
+// route when stationId is zero.
+func (r *repository) SetCategoryStation(ctx context.Context, category string, stationId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category = strings.ToLower(category)
+	if stationId == 0 {
+		delete(r.stationRoutes, category)
+		return nil
+	}
+	if _, ok := r.stations[stationId]; !ok {
+		return fmt.Errorf("failed to route category: station %d does not exist", stationId)
+	}
+	r.stationRoutes[category] = stationId
+	return nil
+}
+
+// SetMenuItemStation routes one menu item to a station, or back to its
+// category's when stationId is zero.
+func (r *repository) SetMenuItemStation(ctx context.Context, itemId, stationId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.menuItems[itemId]
+	if !ok {
+		return fmt.Errorf("menu item not found with id %d: %w", itemId, models.ErrNotFound)
+	}
+	if _, ok := r.stations[stationId]; stationId != 0 && !ok {
+		return fmt.Errorf("failed to route menu item: station %d does not exist", stationId)
+	}
+	row.stationID = stationId
+	return nil
+}
+
+func (r *repository) GetStationRouting(ctx context.Context) (models.StationRouting, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	routing := models.StationRouting{
+		Categories: maps.Clone(r.stationRoutes),
+		Items:      make(map[int]int),
+	}
+	for id, s := range r.stations {
+		if s.IsDefault {
+			routing.DefaultStationID = id
+		}
+	}
+	if routing.DefaultStationID == 0 {
+		return models.StationRouting{}, fmt.Errorf("no default kitchen station: %w", models.ErrNotFound)
+	}
+	for id, row := range r.menuItems {
+		if row.stationID != 0 {
+			routing.Items[id] = row.stationID
+		}
+	}
+	return routing, nil
+}
+
+// Kitchen ticket operations
+
+// CreateKitchenTickets stores the tickets of an order and queues it in the
+// kitchen. An order that already has tickets keeps them.
+func (r *repository) CreateKitchenTickets(ctx context.Context, orderId int, tickets []models.KitchenTicket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[orderId]
+	if !ok {
+		return fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	if order.order.KitchenStatus != "" {
+		return nil
+	}
+	stations := make(map[int]bool, len(tickets))
+	for _, ticket := range tickets {
+		if _, ok := r.stations[ticket.StationID]; !ok || stations[ticket.StationID] {
+			return fmt.Errorf("failed to create kitchen ticket: no station %d or two tickets for it", ticket.StationID)
+		}
+		stations[ticket.StationID] = true
+		for _, item := range ticket.Items {
+			if r.orderItems[item.OrderItemID] != orderId {
+				return fmt.Errorf("failed to insert kitchen ticket item: order item %d is not part of order %d", item.OrderItemID, orderId)
+			}
+			if _, ok := r.menuItems[item.MenuItemID]; !ok {
+				return fmt.Errorf("failed to insert kitchen ticket item: menu item %d does not exist", item.MenuItemID)
+			}
+		}
+	}
+
+	created := now()
+	for _, ticket := range tickets {
+		t := &ticketRow{
+			id:        r.nextID("kitchen_tickets"),
+			orderID:   orderId,
+			stationID: ticket.StationID,
+			status:    models.TicketStatusOpen,
+			createdAt: created,
+		}
+		r.tickets[t.id] = t
+		for _, item := range ticket.Items {
+			i := &ticketItemRow{
+				id:          r.nextID("kitchen_ticket_items"),
+				ticketID:    t.id,
+				orderItemID: item.OrderItemID,
+				menuItemID:  item.MenuItemID,
+				quantity:    item.Quantity,
+				status:      models.TicketItemPending,
+			}
+			if item.BundleID != nil {
+				bundleID := *item.BundleID
+				i.bundleID = &bundleID
+			}
+			r.ticketItems[i.id] = i
+		}
+	}
+
+	order.order.KitchenStatus = models.KitchenStatusQueued
+	order.touch()
+	return nil
+}
+
+// orderItem finds an order item by its id.
+func (r *repository) orderItem(id int) (models.OrderItem, bool) {
+	row, ok := r.orders[r.orderItems[id]]
+	if !ok {
+		return models.OrderItem{}, false
+	}
+	for _, item := range row.order.Items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return models.OrderItem{}, false
+}
+
+// kitchenTicket builds a ticket as it is read, with the station and table
+// names and its items.
+func (r *repository) kitchenTicket(t *ticketRow) models.KitchenTicket {
+	order := r.orders[t.orderID].order
+	ticket := models.KitchenTicket{
+		ID:        t.id,
+		OrderID:   t.orderID,
+		StationID: t.stationID,
+		Station:   r.stations[t.stationID].Name,
+		Status:    t.status,
+		Table:     "Takeaway",
+		Guests:    order.Guests,
+		Items:     []models.TicketItem{},
+		OrderedAt: order.CreatedAt,
+		CreatedAt: timestamp(t.createdAt),
+		BumpedAt:  timestamp(t.bumpedAt),
+	}
+	if table, ok := r.tables[order.TableID]; ok {
+		ticket.Table = table.Name
+	}
+
+	for _, id := range sortedIDs(r.ticketItems) {
+		i := r.ticketItems[id]
+		if i.ticketID != t.id {
+			continue
+		}
+		item := models.TicketItem{
+			ID:          i.id,
+			OrderItemID: i.orderItemID,
+			MenuItemID:  i.menuItemID,
+			Name:        r.menuItems[i.menuItemID].item.Name,
+			Quantity:    i.quantity,
+			Status:      i.status,
+			BumpedAt:    timestamp(i.bumpedAt),
+		}
+		if i.bundleID != nil {
+			bundleID := *i.bundleID
+			item.BundleID = &bundleID
+			item.Modifiers = []string{"part of " + r.bundles[bundleID].Name}
+		}
+		if orderItem, ok := r.orderItem(i.orderItemID); ok {
+			item.Note = orderItem.Note
+		}
+		ticket.Items = append(ticket.Items, item)
+	}
+	return ticket
+}
+
+// ticketsWhere lists the matching tickets in id order.
+func (r *repository) ticketsWhere(match func(t *ticketRow) bool) []*ticketRow {
+	var tickets []*ticketRow
+	for _, id := range sortedIDs(r.tickets) {
+		if t := r.tickets[id]; match(t) {
+			tickets = append(tickets, t)
+		}
+	}
+	return tickets
+}
+
+func (r *repository) GetKitchenTicketById(ctx context.Context, id int) (models.KitchenTicket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tickets[id]
+	if !ok {
+		return models.KitchenTicket{}, fmt.Errorf("kitchen ticket not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return r.kitchenTicket(t), nil
+}
+
+func (r *repository) GetKitchenTicketsByOrder(ctx context.Context, orderId int) ([]models.KitchenTicket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rows := r.ticketsWhere(func(t *ticketRow) bool { return t.orderID == orderId })
+	slices.SortStableFunc(rows, func(a, b *ticketRow) int { return cmp.Compare(a.stationID, b.stationID) })
+	tickets := make([]models.KitchenTicket, 0, len(rows))
+	for _, t := range rows {
+		tickets = append(tickets, r.kitchenTicket(t))
+	}
+	return tickets, nil
+}
+
+// GetStationTickets lists a station's open tickets oldest first, or its done
+// tickets most recently bumped first. Tickets of cancelled orders are left
+// out.
+func (r *repository) GetStationTickets(ctx context.Context, stationId int, status models.TicketStatus, limit int) ([]models.KitchenTicket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rows := r.ticketsWhere(func(t *ticketRow) bool {
+		return t.stationID == stationId && t.status == status && r.orders[t.orderID].order.Status != models.OrderStatusCancelled
+	})
+	if status == models.TicketStatusDone {
+		slices.SortFunc(rows, func(a, b *ticketRow) int {
+			return cmp.Or(b.bumpedAt.Compare(a.bumpedAt), cmp.Compare(b.id, a.id))
+		})
+	} else {
+		slices.SortFunc(rows, func(a, b *ticketRow) int {
+			return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.id, b.id))
+		})
+	}
+	tickets := make([]models.KitchenTicket, 0, min(len(rows), max(limit, 0)))
+	for _, t := range rows {
+		if len(tickets) >= limit {
+			break
+		}
+		tickets = append(tickets, r.kitchenTicket(t))
+	}
+	return tickets, nil
+}
+
+// SetTicketStatus bumps every item of a ticket, or recalls them all.
+func (r *repository) SetTicketStatus(ctx context.Context, ticketId int, status models.TicketStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	itemStatus := models.TicketItemPending
+	if status == models.TicketStatusDone {
+		itemStatus = models.TicketItemReady
+	}
+	return r.updateTicketItems(ticketId, func(i *ticketItemRow) bool { return i.ticketID == ticketId }, itemStatus)
+}
+
+// SetTicketItemStatus bumps or recalls one item of a ticket.
+// It returns the id of the item's ticket.
+func (r *repository) SetTicketItemStatus(ctx context.Context, itemId int, status models.TicketItemStatus) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.ticketItems[itemId]
+	if !ok {
+		return 0, false, fmt.Errorf("kitchen ticket item not found with id %d: %w", itemId, models.ErrNotFound)
+	}
+	changed, err := r.updateTicketItems(item.ticketID, func(i *ticketItemRow) bool { return i.id == itemId }, status)
+	return item.ticketID, changed, err
+}
+
+// updateTicketItems sets the status of the matching items of a ticket and
+// rolls it up to the ticket and the order. It reports whether the order's
+// kitchen status changed.
+func (r *repository) updateTicketItems(ticketId int, match func(i *ticketItemRow) bool, status models.TicketItemStatus) (bool, error) {
+	ticket, ok := r.tickets[ticketId]
+	if !ok {
+		return false, fmt.Errorf("kitchen ticket not found with id %d: %w", ticketId, models.ErrNotFound)
+	}
+	bumped := now()
+
+	for _, i := range r.ticketItems {
+		if !match(i) {
+			continue
+		}
+		i.status = status
+		switch {
+		case status != models.TicketItemReady:
+			i.bumpedAt = time.Time{}
+		case i.bumpedAt.IsZero():
+			i.bumpedAt = bumped
+		}
+	}
+
+	pending := 0
+	for _, i := range r.ticketItems {
+		if i.ticketID == ticketId && i.status == models.TicketItemPending {
+			pending++
+		}
+	}
+	switch {
+	case pending > 0:
+		ticket.status = models.TicketStatusOpen
+		ticket.bumpedAt = time.Time{}
+	case ticket.status != models.TicketStatusDone:
+		ticket.status = models.TicketStatusDone
+		ticket.bumpedAt = bumped
+	}
+
+	var orderPending, orderReady int
+	for _, i := range r.ticketItems {
+		if r.tickets[i.ticketID].orderID != ticket.orderID {
+			continue
+		}
+		switch i.status {
+		case models.TicketItemPending:
+			orderPending++
+		case models.TicketItemReady:
+			orderReady++
+		}
+	}
+	kitchenStatus := models.KitchenStatusQueued
+	if orderPending == 0 {
+		kitchenStatus = models.KitchenStatusReady
+	} else if orderReady > 0 {
+		kitchenStatus = models.KitchenStatusPreparing
+	}
+
+	order := r.orders[ticket.orderID]
+	if order.order.KitchenStatus == kitchenStatus {
+		return false, nil
+	}
+	order.order.KitchenStatus = kitchenStatus
+	order.touch()
+	return true, r.writeOutbox(models.OutboxOrderKitchenStatusChanged, order.change())
+}
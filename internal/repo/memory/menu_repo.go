@@ -0,0 +1,223 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// menuItemRow is a menu item as stored: the base price, the images as
+// uploaded and the station it is routed to.
+type menuItemRow struct {
+	item      models.MenuItem
+	createdAt time.Time
+	stationID int
+}
+
+// menuItem builds the item as it is read: the price in effect right now,
+// missing image sizes filled in from larger ones and empty dietary info as
+// empty lists.
+func (r *repository) menuItem(row *menuItemRow) models.MenuItem {
+	item := row.item
+	if price, ok := r.priceAt(item.ID, now()); ok {
+		item.Price = price
+	}
+	item.Images.Medium = firstNonEmpty(row.item.Images.Medium, row.item.Images.Large)
+	item.Images.Thumbnail = firstNonEmpty(row.item.Images.Thumbnail, row.item.Images.Medium, row.item.Images.Large)
+
+	item.Allergens = slices.Sorted(slices.Values(row.item.Allergens))
+	if item.Allergens == nil {
+		item.Allergens = []models.Allergen{}
+	}
+	item.DietaryTags = slices.Sorted(slices.Values(row.item.DietaryTags))
+	if item.DietaryTags == nil {
+		item.DietaryTags = []models.DietaryTag{}
+	}
+	if row.item.Nutrition != nil && !row.item.Nutrition.IsEmpty() {
+		nutrition := *row.item.Nutrition
+		item.Nutrition = &nutrition
+	} else {
+		item.Nutrition = nil
+	}
+	if row.item.Stock != nil {
+		stock := *row.item.Stock
+		item.Stock = &stock
+	}
+	return item
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// menuItemsWhere lists the menu items that match in id order.
+func (r *repository) menuItemsWhere(match func(row *menuItemRow) bool) []models.MenuItem {
+	items := make([]models.MenuItem, 0)
+	for _, id := range sortedIDs(r.menuItems) {
+		if row := r.menuItems[id]; match(row) {
+			items = append(items, r.menuItem(row))
+		}
+	}
+	return items
+}
+
+// Menu operations
+func (r *repository) CreateMenuItem(ctx context.Context, item models.MenuItem) (int, error) {
+	return 0, fmt.Errorf("not implemented")
+}
+
+func (r *repository) GetMenuItemById(ctx context.Context, id int) (models.MenuItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.menuItems[id]
+	if !ok {
+		return models.MenuItem{}, fmt.Errorf("menu item not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return r.menuItem(row), nil
+}
+
+func (r *repository) GetMenuItemsByCategory(ctx context.Context, category string) ([]models.MenuItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	menuItems := r.menuItemsWhere(func(row *menuItemRow) bool { return row.item.Category == category })
+	if len(menuItems) == 0 {
+		return nil, fmt.Errorf("no menu items found")
+	}
+	return menuItems, nil
+}
+
+func (r *repository) UpdateMenuItem(ctx context.Context, item models.MenuItem) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) UpdateMenuItemImages(ctx context.Context, id int, images models.MenuImages) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.menuItems[id]
+	if !ok {
+		return fmt.Errorf("menu item not found with id %d: %w", id, models.ErrNotFound)
+	}
+	row.item.Images = images
+	return nil
+}
+
+func (r *repository) DeleteMenuItem(ctx context.Context, id int) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) GetAllMenuItems(ctx context.Context) ([]models.MenuItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	menuItems := r.menuItemsWhere(func(*menuItemRow) bool { return true })
+	if len(menuItems) == 0 {
+		return nil, fmt.Errorf("no menu items found: %w", models.ErrIsEmpty)
+	}
+	return menuItems, nil
+}
+
+// ImportMenu checks every change before it applies any, so a failing row
+// leaves the menu as it was.
+func (r *repository) ImportMenu(ctx context.Context, changes []models.MenuImportChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	skus := make(map[string]bool, len(r.menuItems))
+	for _, row := range r.menuItems {
+		skus[row.item.SKU] = true
+	}
+	for _, change := range changes {
+		switch change.Action {
+		case models.MenuImportCreate:
+			if skus[change.Item.SKU] {
+				return fmt.Errorf("failed to import menu item %s: another menu item has the SKU", change.SKU)
+			}
+			skus[change.Item.SKU] = true
+		case models.MenuImportUpdate, models.MenuImportArchive:
+			if _, ok := r.menuItems[change.Item.ID]; !ok {
+				return fmt.Errorf("failed to import menu item %s: menu item not found with id %d: %w", change.SKU, change.Item.ID, models.ErrNotFound)
+			}
+		default:
+			return fmt.Errorf("failed to import menu item %s: unknown menu import action %q", change.SKU, change.Action)
+		}
+	}
+
+	at := now()
+	for _, change := range changes {
+		item := change.Item
+		switch change.Action {
+		case models.MenuImportCreate:
+			item.ID = r.nextID("menu_items")
+			item.Images = models.MenuImages{}
+			item.Nutrition = nil
+			item.Stock = copyStock(item.Stock)
+			item.Allergens = slices.Clone(item.Allergens)
+			item.DietaryTags = slices.Clone(item.DietaryTags)
+			r.menuItems[item.ID] = &menuItemRow{item: item, createdAt: at}
+			r.importPrice(item, at)
+
+		case models.MenuImportUpdate, models.MenuImportArchive:
+			row := r.menuItems[item.ID]
+			// Stock is only written when the file changes it. Counting
+			// stock is a movement like any other; starting or stopping to
+			// track it is not
+			if change.Changes("stock") {
+				if row.item.Stock != nil && item.Stock != nil && *item.Stock != *row.item.Stock {
+					r.movements = append(r.movements, models.StockMovement{
+						ID:         r.nextID("stock_movements"),
+						MenuItemID: item.ID,
+						Quantity:   *item.Stock - *row.item.Stock,
+						Reason:     "import",
+						CreatedAt:  timestamp(at),
+					})
+				}
+				row.item.Stock = copyStock(item.Stock)
+			}
+			row.item.Name = item.Name
+			row.item.Description = item.Description
+			row.item.Category = item.Category
+			row.item.SpicyLevel = item.SpicyLevel
+			row.item.Available = item.Available
+			if change.Changes("price") {
+				r.importPrice(item, at)
+			}
+			if change.Changes("allergens") || change.Changes("dietaryTags") {
+				row.item.Allergens = slices.Clone(item.Allergens)
+				row.item.DietaryTags = slices.Clone(item.DietaryTags)
+			}
+		}
+	}
+	return nil
+}
+
+// importPrice puts the imported price into effect right away.
+func (r *repository) importPrice(item models.MenuItem, at time.Time) {
+	price := models.MenuItemPrice{
+		ID:            r.nextID("menu_item_prices"),
+		MenuItemID:    item.ID,
+		Price:         item.Price,
+		EffectiveFrom: at,
+		Note:          "Menu import",
+		CreatedAt:     at,
+	}
+	r.prices[price.ID] = price
+}
+
+func copyStock(stock *int) *int {
+	if stock == nil {
+		return nil
+	}
+	v := *stock
+	return &v
+}
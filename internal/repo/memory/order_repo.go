@@ -0,0 +1,325 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// orderRow is an order with its items, discounts and taxes.
+type orderRow struct {
+	order     models.Order
+	createdAt time.Time
+}
+
+// open reports whether the order still occupies its table.
+func (o *orderRow) open() bool {
+	return o.order.Status == models.OrderStatusPending || o.order.Status == models.OrderStatusPaid
+}
+
+func (o *orderRow) touch() {
+	o.order.UpdatedAt = timestamp(now())
+}
+
+func (o *orderRow) change() models.OrderChange {
+	return models.OrderChange{
+		OrderID:       o.order.ID,
+		UserID:        o.order.UserID,
+		TableID:       o.order.TableID,
+		Status:        o.order.Status,
+		KitchenStatus: o.order.KitchenStatus,
+		TotalPrice:    o.order.TotalPrice,
+	}
+}
+
+// read copies the order as it reads back. Items are left nil when the
+// order has none; callers that list them as empty set that themselves.
+func (o *orderRow) read() models.Order {
+	order := o.order
+	order.UpdatedAt = ""
+	order.TipPercent = 0
+	order.Items = nil
+	for _, item := range o.order.Items {
+		order.Items = append(order.Items, copyOrderItem(item))
+	}
+	order.ItemsID = nil
+	order.Discounts = append([]models.OrderDiscount{}, o.order.Discounts...)
+	order.Taxes = append([]models.OrderTax{}, o.order.Taxes...)
+	return order
+}
+
+func copyOrderItem(item models.OrderItem) models.OrderItem {
+	if item.BundleID != nil {
+		bundleID := *item.BundleID
+		item.BundleID = &bundleID
+	}
+	item.Choices = nil
+	item.Components = slices.Clone(item.Components)
+	item.Taxes = slices.Clone(item.Taxes)
+	return item
+}
+
+// Order operations
+func (r *repository) CreateOrder(ctx context.Context, order models.Order) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[order.UserID]; !ok {
+		return 0, fmt.Errorf("failed to create order: user %d does not exist", order.UserID)
+	}
+	if _, ok := r.tables[order.TableID]; !ok {
+		return 0, fmt.Errorf("failed to create order: table %d does not exist", order.TableID)
+	}
+	seen := make(map[int]bool)
+	for _, item := range order.Items {
+		if item.IsBundle() {
+			if _, ok := r.bundles[*item.BundleID]; !ok {
+				return 0, fmt.Errorf("failed to insert order item: bundle %d does not exist", *item.BundleID)
+			}
+			continue
+		}
+		if _, ok := r.menuItems[item.MenuItemID]; !ok {
+			return 0, fmt.Errorf("failed to insert order item: menu item %d does not exist", item.MenuItemID)
+		}
+		if seen[item.MenuItemID] {
+			return 0, fmt.Errorf("failed to insert order item: menu item %d is listed twice", item.MenuItemID)
+		}
+		seen[item.MenuItemID] = true
+	}
+	if err := r.checkPromotionLimits(0, order.UserID, order.Discounts); err != nil {
+		return 0, err
+	}
+	used, err := r.checkStock(order)
+	if err != nil {
+		return 0, err
+	}
+
+	created := now()
+	order.ID = r.nextID("orders")
+	order.CreatedAt = timestamp(created)
+	order.UpdatedAt = order.CreatedAt
+	order.KitchenStatus = ""
+	order.RefundedTotal, order.CashRefunded = 0, 0
+	order.ItemsID = nil
+	order.Items = slices.Clone(order.Items)
+	for i := range order.Items {
+		order.Items[i] = copyOrderItem(order.Items[i])
+		order.Items[i].ID = r.nextID("order_items")
+		r.orderItems[order.Items[i].ID] = order.ID
+	}
+	order.Discounts = slices.Clone(order.Discounts)
+	order.Taxes = slices.Clone(order.Taxes)
+	row := &orderRow{order: order, createdAt: created}
+	r.orders[order.ID] = row
+	r.takeStock(used)
+
+	// The event carries the order as it was placed
+	event := row.order
+	event.CreatedAt, event.UpdatedAt = "", ""
+	if err := r.writeOutbox(models.OutboxOrderCreated, event); err != nil {
+		return 0, err
+	}
+	return order.ID, nil
+}
+
+// checkStock works out what the order takes off the menu items whose stock
+// is tracked, failing when there is not enough left.
+func (r *repository) checkStock(order models.Order) (map[int]int, error) {
+	used := make(map[int]int)
+	for _, item := range order.KitchenItems() {
+		used[item.MenuItemID] += item.Quantity
+	}
+	for _, id := range sortedIDs(used) {
+		row, ok := r.menuItems[id]
+		if !ok || row.item.Stock == nil {
+			delete(used, id) // stock is not tracked
+			continue
+		}
+		if left := *row.item.Stock; left < used[id] {
+			return nil, fmt.Errorf("%w: only %d left of menu item %d", models.ErrInvalidInput, left, id)
+		}
+	}
+	return used, nil
+}
+
+// takeStock takes what checkStock found off the stock and records it.
+func (r *repository) takeStock(used map[int]int) {
+	for _, id := range sortedIDs(used) {
+		left := *r.menuItems[id].item.Stock - used[id]
+		r.menuItems[id].item.Stock = &left
+		r.movements = append(r.movements, models.StockMovement{
+			ID:         r.nextID("stock_movements"),
+			MenuItemID: id,
+			Quantity:   -used[id],
+			Reason:     "sale",
+			CreatedAt:  timestamp(now()),
+		})
+	}
+}
+
+func (r *repository) GetOrderById(ctx context.Context, id int) (models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[id]
+	if !ok {
+		return models.Order{}, fmt.Errorf("order not found with id %d: %w", id, models.ErrNotFound)
+	}
+	order := row.read()
+	if order.Items == nil {
+		order.Items = []models.OrderItem{}
+	}
+	return order, nil
+}
+
+func (r *repository) GetAllOrders(ctx context.Context) ([]models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []models.Order
+	for _, id := range sortedIDs(r.orders) {
+		orders = append(orders, r.orders[id].read())
+	}
+	return orders, nil
+}
+
+func (r *repository) GetOrdersByUser(ctx context.Context, userId int) ([]models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []models.Order
+	for _, id := range sortedIDs(r.orders) {
+		if row := r.orders[id]; row.order.UserID == userId {
+			order := row.read()
+			if order.Items == nil {
+				order.Items = []models.OrderItem{}
+			}
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+// ListOrders filters and sorts every order, then skips to query.After.
+func (r *repository) ListOrders(ctx context.Context, query models.OrderListQuery) ([]models.Order, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rows []*orderRow
+	for _, row := range r.orders {
+		if query.Matches(row.order, row.createdAt) {
+			rows = append(rows, row)
+		}
+	}
+	compare := func(a, b *orderRow) int {
+		c := a.createdAt.Compare(b.createdAt)
+		if query.SortBy == models.OrderSortTotal {
+			c = cmp.Compare(a.order.TotalPrice, b.order.TotalPrice)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.order.ID, b.order.ID)
+		}
+		if query.Desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(rows, compare)
+
+	start := 0
+	if after := query.After; after != nil {
+		last := &orderRow{
+			order:     models.Order{ID: after.ID, TotalPrice: after.Total},
+			createdAt: after.CreatedAt,
+		}
+		for start < len(rows) && compare(rows[start], last) <= 0 {
+			start++
+		}
+	}
+	page := rows[start:]
+	if len(page) > query.Limit {
+		page = page[:query.Limit]
+	}
+
+	orders := make([]models.Order, len(page))
+	for i, row := range page {
+		orders[i] = row.read()
+		if orders[i].Items == nil {
+			orders[i].Items = []models.OrderItem{}
+		}
+	}
+	return orders, len(rows), nil
+}
+
+func (r *repository) GetOrderByTableId(ctx context.Context, tableId int) (models.Order, error) {
+	r.mu.Lock()
+	var orderID int
+	for _, id := range sortedIDs(r.orders) {
+		if row := r.orders[id]; row.order.TableID == tableId && row.open() {
+			orderID = id
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if orderID == 0 {
+		return models.Order{}, fmt.Errorf("no pending order found for table ID %d", tableId)
+	}
+	return r.GetOrderById(ctx, orderID)
+}
+
+// UpdateOrderTotals stores the recomputed price breakdown of a pending
+// order, replacing its discounts and taxes and the taxes of its lines.
+func (r *repository) UpdateOrderTotals(ctx context.Context, order models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[order.ID]
+	if !ok || row.order.Status != models.OrderStatusPending {
+		return fmt.Errorf("pending order not found with id %d: %w", order.ID, models.ErrNotFound)
+	}
+	if err := r.checkPromotionLimits(order.ID, order.UserID, order.Discounts); err != nil {
+		return err
+	}
+
+	o := &row.order
+	o.CouponCode, o.Subtotal, o.DiscountTotal = order.CouponCode, order.Subtotal, order.DiscountTotal
+	o.TaxInclusive, o.TaxTotal, o.Guests = order.TaxInclusive, order.TaxTotal, order.Guests
+	o.ServiceCharge, o.Tip, o.TotalPrice = order.ServiceCharge, order.Tip, order.TotalPrice
+	o.Discounts = slices.Clone(order.Discounts)
+	o.Taxes = slices.Clone(order.Taxes)
+	for _, item := range order.Items {
+		for i := range o.Items {
+			if o.Items[i].ID == item.ID {
+				o.Items[i].Taxes = slices.Clone(item.Taxes)
+			}
+		}
+	}
+	row.touch()
+	return nil
+}
+
+func (r *repository) UpdateOrderStatus(ctx context.Context, id int, status models.OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("order not found with id %d: %w", id, models.ErrNotFound)
+	}
+	row.order.Status = status
+	row.touch()
+	return r.writeOutbox(models.OutboxOrderStatusChanged, row.change())
+}
+
+func (r *repository) UpdateOrder(ctx context.Context, order models.Order) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) DeleteOrder(ctx context.Context, id int) error {
+	return fmt.Errorf("not implemented")
+}
@@ -0,0 +1,164 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type paymentEventKey struct {
+	provider, eventID string
+}
+
+type paymentEventRow struct {
+	event     models.PaymentWebhookEvent
+	processed bool
+}
+
+func copyPayment(p models.Payment) models.Payment {
+	if p.CheckID != nil {
+		checkID := *p.CheckID
+		p.CheckID = &checkID
+	}
+	return p
+}
+
+// paymentsWhere lists the payments that match in id order.
+func (r *repository) paymentsWhere(match func(p models.Payment) bool) []models.Payment {
+	payments := make([]models.Payment, 0)
+	for _, id := range sortedIDs(r.payments) {
+		if p := r.payments[id]; match(p) {
+			payments = append(payments, copyPayment(p))
+		}
+	}
+	return payments
+}
+
+func (r *repository) getPayment(match func(p models.Payment) bool) (models.Payment, error) {
+	payments := r.paymentsWhere(match)
+	if len(payments) == 0 {
+		return models.Payment{}, fmt.Errorf("payment not found: %w", models.ErrNotFound)
+	}
+	return payments[0], nil
+}
+
+// Payment operations
+
+// CreatePayment records a payment as long as it fits what is left to pay on
+// its order, or check, with the payments recorded so far.
+func (r *repository) CreatePayment(ctx context.Context, p models.Payment) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[p.OrderID]
+	if !ok {
+		return 0, fmt.Errorf("failed to create payment: order %d does not exist", p.OrderID)
+	}
+	var checks []models.Check
+	for _, c := range r.checks {
+		if c.OrderID == p.OrderID {
+			checks = append(checks, c)
+		}
+	}
+	payments := r.paymentsWhere(func(existing models.Payment) bool { return existing.OrderID == p.OrderID })
+	if err := row.order.CheckPaymentFits(p, checks, payments); err != nil {
+		return 0, err
+	}
+	for _, existing := range r.payments {
+		if p.Reference != "" && existing.Provider == p.Provider && existing.Reference == p.Reference {
+			return 0, fmt.Errorf("failed to create payment: reference %s is already recorded", p.Reference)
+		}
+		if p.IdempotencyKey != "" && existing.OrderID == p.OrderID && existing.IdempotencyKey == p.IdempotencyKey {
+			return 0, fmt.Errorf("failed to create payment: idempotency key %s is already used", p.IdempotencyKey)
+		}
+	}
+	p = copyPayment(p)
+	p.ID = r.nextID("payments")
+	p.CreatedAt = timestamp(now())
+	p.UpdatedAt = p.CreatedAt
+	r.payments[p.ID] = p
+	return p.ID, nil
+}
+
+func (r *repository) GetPaymentById(ctx context.Context, id int) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getPayment(func(p models.Payment) bool { return p.ID == id })
+}
+
+func (r *repository) GetPaymentsByOrder(ctx context.Context, orderId int) ([]models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.paymentsWhere(func(p models.Payment) bool { return p.OrderID == orderId }), nil
+}
+
+func (r *repository) GetPaymentByIdempotencyKey(ctx context.Context, orderId int, key string) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getPayment(func(p models.Payment) bool { return p.OrderID == orderId && key != "" && p.IdempotencyKey == key })
+}
+
+func (r *repository) GetPaymentByReference(ctx context.Context, provider, reference string) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getPayment(func(p models.Payment) bool {
+		return p.Provider == provider && reference != "" && p.Reference == reference
+	})
+}
+
+// UpdatePayment saves a payment that was read with status from. It fails with
+// models.ErrPaymentConflict when the payment changed in the meantime.
+func (r *repository) UpdatePayment(ctx context.Context, p models.Payment, from models.PaymentStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.payments[p.ID]
+	if !ok || current.Status != from {
+		return fmt.Errorf("%w: payment %d is no longer %s", models.ErrPaymentConflict, p.ID, from)
+	}
+	if p.Reference != "" {
+		for _, existing := range r.payments {
+			if existing.ID != p.ID && existing.Provider == current.Provider && existing.Reference == p.Reference {
+				return fmt.Errorf("failed to update payment: reference %s is already recorded", p.Reference)
+			}
+		}
+	}
+	current.Reference, current.Status, current.FailureReason = p.Reference, p.Status, p.FailureReason
+	current.Captured, current.Refunded = p.Captured, p.Refunded
+	current.UpdatedAt = timestamp(now())
+	r.payments[p.ID] = current
+	return nil
+}
+
+// RecordWebhookEvent stores a webhook delivery unless it was seen before, and
+// reports whether the event has already been processed.
+func (r *repository) RecordWebhookEvent(ctx context.Context, e models.PaymentWebhookEvent) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := paymentEventKey{e.Provider, e.EventID}
+	row, ok := r.paymentEvents[key]
+	if !ok {
+		e.ID = r.nextID("payment_webhook_events")
+		e.Payload = append([]byte(nil), e.Payload...)
+		e.ReceivedAt = now()
+		row = &paymentEventRow{event: e}
+		r.paymentEvents[key] = row
+	}
+	return row.processed, nil
+}
+
+func (r *repository) MarkWebhookEventProcessed(ctx context.Context, provider, eventId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.paymentEvents[paymentEventKey{provider, eventId}]; ok {
+		row.processed = true
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// priceAt is the price history entry in effect at the given moment, if any.
+func (r *repository) priceAt(menuItemId int, at time.Time) (int, bool) {
+	var current *models.MenuItemPrice
+	for _, p := range r.prices {
+		if p.MenuItemID != menuItemId || p.EffectiveFrom.After(at) {
+			continue
+		}
+		if current == nil || p.EffectiveFrom.After(current.EffectiveFrom) {
+			current = &p
+		}
+	}
+	if current == nil {
+		return 0, false
+	}
+	return current.Price, true
+}
+
+// Menu price history operations
+func (r *repository) CreateMenuItemPrice(ctx context.Context, price models.MenuItemPrice) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.menuItems[price.MenuItemID]; !ok {
+		return 0, fmt.Errorf("failed to create menu item price: menu item %d does not exist", price.MenuItemID)
+	}
+	effectiveFrom := price.EffectiveFrom.UTC().Truncate(time.Microsecond)
+	for _, p := range r.prices {
+		if p.MenuItemID == price.MenuItemID && p.EffectiveFrom.Equal(effectiveFrom) {
+			return 0, fmt.Errorf("failed to create menu item price: menu item %d already has a price from %s",
+				price.MenuItemID, price.EffectiveFrom.Format(time.RFC3339))
+		}
+	}
+	if price.CreatedBy != nil {
+		createdBy := *price.CreatedBy
+		price.CreatedBy = &createdBy
+	}
+	price.ID = r.nextID("menu_item_prices")
+	price.EffectiveFrom = effectiveFrom
+	price.CreatedAt = now()
+	price.Status = ""
+	r.prices[price.ID] = price
+	return price.ID, nil
+}
+
+func (r *repository) GetMenuItemPrices(ctx context.Context, menuItemId int) ([]models.MenuItemPrice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prices := make([]models.MenuItemPrice, 0)
+	for _, id := range sortedIDs(r.prices) {
+		if p := r.prices[id]; p.MenuItemID == menuItemId {
+			if p.CreatedBy != nil {
+				createdBy := *p.CreatedBy
+				p.CreatedBy = &createdBy
+			}
+			prices = append(prices, p)
+		}
+	}
+	slices.SortStableFunc(prices, func(a, b models.MenuItemPrice) int {
+		return a.EffectiveFrom.Compare(b.EffectiveFrom)
+	})
+	return prices, nil
+}
+
+// GetMenuItemPricesAt returns the price in effect at the given moment for
+// each of the menu items. Unknown and archived ids are left out of the
+// result, as they cannot be ordered.
+func (r *repository) GetMenuItemPricesAt(ctx context.Context, menuItemIds []int, at time.Time) (map[int]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prices := make(map[int]int, len(menuItemIds))
+	for _, id := range menuItemIds {
+		row, ok := r.menuItems[id]
+		if !ok || !row.item.Available {
+			continue
+		}
+		prices[id] = row.item.Price
+		if price, ok := r.priceAt(id, at); ok {
+			prices[id] = price
+		}
+	}
+	return prices, nil
+}
+
+// DeleteScheduledMenuItemPrice removes a price change that has not taken
+// effect by the given moment. Prices already in effect are history and stay.
+func (r *repository) DeleteScheduledMenuItemPrice(ctx context.Context, menuItemId, priceId int, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.prices[priceId]
+	if !ok || p.MenuItemID != menuItemId || !p.EffectiveFrom.After(now) {
+		return fmt.Errorf("scheduled price %d not found for menu item %d: %w", priceId, menuItemId, models.ErrNotFound)
+	}
+	delete(r.prices, priceId)
+	return nil
+}
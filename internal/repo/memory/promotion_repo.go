@@ -0,0 +1,162 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func copyPromotion(p models.Promotion) models.Promotion {
+	if p.StartsAt != nil {
+		startsAt := *p.StartsAt
+		p.StartsAt = &startsAt
+	}
+	if p.EndsAt != nil {
+		endsAt := *p.EndsAt
+		p.EndsAt = &endsAt
+	}
+	return p
+}
+
+// promotionsWhere lists the promotions that match in id order.
+func (r *repository) promotionsWhere(match func(p models.Promotion) bool) []models.Promotion {
+	promotions := make([]models.Promotion, 0)
+	for _, id := range sortedIDs(r.promotions) {
+		if p := r.promotions[id]; match(p) {
+			promotions = append(promotions, copyPromotion(p))
+		}
+	}
+	return promotions
+}
+
+// Promotion operations
+func (r *repository) CreatePromotion(ctx context.Context, p models.Promotion) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.Code != "" {
+		for _, existing := range r.promotions {
+			if existing.Code == p.Code {
+				return 0, fmt.Errorf("failed to create promotion: code %s is taken", p.Code)
+			}
+		}
+	}
+	p = copyPromotion(p)
+	if p.StartsAt != nil {
+		*p.StartsAt = p.StartsAt.UTC().Truncate(time.Microsecond)
+	}
+	if p.EndsAt != nil {
+		*p.EndsAt = p.EndsAt.UTC().Truncate(time.Microsecond)
+	}
+	p.ID = r.nextID("promotions")
+	p.CreatedAt = timestamp(now())
+	p.UpdatedAt = p.CreatedAt
+	r.promotions[p.ID] = p
+	return p.ID, nil
+}
+
+func (r *repository) GetPromotionById(ctx context.Context, id int) (models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.promotions[id]
+	if !ok {
+		return models.Promotion{}, fmt.Errorf("promotion not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return copyPromotion(p), nil
+}
+
+func (r *repository) GetPromotionByCode(ctx context.Context, code string) (models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	promotions := r.promotionsWhere(func(p models.Promotion) bool { return code != "" && p.Code == code })
+	if len(promotions) == 0 {
+		return models.Promotion{}, fmt.Errorf("%w: %s", models.ErrCouponNotFound, code)
+	}
+	return promotions[0], nil
+}
+
+func (r *repository) GetAllPromotions(ctx context.Context) ([]models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.promotionsWhere(func(models.Promotion) bool { return true }), nil
+}
+
+func (r *repository) GetAutomaticPromotions(ctx context.Context) ([]models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.promotionsWhere(func(p models.Promotion) bool { return p.Code == "" && p.Active }), nil
+}
+
+func (r *repository) SetPromotionActive(ctx context.Context, id int, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.promotions[id]
+	if !ok {
+		return fmt.Errorf("promotion not found with id %d: %w", id, models.ErrNotFound)
+	}
+	p.Active = active
+	p.UpdatedAt = timestamp(now())
+	r.promotions[id] = p
+	return nil
+}
+
+// promotionUsage counts orders, cancelled ones aside, the promotion was
+// applied to in total and for one user, ignoring the order being repriced.
+func (r *repository) promotionUsage(promotionId, userId, excludeOrderId int) models.PromotionUsage {
+	var usage models.PromotionUsage
+	for _, row := range r.orders {
+		if row.order.Status == models.OrderStatusCancelled || row.order.ID == excludeOrderId {
+			continue
+		}
+		for _, d := range row.order.Discounts {
+			if d.PromotionID != promotionId {
+				continue
+			}
+			usage.Total++
+			if row.order.UserID == userId {
+				usage.ForUser++
+			}
+		}
+	}
+	return usage
+}
+
+func (r *repository) GetPromotionUsage(ctx context.Context, promotionIds []int, userId, excludeOrderId int) (map[int]models.PromotionUsage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := make(map[int]models.PromotionUsage, len(promotionIds))
+	for _, id := range promotionIds {
+		if _, ok := r.promotions[id]; ok {
+			usage[id] = r.promotionUsage(id, userId, excludeOrderId)
+		}
+	}
+	return usage, nil
+}
+
+// checkPromotionLimits checks the usage limits of the promotions behind an
+// order's discounts again before they are stored.
+func (r *repository) checkPromotionLimits(orderId, userId int, discounts []models.OrderDiscount) error {
+	for _, d := range discounts {
+		p, ok := r.promotions[d.PromotionID]
+		if !ok {
+			return fmt.Errorf("failed to insert order discount: promotion %d does not exist", d.PromotionID)
+		}
+		usage := r.promotionUsage(p.ID, userId, orderId)
+		if (p.UsageLimit > 0 && usage.Total >= p.UsageLimit) || (p.PerUserLimit > 0 && usage.ForUser >= p.PerUserLimit) {
+			label := p.Code
+			if label == "" {
+				label = p.Name
+			}
+			return fmt.Errorf("%w: %s", models.ErrPromotionLimitReached, label)
+		}
+	}
+	return nil
+}
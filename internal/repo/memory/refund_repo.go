@@ -0,0 +1,211 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type refundRow struct {
+	refund      models.Refund
+	processedAt time.Time
+}
+
+func (row *refundRow) read() models.Refund {
+	refund := row.refund
+	if refund.PaymentID != nil {
+		paymentID := *refund.PaymentID
+		refund.PaymentID = &paymentID
+	}
+	refund.Items = append([]models.RefundItem{}, row.refund.Items...)
+	slices.SortStableFunc(refund.Items, func(a, b models.RefundItem) int {
+		return cmp.Compare(a.OrderItemID, b.OrderItemID)
+	})
+	refund.ProcessedAt = timestamp(row.processedAt)
+	return refund
+}
+
+// refundsWhere lists the refunds that match in id order.
+func (r *repository) refundsWhere(match func(refund models.Refund) bool) []models.Refund {
+	refunds := make([]models.Refund, 0)
+	for _, id := range sortedIDs(r.refunds) {
+		if row := r.refunds[id]; match(row.refund) {
+			refunds = append(refunds, row.read())
+		}
+	}
+	return refunds
+}
+
+// Refund operations
+func (r *repository) CreateRefund(ctx context.Context, refund models.Refund) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orders[refund.OrderID]; !ok {
+		return 0, fmt.Errorf("failed to create refund: order %d does not exist", refund.OrderID)
+	}
+	for _, item := range refund.Items {
+		if r.orderItems[item.OrderItemID] != refund.OrderID {
+			return 0, fmt.Errorf("failed to insert refund item: order item %d does not exist", item.OrderItemID)
+		}
+	}
+
+	row := &refundRow{refund: refund}
+	if refund.PaymentID != nil {
+		paymentID := *refund.PaymentID
+		row.refund.PaymentID = &paymentID
+	}
+	row.refund.ID = r.nextID("refunds")
+	row.refund.Items = slices.Clone(refund.Items)
+	row.refund.FailureReason = ""
+	row.refund.ApprovedBy = 0
+	row.refund.CreatedAt = timestamp(now())
+	row.refund.ProcessedAt = ""
+	r.refunds[row.refund.ID] = row
+	return row.refund.ID, nil
+}
+
+func (r *repository) GetRefundById(ctx context.Context, id int) (models.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.refunds[id]
+	if !ok {
+		return models.Refund{}, fmt.Errorf("refund not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return row.read(), nil
+}
+
+func (r *repository) GetRefundsByOrder(ctx context.Context, orderId int) ([]models.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.refundsWhere(func(refund models.Refund) bool { return refund.OrderID == orderId }), nil
+}
+
+func (r *repository) GetRefunds(ctx context.Context, status models.RefundStatus) ([]models.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.refundsWhere(func(refund models.Refund) bool { return status == "" || refund.Status == status }), nil
+}
+
+// UpdateRefundStatus moves a refund on from status from. It fails with
+// models.ErrRefundNotAllowed when the refund changed in the meantime.
+func (r *repository) UpdateRefundStatus(ctx context.Context, refund models.Refund, from models.RefundStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.refunds[refund.ID]
+	if !ok || row.refund.Status != from {
+		return fmt.Errorf("%w: refund %d is no longer %s", models.ErrRefundNotAllowed, refund.ID, from)
+	}
+	row.refund.Status = refund.Status
+	row.refund.ApprovedBy = refund.ApprovedBy
+	row.refund.FailureReason = refund.FailureReason
+	row.processedAt = time.Time{}
+	if refund.Status == models.RefundStatusRejected || refund.Status == models.RefundStatusFailed {
+		row.processedAt = now()
+	}
+	return nil
+}
+
+// CompleteRefund marks a processing refund completed, adds it to the order's
+// refunded totals and puts restocked items back. Cash refunds are checked
+// against the money taken on the order.
+func (r *repository) CompleteRefund(ctx context.Context, refund models.Refund, movements []models.StockMovement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[refund.OrderID]
+	if !ok {
+		return fmt.Errorf("order not found with id %d: %w", refund.OrderID, models.ErrNotFound)
+	}
+	refundable := -order.order.CashRefunded
+	for _, p := range r.payments {
+		if p.OrderID == refund.OrderID {
+			refundable += p.Captured - p.Refunded
+		}
+	}
+	cash := 0
+	if refund.Method == models.RefundMethodCash {
+		cash = refund.Amount
+		if refund.Amount > refundable {
+			return fmt.Errorf("%w: only %d cents are left to refund", models.ErrRefundNotAllowed, refundable)
+		}
+	}
+
+	row, ok := r.refunds[refund.ID]
+	if !ok || row.refund.Status != models.RefundStatusProcessing {
+		return fmt.Errorf("%w: refund %d is no longer processing", models.ErrRefundNotAllowed, refund.ID)
+	}
+	for _, m := range movements {
+		if _, ok := r.menuItems[m.MenuItemID]; !ok {
+			return fmt.Errorf("failed to record stock movement: menu item %d does not exist", m.MenuItemID)
+		}
+	}
+
+	row.refund.Status = models.RefundStatusCompleted
+	row.processedAt = now()
+	order.order.RefundedTotal += refund.Amount
+	order.order.CashRefunded += cash
+	order.touch()
+
+	for _, m := range movements {
+		r.movements = append(r.movements, models.StockMovement{
+			ID:         r.nextID("stock_movements"),
+			MenuItemID: m.MenuItemID,
+			Quantity:   m.Quantity,
+			Reason:     m.Reason,
+			RefundID:   refund.ID,
+			CreatedAt:  timestamp(now()),
+		})
+		// Items without tracked stock only get the movement
+		if item := r.menuItems[m.MenuItemID].item; item.Stock != nil {
+			stock := *item.Stock + m.Quantity
+			r.menuItems[m.MenuItemID].item.Stock = &stock
+		}
+	}
+	return nil
+}
+
+// GetSalesReport sums orders placed and refunds completed in [from, to).
+// Cancelled orders are left out.
+func (r *repository) GetSalesReport(ctx context.Context, from, to time.Time) (models.SalesReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := models.SalesReport{
+		From:            from.Format(time.RFC3339),
+		To:              to.Format(time.RFC3339),
+		RefundsByReason: make(map[models.RefundReason]int),
+		RefundsByMethod: make(map[models.RefundMethod]int),
+	}
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	for _, row := range r.orders {
+		if row.order.Status != models.OrderStatusCancelled && inRange(row.createdAt) {
+			report.Orders++
+			report.GrossSales += row.order.TotalPrice
+		}
+	}
+	for _, row := range r.refunds {
+		switch {
+		case row.refund.Status == models.RefundStatusCompleted && inRange(row.processedAt):
+			report.RefundsByReason[row.refund.Reason] += row.refund.Amount
+			report.RefundsByMethod[row.refund.Method] += row.refund.Amount
+			report.Refunds += row.refund.Amount
+		case row.refund.Status == models.RefundStatusPendingApproval:
+			report.PendingRefunds++
+		}
+	}
+
+	report.NetSales = report.GrossSales - report.Refunds
+	return report, nil
+}
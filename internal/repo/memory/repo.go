@@ -0,0 +1,117 @@
+package memrepo
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+// repository keeps every table in maps guarded by one lock. Each operation
+// holds the lock from start to end, which makes it a transaction: it checks
+// everything that can fail before it changes anything.
+type repository struct {
+	mu  sync.Mutex
+	ids map[string]int // last id handed out per table
+
+	users         map[int]models.User
+	menuItems     map[int]*menuItemRow
+	prices        map[int]models.MenuItemPrice
+	tables        map[int]models.Table
+	orders        map[int]*orderRow
+	orderItems    map[int]int // order item id to order id
+	bundles       map[int]models.Bundle
+	promotions    map[int]models.Promotion
+	taxRates      map[int]models.TaxRate // without categories
+	taxCategories map[string]int         // category to tax rate id
+	payments      map[int]models.Payment
+	paymentEvents map[paymentEventKey]*paymentEventRow
+	checks        map[int]models.Check
+	refunds       map[int]*refundRow
+	movements     []models.StockMovement
+	invoiceSeqs   map[int]int // fiscal year to last invoice number
+	invoices      map[int]models.Invoice
+	stations      map[int]models.Station // without categories
+	stationRoutes map[string]int         // lower case category to station id
+	tickets       map[int]*ticketRow
+	ticketItems   map[int]*ticketItemRow
+	outbox        []*outboxRow
+	endpoints     map[int]models.WebhookEndpoint
+	deliveries    map[int64]*deliveryRow
+}
+
+// NewRepository returns an empty database loaded with the sample data of
+// the migrations. Nothing is persisted; every call starts afresh.
+func NewRepository() interfaces.Repository {
+	r := &repository{
+		ids:           make(map[string]int),
+		users:         make(map[int]models.User),
+		menuItems:     make(map[int]*menuItemRow),
+		prices:        make(map[int]models.MenuItemPrice),
+		tables:        make(map[int]models.Table),
+		orders:        make(map[int]*orderRow),
+		orderItems:    make(map[int]int),
+		bundles:       make(map[int]models.Bundle),
+		promotions:    make(map[int]models.Promotion),
+		taxRates:      make(map[int]models.TaxRate),
+		taxCategories: make(map[string]int),
+		payments:      make(map[int]models.Payment),
+		paymentEvents: make(map[paymentEventKey]*paymentEventRow),
+		checks:        make(map[int]models.Check),
+		refunds:       make(map[int]*refundRow),
+		invoiceSeqs:   make(map[int]int),
+		invoices:      make(map[int]models.Invoice),
+		stations:      make(map[int]models.Station),
+		stationRoutes: make(map[string]int),
+		tickets:       make(map[int]*ticketRow),
+		ticketItems:   make(map[int]*ticketItemRow),
+		endpoints:     make(map[int]models.WebhookEndpoint),
+		deliveries:    make(map[int64]*deliveryRow),
+	}
+	r.seed()
+	return r
+}
+
+// Close does nothing; the data goes when the repository does.
+func (r *repository) Close() error {
+	return nil
+}
+
+// Ping and CheckMigrations always pass; there is no database to lose and
+// the schema is the code.
+func (r *repository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *repository) CheckMigrations(ctx context.Context) error {
+	return nil
+}
+
+// nextID hands out ids per table like a SERIAL column.
+func (r *repository) nextID(table string) int {
+	r.ids[table]++
+	return r.ids[table]
+}
+
+// now is the current time as a TIMESTAMP column records it, to the
+// microsecond.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// timestamp formats a time the way a TIMESTAMP column reads back into a string.
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// sortedIDs returns the keys of a table in id order.
+func sortedIDs[K int | int64, V any](m map[K]V) []K {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,14 @@
+package memrepo
+
+import (
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/repo/repotest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) interfaces.Repository {
+		return NewRepository()
+	})
+}
@@ -0,0 +1,16 @@
+package memrepo
+
+import (
+	"context"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/search"
+)
+
+func (r *repository) SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) ([]models.MenuSearchHit, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hits, total := search.MenuItems(r.menuItemsWhere(func(row *menuItemRow) bool { return row.item.Available }), query)
+	return hits, total, nil
+}
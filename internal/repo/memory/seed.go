@@ -0,0 +1,206 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Password of every sample user, hashed with bcrypt.
+const seedPasswordHash = "$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u"
+
+// seed loads the sample data of db/seed, with the same ids.
+func (r *repository) seed() {
+	ctx := context.Background()
+
+	for _, u := range []models.User{
+		{Name: "Admin", Email: "admin@tastybites.com", Role: "admin"},
+		{Name: "John Doe", Email: "john@example.com", Role: "user"},
+		{Name: "Jane Smith", Email: "jane@example.com", Role: "user"},
+		{Name: "Manager", Email: "manager@tastybites.com", Role: "admin"},
+		{Name: "Kitchen", Email: "kitchen@tastybites.com", Role: "kitchen"},
+	} {
+		u.Password = seedPasswordHash
+		mustSeed(r.CreateUser(ctx, u))
+	}
+
+	r.seedMenu()
+
+	seats := []int{4, 2, 6, 4, 8, 2, 4, 6, 6, 12}
+	names := []string{"Table 1", "Table 2", "Table 3", "Table 4", "Table 5", "Table 6", "Table 7", "Table 8", "Bar Counter", "Private Room"}
+	for i, name := range names {
+		mustSeed(r.CreateTable(ctx, models.Table{Name: name, Seats: seats[i], Status: models.TableStatusAvailable}))
+	}
+	for tableID, userID := range map[int]int{3: 2, 5: 4, 8: 3} {
+		t := r.tables[tableID]
+		t.Status, t.BookedBy = models.TableStatusReserved, userID
+		r.tables[tableID] = t
+	}
+
+	r.seedOrders()
+
+	mustSeed(r.CreateBundle(ctx, models.Bundle{
+		Name:        "Pizza Meal Deal",
+		Description: "Any pizza, a soft drink and a dessert",
+		PricingMode: models.BundlePricingFixed,
+		Price:       1800,
+		Active:      true,
+		Slots: []models.BundleSlot{
+			{Name: "Pizza", Category: "Pizza", MinChoices: 1, MaxChoices: 1},
+			{Name: "Drink", MenuItemIDs: []int{11, 12}, MinChoices: 1, MaxChoices: 1},
+			{Name: "Dessert", Category: "Desserts", MinChoices: 1, MaxChoices: 1},
+		},
+	}))
+
+	for _, p := range []models.Promotion{
+		{Name: "Happy Hour", Description: "20% off drinks between 17:00 and 19:00", Type: models.PromotionTypePercent, Value: 20,
+			Category: "Drinks", DailyStart: "17:00", DailyEnd: "19:00", Stackable: true},
+		{Name: "Dessert 3 for 2", Description: "Buy 2 desserts, get the cheapest third one free", Type: models.PromotionTypeBuyXGetY,
+			BuyQuantity: 2, FreeQuantity: 1, Category: "Desserts", Stackable: true},
+		{Name: "Welcome 10%", Description: "10% off your first order over $20", Code: "WELCOME10", Type: models.PromotionTypePercent,
+			Value: 10, MinSpend: 2000, PerUserLimit: 1},
+		{Name: "$5 Off", Description: "$5 off orders over $30, first 100 orders", Code: "FIVEOFF", Type: models.PromotionTypeFixed,
+			Value: 500, MinSpend: 3000, UsageLimit: 100, PerUserLimit: 1, Stackable: true},
+	} {
+		p.Active = true
+		mustSeed(r.CreatePromotion(ctx, p))
+	}
+
+	mustSeed(r.CreateTaxRate(ctx, models.TaxRate{Name: "Food", Rate: 500, Default: true}))
+	mustSeed(r.CreateTaxRate(ctx, models.TaxRate{Name: "Alcohol", Rate: 2000, Categories: []string{"Alcohol"}}))
+
+	for _, name := range []string{"kitchen", "grill", "fryer", "pastry", "bar"} {
+		mustSeed(r.CreateStation(ctx, models.Station{Name: name, IsDefault: name == "kitchen"}))
+	}
+	for category, stationID := range map[string]int{"burgers": 2, "main course": 3, "appetizers": 3, "desserts": 4, "drinks": 5} {
+		mustSeed(0, r.SetCategoryStation(ctx, category, stationID))
+	}
+}
+
+func (r *repository) seedMenu() {
+	type nutrition struct {
+		kcal                             int
+		protein, carbs, fat, sugar, salt float64
+	}
+	items := []struct {
+		name, description string
+		price             int
+		category, image   string
+		allergens         []models.Allergen
+		tags              []models.DietaryTag
+		spicy             int
+		nutrition         *nutrition
+	}{
+		{"Margherita Pizza", "Classic pizza with tomato sauce, mozzarella, and fresh basil", 1299, "Pizza", "photo-1574071318508-1cdbab80d002",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk},
+			[]models.DietaryTag{models.DietaryTagVegetarian}, 0, &nutrition{850, 34, 98, 32, 8, 3.20}},
+		{"Chicken Caesar Salad", "Crisp romaine lettuce with grilled chicken, parmesan, and caesar dressing", 1149, "Salads", "photo-1546793665-c74683f339c1",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk, models.AllergenEggs, models.AllergenFish, models.AllergenMustard},
+			[]models.DietaryTag{models.DietaryTagHalal}, 0, &nutrition{520, 38, 18, 33, 4, 2.10}},
+		{"Beef Burger", "Juicy beef patty with lettuce, tomato, cheese, and special sauce", 1499, "Burgers", "photo-1568901346375-23c9450c58cd",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk, models.AllergenSesame, models.AllergenMustard},
+			nil, 0, &nutrition{980, 52, 62, 56, 12, 3.80}},
+		{"Chocolate Brownie", "Rich chocolate brownie served with vanilla ice cream", 699, "Desserts", "photo-1606313564200-e75d5e30476c",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk, models.AllergenEggs, models.AllergenTreeNuts, models.AllergenSoybeans},
+			[]models.DietaryTag{models.DietaryTagVegetarian}, 0, &nutrition{610, 7, 74, 32, 55, 0.40}},
+		{"Pepperoni Pizza", "Classic pepperoni pizza with mozzarella cheese", 1499, "Pizza", "photo-1565299624946-b28f40a0ca4b",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk, models.AllergenSulphites},
+			nil, 1, nil},
+		{"Greek Salad", "Fresh vegetables with feta cheese and olive oil dressing", 999, "Salads", "photo-1540420773420-3366772f4999",
+			[]models.Allergen{models.AllergenMilk},
+			[]models.DietaryTag{models.DietaryTagVegetarian}, 0, &nutrition{360, 9, 14, 30, 8, 2.40}},
+		{"Fish & Chips", "Beer-battered cod with crispy fries and tartar sauce", 1699, "Main Course", "photo-1544025162-d76694265947",
+			[]models.Allergen{models.AllergenGluten, models.AllergenFish, models.AllergenEggs, models.AllergenMustard},
+			nil, 0, nil},
+		{"Tiramisu", "Classic Italian dessert with coffee-soaked ladyfingers", 799, "Desserts", "photo-1571877227200-a0d98ea607e9",
+			[]models.Allergen{models.AllergenGluten, models.AllergenMilk, models.AllergenEggs, models.AllergenSulphites},
+			[]models.DietaryTag{models.DietaryTagVegetarian}, 0, nil},
+		{"BBQ Chicken Wings", "Smoky BBQ chicken wings with celery sticks", 1299, "Appetizers", "photo-1608039755401-742074f0548d",
+			[]models.Allergen{models.AllergenCelery, models.AllergenMustard, models.AllergenSoybeans},
+			[]models.DietaryTag{models.DietaryTagHalal}, 2, nil},
+		{"Vegetarian Pasta", "Penne pasta with seasonal vegetables in garlic olive oil", 1199, "Pasta", "photo-1621996346565-e3dbc1d56d0e",
+			[]models.Allergen{models.AllergenGluten},
+			[]models.DietaryTag{models.DietaryTagVegetarian, models.DietaryTagVegan}, 0, nil},
+		{"Fresh Lemonade", "House-made lemonade with mint", 399, "Drinks", "photo-1621263764928-df1444c5e859", nil, nil, 0, nil},
+		{"Iced Tea", "Cold brewed black tea with lemon", 349, "Drinks", "photo-1556679343-c7306c1976bc", nil, nil, 0, nil},
+		{"Craft Beer", "Local pale ale on tap", 699, "Alcohol", "photo-1535958636474-b021ee887b13", nil, nil, 0, nil},
+	}
+
+	created := now()
+	for _, it := range items {
+		id := r.nextID("menu_items")
+		item := models.MenuItem{
+			ID:          id,
+			SKU:         fmt.Sprintf("ITEM-%d", id),
+			Name:        it.name,
+			Description: it.description,
+			Price:       it.price,
+			Category:    it.category,
+			Images:      models.MenuImages{Large: "https://images.unsplash.com/" + it.image},
+			Allergens:   it.allergens,
+			DietaryTags: it.tags,
+			SpicyLevel:  it.spicy,
+			Available:   true,
+		}
+		if n := it.nutrition; n != nil {
+			item.Nutrition = &models.NutritionFacts{
+				Kcal: &n.kcal, ProteinGrams: &n.protein, CarbsGrams: &n.carbs,
+				FatGrams: &n.fat, SugarGrams: &n.sugar, SaltGrams: &n.salt,
+			}
+		}
+		r.menuItems[item.ID] = &menuItemRow{item: item, createdAt: created}
+
+		price := models.MenuItemPrice{
+			ID:            r.nextID("menu_item_prices"),
+			MenuItemID:    item.ID,
+			Price:         item.Price,
+			EffectiveFrom: created,
+			Note:          "Initial price",
+			CreatedAt:     created,
+		}
+		r.prices[price.ID] = price
+	}
+}
+
+func (r *repository) seedOrders() {
+	orders := []struct {
+		userID, tableID int
+		status          models.OrderStatus
+		items           [][3]int // menu item, quantity, price
+	}{
+		{2, 1, models.OrderStatusCompleted, [][3]int{{1, 1, 1299}, {2, 1, 1149}}},
+		{3, 2, models.OrderStatusPending, [][3]int{{3, 1, 1499}}},
+		{2, 4, models.OrderStatusPending, [][3]int{{5, 1, 1499}, {4, 1, 699}}},
+		{4, 9, models.OrderStatusCompleted, [][3]int{{7, 1, 1699}, {9, 1, 999}}},
+	}
+
+	created := now()
+	for _, o := range orders {
+		order := models.Order{
+			ID:        r.nextID("orders"),
+			UserID:    o.userID,
+			TableID:   o.tableID,
+			Status:    o.status,
+			Guests:    1,
+			CreatedAt: timestamp(created),
+			UpdatedAt: timestamp(created),
+		}
+		for _, it := range o.items {
+			item := models.OrderItem{ID: r.nextID("order_items"), MenuItemID: it[0], Quantity: it[1], Price: it[2]}
+			order.Items = append(order.Items, item)
+			order.TotalPrice += item.Quantity * item.Price
+			r.orderItems[item.ID] = order.ID
+		}
+		order.Subtotal = order.TotalPrice
+		r.orders[order.ID] = &orderRow{order: order, createdAt: created}
+	}
+}
+
+// mustSeed stops on sample data the repository itself refuses, which is a
+// bug in the seed.
+func mustSeed(_ int, err error) {
+	if err != nil {
+		panic(fmt.Sprintf("memrepo: failed to seed: %v", err))
+	}
+}
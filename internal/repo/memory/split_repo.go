@@ -0,0 +1,135 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Split bill operations
+
+// CreateOrderChecks replaces the checks of a pending order and stores its tip
+// and total, which change when the checks carry their own tips. It fails when
+// money is already held or taken on the order.
+func (r *repository) CreateOrderChecks(ctx context.Context, order models.Order, checks []models.Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[order.ID]
+	if !ok {
+		return fmt.Errorf("order not found with id %d: %w", order.ID, models.ErrNotFound)
+	}
+	if row.order.Status != models.OrderStatusPending {
+		return fmt.Errorf("%w: only pending orders can be split", models.ErrInvalidInput)
+	}
+	if err := r.checkNoPayments(order.ID); err != nil {
+		return err
+	}
+	numbers := make(map[int]bool, len(checks))
+	for _, c := range checks {
+		if numbers[c.Number] {
+			return fmt.Errorf("failed to create order check: check number %d is used twice", c.Number)
+		}
+		numbers[c.Number] = true
+	}
+
+	r.deleteOrderChecks(order.ID)
+	row.order.Tip, row.order.TotalPrice = order.Tip, order.TotalPrice
+	row.touch()
+
+	for _, c := range checks {
+		c.ID = r.nextID("order_checks")
+		c.OrderID = order.ID
+		// Payments are applied when the checks are read
+		c.Paid, c.Balance, c.Status = 0, 0, ""
+		c.Items = slices.Clone(c.Items)
+		c.Taxes = slices.Clone(c.Taxes)
+		r.checks[c.ID] = c
+	}
+	return nil
+}
+
+// DeleteOrderChecks merges a split bill back into one, as long as the order
+// is pending and no money is held or taken on it. The tips of the checks
+// become the order's tip, and its total follows.
+func (r *repository) DeleteOrderChecks(ctx context.Context, orderId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.orders[orderId]
+	if !ok {
+		return fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	if row.order.Status != models.OrderStatusPending {
+		return fmt.Errorf("%w: only pending orders can be merged", models.ErrInvalidInput)
+	}
+	if err := r.checkNoPayments(orderId); err != nil {
+		return err
+	}
+	tip, split := 0, false
+	for _, c := range r.checks {
+		if c.OrderID == orderId {
+			tip += c.Tip
+			split = true
+		}
+	}
+	if split {
+		row.order.TotalPrice += tip - row.order.Tip
+		row.order.Tip = tip
+		row.touch()
+	}
+	r.deleteOrderChecks(orderId)
+	return nil
+}
+
+func (r *repository) deleteOrderChecks(orderId int) {
+	for id, c := range r.checks {
+		if c.OrderID == orderId {
+			delete(r.checks, id)
+		}
+	}
+}
+
+// checkNoPayments fails when an order has payments that hold or took money.
+func (r *repository) checkNoPayments(orderId int) error {
+	for _, p := range r.payments {
+		if p.OrderID != orderId {
+			continue
+		}
+		switch p.Status {
+		case models.PaymentStatusFailed, models.PaymentStatusVoided, models.PaymentStatusRefunded:
+		default:
+			return fmt.Errorf("%w: the order already has payments", models.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+func (r *repository) GetOrderChecks(ctx context.Context, orderId int) ([]models.Check, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checks := make([]models.Check, 0)
+	for _, id := range sortedIDs(r.checks) {
+		c := r.checks[id]
+		if c.OrderID != orderId {
+			continue
+		}
+		c.Items = slices.Clone(c.Items)
+		if c.Items == nil {
+			c.Items = []models.CheckItem{}
+		}
+		slices.SortStableFunc(c.Items, func(a, b models.CheckItem) int {
+			return cmp.Compare(a.OrderItemID, b.OrderItemID)
+		})
+		c.Taxes = append([]models.OrderTax{}, c.Taxes...)
+		checks = append(checks, c)
+	}
+	slices.SortFunc(checks, func(a, b models.Check) int {
+		return cmp.Compare(a.Number, b.Number)
+	})
+	return checks, nil
+}
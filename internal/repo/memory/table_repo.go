@@ -0,0 +1,131 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// tableColumns is what a table reads back as; who booked it and when it
+// changed are not selected.
+func tableColumns(t models.Table) models.Table {
+	return models.Table{ID: t.ID, Name: t.Name, Seats: t.Seats, Status: t.Status}
+}
+
+// Table operations
+func (r *repository) CreateTable(ctx context.Context, table models.Table) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tables {
+		if t.Name == table.Name {
+			return 0, fmt.Errorf("failed to create table: table %q already exists", table.Name)
+		}
+	}
+	table.ID = r.nextID("tables")
+	table.BookedBy = 0
+	table.CreatedAt = timestamp(now())
+	table.UpdatedAt = table.CreatedAt
+	r.tables[table.ID] = table
+	return table.ID, nil
+}
+
+func (r *repository) GetTableById(ctx context.Context, id int) (models.Table, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	table, ok := r.tables[id]
+	if !ok {
+		return models.Table{}, fmt.Errorf("failed to get table by ID: table %d does not exist", id)
+	}
+	return tableColumns(table), nil
+}
+
+func (r *repository) UpdateTable(ctx context.Context, table models.Table) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tables[table.ID]
+	if !ok {
+		return nil
+	}
+	for _, t := range r.tables {
+		if t.ID != table.ID && t.Name == table.Name {
+			return fmt.Errorf("failed to update table: table %q already exists", table.Name)
+		}
+	}
+	current.Name, current.Seats, current.Status = table.Name, table.Seats, table.Status
+	current.UpdatedAt = timestamp(now())
+	r.tables[table.ID] = current
+	return nil
+}
+
+func (r *repository) GetTablesByStatus(ctx context.Context, status models.TableStatus) ([]models.Table, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tables []models.Table
+	for _, id := range sortedIDs(r.tables) {
+		if t := r.tables[id]; t.Status == status {
+			tables = append(tables, tableColumns(t))
+		}
+	}
+	return tables, nil
+}
+
+func (r *repository) DeleteTable(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tables, id)
+	return nil
+}
+
+func (r *repository) GetAllTables(ctx context.Context) ([]models.Table, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tables []models.Table
+	for _, id := range sortedIDs(r.tables) {
+		tables = append(tables, tableColumns(r.tables[id]))
+	}
+	return tables, nil
+}
+
+// ResetTable completes the open orders of a table and makes it available
+// again, returning the ids of the completed orders.
+func (r *repository) ResetTable(ctx context.Context, tableId int) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []models.OrderChange
+	for _, id := range sortedIDs(r.orders) {
+		row := r.orders[id]
+		if row.order.TableID != tableId || !row.open() {
+			continue
+		}
+		row.order.Status = models.OrderStatusCompleted
+		row.touch()
+		changes = append(changes, row.change())
+	}
+
+	if table, ok := r.tables[tableId]; ok {
+		table.Status = models.TableStatusAvailable
+		table.BookedBy = 0
+		table.UpdatedAt = timestamp(now())
+		r.tables[tableId] = table
+	}
+
+	orderIDs := make([]int, len(changes))
+	for i, change := range changes {
+		orderIDs[i] = change.OrderID
+		if err := r.writeOutbox(models.OutboxOrderStatusChanged, change); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.writeOutbox(models.OutboxTableReset, models.TableChange{TableID: tableId, CompletedOrderIDs: orderIDs}); err != nil {
+		return nil, err
+	}
+	return orderIDs, nil
+}
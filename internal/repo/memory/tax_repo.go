@@ -0,0 +1,59 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Tax rate operations
+func (r *repository) CreateTaxRate(ctx context.Context, rate models.TaxRate) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.taxRates {
+		if t.Name == rate.Name {
+			return 0, fmt.Errorf("failed to create tax rate: tax rate %q already exists", rate.Name)
+		}
+	}
+
+	// A new default rate replaces the old one
+	if rate.Default {
+		for id, t := range r.taxRates {
+			t.Default = false
+			r.taxRates[id] = t
+		}
+	}
+
+	rate.ID = r.nextID("tax_rates")
+	rate.CreatedAt = timestamp(now())
+	rate.UpdatedAt = rate.CreatedAt
+	// Categories move over from the rate they were under before
+	for _, category := range rate.Categories {
+		r.taxCategories[category] = rate.ID
+	}
+	rate.Categories = nil
+	r.taxRates[rate.ID] = rate
+	return rate.ID, nil
+}
+
+func (r *repository) GetTaxRates(ctx context.Context) ([]models.TaxRate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rates := make([]models.TaxRate, 0)
+	for _, id := range sortedIDs(r.taxRates) {
+		t := r.taxRates[id]
+		t.Categories = []string{}
+		for _, category := range slices.Sorted(maps.Keys(r.taxCategories)) {
+			if r.taxCategories[category] == id {
+				t.Categories = append(t.Categories, category)
+			}
+		}
+		rates = append(rates, t)
+	}
+	return rates, nil
+}
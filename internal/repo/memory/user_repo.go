@@ -0,0 +1,75 @@
+package memrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// User operations
+func (r *repository) CreateUser(ctx context.Context, user models.User) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.Role == "" {
+		user.Role = string(models.UserRoleUser)
+	}
+	for _, u := range r.users {
+		if u.Email == user.Email {
+			return 0, fmt.Errorf("failed to create user: email %s is already registered", user.Email)
+		}
+	}
+	user.ID = r.nextID("users")
+	user.CreatedAt = timestamp(now())
+	user.UpdatedAt = user.CreatedAt
+	r.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (r *repository) GetUserById(ctx context.Context, id int) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return models.User{}, fmt.Errorf("user not found with id %d: %w", id, sql.ErrNoRows)
+	}
+	user.Password = ""
+	return user, nil
+}
+
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, fmt.Errorf("user not found with email %s: %w", email, sql.ErrNoRows)
+}
+
+func (r *repository) UpdateUser(ctx context.Context, user models.User) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) UpdateUserPassword(ctx context.Context, id int, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user not found with id %d: %w", id, sql.ErrNoRows)
+	}
+	user.Password = password
+	user.UpdatedAt = timestamp(now())
+	r.users[id] = user
+	return nil
+}
+
+func (r *repository) DeleteUser(ctx context.Context, id int) error {
+	return fmt.Errorf("not implemented")
+}
@@ -0,0 +1,267 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+type outboxRow struct {
+	event      models.OutboxEvent
+	dispatched bool
+}
+
+type deliveryRow struct {
+	id            int64
+	endpointID    int
+	eventID       int64
+	status        models.WebhookDeliveryStatus
+	attempts      int
+	nextAttemptAt time.Time
+	lastError     string
+	createdAt     time.Time
+	deliveredAt   time.Time
+	log           []models.WebhookAttempt
+}
+
+// Outbox and webhook operations
+
+// writeOutbox records an event together with the change it is about. The
+// caller holds the lock.
+func (r *repository) writeOutbox(eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+	r.outbox = append(r.outbox, &outboxRow{event: models.OutboxEvent{
+		ID:        int64(r.nextID("outbox_events")),
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: timestamp(now()),
+	}})
+	return nil
+}
+
+func (r *repository) CreateWebhookEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint.ID = r.nextID("webhook_endpoints")
+	endpoint.EventTypes = append([]string{}, endpoint.EventTypes...)
+	endpoint.CreatedAt = timestamp(now())
+	r.endpoints[endpoint.ID] = endpoint
+	return endpoint.ID, nil
+}
+
+// webhookEndpoint reads an endpoint back without its secret.
+func (r *repository) webhookEndpoint(id int) models.WebhookEndpoint {
+	e := r.endpoints[id]
+	e.Secret = ""
+	e.EventTypes = append([]string{}, e.EventTypes...)
+	return e
+}
+
+func (r *repository) GetWebhookEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var endpoints []models.WebhookEndpoint
+	for _, id := range sortedIDs(r.endpoints) {
+		endpoints = append(endpoints, r.webhookEndpoint(id))
+	}
+	return endpoints, nil
+}
+
+func (r *repository) GetWebhookEndpointById(ctx context.Context, id int) (models.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[id]; !ok {
+		return models.WebhookEndpoint{}, fmt.Errorf("webhook endpoint not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return r.webhookEndpoint(id), nil
+}
+
+func (r *repository) SetWebhookEndpointActive(ctx context.Context, id int, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.endpoints[id]
+	if !ok {
+		return fmt.Errorf("webhook endpoint not found with id %d: %w", id, models.ErrNotFound)
+	}
+	e.Active = active
+	r.endpoints[id] = e
+	return nil
+}
+
+// DispatchOutbox creates a delivery per subscribed active endpoint for the
+// oldest undispatched outbox events and marks them dispatched. Events nobody
+// subscribes to are only marked. It returns how many events it took.
+func (r *repository) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := now()
+	n := 0
+	for _, o := range r.outbox {
+		if n >= limit {
+			break
+		}
+		if o.dispatched {
+			continue
+		}
+		for _, id := range sortedIDs(r.endpoints) {
+			e := r.endpoints[id]
+			if !e.Active || (len(e.EventTypes) > 0 && !slices.Contains(e.EventTypes, o.event.Type)) {
+				continue
+			}
+			if r.hasDelivery(id, o.event.ID) {
+				continue
+			}
+			d := &deliveryRow{
+				id:            int64(r.nextID("webhook_deliveries")),
+				endpointID:    id,
+				eventID:       o.event.ID,
+				status:        models.WebhookDeliveryPending,
+				nextAttemptAt: created,
+				createdAt:     created,
+			}
+			r.deliveries[d.id] = d
+		}
+		o.dispatched = true
+		n++
+	}
+	return n, nil
+}
+
+func (r *repository) hasDelivery(endpointId int, eventId int64) bool {
+	for _, d := range r.deliveries {
+		if d.endpointID == endpointId && d.eventID == eventId {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookDelivery builds a delivery as it is read, with its event and
+// without its log.
+func (r *repository) webhookDelivery(d *deliveryRow) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		ID:          d.id,
+		EndpointID:  d.endpointID,
+		Status:      d.status,
+		Attempts:    d.attempts,
+		LastError:   d.lastError,
+		CreatedAt:   timestamp(d.createdAt),
+		DeliveredAt: timestamp(d.deliveredAt),
+	}
+	for _, o := range r.outbox {
+		if o.event.ID == d.eventID {
+			delivery.Event = o.event
+			delivery.Event.Data = slices.Clone(o.event.Data)
+		}
+	}
+	if d.status == models.WebhookDeliveryPending || d.status == models.WebhookDeliveryRetrying {
+		delivery.NextAttemptAt = timestamp(d.nextAttemptAt)
+	}
+	return delivery
+}
+
+// ClaimWebhookDeliveries takes deliveries that are due and moves their next
+// attempt lease into the future, so they are retried if the caller dies.
+func (r *repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := now()
+	var due []*deliveryRow
+	for _, d := range r.deliveries {
+		if (d.status == models.WebhookDeliveryPending || d.status == models.WebhookDeliveryRetrying) && !d.nextAttemptAt.After(claimed) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b *deliveryRow) int {
+		return cmp.Or(a.nextAttemptAt.Compare(b.nextAttemptAt), cmp.Compare(a.id, b.id))
+	})
+	due = due[:min(len(due), max(limit, 0))]
+	slices.SortFunc(due, func(a, b *deliveryRow) int { return cmp.Compare(a.id, b.id) })
+
+	var deliveries []models.WebhookDelivery
+	for _, d := range due {
+		d.nextAttemptAt = claimed.Add(lease).Truncate(time.Microsecond)
+		delivery := r.webhookDelivery(d)
+		delivery.URL, delivery.Secret = r.endpoints[d.endpointID].URL, r.endpoints[d.endpointID].Secret
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt logs an attempt and moves the delivery to status,
+// to be tried again after retryIn when it is retrying.
+func (r *repository) RecordWebhookAttempt(ctx context.Context, deliveryId int64, attempt models.WebhookAttempt, status models.WebhookDeliveryStatus, retryIn time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[deliveryId]
+	if !ok {
+		return fmt.Errorf("failed to log webhook attempt: delivery %d does not exist", deliveryId)
+	}
+	attempted := now()
+	attempt.AttemptedAt = timestamp(attempted)
+	d.log = append(d.log, attempt)
+
+	d.status, d.attempts, d.lastError = status, attempt.Attempt, attempt.Error
+	d.nextAttemptAt, d.deliveredAt = time.Time{}, time.Time{}
+	switch status {
+	case models.WebhookDeliveryRetrying:
+		d.nextAttemptAt = attempted.Add(retryIn).Truncate(time.Microsecond)
+	case models.WebhookDeliveryDelivered:
+		d.deliveredAt = attempted
+	}
+	return nil
+}
+
+// GetWebhookDeliveries lists an endpoint's latest deliveries, optionally of
+// one status, with their attempts.
+func (r *repository) GetWebhookDeliveries(ctx context.Context, endpointId int, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deliveries []models.WebhookDelivery
+	for _, id := range slices.Backward(sortedIDs(r.deliveries)) {
+		if len(deliveries) >= limit {
+			break
+		}
+		d := r.deliveries[id]
+		if d.endpointID != endpointId || (status != "" && d.status != status) {
+			continue
+		}
+		delivery := r.webhookDelivery(d)
+		delivery.Log = slices.Clone(d.log)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery schedules a delivery that is not delivered yet for
+// an immediate fresh series of attempts.
+func (r *repository) RetryWebhookDelivery(ctx context.Context, deliveryId int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[deliveryId]
+	if !ok {
+		return fmt.Errorf("webhook delivery not found with id %d: %w", deliveryId, models.ErrNotFound)
+	}
+	if d.status == models.WebhookDeliveryDelivered {
+		return fmt.Errorf("%w: webhook delivery %d was already delivered", models.ErrInvalidInput, deliveryId)
+	}
+	d.status, d.attempts, d.nextAttemptAt = models.WebhookDeliveryPending, 0, now()
+	return nil
+}
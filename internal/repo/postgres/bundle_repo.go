@@ -0,0 +1,134 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Bundle operations
+func (r *repository) CreateBundle(ctx context.Context, bundle models.Bundle) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO public.bundles (name, description, pricing_mode, price, discount_percent, active)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	var bundleID int
+	err = tx.QueryRowContext(ctx, query, bundle.Name, bundle.Description, bundle.PricingMode,
+		nullIfZero(bundle.Price), nullIfZero(bundle.DiscountPercent), bundle.Active).Scan(&bundleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	for i, slot := range bundle.Slots {
+		slotQuery := `INSERT INTO public.bundle_slots (bundle_id, name, category, min_choices, max_choices, position)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		var slotID int
+		err := tx.QueryRowContext(ctx, slotQuery, bundleID, slot.Name, sql.NullString{String: slot.Category, Valid: slot.Category != ""},
+			slot.MinChoices, slot.MaxChoices, i).Scan(&slotID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create bundle slot: %w", err)
+		}
+		for _, itemID := range slot.MenuItemIDs {
+			_, err := tx.ExecContext(ctx, `INSERT INTO public.bundle_slot_items (slot_id, menu_item_id) VALUES ($1, $2)`, slotID, itemID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to add item to bundle slot: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit bundle: %w", err)
+	}
+	return bundleID, nil
+}
+
+func (r *repository) GetBundleById(ctx context.Context, id int) (models.Bundle, error) {
+	bundles, err := r.queryBundles(ctx, `WHERE id = $1`, id)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	if len(bundles) == 0 {
+		return models.Bundle{}, fmt.Errorf("bundle not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return bundles[0], nil
+}
+
+func (r *repository) GetAllBundles(ctx context.Context) ([]models.Bundle, error) {
+	return r.queryBundles(ctx, ``)
+}
+
+// queryBundles loads bundles matching the where clause along with their slots.
+func (r *repository) queryBundles(ctx context.Context, where string, args ...any) ([]models.Bundle, error) {
+	query := `SELECT id, name, coalesce(description, ''), pricing_mode, coalesce(price, 0), coalesce(discount_percent, 0), active, created_at, updated_at
+		FROM public.bundles ` + where + ` ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bundles: %w", err)
+	}
+	defer rows.Close()
+
+	bundles := make([]models.Bundle, 0)
+	for rows.Next() {
+		var b models.Bundle
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.PricingMode, &b.Price, &b.DiscountPercent, &b.Active, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bundle: %w", err)
+		}
+		b.Slots = []models.BundleSlot{}
+		bundles = append(bundles, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over bundles: %w", err)
+	}
+	if len(bundles) == 0 {
+		return bundles, nil
+	}
+
+	byID := make(map[int]*models.Bundle, len(bundles))
+	ids := make([]int64, len(bundles))
+	for i := range bundles {
+		byID[bundles[i].ID] = &bundles[i]
+		ids[i] = int64(bundles[i].ID)
+	}
+
+	slotQuery := `
+		SELECT s.bundle_id, s.id, s.name, coalesce(s.category, ''), s.min_choices, s.max_choices,
+			coalesce(array_to_string(array_agg(si.menu_item_id ORDER BY si.menu_item_id) FILTER (WHERE si.menu_item_id IS NOT NULL), ','), '')
+		FROM public.bundle_slots s
+		LEFT JOIN public.bundle_slot_items si ON si.slot_id = s.id
+		WHERE s.bundle_id = ANY($1)
+		GROUP BY s.id
+		ORDER BY s.bundle_id, s.position, s.id
+	`
+	slotRows, err := r.DB.QueryContext(ctx, slotQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bundle slots: %w", err)
+	}
+	defer slotRows.Close()
+
+	for slotRows.Next() {
+		var (
+			bundleID int
+			slot     models.BundleSlot
+			itemIDs  string
+		)
+		if err := slotRows.Scan(&bundleID, &slot.ID, &slot.Name, &slot.Category, &slot.MinChoices, &slot.MaxChoices, &itemIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan bundle slot: %w", err)
+		}
+		if slot.MenuItemIDs, err = parseIDList(itemIDs); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle slot items: %w", err)
+		}
+		if b, ok := byID[bundleID]; ok {
+			b.Slots = append(b.Slots, slot)
+		}
+	}
+	if err := slotRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over bundle slots: %w", err)
+	}
+	return bundles, nil
+}
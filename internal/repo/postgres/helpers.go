@@ -0,0 +1,39 @@
+package pgrepo
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// nullIfZero stores zero values as NULL, for optional integer columns.
+func nullIfZero(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
+
+// parseIDList parses a comma separated list of ids as produced by
+// array_to_string.
+func parseIDList(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]int, len(parts))
+	for i, part := range parts {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// toInt64s converts ids for use with = ANY($1).
+func toInt64s(ids []int) []int64 {
+	out := make([]int64, len(ids))
+	for i, id := range ids {
+		out[i] = int64(id)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const invoiceColumns = `id, order_id, fiscal_year, sequence, number, customer_name,
+	coalesce(customer_tax_id, ''), coalesce(customer_address, ''), issued_at`
+
+func scanInvoice(row rowScanner) (models.Invoice, error) {
+	var i models.Invoice
+	err := row.Scan(&i.ID, &i.OrderID, &i.FiscalYear, &i.Sequence, &i.Number, &i.CustomerName,
+		&i.CustomerTaxID, &i.CustomerAddress, &i.IssuedAt)
+	return i, err
+}
+
+// Invoice operations
+
+// CreateInvoice issues the next invoice number of the invoice's fiscal year
+// to its order. The counter is bumped in the same transaction as the insert,
+// so a failed insert gives the number back. An order that already has an
+// invoice gets the existing one.
+func (r *repository) CreateInvoice(ctx context.Context, invoice models.Invoice) (models.Invoice, error) {
+	existing, err := r.GetInvoiceByOrder(ctx, invoice.OrderID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return models.Invoice{}, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Row lock on the year's counter serialises concurrent issuers
+	query := `INSERT INTO public.invoice_sequences (fiscal_year, last_number) VALUES ($1, 1)
+		ON CONFLICT (fiscal_year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number`
+	if err := tx.QueryRowContext(ctx, query, invoice.FiscalYear).Scan(&invoice.Sequence); err != nil {
+		return models.Invoice{}, fmt.Errorf("failed to take invoice number: %w", err)
+	}
+	invoice.Number = models.InvoiceNumber(invoice.FiscalYear, invoice.Sequence)
+
+	query = `INSERT INTO public.invoices (order_id, fiscal_year, sequence, number, customer_name, customer_tax_id, customer_address)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+		ON CONFLICT (order_id) DO NOTHING
+		RETURNING ` + invoiceColumns
+	created, err := scanInvoice(tx.QueryRowContext(ctx, query, invoice.OrderID, invoice.FiscalYear, invoice.Sequence,
+		invoice.Number, invoice.CustomerName, invoice.CustomerTaxID, invoice.CustomerAddress))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Issued concurrently; rolling back returns the number
+		tx.Rollback()
+		return r.GetInvoiceByOrder(ctx, invoice.OrderID)
+	}
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Invoice{}, fmt.Errorf("failed to commit invoice: %w", err)
+	}
+	return created, nil
+}
+
+func (r *repository) GetInvoiceByOrder(ctx context.Context, orderId int) (models.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM public.invoices WHERE order_id = $1`
+	invoice, err := scanInvoice(r.DB.QueryRowContext(ctx, query, orderId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Invoice{}, fmt.Errorf("invoice not found for order %d: %w", orderId, models.ErrNotFound)
+	}
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return invoice, nil
+}
@@ -0,0 +1,60 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/db"
+	"github.com/abdullahnettoor/tastybites/internal/migrate"
+)
+
+// autoMigrate applies the pending embedded migrations and, when seed is set,
+// loads the sample data into a database that has no users yet.
+func autoMigrate(conn *sql.DB, seed bool) error {
+	ctx := context.Background()
+	migrator, err := migrate.New(conn, db.Migrations)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	log.Printf("Database schema is up to date, %d migrations applied", applied)
+
+	if seed {
+		seeded, err := migrator.Seed(ctx, db.Seed)
+		if err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
+		}
+		if seeded {
+			log.Println("Loaded the sample data")
+		}
+	}
+	return nil
+}
+
+// CheckMigrations fails when migrations embedded in this binary are not
+// applied, as happens when it starts without auto-migrate before someone
+// runs migrate up.
+func (r *repository) CheckMigrations(ctx context.Context) error {
+	migrator, err := migrate.New(r.DB, db.Migrations)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for i, mig := range pending {
+			names[i] = fmt.Sprintf("%03d_%s", mig.Version, mig.Name)
+		}
+		return fmt.Errorf("%d migrations not applied: %s", len(pending), strings.Join(names, ", "))
+	}
+	return nil
+}
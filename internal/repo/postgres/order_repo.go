@@ -2,44 +2,225 @@ package pgrepo
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/models"
 )
 
 // Order operations
 func (r *repository) CreateOrder(ctx context.Context, order models.Order) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	// Insert order into the database
-	query := `INSERT INTO public.orders (user_id, table_id, total_price, status) VALUES ($1, $2, $3, $4) RETURNING id`
+	query := `INSERT INTO public.orders (user_id, table_id, total_price, status, coupon_code, subtotal, discount_total,
+			tax_inclusive, tax_total, guests, service_charge, tip)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 	var orderID int
-	err := r.DB.QueryRowContext(ctx, query, order.UserID, order.TableID, order.TotalPrice, order.Status).Scan(&orderID)
+	err = tx.QueryRowContext(ctx, query, order.UserID, order.TableID, order.TotalPrice, order.Status,
+		order.CouponCode, order.Subtotal, order.DiscountTotal,
+		order.TaxInclusive, order.TaxTotal, order.Guests, order.ServiceCharge, order.Tip).Scan(&orderID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create order: %w", err)
 	}
 
 	// Insert order items into the database
-	for _, item := range order.Items {
-		itemQuery := `INSERT INTO public.order_items (order_id, menu_item_id, quantity, price) VALUES ($1, $2, $3, $4)`
-		_, err := r.DB.ExecContext(ctx, itemQuery, orderID, item.MenuItemID, item.Quantity, item.Price)
+	for i, item := range order.Items {
+		itemQuery := `INSERT INTO public.order_items (order_id, menu_item_id, bundle_id, quantity, price, note) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')) RETURNING id`
+		var itemID int
+		err := tx.QueryRowContext(ctx, itemQuery, orderID, nullIfZero(item.MenuItemID), item.BundleID, item.Quantity, item.Price, item.Note).Scan(&itemID)
 		if err != nil {
 			return 0, fmt.Errorf("failed to insert order item: %w", err)
 		}
+		order.Items[i].ID = itemID
+
+		for _, c := range item.Components {
+			componentQuery := `INSERT INTO public.order_item_components (order_item_id, bundle_slot_id, menu_item_id, quantity) VALUES ($1, $2, $3, $4)`
+			_, err := tx.ExecContext(ctx, componentQuery, itemID, nullIfZero(c.SlotID), c.MenuItemID, c.Quantity)
+			if err != nil {
+				return 0, fmt.Errorf("failed to insert order item component: %w", err)
+			}
+		}
+		if err := writeItemTaxes(ctx, tx, itemID, item.Taxes); err != nil {
+			return 0, err
+		}
 	}
 
+	if err := writeOrderDiscounts(ctx, tx, orderID, order.UserID, order.Discounts); err != nil {
+		return 0, err
+	}
+	if err := writeOrderTaxes(ctx, tx, orderID, order.Taxes); err != nil {
+		return 0, err
+	}
+	if err := takeStock(ctx, tx, order); err != nil {
+		return 0, err
+	}
+	order.ID = orderID
+	if err := writeOutbox(ctx, tx, models.OutboxOrderCreated, order); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit order: %w", err)
+	}
 	return orderID, nil
 }
 
-func (r *repository) GetOrderById(ctx context.Context, id int) (models.Order, error) {
+// takeStock takes what the order uses off the menu items whose stock is
+// tracked, failing when there is not enough left.
+func takeStock(ctx context.Context, tx *sql.Tx, order models.Order) error {
+	used := make(map[int]int)
+	for _, item := range order.KitchenItems() {
+		used[item.MenuItemID] += item.Quantity
+	}
+	// Lock rows in id order so concurrent orders cannot deadlock
+	ids := make([]int, 0, len(used))
+	for id := range used {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		var left int
+		err := tx.QueryRowContext(ctx, `UPDATE public.menu_items SET stock_quantity = stock_quantity - $1
+			WHERE id = $2 AND stock_quantity IS NOT NULL RETURNING stock_quantity`, used[id], id).Scan(&left)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // stock is not tracked
+		}
+		if err != nil {
+			return fmt.Errorf("failed to take stock: %w", err)
+		}
+		if left < 0 {
+			return fmt.Errorf("%w: only %d left of menu item %d", models.ErrInvalidInput, left+used[id], id)
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO public.stock_movements (menu_item_id, quantity, reason) VALUES ($1, $2, 'sale')`, id, -used[id])
+		if err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+	}
+	return nil
+}
+
+const orderColumns = `o.id, o.user_id, coalesce(o.table_id, 0), o.total_price, o.status,
+	coalesce(o.coupon_code, ''), o.subtotal, o.discount_total,
+	o.tax_inclusive, o.tax_total, o.guests, o.service_charge, o.tip, o.refunded_total, o.cash_refunded, coalesce(o.kitchen_status, ''), o.created_at`
+
+func scanOrder(row rowScanner) (models.Order, error) {
 	var order models.Order
-	query := `SELECT id, user_id, total_price, status FROM public.orders WHERE id = $1`
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Status)
+	err := row.Scan(&order.ID, &order.UserID, &order.TableID, &order.TotalPrice, &order.Status,
+		&order.CouponCode, &order.Subtotal, &order.DiscountTotal,
+		&order.TaxInclusive, &order.TaxTotal, &order.Guests, &order.ServiceCharge, &order.Tip,
+		&order.RefundedTotal, &order.CashRefunded, &order.KitchenStatus, &order.CreatedAt)
+	return order, err
+}
+
+// attachOrderDetails loads bundle components and line taxes, and the
+// discount and tax breakdown of the given orders.
+func (r *repository) attachOrderDetails(ctx context.Context, orders []models.Order) error {
+	items := orderItemPointers(orders)
+	if err := r.attachComponents(ctx, items); err != nil {
+		return err
+	}
+	if err := r.attachItemTaxes(ctx, items); err != nil {
+		return err
+	}
+	if err := r.attachDiscounts(ctx, orders); err != nil {
+		return err
+	}
+	return r.attachTaxes(ctx, orders)
+}
+
+const orderItemColumns = `oi.id, oi.menu_item_id, oi.bundle_id, oi.quantity, oi.price, coalesce(oi.note, '')`
+
+// scanOrderItem scans orderItemColumns, preceded by any leading columns.
+func scanOrderItem(row rowScanner, leading ...any) (models.OrderItem, error) {
+	var (
+		item       models.OrderItem
+		menuItemID sql.NullInt64
+		bundleID   sql.NullInt64
+	)
+	dest := append(leading, &item.ID, &menuItemID, &bundleID, &item.Quantity, &item.Price, &item.Note)
+	if err := row.Scan(dest...); err != nil {
+		return models.OrderItem{}, err
+	}
+	item.MenuItemID = int(menuItemID.Int64)
+	if bundleID.Valid {
+		id := int(bundleID.Int64)
+		item.BundleID = &id
+	}
+	return item, nil
+}
+
+// attachComponents loads the chosen components of every bundle line.
+func (r *repository) attachComponents(ctx context.Context, items []*models.OrderItem) error {
+	byID := make(map[int]*models.OrderItem)
+	var ids []int64
+	for _, item := range items {
+		if item.IsBundle() {
+			byID[item.ID] = item
+			ids = append(ids, int64(item.ID))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `SELECT order_item_id, coalesce(bundle_slot_id, 0), menu_item_id, quantity
+		FROM public.order_item_components WHERE order_item_id = ANY($1) ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get order item components: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var c models.OrderItemComponent
+		if err := rows.Scan(&itemID, &c.SlotID, &c.MenuItemID, &c.Quantity); err != nil {
+			return fmt.Errorf("failed to scan order item component: %w", err)
+		}
+		if item, ok := byID[itemID]; ok {
+			item.Components = append(item.Components, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order item components: %w", err)
+	}
+	return nil
+}
+
+// orderItemPointers collects pointers to the items of all orders.
+func orderItemPointers(orders []models.Order) []*models.OrderItem {
+	var items []*models.OrderItem
+	for i := range orders {
+		for j := range orders[i].Items {
+			items = append(items, &orders[i].Items[j])
+		}
+	}
+	return items
+}
+
+func (r *repository) GetOrderById(ctx context.Context, id int) (models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM public.orders o WHERE o.id = $1`
+	order, err := scanOrder(r.DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Order{}, fmt.Errorf("order not found with id %d: %w", id, models.ErrNotFound)
+	}
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to get order by ID: %w", err)
 	}
+	order.Items = []models.OrderItem{}
 
 	// Get order items
-	itemQuery := `SELECT menu_item_id, quantity, price FROM public.order_items WHERE order_id = $1`
+	itemQuery := `SELECT ` + orderItemColumns + ` FROM public.order_items oi WHERE oi.order_id = $1 ORDER BY oi.id`
 	rows, err := r.DB.QueryContext(ctx, itemQuery, id)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to get order items: %w", err)
@@ -47,155 +228,266 @@ func (r *repository) GetOrderById(ctx context.Context, id int) (models.Order, er
 	defer rows.Close()
 
 	for rows.Next() {
-		var item models.OrderItem
-		if err := rows.Scan(&item.MenuItemID, &item.Quantity, &item.Price); err != nil {
+		item, err := scanOrderItem(rows)
+		if err != nil {
 			return models.Order{}, fmt.Errorf("failed to scan order item: %w", err)
 		}
 		order.Items = append(order.Items, item)
 	}
 
-	return order, nil
+	orders := []models.Order{order}
+	if err := r.attachOrderDetails(ctx, orders); err != nil {
+		return models.Order{}, err
+	}
+	return orders[0], nil
 }
 
 func (r *repository) GetAllOrders(ctx context.Context) ([]models.Order, error) {
-	var orders []models.Order
-	query := `SELECT id, user_id, total_price, status FROM public.orders`
-	rows, err := r.DB.QueryContext(ctx, query)
+	query := `SELECT ` + orderColumns + ` FROM public.orders o ORDER BY o.id`
+	orders, err := r.queryOrders(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all orders: %w", err)
 	}
-	defer rows.Close()
+	return orders, r.attachItems(ctx, orders)
+}
 
-	for rows.Next() {
-		var order models.Order
-		if err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Status); err != nil {
-			return nil, fmt.Errorf("failed to scan order: %w", err)
-		}
+func (r *repository) GetOrdersByUser(ctx context.Context, userId int) ([]models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM public.orders o WHERE o.user_id = $1 ORDER BY o.id`
+	orders, err := r.queryOrders(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders by user: %w", err)
+	}
+	return orders, r.attachItems(ctx, orders)
+}
 
-		// Get order items
-		itemQuery := `SELECT menu_item_id, quantity, price FROM public.order_items WHERE order_id = $1`
-		itemRows, err := r.DB.QueryContext(ctx, itemQuery, order.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get order items: %w", err)
+// ListOrders counts the orders matching the filters and returns the page
+// after query.After, using the sort key and id as the keyset.
+func (r *repository) ListOrders(ctx context.Context, query models.OrderListQuery) ([]models.Order, int, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg ...any) {
+		placeholders := make([]any, len(arg))
+		for i := range arg {
+			args = append(args, arg[i])
+			placeholders[i] = len(args)
 		}
-		defer itemRows.Close()
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+	}
+	if query.Status != "" {
+		add("o.status = $%d", string(query.Status))
+	}
+	if query.TableID != 0 {
+		add("o.table_id = $%d", query.TableID)
+	}
+	if query.UserID != 0 {
+		add("o.user_id = $%d", query.UserID)
+	}
+	if !query.From.IsZero() {
+		add("o.created_at >= $%d::timestamp", pgTimestamp(query.From))
+	}
+	if !query.To.IsZero() {
+		add("o.created_at < $%d::timestamp", pgTimestamp(query.To))
+	}
+	if query.MinTotal > 0 {
+		add("o.total_price >= $%d", query.MinTotal)
+	}
 
-		for itemRows.Next() {
-			var item models.OrderItem
-			if err := itemRows.Scan(&item.MenuItemID, &item.Quantity, &item.Price); err != nil {
-				return nil, fmt.Errorf("failed to scan order item: %w", err)
-			}
-			order.Items = append(order.Items, item)
-		}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	var total int
+	if err := r.DB.QueryRowContext(ctx, `SELECT count(*) FROM public.orders o`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
 
-		orders = append(orders, order)
+	column, direction, compare := "o.created_at", "ASC", ">"
+	if query.SortBy == models.OrderSortTotal {
+		column = "o.total_price"
+	}
+	if query.Desc {
+		direction, compare = "DESC", "<"
 	}
+	if after := query.After; after != nil {
+		if query.SortBy == models.OrderSortTotal {
+			add("("+column+", o.id) "+compare+" ($%d, $%d)", after.Total, after.ID)
+		} else {
+			add("("+column+", o.id) "+compare+" ($%d::timestamp, $%d)", pgTimestamp(after.CreatedAt), after.ID)
+		}
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, query.Limit)
+	listQuery := fmt.Sprintf(`SELECT %s FROM public.orders o%s ORDER BY %s %s, o.id %s LIMIT $%d`,
+		orderColumns, where, column, direction, direction, len(args))
+	orders, err := r.queryOrders(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return orders, total, r.attachItems(ctx, orders)
+}
 
-	return orders, nil
+// pgTimestamp formats t for comparison with a TIMESTAMP column, which holds
+// times in UTC.
+func pgTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.999999")
 }
 
-func (r *repository) GetOrdersByUser(ctx context.Context, userId int) ([]models.Order, error) {
-	var orders []models.Order
-	query := `SELECT id, user_id, total_price, status FROM public.orders WHERE user_id = $1`
-	rows, err := r.DB.QueryContext(ctx, query, userId)
+// queryOrders runs a query for orderColumns, without the items.
+func (r *repository) queryOrders(ctx context.Context, query string, args ...any) ([]models.Order, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orders by user: %w", err)
+		return nil, err
 	}
 	defer rows.Close()
 
+	var orders []models.Order
 	for rows.Next() {
-		var order models.Order
-		if err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Status); err != nil {
+		order, err := scanOrder(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
-		order.Items = []models.OrderItem{}
 		orders = append(orders, order)
 	}
-
-	// Fetch all orders and their items in a single query
-	itemQuery := `
-		SELECT oi.order_id, oi.menu_item_id, oi.quantity, oi.price
-		FROM public.order_items oi
-		JOIN public.orders o ON o.id = oi.order_id
-		WHERE o.user_id = $1
-	`
-	itemRows, err := r.DB.QueryContext(ctx, itemQuery, userId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get order items: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over orders: %w", err)
 	}
-	defer itemRows.Close()
+	return orders, nil
+}
 
-	// Map orderID to order pointer for quick lookup
-	orderMap := make(map[int]*models.Order)
+// attachItems loads the items of the orders in one query, then their
+// details.
+func (r *repository) attachItems(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.Order, len(orders))
+	ids := make([]int64, len(orders))
 	for i := range orders {
-		orderMap[orders[i].ID] = &orders[i]
+		orders[i].Items = []models.OrderItem{}
+		byID[orders[i].ID] = &orders[i]
+		ids[i] = int64(orders[i].ID)
 	}
 
-	for itemRows.Next() {
+	query := `SELECT oi.order_id, ` + orderItemColumns + ` FROM public.order_items oi WHERE oi.order_id = ANY($1) ORDER BY oi.id`
+	rows, err := r.DB.QueryContext(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
 		var orderID int
-		var item models.OrderItem
-		if err := itemRows.Scan(&orderID, &item.MenuItemID, &item.Quantity, &item.Price); err != nil {
-			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		item, err := scanOrderItem(rows, &orderID)
+		if err != nil {
+			return fmt.Errorf("failed to scan order item: %w", err)
 		}
-		if order, ok := orderMap[orderID]; ok {
+		if order, ok := byID[orderID]; ok {
 			order.Items = append(order.Items, item)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order items: %w", err)
+	}
+	rows.Close()
 
-	return orders, nil
+	return r.attachOrderDetails(ctx, orders)
 }
 
 func (r *repository) GetOrderByTableId(ctx context.Context, tableId int) (models.Order, error) {
-	query := `
-		SELECT o.id, o.user_id, o.total_price, o.status, oi.menu_item_id, oi.quantity, oi.price
-		FROM public.orders o
-		LEFT JOIN public.order_items oi ON o.id = oi.order_id
-		WHERE o.table_id = $1 AND o.status = 'pending'
-	`
-	rows, err := r.DB.QueryContext(ctx, query, tableId)
+	query := `SELECT id FROM public.orders WHERE table_id = $1 AND status IN ('pending', 'paid') ORDER BY id LIMIT 1`
+	var orderID int
+	err := r.DB.QueryRowContext(ctx, query, tableId).Scan(&orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Order{}, fmt.Errorf("no pending order found for table ID %d", tableId)
+	}
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to get order by table ID: %w", err)
 	}
-	defer rows.Close()
+	return r.GetOrderById(ctx, orderID)
+}
 
-	var order models.Order
-	order.Items = []models.OrderItem{}
-	first := true
-	for rows.Next() {
-		var (
-			id         int
-			userID     int
-			totalPrice float64
-			status     string
-			menuItemID *int
-			quantity   *int
-			price      *float64
-		)
-		if err := rows.Scan(&id, &userID, &totalPrice, &status, &menuItemID, &quantity, &price); err != nil {
-			return models.Order{}, fmt.Errorf("failed to scan row: %w", err)
-		}
-		if first {
-			order.ID = id
-			order.UserID = userID
-			order.TableID = tableId
-			order.Status = models.OrderStatus(status)
-			first = false
-		}
-
-		if menuItemID != nil && quantity != nil && price != nil {
-			order.Items = append(order.Items, models.OrderItem{
-				MenuItemID: *menuItemID,
-				Quantity:   *quantity,
-				Price:      *price,
-			})
-		}
-	}
-
-	if first {
-		return models.Order{}, fmt.Errorf("no pending order found for table ID %d", tableId)
+// UpdateOrderTotals stores the recomputed price breakdown of a pending
+// order, replacing its discounts and taxes and the taxes of its lines.
+func (r *repository) UpdateOrderTotals(ctx context.Context, order models.Order) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE public.orders SET coupon_code = NULLIF($1, ''), subtotal = $2, discount_total = $3,
+			tax_inclusive = $4, tax_total = $5, guests = $6, service_charge = $7, tip = $8, total_price = $9
+		WHERE id = $10 AND status = 'pending'`
+	result, err := tx.ExecContext(ctx, query, order.CouponCode, order.Subtotal, order.DiscountTotal,
+		order.TaxInclusive, order.TaxTotal, order.Guests, order.ServiceCharge, order.Tip, order.TotalPrice, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update order totals: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("pending order not found with id %d: %w", order.ID, models.ErrNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.order_discounts WHERE order_id = $1`, order.ID); err != nil {
+		return fmt.Errorf("failed to clear order discounts: %w", err)
+	}
+	if err := writeOrderDiscounts(ctx, tx, order.ID, order.UserID, order.Discounts); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.order_taxes WHERE order_id = $1`, order.ID); err != nil {
+		return fmt.Errorf("failed to clear order taxes: %w", err)
+	}
+	if err := writeOrderTaxes(ctx, tx, order.ID, order.Taxes); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM public.order_item_taxes WHERE order_item_id IN (SELECT id FROM public.order_items WHERE order_id = $1)`, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear order item taxes: %w", err)
+	}
+	for _, item := range order.Items {
+		if err := writeItemTaxes(ctx, tx, item.ID, item.Taxes); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order totals: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) UpdateOrderStatus(ctx context.Context, id int, status models.OrderStatus) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `UPDATE public.orders SET status = $1 WHERE id = $2 RETURNING `+orderChangeColumns, status, id)
+	change, err := scanOrderChange(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("order not found with id %d: %w", id, models.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if err := writeOutbox(ctx, tx, models.OutboxOrderStatusChanged, change); err != nil {
+		return err
 	}
 
-	return order, nil
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order status: %w", err)
+	}
+	return nil
+}
+
+// orderChangeColumns are the columns of an orders row that make up an
+// outbox OrderChange.
+const orderChangeColumns = `id, user_id, coalesce(table_id, 0), status, coalesce(kitchen_status, ''), total_price`
+
+func scanOrderChange(row rowScanner) (models.OrderChange, error) {
+	var c models.OrderChange
+	err := row.Scan(&c.OrderID, &c.UserID, &c.TableID, &c.Status, &c.KitchenStatus, &c.TotalPrice)
+	return c, err
 }
 
 func (r *repository) UpdateOrder(ctx context.Context, order models.Order) error {
@@ -205,12 +497,3 @@ func (r *repository) UpdateOrder(ctx context.Context, order models.Order) error
 func (r *repository) DeleteOrder(ctx context.Context, id int) error {
 	return fmt.Errorf("not implemented")
 }
-
-func (r *repository) UpdateOrderStatusByTableId(ctx context.Context, tableId int, status models.OrderStatus) error {
-	query := `UPDATE public.orders SET status = $1 WHERE table_id = $2 AND status = 'pending'`
-	_, err := r.DB.ExecContext(ctx, query, status, tableId)
-	if err != nil {
-		return fmt.Errorf("failed to update order status by table ID: %w", err)
-	}
-	return nil
-}
@@ -0,0 +1,209 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const paymentColumns = `id, order_id, check_id, provider, coalesce(reference, ''), status, amount, captured, refunded, currency,
+	coalesce(failure_reason, ''), coalesce(idempotency_key, ''), coalesce(created_by, 0), created_at, updated_at`
+
+func scanPayment(row rowScanner) (models.Payment, error) {
+	var (
+		p       models.Payment
+		checkID sql.NullInt64
+	)
+	err := row.Scan(&p.ID, &p.OrderID, &checkID, &p.Provider, &p.Reference, &p.Status, &p.Amount, &p.Captured, &p.Refunded, &p.Currency,
+		&p.FailureReason, &p.IdempotencyKey, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if checkID.Valid {
+		id := int(checkID.Int64)
+		p.CheckID = &id
+	}
+	return p, err
+}
+
+func (r *repository) queryPayments(ctx context.Context, where string, args ...any) ([]models.Payment, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+paymentColumns+` FROM public.payments `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make([]models.Payment, 0)
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over payments: %w", err)
+	}
+	return payments, nil
+}
+
+func (r *repository) getPayment(ctx context.Context, where string, args ...any) (models.Payment, error) {
+	payments, err := r.queryPayments(ctx, where, args...)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if len(payments) == 0 {
+		return models.Payment{}, fmt.Errorf("payment not found: %w", models.ErrNotFound)
+	}
+	return payments[0], nil
+}
+
+// Payment operations
+
+// CreatePayment records a payment as long as it fits what is left to pay on
+// its order, or check, with the payments recorded so far. Concurrent payments of an order wait for the lock on it, so each sees
+// the ones recorded before it.
+func (r *repository) CreatePayment(ctx context.Context, p models.Payment) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := paymentFits(ctx, tx, p); err != nil {
+		return 0, err
+	}
+	query := `INSERT INTO public.payments (order_id, check_id, provider, reference, status, amount, captured, refunded, currency,
+			failure_reason, idempotency_key, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12) RETURNING id`
+	checkID := 0
+	if p.CheckID != nil {
+		checkID = *p.CheckID
+	}
+	var paymentID int
+	err = tx.QueryRowContext(ctx, query, p.OrderID, nullIfZero(checkID), p.Provider, p.Reference, p.Status, p.Amount, p.Captured, p.Refunded, p.Currency,
+		p.FailureReason, p.IdempotencyKey, nullIfZero(p.CreatedBy)).Scan(&paymentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create payment: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit payment: %w", err)
+	}
+	return paymentID, nil
+}
+
+// paymentFits checks a new payment against the order it locks, its checks and
+// its payments.
+func paymentFits(ctx context.Context, tx *sql.Tx, p models.Payment) error {
+	var order models.Order
+	err := tx.QueryRowContext(ctx, `SELECT id, status, total_price, refunded_total, cash_refunded FROM public.orders WHERE id = $1 FOR UPDATE`,
+		p.OrderID).Scan(&order.ID, &order.Status, &order.TotalPrice, &order.RefundedTotal, &order.CashRefunded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to create payment: order %d does not exist", p.OrderID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	var checks []models.Check
+	rows, err := tx.QueryContext(ctx, `SELECT id, total_price FROM public.order_checks WHERE order_id = $1`, p.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order checks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c models.Check
+		if err := rows.Scan(&c.ID, &c.TotalPrice); err != nil {
+			return fmt.Errorf("failed to scan order check: %w", err)
+		}
+		checks = append(checks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order checks: %w", err)
+	}
+
+	var payments []models.Payment
+	rows, err = tx.QueryContext(ctx, `SELECT check_id, status, amount, captured, refunded FROM public.payments WHERE order_id = $1`, p.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get payments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			existing models.Payment
+			checkID  sql.NullInt64
+		)
+		if err := rows.Scan(&checkID, &existing.Status, &existing.Amount, &existing.Captured, &existing.Refunded); err != nil {
+			return fmt.Errorf("failed to scan payment: %w", err)
+		}
+		if checkID.Valid {
+			id := int(checkID.Int64)
+			existing.CheckID = &id
+		}
+		payments = append(payments, existing)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over payments: %w", err)
+	}
+	return order.CheckPaymentFits(p, checks, payments)
+}
+
+func (r *repository) GetPaymentById(ctx context.Context, id int) (models.Payment, error) {
+	return r.getPayment(ctx, `WHERE id = $1`, id)
+}
+
+func (r *repository) GetPaymentsByOrder(ctx context.Context, orderId int) ([]models.Payment, error) {
+	return r.queryPayments(ctx, `WHERE order_id = $1`, orderId)
+}
+
+func (r *repository) GetPaymentByIdempotencyKey(ctx context.Context, orderId int, key string) (models.Payment, error) {
+	return r.getPayment(ctx, `WHERE order_id = $1 AND idempotency_key = $2`, orderId, key)
+}
+
+func (r *repository) GetPaymentByReference(ctx context.Context, provider, reference string) (models.Payment, error) {
+	return r.getPayment(ctx, `WHERE provider = $1 AND reference = $2`, provider, reference)
+}
+
+// UpdatePayment saves a payment that was read with status from. It fails with
+// models.ErrPaymentConflict when the payment changed in the meantime.
+func (r *repository) UpdatePayment(ctx context.Context, p models.Payment, from models.PaymentStatus) error {
+	query := `UPDATE public.payments SET reference = NULLIF($1, ''), status = $2, captured = $3, refunded = $4, failure_reason = NULLIF($5, '')
+		WHERE id = $6 AND status = $7`
+	result, err := r.DB.ExecContext(ctx, query, p.Reference, p.Status, p.Captured, p.Refunded, p.FailureReason, p.ID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: payment %d is no longer %s", models.ErrPaymentConflict, p.ID, from)
+	}
+	return nil
+}
+
+// RecordWebhookEvent stores a webhook delivery unless it was seen before, and
+// reports whether the event has already been processed.
+func (r *repository) RecordWebhookEvent(ctx context.Context, e models.PaymentWebhookEvent) (bool, error) {
+	query := `INSERT INTO public.payment_webhook_events (provider, event_id, event_type, reference, payload)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (provider, event_id) DO NOTHING`
+	if _, err := r.DB.ExecContext(ctx, query, e.Provider, e.EventID, e.Type, e.Reference, string(e.Payload)); err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+
+	var processedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, `SELECT processed_at FROM public.payment_webhook_events WHERE provider = $1 AND event_id = $2`,
+		e.Provider, e.EventID).Scan(&processedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("webhook event %s vanished: %w", e.EventID, models.ErrNotFound)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+	return processedAt.Valid, nil
+}
+
+func (r *repository) MarkWebhookEventProcessed(ctx context.Context, provider, eventId string) error {
+	query := `UPDATE public.payment_webhook_events SET processed_at = CURRENT_TIMESTAMP WHERE provider = $1 AND event_id = $2`
+	if _, err := r.DB.ExecContext(ctx, query, provider, eventId); err != nil {
+		return fmt.Errorf("failed to mark webhook event processed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Menu price history operations
+func (r *repository) CreateMenuItemPrice(ctx context.Context, price models.MenuItemPrice) (int, error) {
+	query := `INSERT INTO public.menu_item_prices (menu_item_id, price, effective_from, created_by, note)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	var priceID int
+	err := r.DB.QueryRowContext(ctx, query, price.MenuItemID, price.Price, price.EffectiveFrom, price.CreatedBy,
+		sql.NullString{String: price.Note, Valid: price.Note != ""}).Scan(&priceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create menu item price: %w", err)
+	}
+	return priceID, nil
+}
+
+func (r *repository) GetMenuItemPrices(ctx context.Context, menuItemId int) ([]models.MenuItemPrice, error) {
+	query := `SELECT id, menu_item_id, price, effective_from, created_by, coalesce(note, ''), created_at
+		FROM public.menu_item_prices WHERE menu_item_id = $1 ORDER BY effective_from`
+	rows, err := r.DB.QueryContext(ctx, query, menuItemId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu item prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make([]models.MenuItemPrice, 0)
+	for rows.Next() {
+		var (
+			p         models.MenuItemPrice
+			createdBy sql.NullInt64
+		)
+		if err := rows.Scan(&p.ID, &p.MenuItemID, &p.Price, &p.EffectiveFrom, &createdBy, &p.Note, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan menu item price: %w", err)
+		}
+		if createdBy.Valid {
+			id := int(createdBy.Int64)
+			p.CreatedBy = &id
+		}
+		prices = append(prices, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over menu item prices: %w", err)
+	}
+	return prices, nil
+}
+
+// GetMenuItemPricesAt returns the price in effect at the given moment for
+// each of the menu items, in a single query so all prices come from the same
+// snapshot. Unknown and archived ids are left out of the result, as they
+// cannot be ordered.
+func (r *repository) GetMenuItemPricesAt(ctx context.Context, menuItemIds []int, at time.Time) (map[int]int, error) {
+	ids := make([]int64, len(menuItemIds))
+	for i, id := range menuItemIds {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT m.id, coalesce((
+			SELECT p.price FROM public.menu_item_prices p
+			WHERE p.menu_item_id = m.id AND p.effective_from <= $2
+			ORDER BY p.effective_from DESC
+			LIMIT 1
+		), m.price)
+		FROM public.menu_items m
+		WHERE m.id = ANY($1) AND m.available
+	`
+	rows, err := r.DB.QueryContext(ctx, query, ids, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu item prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make(map[int]int, len(menuItemIds))
+	for rows.Next() {
+		var id, price int
+		if err := rows.Scan(&id, &price); err != nil {
+			return nil, fmt.Errorf("failed to scan menu item price: %w", err)
+		}
+		prices[id] = price
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over menu item prices: %w", err)
+	}
+	return prices, nil
+}
+
+// DeleteScheduledMenuItemPrice removes a price change that has not taken
+// effect by the given moment. Prices already in effect are history and stay.
+func (r *repository) DeleteScheduledMenuItemPrice(ctx context.Context, menuItemId, priceId int, now time.Time) error {
+	query := `DELETE FROM public.menu_item_prices WHERE id = $1 AND menu_item_id = $2 AND effective_from > $3`
+	result, err := r.DB.ExecContext(ctx, query, priceId, menuItemId, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete menu item price: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("scheduled price %d not found for menu item %d: %w", priceId, menuItemId, models.ErrNotFound)
+	}
+	return nil
+}
@@ -1,32 +1,146 @@
 package pgrepo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
 	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
-	_ "github.com/jackc/pgx/v5/stdlib" 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// repository runs its queries through database/sql with the pgx stdlib
+// driver, which converts the timestamps the models keep as strings. The
+// driver borrows its connections from Pool, so the pool limits, statement
+// cache mode and TLS settings apply to every query, and the pool statistics
+// count them; the queries themselves do not use the pgx API.
 type repository struct {
-	DB *sql.DB
+	DB   *sql.DB
+	Pool *pgxpool.Pool
 }
 
 func NewRepository(cfg *config.DBConfig) (interfaces.Repository, error) {
-	// Initialize the database connection here
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Username,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-	)
-	log.Println("Connecting to database with connection string:", connStr)
-	db, err := sql.Open("pgx", connStr)
+	pool, db, err := Open(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &repository{DB: db}, nil
+	r := &repository{DB: db, Pool: pool}
+	if err := waitForDB(pool, cfg.ConnectTimeout); err != nil {
+		r.Close()
+		return nil, err
+	}
+	if cfg.AutoMigrate {
+		if err := autoMigrate(db, cfg.Seed); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *repository) Close() error {
+	err := r.DB.Close()
+	r.Pool.Close()
+	return err
+}
+
+func (r *repository) Ping(ctx context.Context) error {
+	return r.Pool.Ping(ctx)
+}
+
+// PoolStats reports the connection pool for the metrics.
+func (r *repository) PoolStats() models.PoolStats {
+	stat := r.Pool.Stat()
+	return models.PoolStats{
+		MaxConns:                int(stat.MaxConns()),
+		TotalConns:              int(stat.TotalConns()),
+		IdleConns:               int(stat.IdleConns()),
+		AcquiredConns:           int(stat.AcquiredConns()),
+		ConstructingConns:       int(stat.ConstructingConns()),
+		AcquireCount:            stat.AcquireCount(),
+		AcquireDurationMs:       stat.AcquireDuration().Milliseconds(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
+
+// Waits between connection attempts at startup, doubling from the first
+// to the last.
+const (
+	firstConnectDelay = 250 * time.Millisecond
+	maxConnectDelay   = 5 * time.Second
+)
+
+// waitForDB pings the database until it answers, for up to timeout, as it
+// may still be starting next to the server. A zero timeout tries once.
+func waitForDB(pool *pgxpool.Pool, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := firstConnectDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), maxConnectDelay)
+		err := pool.Ping(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
+		}
+		wait := min(delay, left)
+		log.Printf("Database not ready (attempt %d): %v; retrying in %s", attempt, err, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+		delay = min(delay*2, maxConnectDelay)
+	}
+}
+
+// Open creates a connection pool for the Postgres database cfg describes,
+// along with a database/sql handle on it for the queries and migrations.
+// Connections are made as they are needed. Closing the handle leaves the
+// pool open.
+func Open(cfg *config.DBConfig) (*pgxpool.Pool, *sql.DB, error) {
+	dsn := connString(cfg)
+	log.Println("Connecting to database:", dsn.Redacted())
+	poolConfig, err := pgxpool.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid database settings: %w", err)
+	}
+	poolConfig.MaxConns = int32(cfg.MaxConns)
+	poolConfig.MinConns = int32(cfg.MinConns)
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	return pool, stdlib.OpenDBFromPool(pool), nil
+}
+
+// connString is the URL of the database cfg describes. pgx reads the TLS
+// and statement cache settings from its query.
+func connString(cfg *config.DBConfig) *url.URL {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	if cfg.CAFile != "" {
+		query.Set("sslrootcert", cfg.CAFile)
+	}
+	query.Set("default_query_exec_mode", cfg.StatementCacheMode)
+	return &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Database,
+		RawQuery: query.Encode(),
+	}
 }
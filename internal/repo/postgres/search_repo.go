@@ -0,0 +1,80 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/search"
+)
+
+// Minimum pg_trgm word similarity for a name to count as a typo match.
+const menuSearchSimilarityThreshold = 0.3
+
+// ts_headline marks matches with search's stand-ins rather than the <mark>
+// tags, so the text can be escaped before they become tags. Any stand-ins
+// in the text itself are dropped first.
+const (
+	marks       = `'` + search.MarkStart + search.MarkStop + `'`
+	markOptions = `'StartSel="` + search.MarkStart + `", StopSel="` + search.MarkStop + `", `
+)
+
+func (r *repository) SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) ([]models.MenuSearchHit, int, error) {
+	// Rank is the weighted full-text rank plus the trigram similarity of the
+	// name, so exact matches come first and typo matches still surface.
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS tsq)
+		SELECT ` + menuItemColumns + `,
+			ts_rank_cd(m.search_vector, q.tsq) + word_similarity($1, m.name) AS rank,
+			ts_headline('english', translate(m.name, ` + marks + `, ''), q.tsq, ` + markOptions + `HighlightAll=true'),
+			ts_headline('english', translate(coalesce(m.description, ''), ` + marks + `, ''), q.tsq, ` + markOptions + `MaxFragments=2, MaxWords=20, MinWords=5'),
+			count(*) OVER () AS total
+		FROM ` + menuItemSource + ` CROSS JOIN q
+		WHERE m.available AND (m.search_vector @@ q.tsq OR word_similarity($1, m.name) >= $2)
+		ORDER BY rank DESC, m.id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.DB.QueryContext(ctx, sqlQuery, query.Text, menuSearchSimilarityThreshold, query.Limit, query.Offset())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search menu items: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]models.MenuSearchHit, 0)
+	total := 0
+	for rows.Next() {
+		var hit models.MenuSearchHit
+		item, err := scanMenuItem(rows, &hit.Rank, &hit.Highlights.Name, &hit.Highlights.Description, &total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan menu search hit: %w", err)
+		}
+		hit.Item = item
+		hit.Highlights.Name = search.EscapeHighlights(hit.Highlights.Name)
+		hit.Highlights.Description = search.EscapeHighlights(hit.Highlights.Description)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate over menu search hits: %w", err)
+	}
+
+	items := make([]models.MenuItem, len(hits))
+	for i := range hits {
+		items[i] = hits[i].Item
+	}
+	if err := r.attachDietaryInfo(ctx, items); err != nil {
+		return nil, 0, err
+	}
+	for i := range hits {
+		hits[i].Item = items[i]
+	}
+
+	// The window count is only available when the page is not empty
+	if len(hits) == 0 && query.Offset() > 0 {
+		countQuery := `SELECT count(*) FROM public.menu_items m
+			WHERE m.available AND (m.search_vector @@ websearch_to_tsquery('english', $1) OR word_similarity($1, m.name) >= $2)`
+		if err := r.DB.QueryRowContext(ctx, countQuery, query.Text, menuSearchSimilarityThreshold).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count menu search hits: %w", err)
+		}
+	}
+	return hits, total, nil
+}
@@ -49,7 +49,7 @@ func (r *repository) UpdateTable(ctx context.Context, table models.Table) error
 }
 
 func (r *repository) GetTablesByStatus(ctx context.Context, status models.TableStatus) ([]models.Table, error) {
-	query := `SELECT id, name, seats, status FROM public.tables WHERE status = $1`
+	query := `SELECT id, name, seats, status FROM public.tables WHERE status = $1 ORDER BY id`
 	rows, err := r.DB.QueryContext(ctx, query, status)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tables by status: %w", err)
@@ -80,7 +80,7 @@ func (r *repository) DeleteTable(ctx context.Context, id int) error {
 }
 
 func (r *repository) GetAllTables(ctx context.Context) ([]models.Table, error) {
-	query := `SELECT id, name, capacity, status FROM public.tables`
+	query := `SELECT id, name, seats, status FROM public.tables ORDER BY id`
 	rows, err := r.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all tables: %w", err)
@@ -101,11 +101,52 @@ func (r *repository) GetAllTables(ctx context.Context) ([]models.Table, error) {
 	return tables, nil
 }
 
-func (r *repository) ResetTableToAvailable(ctx context.Context, tableId int) error {
-	query := `UPDATE public.tables SET status = $1, booked_by = NULL WHERE id = $2`
-	_, err := r.DB.ExecContext(ctx, query, models.TableStatusAvailable, tableId)
+// ResetTable completes the open orders of a table and makes it available
+// again, returning the ids of the completed orders.
+func (r *repository) ResetTable(ctx context.Context, tableId int) ([]int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to reset table to available: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return nil
+	defer tx.Rollback()
+
+	query := `UPDATE public.orders SET status = $1 WHERE table_id = $2 AND status IN ('pending', 'paid') RETURNING ` + orderChangeColumns
+	rows, err := tx.QueryContext(ctx, query, models.OrderStatusCompleted, tableId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete orders for table: %w", err)
+	}
+	var changes []models.OrderChange
+	for rows.Next() {
+		change, err := scanOrderChange(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan completed order: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to complete orders for table: %w", err)
+	}
+
+	query = `UPDATE public.tables SET status = $1, booked_by = NULL WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, models.TableStatusAvailable, tableId); err != nil {
+		return nil, fmt.Errorf("failed to reset table to available: %w", err)
+	}
+
+	orderIDs := make([]int, len(changes))
+	for i, change := range changes {
+		orderIDs[i] = change.OrderID
+		if err := writeOutbox(ctx, tx, models.OutboxOrderStatusChanged, change); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeOutbox(ctx, tx, models.OutboxTableReset, models.TableChange{TableID: tableId, CompletedOrderIDs: orderIDs}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit table reset: %w", err)
+	}
+	return orderIDs, nil
 }
@@ -0,0 +1,177 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Tax rate operations
+func (r *repository) CreateTaxRate(ctx context.Context, rate models.TaxRate) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A new default rate replaces the old one
+	if rate.Default {
+		if _, err := tx.ExecContext(ctx, `UPDATE public.tax_rates SET is_default = FALSE WHERE is_default`); err != nil {
+			return 0, fmt.Errorf("failed to clear default tax rate: %w", err)
+		}
+	}
+
+	var rateID int
+	query := `INSERT INTO public.tax_rates (name, rate, is_default) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, rate.Name, rate.Rate, rate.Default).Scan(&rateID); err != nil {
+		return 0, fmt.Errorf("failed to create tax rate: %w", err)
+	}
+
+	// Categories move over from the rate they were under before
+	for _, category := range rate.Categories {
+		_, err := tx.ExecContext(ctx, `INSERT INTO public.tax_rate_categories (category, tax_rate_id) VALUES ($1, $2)
+			ON CONFLICT (category) DO UPDATE SET tax_rate_id = EXCLUDED.tax_rate_id`, category, rateID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add category to tax rate: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tax rate: %w", err)
+	}
+	return rateID, nil
+}
+
+func (r *repository) GetTaxRates(ctx context.Context) ([]models.TaxRate, error) {
+	query := `
+		SELECT t.id, t.name, t.rate, t.is_default,
+			coalesce(string_agg(c.category, E'\n' ORDER BY c.category), ''), t.created_at, t.updated_at
+		FROM public.tax_rates t
+		LEFT JOIN public.tax_rate_categories c ON c.tax_rate_id = t.id
+		GROUP BY t.id
+		ORDER BY t.id
+	`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make([]models.TaxRate, 0)
+	for rows.Next() {
+		var (
+			t          models.TaxRate
+			categories string
+		)
+		if err := rows.Scan(&t.ID, &t.Name, &t.Rate, &t.Default, &categories, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tax rate: %w", err)
+		}
+		t.Categories = []string{}
+		if categories != "" {
+			t.Categories = strings.Split(categories, "\n")
+		}
+		rates = append(rates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+// writeOrderTaxes inserts the tax breakdown of an order.
+func writeOrderTaxes(ctx context.Context, tx *sql.Tx, orderId int, taxes []models.OrderTax) error {
+	for _, t := range taxes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO public.order_taxes (order_id, tax_rate_id, name, rate, taxable, amount)
+			VALUES ($1, $2, $3, $4, $5, $6)`, orderId, nullIfZero(t.TaxRateID), t.Name, t.Rate, t.Taxable, t.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to insert order tax: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachTaxes loads the tax breakdown of the given orders.
+func (r *repository) attachTaxes(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.Order, len(orders))
+	ids := make([]int, len(orders))
+	for i := range orders {
+		orders[i].Taxes = []models.OrderTax{}
+		byID[orders[i].ID] = &orders[i]
+		ids[i] = orders[i].ID
+	}
+
+	query := `SELECT order_id, coalesce(tax_rate_id, 0), name, rate, taxable, amount FROM public.order_taxes WHERE order_id = ANY($1) ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, toInt64s(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get order taxes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var t models.OrderTax
+		if err := rows.Scan(&orderID, &t.TaxRateID, &t.Name, &t.Rate, &t.Taxable, &t.Amount); err != nil {
+			return fmt.Errorf("failed to scan order tax: %w", err)
+		}
+		if order, ok := byID[orderID]; ok {
+			order.Taxes = append(order.Taxes, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order taxes: %w", err)
+	}
+	return nil
+}
+
+// writeItemTaxes inserts the part of an order line taxed at each rate.
+func writeItemTaxes(ctx context.Context, tx *sql.Tx, orderItemId int, taxes []models.LineTax) error {
+	for _, t := range taxes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO public.order_item_taxes (order_item_id, tax_rate_id, amount) VALUES ($1, $2, $3)`,
+			orderItemId, nullIfZero(t.TaxRateID), t.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to insert order item tax: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachItemTaxes loads the taxes of the given order lines.
+func (r *repository) attachItemTaxes(ctx context.Context, items []*models.OrderItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.OrderItem, len(items))
+	ids := make([]int, len(items))
+	for i, item := range items {
+		byID[item.ID] = item
+		ids[i] = item.ID
+	}
+
+	query := `SELECT order_item_id, coalesce(tax_rate_id, 0), amount FROM public.order_item_taxes WHERE order_item_id = ANY($1) ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, toInt64s(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get order item taxes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var t models.LineTax
+		if err := rows.Scan(&itemID, &t.TaxRateID, &t.Amount); err != nil {
+			return fmt.Errorf("failed to scan order item tax: %w", err)
+		}
+		if item, ok := byID[itemID]; ok {
+			item.Taxes = append(item.Taxes, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order item taxes: %w", err)
+	}
+	return nil
+}
@@ -53,6 +53,17 @@ func (r *repository) UpdateUser(ctx context.Context, user models.User) error {
 	return fmt.Errorf("not implemented")
 }
 
+func (r *repository) UpdateUserPassword(ctx context.Context, id int, password string) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE public.users SET password = $1 WHERE id = $2`, password, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("user not found with id %d: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
 func (r *repository) DeleteUser(ctx context.Context, id int) error {
 	return fmt.Errorf("not implemented")
 }
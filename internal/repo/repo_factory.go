@@ -5,7 +5,9 @@ import (
 
 	"github.com/abdullahnettoor/tastybites/internal/config"
 	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
 	pgrepo "github.com/abdullahnettoor/tastybites/internal/repo/postgres"
+	sqliterepo "github.com/abdullahnettoor/tastybites/internal/repo/sqlite"
 )
 
 func NewRepository(dbConfig *config.DBConfig) (interfaces.Repository, error) {
@@ -13,6 +15,10 @@ func NewRepository(dbConfig *config.DBConfig) (interfaces.Repository, error) {
 	switch dbConfig.Driver {
 	case "postgres":
 		return pgrepo.NewRepository(dbConfig)
+	case "sqlite":
+		return sqliterepo.NewRepository(dbConfig)
+	case "memory":
+		return memrepo.NewRepository(), nil
 	default:
 		return nil, errors.New("unsupported database driver: " + dbConfig.Driver)
 	}
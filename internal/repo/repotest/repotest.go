@@ -0,0 +1,788 @@
+// Package repotest is a conformance suite for implementations of
+// interfaces.Repository. It checks the behaviour every driver has to share
+// on the sample data of db/seed, so a driver passes it when it can
+// stand in for postgres.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+// Run runs the suite. newRepo has to return a repository holding exactly
+// the sample data, fresh for every subtest.
+func Run(t *testing.T, newRepo func(t *testing.T) interfaces.Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r interfaces.Repository)
+	}{
+		{"Users", testUsers},
+		{"Menu", testMenu},
+		{"Prices", testPrices},
+		{"Search", testSearch},
+		{"MenuImport", testMenuImport},
+		{"Tables", testTables},
+		{"Orders", testOrders},
+		{"ListOrders", testListOrders},
+		{"Promotions", testPromotions},
+		{"Taxes", testTaxes},
+		{"Bundles", testBundles},
+		{"Checks", testChecks},
+		{"Payments", testPayments},
+		{"Invoices", testInvoices},
+		{"Kitchen", testKitchen},
+		{"Webhooks", testWebhooks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+// must fails the test on err and returns v otherwise, as in
+// must(r.GetOrderById(ctx, 1))(t).
+func must[T any](v T, err error) func(t *testing.T) T {
+	return func(t *testing.T) T {
+		t.Helper()
+		noErr(t, err)
+		return v
+	}
+}
+
+func noErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func wantErr(t *testing.T, err, target error) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("got no error, want %v", target)
+	}
+	if target != nil && !errors.Is(err, target) {
+		t.Fatalf("got error %v, want %v", err, target)
+	}
+}
+
+func wantIDs[T any](t *testing.T, got []T, id func(T) int, want ...int) {
+	t.Helper()
+	ids := make([]int, len(got))
+	for i, v := range got {
+		ids[i] = id(v)
+	}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("got ids %v, want %v", ids, want)
+	}
+}
+
+func testUsers(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	john := must(r.GetUserByEmail(ctx, "john@example.com"))(t)
+	if john.ID != 2 || john.Name != "John Doe" || john.Role != "user" || john.Password == "" {
+		t.Fatalf("got user %+v, want John Doe with his password", john)
+	}
+	if u := must(r.GetUserById(ctx, 1))(t); u.Role != "admin" || u.Password != "" {
+		t.Fatalf("got user %+v, want the admin without password", u)
+	}
+	_, err := r.GetUserById(ctx, 999)
+	wantErr(t, err, nil)
+
+	id := must(r.CreateUser(ctx, models.User{Name: "Sam", Email: "sam@example.com", Password: "hash"}))(t)
+	if u := must(r.GetUserById(ctx, id))(t); u.Role != "user" {
+		t.Fatalf("got role %q, want the default role user", u.Role)
+	}
+	_, err = r.CreateUser(ctx, models.User{Name: "Sam", Email: "sam@example.com", Password: "hash"})
+	wantErr(t, err, nil)
+
+	noErr(t, r.UpdateUserPassword(ctx, id, "new-hash"))
+	if u := must(r.GetUserByEmail(ctx, "sam@example.com"))(t); u.Password != "new-hash" {
+		t.Fatalf("got password %q, want the new hash", u.Password)
+	}
+	wantErr(t, r.UpdateUserPassword(ctx, 999, "new-hash"), sql.ErrNoRows)
+}
+
+func testMenu(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	items := must(r.GetAllMenuItems(ctx))(t)
+	wantIDs(t, items, func(m models.MenuItem) int { return m.ID }, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13)
+
+	pizza := items[0]
+	if pizza.Price != 1299 || pizza.Category != "Pizza" || pizza.Images.Thumbnail != pizza.Images.Large {
+		t.Fatalf("got menu item %+v, want Margherita Pizza", pizza)
+	}
+	if !slices.Equal(pizza.Allergens, []models.Allergen{models.AllergenGluten, models.AllergenMilk}) ||
+		!slices.Equal(pizza.DietaryTags, []models.DietaryTag{models.DietaryTagVegetarian}) {
+		t.Fatalf("got allergens %v and tags %v", pizza.Allergens, pizza.DietaryTags)
+	}
+	if pizza.Nutrition == nil || *pizza.Nutrition.Kcal != 850 {
+		t.Fatalf("got nutrition %+v, want 850 kcal", pizza.Nutrition)
+	}
+	if beer := items[12]; beer.Allergens == nil || len(beer.Allergens) != 0 || beer.Nutrition != nil {
+		t.Fatalf("got %+v, want empty allergens and no nutrition", beer)
+	}
+
+	drinks := must(r.GetMenuItemsByCategory(ctx, "Drinks"))(t)
+	wantIDs(t, drinks, func(m models.MenuItem) int { return m.ID }, 11, 12)
+	_, err := r.GetMenuItemsByCategory(ctx, "Soups")
+	wantErr(t, err, nil)
+
+	_, err = r.GetMenuItemById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+	wantErr(t, r.UpdateMenuItemImages(ctx, 999, models.MenuImages{Large: "x"}), models.ErrNotFound)
+
+	images := models.MenuImages{Large: "large.jpg", Medium: "medium.jpg", Key: "menu/1"}
+	noErr(t, r.UpdateMenuItemImages(ctx, 1, images))
+	if got := must(r.GetMenuItemById(ctx, 1))(t).Images; got.Medium != "medium.jpg" || got.Thumbnail != "medium.jpg" {
+		t.Fatalf("got images %+v, want the thumbnail to fall back to medium", got)
+	}
+}
+
+func testPrices(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	prices := must(r.GetMenuItemPrices(ctx, 1))(t)
+	if len(prices) != 1 || prices[0].Price != 1299 {
+		t.Fatalf("got prices %+v, want the initial price", prices)
+	}
+
+	from := time.Now().Add(time.Hour)
+	id := must(r.CreateMenuItemPrice(ctx, models.MenuItemPrice{MenuItemID: 1, Price: 1399, EffectiveFrom: from}))(t)
+	_, err := r.CreateMenuItemPrice(ctx, models.MenuItemPrice{MenuItemID: 1, Price: 1499, EffectiveFrom: from})
+	wantErr(t, err, nil)
+
+	if at := must(r.GetMenuItemPricesAt(ctx, []int{1, 999}, from.Add(time.Minute)))(t); at[1] != 1399 || len(at) != 1 {
+		t.Fatalf("got prices %v, want the scheduled price of item 1 only", at)
+	}
+	if got := must(r.GetMenuItemById(ctx, 1))(t).Price; got != 1299 {
+		t.Fatalf("got price %d, want the scheduled price to wait", got)
+	}
+
+	wantErr(t, r.DeleteScheduledMenuItemPrice(ctx, 1, prices[0].ID, time.Now()), models.ErrNotFound)
+	noErr(t, r.DeleteScheduledMenuItemPrice(ctx, 1, id, time.Now()))
+	if got := must(r.GetMenuItemPrices(ctx, 1))(t); len(got) != 1 {
+		t.Fatalf("got %d prices, want the scheduled one deleted", len(got))
+	}
+}
+
+func testMenuImport(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	pizza := must(r.GetMenuItemById(ctx, 1))(t)
+	if pizza.SKU != "ITEM-1" || !pizza.Available {
+		t.Fatalf("got SKU %q and available %v, want ITEM-1 on the menu", pizza.SKU, pizza.Available)
+	}
+
+	stock := 20
+	soup := models.MenuItem{SKU: "SOUP-1", Name: "Tomato Soup", Category: "Soups", Price: 599, Available: true, Stock: &stock,
+		Allergens: []models.Allergen{models.AllergenCelery}, DietaryTags: []models.DietaryTag{models.DietaryTagVegan}}
+	pizza.Name, pizza.Price, pizza.Allergens = "Pizza Margherita", 1399, []models.Allergen{models.AllergenGluten}
+	beer := must(r.GetMenuItemById(ctx, 13))(t)
+	beer.Available = false
+	noErr(t, r.ImportMenu(ctx, []models.MenuImportChange{
+		{SKU: soup.SKU, Action: models.MenuImportCreate, Item: soup},
+		{SKU: pizza.SKU, Action: models.MenuImportUpdate, Fields: []string{"name", "price", "allergens"}, Item: pizza},
+		{SKU: beer.SKU, Action: models.MenuImportArchive, Fields: []string{"available"}, Item: beer},
+	}))
+
+	soups := must(r.GetMenuItemsByCategory(ctx, "Soups"))(t)
+	if len(soups) != 1 || soups[0].SKU != "SOUP-1" || soups[0].Price != 599 || soups[0].Stock == nil || *soups[0].Stock != 20 ||
+		!slices.Equal(soups[0].DietaryTags, soup.DietaryTags) || !soups[0].Available {
+		t.Fatalf("got %+v, want the imported soup", soups)
+	}
+	got := must(r.GetMenuItemById(ctx, 1))(t)
+	if got.Name != "Pizza Margherita" || got.Price != 1399 || !slices.Equal(got.Allergens, pizza.Allergens) ||
+		!slices.Equal(got.DietaryTags, []models.DietaryTag{models.DietaryTagVegetarian}) || got.Nutrition == nil {
+		t.Fatalf("got %+v, want the imported fields changed and the rest kept", got)
+	}
+	if at := must(r.GetMenuItemPricesAt(ctx, []int{1, 13}, time.Now()))(t); at[1] != 1399 || len(at) != 1 {
+		t.Fatalf("got prices %v, want the imported price and the archived item left out", at)
+	}
+	if must(r.GetMenuItemById(ctx, 13))(t).Available {
+		t.Fatalf("got the beer available, want it archived")
+	}
+	hits, _ := must2(r.SearchMenuItems(ctx, models.MenuSearchQuery{Text: "beer", Page: 1, Limit: 20}))(t)
+	if containsItems(hits, 13) {
+		t.Fatalf("got the archived beer in search hits")
+	}
+
+	// A failing change leaves the menu as it was
+	pizza.Name = "Renamed"
+	err := r.ImportMenu(ctx, []models.MenuImportChange{
+		{SKU: pizza.SKU, Action: models.MenuImportUpdate, Fields: []string{"name"}, Item: pizza},
+		{SKU: soup.SKU, Action: models.MenuImportCreate, Item: soup},
+	})
+	wantErr(t, err, nil)
+	if name := must(r.GetMenuItemById(ctx, 1))(t).Name; name != "Pizza Margherita" {
+		t.Fatalf("got name %q, want the failed import rolled back", name)
+	}
+}
+
+func testSearch(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+	search := func(text string, page, limit int) ([]models.MenuSearchHit, int) {
+		return must2(r.SearchMenuItems(ctx, models.MenuSearchQuery{Text: text, Page: page, Limit: limit}))(t)
+	}
+
+	hits, total := search("pizza", 1, 20)
+	if total != len(hits) || !containsItems(hits, 1, 5) {
+		t.Fatalf("got %d of %d hits, want both pizzas", len(hits), total)
+	}
+	if !strings.Contains(hits[0].Highlights.Name, models.HighlightStart) {
+		t.Fatalf("got name highlight %q, want a marked match", hits[0].Highlights.Name)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].Rank > hits[i-1].Rank {
+			t.Fatalf("got hits out of rank order: %v before %v", hits[i-1].Rank, hits[i].Rank)
+		}
+	}
+
+	page, pageTotal := search("pizza", 2, 1)
+	if pageTotal != total || len(page) != 1 || page[0].Item.ID != hits[1].Item.ID {
+		t.Fatalf("got the second page %+v of %d, want hit %d", page, pageTotal, hits[1].Item.ID)
+	}
+
+	if hits, _ := search("margarita", 1, 20); !containsItems(hits, 1) {
+		t.Fatal("got no typo match for margarita")
+	}
+	if hits, total := search("xylophone", 1, 20); hits == nil || len(hits) != 0 || total != 0 {
+		t.Fatalf("got %v hits of %d, want an empty list", hits, total)
+	}
+
+	// Highlights are HTML, so the item's own text comes back escaped
+	noErr(t, r.ImportMenu(ctx, []models.MenuImportChange{{SKU: "XSS-1", Action: models.MenuImportCreate, Item: models.MenuItem{
+		SKU: "XSS-1", Name: `Zucchini <img src=x onerror="alert(1)">`, Category: "Starters",
+		Description: "Zucchini & <b>dip</b>", Price: 499, Available: true,
+	}}}))
+	hits, _ = search("zucchini", 1, 20)
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits for zucchini, want 1", len(hits))
+	}
+	if h := hits[0].Highlights; h.Name != `<mark>Zucchini</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt;` ||
+		h.Description != "<mark>Zucchini</mark> &amp; &lt;b&gt;dip&lt;/b&gt;" {
+		t.Fatalf("got highlights %+v, want the text escaped and only the match marked", h)
+	}
+}
+
+func must2[A, B any](a A, b B, err error) func(t *testing.T) (A, B) {
+	return func(t *testing.T) (A, B) {
+		t.Helper()
+		noErr(t, err)
+		return a, b
+	}
+}
+
+func containsItems(hits []models.MenuSearchHit, ids ...int) bool {
+	for _, id := range ids {
+		if !slices.ContainsFunc(hits, func(h models.MenuSearchHit) bool { return h.Item.ID == id }) {
+			return false
+		}
+	}
+	return true
+}
+
+func testTables(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	tables := must(r.GetAllTables(ctx))(t)
+	wantIDs(t, tables, func(t models.Table) int { return t.ID }, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	if tables[9].Name != "Private Room" || tables[9].Seats != 12 {
+		t.Fatalf("got table %+v, want the private room", tables[9])
+	}
+
+	reserved := must(r.GetTablesByStatus(ctx, models.TableStatusReserved))(t)
+	wantIDs(t, reserved, func(t models.Table) int { return t.ID }, 3, 5, 8)
+
+	_, err := r.GetTableById(ctx, 999)
+	wantErr(t, err, nil)
+	_, err = r.CreateTable(ctx, models.Table{Name: "Table 1", Seats: 2, Status: models.TableStatusAvailable})
+	wantErr(t, err, nil)
+
+	completed := must(r.ResetTable(ctx, 3))(t)
+	if len(completed) != 0 {
+		t.Fatalf("got completed orders %v, want none", completed)
+	}
+	if table := must(r.GetTableById(ctx, 3))(t); table.Status != models.TableStatusAvailable {
+		t.Fatalf("got status %q, want the table freed", table.Status)
+	}
+}
+
+func testOrders(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	order := must(r.GetOrderById(ctx, 1))(t)
+	if order.UserID != 2 || order.TotalPrice != 2448 || order.Status != models.OrderStatusCompleted || len(order.Items) != 2 {
+		t.Fatalf("got order %+v, want John's first order", order)
+	}
+	_, err := r.GetOrderById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+
+	wantIDs(t, must(r.GetAllOrders(ctx))(t), func(o models.Order) int { return o.ID }, 1, 2, 3, 4)
+	wantIDs(t, must(r.GetOrdersByUser(ctx, 2))(t), func(o models.Order) int { return o.ID }, 1, 3)
+	if o := must(r.GetOrderByTableId(ctx, 2))(t); o.ID != 2 {
+		t.Fatalf("got order %d for table 2, want 2", o.ID)
+	}
+	_, err = r.GetOrderByTableId(ctx, 6)
+	wantErr(t, err, nil)
+
+	id := must(r.CreateOrder(ctx, models.Order{
+		UserID:     3,
+		TableID:    6,
+		Status:     models.OrderStatusPending,
+		Items:      []models.OrderItem{{MenuItemID: 11, Quantity: 2, Price: 399}},
+		Subtotal:   798,
+		TotalPrice: 798,
+		Guests:     2,
+	}))(t)
+	created := must(r.GetOrderById(ctx, id))(t)
+	if len(created.Items) != 1 || created.Items[0].ID == 0 || created.Guests != 2 || created.Discounts == nil {
+		t.Fatalf("got order %+v, want it as created", created)
+	}
+	_, err = r.CreateOrder(ctx, models.Order{UserID: 3, TableID: 999, Status: models.OrderStatusPending})
+	wantErr(t, err, nil)
+
+	noErr(t, r.UpdateOrderStatus(ctx, 2, models.OrderStatusCancelled))
+	if completed := must(r.ResetTable(ctx, 6))(t); !slices.Equal(completed, []int{id}) {
+		t.Fatalf("got completed orders %v, want [%d]", completed, id)
+	}
+	if o := must(r.GetOrderById(ctx, id))(t); o.Status != models.OrderStatusCompleted {
+		t.Fatalf("got status %q, want the order completed by the reset", o.Status)
+	}
+}
+
+func testListOrders(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	// The sample orders share their creation time; these share totals with
+	// them and each other, and are created later
+	time.Sleep(time.Millisecond)
+	for _, o := range []struct {
+		userId int
+		status models.OrderStatus
+		total  int
+	}{
+		{3, models.OrderStatusPending, 1499},
+		{2, models.OrderStatusPending, 1499},
+		{3, models.OrderStatusCompleted, 2448},
+		{2, models.OrderStatusPending, 1499},
+	} {
+		must(r.CreateOrder(ctx, models.Order{
+			UserID: o.userId, TableID: 6, Status: o.status,
+			Items:    []models.OrderItem{{MenuItemID: 11, Quantity: 1, Price: o.total}},
+			Subtotal: o.total, TotalPrice: o.total, Guests: 1,
+		}))(t)
+	}
+
+	placedAt := func(o models.Order) time.Time {
+		at, err := time.Parse(time.RFC3339Nano, o.CreatedAt)
+		if err != nil {
+			t.Fatalf("got creation time %q of order %d: %v", o.CreatedAt, o.ID, err)
+		}
+		return at
+	}
+	all := must(r.GetAllOrders(ctx))(t)
+	first, last := placedAt(all[0]), placedAt(all[len(all)-1])
+	if !first.Before(last) {
+		t.Fatalf("got the created orders placed at %v, want after the sample orders at %v", last, first)
+	}
+
+	// want filters and sorts the orders by the rules every driver follows
+	want := func(query models.OrderListQuery) []int {
+		var orders []models.Order
+		for _, o := range all {
+			if query.Matches(o, placedAt(o)) {
+				orders = append(orders, o)
+			}
+		}
+		slices.SortFunc(orders, func(a, b models.Order) int {
+			c := placedAt(a).Compare(placedAt(b))
+			if query.SortBy == models.OrderSortTotal {
+				c = a.TotalPrice - b.TotalPrice
+			}
+			if c == 0 {
+				c = a.ID - b.ID
+			}
+			if query.Desc {
+				return -c
+			}
+			return c
+		})
+		ids := []int{}
+		for _, o := range orders {
+			ids = append(ids, o.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name  string
+		query models.OrderListQuery
+	}{
+		{"oldest first", models.OrderListQuery{SortBy: models.OrderSortCreatedAt}},
+		{"newest first", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, Desc: true}},
+		{"cheapest first", models.OrderListQuery{SortBy: models.OrderSortTotal}},
+		{"dearest first", models.OrderListQuery{SortBy: models.OrderSortTotal, Desc: true}},
+		{"status", models.OrderListQuery{SortBy: models.OrderSortTotal, Status: models.OrderStatusPending}},
+		{"user", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, Desc: true, UserID: 2}},
+		{"user and status", models.OrderListQuery{SortBy: models.OrderSortTotal, Desc: true, UserID: 3, Status: models.OrderStatusPending}},
+		{"from", models.OrderListQuery{SortBy: models.OrderSortTotal, From: last}},
+		{"to", models.OrderListQuery{SortBy: models.OrderSortTotal, To: last}},
+		{"from and to", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, From: first, To: last.Add(time.Microsecond)}},
+		{"before everything", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, To: first}},
+		{"minimum total", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, MinTotal: 2198}},
+		{"no match", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, Status: models.OrderStatusRefunded}},
+	}
+	for _, tt := range tests {
+		wantIDs := want(tt.query)
+		// Follow the cursor to the end in pages of every size
+		for limit := 1; limit <= len(all)+1; limit++ {
+			query := tt.query
+			query.Limit = limit
+			var got []int
+			for pages := 0; ; pages++ {
+				if pages > len(all)+1 {
+					t.Fatalf("%s: got no end to the listing in pages of %d", tt.name, limit)
+				}
+				orders, total := must2(r.ListOrders(ctx, query))(t)
+				if total != len(wantIDs) {
+					t.Fatalf("%s: got a total of %d on page %d, want %d", tt.name, total, pages+1, len(wantIDs))
+				}
+				if len(orders) > limit {
+					t.Fatalf("%s: got %d orders, want at most %d", tt.name, len(orders), limit)
+				}
+				if len(orders) == 0 {
+					break
+				}
+				for _, o := range orders {
+					got = append(got, o.ID)
+				}
+				last := orders[len(orders)-1]
+				after := query.CursorAfter(last, placedAt(last))
+				query.After = &after
+			}
+			if !slices.Equal(got, wantIDs) {
+				t.Fatalf("%s: got %v in pages of %d, want %v", tt.name, got, limit, wantIDs)
+			}
+		}
+	}
+
+	page, _ := must2(r.ListOrders(ctx, models.OrderListQuery{SortBy: models.OrderSortCreatedAt, Limit: 1}))(t)
+	if len(page) != 1 || len(page[0].Items) == 0 {
+		t.Fatalf("got %+v, want the order with its items", page)
+	}
+}
+
+func testPromotions(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	wantIDs(t, must(r.GetAllPromotions(ctx))(t), func(p models.Promotion) int { return p.ID }, 1, 2, 3, 4)
+	wantIDs(t, must(r.GetAutomaticPromotions(ctx))(t), func(p models.Promotion) int { return p.ID }, 1, 2)
+
+	welcome := must(r.GetPromotionByCode(ctx, "WELCOME10"))(t)
+	if welcome.ID != 3 || welcome.PerUserLimit != 1 || welcome.Stackable {
+		t.Fatalf("got promotion %+v, want WELCOME10", welcome)
+	}
+	if happy := must(r.GetPromotionById(ctx, 1))(t); happy.DailyStart != "17:00" || happy.DailyEnd != "19:00" {
+		t.Fatalf("got daily window %s-%s, want 17:00-19:00", happy.DailyStart, happy.DailyEnd)
+	}
+	_, err := r.GetPromotionByCode(ctx, "NOPE")
+	wantErr(t, err, models.ErrCouponNotFound)
+	wantErr(t, r.SetPromotionActive(ctx, 999, false), models.ErrNotFound)
+
+	discount := models.OrderDiscount{PromotionID: 3, Name: welcome.Name, Code: welcome.Code, Amount: 300}
+	order := models.Order{
+		UserID: 3, TableID: 6, Status: models.OrderStatusPending,
+		Items:    []models.OrderItem{{MenuItemID: 3, Quantity: 2, Price: 1499}},
+		Subtotal: 2998, DiscountTotal: 300, TotalPrice: 2698, Discounts: []models.OrderDiscount{discount},
+	}
+	must(r.CreateOrder(ctx, order))(t)
+	usage := must(r.GetPromotionUsage(ctx, []int{3, 4}, 3, 0))(t)
+	if usage[3].Total != 1 || usage[3].ForUser != 1 || usage[4].Total != 0 {
+		t.Fatalf("got usage %+v, want WELCOME10 used once by user 3", usage)
+	}
+	_, err = r.CreateOrder(ctx, order)
+	wantErr(t, err, models.ErrPromotionLimitReached)
+}
+
+func testTaxes(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	rates := must(r.GetTaxRates(ctx))(t)
+	wantIDs(t, rates, func(r models.TaxRate) int { return r.ID }, 1, 2)
+	if !rates[0].Default || rates[0].Rate != 500 || len(rates[0].Categories) != 0 {
+		t.Fatalf("got tax rate %+v, want the default food rate", rates[0])
+	}
+	if !slices.Equal(rates[1].Categories, []string{"Alcohol"}) || rates[1].Rate != 2000 {
+		t.Fatalf("got tax rate %+v, want alcohol", rates[1])
+	}
+
+	// Lines keep the part of their price taxed at each rate
+	lineTaxes := []models.LineTax{{TaxRateID: 1, Amount: 500}, {TaxRateID: 2, Amount: 298}}
+	id := must(r.CreateOrder(ctx, models.Order{
+		UserID: 3, TableID: 6, Status: models.OrderStatusPending,
+		Items:    []models.OrderItem{{MenuItemID: 11, Quantity: 2, Price: 399, Taxes: lineTaxes}},
+		Subtotal: 798, TaxTotal: 85, TotalPrice: 883,
+		Taxes: []models.OrderTax{{TaxRateID: 1, Name: "Food", Rate: 500, Taxable: 500, Amount: 25}, {TaxRateID: 2, Name: "Alcohol", Rate: 2000, Taxable: 298, Amount: 60}},
+	}))(t)
+	order := must(r.GetOrderById(ctx, id))(t)
+	if !slices.Equal(order.Items[0].Taxes, lineTaxes) {
+		t.Fatalf("got line taxes %+v, want %+v", order.Items[0].Taxes, lineTaxes)
+	}
+	order.Items[0].Taxes = []models.LineTax{{TaxRateID: 1, Amount: 798}}
+	order.Taxes = []models.OrderTax{{TaxRateID: 1, Name: "Food", Rate: 500, Taxable: 798, Amount: 40}}
+	noErr(t, r.UpdateOrderTotals(ctx, order))
+	if got := must(r.GetOrderById(ctx, id))(t); !slices.Equal(got.Items[0].Taxes, order.Items[0].Taxes) || len(got.Taxes) != 1 {
+		t.Fatalf("got line taxes %+v and taxes %+v, want them replaced", got.Items[0].Taxes, got.Taxes)
+	}
+
+	must(r.CreateTaxRate(ctx, models.TaxRate{Name: "Zero", Rate: 0, Default: true, Categories: []string{"Alcohol"}}))(t)
+	rates = must(r.GetTaxRates(ctx))(t)
+	if rates[0].Default || !rates[2].Default || len(rates[1].Categories) != 0 {
+		t.Fatalf("got tax rates %+v, want the new rate to take the default and the category", rates)
+	}
+}
+
+func testBundles(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	bundle := must(r.GetBundleById(ctx, 1))(t)
+	if bundle.Name != "Pizza Meal Deal" || bundle.Price != 1800 || len(bundle.Slots) != 3 {
+		t.Fatalf("got bundle %+v, want the pizza meal deal", bundle)
+	}
+	if !slices.Equal(bundle.Slots[1].MenuItemIDs, []int{11, 12}) || bundle.Slots[0].Category != "Pizza" {
+		t.Fatalf("got slots %+v", bundle.Slots)
+	}
+	_, err := r.GetBundleById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+	wantIDs(t, must(r.GetAllBundles(ctx))(t), func(b models.Bundle) int { return b.ID }, 1)
+}
+
+func testChecks(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	// Order 3 is pending at 21.98; its checks give tips of 2.00 and 1.50
+	order := must(r.GetOrderById(ctx, 3))(t)
+	order.Tip, order.TotalPrice = 350, 2548
+	checks := []models.Check{
+		{Number: 1, Label: "Check 1", Mode: models.SplitEvenly, Subtotal: 1099, Tip: 200, TotalPrice: 1299, Taxes: []models.OrderTax{}},
+		{Number: 2, Label: "Check 2", Mode: models.SplitEvenly, Subtotal: 1099, Tip: 150, TotalPrice: 1249, Taxes: []models.OrderTax{}},
+	}
+	noErr(t, r.CreateOrderChecks(ctx, order, checks))
+	got := must(r.GetOrderChecks(ctx, 3))(t)
+	if len(got) != 2 || got[0].Tip != 200 || got[1].TotalPrice != 1249 {
+		t.Fatalf("got checks %+v", got)
+	}
+	if o := must(r.GetOrderById(ctx, 3))(t); o.Tip != 350 || o.TotalPrice != 2548 {
+		t.Fatalf("got tip %d and total %d, want the tips of the checks", o.Tip, o.TotalPrice)
+	}
+
+	// Merging keeps the tips the checks were given on the order
+	noErr(t, r.DeleteOrderChecks(ctx, 3))
+	if got := must(r.GetOrderChecks(ctx, 3))(t); len(got) != 0 {
+		t.Fatalf("got %d checks after merging", len(got))
+	}
+	if o := must(r.GetOrderById(ctx, 3))(t); o.Tip != 350 || o.TotalPrice != 2548 {
+		t.Fatalf("got tip %d and total %d after merging, want 350 and 2548", o.Tip, o.TotalPrice)
+	}
+	// and with nothing to merge leaves the order alone
+	noErr(t, r.DeleteOrderChecks(ctx, 3))
+	if o := must(r.GetOrderById(ctx, 3))(t); o.Tip != 350 || o.TotalPrice != 2548 {
+		t.Fatalf("got tip %d and total %d after merging again", o.Tip, o.TotalPrice)
+	}
+
+	wantErr(t, r.DeleteOrderChecks(ctx, 1), models.ErrInvalidInput) // completed
+	wantErr(t, r.DeleteOrderChecks(ctx, 999), models.ErrNotFound)
+}
+
+func testPayments(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	payment := models.Payment{
+		OrderID: 2, Provider: "cash", Status: models.PaymentStatusPending,
+		Amount: 1499, Currency: "usd", IdempotencyKey: "key-1", CreatedBy: 1,
+	}
+	id := must(r.CreatePayment(ctx, payment))(t)
+	_, err := r.CreatePayment(ctx, payment)
+	wantErr(t, err, nil)
+
+	// The pending payment holds the whole balance, so a second one started
+	// alongside it cannot take it too
+	_, err = r.CreatePayment(ctx, models.Payment{OrderID: 2, Provider: "cash", Status: models.PaymentStatusPending, Amount: 1, Currency: "usd"})
+	wantErr(t, err, models.ErrPaymentConflict)
+	_, err = r.CreatePayment(ctx, models.Payment{OrderID: 1, Provider: "cash", Status: models.PaymentStatusPending, Amount: 1, Currency: "usd"})
+	wantErr(t, err, models.ErrPaymentNotAllowed)
+	if p := must(r.GetPaymentByIdempotencyKey(ctx, 2, "key-1"))(t); p.ID != id {
+		t.Fatalf("got payment %d, want %d", p.ID, id)
+	}
+	_, err = r.GetPaymentById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+
+	payment.ID, payment.Status, payment.Captured = id, models.PaymentStatusCaptured, 1499
+	wantErr(t, r.UpdatePayment(ctx, payment, models.PaymentStatusAuthorized), models.ErrPaymentConflict)
+	noErr(t, r.UpdatePayment(ctx, payment, models.PaymentStatusPending))
+	if p := must(r.GetPaymentById(ctx, id))(t); p.Status != models.PaymentStatusCaptured || p.Captured != 1499 {
+		t.Fatalf("got payment %+v, want it captured", p)
+	}
+
+	// Money is taken, so the bill can no longer be split
+	check := models.Check{Number: 1, Label: "All", Mode: models.SplitEvenly, TotalPrice: 1499}
+	wantErr(t, r.CreateOrderChecks(ctx, models.Order{ID: 2, TotalPrice: 1499}, []models.Check{check}), models.ErrInvalidInput)
+
+	event := models.PaymentWebhookEvent{Provider: "stripe", EventID: "evt_1", Type: "payment_intent.succeeded", Payload: []byte("{}")}
+	if processed := must(r.RecordWebhookEvent(ctx, event))(t); processed {
+		t.Fatal("got a new event processed")
+	}
+	noErr(t, r.MarkWebhookEventProcessed(ctx, "stripe", "evt_1"))
+	if processed := must(r.RecordWebhookEvent(ctx, event))(t); !processed {
+		t.Fatal("got a processed event unprocessed")
+	}
+}
+
+func testInvoices(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	_, err := r.GetInvoiceByOrder(ctx, 1)
+	wantErr(t, err, models.ErrNotFound)
+
+	first := must(r.CreateInvoice(ctx, models.Invoice{OrderID: 1, FiscalYear: 2030, CustomerName: "John Doe"}))(t)
+	if first.Number != models.InvoiceNumber(2030, 1) {
+		t.Fatalf("got invoice number %s, want the first of 2030", first.Number)
+	}
+	if again := must(r.CreateInvoice(ctx, models.Invoice{OrderID: 1, FiscalYear: 2030, CustomerName: "John Doe"}))(t); again.Number != first.Number {
+		t.Fatalf("got invoice %s, want the existing %s", again.Number, first.Number)
+	}
+	if second := must(r.CreateInvoice(ctx, models.Invoice{OrderID: 4, FiscalYear: 2030, CustomerName: "Manager"}))(t); second.Sequence != 2 {
+		t.Fatalf("got sequence %d, want 2", second.Sequence)
+	}
+	if got := must(r.GetInvoiceByOrder(ctx, 1))(t); got.Number != first.Number {
+		t.Fatalf("got invoice %s, want %s", got.Number, first.Number)
+	}
+
+	// A new fiscal year starts from one again
+	next := must(r.CreateInvoice(ctx, models.Invoice{OrderID: 3, FiscalYear: 2031, CustomerName: "John Doe"}))(t)
+	if next.Sequence != 1 || next.Number != models.InvoiceNumber(2031, 1) {
+		t.Fatalf("got invoice %s, want the first of 2031", next.Number)
+	}
+	if back := must(r.CreateInvoice(ctx, models.Invoice{OrderID: 2, FiscalYear: 2030, CustomerName: "Jane Doe"}))(t); back.Number != models.InvoiceNumber(2030, 3) {
+		t.Fatalf("got invoice %s, want the third of 2030", back.Number)
+	}
+}
+
+func testKitchen(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	routing := must(r.GetStationRouting(ctx))(t)
+	if routing.DefaultStationID != 1 || routing.Categories["drinks"] != 5 || routing.Categories["burgers"] != 2 {
+		t.Fatalf("got routing %+v, want the sample routes", routing)
+	}
+	if grill := must(r.GetStationById(ctx, 2))(t); grill.Name != "grill" || !slices.Equal(grill.Categories, []string{"burgers"}) {
+		t.Fatalf("got station %+v, want the grill", grill)
+	}
+	_, err := r.GetStationById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+
+	order := must(r.GetOrderById(ctx, 3))(t)
+	tickets := []models.KitchenTicket{
+		{StationID: 1, Items: []models.TicketItem{{OrderItemID: order.Items[0].ID, MenuItemID: 5, Quantity: 1}}},
+		{StationID: 4, Items: []models.TicketItem{{OrderItemID: order.Items[1].ID, MenuItemID: 4, Quantity: 1}}},
+	}
+	noErr(t, r.CreateKitchenTickets(ctx, 3, tickets))
+	noErr(t, r.CreateKitchenTickets(ctx, 3, tickets))
+	if o := must(r.GetOrderById(ctx, 3))(t); o.KitchenStatus != models.KitchenStatusQueued {
+		t.Fatalf("got kitchen status %q, want queued", o.KitchenStatus)
+	}
+
+	created := must(r.GetKitchenTicketsByOrder(ctx, 3))(t)
+	wantIDs(t, created, func(k models.KitchenTicket) int { return k.StationID }, 1, 4)
+	pastry := created[1]
+	if pastry.Station != "pastry" || pastry.Table != "Table 4" || len(pastry.Items) != 1 || pastry.Items[0].Name != "Chocolate Brownie" {
+		t.Fatalf("got ticket %+v, want the brownie at the pastry station", pastry)
+	}
+	open := must(r.GetStationTickets(ctx, 4, models.TicketStatusOpen, 10))(t)
+	wantIDs(t, open, func(k models.KitchenTicket) int { return k.ID }, pastry.ID)
+
+	ticketID, changed, err := r.SetTicketItemStatus(ctx, pastry.Items[0].ID, models.TicketItemReady)
+	if err != nil || ticketID != pastry.ID || !changed {
+		t.Fatalf("got ticket %d, changed %v, error %v; want the pastry ticket to change the order", ticketID, changed, err)
+	}
+	if o := must(r.GetOrderById(ctx, 3))(t); o.KitchenStatus != models.KitchenStatusPreparing {
+		t.Fatalf("got kitchen status %q, want preparing", o.KitchenStatus)
+	}
+	if done := must(r.GetKitchenTicketById(ctx, pastry.ID))(t); done.Status != models.TicketStatusDone || done.BumpedAt == "" {
+		t.Fatalf("got ticket %+v, want it done", done)
+	}
+
+	if changed := must(r.SetTicketStatus(ctx, created[0].ID, models.TicketStatusDone))(t); !changed {
+		t.Fatal("got the order unchanged, want it ready")
+	}
+	if o := must(r.GetOrderById(ctx, 3))(t); o.KitchenStatus != models.KitchenStatusReady {
+		t.Fatalf("got kitchen status %q, want ready", o.KitchenStatus)
+	}
+	if changed := must(r.SetTicketStatus(ctx, created[0].ID, models.TicketStatusDone))(t); changed {
+		t.Fatal("got a change from bumping a done ticket again")
+	}
+	_, err = r.GetKitchenTicketById(ctx, 999)
+	wantErr(t, err, models.ErrNotFound)
+}
+
+func testWebhooks(t *testing.T, r interfaces.Repository) {
+	ctx := context.Background()
+
+	if endpoints := must(r.GetWebhookEndpoints(ctx))(t); len(endpoints) != 0 {
+		t.Fatalf("got %d endpoints, want none", len(endpoints))
+	}
+	all := must(r.CreateWebhookEndpoint(ctx, models.WebhookEndpoint{URL: "https://example.com/all", Secret: "s3cret", EventTypes: []string{}, Active: true}))(t)
+	tables := must(r.CreateWebhookEndpoint(ctx, models.WebhookEndpoint{URL: "https://example.com/tables", Secret: "other",
+		EventTypes: []string{models.OutboxTableReset}, Active: true}))(t)
+	if e := must(r.GetWebhookEndpointById(ctx, all))(t); e.Secret != "" || e.EventTypes == nil {
+		t.Fatalf("got endpoint %+v, want no secret and empty event types", e)
+	}
+	wantErr(t, r.SetWebhookEndpointActive(ctx, 999, false), models.ErrNotFound)
+
+	noErr(t, r.UpdateOrderStatus(ctx, 2, models.OrderStatusCancelled))
+	if n := must(r.DispatchOutbox(ctx, 100))(t); n != 1 {
+		t.Fatalf("got %d events dispatched, want 1", n)
+	}
+	if n := must(r.DispatchOutbox(ctx, 100))(t); n != 0 {
+		t.Fatalf("got %d events dispatched again, want 0", n)
+	}
+
+	claimed := must(r.ClaimWebhookDeliveries(ctx, 10, time.Minute))(t)
+	if len(claimed) != 1 || claimed[0].EndpointID != all || claimed[0].Secret != "s3cret" || claimed[0].Event.Type != models.OutboxOrderStatusChanged {
+		t.Fatalf("got deliveries %+v, want the status change for the catch-all endpoint", claimed)
+	}
+	if again := must(r.ClaimWebhookDeliveries(ctx, 10, time.Minute))(t); len(again) != 0 {
+		t.Fatalf("got %d deliveries claimed twice", len(again))
+	}
+
+	d := claimed[0]
+	noErr(t, r.RecordWebhookAttempt(ctx, d.ID, models.WebhookAttempt{Attempt: 1, StatusCode: 500, Error: "HTTP 500"}, models.WebhookDeliveryRetrying, time.Hour))
+	noErr(t, r.RetryWebhookDelivery(ctx, d.ID))
+	noErr(t, r.RecordWebhookAttempt(ctx, d.ID, models.WebhookAttempt{Attempt: 1, StatusCode: 200}, models.WebhookDeliveryDelivered, 0))
+	wantErr(t, r.RetryWebhookDelivery(ctx, d.ID), models.ErrInvalidInput)
+	wantErr(t, r.RetryWebhookDelivery(ctx, 999), models.ErrNotFound)
+
+	delivered := must(r.GetWebhookDeliveries(ctx, all, models.WebhookDeliveryDelivered, 10))(t)
+	if len(delivered) != 1 || len(delivered[0].Log) != 2 || delivered[0].DeliveredAt == "" || delivered[0].NextAttemptAt != "" {
+		t.Fatalf("got deliveries %+v, want one delivered after two attempts", delivered)
+	}
+
+	completed := must(r.ResetTable(ctx, 4))(t)
+	must(r.DispatchOutbox(ctx, 100))(t)
+	reset := must(r.GetWebhookDeliveries(ctx, tables, "", 10))(t)
+	if len(completed) != 1 || len(reset) != 1 || reset[0].Event.Type != models.OutboxTableReset {
+		t.Fatalf("got deliveries %+v, want only the table reset for the table endpoint", reset)
+	}
+}
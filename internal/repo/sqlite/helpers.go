@@ -0,0 +1,67 @@
+package sqliterepo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayout is how timestamps are stored. It is fixed width, in UTC, so
+// stored times compare correctly as text.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+// now is the current time in SQL, in timeLayout.
+const now = `strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')`
+
+// timestamp formats t for a timestamp column.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTimestamp reads a timestamp column back.
+func parseTimestamp(s string) (time.Time, error) {
+	return time.Parse(timeLayout, s)
+}
+
+// nullTimestamp formats an optional time, storing nil as NULL.
+func nullTimestamp(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: timestamp(*t), Valid: true}
+}
+
+// nullIfZero stores zero values as NULL, for optional integer columns.
+func nullIfZero(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
+
+// parseIDList parses a comma separated list of ids as produced by
+// group_concat.
+func parseIDList(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]int, len(parts))
+	for i, part := range parts {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// idList encodes ids as a JSON array for use with
+// IN (SELECT value FROM json_each(?1)).
+func idList[T int | int64](ids []T) string {
+	if ids == nil {
+		ids = []T{}
+	}
+	b, _ := json.Marshal(ids)
+	return string(b)
+}
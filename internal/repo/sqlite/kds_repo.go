@@ -0,0 +1,416 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Station operations
+func (r *repository) CreateStation(ctx context.Context, station models.Station) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only one station can be the default
+	if station.IsDefault {
+		if _, err := tx.ExecContext(ctx, `UPDATE kitchen_stations SET is_default = 0 WHERE is_default`); err != nil {
+			return 0, fmt.Errorf("failed to unset default station: %w", err)
+		}
+	}
+	var id int
+	err = tx.QueryRowContext(ctx, `INSERT INTO kitchen_stations (name, is_default) VALUES (?1, ?2)
+		ON CONFLICT (name) DO NOTHING RETURNING id`, station.Name, station.IsDefault).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: station %q already exists", models.ErrInvalidInput, station.Name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create station: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit station: %w", err)
+	}
+	return id, nil
+}
+
+func (r *repository) GetStations(ctx context.Context) ([]models.Station, error) {
+	query := `SELECT s.id, s.name, s.is_default, s.created_at,
+			coalesce(group_concat(c.category, ',' ORDER BY c.category), '')
+		FROM kitchen_stations s
+		LEFT JOIN kitchen_category_stations c ON c.station_id = s.id
+		GROUP BY s.id ORDER BY s.id`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stations: %w", err)
+	}
+	defer rows.Close()
+
+	stations := make([]models.Station, 0)
+	for rows.Next() {
+		var (
+			s          models.Station
+			categories string
+		)
+		if err := rows.Scan(&s.ID, &s.Name, &s.IsDefault, &s.CreatedAt, &categories); err != nil {
+			return nil, fmt.Errorf("failed to scan station: %w", err)
+		}
+		s.Categories = []string{}
+		if categories != "" {
+			s.Categories = strings.Split(categories, ",")
+		}
+		stations = append(stations, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over stations: %w", err)
+	}
+	return stations, nil
+}
+
+func (r *repository) GetStationById(ctx context.Context, id int) (models.Station, error) {
+	stations, err := r.GetStations(ctx)
+	if err != nil {
+		return models.Station{}, err
+	}
+	for _, s := range stations {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return models.Station{}, fmt.Errorf("station not found with id %d: %w", id, models.ErrNotFound)
+}
+
+// SetCategoryStation routes a menu category to a station, or removes its
+// route when stationId is zero.
+func (r *repository) SetCategoryStation(ctx context.Context, category string, stationId int) error {
+	category = strings.ToLower(category)
+	if stationId == 0 {
+		if _, err := r.DB.ExecContext(ctx, `DELETE FROM kitchen_category_stations WHERE category = ?1`, category); err != nil {
+			return fmt.Errorf("failed to remove category route: %w", err)
+		}
+		return nil
+	}
+	query := `INSERT INTO kitchen_category_stations (category, station_id) VALUES (?1, ?2)
+		ON CONFLICT (category) DO UPDATE SET station_id = EXCLUDED.station_id`
+	if _, err := r.DB.ExecContext(ctx, query, category, stationId); err != nil {
+		return fmt.Errorf("failed to route category: %w", err)
+	}
+	return nil
+}
+
+// SetMenuItemStation routes one menu item to a station, or back to its
+// category's when stationId is zero.
+func (r *repository) SetMenuItemStation(ctx context.Context, itemId, stationId int) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE menu_items SET station_id = ?1 WHERE id = ?2`, nullIfZero(stationId), itemId)
+	if err != nil {
+		return fmt.Errorf("failed to route menu item: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("menu item not found with id %d: %w", itemId, models.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *repository) GetStationRouting(ctx context.Context) (models.StationRouting, error) {
+	routing := models.StationRouting{
+		Categories: make(map[string]int),
+		Items:      make(map[int]int),
+	}
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM kitchen_stations WHERE is_default`).Scan(&routing.DefaultStationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StationRouting{}, fmt.Errorf("no default kitchen station: %w", models.ErrNotFound)
+	}
+	if err != nil {
+		return models.StationRouting{}, fmt.Errorf("failed to get default station: %w", err)
+	}
+
+	query := `SELECT 'category', category, 0, station_id FROM kitchen_category_stations
+		UNION ALL
+		SELECT 'item', '', id, station_id FROM menu_items WHERE station_id IS NOT NULL`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return models.StationRouting{}, fmt.Errorf("failed to get station routes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			kind, category    string
+			itemID, stationID int
+		)
+		if err := rows.Scan(&kind, &category, &itemID, &stationID); err != nil {
+			return models.StationRouting{}, fmt.Errorf("failed to scan station route: %w", err)
+		}
+		if kind == "item" {
+			routing.Items[itemID] = stationID
+		} else {
+			routing.Categories[category] = stationID
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return models.StationRouting{}, fmt.Errorf("failed to iterate over station routes: %w", err)
+	}
+	return routing, nil
+}
+
+// Kitchen ticket operations
+
+// CreateKitchenTickets stores the tickets of an order and queues it in the
+// kitchen. An order that already has tickets keeps them.
+func (r *repository) CreateKitchenTickets(ctx context.Context, orderId int, tickets []models.KitchenTicket) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var kitchenStatus sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT kitchen_status FROM orders WHERE id = ?1`, orderId).Scan(&kitchenStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	if kitchenStatus.Valid {
+		return nil
+	}
+
+	for _, ticket := range tickets {
+		var ticketID int
+		err := tx.QueryRowContext(ctx, `INSERT INTO kitchen_tickets (order_id, station_id) VALUES (?1, ?2) RETURNING id`,
+			orderId, ticket.StationID).Scan(&ticketID)
+		if err != nil {
+			return fmt.Errorf("failed to create kitchen ticket: %w", err)
+		}
+		for _, item := range ticket.Items {
+			_, err := tx.ExecContext(ctx, `INSERT INTO kitchen_ticket_items (ticket_id, order_item_id, menu_item_id, bundle_id, quantity)
+				VALUES (?1, ?2, ?3, ?4, ?5)`, ticketID, item.OrderItemID, item.MenuItemID, item.BundleID, item.Quantity)
+			if err != nil {
+				return fmt.Errorf("failed to insert kitchen ticket item: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET kitchen_status = 'queued' WHERE id = ?1`, orderId); err != nil {
+		return fmt.Errorf("failed to queue order in the kitchen: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit kitchen tickets: %w", err)
+	}
+	return nil
+}
+
+const kitchenTicketColumns = `t.id, t.order_id, t.station_id, s.name, t.status, coalesce(tb.name, 'Takeaway'), o.guests,
+	o.created_at, t.created_at, t.bumped_at`
+
+const kitchenTicketSource = `kitchen_tickets t
+	JOIN kitchen_stations s ON s.id = t.station_id
+	JOIN orders o ON o.id = t.order_id
+	LEFT JOIN tables tb ON tb.id = o.table_id`
+
+func (r *repository) queryKitchenTickets(ctx context.Context, where string, args ...any) ([]models.KitchenTicket, error) {
+	query := `SELECT ` + kitchenTicketColumns + ` FROM ` + kitchenTicketSource + ` ` + where
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kitchen tickets: %w", err)
+	}
+	defer rows.Close()
+
+	tickets := make([]models.KitchenTicket, 0)
+	for rows.Next() {
+		var (
+			t        models.KitchenTicket
+			bumpedAt sql.NullString
+		)
+		err := rows.Scan(&t.ID, &t.OrderID, &t.StationID, &t.Station, &t.Status, &t.Table, &t.Guests,
+			&t.OrderedAt, &t.CreatedAt, &bumpedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan kitchen ticket: %w", err)
+		}
+		t.BumpedAt = bumpedAt.String
+		t.Items = []models.TicketItem{}
+		tickets = append(tickets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over kitchen tickets: %w", err)
+	}
+	if err := r.attachTicketItems(ctx, tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+// attachTicketItems loads the items of the given tickets with their names,
+// bundles and notes.
+func (r *repository) attachTicketItems(ctx context.Context, tickets []models.KitchenTicket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.KitchenTicket, len(tickets))
+	ids := make([]int, len(tickets))
+	for i := range tickets {
+		byID[tickets[i].ID] = &tickets[i]
+		ids[i] = tickets[i].ID
+	}
+
+	query := `SELECT i.ticket_id, i.id, i.order_item_id, i.menu_item_id, i.bundle_id, m.name, coalesce(b.name, ''),
+			i.quantity, coalesce(oi.note, ''), i.status, i.bumped_at
+		FROM kitchen_ticket_items i
+		JOIN menu_items m ON m.id = i.menu_item_id
+		JOIN order_items oi ON oi.id = i.order_item_id
+		LEFT JOIN bundles b ON b.id = i.bundle_id
+		WHERE i.ticket_id IN (SELECT value FROM json_each(?1)) ORDER BY i.id`
+	rows, err := r.DB.QueryContext(ctx, query, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get kitchen ticket items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ticketID   int
+			item       models.TicketItem
+			bundleID   sql.NullInt64
+			bundleName string
+			bumpedAt   sql.NullString
+		)
+		err := rows.Scan(&ticketID, &item.ID, &item.OrderItemID, &item.MenuItemID, &bundleID, &item.Name, &bundleName,
+			&item.Quantity, &item.Note, &item.Status, &bumpedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan kitchen ticket item: %w", err)
+		}
+		if bundleID.Valid {
+			id := int(bundleID.Int64)
+			item.BundleID = &id
+			item.Modifiers = []string{"part of " + bundleName}
+		}
+		item.BumpedAt = bumpedAt.String
+		if ticket, ok := byID[ticketID]; ok {
+			ticket.Items = append(ticket.Items, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over kitchen ticket items: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetKitchenTicketById(ctx context.Context, id int) (models.KitchenTicket, error) {
+	tickets, err := r.queryKitchenTickets(ctx, `WHERE t.id = ?1`, id)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	if len(tickets) == 0 {
+		return models.KitchenTicket{}, fmt.Errorf("kitchen ticket not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return tickets[0], nil
+}
+
+func (r *repository) GetKitchenTicketsByOrder(ctx context.Context, orderId int) ([]models.KitchenTicket, error) {
+	return r.queryKitchenTickets(ctx, `WHERE t.order_id = ?1 ORDER BY t.station_id`, orderId)
+}
+
+// GetStationTickets lists a station's open tickets oldest first, or its done
+// tickets most recently bumped first. Tickets of cancelled orders are left
+// out.
+func (r *repository) GetStationTickets(ctx context.Context, stationId int, status models.TicketStatus, limit int) ([]models.KitchenTicket, error) {
+	order := `t.created_at, t.id`
+	if status == models.TicketStatusDone {
+		order = `t.bumped_at DESC, t.id DESC`
+	}
+	return r.queryKitchenTickets(ctx, `WHERE t.station_id = ?1 AND t.status = ?2 AND o.status <> 'cancelled'
+		ORDER BY `+order+` LIMIT ?3`, stationId, status, limit)
+}
+
+// SetTicketStatus bumps every item of a ticket, or recalls them all.
+func (r *repository) SetTicketStatus(ctx context.Context, ticketId int, status models.TicketStatus) (bool, error) {
+	itemStatus := models.TicketItemPending
+	if status == models.TicketStatusDone {
+		itemStatus = models.TicketItemReady
+	}
+	return r.updateTicketItems(ctx, ticketId, `ticket_id = ?2`, ticketId, itemStatus)
+}
+
+// SetTicketItemStatus bumps or recalls one item of a ticket.
+// It returns the id of the item's ticket.
+func (r *repository) SetTicketItemStatus(ctx context.Context, itemId int, status models.TicketItemStatus) (int, bool, error) {
+	var ticketID int
+	err := r.DB.QueryRowContext(ctx, `SELECT ticket_id FROM kitchen_ticket_items WHERE id = ?1`, itemId).Scan(&ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("kitchen ticket item not found with id %d: %w", itemId, models.ErrNotFound)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get kitchen ticket item: %w", err)
+	}
+	changed, err := r.updateTicketItems(ctx, ticketID, `id = ?2`, itemId, status)
+	return ticketID, changed, err
+}
+
+// updateTicketItems sets the status of the matching items of a ticket and
+// rolls it up to the ticket and the order. The order is locked first so
+// concurrent bumps see each other's items. It reports whether the order's
+// kitchen status changed.
+func (r *repository) updateTicketItems(ctx context.Context, ticketId int, match string, arg int, status models.TicketItemStatus) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var orderID int
+	query := `SELECT o.id FROM orders o JOIN kitchen_tickets t ON t.order_id = o.id WHERE t.id = ?1`
+	err = tx.QueryRowContext(ctx, query, ticketId).Scan(&orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("kitchen ticket not found with id %d: %w", ticketId, models.ErrNotFound)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	query = `UPDATE kitchen_ticket_items
+		SET status = ?1, bumped_at = CASE WHEN ?1 = 'ready' THEN coalesce(bumped_at, ` + now + `) END
+		WHERE ` + match
+	if _, err := tx.ExecContext(ctx, query, status, arg); err != nil {
+		return false, fmt.Errorf("failed to update kitchen ticket items: %w", err)
+	}
+
+	query = `UPDATE kitchen_tickets AS t SET
+			status = CASE WHEN r.pending = 0 THEN 'done' ELSE 'open' END,
+			bumped_at = CASE WHEN r.pending = 0 THEN CASE WHEN t.status = 'done' THEN t.bumped_at ELSE ` + now + ` END END
+		FROM (SELECT count(*) FILTER (WHERE status = 'pending') AS pending FROM kitchen_ticket_items WHERE ticket_id = ?1) r
+		WHERE t.id = ?1`
+	if _, err := tx.ExecContext(ctx, query, ticketId); err != nil {
+		return false, fmt.Errorf("failed to update kitchen ticket: %w", err)
+	}
+
+	// Only a change is written, so a returned row means there was one
+	query = `UPDATE orders AS o SET kitchen_status = s.status
+		FROM (SELECT CASE WHEN count(*) FILTER (WHERE i.status = 'pending') = 0 THEN 'ready'
+				WHEN count(*) FILTER (WHERE i.status = 'ready') > 0 THEN 'preparing' ELSE 'queued' END AS status
+			FROM kitchen_ticket_items i JOIN kitchen_tickets t ON t.id = i.ticket_id
+			WHERE t.order_id = ?1) s
+		WHERE o.id = ?1 AND o.kitchen_status IS DISTINCT FROM s.status
+		RETURNING ` + orderChangeColumns
+	change, err := scanOrderChange(tx.QueryRowContext(ctx, query, orderID))
+	changed := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("failed to update order kitchen status: %w", err)
+	}
+	if changed {
+		if err := writeOutbox(ctx, tx, models.OutboxOrderKitchenStatusChanged, change); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit kitchen ticket: %w", err)
+	}
+	return changed, nil
+}
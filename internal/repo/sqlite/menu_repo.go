@@ -0,0 +1,358 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// menuItemColumns are selected from menuItemSource. The price is the one in
+// effect right now according to the price history, falling back to the base
+// price on menu_items.
+const menuItemColumns = `m.id, m.name, m.description, coalesce((
+		SELECT p.price FROM menu_item_prices p
+		WHERE p.menu_item_id = m.id AND p.effective_from <= ` + now + `
+		ORDER BY p.effective_from DESC
+		LIMIT 1
+	), m.price), m.category,
+	m.image_url, m.image_medium_url, m.image_thumbnail_url, m.image_key,
+	m.spicy_level, m.kcal, m.protein_g, m.carbs_g, m.fat_g, m.sugar_g, m.salt_g, m.stock_quantity,
+	m.sku, m.available`
+
+const menuItemSource = `menu_items m`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanMenuItem scans menuItemColumns, followed by any extra selected columns.
+func scanMenuItem(row rowScanner, extra ...any) (models.MenuItem, error) {
+	var (
+		item                             models.MenuItem
+		large, medium, thumbnail, key    sql.NullString
+		kcal, stock                      sql.NullInt64
+		protein, carbs, fat, sugar, salt sql.NullFloat64
+	)
+	dest := []any{&item.ID, &item.Name, &item.Description, &item.Price, &item.Category, &large, &medium, &thumbnail, &key,
+		&item.SpicyLevel, &kcal, &protein, &carbs, &fat, &sugar, &salt, &stock, &item.SKU, &item.Available}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return models.MenuItem{}, err
+	}
+
+	item.Images = models.MenuImages{
+		Large:     large.String,
+		Medium:    firstNonEmpty(medium.String, large.String),
+		Thumbnail: firstNonEmpty(thumbnail.String, medium.String, large.String),
+		Key:       key.String,
+	}
+
+	nutrition := models.NutritionFacts{
+		ProteinGrams: nullFloatPtr(protein),
+		CarbsGrams:   nullFloatPtr(carbs),
+		FatGrams:     nullFloatPtr(fat),
+		SugarGrams:   nullFloatPtr(sugar),
+		SaltGrams:    nullFloatPtr(salt),
+	}
+	if kcal.Valid {
+		v := int(kcal.Int64)
+		nutrition.Kcal = &v
+	}
+	if !nutrition.IsEmpty() {
+		item.Nutrition = &nutrition
+	}
+	if stock.Valid {
+		v := int(stock.Int64)
+		item.Stock = &v
+	}
+	item.Allergens = []models.Allergen{}
+	item.DietaryTags = []models.DietaryTag{}
+	return item, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func nullFloatPtr(n sql.NullFloat64) *float64 {
+	if !n.Valid {
+		return nil
+	}
+	v := n.Float64
+	return &v
+}
+
+// attachDietaryInfo loads allergens and tags for all given items with one query each.
+func (r *repository) attachDietaryInfo(ctx context.Context, items []models.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.MenuItem, len(items))
+	ids := make([]int, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+		ids[i] = items[i].ID
+	}
+
+	allergenRows, err := r.DB.QueryContext(ctx,
+		`SELECT menu_item_id, allergen FROM menu_item_allergens WHERE menu_item_id IN (SELECT value FROM json_each(?1)) ORDER BY menu_item_id, allergen`, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get menu item allergens: %w", err)
+	}
+	defer allergenRows.Close()
+	for allergenRows.Next() {
+		var id int
+		var allergen models.Allergen
+		if err := allergenRows.Scan(&id, &allergen); err != nil {
+			return fmt.Errorf("failed to scan menu item allergen: %w", err)
+		}
+		if item, ok := byID[id]; ok {
+			item.Allergens = append(item.Allergens, allergen)
+		}
+	}
+	if err := allergenRows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over menu item allergens: %w", err)
+	}
+
+	tagRows, err := r.DB.QueryContext(ctx,
+		`SELECT menu_item_id, tag FROM menu_item_dietary_tags WHERE menu_item_id IN (SELECT value FROM json_each(?1)) ORDER BY menu_item_id, tag`, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get menu item dietary tags: %w", err)
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var id int
+		var tag models.DietaryTag
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return fmt.Errorf("failed to scan menu item dietary tag: %w", err)
+		}
+		if item, ok := byID[id]; ok {
+			item.DietaryTags = append(item.DietaryTags, tag)
+		}
+	}
+	if err := tagRows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over menu item dietary tags: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) queryMenuItems(ctx context.Context, query string, args ...any) ([]models.MenuItem, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var menuItems = make([]models.MenuItem, 0)
+	for rows.Next() {
+		item, err := scanMenuItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan menu item: %w", err)
+		}
+		menuItems = append(menuItems, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over menu items: %w", err)
+	}
+
+	if err := r.attachDietaryInfo(ctx, menuItems); err != nil {
+		return nil, err
+	}
+	return menuItems, nil
+}
+
+// Menu operations
+func (r *repository) CreateMenuItem(ctx context.Context, item models.MenuItem) (int, error) {
+	return 0, fmt.Errorf("not implemented")
+}
+
+func (r *repository) GetMenuItemById(ctx context.Context, id int) (models.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM ` + menuItemSource + ` WHERE m.id = ?1`
+	item, err := scanMenuItem(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.MenuItem{}, fmt.Errorf("menu item not found with id %d: %w", id, models.ErrNotFound)
+		}
+		return models.MenuItem{}, fmt.Errorf("failed to get menu item by ID: %w", err)
+	}
+
+	items := []models.MenuItem{item}
+	if err := r.attachDietaryInfo(ctx, items); err != nil {
+		return models.MenuItem{}, err
+	}
+	return items[0], nil
+}
+
+func (r *repository) GetMenuItemsByCategory(ctx context.Context, category string) ([]models.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM ` + menuItemSource + ` WHERE m.category = ?1 ORDER BY m.id`
+	menuItems, err := r.queryMenuItems(ctx, query, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu items by category %s: %w", category, err)
+	}
+	if len(menuItems) == 0 {
+		return nil, fmt.Errorf("no menu items found")
+	}
+	return menuItems, nil
+}
+
+func (r *repository) UpdateMenuItem(ctx context.Context, item models.MenuItem) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) UpdateMenuItemImages(ctx context.Context, id int, images models.MenuImages) error {
+	query := `UPDATE menu_items SET image_url = ?1, image_medium_url = ?2, image_thumbnail_url = ?3, image_key = ?4 WHERE id = ?5`
+	result, err := r.DB.ExecContext(ctx, query, images.Large, images.Medium, images.Thumbnail, images.Key, id)
+	if err != nil {
+		return fmt.Errorf("failed to update menu item images: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("menu item not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *repository) DeleteMenuItem(ctx context.Context, id int) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *repository) GetAllMenuItems(ctx context.Context) ([]models.MenuItem, error) {
+
+	query := `SELECT ` + menuItemColumns + ` FROM ` + menuItemSource + ` ORDER BY m.id`
+	menuItems, err := r.queryMenuItems(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all menu items: %w", err)
+	}
+	if len(menuItems) == 0 {
+		return nil, fmt.Errorf("no menu items found: %w", models.ErrIsEmpty)
+	}
+	return menuItems, nil
+}
+
+// ImportMenu applies the changes in one transaction, so a failing row
+// leaves the menu as it was.
+func (r *repository) ImportMenu(ctx context.Context, changes []models.MenuImportChange) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, change := range changes {
+		if err := importMenuChange(ctx, tx, change); err != nil {
+			return fmt.Errorf("failed to import menu item %s: %w", change.SKU, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit menu import: %w", err)
+	}
+	return nil
+}
+
+func importMenuChange(ctx context.Context, tx *sql.Tx, change models.MenuImportChange) error {
+	item := change.Item
+	switch change.Action {
+	case models.MenuImportCreate:
+		err := tx.QueryRowContext(ctx, `INSERT INTO menu_items (sku, name, description, price, category, spicy_level, stock_quantity, available)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8) RETURNING id`,
+			item.SKU, item.Name, item.Description, item.Price, item.Category, item.SpicyLevel, item.Stock, item.Available).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create menu item: %w", err)
+		}
+		if err := importPrice(ctx, tx, item); err != nil {
+			return err
+		}
+		return replaceDietaryInfo(ctx, tx, item)
+
+	case models.MenuImportUpdate, models.MenuImportArchive:
+		result, err := tx.ExecContext(ctx, `UPDATE menu_items
+			SET name = ?1, description = ?2, category = ?3, spicy_level = ?4, available = ?5
+			WHERE id = ?6`,
+			item.Name, item.Description, item.Category, item.SpicyLevel, item.Available, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update menu item: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("menu item not found with id %d: %w", item.ID, models.ErrNotFound)
+		}
+		// Stock is only written when the file changes it, so orders taken
+		// since the diff are not undone
+		if change.Changes("stock") {
+			if err := importStock(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		if change.Changes("price") {
+			if err := importPrice(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		if change.Changes("allergens") || change.Changes("dietaryTags") {
+			return replaceDietaryInfo(ctx, tx, item)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown menu import action %q", change.Action)
+}
+
+// importStock sets the stock of the item. Counting stock is a movement like
+// any other; starting or stopping to track it is not.
+func importStock(ctx context.Context, tx *sql.Tx, item models.MenuItem) error {
+	var stock sql.NullInt64
+	err := tx.QueryRowContext(ctx, `SELECT stock_quantity FROM menu_items WHERE id = ?1`, item.ID).Scan(&stock)
+	if err != nil {
+		return fmt.Errorf("failed to get menu item stock: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE menu_items SET stock_quantity = ?1 WHERE id = ?2`, item.Stock, item.ID); err != nil {
+		return fmt.Errorf("failed to update menu item stock: %w", err)
+	}
+	if stock.Valid && item.Stock != nil && int64(*item.Stock) != stock.Int64 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO stock_movements (menu_item_id, quantity, reason) VALUES (?1, ?2, 'import')`,
+			item.ID, int64(*item.Stock)-stock.Int64)
+		if err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+	}
+	return nil
+}
+
+// importPrice puts the imported price into effect right away.
+func importPrice(ctx context.Context, tx *sql.Tx, item models.MenuItem) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO menu_item_prices (menu_item_id, price, effective_from, note)
+		VALUES (?1, ?2, `+now+`, 'Menu import')`, item.ID, item.Price)
+	if err != nil {
+		return fmt.Errorf("failed to record menu item price: %w", err)
+	}
+	return nil
+}
+
+// replaceDietaryInfo sets the allergens and tags of the item to the given ones.
+func replaceDietaryInfo(ctx context.Context, tx *sql.Tx, item models.MenuItem) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM menu_item_allergens WHERE menu_item_id = ?1`, item.ID); err != nil {
+		return fmt.Errorf("failed to clear menu item allergens: %w", err)
+	}
+	for _, allergen := range item.Allergens {
+		_, err := tx.ExecContext(ctx, `INSERT INTO menu_item_allergens (menu_item_id, allergen) VALUES (?1, ?2)`, item.ID, allergen)
+		if err != nil {
+			return fmt.Errorf("failed to add menu item allergen: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM menu_item_dietary_tags WHERE menu_item_id = ?1`, item.ID); err != nil {
+		return fmt.Errorf("failed to clear menu item dietary tags: %w", err)
+	}
+	for _, tag := range item.DietaryTags {
+		_, err := tx.ExecContext(ctx, `INSERT INTO menu_item_dietary_tags (menu_item_id, tag) VALUES (?1, ?2)`, item.ID, tag)
+		if err != nil {
+			return fmt.Errorf("failed to add menu item dietary tag: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,604 @@
+-- TastyBites SQLite schema
+-- The final shape of db/migrations/000 to 014, ported to SQLite.
+--
+-- Timestamps are TEXT in UTC with microseconds (2024-06-01T12:00:00.000000Z),
+-- fixed width so they compare correctly as text. Booleans are 0 or 1.
+-- updated_at is maintained by AFTER UPDATE triggers that only step in when
+-- the statement did not set it itself, so they do not recurse.
+
+-- =============================================================================
+-- USERS TABLE
+-- =============================================================================
+
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin', 'manager', 'kitchen')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE TRIGGER update_users_updated_at
+    AFTER UPDATE ON users
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE users SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- =============================================================================
+-- KITCHEN STATIONS
+-- =============================================================================
+
+CREATE TABLE kitchen_stations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    is_default BOOLEAN NOT NULL DEFAULT 0 CHECK (is_default IN (0, 1)), -- gets items nothing else routes
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+-- At most one default station
+CREATE UNIQUE INDEX idx_kitchen_stations_default ON kitchen_stations(is_default) WHERE is_default;
+
+-- Categories are matched case-insensitively; store them lower case
+CREATE TABLE kitchen_category_stations (
+    category TEXT PRIMARY KEY,
+    station_id INTEGER NOT NULL REFERENCES kitchen_stations(id) ON DELETE CASCADE
+);
+
+-- =============================================================================
+-- MENU TABLES
+-- =============================================================================
+
+-- image_url holds the large variant; the smaller variants fall back to it
+CREATE TABLE menu_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    price INTEGER NOT NULL CHECK (price > 0), -- Price in cents
+    category TEXT NOT NULL,
+    image_url TEXT,
+    image_medium_url TEXT,
+    image_thumbnail_url TEXT,
+    image_key TEXT, -- blob storage prefix of uploaded variants
+    spicy_level INTEGER NOT NULL DEFAULT 0 CHECK (spicy_level BETWEEN 0 AND 3),
+    kcal INTEGER CHECK (kcal >= 0),
+    protein_g REAL CHECK (protein_g >= 0),
+    carbs_g REAL CHECK (carbs_g >= 0),
+    fat_g REAL CHECK (fat_g >= 0),
+    sugar_g REAL CHECK (sugar_g >= 0),
+    salt_g REAL CHECK (salt_g >= 0),
+    stock_quantity INTEGER CHECK (stock_quantity >= 0), -- NULL when stock is not tracked
+    station_id INTEGER REFERENCES kitchen_stations(id) ON DELETE SET NULL, -- wins over the category's station
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX idx_menu_items_category ON menu_items(category);
+CREATE INDEX idx_menu_items_name ON menu_items(name);
+
+CREATE TRIGGER update_menu_items_updated_at
+    AFTER UPDATE ON menu_items
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE menu_items SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- One row per declared allergen (EU FIC 14 major allergens)
+CREATE TABLE menu_item_allergens (
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    allergen TEXT NOT NULL CHECK (allergen IN (
+        'celery', 'gluten', 'crustaceans', 'eggs', 'fish', 'lupin', 'milk',
+        'molluscs', 'mustard', 'tree_nuts', 'peanuts', 'sesame', 'soybeans', 'sulphites'
+    )),
+    PRIMARY KEY (menu_item_id, allergen)
+);
+
+CREATE INDEX idx_menu_item_allergens_allergen ON menu_item_allergens(allergen);
+
+-- The "spicy" tag is derived from menu_items.spicy_level and is not stored here
+CREATE TABLE menu_item_dietary_tags (
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL CHECK (tag IN ('vegan', 'vegetarian', 'halal')),
+    PRIMARY KEY (menu_item_id, tag)
+);
+
+CREATE INDEX idx_menu_item_dietary_tags_tag ON menu_item_dietary_tags(tag);
+
+-- Every price a menu item has had or will have. The price in effect at a given
+-- moment is the entry with the latest effective_from not after that moment;
+-- menu_items.price is only a fallback for items without any history.
+CREATE TABLE menu_item_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0), -- Price in cents
+    effective_from TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (menu_item_id, effective_from)
+);
+
+CREATE INDEX idx_menu_item_prices_item_effective ON menu_item_prices(menu_item_id, effective_from DESC);
+
+-- =============================================================================
+-- TABLES TABLE
+-- =============================================================================
+
+CREATE TABLE tables (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    seats INTEGER NOT NULL CHECK (seats > 0),
+    status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'reserved')),
+    booked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX idx_tables_status ON tables(status);
+CREATE INDEX idx_tables_booked_by ON tables(booked_by);
+
+CREATE TRIGGER update_tables_updated_at
+    AFTER UPDATE ON tables
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tables SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- =============================================================================
+-- BUNDLES TABLES
+-- =============================================================================
+
+CREATE TABLE bundles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    pricing_mode TEXT NOT NULL CHECK (pricing_mode IN ('fixed', 'discount')),
+    price INTEGER CHECK (price > 0), -- Price in cents, fixed pricing only
+    discount_percent INTEGER CHECK (discount_percent BETWEEN 1 AND 100),
+    active BOOLEAN NOT NULL DEFAULT 1 CHECK (active IN (0, 1)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    CHECK (
+        (pricing_mode = 'fixed' AND price IS NOT NULL) OR
+        (pricing_mode = 'discount' AND discount_percent IS NOT NULL)
+    )
+);
+
+CREATE TRIGGER update_bundles_updated_at
+    AFTER UPDATE ON bundles
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE bundles SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- A slot allows either any item of a category or an explicit list of items
+CREATE TABLE bundle_slots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    bundle_id INTEGER NOT NULL REFERENCES bundles(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    category TEXT,
+    min_choices INTEGER NOT NULL DEFAULT 1,
+    max_choices INTEGER NOT NULL DEFAULT 1,
+    position INTEGER NOT NULL DEFAULT 0,
+    CHECK (min_choices >= 0 AND max_choices > 0 AND max_choices >= min_choices)
+);
+
+CREATE INDEX idx_bundle_slots_bundle_id ON bundle_slots(bundle_id);
+
+CREATE TABLE bundle_slot_items (
+    slot_id INTEGER NOT NULL REFERENCES bundle_slots(id) ON DELETE CASCADE,
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (slot_id, menu_item_id)
+);
+
+-- =============================================================================
+-- ORDERS TABLES
+-- =============================================================================
+
+-- Amounts in cents
+CREATE TABLE orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    table_id INTEGER REFERENCES tables(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'refunded', 'completed', 'cancelled')),
+    total_price INTEGER NOT NULL DEFAULT 0 CHECK (total_price >= 0),
+    coupon_code TEXT,
+    subtotal INTEGER NOT NULL DEFAULT 0 CHECK (subtotal >= 0), -- before discounts
+    discount_total INTEGER NOT NULL DEFAULT 0 CHECK (discount_total >= 0),
+    tax_inclusive BOOLEAN NOT NULL DEFAULT 0 CHECK (tax_inclusive IN (0, 1)),
+    tax_total INTEGER NOT NULL DEFAULT 0 CHECK (tax_total >= 0),
+    guests INTEGER NOT NULL DEFAULT 1 CHECK (guests >= 0),
+    service_charge INTEGER NOT NULL DEFAULT 0 CHECK (service_charge >= 0),
+    tip INTEGER NOT NULL DEFAULT 0 CHECK (tip >= 0),
+    refunded_total INTEGER NOT NULL DEFAULT 0 CHECK (refunded_total >= 0),
+    cash_refunded INTEGER NOT NULL DEFAULT 0 CHECK (cash_refunded >= 0),
+    kitchen_status TEXT CHECK (kitchen_status IN ('queued', 'preparing', 'ready')), -- NULL until sent to the kitchen
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+CREATE INDEX idx_orders_table_id ON orders(table_id);
+CREATE INDEX idx_orders_status ON orders(status);
+CREATE INDEX idx_orders_created_at ON orders(created_at);
+
+CREATE TRIGGER update_orders_updated_at
+    AFTER UPDATE ON orders
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE orders SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- A line is either a single menu item or a bundle
+CREATE TABLE order_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    menu_item_id INTEGER REFERENCES menu_items(id) ON DELETE RESTRICT,
+    bundle_id INTEGER REFERENCES bundles(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price INTEGER NOT NULL CHECK (price > 0), -- Price per item in cents at time of order
+    note TEXT, -- cooking note printed on kitchen tickets
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    CONSTRAINT order_items_item_or_bundle CHECK ((menu_item_id IS NULL) <> (bundle_id IS NULL))
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items(order_id);
+CREATE UNIQUE INDEX idx_order_items_unique ON order_items(order_id, menu_item_id);
+CREATE INDEX idx_order_items_bundle_id ON order_items(bundle_id);
+
+-- The items chosen for each slot of a bundle line, quantity is per bundle
+CREATE TABLE order_item_components (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    bundle_slot_id INTEGER REFERENCES bundle_slots(id) ON DELETE SET NULL,
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_order_item_components_order_item_id ON order_item_components(order_item_id);
+
+-- =============================================================================
+-- PROMOTIONS TABLES
+-- =============================================================================
+
+-- Coupons have a code; automatic promotions (happy hour, buy 2 get 1) do not
+CREATE TABLE promotions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    code TEXT UNIQUE CHECK (code = upper(code)),
+    type TEXT NOT NULL CHECK (type IN ('percent', 'fixed', 'buy_x_get_y')),
+    value INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0), -- percent, or cents for fixed discounts
+    buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    free_quantity INTEGER NOT NULL DEFAULT 0 CHECK (free_quantity >= 0),
+    category TEXT, -- only items of this category qualify
+    min_spend INTEGER NOT NULL DEFAULT 0 CHECK (min_spend >= 0), -- in cents
+    starts_at TEXT,
+    ends_at TEXT,
+    daily_start TEXT CHECK (daily_start GLOB '[0-2][0-9]:[0-5][0-9]'), -- daily window, e.g. 17:00-19:00 for happy hour
+    daily_end TEXT CHECK (daily_end GLOB '[0-2][0-9]:[0-5][0-9]'),
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0), -- 0 means unlimited
+    per_user_limit INTEGER NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    stackable BOOLEAN NOT NULL DEFAULT 0 CHECK (stackable IN (0, 1)),
+    active BOOLEAN NOT NULL DEFAULT 1 CHECK (active IN (0, 1)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    CHECK (type <> 'percent' OR value BETWEEN 1 AND 100),
+    CHECK (type <> 'fixed' OR value > 0),
+    CHECK (type <> 'buy_x_get_y' OR (buy_quantity > 0 AND free_quantity > 0)),
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at),
+    CHECK ((daily_start IS NULL) = (daily_end IS NULL))
+);
+
+CREATE INDEX idx_promotions_active ON promotions(active);
+
+CREATE TRIGGER update_promotions_updated_at
+    AFTER UPDATE ON promotions
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE promotions SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- Discount breakdown of an order; also the source of promotion usage counts
+CREATE TABLE order_discounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE RESTRICT,
+    name TEXT NOT NULL,
+    code TEXT,
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (order_id, promotion_id)
+);
+
+CREATE INDEX idx_order_discounts_promotion_id ON order_discounts(promotion_id);
+
+-- =============================================================================
+-- TAX TABLES
+-- =============================================================================
+
+CREATE TABLE tax_rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    rate INTEGER NOT NULL CHECK (rate BETWEEN 0 AND 10000), -- in basis points, 500 = 5%
+    is_default BOOLEAN NOT NULL DEFAULT 0 CHECK (is_default IN (0, 1)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+-- At most one rate applies to categories without their own rate
+CREATE UNIQUE INDEX idx_tax_rates_default ON tax_rates(is_default) WHERE is_default;
+
+CREATE TRIGGER update_tax_rates_updated_at
+    AFTER UPDATE ON tax_rates
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tax_rates SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- Each menu category falls under at most one rate
+CREATE TABLE tax_rate_categories (
+    category TEXT PRIMARY KEY,
+    tax_rate_id INTEGER NOT NULL REFERENCES tax_rates(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tax_rate_categories_tax_rate_id ON tax_rate_categories(tax_rate_id);
+
+-- Tax charged per rate; name and rate are copied so the order keeps them
+CREATE TABLE order_taxes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER REFERENCES tax_rates(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    rate INTEGER NOT NULL CHECK (rate BETWEEN 0 AND 10000),
+    taxable INTEGER NOT NULL CHECK (taxable >= 0), -- net amount taxed, in cents
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    UNIQUE (order_id, name)
+);
+
+CREATE INDEX idx_order_taxes_order_id ON order_taxes(order_id);
+
+-- =============================================================================
+-- SPLIT BILLS TABLES
+-- =============================================================================
+
+-- A split order has two or more checks that share its breakdown; amounts in cents
+CREATE TABLE order_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    label TEXT NOT NULL,
+    seat INTEGER CHECK (seat > 0),
+    split_mode TEXT NOT NULL CHECK (split_mode IN ('items', 'seats', 'even')),
+    subtotal INTEGER NOT NULL DEFAULT 0,
+    discount_total INTEGER NOT NULL DEFAULT 0,
+    tax_total INTEGER NOT NULL DEFAULT 0,
+    service_charge INTEGER NOT NULL DEFAULT 0,
+    tip INTEGER NOT NULL DEFAULT 0 CHECK (tip >= 0),
+    total_price INTEGER NOT NULL DEFAULT 0 CHECK (total_price >= 0),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (order_id, number)
+);
+
+CREATE INDEX idx_order_checks_order_id ON order_checks(order_id);
+
+CREATE TABLE order_check_items (
+    check_id INTEGER NOT NULL REFERENCES order_checks(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (check_id, order_item_id)
+);
+
+CREATE TABLE order_check_taxes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    check_id INTEGER NOT NULL REFERENCES order_checks(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER REFERENCES tax_rates(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    rate INTEGER NOT NULL,
+    taxable INTEGER NOT NULL,
+    amount INTEGER NOT NULL
+);
+
+-- =============================================================================
+-- PAYMENTS TABLES
+-- =============================================================================
+
+CREATE TABLE payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    check_id INTEGER REFERENCES order_checks(id) ON DELETE SET NULL,
+    provider TEXT NOT NULL,
+    reference TEXT, -- the gateway's id, unknown until it answers
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN
+        ('pending', 'authorized', 'captured', 'partially_refunded', 'refunded', 'voided', 'failed')),
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    captured INTEGER NOT NULL DEFAULT 0 CHECK (captured >= 0 AND captured <= amount),
+    refunded INTEGER NOT NULL DEFAULT 0 CHECK (refunded >= 0 AND refunded <= captured),
+    currency TEXT NOT NULL CHECK (length(currency) = 3),
+    failure_reason TEXT,
+    idempotency_key TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (provider, reference),
+    UNIQUE (order_id, idempotency_key)
+);
+
+CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE INDEX idx_payments_check_id ON payments(check_id);
+
+CREATE TRIGGER update_payments_updated_at
+    AFTER UPDATE ON payments
+    FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE payments SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
+
+-- Every delivery is recorded once per provider event id; redeliveries of a
+-- processed event are acknowledged without being applied again
+CREATE TABLE payment_webhook_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    reference TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    received_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    processed_at TEXT,
+    UNIQUE (provider, event_id)
+);
+
+-- =============================================================================
+-- REFUNDS TABLES
+-- =============================================================================
+
+CREATE TABLE refunds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    payment_id INTEGER REFERENCES payments(id) ON DELETE RESTRICT,
+    method TEXT NOT NULL CHECK (method IN ('gateway', 'cash')),
+    status TEXT NOT NULL CHECK (status IN ('pending_approval', 'processing', 'completed', 'rejected', 'failed')),
+    reason TEXT NOT NULL CHECK (reason IN
+        ('quality', 'wrong_item', 'missing_item', 'late', 'duplicate_charge', 'customer_request', 'other')),
+    note TEXT,
+    amount INTEGER NOT NULL CHECK (amount > 0), -- in cents
+    restock BOOLEAN NOT NULL DEFAULT 0 CHECK (restock IN (0, 1)),
+    failure_reason TEXT,
+    requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    approved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    processed_at TEXT
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+CREATE INDEX idx_refunds_status ON refunds(status);
+CREATE INDEX idx_refunds_processed_at ON refunds(processed_at);
+
+CREATE TABLE refund_items (
+    refund_id INTEGER NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (refund_id, order_item_id)
+);
+
+CREATE TABLE stock_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL, -- positive when stock comes back
+    reason TEXT NOT NULL,
+    refund_id INTEGER REFERENCES refunds(id) ON DELETE SET NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX idx_stock_movements_menu_item_id ON stock_movements(menu_item_id);
+
+-- =============================================================================
+-- INVOICES TABLES
+-- =============================================================================
+
+-- One counter row per fiscal year, bumped inside the transaction that issues
+-- the invoice so both commit or neither does
+CREATE TABLE invoice_sequences (
+    fiscal_year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL CHECK (last_number > 0)
+);
+
+CREATE TABLE invoices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    fiscal_year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    number TEXT NOT NULL UNIQUE, -- e.g. INV-2024-000042
+    customer_name TEXT NOT NULL,
+    customer_tax_id TEXT,
+    customer_address TEXT,
+    issued_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (fiscal_year, sequence)
+);
+
+-- =============================================================================
+-- KITCHEN TICKETS TABLES
+-- =============================================================================
+
+CREATE TABLE kitchen_tickets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    station_id INTEGER NOT NULL REFERENCES kitchen_stations(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    bumped_at TEXT,
+    UNIQUE (order_id, station_id)
+);
+
+CREATE INDEX idx_kitchen_tickets_station_status ON kitchen_tickets(station_id, status, created_at);
+
+CREATE TABLE kitchen_ticket_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ticket_id INTEGER NOT NULL REFERENCES kitchen_tickets(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items(id) ON DELETE RESTRICT,
+    bundle_id INTEGER REFERENCES bundles(id) ON DELETE SET NULL, -- set for bundle components
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready')),
+    bumped_at TEXT
+);
+
+CREATE INDEX idx_kitchen_ticket_items_ticket_id ON kitchen_ticket_items(ticket_id);
+
+-- =============================================================================
+-- OUTBOX AND WEBHOOKS TABLES
+-- =============================================================================
+
+-- Written in the same transaction as the change it describes, so an event
+-- exists exactly when the change was committed
+CREATE TABLE outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL CHECK (json_valid(payload)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    dispatched_at TEXT -- NULL until deliveries were created for it
+);
+
+CREATE INDEX idx_outbox_events_undispatched ON outbox_events(id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    description TEXT,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '', -- comma separated, empty means every event
+    active BOOLEAN NOT NULL DEFAULT 1 CHECK (active IN (0, 1)),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'retrying', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    last_error TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    delivered_at TEXT,
+    UNIQUE (endpoint_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'retrying');
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries(endpoint_id);
+
+-- One row per attempt, the delivery log
+CREATE TABLE webhook_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER, -- NULL when no response came back
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    attempted_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
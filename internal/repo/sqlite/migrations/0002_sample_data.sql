@@ -0,0 +1,129 @@
+-- TastyBites sample data, the same rows and ids as db/seed
+
+-- Default password for all users: "password123"
+INSERT INTO users (name, email, password, role) VALUES
+('Admin', 'admin@tastybites.com', '$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u', 'admin'),
+('John Doe', 'john@example.com', '$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u', 'user'),
+('Jane Smith', 'jane@example.com', '$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u', 'user'),
+('Manager', 'manager@tastybites.com', '$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u', 'admin'),
+('Kitchen', 'kitchen@tastybites.com', '$2a$10$85UC/kMB3jsoINLoDV5dk.8uMlYrQHhxbV.21SLnwRxjgKBcZcu1u', 'kitchen');
+
+-- =============================================================================
+-- MENU
+-- =============================================================================
+
+INSERT INTO menu_items (name, description, price, category, image_url, spicy_level, kcal, protein_g, carbs_g, fat_g, sugar_g, salt_g) VALUES
+('Margherita Pizza', 'Classic pizza with tomato sauce, mozzarella, and fresh basil', 1299, 'Pizza', 'https://images.unsplash.com/photo-1574071318508-1cdbab80d002', 0, 850, 34.0, 98.0, 32.0, 8.0, 3.20),
+('Chicken Caesar Salad', 'Crisp romaine lettuce with grilled chicken, parmesan, and caesar dressing', 1149, 'Salads', 'https://images.unsplash.com/photo-1546793665-c74683f339c1', 0, 520, 38.0, 18.0, 33.0, 4.0, 2.10),
+('Beef Burger', 'Juicy beef patty with lettuce, tomato, cheese, and special sauce', 1499, 'Burgers', 'https://images.unsplash.com/photo-1568901346375-23c9450c58cd', 0, 980, 52.0, 62.0, 56.0, 12.0, 3.80),
+('Chocolate Brownie', 'Rich chocolate brownie served with vanilla ice cream', 699, 'Desserts', 'https://images.unsplash.com/photo-1606313564200-e75d5e30476c', 0, 610, 7.0, 74.0, 32.0, 55.0, 0.40),
+('Pepperoni Pizza', 'Classic pepperoni pizza with mozzarella cheese', 1499, 'Pizza', 'https://images.unsplash.com/photo-1565299624946-b28f40a0ca4b', 1, NULL, NULL, NULL, NULL, NULL, NULL),
+('Greek Salad', 'Fresh vegetables with feta cheese and olive oil dressing', 999, 'Salads', 'https://images.unsplash.com/photo-1540420773420-3366772f4999', 0, 360, 9.0, 14.0, 30.0, 8.0, 2.40),
+('Fish & Chips', 'Beer-battered cod with crispy fries and tartar sauce', 1699, 'Main Course', 'https://images.unsplash.com/photo-1544025162-d76694265947', 0, NULL, NULL, NULL, NULL, NULL, NULL),
+('Tiramisu', 'Classic Italian dessert with coffee-soaked ladyfingers', 799, 'Desserts', 'https://images.unsplash.com/photo-1571877227200-a0d98ea607e9', 0, NULL, NULL, NULL, NULL, NULL, NULL),
+('BBQ Chicken Wings', 'Smoky BBQ chicken wings with celery sticks', 1299, 'Appetizers', 'https://images.unsplash.com/photo-1608039755401-742074f0548d', 2, NULL, NULL, NULL, NULL, NULL, NULL),
+('Vegetarian Pasta', 'Penne pasta with seasonal vegetables in garlic olive oil', 1199, 'Pasta', 'https://images.unsplash.com/photo-1621996346565-e3dbc1d56d0e', 0, NULL, NULL, NULL, NULL, NULL, NULL),
+('Fresh Lemonade', 'House-made lemonade with mint', 399, 'Drinks', 'https://images.unsplash.com/photo-1621263764928-df1444c5e859', 0, NULL, NULL, NULL, NULL, NULL, NULL),
+('Iced Tea', 'Cold brewed black tea with lemon', 349, 'Drinks', 'https://images.unsplash.com/photo-1556679343-c7306c1976bc', 0, NULL, NULL, NULL, NULL, NULL, NULL),
+('Craft Beer', 'Local pale ale on tap', 699, 'Alcohol', 'https://images.unsplash.com/photo-1535958636474-b021ee887b13', 0, NULL, NULL, NULL, NULL, NULL, NULL);
+
+INSERT INTO menu_item_allergens (menu_item_id, allergen) VALUES
+(1, 'gluten'), (1, 'milk'),
+(2, 'gluten'), (2, 'milk'), (2, 'eggs'), (2, 'fish'), (2, 'mustard'),
+(3, 'gluten'), (3, 'milk'), (3, 'sesame'), (3, 'mustard'),
+(4, 'gluten'), (4, 'milk'), (4, 'eggs'), (4, 'tree_nuts'), (4, 'soybeans'),
+(5, 'gluten'), (5, 'milk'), (5, 'sulphites'),
+(6, 'milk'),
+(7, 'gluten'), (7, 'fish'), (7, 'eggs'), (7, 'mustard'),
+(8, 'gluten'), (8, 'milk'), (8, 'eggs'), (8, 'sulphites'),
+(9, 'celery'), (9, 'mustard'), (9, 'soybeans'),
+(10, 'gluten');
+
+INSERT INTO menu_item_dietary_tags (menu_item_id, tag) VALUES
+(1, 'vegetarian'),
+(6, 'vegetarian'),
+(4, 'vegetarian'),
+(8, 'vegetarian'),
+(10, 'vegetarian'), (10, 'vegan'),
+(2, 'halal'),
+(9, 'halal');
+
+-- Seed the history with the current prices
+INSERT INTO menu_item_prices (menu_item_id, price, effective_from, note)
+SELECT id, price, created_at, 'Initial price' FROM menu_items;
+
+-- =============================================================================
+-- TABLES AND ORDERS
+-- =============================================================================
+
+INSERT INTO tables (name, seats, status, booked_by) VALUES
+('Table 1', 4, 'available', NULL),
+('Table 2', 2, 'available', NULL),
+('Table 3', 6, 'reserved', 2), -- Reserved by john_doe
+('Table 4', 4, 'available', NULL),
+('Table 5', 8, 'reserved', 4), -- Reserved by manager
+('Table 6', 2, 'available', NULL),
+('Table 7', 4, 'available', NULL),
+('Table 8', 6, 'reserved', 3), -- Reserved by jane_smith
+('Bar Counter', 6, 'available', NULL),
+('Private Room', 12, 'available', NULL);
+
+INSERT INTO orders (user_id, table_id, status, total_price, subtotal) VALUES
+(2, 1, 'completed', 2448, 2448), -- John's order at Table 1: Margherita Pizza + Chicken Caesar Salad
+(3, 2, 'pending', 1499, 1499),   -- Jane's order at Table 2: Beef Burger
+(2, 4, 'pending', 2198, 2198),   -- John's another order at Table 4: Pepperoni Pizza + Chocolate Brownie
+(4, 9, 'completed', 2698, 2698); -- Manager's order at Bar Counter: Fish & Chips + BBQ Wings
+
+INSERT INTO order_items (order_id, menu_item_id, quantity, price) VALUES
+(1, 1, 1, 1299), -- 1x Margherita Pizza
+(1, 2, 1, 1149), -- 1x Chicken Caesar Salad
+(2, 3, 1, 1499), -- 1x Beef Burger
+(3, 5, 1, 1499), -- 1x Pepperoni Pizza
+(3, 4, 1, 699),  -- 1x Chocolate Brownie
+(4, 7, 1, 1699), -- 1x Fish & Chips
+(4, 9, 1, 999);  -- 1x BBQ Chicken Wings
+
+-- =============================================================================
+-- BUNDLES, PROMOTIONS AND TAX
+-- =============================================================================
+
+INSERT INTO bundles (name, description, pricing_mode, price) VALUES
+('Pizza Meal Deal', 'Any pizza, a soft drink and a dessert', 'fixed', 1800);
+
+INSERT INTO bundle_slots (bundle_id, name, category, min_choices, max_choices, position) VALUES
+(1, 'Pizza', 'Pizza', 1, 1, 1),
+(1, 'Drink', NULL, 1, 1, 2),
+(1, 'Dessert', 'Desserts', 1, 1, 3);
+
+-- Beer is not part of the deal, so the drink slot lists the soft drinks
+INSERT INTO bundle_slot_items (slot_id, menu_item_id) VALUES (2, 11), (2, 12);
+
+INSERT INTO promotions (name, description, code, type, value, buy_quantity, free_quantity, category, min_spend, daily_start, daily_end, usage_limit, per_user_limit, stackable) VALUES
+('Happy Hour', '20% off drinks between 17:00 and 19:00', NULL, 'percent', 20, 0, 0, 'Drinks', 0, '17:00', '19:00', 0, 0, 1),
+('Dessert 3 for 2', 'Buy 2 desserts, get the cheapest third one free', NULL, 'buy_x_get_y', 0, 2, 1, 'Desserts', 0, NULL, NULL, 0, 0, 1),
+('Welcome 10%', '10% off your first order over $20', 'WELCOME10', 'percent', 10, 0, 0, NULL, 2000, NULL, NULL, 0, 1, 0),
+('$5 Off', '$5 off orders over $30, first 100 orders', 'FIVEOFF', 'fixed', 500, 0, 0, NULL, 3000, NULL, NULL, 100, 1, 1);
+
+INSERT INTO tax_rates (name, rate, is_default) VALUES
+('Food', 500, 1),
+('Alcohol', 2000, 0);
+
+INSERT INTO tax_rate_categories (category, tax_rate_id) VALUES ('Alcohol', 2);
+
+-- =============================================================================
+-- KITCHEN
+-- =============================================================================
+
+INSERT INTO kitchen_stations (name, is_default) VALUES
+('kitchen', 1),
+('grill', 0),
+('fryer', 0),
+('pastry', 0),
+('bar', 0);
+
+INSERT INTO kitchen_category_stations (category, station_id) VALUES
+('burgers', 2),
+('main course', 3),
+('appetizers', 3),
+('desserts', 4),
+('drinks', 5);
@@ -0,0 +1,10 @@
+-- TastyBites: stable SKUs and availability for menu import and export
+
+-- SQLite cannot add a NOT NULL column without a default, so the SKU is kept
+-- non-empty by the repository; existing items get one from their id
+ALTER TABLE menu_items ADD COLUMN sku TEXT;
+UPDATE menu_items SET sku = 'ITEM-' || id;
+CREATE UNIQUE INDEX idx_menu_items_sku ON menu_items(sku);
+
+-- Archived items stay on record for past orders but cannot be ordered
+ALTER TABLE menu_items ADD COLUMN available INTEGER NOT NULL DEFAULT 1 CHECK (available IN (0, 1));
@@ -0,0 +1,239 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const promotionColumns = `id, name, coalesce(description, ''), coalesce(code, ''), type, value, buy_quantity, free_quantity,
+	coalesce(category, ''), min_spend, starts_at, ends_at,
+	coalesce(daily_start, ''), coalesce(daily_end, ''),
+	usage_limit, per_user_limit, stackable, active, created_at, updated_at`
+
+func scanPromotion(row rowScanner) (models.Promotion, error) {
+	var (
+		p        models.Promotion
+		startsAt sql.NullString
+		endsAt   sql.NullString
+	)
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Code, &p.Type, &p.Value, &p.BuyQuantity, &p.FreeQuantity,
+		&p.Category, &p.MinSpend, &startsAt, &endsAt, &p.DailyStart, &p.DailyEnd,
+		&p.UsageLimit, &p.PerUserLimit, &p.Stackable, &p.Active, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return models.Promotion{}, err
+	}
+	if startsAt.Valid {
+		t, err := parseTimestamp(startsAt.String)
+		if err != nil {
+			return models.Promotion{}, err
+		}
+		p.StartsAt = &t
+	}
+	if endsAt.Valid {
+		t, err := parseTimestamp(endsAt.String)
+		if err != nil {
+			return models.Promotion{}, err
+		}
+		p.EndsAt = &t
+	}
+	return p, nil
+}
+
+func (r *repository) queryPromotions(ctx context.Context, where string, args ...any) ([]models.Promotion, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+promotionColumns+` FROM promotions `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion: %w", err)
+		}
+		promotions = append(promotions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over promotions: %w", err)
+	}
+	return promotions, nil
+}
+
+// Promotion operations
+func (r *repository) CreatePromotion(ctx context.Context, p models.Promotion) (int, error) {
+	query := `INSERT INTO promotions (name, description, code, type, value, buy_quantity, free_quantity, category,
+			min_spend, starts_at, ends_at, daily_start, daily_end, usage_limit, per_user_limit, stackable, active)
+		VALUES (?1, NULLIF(?2, ''), NULLIF(?3, ''), ?4, ?5, ?6, ?7, NULLIF(?8, ''),
+			?9, ?10, ?11, NULLIF(?12, ''), NULLIF(?13, ''), ?14, ?15, ?16, ?17)
+		RETURNING id`
+	var promotionID int
+	err := r.DB.QueryRowContext(ctx, query, p.Name, p.Description, p.Code, p.Type, p.Value, p.BuyQuantity, p.FreeQuantity, p.Category,
+		p.MinSpend, nullTimestamp(p.StartsAt), nullTimestamp(p.EndsAt), p.DailyStart, p.DailyEnd, p.UsageLimit, p.PerUserLimit, p.Stackable, p.Active).Scan(&promotionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return promotionID, nil
+}
+
+func (r *repository) GetPromotionById(ctx context.Context, id int) (models.Promotion, error) {
+	promotions, err := r.queryPromotions(ctx, `WHERE id = ?1`, id)
+	if err != nil {
+		return models.Promotion{}, err
+	}
+	if len(promotions) == 0 {
+		return models.Promotion{}, fmt.Errorf("promotion not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return promotions[0], nil
+}
+
+func (r *repository) GetPromotionByCode(ctx context.Context, code string) (models.Promotion, error) {
+	promotions, err := r.queryPromotions(ctx, `WHERE code = ?1`, code)
+	if err != nil {
+		return models.Promotion{}, err
+	}
+	if len(promotions) == 0 {
+		return models.Promotion{}, fmt.Errorf("%w: %s", models.ErrCouponNotFound, code)
+	}
+	return promotions[0], nil
+}
+
+func (r *repository) GetAllPromotions(ctx context.Context) ([]models.Promotion, error) {
+	return r.queryPromotions(ctx, ``)
+}
+
+func (r *repository) GetAutomaticPromotions(ctx context.Context) ([]models.Promotion, error) {
+	return r.queryPromotions(ctx, `WHERE code IS NULL AND active`)
+}
+
+func (r *repository) SetPromotionActive(ctx context.Context, id int, active bool) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE promotions SET active = ?1 WHERE id = ?2`, active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("promotion not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return nil
+}
+
+// promotionUsageQuery counts orders, cancelled ones aside, each promotion was
+// applied to in total and for one user, ignoring the order being repriced.
+const promotionUsageQuery = `
+	SELECT p.id,
+		(SELECT count(*) FROM order_discounts d JOIN orders o ON o.id = d.order_id
+			WHERE d.promotion_id = p.id AND o.status <> 'cancelled' AND o.id <> ?3),
+		(SELECT count(*) FROM order_discounts d JOIN orders o ON o.id = d.order_id
+			WHERE d.promotion_id = p.id AND o.status <> 'cancelled' AND o.id <> ?3 AND o.user_id = ?2),
+		p.usage_limit, p.per_user_limit, coalesce(p.code, p.name)
+	FROM promotions p
+	WHERE p.id IN (SELECT value FROM json_each(?1))
+`
+
+func (r *repository) GetPromotionUsage(ctx context.Context, promotionIds []int, userId, excludeOrderId int) (map[int]models.PromotionUsage, error) {
+	rows, err := r.DB.QueryContext(ctx, promotionUsageQuery, idList(promotionIds), userId, excludeOrderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotion usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int]models.PromotionUsage, len(promotionIds))
+	for rows.Next() {
+		var (
+			id, total, forUser, usageLimit, perUserLimit int
+			label                                        string
+		)
+		if err := rows.Scan(&id, &total, &forUser, &usageLimit, &perUserLimit, &label); err != nil {
+			return nil, fmt.Errorf("failed to scan promotion usage: %w", err)
+		}
+		usage[id] = models.PromotionUsage{Total: total, ForUser: forUser}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over promotion usage: %w", err)
+	}
+	return usage, nil
+}
+
+// writeOrderDiscounts inserts the discount breakdown of an order. The usage
+// limits of the promotions involved are checked again inside the transaction,
+// which holds the database write lock, so concurrent orders cannot redeem
+// past a limit.
+func writeOrderDiscounts(ctx context.Context, tx *sql.Tx, orderId, userId int, discounts []models.OrderDiscount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(discounts))
+	for i, d := range discounts {
+		ids[i] = d.PromotionID
+	}
+	rows, err := tx.QueryContext(ctx, promotionUsageQuery, idList(ids), userId, orderId)
+	if err != nil {
+		return fmt.Errorf("failed to get promotion usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id, total, forUser, usageLimit, perUserLimit int
+			label                                        string
+		)
+		if err := rows.Scan(&id, &total, &forUser, &usageLimit, &perUserLimit, &label); err != nil {
+			return fmt.Errorf("failed to scan promotion usage: %w", err)
+		}
+		if (usageLimit > 0 && total >= usageLimit) || (perUserLimit > 0 && forUser >= perUserLimit) {
+			return fmt.Errorf("%w: %s", models.ErrPromotionLimitReached, label)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over promotion usage: %w", err)
+	}
+	rows.Close()
+
+	for _, d := range discounts {
+		_, err := tx.ExecContext(ctx, `INSERT INTO order_discounts (order_id, promotion_id, name, code, amount) VALUES (?1, ?2, ?3, NULLIF(?4, ''), ?5)`,
+			orderId, d.PromotionID, d.Name, d.Code, d.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to insert order discount: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachDiscounts loads the discount breakdown of the given orders.
+func (r *repository) attachDiscounts(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.Order, len(orders))
+	ids := make([]int, len(orders))
+	for i := range orders {
+		orders[i].Discounts = []models.OrderDiscount{}
+		byID[orders[i].ID] = &orders[i]
+		ids[i] = orders[i].ID
+	}
+
+	query := `SELECT order_id, promotion_id, name, coalesce(code, ''), amount FROM order_discounts WHERE order_id IN (SELECT value FROM json_each(?1)) ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get order discounts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var d models.OrderDiscount
+		if err := rows.Scan(&orderID, &d.PromotionID, &d.Name, &d.Code, &d.Amount); err != nil {
+			return fmt.Errorf("failed to scan order discount: %w", err)
+		}
+		if order, ok := byID[orderID]; ok {
+			order.Discounts = append(order.Discounts, d)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order discounts: %w", err)
+	}
+	return nil
+}
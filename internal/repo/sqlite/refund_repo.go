@@ -0,0 +1,271 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+const refundColumns = `id, order_id, payment_id, method, status, reason, coalesce(note, ''), amount, restock,
+	coalesce(failure_reason, ''), coalesce(requested_by, 0), coalesce(approved_by, 0), created_at, processed_at`
+
+func scanRefund(row rowScanner) (models.Refund, error) {
+	var (
+		r           models.Refund
+		paymentID   sql.NullInt64
+		processedAt sql.NullString
+	)
+	err := row.Scan(&r.ID, &r.OrderID, &paymentID, &r.Method, &r.Status, &r.Reason, &r.Note, &r.Amount, &r.Restock,
+		&r.FailureReason, &r.RequestedBy, &r.ApprovedBy, &r.CreatedAt, &processedAt)
+	if paymentID.Valid {
+		id := int(paymentID.Int64)
+		r.PaymentID = &id
+	}
+	r.ProcessedAt = processedAt.String
+	r.Items = []models.RefundItem{}
+	return r, err
+}
+
+func (r *repository) queryRefunds(ctx context.Context, where string, args ...any) ([]models.Refund, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+refundColumns+` FROM refunds `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := make([]models.Refund, 0)
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over refunds: %w", err)
+	}
+	if err := r.attachRefundItems(ctx, refunds); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// attachRefundItems loads the refunded lines of the given refunds.
+func (r *repository) attachRefundItems(ctx context.Context, refunds []models.Refund) error {
+	if len(refunds) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.Refund, len(refunds))
+	ids := make([]int, len(refunds))
+	for i := range refunds {
+		byID[refunds[i].ID] = &refunds[i]
+		ids[i] = refunds[i].ID
+	}
+
+	query := `SELECT refund_id, order_item_id, quantity, amount FROM refund_items WHERE refund_id IN (SELECT value FROM json_each(?1)) ORDER BY order_item_id`
+	rows, err := r.DB.QueryContext(ctx, query, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get refund items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var refundID int
+		var item models.RefundItem
+		if err := rows.Scan(&refundID, &item.OrderItemID, &item.Quantity, &item.Amount); err != nil {
+			return fmt.Errorf("failed to scan refund item: %w", err)
+		}
+		if refund, ok := byID[refundID]; ok {
+			refund.Items = append(refund.Items, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over refund items: %w", err)
+	}
+	return nil
+}
+
+// Refund operations
+func (r *repository) CreateRefund(ctx context.Context, refund models.Refund) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	paymentID := 0
+	if refund.PaymentID != nil {
+		paymentID = *refund.PaymentID
+	}
+	var refundID int
+	query := `INSERT INTO refunds (order_id, payment_id, method, status, reason, note, amount, restock, requested_by)
+		VALUES (?1, ?2, ?3, ?4, ?5, NULLIF(?6, ''), ?7, ?8, ?9) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, refund.OrderID, nullIfZero(paymentID), refund.Method, refund.Status, refund.Reason,
+		refund.Note, refund.Amount, refund.Restock, nullIfZero(refund.RequestedBy)).Scan(&refundID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	for _, item := range refund.Items {
+		_, err := tx.ExecContext(ctx, `INSERT INTO refund_items (refund_id, order_item_id, quantity, amount) VALUES (?1, ?2, ?3, ?4)`,
+			refundID, item.OrderItemID, item.Quantity, item.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert refund item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit refund: %w", err)
+	}
+	return refundID, nil
+}
+
+func (r *repository) GetRefundById(ctx context.Context, id int) (models.Refund, error) {
+	refunds, err := r.queryRefunds(ctx, `WHERE id = ?1`, id)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	if len(refunds) == 0 {
+		return models.Refund{}, fmt.Errorf("refund not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return refunds[0], nil
+}
+
+func (r *repository) GetRefundsByOrder(ctx context.Context, orderId int) ([]models.Refund, error) {
+	return r.queryRefunds(ctx, `WHERE order_id = ?1`, orderId)
+}
+
+func (r *repository) GetRefunds(ctx context.Context, status models.RefundStatus) ([]models.Refund, error) {
+	if status == "" {
+		return r.queryRefunds(ctx, ``)
+	}
+	return r.queryRefunds(ctx, `WHERE status = ?1`, status)
+}
+
+// UpdateRefundStatus moves a refund on from status from. It fails with
+// models.ErrRefundNotAllowed when the refund changed in the meantime.
+func (r *repository) UpdateRefundStatus(ctx context.Context, refund models.Refund, from models.RefundStatus) error {
+	query := `UPDATE refunds SET status = ?1, approved_by = ?2, failure_reason = NULLIF(?3, ''),
+			processed_at = CASE WHEN ?1 IN ('rejected', 'failed') THEN ` + now + ` END
+		WHERE id = ?4 AND status = ?5`
+	result, err := r.DB.ExecContext(ctx, query, refund.Status, nullIfZero(refund.ApprovedBy), refund.FailureReason, refund.ID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: refund %d is no longer %s", models.ErrRefundNotAllowed, refund.ID, from)
+	}
+	return nil
+}
+
+// CompleteRefund marks a processing refund completed, adds it to the order's
+// refunded totals and puts restocked items back. Cash refunds are checked
+// against the money taken on the order while it is locked.
+func (r *repository) CompleteRefund(ctx context.Context, refund models.Refund, movements []models.StockMovement) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var refundable int
+	query := `SELECT (SELECT coalesce(sum(p.captured - p.refunded), 0) FROM payments p WHERE p.order_id = o.id) - o.cash_refunded
+		FROM orders o WHERE o.id = ?1`
+	if err := tx.QueryRowContext(ctx, query, refund.OrderID).Scan(&refundable); err != nil {
+		return fmt.Errorf("order not found with id %d: %w", refund.OrderID, models.ErrNotFound)
+	}
+	cash := 0
+	if refund.Method == models.RefundMethodCash {
+		cash = refund.Amount
+		if refund.Amount > refundable {
+			return fmt.Errorf("%w: only %d cents are left to refund", models.ErrRefundNotAllowed, refundable)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE refunds SET status = 'completed', processed_at = `+now+`
+		WHERE id = ?1 AND status = 'processing'`, refund.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete refund: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: refund %d is no longer processing", models.ErrRefundNotAllowed, refund.ID)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET refunded_total = refunded_total + ?1, cash_refunded = cash_refunded + ?2 WHERE id = ?3`,
+		refund.Amount, cash, refund.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to update order refunds: %w", err)
+	}
+
+	for _, m := range movements {
+		_, err := tx.ExecContext(ctx, `INSERT INTO stock_movements (menu_item_id, quantity, reason, refund_id) VALUES (?1, ?2, ?3, ?4)`,
+			m.MenuItemID, m.Quantity, m.Reason, refund.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+		// Items without tracked stock only get the movement
+		_, err = tx.ExecContext(ctx, `UPDATE menu_items SET stock_quantity = stock_quantity + ?1
+			WHERE id = ?2 AND stock_quantity IS NOT NULL`, m.Quantity, m.MenuItemID)
+		if err != nil {
+			return fmt.Errorf("failed to restock menu item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refund: %w", err)
+	}
+	return nil
+}
+
+// GetSalesReport sums orders placed and refunds completed in [from, to).
+// Cancelled orders are left out.
+func (r *repository) GetSalesReport(ctx context.Context, from, to time.Time) (models.SalesReport, error) {
+	report := models.SalesReport{
+		From:            from.Format(time.RFC3339),
+		To:              to.Format(time.RFC3339),
+		RefundsByReason: make(map[models.RefundReason]int),
+		RefundsByMethod: make(map[models.RefundMethod]int),
+	}
+
+	query := `SELECT count(*), coalesce(sum(total_price), 0) FROM orders
+		WHERE created_at >= ?1 AND created_at < ?2 AND status <> 'cancelled'`
+	if err := r.DB.QueryRowContext(ctx, query, from, to).Scan(&report.Orders, &report.GrossSales); err != nil {
+		return models.SalesReport{}, fmt.Errorf("failed to sum sales: %w", err)
+	}
+
+	query = `SELECT reason, method, sum(amount) FROM refunds
+		WHERE status = 'completed' AND processed_at >= ?1 AND processed_at < ?2
+		GROUP BY reason, method`
+	rows, err := r.DB.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return models.SalesReport{}, fmt.Errorf("failed to sum refunds: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			reason models.RefundReason
+			method models.RefundMethod
+			amount int
+		)
+		if err := rows.Scan(&reason, &method, &amount); err != nil {
+			return models.SalesReport{}, fmt.Errorf("failed to scan refund sum: %w", err)
+		}
+		report.RefundsByReason[reason] += amount
+		report.RefundsByMethod[method] += amount
+		report.Refunds += amount
+	}
+	if err := rows.Err(); err != nil {
+		return models.SalesReport{}, fmt.Errorf("failed to iterate over refund sums: %w", err)
+	}
+
+	query = `SELECT count(*) FROM refunds WHERE status = 'pending_approval'`
+	if err := r.DB.QueryRowContext(ctx, query).Scan(&report.PendingRefunds); err != nil {
+		return models.SalesReport{}, fmt.Errorf("failed to count pending refunds: %w", err)
+	}
+
+	report.NetSales = report.GrossSales - report.Refunds
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+type repository struct {
+	DB *sql.DB
+}
+
+// NewRepository opens the SQLite database file at cfg.Path, creating it if
+// needed, and brings its schema up to date.
+func NewRepository(cfg *config.DBConfig) (interfaces.Repository, error) {
+	// Foreign keys are off in SQLite unless asked for on every connection.
+	// Transactions take the write lock up front so two writers wait on the
+	// busy timeout instead of failing when one upgrades a read lock.
+	dsn := "file:" + cfg.Path +
+		"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	log.Println("Opening SQLite database:", cfg.Path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &repository{DB: db}, nil
+}
+
+func (r *repository) Close() error {
+	return r.DB.Close()
+}
+
+func (r *repository) Ping(ctx context.Context) error {
+	return r.DB.PingContext(ctx)
+}
+
+// CheckMigrations fails when embedded migrations are not applied, which
+// only happens when another process opened the file since startup.
+func (r *repository) CheckMigrations(ctx context.Context) error {
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	rows, err := r.DB.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over applied migrations: %w", err)
+	}
+
+	var pending []string
+	for _, name := range names {
+		if !applied[name] {
+			pending = append(pending, path.Base(name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations not applied: %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// migrate applies the embedded migrations that have not been applied yet,
+// each in its own transaction, in file name order.
+func migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TEXT NOT NULL DEFAULT (`+now+`)
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := applyMigration(ctx, db, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, name string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM schema_migrations WHERE version = ?1`, name).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to check migration %s: %w", name, err)
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	script, err := migrations.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?1)`, name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", name, err)
+	}
+	log.Println("Applied SQLite migration", name)
+	return nil
+}
@@ -0,0 +1,22 @@
+package sqliterepo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/repo/repotest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) interfaces.Repository {
+		// A new file gets the schema and the sample data from the migrations
+		r, err := NewRepository(&config.DBConfig{Path: filepath.Join(t.TempDir(), "tastybites.db")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { r.Close() })
+		return r
+	})
+}
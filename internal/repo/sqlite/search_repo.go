@@ -0,0 +1,21 @@
+package sqliterepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/search"
+)
+
+// SearchMenuItems ranks the menu in memory, as SQLite has neither stemmed
+// full-text search nor trigram similarity built in. Menus are small enough
+// for this to be cheaper than keeping an FTS index in step.
+func (r *repository) SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) ([]models.MenuSearchHit, int, error) {
+	items, err := r.queryMenuItems(ctx, `SELECT `+menuItemColumns+` FROM `+menuItemSource+` WHERE m.available ORDER BY m.id`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search menu items: %w", err)
+	}
+	hits, total := search.MenuItems(items, query)
+	return hits, total, nil
+}
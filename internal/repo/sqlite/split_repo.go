@@ -0,0 +1,219 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Split bill operations
+
+// CreateOrderChecks replaces the checks of a pending order and stores its tip
+// and total, which change when the checks carry their own tips. It fails when
+// money is already held or taken on the order.
+func (r *repository) CreateOrderChecks(ctx context.Context, order models.Order, checks []models.Check) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = ?1`, order.ID).Scan(&status)
+	if err != nil {
+		return fmt.Errorf("order not found with id %d: %w", order.ID, models.ErrNotFound)
+	}
+	if status != string(models.OrderStatusPending) {
+		return fmt.Errorf("%w: only pending orders can be split", models.ErrInvalidInput)
+	}
+	if err := checkNoPayments(ctx, tx, order.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM order_checks WHERE order_id = ?1`, order.ID); err != nil {
+		return fmt.Errorf("failed to clear order checks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET tip = ?1, total_price = ?2 WHERE id = ?3`,
+		order.Tip, order.TotalPrice, order.ID); err != nil {
+		return fmt.Errorf("failed to update order tip: %w", err)
+	}
+
+	for _, c := range checks {
+		var checkID int
+		query := `INSERT INTO order_checks (order_id, number, label, seat, split_mode, subtotal, discount_total,
+				tax_total, service_charge, tip, total_price)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11) RETURNING id`
+		err := tx.QueryRowContext(ctx, query, order.ID, c.Number, c.Label, nullIfZero(c.Seat), c.Mode, c.Subtotal, c.DiscountTotal,
+			c.TaxTotal, c.ServiceCharge, c.Tip, c.TotalPrice).Scan(&checkID)
+		if err != nil {
+			return fmt.Errorf("failed to create order check: %w", err)
+		}
+		for _, item := range c.Items {
+			_, err := tx.ExecContext(ctx, `INSERT INTO order_check_items (check_id, order_item_id, quantity) VALUES (?1, ?2, ?3)`,
+				checkID, item.OrderItemID, item.Quantity)
+			if err != nil {
+				return fmt.Errorf("failed to insert order check item: %w", err)
+			}
+		}
+		for _, t := range c.Taxes {
+			_, err := tx.ExecContext(ctx, `INSERT INTO order_check_taxes (check_id, tax_rate_id, name, rate, taxable, amount)
+				VALUES (?1, ?2, ?3, ?4, ?5, ?6)`, checkID, nullIfZero(t.TaxRateID), t.Name, t.Rate, t.Taxable, t.Amount)
+			if err != nil {
+				return fmt.Errorf("failed to insert order check tax: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order checks: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrderChecks merges a split bill back into one, as long as the order
+// is pending and no money is held or taken on it. The tips of the checks
+// become the order's tip, and its total follows.
+func (r *repository) DeleteOrderChecks(ctx context.Context, orderId int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = ?1`, orderId).Scan(&status)
+	if err != nil {
+		return fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	if status != string(models.OrderStatusPending) {
+		return fmt.Errorf("%w: only pending orders can be merged", models.ErrInvalidInput)
+	}
+	if err := checkNoPayments(ctx, tx, orderId); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET tip = c.tip, total_price = total_price - orders.tip + c.tip
+		FROM (SELECT sum(tip) AS tip FROM order_checks WHERE order_id = ?1 HAVING count(*) > 0) AS c
+		WHERE orders.id = ?1`, orderId)
+	if err != nil {
+		return fmt.Errorf("failed to fold check tips into the order: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM order_checks WHERE order_id = ?1`, orderId); err != nil {
+		return fmt.Errorf("failed to delete order checks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order checks: %w", err)
+	}
+	return nil
+}
+
+// checkNoPayments fails when an order has payments that hold or took money.
+func checkNoPayments(ctx context.Context, tx *sql.Tx, orderId int) error {
+	var held bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payments
+		WHERE order_id = ?1 AND status NOT IN ('failed', 'voided', 'refunded'))`, orderId).Scan(&held)
+	if err != nil {
+		return fmt.Errorf("failed to check order payments: %w", err)
+	}
+	if held {
+		return fmt.Errorf("%w: the order already has payments", models.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (r *repository) GetOrderChecks(ctx context.Context, orderId int) ([]models.Check, error) {
+	query := `SELECT id, order_id, number, label, coalesce(seat, 0), split_mode, subtotal, discount_total, tax_total,
+			service_charge, tip, total_price
+		FROM order_checks WHERE order_id = ?1 ORDER BY number`
+	rows, err := r.DB.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order checks: %w", err)
+	}
+	defer rows.Close()
+
+	checks := make([]models.Check, 0)
+	for rows.Next() {
+		var c models.Check
+		if err := rows.Scan(&c.ID, &c.OrderID, &c.Number, &c.Label, &c.Seat, &c.Mode, &c.Subtotal, &c.DiscountTotal, &c.TaxTotal,
+			&c.ServiceCharge, &c.Tip, &c.TotalPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan order check: %w", err)
+		}
+		c.Items = []models.CheckItem{}
+		c.Taxes = []models.OrderTax{}
+		checks = append(checks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over order checks: %w", err)
+	}
+	if len(checks) == 0 {
+		return checks, nil
+	}
+
+	byID := make(map[int]*models.Check, len(checks))
+	for i := range checks {
+		byID[checks[i].ID] = &checks[i]
+	}
+	if err := r.attachCheckItems(ctx, orderId, byID); err != nil {
+		return nil, err
+	}
+	if err := r.attachCheckTaxes(ctx, orderId, byID); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *repository) attachCheckItems(ctx context.Context, orderId int, byID map[int]*models.Check) error {
+	query := `SELECT ci.check_id, ci.order_item_id, ci.quantity
+		FROM order_check_items ci
+		JOIN order_checks c ON c.id = ci.check_id
+		WHERE c.order_id = ?1 ORDER BY ci.order_item_id`
+	rows, err := r.DB.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return fmt.Errorf("failed to get order check items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var checkID int
+		var item models.CheckItem
+		if err := rows.Scan(&checkID, &item.OrderItemID, &item.Quantity); err != nil {
+			return fmt.Errorf("failed to scan order check item: %w", err)
+		}
+		if c, ok := byID[checkID]; ok {
+			c.Items = append(c.Items, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order check items: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) attachCheckTaxes(ctx context.Context, orderId int, byID map[int]*models.Check) error {
+	query := `SELECT t.check_id, coalesce(t.tax_rate_id, 0), t.name, t.rate, t.taxable, t.amount
+		FROM order_check_taxes t
+		JOIN order_checks c ON c.id = t.check_id
+		WHERE c.order_id = ?1 ORDER BY t.id`
+	rows, err := r.DB.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return fmt.Errorf("failed to get order check taxes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var checkID int
+		var t models.OrderTax
+		if err := rows.Scan(&checkID, &t.TaxRateID, &t.Name, &t.Rate, &t.Taxable, &t.Amount); err != nil {
+			return fmt.Errorf("failed to scan order check tax: %w", err)
+		}
+		if c, ok := byID[checkID]; ok {
+			c.Taxes = append(c.Taxes, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over order check taxes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// Outbox and webhook operations
+
+// writeOutbox records an event in the transaction of the change it is about.
+func writeOutbox(ctx context.Context, tx *sql.Tx, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox_events (event_type, payload) VALUES (?1, ?2)`, eventType, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) CreateWebhookEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (int, error) {
+	query := `INSERT INTO webhook_endpoints (url, description, secret, event_types, active)
+		VALUES (?1, NULLIF(?2, ''), ?3, ?4, ?5) RETURNING id`
+	var id int
+	err := r.DB.QueryRowContext(ctx, query, endpoint.URL, endpoint.Description, endpoint.Secret,
+		strings.Join(endpoint.EventTypes, ","), endpoint.Active).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return id, nil
+}
+
+// Secrets are never read back; they are only shown when created
+const webhookEndpointColumns = `id, url, coalesce(description, ''), event_types, active, created_at`
+
+func scanWebhookEndpoint(row rowScanner) (models.WebhookEndpoint, error) {
+	var (
+		e          models.WebhookEndpoint
+		eventTypes string
+	)
+	if err := row.Scan(&e.ID, &e.URL, &e.Description, &eventTypes, &e.Active, &e.CreatedAt); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	e.EventTypes = []string{}
+	if eventTypes != "" {
+		e.EventTypes = strings.Split(eventTypes, ",")
+	}
+	return e, nil
+}
+
+func (r *repository) GetWebhookEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []models.WebhookEndpoint
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (r *repository) GetWebhookEndpointById(ctx context.Context, id int) (models.WebhookEndpoint, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE id = ?1`, id)
+	e, err := scanWebhookEndpoint(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookEndpoint{}, fmt.Errorf("webhook endpoint not found with id %d: %w", id, models.ErrNotFound)
+	}
+	if err != nil {
+		return models.WebhookEndpoint{}, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+func (r *repository) SetWebhookEndpointActive(ctx context.Context, id int, active bool) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE webhook_endpoints SET active = ?1 WHERE id = ?2`, active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook endpoint not found with id %d: %w", id, models.ErrNotFound)
+	}
+	return nil
+}
+
+// DispatchOutbox creates a delivery per subscribed active endpoint for the
+// oldest undispatched outbox events and marks them dispatched. Events nobody
+// subscribes to are only marked. It returns how many events it took.
+func (r *repository) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The transaction holds the write lock, so both statements see the same batch
+	const batch = `SELECT id FROM outbox_events WHERE dispatched_at IS NULL ORDER BY id LIMIT ?1`
+	query := `INSERT INTO webhook_deliveries (endpoint_id, event_id)
+		SELECT e.id, b.id FROM outbox_events b
+		JOIN webhook_endpoints e ON e.active AND (e.event_types = '' OR instr(',' || e.event_types || ',', ',' || b.event_type || ',') > 0)
+		WHERE b.id IN (` + batch + `)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, limit); err != nil {
+		return 0, fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE outbox_events SET dispatched_at = `+now+` WHERE id IN (`+batch+`)`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dispatch outbox events: %w", err)
+	}
+	n, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox dispatch: %w", err)
+	}
+	return int(n), nil
+}
+
+const webhookDeliveryColumns = `d.id, d.endpoint_id, o.id, o.event_type, o.payload, o.created_at, d.status, d.attempts,
+	d.next_attempt_at, coalesce(d.last_error, ''), d.created_at, d.delivered_at`
+
+func scanWebhookDelivery(row rowScanner, trailing ...any) (models.WebhookDelivery, error) {
+	var (
+		d                          models.WebhookDelivery
+		payload                    []byte
+		nextAttemptAt, deliveredAt sql.NullString
+	)
+	dest := []any{&d.ID, &d.EndpointID, &d.Event.ID, &d.Event.Type, &payload, &d.Event.CreatedAt, &d.Status, &d.Attempts,
+		&nextAttemptAt, &d.LastError, &d.CreatedAt, &deliveredAt}
+	if err := row.Scan(append(dest, trailing...)...); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d.Event.Data = payload
+	if d.Status == models.WebhookDeliveryPending || d.Status == models.WebhookDeliveryRetrying {
+		d.NextAttemptAt = nextAttemptAt.String
+	}
+	d.DeliveredAt = deliveredAt.String
+	return d, nil
+}
+
+// ClaimWebhookDeliveries takes deliveries that are due and moves their next
+// attempt lease into the future, so no other dispatcher sends them meanwhile
+// and they are retried if this one dies.
+func (r *repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claimed := time.Now()
+	query := `UPDATE webhook_deliveries SET next_attempt_at = ?2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ('pending', 'retrying') AND next_attempt_at <= ?3
+			ORDER BY next_attempt_at, id LIMIT ?1)
+		RETURNING id`
+	idRows, err := tx.QueryContext(ctx, query, limit, timestamp(claimed.Add(lease)), timestamp(claimed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	var ids []int64
+	for idRows.Next() {
+		var id int64
+		if err := idRows.Scan(&id); err != nil {
+			idRows.Close()
+			return nil, fmt.Errorf("failed to scan claimed webhook delivery: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	idRows.Close()
+	if err := idRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	query = `SELECT ` + webhookDeliveryColumns + `, e.url, e.secret
+		FROM webhook_deliveries d
+		JOIN outbox_events o ON o.id = d.event_id
+		JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		WHERE d.id IN (SELECT value FROM json_each(?1))
+		ORDER BY d.id`
+	rows, err := tx.QueryContext(ctx, query, idList(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claimed webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		d, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.URL, d.Secret = url, secret
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook deliveries: %w", err)
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook claim: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt logs an attempt and moves the delivery to status,
+// to be tried again after retryIn when it is retrying.
+func (r *repository) RecordWebhookAttempt(ctx context.Context, deliveryId int64, attempt models.WebhookAttempt, status models.WebhookDeliveryStatus, retryIn time.Duration) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES (?1, ?2, ?3, NULLIF(?4, ''), ?5)`,
+		deliveryId, attempt.Attempt, nullIfZero(attempt.StatusCode), attempt.Error, attempt.DurationMs)
+	if err != nil {
+		return fmt.Errorf("failed to log webhook attempt: %w", err)
+	}
+
+	query := `UPDATE webhook_deliveries SET status = ?1, attempts = ?2, last_error = NULLIF(?3, ''),
+			next_attempt_at = CASE WHEN ?1 = 'retrying' THEN ?4 END,
+			delivered_at = CASE WHEN ?1 = 'delivered' THEN ` + now + ` END
+		WHERE id = ?5`
+	if _, err := tx.ExecContext(ctx, query, status, attempt.Attempt, attempt.Error, timestamp(time.Now().Add(retryIn)), deliveryId); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries lists an endpoint's latest deliveries, optionally of
+// one status, with their attempts.
+func (r *repository) GetWebhookDeliveries(ctx context.Context, endpointId int, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d JOIN outbox_events o ON o.id = d.event_id
+		WHERE d.endpoint_id = ?1 AND (?2 = '' OR d.status = ?2)
+		ORDER BY d.id DESC LIMIT ?3`
+	rows, err := r.DB.QueryContext(ctx, query, endpointId, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook deliveries: %w", err)
+	}
+	if err := r.attachWebhookAttempts(ctx, deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *repository) attachWebhookAttempts(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.WebhookDelivery, len(deliveries))
+	ids := make([]int64, len(deliveries))
+	for i := range deliveries {
+		byID[deliveries[i].ID] = &deliveries[i]
+		ids[i] = deliveries[i].ID
+	}
+
+	query := `SELECT delivery_id, attempt, coalesce(status_code, 0), coalesce(error, ''), duration_ms, attempted_at
+		FROM webhook_delivery_attempts WHERE delivery_id IN (SELECT value FROM json_each(?1)) ORDER BY delivery_id, id`
+	rows, err := r.DB.QueryContext(ctx, query, idList(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			deliveryID int64
+			a          models.WebhookAttempt
+		)
+		if err := rows.Scan(&deliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMs, &a.AttemptedAt); err != nil {
+			return fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		byID[deliveryID].Log = append(byID[deliveryID].Log, a)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over webhook attempts: %w", err)
+	}
+	return nil
+}
+
+// RetryWebhookDelivery schedules a delivery that is not delivered yet for
+// an immediate fresh series of attempts.
+func (r *repository) RetryWebhookDelivery(ctx context.Context, deliveryId int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status models.WebhookDeliveryStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM webhook_deliveries WHERE id = ?1`, deliveryId).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("webhook delivery not found with id %d: %w", deliveryId, models.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if status == models.WebhookDeliveryDelivered {
+		return fmt.Errorf("%w: webhook delivery %d was already delivered", models.ErrInvalidInput, deliveryId)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = `+now+`
+		WHERE id = ?1`, deliveryId)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook delivery: %w", err)
+	}
+	return nil
+}
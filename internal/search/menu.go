@@ -0,0 +1,261 @@
+// Package search implements in-memory menu search for repositories that have
+// no full-text engine of their own. It mirrors the Postgres implementation:
+// name, category and description are weighted A, B and C like the
+// search_vector column, and names are also matched by trigram similarity
+// (as pg_trgm's word_similarity does) so typos still find the dish.
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// SimilarityThreshold is the minimum trigram similarity for a typo match.
+const SimilarityThreshold = 0.3
+
+// Field weights, matching ts_rank's default {D, C, B, A} = {0.1, 0.2, 0.4, 1.0}.
+const (
+	weightName        = 1.0
+	weightCategory    = 0.4
+	weightDescription = 0.2
+)
+
+// MarkStart and MarkStop stand in for the highlight markers until the text
+// around them has been escaped for HTML. They are private use code points,
+// which are dropped from the text being highlighted.
+const (
+	MarkStart = "\uE000"
+	MarkStop  = "\uE001"
+)
+
+var markReplacer = strings.NewReplacer(MarkStart, models.HighlightStart, MarkStop, models.HighlightStop)
+
+// EscapeHighlights escapes text marked with MarkStart and MarkStop for HTML
+// and then turns the marks into the highlight markers, so only those are
+// markup.
+func EscapeHighlights(marked string) string {
+	return markReplacer.Replace(html.EscapeString(marked))
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "the": true, "to": true, "with": true,
+}
+
+// MenuItems ranks items against the query and returns the requested page
+// along with the total number of matches.
+func MenuItems(items []models.MenuItem, query models.MenuSearchQuery) ([]models.MenuSearchHit, int) {
+	query.Normalize()
+	terms := stems(query.Text)
+	queryWords := words(query.Text)
+
+	hits := make([]models.MenuSearchHit, 0)
+	for _, item := range items {
+		textRank, ok := textMatch(item, terms)
+		similarity := wordSimilarity(queryWords, words(item.Name))
+		if !ok && similarity < SimilarityThreshold {
+			continue
+		}
+
+		hits = append(hits, models.MenuSearchHit{
+			Item: item,
+			Rank: textRank + similarity,
+			Highlights: models.MenuSearchHighlight{
+				Name:        highlight(item.Name, terms, queryWords),
+				Description: highlight(item.Description, terms, nil),
+			},
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Item.ID < hits[j].Item.ID
+	})
+
+	total := len(hits)
+	start := query.Offset()
+	if start > total {
+		start = total
+	}
+	end := start + query.Limit
+	if end > total {
+		end = total
+	}
+	return hits[start:end], total
+}
+
+// textMatch behaves like websearch_to_tsquery: every term has to appear in
+// at least one field. The rank adds up the best field weight of each term.
+func textMatch(item models.MenuItem, terms []string) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, false
+	}
+	fields := []struct {
+		stems  map[string]bool
+		weight float64
+	}{
+		{stemSet(item.Name), weightName},
+		{stemSet(item.Category), weightCategory},
+		{stemSet(item.Description), weightDescription},
+	}
+
+	rank := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, field := range fields {
+			if field.stems[term] && field.weight > best {
+				best = field.weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		rank += best
+	}
+	return rank / float64(len(terms)), true
+}
+
+// highlight wraps every word of text that matches one of the terms, or is
+// similar enough to one of the fuzzy words, in highlight markers. The rest
+// of the text is escaped for HTML.
+func highlight(text string, terms []string, fuzzy []string) string {
+	termSet := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		termSet[t] = true
+	}
+
+	var b strings.Builder
+	runes := []rune(strings.NewReplacer(MarkStart, "", MarkStop, "").Replace(text))
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if termSet[stem(strings.ToLower(word))] || similarToAny(word, fuzzy) {
+			b.WriteString(MarkStart + word + MarkStop)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return EscapeHighlights(b.String())
+}
+
+func similarToAny(word string, candidates []string) bool {
+	for _, c := range candidates {
+		if similarity(trigrams(c), trigrams(word)) >= SimilarityThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// wordSimilarity approximates pg_trgm's word_similarity: the best trigram
+// similarity between the query and any run of consecutive words of the
+// target with the same length as the query.
+func wordSimilarity(queryWords, targetWords []string) float64 {
+	if len(queryWords) == 0 || len(targetWords) == 0 {
+		return 0
+	}
+	q := trigrams(strings.Join(queryWords, " "))
+	n := len(queryWords)
+	if n > len(targetWords) {
+		n = len(targetWords)
+	}
+
+	best := 0.0
+	for i := 0; i+n <= len(targetWords); i++ {
+		if s := similarity(q, trigrams(strings.Join(targetWords[i:i+n], " "))); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// trigrams extracts pg_trgm style trigrams: each word is lower-cased and
+// padded with two spaces in front and one behind.
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range words(text) {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func stems(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, w := range words(text) {
+		if stopWords[w] {
+			continue
+		}
+		s := stem(w)
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func stemSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, s := range stems(text) {
+		set[s] = true
+	}
+	return set
+}
+
+// stem is a deliberately small English plural stripper; it is enough to
+// make "pizzas" find "pizza" and "wing" find "wings".
+func stem(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") ||
+		strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes")):
+		return w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,220 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct{ word, want string }{
+		{"pizzas", "pizza"},
+		{"wings", "wing"},
+		{"fries", "fry"},
+		{"pies", "pie"}, // too short for -ies
+		{"sandwiches", "sandwich"},
+		{"dishes", "dish"},
+		{"glasses", "glass"},
+		{"boxes", "box"},
+		{"glass", "glass"},
+		{"hummus", "hummu"},
+		{"bus", "bus"},
+		{"tea", "tea"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTokenising(t *testing.T) {
+	tests := []struct {
+		text      string
+		words     []string
+		stems     []string
+		trigramsN int
+	}{
+		{"", nil, nil, 0},
+		{"  ", nil, nil, 0},
+		{"Chicken Wings", []string{"chicken", "wings"}, []string{"chicken", "wing"}, 14},
+		{"the wings AND a wing", []string{"the", "wings", "and", "a", "wing"}, []string{"wing"}, 16},
+		{"Fish & Chips!", []string{"fish", "chips"}, []string{"fish", "chip"}, 11},
+		{"<b>crème brûlée</b>", []string{"b", "crème", "brûlée", "b"}, []string{"b", "crème", "brûlée"}, 14},
+		{"7up, 2x", []string{"7up", "2x"}, []string{"7up", "2x"}, 7},
+	}
+	for _, tt := range tests {
+		if got := words(tt.text); !slices.Equal(got, tt.words) {
+			t.Errorf("words(%q) = %q, want %q", tt.text, got, tt.words)
+		}
+		if got := stems(tt.text); !slices.Equal(got, tt.stems) {
+			t.Errorf("stems(%q) = %q, want %q", tt.text, got, tt.stems)
+		}
+		if got := len(trigrams(tt.text)); got != tt.trigramsN {
+			t.Errorf("trigrams(%q) has %d trigrams, want %d", tt.text, got, tt.trigramsN)
+		}
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		query, target string
+		similar       bool
+	}{
+		{"margherita", "Pizza Margherita", true},
+		{"margarita", "Pizza Margherita", true},
+		{"pizza", "Pizza Margherita", true},
+		{"piza", "Pizza Margherita", true},
+		{"burger", "Pizza Margherita", false},
+		{"xylophone", "Caesar Salad", false},
+		{"", "Caesar Salad", false},
+	}
+	for _, tt := range tests {
+		s := wordSimilarity(words(tt.query), words(tt.target))
+		if (s >= SimilarityThreshold) != tt.similar {
+			t.Errorf("wordSimilarity(%q, %q) = %.2f, want similar %v", tt.query, tt.target, s, tt.similar)
+		}
+	}
+	if s := wordSimilarity(words("pizza"), words("Pizza Margherita")); s != 1 {
+		t.Errorf("an exact word has similarity %.2f, want 1", s)
+	}
+}
+
+var menu = []models.MenuItem{
+	{ID: 1, Name: "Pizza Margherita", Category: "Pizza", Description: "Tomato, mozzarella and basil"},
+	{ID: 2, Name: "Garlic Bread", Category: "Starters", Description: "Baked with garlic butter"},
+	{ID: 3, Name: "Tomato Soup", Category: "Soups", Description: "Roasted tomatoes, served with bread"},
+	{ID: 4, Name: "Bruschetta", Category: "Starters", Description: "Toasted bread with tomato"},
+	{ID: 5, Name: "Pepperoni Pizza", Category: "Pizza", Description: "Spicy pepperoni"},
+	{ID: 6, Name: "Tiramisu", Category: "Desserts", Description: "Coffee soaked sponge"},
+	{ID: 7, Name: "Breadsticks", Category: "Bread", Description: "Crunchy"},
+}
+
+func hitIDs(hits []models.MenuSearchHit) []int {
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.Item.ID
+	}
+	return ids
+}
+
+func TestMenuItemsRanking(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// Names rank above categories, categories above descriptions
+		{"tomato", []int{3, 1, 4}},
+		// and name similarity breaks the tie between descriptions
+		{"bread", []int{2, 7, 4, 3}},
+		// Plurals
+		{"pizzas", []int{1, 5}},
+		// Every term has to match, somewhere
+		{"mozzarella basil", []int{1}},
+		{"sponge butter", nil},
+		// Typos in names
+		{"margarita", []int{1}},
+		{"tiramsu", []int{6}},
+		{"xylophone", nil},
+		{"and the", nil},
+	}
+	for _, tt := range tests {
+		hits, total := MenuItems(menu, models.MenuSearchQuery{Text: tt.query})
+		if got := hitIDs(hits); !slices.Equal(got, tt.want) || total != len(tt.want) {
+			t.Errorf("MenuItems(%q) = %v of %d, want %v", tt.query, got, total, tt.want)
+		}
+		for i := 1; i < len(hits); i++ {
+			if hits[i].Rank > hits[i-1].Rank {
+				t.Errorf("MenuItems(%q): rank %v before %v", tt.query, hits[i-1].Rank, hits[i].Rank)
+			}
+		}
+	}
+}
+
+func TestMenuItemsTiesAndPages(t *testing.T) {
+	same := []models.MenuItem{
+		{ID: 9, Name: "Soup", Category: "Soups"},
+		{ID: 4, Name: "Soup", Category: "Soups"},
+		{ID: 7, Name: "Soup", Category: "Soups"},
+	}
+	hits, total := MenuItems(same, models.MenuSearchQuery{Text: "soup"})
+	if got := hitIDs(hits); !slices.Equal(got, []int{4, 7, 9}) || total != 3 {
+		t.Errorf("equal ranks in order %v of %d, want by id", got, total)
+	}
+
+	tests := []struct {
+		page, limit int
+		want        []int
+	}{
+		{1, 2, []int{4, 7}},
+		{2, 2, []int{9}},
+		{3, 2, []int{}},
+		{0, 0, []int{4, 7, 9}}, // defaults
+	}
+	for _, tt := range tests {
+		hits, total := MenuItems(same, models.MenuSearchQuery{Text: "soup", Page: tt.page, Limit: tt.limit})
+		if got := hitIDs(hits); !slices.Equal(got, tt.want) || total != 3 {
+			t.Errorf("page %d of %d: %v of %d, want %v of 3", tt.page, tt.limit, got, total, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		fuzzy []string
+		want  string
+	}{
+		{"match", "Pizza Margherita", []string{"pizza"}, nil, "<mark>Pizza</mark> Margherita"},
+		{"every occurrence", "Garlic bread, garlic butter", []string{"garlic"}, nil, "<mark>Garlic</mark> bread, <mark>garlic</mark> butter"},
+		{"stemmed", "Crispy wings", []string{"wing"}, nil, "Crispy <mark>wings</mark>"},
+		{"fuzzy", "Pizza Margherita", nil, []string{"margarita"}, "Pizza <mark>Margherita</mark>"},
+		{"whole words only", "Breadsticks", []string{"bread"}, nil, "Breadsticks"},
+		{"no match", "Tiramisu", []string{"pizza"}, nil, "Tiramisu"},
+		{"empty", "", []string{"pizza"}, nil, ""},
+		{"script", `<script>alert("pizza")</script>`, []string{"pizza"}, nil,
+			`&lt;script&gt;alert(&#34;<mark>pizza</mark>&#34;)&lt;/script&gt;`},
+		{"markers in the text", "<mark>Fish</mark> & Chips", []string{"chip"}, nil,
+			"&lt;mark&gt;Fish&lt;/mark&gt; &amp; <mark>Chips</mark>"},
+		{"attribute", `Pizza' onmouseover='x`, []string{"pizza"}, nil, "<mark>Pizza</mark>&#39; onmouseover=&#39;x"},
+		{"stand-ins in the text", "Pizza" + MarkStop + "<b>" + MarkStart, []string{"pizza"}, nil, "<mark>Pizza</mark>&lt;b&gt;"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.terms, tt.fuzzy); got != tt.want {
+			t.Errorf("%s: highlight(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMenuItemsEscapesHighlights(t *testing.T) {
+	items := []models.MenuItem{{ID: 1, Name: "Fish & Chips <img src=x onerror=alert(1)>", Description: "Chips with <b>vinegar</b>"}}
+	hits, _ := MenuItems(items, models.MenuSearchQuery{Text: "chips"})
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1", len(hits))
+	}
+	h := hits[0].Highlights
+	if h.Name != "Fish &amp; <mark>Chips</mark> &lt;img src=x onerror=alert(1)&gt;" ||
+		h.Description != "<mark>Chips</mark> with &lt;b&gt;vinegar&lt;/b&gt;" {
+		t.Errorf("highlights %+v", h)
+	}
+	if hits[0].Item.Name != items[0].Name {
+		t.Errorf("item name changed to %q", hits[0].Item.Name)
+	}
+}
+
+func TestEscapeHighlights(t *testing.T) {
+	tests := []struct{ marked, want string }{
+		{"", ""},
+		{"plain", "plain"},
+		{MarkStart + "Tom & Jerry" + MarkStop + " <3", "<mark>Tom &amp; Jerry</mark> &lt;3"},
+		{"<mark>x</mark>", "&lt;mark&gt;x&lt;/mark&gt;"},
+	}
+	for _, tt := range tests {
+		if got := EscapeHighlights(tt.marked); got != tt.want {
+			t.Errorf("EscapeHighlights(%q) = %q, want %q", tt.marked, got, tt.want)
+		}
+	}
+}
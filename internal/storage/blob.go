@@ -0,0 +1,48 @@
+// Package storage provides blob storage for uploaded files such as menu
+// item images.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+type BlobInfo struct {
+	Key          string
+	ContentType  string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// BlobStore stores opaque blobs under slash separated keys.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader) (BlobInfo, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+	// URL returns the public URL the blob is served from.
+	URL(key string) string
+}
+
+// ValidateKey rejects keys that could escape the store, e.g. "../x" or "/etc/x".
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
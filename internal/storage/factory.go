@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+)
+
+func NewBlobStore(cfg *config.StorageConfig) (BlobStore, error) {
+
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStore(cfg.Dir, cfg.BaseURL)
+	default:
+		return nil, errors.New("unsupported storage driver: " + cfg.Driver)
+	}
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) (BlobStore, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := NewLocalStore(root, "http://localhost:8080/images/")
+	if err != nil {
+		t.Fatalf("NewLocalStore() failed: %v", err)
+	}
+	return store, root
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"menu/1/abc/thumbnail.jpg", true},
+		{"a", true},
+		{"a..b/c", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../secret", false},
+		{"menu/../../secret", false},
+		{"menu/./x.jpg", false},
+		{"menu//x.jpg", false},
+		{"menu/", false},
+		{`menu\..\x.jpg`, false},
+	}
+	for _, tt := range tests {
+		err := ValidateKey(tt.key)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateKey(%q) = %v, want nil", tt.key, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateKey(%q) = %v, want ErrInvalidKey", tt.key, err)
+		}
+	}
+}
+
+func TestLocalStorePutGet(t *testing.T) {
+	ctx := context.Background()
+	store, root := newTestStore(t)
+
+	info, err := store.Put(ctx, "menu/1/abc/large.jpg", "image/jpeg", strings.NewReader("jpeg bytes"))
+	if err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if info.Size != 10 || info.ContentType != "image/jpeg" || info.ETag == "" {
+		t.Errorf("Put() info = %+v", info)
+	}
+	if _, err := os.Stat(filepath.Join(root, "menu", "1", "abc", "large.jpg")); err != nil {
+		t.Errorf("blob not stored below the root: %v", err)
+	}
+
+	body, got, err := store.Get(ctx, "menu/1/abc/large.jpg")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "jpeg bytes" {
+		t.Errorf("Get() body = %q", data)
+	}
+	if got.ContentType != "image/jpeg" || got.Size != 10 || got.ETag != info.ETag {
+		t.Errorf("Get() info = %+v, want %+v", got, info)
+	}
+
+	// Overwriting replaces the blob whole
+	if _, err := store.Put(ctx, "menu/1/abc/large.jpg", "image/jpeg", strings.NewReader("new")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	body, _, _ = store.Get(ctx, "menu/1/abc/large.jpg")
+	data, _ = io.ReadAll(body)
+	body.Close()
+	if string(data) != "new" {
+		t.Errorf("Get() after overwrite = %q", data)
+	}
+
+	// No temp files are left behind
+	entries, _ := os.ReadDir(filepath.Join(root, "menu", "1", "abc"))
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want 1", len(entries))
+	}
+
+	if got := store.URL("menu/1/abc/large.jpg"); got != "http://localhost:8080/images/menu/1/abc/large.jpg" {
+		t.Errorf("URL() = %q", got)
+	}
+}
+
+func TestLocalStoreNotFound(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+	if _, err := store.Put(ctx, "menu/1/x.png", "image/png", strings.NewReader("x")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	for _, key := range []string{"menu/2/x.png", "menu/1"} {
+		if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+			t.Errorf("Get(%q) error = %v, want ErrBlobNotFound", key, err)
+		}
+	}
+}
+
+func TestLocalStoreRejectsTraversal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "blobs"), "")
+	if err != nil {
+		t.Fatalf("NewLocalStore() failed: %v", err)
+	}
+	outside := filepath.Join(dir, "outside.txt")
+	if err := os.WriteFile(outside, []byte("keep me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../outside.txt", "/etc/passwd", "a/../../outside.txt", `..\outside.txt`} {
+		if _, err := store.Put(ctx, key, "text/plain", strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.DeletePrefix(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("DeletePrefix(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	// Deleting the whole store is not a prefix either
+	if err := store.DeletePrefix(ctx, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("DeletePrefix(\"\") error = %v, want ErrInvalidKey", err)
+	}
+
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "keep me" {
+		t.Errorf("file outside the store changed: %q, %v", data, err)
+	}
+}
+
+func TestLocalStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+	for _, key := range []string{"menu/1/a/thumbnail.jpg", "menu/1/a/large.jpg", "menu/1/b/large.jpg", "menu/10/a/large.jpg"} {
+		if _, err := store.Put(ctx, key, "image/jpeg", strings.NewReader(key)); err != nil {
+			t.Fatalf("Put(%q) failed: %v", key, err)
+		}
+	}
+
+	if err := store.Delete(ctx, "menu/1/b/large.jpg"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, _, err := store.Get(ctx, "menu/1/b/large.jpg"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrBlobNotFound", err)
+	}
+	// Deleting a missing blob is not an error
+	if err := store.Delete(ctx, "menu/1/b/large.jpg"); err != nil {
+		t.Errorf("Delete() of a missing blob = %v", err)
+	}
+
+	// A prefix with or without the trailing slash removes the whole folder,
+	// but not its siblings sharing the same leading characters
+	if err := store.DeletePrefix(ctx, "menu/1/a/"); err != nil {
+		t.Fatalf("DeletePrefix() failed: %v", err)
+	}
+	for _, key := range []string{"menu/1/a/thumbnail.jpg", "menu/1/a/large.jpg"} {
+		if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+			t.Errorf("Get(%q) after DeletePrefix() error = %v, want ErrBlobNotFound", key, err)
+		}
+	}
+	body, _, err := store.Get(ctx, "menu/10/a/large.jpg")
+	if err != nil {
+		t.Fatalf("DeletePrefix() removed a sibling: %v", err)
+	}
+	body.Close()
+}
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/models"
 )
@@ -13,3 +15,32 @@ func (u *UserUsecase) GetOrderByTableId(ctx context.Context, tableId int) (model
 func (u *UserUsecase) GetAllOrders(ctx context.Context) ([]models.Order, error) {
 	return u.repo.GetAllOrders(ctx)
 }
+
+// GetOrdersBetween returns the orders placed from `from` up to, but not
+// including, `to`, oldest first.
+func (u *UserUsecase) GetOrdersBetween(ctx context.Context, from, to time.Time) ([]models.Order, error) {
+	query := models.OrderListQuery{
+		From:   from,
+		To:     to,
+		SortBy: models.OrderSortCreatedAt,
+		Limit:  models.MaxOrderListLimit,
+	}
+	placed := make([]models.Order, 0)
+	for {
+		page, err := listOrders(ctx, u.repo, query)
+		if err != nil {
+			return nil, err
+		}
+		placed = append(placed, page.Orders...)
+		if page.NextCursor == "" {
+			return placed, nil
+		}
+		last := page.Orders[len(page.Orders)-1]
+		placedAt, err := time.Parse(time.RFC3339Nano, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of order %d: %w", last.ID, err)
+		}
+		after := query.CursorAfter(last, placedAt)
+		query.After = &after
+	}
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type BundleIUsecase interface {
+	CreateBundle(ctx context.Context, bundle models.Bundle) (int, error)
+	GetBundle(ctx context.Context, id int) (models.Bundle, error)
+	GetActiveBundles(ctx context.Context) ([]models.Bundle, error)
+}
+
+type BundleUsecase struct {
+	repo interfaces.Repository
+}
+
+func NewBundleUsecase(repo interfaces.Repository) BundleIUsecase {
+	return &BundleUsecase{
+		repo: repo,
+	}
+}
+
+func (b *BundleUsecase) CreateBundle(ctx context.Context, bundle models.Bundle) (int, error) {
+	if err := bundle.Validate(); err != nil {
+		return 0, err
+	}
+	// Explicitly listed items have to exist
+	for _, slot := range bundle.Slots {
+		for _, id := range slot.MenuItemIDs {
+			if _, err := b.repo.GetMenuItemById(ctx, id); err != nil {
+				return 0, fmt.Errorf("%w: slot %q: %v", models.ErrInvalidInput, slot.Name, err)
+			}
+		}
+	}
+	return b.repo.CreateBundle(ctx, bundle)
+}
+
+func (b *BundleUsecase) GetBundle(ctx context.Context, id int) (models.Bundle, error) {
+	return b.repo.GetBundleById(ctx, id)
+}
+
+func (b *BundleUsecase) GetActiveBundles(ctx context.Context) ([]models.Bundle, error) {
+	bundles, err := b.repo.GetAllBundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.Bundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		if bundle.Active {
+			active = append(active, bundle)
+		}
+	}
+	return active, nil
+}
+
+// priceBundleLine validates the choices of a bundle line and fills in its
+// components and bundle price, using the given menu item prices.
+func priceBundleLine(ctx context.Context, repo interfaces.Repository, item *models.OrderItem, prices map[int]int) error {
+	bundle, err := repo.GetBundleById(ctx, *item.BundleID)
+	if err != nil {
+		return err
+	}
+	if !bundle.Active {
+		return fmt.Errorf("%w: bundle %q is not available", models.ErrInvalidInput, bundle.Name)
+	}
+
+	menuItems := make(map[int]models.MenuItem, len(item.Choices))
+	for _, choice := range item.Choices {
+		if _, ok := menuItems[choice.MenuItemID]; ok {
+			continue
+		}
+		menuItem, err := repo.GetMenuItemById(ctx, choice.MenuItemID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+		}
+		if !menuItem.Available {
+			return fmt.Errorf("%w: %q is not on the menu", models.ErrInvalidInput, menuItem.Name)
+		}
+		if price, ok := prices[menuItem.ID]; ok {
+			menuItem.Price = price
+		}
+		menuItems[menuItem.ID] = menuItem
+	}
+
+	if err := bundle.ValidateChoices(item.Choices, menuItems); err != nil {
+		return err
+	}
+
+	item.MenuItemID = 0
+	item.Price = bundle.PriceFor(item.Choices, menuItems)
+	item.Components = make([]models.OrderItemComponent, len(item.Choices))
+	for i, choice := range item.Choices {
+		item.Components[i] = models.OrderItemComponent{
+			SlotID:     choice.SlotID,
+			MenuItemID: choice.MenuItemID,
+			Quantity:   choice.Quantity,
+		}
+	}
+	return nil
+}
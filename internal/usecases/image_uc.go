@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/abdullahnettoor/tastybites/internal/imaging"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
+)
+
+// Maximum accepted upload size for menu item images.
+const MaxImageUploadBytes = 5 << 20
+
+var menuImageVariants = []imaging.Variant{
+	{Name: "thumbnail", MaxWidth: 160, MaxHeight: 160},
+	{Name: "medium", MaxWidth: 640, MaxHeight: 640},
+	{Name: "large", MaxWidth: 1280, MaxHeight: 1280},
+}
+
+// UploadMenuItemImage validates the upload, stores every variant and points
+// the menu item at them. Variants of a previous upload are removed afterwards.
+func (m *MenuUsecase) UploadMenuItemImage(ctx context.Context, itemID int, data []byte) (models.MenuImages, error) {
+	if len(data) > MaxImageUploadBytes {
+		return models.MenuImages{}, imaging.ErrTooLarge
+	}
+
+	item, err := m.repo.GetMenuItemById(ctx, itemID)
+	if err != nil {
+		return models.MenuImages{}, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return models.MenuImages{}, err
+	}
+
+	// Keys are content addressed so they can be cached forever
+	sum := sha256.Sum256(data)
+	prefix := fmt.Sprintf("menu/%d/%s", itemID, hex.EncodeToString(sum[:8]))
+
+	urls := make(map[string]string, len(menuImageVariants))
+	for _, variant := range menuImageVariants {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Fit(img, variant)); err != nil {
+			return models.MenuImages{}, fmt.Errorf("failed to encode %s variant: %w", variant.Name, err)
+		}
+		key := prefix + "/" + variant.Name + ".jpg"
+		if _, err := m.blobs.Put(ctx, key, "image/jpeg", &buf); err != nil {
+			return models.MenuImages{}, fmt.Errorf("failed to store %s variant: %w", variant.Name, err)
+		}
+		urls[variant.Name] = m.blobs.URL(key)
+	}
+
+	images := models.MenuImages{
+		Thumbnail: urls["thumbnail"],
+		Medium:    urls["medium"],
+		Large:     urls["large"],
+		Key:       prefix,
+	}
+	if err := m.repo.UpdateMenuItemImages(ctx, itemID, images); err != nil {
+		return models.MenuImages{}, err
+	}
+
+	if old := item.Images.Key; old != "" && old != prefix {
+		if err := m.blobs.DeletePrefix(ctx, old); err != nil {
+			log.Printf("failed to delete old images %s: %v", old, err)
+		}
+	}
+	return images, nil
+}
+
+func (m *MenuUsecase) OpenImage(ctx context.Context, key string) (io.ReadCloser, storage.BlobInfo, error) {
+	return m.blobs.Get(ctx, key)
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/imaging"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
+)
+
+func pngOf(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadMenuItemImage(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	blobs, err := storage.NewLocalStore(filepath.Join(t.TempDir(), "blobs"), "/images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu := NewMenuUsecase(repo, blobs)
+
+	images, err := menu.UploadMenuItemImage(ctx, 1, pngOf(t, 2000, 1000, color.RGBA{G: 255, A: 255}))
+	if err != nil {
+		t.Fatalf("UploadMenuItemImage() failed: %v", err)
+	}
+	if !strings.HasPrefix(images.Key, "menu/1/") {
+		t.Errorf("key = %q, want it under menu/1/", images.Key)
+	}
+
+	// Every variant fits its box, keeps the aspect ratio and is a JPEG
+	wantSizes := map[string][2]int{"thumbnail": {160, 80}, "medium": {640, 320}, "large": {1280, 640}}
+	urls := map[string]string{"thumbnail": images.Thumbnail, "medium": images.Medium, "large": images.Large}
+	for name, size := range wantSizes {
+		key := images.Key + "/" + name + ".jpg"
+		if urls[name] != "/images/"+key {
+			t.Errorf("%s URL = %q, want /images/%s", name, urls[name], key)
+		}
+		body, info, err := menu.OpenImage(ctx, key)
+		if err != nil {
+			t.Fatalf("OpenImage(%s) failed: %v", key, err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		img, contentType, err := imaging.Decode(data)
+		if err != nil || contentType != "image/jpeg" || info.ContentType != "image/jpeg" {
+			t.Fatalf("%s variant is %q (%q), %v", name, contentType, info.ContentType, err)
+		}
+		if b := img.Bounds(); b.Dx() != size[0] || b.Dy() != size[1] {
+			t.Errorf("%s variant is %dx%d, want %dx%d", name, b.Dx(), b.Dy(), size[0], size[1])
+		}
+	}
+
+	stored, err := repo.GetMenuItemById(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Images.Key != images.Key || stored.Images.Large != images.Large {
+		t.Errorf("menu item images = %+v, want %+v", stored.Images, images)
+	}
+
+	// A new upload replaces the variants of the previous one
+	next, err := menu.UploadMenuItemImage(ctx, 1, pngOf(t, 100, 100, color.White))
+	if err != nil {
+		t.Fatalf("UploadMenuItemImage() failed: %v", err)
+	}
+	if next.Key == images.Key {
+		t.Fatalf("different images got the same key %q", next.Key)
+	}
+	if _, _, err := menu.OpenImage(ctx, images.Key+"/large.jpg"); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("old variant still there: %v", err)
+	}
+}
+
+func TestUploadMenuItemImageRejects(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := storage.NewLocalStore(filepath.Join(t.TempDir(), "blobs"), "/images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu := NewMenuUsecase(memrepo.NewRepository(), blobs)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"not an image", []byte("<html><body>hi</body></html>"), imaging.ErrUnsupportedType},
+		{"over the upload limit", make([]byte, MaxImageUploadBytes+1), imaging.ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := menu.UploadMenuItemImage(ctx, 1, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("UploadMenuItemImage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
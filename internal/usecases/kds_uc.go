@@ -0,0 +1,201 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+// doneTicketsLimit is how many bumped tickets a station can recall from.
+const doneTicketsLimit = 20
+
+type KitchenIUsecase interface {
+	// SendToKitchen splits an order into a ticket per station and prints
+	// them. Sending an order again returns the tickets it already has.
+	SendToKitchen(ctx context.Context, orderId int) ([]models.KitchenTicket, error)
+
+	GetStations(ctx context.Context) ([]models.Station, error)
+	CreateStation(ctx context.Context, station models.Station) (models.Station, error)
+	// RouteCategory sends a menu category to a station; zero removes the
+	// route so the category goes to the default station.
+	RouteCategory(ctx context.Context, category string, stationId int) error
+	// RouteMenuItem sends one item to a station regardless of its category;
+	// zero routes it by category again.
+	RouteMenuItem(ctx context.Context, itemId, stationId int) error
+
+	// GetStationQueue lists a station's open tickets oldest first, or the
+	// latest done ones.
+	GetStationQueue(ctx context.Context, stationId int, status models.TicketStatus) ([]models.KitchenTicket, error)
+	GetOrderTickets(ctx context.Context, orderId int) ([]models.KitchenTicket, error)
+	BumpTicket(ctx context.Context, ticketId int) (models.KitchenTicket, error)
+	RecallTicket(ctx context.Context, ticketId int) (models.KitchenTicket, error)
+	BumpItem(ctx context.Context, itemId int) (models.KitchenTicket, error)
+	RecallItem(ctx context.Context, itemId int) (models.KitchenTicket, error)
+}
+
+type KitchenUsecase struct {
+	repo    interfaces.Repository
+	printer PrintIUsecase
+	events  *events.Bus
+}
+
+func NewKitchenUsecase(repo interfaces.Repository, printer PrintIUsecase, bus *events.Bus) KitchenIUsecase {
+	return &KitchenUsecase{
+		repo:    repo,
+		printer: printer,
+		events:  bus,
+	}
+}
+
+func (k *KitchenUsecase) SendToKitchen(ctx context.Context, orderId int) ([]models.KitchenTicket, error) {
+	order, err := k.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.KitchenStatus != "" {
+		return k.repo.GetKitchenTicketsByOrder(ctx, orderId)
+	}
+	routing, err := k.repo.GetStationRouting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	menuItems, err := menuItemsByID(ctx, k.repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.repo.CreateKitchenTickets(ctx, orderId, order.KitchenTickets(menuItems, routing)); err != nil {
+		return nil, err
+	}
+
+	// Screens have the tickets now; paper is a convenience that can be reprinted
+	if _, err := k.printer.PrintKitchenTickets(ctx, orderId); err != nil {
+		log.Printf("failed to print kitchen tickets for order %d: %v", orderId, err)
+	}
+
+	tickets, err := k.repo.GetKitchenTicketsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	for _, ticket := range tickets {
+		k.events.Publish(events.TicketEvent(events.TicketCreated, ticket))
+	}
+	return tickets, nil
+}
+
+func (k *KitchenUsecase) GetStations(ctx context.Context) ([]models.Station, error) {
+	return k.repo.GetStations(ctx)
+}
+
+func (k *KitchenUsecase) CreateStation(ctx context.Context, station models.Station) (models.Station, error) {
+	if err := station.Validate(); err != nil {
+		return models.Station{}, err
+	}
+	id, err := k.repo.CreateStation(ctx, station)
+	if err != nil {
+		return models.Station{}, err
+	}
+	return k.repo.GetStationById(ctx, id)
+}
+
+func (k *KitchenUsecase) RouteCategory(ctx context.Context, category string, stationId int) error {
+	category = strings.TrimSpace(category)
+	if category == "" || len(category) > 50 {
+		return fmt.Errorf("%w: category must be 1 to 50 characters", models.ErrInvalidInput)
+	}
+	if stationId != 0 {
+		if _, err := k.repo.GetStationById(ctx, stationId); err != nil {
+			return err
+		}
+	}
+	return k.repo.SetCategoryStation(ctx, category, stationId)
+}
+
+func (k *KitchenUsecase) RouteMenuItem(ctx context.Context, itemId, stationId int) error {
+	if stationId != 0 {
+		if _, err := k.repo.GetStationById(ctx, stationId); err != nil {
+			return err
+		}
+	}
+	return k.repo.SetMenuItemStation(ctx, itemId, stationId)
+}
+
+func (k *KitchenUsecase) GetStationQueue(ctx context.Context, stationId int, status models.TicketStatus) ([]models.KitchenTicket, error) {
+	if status == "" {
+		status = models.TicketStatusOpen
+	}
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: status must be open or done", models.ErrInvalidInput)
+	}
+	if _, err := k.repo.GetStationById(ctx, stationId); err != nil {
+		return nil, err
+	}
+	// Open tickets are the station's work and all of them are shown
+	limit := doneTicketsLimit
+	if status == models.TicketStatusOpen {
+		limit = 1000
+	}
+	return k.repo.GetStationTickets(ctx, stationId, status, limit)
+}
+
+func (k *KitchenUsecase) GetOrderTickets(ctx context.Context, orderId int) ([]models.KitchenTicket, error) {
+	if _, err := k.repo.GetOrderById(ctx, orderId); err != nil {
+		return nil, err
+	}
+	return k.repo.GetKitchenTicketsByOrder(ctx, orderId)
+}
+
+func (k *KitchenUsecase) BumpTicket(ctx context.Context, ticketId int) (models.KitchenTicket, error) {
+	changed, err := k.repo.SetTicketStatus(ctx, ticketId, models.TicketStatusDone)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	return k.ticketUpdated(ctx, ticketId, changed)
+}
+
+func (k *KitchenUsecase) RecallTicket(ctx context.Context, ticketId int) (models.KitchenTicket, error) {
+	changed, err := k.repo.SetTicketStatus(ctx, ticketId, models.TicketStatusOpen)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	return k.ticketUpdated(ctx, ticketId, changed)
+}
+
+func (k *KitchenUsecase) BumpItem(ctx context.Context, itemId int) (models.KitchenTicket, error) {
+	ticketID, changed, err := k.repo.SetTicketItemStatus(ctx, itemId, models.TicketItemReady)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	return k.ticketUpdated(ctx, ticketID, changed)
+}
+
+func (k *KitchenUsecase) RecallItem(ctx context.Context, itemId int) (models.KitchenTicket, error) {
+	ticketID, changed, err := k.repo.SetTicketItemStatus(ctx, itemId, models.TicketItemPending)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	return k.ticketUpdated(ctx, ticketID, changed)
+}
+
+// ticketUpdated loads a bumped or recalled ticket and announces it, and the
+// order's new kitchen status when that changed.
+func (k *KitchenUsecase) ticketUpdated(ctx context.Context, ticketId int, orderChanged bool) (models.KitchenTicket, error) {
+	ticket, err := k.repo.GetKitchenTicketById(ctx, ticketId)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	k.events.Publish(events.TicketEvent(events.TicketUpdated, ticket))
+
+	if orderChanged {
+		order, err := k.repo.GetOrderById(ctx, ticket.OrderID)
+		if err != nil {
+			return models.KitchenTicket{}, err
+		}
+		k.events.Publish(events.OrderEvent(events.OrderKitchenStatusChanged, order))
+	}
+	return ticket, nil
+}
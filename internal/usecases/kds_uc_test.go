@@ -0,0 +1,399 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+// In the sample data the kitchen (1) is the default station, and burgers go
+// to the grill (2), main courses to the fryer (3), desserts to pastry (4) and
+// drinks to the bar (5).
+const (
+	kitchenStation = 1
+	grillStation   = 2
+	fryerStation   = 3
+	pastryStation  = 4
+	barStation     = 5
+)
+
+// fakePrinter records the orders whose kitchen tickets were printed.
+type fakePrinter struct {
+	PrintIUsecase
+	printed []int
+	err     error
+}
+
+func (p *fakePrinter) PrintKitchenTickets(ctx context.Context, orderId int) ([]models.PrintJob, error) {
+	p.printed = append(p.printed, orderId)
+	return nil, p.err
+}
+
+type kitchenTest struct {
+	uc      KitchenIUsecase
+	repo    interfaces.Repository
+	printer *fakePrinter
+	events  *events.Subscription
+	orderID int
+}
+
+// newKitchenTest places an order of 2 burgers, fish and chips, 2 lemonades,
+// a brownie and 2 pizza meal deals of pepperoni pizza, iced tea and
+// tiramisu.
+func newKitchenTest(t *testing.T) kitchenTest {
+	t.Helper()
+	repo := memrepo.NewRepository()
+	bus := newTestBus(t)
+	sub := bus.Subscribe(events.Principal{Role: string(models.UserRoleAdmin)}, 0)
+	t.Cleanup(sub.Close)
+	printer := &fakePrinter{}
+
+	bundleID := 1
+	orderID, err := repo.CreateOrder(context.Background(), models.Order{
+		UserID: 2, TableID: 6, Status: models.OrderStatusPending,
+		Items: []models.OrderItem{
+			{MenuItemID: 3, Quantity: 2, Price: 1499},
+			{MenuItemID: 7, Quantity: 1, Price: 1699},
+			{MenuItemID: 11, Quantity: 2, Price: 399},
+			{MenuItemID: 4, Quantity: 1, Price: 699, Note: "no ice cream"},
+			{BundleID: &bundleID, Quantity: 2, Price: 1800, Components: []models.OrderItemComponent{
+				{SlotID: 1, MenuItemID: 5, Quantity: 1},
+				{SlotID: 2, MenuItemID: 12, Quantity: 1},
+				{SlotID: 3, MenuItemID: 8, Quantity: 1},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kitchenTest{
+		uc:      NewKitchenUsecase(repo, printer, bus),
+		repo:    repo,
+		printer: printer,
+		events:  sub,
+		orderID: orderID,
+	}
+}
+
+// published drains the events published so far.
+func (kt kitchenTest) published() []events.Type {
+	var types []events.Type
+	for {
+		select {
+		case e := <-kt.events.C:
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func (kt kitchenTest) kitchenStatus(t *testing.T) models.KitchenStatus {
+	t.Helper()
+	order, err := kt.repo.GetOrderById(context.Background(), kt.orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order.KitchenStatus
+}
+
+// ticketLines sums up tickets as station to "menu item x quantity" lines.
+func ticketLines(tickets []models.KitchenTicket) map[int][]string {
+	lines := make(map[int][]string)
+	for _, ticket := range tickets {
+		for _, item := range ticket.Items {
+			lines[ticket.StationID] = append(lines[ticket.StationID], fmt.Sprintf("%dx%d", item.MenuItemID, item.Quantity))
+		}
+	}
+	return lines
+}
+
+func TestSendToKitchenGroupsByStation(t *testing.T) {
+	tests := []struct {
+		name  string
+		route func(ctx context.Context, uc KitchenIUsecase) error
+		want  map[int][]string
+	}{
+		{
+			name: "by category, bundles split into their components",
+			want: map[int][]string{
+				kitchenStation: {"5x2"},
+				grillStation:   {"3x2"},
+				fryerStation:   {"7x1"},
+				pastryStation:  {"4x1", "8x2"},
+				barStation:     {"11x2", "12x2"},
+			},
+		},
+		{
+			name: "an item's own station wins over its category",
+			route: func(ctx context.Context, uc KitchenIUsecase) error {
+				return uc.RouteMenuItem(ctx, 11, kitchenStation)
+			},
+			want: map[int][]string{
+				kitchenStation: {"11x2", "5x2"},
+				grillStation:   {"3x2"},
+				fryerStation:   {"7x1"},
+				pastryStation:  {"4x1", "8x2"},
+				barStation:     {"12x2"},
+			},
+		},
+		{
+			name: "categories match whatever their case",
+			route: func(ctx context.Context, uc KitchenIUsecase) error {
+				return uc.RouteCategory(ctx, " Desserts ", barStation)
+			},
+			want: map[int][]string{
+				kitchenStation: {"5x2"},
+				grillStation:   {"3x2"},
+				fryerStation:   {"7x1"},
+				barStation:     {"11x2", "4x1", "12x2", "8x2"},
+			},
+		},
+		{
+			name: "unrouted categories go to the default station",
+			route: func(ctx context.Context, uc KitchenIUsecase) error {
+				return uc.RouteCategory(ctx, "main course", 0)
+			},
+			want: map[int][]string{
+				kitchenStation: {"7x1", "5x2"},
+				grillStation:   {"3x2"},
+				pastryStation:  {"4x1", "8x2"},
+				barStation:     {"11x2", "12x2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			kt := newKitchenTest(t)
+			if tt.route != nil {
+				if err := tt.route(ctx, kt.uc); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tickets, err := kt.uc.SendToKitchen(ctx, kt.orderID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ticketLines(tickets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tickets %v, want %v", got, tt.want)
+			}
+			for i, ticket := range tickets {
+				if i > 0 && ticket.StationID <= tickets[i-1].StationID {
+					t.Errorf("tickets out of station order: %d after %d", ticket.StationID, tickets[i-1].StationID)
+				}
+				if ticket.Status != models.TicketStatusOpen || ticket.OrderID != kt.orderID {
+					t.Errorf("ticket %+v, want an open ticket of the order", ticket)
+				}
+				for _, item := range ticket.Items {
+					if item.Status != models.TicketItemPending {
+						t.Errorf("item %+v, want it pending", item)
+					}
+				}
+			}
+			if status := kt.kitchenStatus(t); status != models.KitchenStatusQueued {
+				t.Errorf("kitchen status %q, want queued", status)
+			}
+			created := 0
+			for _, e := range kt.published() {
+				if e == events.TicketCreated {
+					created++
+				}
+			}
+			if created != len(tickets) || !reflect.DeepEqual(kt.printer.printed, []int{kt.orderID}) {
+				t.Errorf("announced %d tickets and printed %v, want %d and the order once", created, kt.printer.printed, len(tickets))
+			}
+		})
+	}
+}
+
+func TestSendToKitchenAgain(t *testing.T) {
+	ctx := context.Background()
+	kt := newKitchenTest(t)
+	kt.printer.err = errors.New("printer is out of paper")
+
+	first, err := kt.uc.SendToKitchen(ctx, kt.orderID)
+	if err != nil {
+		t.Fatalf("SendToKitchen() failed with the printer down: %v", err)
+	}
+	kt.published()
+
+	// Routing changed since does not move tickets already sent
+	if err := kt.uc.RouteCategory(ctx, "burgers", kitchenStation); err != nil {
+		t.Fatal(err)
+	}
+	again, err := kt.uc.SendToKitchen(ctx, kt.orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ticketLines(again), ticketLines(first)) || len(kt.printer.printed) != 1 || len(kt.published()) != 0 {
+		t.Errorf("sending again gave %v, printed %v; want the same tickets, printed once and not announced", ticketLines(again), kt.printer.printed)
+	}
+
+	if _, err := kt.uc.SendToKitchen(ctx, 999); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("SendToKitchen(999) error = %v, want not found", err)
+	}
+}
+
+func TestKitchenBumpAndRecall(t *testing.T) {
+	ctx := context.Background()
+	kt := newKitchenTest(t)
+	tickets, err := kt.uc.SendToKitchen(ctx, kt.orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kt.published()
+	byStation := make(map[int]models.KitchenTicket)
+	for _, ticket := range tickets {
+		byStation[ticket.StationID] = ticket
+	}
+	bar, pastry := byStation[barStation], byStation[pastryStation]
+	lemonade, icedTea := bar.Items[0].ID, bar.Items[1].ID
+
+	type step struct {
+		name    string
+		do      func() (models.KitchenTicket, error)
+		ticket  models.TicketStatus       // of the ticket returned
+		items   []models.TicketItemStatus // of its items
+		kitchen models.KitchenStatus
+		// whether the order's kitchen status was announced
+		announced bool
+	}
+	bumpItem := func(id int) func() (models.KitchenTicket, error) {
+		return func() (models.KitchenTicket, error) { return kt.uc.BumpItem(ctx, id) }
+	}
+	recallItem := func(id int) func() (models.KitchenTicket, error) {
+		return func() (models.KitchenTicket, error) { return kt.uc.RecallItem(ctx, id) }
+	}
+	bumpTicket := func(id int) func() (models.KitchenTicket, error) {
+		return func() (models.KitchenTicket, error) { return kt.uc.BumpTicket(ctx, id) }
+	}
+	recallTicket := func(id int) func() (models.KitchenTicket, error) {
+		return func() (models.KitchenTicket, error) { return kt.uc.RecallTicket(ctx, id) }
+	}
+	pending, ready := models.TicketItemPending, models.TicketItemReady
+	open, done := models.TicketStatusOpen, models.TicketStatusDone
+
+	steps := []step{
+		{"first item ready", bumpItem(lemonade), open, []models.TicketItemStatus{ready, pending}, models.KitchenStatusPreparing, true},
+		{"the same item again", bumpItem(lemonade), open, []models.TicketItemStatus{ready, pending}, models.KitchenStatusPreparing, false},
+		{"last item of the ticket", bumpItem(icedTea), done, []models.TicketItemStatus{ready, ready}, models.KitchenStatusPreparing, false},
+		{"an item of a done ticket recalled", recallItem(icedTea), open, []models.TicketItemStatus{ready, pending}, models.KitchenStatusPreparing, false},
+		{"everything recalled", recallTicket(bar.ID), open, []models.TicketItemStatus{pending, pending}, models.KitchenStatusQueued, true},
+		{"a ticket bumped whole", bumpTicket(pastry.ID), done, []models.TicketItemStatus{ready, ready}, models.KitchenStatusPreparing, true},
+		{"a done ticket bumped again", bumpTicket(pastry.ID), done, []models.TicketItemStatus{ready, ready}, models.KitchenStatusPreparing, false},
+		{"the bar", bumpTicket(bar.ID), done, []models.TicketItemStatus{ready, ready}, models.KitchenStatusPreparing, false},
+		{"the grill", bumpTicket(byStation[grillStation].ID), done, []models.TicketItemStatus{ready}, models.KitchenStatusPreparing, false},
+		{"the fryer", bumpTicket(byStation[fryerStation].ID), done, []models.TicketItemStatus{ready}, models.KitchenStatusPreparing, false},
+		{"the last ticket", bumpTicket(byStation[kitchenStation].ID), done, []models.TicketItemStatus{ready}, models.KitchenStatusReady, true},
+		{"a done ticket recalled", recallTicket(pastry.ID), open, []models.TicketItemStatus{pending, pending}, models.KitchenStatusPreparing, true},
+	}
+	for _, s := range steps {
+		ticket, err := s.do()
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		var items []models.TicketItemStatus
+		for _, item := range ticket.Items {
+			items = append(items, item.Status)
+			if (item.Status == ready) != (item.BumpedAt != "") {
+				t.Errorf("%s: item %d is %s bumped at %q", s.name, item.ID, item.Status, item.BumpedAt)
+			}
+		}
+		if ticket.Status != s.ticket || !reflect.DeepEqual(items, s.items) {
+			t.Errorf("%s: ticket %s with items %v, want %s with %v", s.name, ticket.Status, items, s.ticket, s.items)
+		}
+		if (ticket.Status == done) != (ticket.BumpedAt != "") {
+			t.Errorf("%s: ticket is %s bumped at %q", s.name, ticket.Status, ticket.BumpedAt)
+		}
+		if status := kt.kitchenStatus(t); status != s.kitchen {
+			t.Errorf("%s: kitchen status %q, want %q", s.name, status, s.kitchen)
+		}
+		want := []events.Type{events.TicketUpdated}
+		if s.announced {
+			want = append(want, events.OrderKitchenStatusChanged)
+		}
+		if got := kt.published(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: published %v, want %v", s.name, got, want)
+		}
+	}
+
+	// Bumping keeps the time an item was first bumped
+	first, _ := kt.uc.BumpItem(ctx, lemonade)
+	again, _ := kt.uc.BumpItem(ctx, lemonade)
+	if first.Items[0].BumpedAt != again.Items[0].BumpedAt {
+		t.Errorf("bumped again at %q, want %q kept", again.Items[0].BumpedAt, first.Items[0].BumpedAt)
+	}
+
+	for name, do := range map[string]func() (models.KitchenTicket, error){
+		"bump item":     bumpItem(999),
+		"recall item":   recallItem(999),
+		"bump ticket":   bumpTicket(999),
+		"recall ticket": recallTicket(999),
+	} {
+		if _, err := do(); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("%s 999: error %v, want not found", name, err)
+		}
+	}
+}
+
+func TestStationQueue(t *testing.T) {
+	ctx := context.Background()
+	kt := newKitchenTest(t)
+	tickets, err := kt.uc.SendToKitchen(ctx, kt.orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar := tickets[len(tickets)-1]
+
+	queue := func(status models.TicketStatus) []int {
+		t.Helper()
+		list, err := kt.uc.GetStationQueue(ctx, barStation, status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int{}
+		for _, ticket := range list {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+	check := func(when string, open, done []int) {
+		t.Helper()
+		if got := queue(""); !reflect.DeepEqual(got, open) {
+			t.Errorf("%s: open queue %v, want %v", when, got, open)
+		}
+		if got := queue(models.TicketStatusDone); !reflect.DeepEqual(got, done) {
+			t.Errorf("%s: done queue %v, want %v", when, got, done)
+		}
+	}
+
+	check("sent", []int{bar.ID}, []int{})
+	if _, err := kt.uc.BumpItem(ctx, bar.Items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	check("partly ready", []int{bar.ID}, []int{})
+	if _, err := kt.uc.BumpTicket(ctx, bar.ID); err != nil {
+		t.Fatal(err)
+	}
+	check("bumped", []int{}, []int{bar.ID})
+	if _, err := kt.uc.RecallItem(ctx, bar.Items[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	check("recalled", []int{bar.ID}, []int{})
+
+	if _, err := kt.uc.GetStationQueue(ctx, barStation, "cooking"); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("queue of an unknown status: error %v, want invalid input", err)
+	}
+	if _, err := kt.uc.GetStationQueue(ctx, 999, ""); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("queue of an unknown station: error %v, want not found", err)
+	}
+}
@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+)
+
+// ExportMenu lists the whole menu, archived items included, as the rows of
+// an import file.
+func (m *MenuUsecase) ExportMenu(ctx context.Context) ([]models.MenuImportRow, error) {
+	items, err := m.repo.GetAllMenuItems(ctx)
+	if err != nil && !errors.Is(err, models.ErrIsEmpty) {
+		return nil, err
+	}
+	rows := make([]models.MenuImportRow, len(items))
+	for i, item := range items {
+		rows[i] = models.NewMenuImportRow(item)
+	}
+	return rows, nil
+}
+
+// ImportMenu compares the rows with the menu by SKU and applies the
+// difference in one transaction. Nothing is applied on a dry run or when
+// any row is invalid; the result lists the changes and errors either way.
+func (m *MenuUsecase) ImportMenu(ctx context.Context, in models.MenuImport) (models.MenuImportResult, error) {
+	items, err := m.repo.GetAllMenuItems(ctx)
+	if err != nil && !errors.Is(err, models.ErrIsEmpty) {
+		return models.MenuImportResult{}, err
+	}
+	bySKU := make(map[string]models.MenuItem, len(items))
+	for _, item := range items {
+		bySKU[item.SKU] = item
+	}
+
+	result := models.MenuImportResult{
+		DryRun:  in.DryRun,
+		Changes: []models.MenuImportChange{},
+		Errors:  append([]models.MenuImportError{}, in.Errors...),
+	}
+	inFile := make(map[string]int, len(in.Rows)) // SKU to the first row with it
+	for _, row := range in.Rows {
+		row = normalizeImportRow(row)
+		if first, ok := inFile[row.SKU]; ok && row.SKU != "" {
+			result.Errors = append(result.Errors, models.MenuImportError{Row: row.Row, SKU: row.SKU, Field: "sku",
+				Message: fmt.Sprintf("SKU already appears on row %d", first)})
+			continue
+		}
+		inFile[row.SKU] = row.Row
+		if errs := row.Validate(); len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			continue
+		}
+
+		current, ok := bySKU[row.SKU]
+		if !ok {
+			result.Changes = append(result.Changes, models.MenuImportChange{
+				Row:    row.Row,
+				SKU:    row.SKU,
+				Action: models.MenuImportCreate,
+				Item:   importedItem(models.MenuItem{}, row),
+			})
+			result.Created++
+			continue
+		}
+
+		fields := changedFields(current, row)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		change := models.MenuImportChange{
+			Row:    row.Row,
+			SKU:    row.SKU,
+			Action: models.MenuImportUpdate,
+			Fields: fields,
+			Item:   importedItem(current, row),
+		}
+		if current.Available && !row.Available {
+			change.Action = models.MenuImportArchive
+			result.Archived++
+		} else {
+			result.Updated++
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if in.ArchiveMissing {
+		for _, item := range items {
+			if _, ok := inFile[item.SKU]; ok || !item.Available {
+				continue
+			}
+			item.Available = false
+			result.Changes = append(result.Changes, models.MenuImportChange{
+				SKU:    item.SKU,
+				Action: models.MenuImportArchive,
+				Fields: []string{"available"},
+				Item:   item,
+			})
+			result.Archived++
+		}
+	}
+
+	slices.SortStableFunc(result.Errors, func(a, b models.MenuImportError) int {
+		return cmp.Compare(a.Row, b.Row)
+	})
+	if len(result.Errors) > 0 || in.DryRun {
+		return result, nil
+	}
+	if len(result.Changes) > 0 {
+		if err := m.repo.ImportMenu(ctx, result.Changes); err != nil {
+			return models.MenuImportResult{}, err
+		}
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// normalizeImportRow trims the text fields and turns the allergens and
+// tags into sorted sets, so they compare with what the menu stores.
+func normalizeImportRow(row models.MenuImportRow) models.MenuImportRow {
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.Name = strings.TrimSpace(row.Name)
+	row.Description = strings.TrimSpace(row.Description)
+	row.Category = strings.TrimSpace(row.Category)
+
+	allergens := make([]models.Allergen, len(row.Allergens))
+	for i, a := range row.Allergens {
+		allergens[i] = models.Allergen(strings.ToLower(strings.TrimSpace(string(a))))
+	}
+	slices.Sort(allergens)
+	row.Allergens = slices.Compact(allergens)
+
+	tags := make([]models.DietaryTag, len(row.DietaryTags))
+	for i, t := range row.DietaryTags {
+		tags[i] = models.DietaryTag(strings.ToLower(strings.TrimSpace(string(t))))
+	}
+	slices.Sort(tags)
+	row.DietaryTags = slices.Compact(tags)
+	return row
+}
+
+// changedFields names the fields the row changes, as they are called in
+// the file.
+func changedFields(item models.MenuItem, row models.MenuImportRow) []string {
+	var fields []string
+	if item.Name != row.Name {
+		fields = append(fields, "name")
+	}
+	if item.Description != row.Description {
+		fields = append(fields, "description")
+	}
+	if item.Category != row.Category {
+		fields = append(fields, "category")
+	}
+	if item.Price != row.Price {
+		fields = append(fields, "price")
+	}
+	if item.Available != row.Available {
+		fields = append(fields, "available")
+	}
+	if (item.Stock == nil) != (row.Stock == nil) || item.Stock != nil && *item.Stock != *row.Stock {
+		fields = append(fields, "stock")
+	}
+	if item.SpicyLevel != row.SpicyLevel {
+		fields = append(fields, "spicyLevel")
+	}
+	if !slices.Equal(sorted(item.Allergens), row.Allergens) {
+		fields = append(fields, "allergens")
+	}
+	if !slices.Equal(sorted(item.DietaryTags), row.DietaryTags) {
+		fields = append(fields, "dietaryTags")
+	}
+	return fields
+}
+
+func sorted[T cmp.Ordered](values []T) []T {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return values
+}
+
+// importedItem is the item with the fields of the row applied. Images,
+// nutrition and everything else the file does not carry stay as they are.
+func importedItem(item models.MenuItem, row models.MenuImportRow) models.MenuItem {
+	item.SKU = row.SKU
+	item.Name = row.Name
+	item.Description = row.Description
+	item.Category = row.Category
+	item.Price = row.Price
+	item.Available = row.Available
+	item.Stock = row.Stock
+	item.SpicyLevel = row.SpicyLevel
+	item.Allergens = row.Allergens
+	item.DietaryTags = row.DietaryTags
+	return item
+}
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+// exportedRows are the sample menu as an export file, numbered from row 1.
+func exportedRows(t *testing.T, menu MenuIUsecase) []models.MenuImportRow {
+	t.Helper()
+	rows, err := menu.ExportMenu(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+	return rows
+}
+
+func menuBySKU(t *testing.T, repo interfaces.Repository) map[string]models.MenuItem {
+	t.Helper()
+	items, err := repo.GetAllMenuItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySKU := make(map[string]models.MenuItem, len(items))
+	for _, item := range items {
+		bySKU[item.SKU] = item
+	}
+	return bySKU
+}
+
+func importSummary(r models.MenuImportResult) []int {
+	return []int{r.Created, r.Updated, r.Archived, r.Unchanged}
+}
+
+func TestImportMenuDryRunThenApply(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	menu := NewMenuUsecase(repo, nil)
+	before := menuBySKU(t, repo)
+
+	// Lemonade goes up, iced tea comes off and a new milkshake goes on
+	rows := exportedRows(t, menu)
+	for i := range rows {
+		switch rows[i].SKU {
+		case "ITEM-11":
+			rows[i].Price = 449
+			rows[i].Allergens = []models.Allergen{" Sulphites "}
+		case "ITEM-12":
+			rows[i].Available = false
+		}
+	}
+	rows = append(rows, models.MenuImportRow{Row: len(rows) + 1, SKU: "SHK-1", Name: " Milkshake ", Category: "Drinks", Price: 499,
+		Available: true, Allergens: []models.Allergen{"milk"}})
+	wantChanges := []models.MenuImportChange{
+		{Row: 11, SKU: "ITEM-11", Action: models.MenuImportUpdate, Fields: []string{"price", "allergens"}},
+		{Row: 12, SKU: "ITEM-12", Action: models.MenuImportArchive, Fields: []string{"available"}},
+		{Row: 14, SKU: "SHK-1", Action: models.MenuImportCreate},
+	}
+	changes := func(r models.MenuImportResult) []models.MenuImportChange {
+		got := make([]models.MenuImportChange, len(r.Changes))
+		for i, c := range r.Changes {
+			got[i] = models.MenuImportChange{Row: c.Row, SKU: c.SKU, Action: c.Action, Fields: c.Fields}
+		}
+		return got
+	}
+
+	dryRun, err := menu.ImportMenu(ctx, models.MenuImport{Rows: rows, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dryRun.DryRun || dryRun.Applied || len(dryRun.Errors) != 0 {
+		t.Errorf("dry run %+v", dryRun)
+	}
+	if got := importSummary(dryRun); !slices.Equal(got, []int{1, 1, 1, 11}) {
+		t.Errorf("dry run created, updated, archived and left %v, want [1 1 1 11]", got)
+	}
+	if got := changes(dryRun); !reflect.DeepEqual(got, wantChanges) {
+		t.Errorf("dry run changes %+v, want %+v", got, wantChanges)
+	}
+	if after := menuBySKU(t, repo); !reflect.DeepEqual(after, before) {
+		t.Error("the dry run changed the menu")
+	}
+
+	applied, err := menu.ImportMenu(ctx, models.MenuImport{Rows: rows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.DryRun || !applied.Applied || len(applied.Errors) != 0 {
+		t.Errorf("import %+v", applied)
+	}
+	if got := changes(applied); !reflect.DeepEqual(got, wantChanges) {
+		t.Errorf("import changes %+v, want the dry run's %+v", got, wantChanges)
+	}
+	after := menuBySKU(t, repo)
+	if lemonade := after["ITEM-11"]; lemonade.Price != 449 || !slices.Equal(lemonade.Allergens, []models.Allergen{models.AllergenSulphites}) ||
+		lemonade.Images != before["ITEM-11"].Images {
+		t.Errorf("lemonade %+v", lemonade)
+	}
+	if tea := after["ITEM-12"]; tea.Available || tea.ID != before["ITEM-12"].ID {
+		t.Errorf("iced tea %+v", tea)
+	}
+	if shake, ok := after["SHK-1"]; !ok || shake.Name != "Milkshake" || shake.Price != 499 || !shake.Available {
+		t.Errorf("milkshake %+v", shake)
+	}
+	if len(after) != len(before)+1 {
+		t.Errorf("%d items after the import, want %d", len(after), len(before)+1)
+	}
+
+	// Importing the same file again changes nothing
+	again, err := menu.ImportMenu(ctx, models.MenuImport{Rows: rows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importSummary(again); !again.Applied || len(again.Changes) != 0 || !slices.Equal(got, []int{0, 0, 0, 14}) {
+		t.Errorf("the second import %+v", again)
+	}
+}
+
+func TestImportMenuErrorsApplyNothing(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	menu := NewMenuUsecase(repo, nil)
+	before := menuBySKU(t, repo)
+
+	in := models.MenuImport{
+		Rows: []models.MenuImportRow{
+			{Row: 2, SKU: "ITEM-1", Name: "Margherita", Category: "Pizza", Price: 999, Available: true},
+			{Row: 3, SKU: "NEW-1", Name: "Soup", Category: "Soups", Price: 500, Available: true},
+			{Row: 5, SKU: "NEW-1", Name: "Bread", Category: "Starters", Price: 300, Available: true},
+			{Row: 6, SKU: "NEW 2", Category: "Soups", Price: 0, Available: true},
+		},
+		Errors: []models.MenuImportError{{Row: 4, Message: "want 10 columns, got 5"}},
+	}
+	result, err := menu.ImportMenu(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Applied {
+		t.Error("an import with errors was applied")
+	}
+	wantErrs := []models.MenuImportError{
+		{Row: 4, Message: "want 10 columns, got 5"},
+		{Row: 5, SKU: "NEW-1", Field: "sku", Message: "SKU already appears on row 3"},
+		{Row: 6, SKU: "NEW 2", Field: "sku", Message: "SKU must not contain spaces"},
+		{Row: 6, SKU: "NEW 2", Field: "name", Message: "name is required"},
+		{Row: 6, SKU: "NEW 2", Field: "price", Message: "price must be positive"},
+	}
+	if !reflect.DeepEqual(result.Errors, wantErrs) {
+		t.Errorf("errors %+v, want %+v", result.Errors, wantErrs)
+	}
+	// The valid rows are still compared, so the file can be fixed in one go
+	if got := importSummary(result); !slices.Equal(got, []int{1, 1, 0, 0}) {
+		t.Errorf("created, updated, archived and left %v, want [1 1 0 0]", got)
+	}
+	if after := menuBySKU(t, repo); !reflect.DeepEqual(after, before) {
+		t.Error("an import with errors changed the menu")
+	}
+}
+
+func TestImportMenuArchiveMissing(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	menu := NewMenuUsecase(repo, nil)
+	rows := exportedRows(t, menu)[:2]
+
+	for _, archive := range []bool{false, true} {
+		result, err := menu.ImportMenu(ctx, models.MenuImport{Rows: rows, DryRun: true, ArchiveMissing: archive})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []int{0, 0, 0, 2}
+		if archive {
+			want = []int{0, 0, 11, 2}
+		}
+		if got := importSummary(result); !slices.Equal(got, want) {
+			t.Errorf("archiving missing %v: created, updated, archived and left %v, want %v", archive, got, want)
+		}
+	}
+
+	if _, err := menu.ImportMenu(ctx, models.MenuImport{Rows: rows, ArchiveMissing: true}); err != nil {
+		t.Fatal(err)
+	}
+	for sku, item := range menuBySKU(t, repo) {
+		if kept := sku == "ITEM-1" || sku == "ITEM-2"; item.Available != kept {
+			t.Errorf("%s available %v, want %v", sku, item.Available, kept)
+		}
+	}
+}
@@ -2,13 +2,26 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/abdullahnettoor/tastybites/internal/models"
 	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	"github.com/abdullahnettoor/tastybites/internal/storage"
 )
 
 type MenuIUsecase interface {
 	GetAllMenuItems(ctx context.Context) ([]models.MenuItem, error)
+	GetMenuItems(ctx context.Context, filter models.MenuFilter) ([]models.MenuItem, error)
+	SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) (models.MenuSearchResult, error)
+	UploadMenuItemImage(ctx context.Context, itemID int, data []byte) (models.MenuImages, error)
+	OpenImage(ctx context.Context, key string) (io.ReadCloser, storage.BlobInfo, error)
+	ScheduleMenuItemPrice(ctx context.Context, price models.MenuItemPrice) (int, error)
+	GetPriceTimeline(ctx context.Context, menuItemID int) (models.PriceTimeline, error)
+	CancelScheduledPrice(ctx context.Context, menuItemID, priceID int) error
+	ExportMenu(ctx context.Context) ([]models.MenuImportRow, error)
+	ImportMenu(ctx context.Context, in models.MenuImport) (models.MenuImportResult, error)
 	// CreateMenuItem(ctx context.Context, item models.MenuItem) (int, error)
 	// GetMenuItemById(ctx context.Context, id int) (models.MenuItem, error)
 	// GetMenuItemsByCategory(ctx context.Context, category string) ([]models.MenuItem, error)
@@ -18,12 +31,14 @@ type MenuIUsecase interface {
 }
 
 type MenuUsecase struct {
-	repo interfaces.Repository
+	repo  interfaces.Repository
+	blobs storage.BlobStore
 }
 
-func NewMenuUsecase(repo interfaces.Repository) MenuIUsecase {
+func NewMenuUsecase(repo interfaces.Repository, blobs storage.BlobStore) MenuIUsecase {
 	return &MenuUsecase{
-		repo: repo,
+		repo:  repo,
+		blobs: blobs,
 	}
 }
 
@@ -38,6 +53,43 @@ func (m *MenuUsecase) GetAllMenuItems(ctx context.Context) ([]models.MenuItem, e
 	return menuItems, nil
 }
 
+// GetMenuItems returns the menu narrowed down by the filter, leaving out
+// archived items. Unlike GetAllMenuItems, an empty result is not an error.
+func (m *MenuUsecase) GetMenuItems(ctx context.Context, filter models.MenuFilter) ([]models.MenuItem, error) {
+	menuItems, err := m.repo.GetAllMenuItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.MenuItem, 0, len(menuItems))
+	for _, item := range menuItems {
+		if item.Available && filter.Matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+func (m *MenuUsecase) SearchMenuItems(ctx context.Context, query models.MenuSearchQuery) (models.MenuSearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return models.MenuSearchResult{}, fmt.Errorf("%w: search query is required", models.ErrInvalidInput)
+	}
+	query.Normalize()
+
+	hits, total, err := m.repo.SearchMenuItems(ctx, query)
+	if err != nil {
+		return models.MenuSearchResult{}, err
+	}
+	return models.MenuSearchResult{
+		Query: query.Text,
+		Hits:  hits,
+		Total: total,
+		Page:  query.Page,
+		Limit: query.Limit,
+	}, nil
+}
+
 func (m *MenuUsecase) GetAvailableTables(ctx context.Context) ([]models.Table, error) {
 	tables, err := m.repo.GetTablesByStatus(ctx, models.TableStatusAvailable)
 	if err != nil {
@@ -2,8 +2,14 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/abdullahnettoor/tastybites/internal/events"
 	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
 	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
 )
 
@@ -12,23 +18,181 @@ type OrderIUsecase interface {
 	GetOrderById(ctx context.Context, id int) (models.Order, error)
 	GetAllOrders(ctx context.Context) ([]models.Order, error)
 	GetOrdersByUser(ctx context.Context, userId int) ([]models.Order, error)
+	// ListOrders returns the page of orders after cursor, which is empty for
+	// the first page.
+	ListOrders(ctx context.Context, query models.OrderListQuery, cursor string) (models.OrderPage, error)
 	UpdateOrder(ctx context.Context, order models.Order) error
 	DeleteOrder(ctx context.Context, id int) error
+	ApplyCoupon(ctx context.Context, orderId, userId int, code string) (models.Order, error)
+	RemoveCoupon(ctx context.Context, orderId, userId int) (models.Order, error)
 }
 
 type OrderUsecase struct {
-	repo interfaces.Repository
+	repo    interfaces.Repository
+	pricing pricing.Settings
+	kitchen KitchenIUsecase
+	events  *events.Bus
 }
 
-func NewOrderUsecase(repo interfaces.Repository) OrderIUsecase {
+func NewOrderUsecase(repo interfaces.Repository, settings pricing.Settings, kitchen KitchenIUsecase, bus *events.Bus) OrderIUsecase {
 	return &OrderUsecase{
-		repo: repo,
+		repo:    repo,
+		pricing: settings,
+		kitchen: kitchen,
+		events:  bus,
 	}
 }
 
 func (o *OrderUsecase) CreateOrder(ctx context.Context, order models.Order) (int, error) {
-	order.CalculateTotalPrice() // Calculate total price before saving
-	return o.repo.CreateOrder(ctx, order)
+	// Lines are always priced here, never by the client, with every price
+	// taken from the same moment
+	if len(order.Items) == 0 {
+		return 0, fmt.Errorf("%w: order has no items", models.ErrInvalidInput)
+	}
+	for i := range order.Items {
+		order.Items[i].Note = strings.TrimSpace(order.Items[i].Note)
+		if len(order.Items[i].Note) > 200 {
+			return 0, fmt.Errorf("%w: item notes are limited to 200 characters", models.ErrInvalidInput)
+		}
+	}
+	now := time.Now()
+	if err := priceOrderItems(ctx, o.repo, order.Items, now); err != nil {
+		return 0, err
+	}
+	if order.Guests == 0 {
+		order.Guests = 1
+	}
+	if err := o.calculateTotals(ctx, &order, now, now); err != nil {
+		return 0, err
+	}
+	orderId, err := o.repo.CreateOrder(ctx, order)
+	if err != nil {
+		return 0, err
+	}
+	o.announceOrder(ctx, orderId)
+
+	// A stored order is confirmed, so the kitchen gets its tickets now. A
+	// kitchen problem must not lose the order; it can be sent again.
+	if _, err := o.kitchen.SendToKitchen(ctx, orderId); err != nil {
+		log.Printf("failed to send order %d to the kitchen: %v", orderId, err)
+	}
+	return orderId, nil
+}
+
+// announceOrder publishes a new order and the menu items it sold out. The
+// order is already stored, so failures are only logged.
+func (o *OrderUsecase) announceOrder(ctx context.Context, orderId int) {
+	order, err := o.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		log.Printf("failed to load order %d for its event: %v", orderId, err)
+		return
+	}
+	o.events.Publish(events.OrderEvent(events.OrderCreated, order))
+
+	seen := make(map[int]bool)
+	for _, used := range order.KitchenItems() {
+		if seen[used.MenuItemID] {
+			continue
+		}
+		seen[used.MenuItemID] = true
+		item, err := o.repo.GetMenuItemById(ctx, used.MenuItemID)
+		if err != nil {
+			log.Printf("failed to check stock of menu item %d: %v", used.MenuItemID, err)
+			continue
+		}
+		if item.Stock != nil && *item.Stock == 0 {
+			o.events.Publish(events.MenuItemEvent(events.MenuItemSoldOut, item))
+		}
+	}
+}
+
+func (o *OrderUsecase) ApplyCoupon(ctx context.Context, orderId, userId int, code string) (models.Order, error) {
+	code = models.NormalizeCouponCode(code)
+	if code == "" {
+		return models.Order{}, fmt.Errorf("%w: coupon code is required", models.ErrInvalidInput)
+	}
+	return o.repriceOrder(ctx, orderId, userId, code)
+}
+
+func (o *OrderUsecase) RemoveCoupon(ctx context.Context, orderId, userId int) (models.Order, error) {
+	return o.repriceOrder(ctx, orderId, userId, "")
+}
+
+// repriceOrder re-evaluates the promotions of a pending order with the given
+// coupon. Line prices stay as they were when the order was placed.
+func (o *OrderUsecase) repriceOrder(ctx context.Context, orderId, userId int, code string) (models.Order, error) {
+	order, err := o.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if order.UserID != userId {
+		return models.Order{}, fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	if order.Status != models.OrderStatusPending {
+		return models.Order{}, fmt.Errorf("%w: only pending orders can be changed", models.ErrInvalidInput)
+	}
+	payments, err := o.repo.GetPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if order.Balance(payments) < order.TotalPrice {
+		return models.Order{}, fmt.Errorf("%w: the order already has payments", models.ErrInvalidInput)
+	}
+	checks, err := o.repo.GetOrderChecks(ctx, orderId)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if len(checks) > 0 {
+		return models.Order{}, fmt.Errorf("%w: merge the checks before changing the coupon", models.ErrOrderSplit)
+	}
+
+	// Stored times are UTC; daily windows go by the restaurant's clock
+	now := time.Now()
+	orderedAt, err := time.Parse(time.RFC3339Nano, order.CreatedAt)
+	if err != nil {
+		orderedAt = now
+	}
+	orderedAt = orderedAt.Local()
+	order.CouponCode = code
+	if err := o.calculateTotals(ctx, &order, orderedAt, now); err != nil {
+		return models.Order{}, err
+	}
+	if err := o.repo.UpdateOrderTotals(ctx, order); err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// calculateTotals applies promotions to a priced order and works out its tax,
+// service charge, tip and grand total.
+func (o *OrderUsecase) calculateTotals(ctx context.Context, order *models.Order, orderedAt, now time.Time) error {
+	menuItems, err := menuItemsByID(ctx, o.repo)
+	if err != nil {
+		return err
+	}
+
+	if err := applyPromotions(ctx, o.repo, order, menuItems, orderedAt, now); err != nil {
+		return err
+	}
+
+	rates, err := o.repo.GetTaxRates(ctx)
+	if err != nil {
+		return err
+	}
+	return pricing.Calculate(order, menuItems, rates, o.pricing)
+}
+
+// menuItemsByID loads the whole menu keyed by item id.
+func menuItemsByID(ctx context.Context, repo interfaces.Repository) (map[int]models.MenuItem, error) {
+	items, err := repo.GetAllMenuItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	menuItems := make(map[int]models.MenuItem, len(items))
+	for _, item := range items {
+		menuItems[item.ID] = item
+	}
+	return menuItems, nil
 }
 
 func (o *OrderUsecase) GetOrderById(ctx context.Context, id int) (models.Order, error) {
@@ -43,6 +207,56 @@ func (o *OrderUsecase) GetOrdersByUser(ctx context.Context, userId int) ([]model
 	return o.repo.GetOrdersByUser(ctx, userId)
 }
 
+func (o *OrderUsecase) ListOrders(ctx context.Context, query models.OrderListQuery, cursor string) (models.OrderPage, error) {
+	query.Normalize()
+	switch query.Status {
+	case "", models.OrderStatusPending, models.OrderStatusCompleted, models.OrderStatusCancelled,
+		models.OrderStatusPaid, models.OrderStatusRefunded:
+	default:
+		return models.OrderPage{}, fmt.Errorf("%w: unknown order status %q", models.ErrInvalidInput, query.Status)
+	}
+	if query.MinTotal < 0 {
+		return models.OrderPage{}, fmt.Errorf("%w: minTotal must not be negative", models.ErrInvalidInput)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return models.OrderPage{}, fmt.Errorf("%w: from must be before to", models.ErrInvalidInput)
+	}
+	if cursor != "" {
+		after, err := models.ParseOrderCursor(cursor, query.Sort())
+		if err != nil {
+			return models.OrderPage{}, err
+		}
+		query.After = &after
+	}
+	return listOrders(ctx, o.repo, query)
+}
+
+// listOrders reads one page of a normalized query, asking for one order more
+// to tell whether another page follows.
+func listOrders(ctx context.Context, repo interfaces.OrderRepository, query models.OrderListQuery) (models.OrderPage, error) {
+	limit := query.Limit
+	query.Limit++
+	orders, total, err := repo.ListOrders(ctx, query)
+	if err != nil {
+		return models.OrderPage{}, err
+	}
+
+	page := models.OrderPage{Orders: orders, Total: total, Limit: limit}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		placedAt, err := time.Parse(time.RFC3339Nano, last.CreatedAt)
+		if err != nil {
+			return models.OrderPage{}, fmt.Errorf("invalid creation time of order %d: %w", last.ID, err)
+		}
+		page.NextCursor = query.CursorAfter(last, placedAt).String()
+	}
+	if page.Orders == nil {
+		page.Orders = []models.Order{}
+	}
+	return page, nil
+}
+
 func (o *OrderUsecase) UpdateOrder(ctx context.Context, order models.Order) error {
 	order.CalculateTotalPrice() // Recalculate total price before updating
 	return o.repo.UpdateOrder(ctx, order)
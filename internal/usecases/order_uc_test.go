@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+func TestListOrdersFollowsCursor(t *testing.T) {
+	ctx := context.Background()
+	uc := NewOrderUsecase(memrepo.NewRepository(), pricing.Settings{}, nil, newTestBus(t))
+
+	for _, sort := range []struct {
+		by   string
+		desc bool
+		want []int
+	}{
+		{models.OrderSortCreatedAt, true, []int{4, 3, 2, 1}}, // placed together, so by id
+		{models.OrderSortTotal, false, []int{2, 3, 1, 4}},
+		{models.OrderSortTotal, true, []int{4, 1, 3, 2}},
+	} {
+		query := models.OrderListQuery{SortBy: sort.by, Desc: sort.desc, Limit: 3}
+		var got []int
+		cursor := ""
+		for {
+			page, err := uc.ListOrders(ctx, query, cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != 4 || page.Limit != 3 {
+				t.Errorf("%s: page of %d with limit %d", query.Sort(), page.Total, page.Limit)
+			}
+			for _, o := range page.Orders {
+				got = append(got, o.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if !slices.Equal(got, sort.want) {
+			t.Errorf("%s: listed %v, want %v", query.Sort(), got, sort.want)
+		}
+	}
+}
+
+func TestListOrdersRejectsCursor(t *testing.T) {
+	ctx := context.Background()
+	uc := NewOrderUsecase(memrepo.NewRepository(), pricing.Settings{}, nil, newTestBus(t))
+	newest := models.OrderListQuery{Limit: 1}
+	page, err := uc.ListOrders(ctx, newest, "")
+	if err != nil || page.NextCursor == "" {
+		t.Fatalf("first page %+v, %v", page, err)
+	}
+	valid := page.NextCursor
+	encode := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+	placed := time.Now().UTC().Format(time.RFC3339Nano)
+	byTotal := models.OrderListQuery{SortBy: models.OrderSortTotal, Limit: 1}
+
+	tests := []struct {
+		name   string
+		query  models.OrderListQuery
+		cursor string
+	}{
+		{"not base64", newest, "!!!"},
+		{"not JSON", newest, encode("order 3")},
+		{"truncated", newest, valid[:len(valid)-4]},
+		{"trailing data", newest, encode(`{"sort":"-createdAt","createdAt":"` + placed + `","id":3}x`)},
+		{"no id", newest, encode(`{"sort":"-createdAt","createdAt":"` + placed + `"}`)},
+		{"negative id", newest, encode(`{"sort":"-createdAt","createdAt":"` + placed + `","id":-3}`)},
+		{"no time", newest, encode(`{"sort":"-createdAt","id":3}`)},
+		{"bad time", newest, encode(`{"sort":"-createdAt","createdAt":"yesterday","id":3}`)},
+		{"total on a time cursor", newest, encode(`{"sort":"-createdAt","createdAt":"` + placed + `","total":100,"id":3}`)},
+		{"negative total", byTotal, encode(`{"sort":"total","total":-1,"id":3}`)},
+		{"time on a total cursor", byTotal, encode(`{"sort":"total","createdAt":"` + placed + `","total":100,"id":3}`)},
+		{"other sort", byTotal, valid},
+		{"other direction", models.OrderListQuery{SortBy: models.OrderSortCreatedAt, Limit: 1}, valid},
+	}
+	for _, tt := range tests {
+		if page, err := uc.ListOrders(ctx, tt.query, tt.cursor); !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("%s: ListOrders() = %+v, %v; want ErrInvalidInput", tt.name, page, err)
+		}
+	}
+
+	// A well-formed cursor only moves where the listing resumes
+	page, err = uc.ListOrders(ctx, byTotal, encode(`{"sort":"total","total":2000,"id":1}`))
+	if err != nil || len(page.Orders) != 1 || page.Orders[0].ID != 3 {
+		t.Errorf("resumed at total 20.00: %+v, %v; want order 3", page, err)
+	}
+}
@@ -0,0 +1,298 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type PaymentIUsecase interface {
+	CreatePayment(ctx context.Context, req models.PaymentRequest) (models.Payment, error)
+	// GetOrderPayments lists the payments of an order; a non-zero userId
+	// must own the order.
+	GetOrderPayments(ctx context.Context, orderId, userId int) ([]models.Payment, error)
+	CapturePayment(ctx context.Context, paymentId, amount int) (models.Payment, error)
+	VoidPayment(ctx context.Context, paymentId int) (models.Payment, error)
+	HandleWebhook(ctx context.Context, provider string, payload []byte, header http.Header) error
+}
+
+type PaymentUsecase struct {
+	repo     interfaces.Repository
+	gateway  payments.PaymentGateway
+	currency string
+	events   *events.Bus
+}
+
+func NewPaymentUsecase(repo interfaces.Repository, gateway payments.PaymentGateway, currency string, bus *events.Bus) PaymentIUsecase {
+	return &PaymentUsecase{
+		repo:     repo,
+		gateway:  gateway,
+		currency: currency,
+		events:   bus,
+	}
+}
+
+func (p *PaymentUsecase) CreatePayment(ctx context.Context, req models.PaymentRequest) (models.Payment, error) {
+	order, err := p.repo.GetOrderById(ctx, req.OrderID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if order.UserID != req.UserID {
+		return models.Payment{}, fmt.Errorf("order not found with id %d: %w", req.OrderID, models.ErrNotFound)
+	}
+
+	// A retried request returns the payment the first one created
+	if req.IdempotencyKey != "" {
+		payment, err := p.repo.GetPaymentByIdempotencyKey(ctx, order.ID, req.IdempotencyKey)
+		if err == nil {
+			return payment, nil
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			return models.Payment{}, err
+		}
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return models.Payment{}, fmt.Errorf("%w: order is %s", models.ErrPaymentNotAllowed, order.Status)
+	}
+	existing, err := p.repo.GetPaymentsByOrder(ctx, order.ID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	balance, err := p.balance(ctx, order, req.CheckID, existing)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if balance == 0 {
+		return models.Payment{}, fmt.Errorf("%w: nothing left to pay", models.ErrPaymentNotAllowed)
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = balance
+	}
+	if amount < 0 || amount > balance {
+		return models.Payment{}, fmt.Errorf("%w: amount must be between 1 and %d cents", models.ErrInvalidInput, balance)
+	}
+
+	// Record the attempt before calling the gateway so it is never lost
+	payment := models.Payment{
+		OrderID:        order.ID,
+		CheckID:        nullableID(req.CheckID),
+		Provider:       p.gateway.Name(),
+		Status:         models.PaymentStatusPending,
+		Amount:         amount,
+		Currency:       p.currency,
+		IdempotencyKey: req.IdempotencyKey,
+		CreatedBy:      req.UserID,
+	}
+	if payment.ID, err = p.repo.CreatePayment(ctx, payment); err != nil {
+		return models.Payment{}, err
+	}
+
+	result, err := p.gateway.Authorize(ctx, payments.AuthorizeRequest{
+		OrderID:        order.ID,
+		Amount:         amount,
+		Currency:       p.currency,
+		Source:         req.Source,
+		Capture:        !req.AuthorizeOnly,
+		IdempotencyKey: fmt.Sprintf("payment-%d", payment.ID),
+	})
+	if err != nil {
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		if saveErr := p.repo.UpdatePayment(ctx, payment, models.PaymentStatusPending); saveErr != nil {
+			return models.Payment{}, saveErr
+		}
+		return payment, err
+	}
+
+	payment.Reference = result.Reference
+	payment.FailureReason = result.FailureReason
+	if result.Status != models.PaymentStatusPending {
+		payment.Status = result.Status
+	}
+	if payment.Status == models.PaymentStatusCaptured {
+		payment.Captured = amount
+	}
+	if err := p.repo.UpdatePayment(ctx, payment, models.PaymentStatusPending); err != nil {
+		p.release(ctx, payment)
+		return models.Payment{}, err
+	}
+	return payment, syncOrderStatus(ctx, p.repo, p.events, order.ID)
+}
+
+// release gives back money the gateway took for a payment that could not be
+// recorded, so the customer is not charged for it. It is best effort: the
+// payment stays pending and the gateway's webhook settles it otherwise.
+func (p *PaymentUsecase) release(ctx context.Context, payment models.Payment) {
+	var err error
+	switch payment.Status {
+	case models.PaymentStatusAuthorized:
+		_, err = p.gateway.Void(ctx, payment.Reference)
+	case models.PaymentStatusCaptured:
+		_, err = p.gateway.Refund(ctx, payment.Reference, payment.Captured)
+	}
+	if err != nil {
+		log.Printf("failed to release payment %d at %s: %v", payment.ID, payment.Provider, err)
+	}
+}
+
+// balance is what is left to pay on the order, or on one of its checks.
+func (p *PaymentUsecase) balance(ctx context.Context, order models.Order, checkId int, payments []models.Payment) (int, error) {
+	checks, err := p.repo.GetOrderChecks(ctx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	return order.BalanceFor(checkId, checks, payments)
+}
+
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func (p *PaymentUsecase) GetOrderPayments(ctx context.Context, orderId, userId int) ([]models.Payment, error) {
+	if userId != 0 {
+		order, err := p.repo.GetOrderById(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+		if order.UserID != userId {
+			return nil, fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+		}
+	}
+	return p.repo.GetPaymentsByOrder(ctx, orderId)
+}
+
+func (p *PaymentUsecase) CapturePayment(ctx context.Context, paymentId, amount int) (models.Payment, error) {
+	payment, err := p.repo.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if payment.Status != models.PaymentStatusAuthorized {
+		return models.Payment{}, fmt.Errorf("%w: payment is %s", models.ErrPaymentConflict, payment.Status)
+	}
+	if amount == 0 {
+		amount = payment.Amount
+	}
+	if amount < 0 || amount > payment.Amount {
+		return models.Payment{}, fmt.Errorf("%w: amount must be between 1 and %d cents", models.ErrInvalidInput, payment.Amount)
+	}
+
+	result, err := p.gateway.Capture(ctx, payment.Reference, amount)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if result.Status != models.PaymentStatusCaptured {
+		return models.Payment{}, fmt.Errorf("%w: %s", models.ErrPaymentConflict, result.FailureReason)
+	}
+	from := payment.Status
+	payment.Status = models.PaymentStatusCaptured
+	payment.Captured = result.Amount
+	return p.save(ctx, payment, from)
+}
+
+func (p *PaymentUsecase) VoidPayment(ctx context.Context, paymentId int) (models.Payment, error) {
+	payment, err := p.repo.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if !payment.Status.CanTransitionTo(models.PaymentStatusVoided) || payment.Reference == "" {
+		return models.Payment{}, fmt.Errorf("%w: payment is %s", models.ErrPaymentConflict, payment.Status)
+	}
+
+	result, err := p.gateway.Void(ctx, payment.Reference)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if result.Status != models.PaymentStatusVoided {
+		return models.Payment{}, fmt.Errorf("%w: %s", models.ErrPaymentConflict, result.FailureReason)
+	}
+	from := payment.Status
+	payment.Status = models.PaymentStatusVoided
+	return p.save(ctx, payment, from)
+}
+
+// HandleWebhook applies a gateway notification. Deliveries are recorded first,
+// so redeliveries of a processed event are acknowledged without effect.
+// Amounts in events are totals as known by the gateway.
+func (p *PaymentUsecase) HandleWebhook(ctx context.Context, provider string, payload []byte, header http.Header) error {
+	if provider != p.gateway.Name() {
+		return fmt.Errorf("payment provider %s: %w", provider, models.ErrNotFound)
+	}
+	event, err := p.gateway.ParseWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	processed, err := p.repo.RecordWebhookEvent(ctx, event)
+	if err != nil || processed {
+		return err
+	}
+
+	payment, err := p.repo.GetPaymentByReference(ctx, provider, event.Reference)
+	if err != nil {
+		return err
+	}
+
+	// Stale or out of order events leave the payment alone
+	if payment.Status != event.Status && payment.Status.CanTransitionTo(event.Status) {
+		from := payment.Status
+		payment.Status = event.Status
+		switch event.Status {
+		case models.PaymentStatusCaptured:
+			payment.Captured = payment.Amount
+			if event.Amount > 0 && event.Amount <= payment.Amount {
+				payment.Captured = event.Amount
+			}
+		case models.PaymentStatusRefunded:
+			payment.Refunded = payment.Captured
+		case models.PaymentStatusPartiallyRefunded:
+			payment.Refunded = min(max(payment.Refunded, event.Amount), payment.Captured)
+		case models.PaymentStatusFailed:
+			payment.FailureReason = event.FailureReason
+		}
+		if _, err := p.save(ctx, payment, from); err != nil {
+			return err
+		}
+	}
+
+	return p.repo.MarkWebhookEventProcessed(ctx, provider, event.EventID)
+}
+
+// save stores a payment change and moves the order along with it.
+func (p *PaymentUsecase) save(ctx context.Context, payment models.Payment, from models.PaymentStatus) (models.Payment, error) {
+	if err := p.repo.UpdatePayment(ctx, payment, from); err != nil {
+		return models.Payment{}, err
+	}
+	return payment, syncOrderStatus(ctx, p.repo, p.events, payment.OrderID)
+}
+
+// syncOrderStatus sets the order status its payments and refunds imply.
+func syncOrderStatus(ctx context.Context, repo interfaces.Repository, bus *events.Bus, orderId int) error {
+	order, err := repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	paymentList, err := repo.GetPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if status := order.StatusAfterPayments(paymentList); status != order.Status {
+		if err := repo.UpdateOrderStatus(ctx, orderId, status); err != nil {
+			return err
+		}
+		order.Status = status
+		bus.Publish(events.OrderEvent(events.OrderStatusChanged, order))
+	}
+	return nil
+}
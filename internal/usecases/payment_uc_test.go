@@ -0,0 +1,412 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+// In the sample data order 2 is pending for user 3 and costs 14.99.
+const (
+	testOrderID    = 2
+	testOrderUser  = 3
+	testOrderTotal = 1499
+	testWebhookKey = "whsec_test"
+)
+
+func newTestBus(t *testing.T) *events.Bus {
+	t.Helper()
+	bus, err := events.NewBus(&config.EventsConfig{ReplaySize: 64, Heartbeat: 15 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bus.Close)
+	return bus
+}
+
+func newTestGateway() *payments.FakeGateway {
+	g := payments.NewFakeGateway(payments.FakeApprove, testWebhookKey, "")
+	g.WebhookDelay = time.Hour // async payments wait for the test's webhooks
+	return g
+}
+
+func newPaymentTest(t *testing.T) (PaymentIUsecase, interfaces.Repository) {
+	t.Helper()
+	repo := memrepo.NewRepository()
+	return NewPaymentUsecase(repo, newTestGateway(), "USD", newTestBus(t)), repo
+}
+
+func orderStatus(t *testing.T, repo interfaces.Repository, orderId int) models.OrderStatus {
+	t.Helper()
+	order, err := repo.GetOrderById(context.Background(), orderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order.Status
+}
+
+func TestCreatePayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           models.PaymentRequest
+		wantStatus    models.PaymentStatus
+		wantCaptured  int
+		wantOrder     models.OrderStatus
+		wantErr       error
+		wantNoPayment bool
+	}{
+		{
+			name:       "capture the balance",
+			req:        models.PaymentRequest{Source: "tok_visa"},
+			wantStatus: models.PaymentStatusCaptured, wantCaptured: testOrderTotal,
+			wantOrder: models.OrderStatusPaid,
+		},
+		{
+			name:       "capture part of it",
+			req:        models.PaymentRequest{Amount: 1000, Source: "tok_visa"},
+			wantStatus: models.PaymentStatusCaptured, wantCaptured: 1000,
+			wantOrder: models.OrderStatusPending,
+		},
+		{
+			name:       "hold only",
+			req:        models.PaymentRequest{Source: "tok_visa", AuthorizeOnly: true},
+			wantStatus: models.PaymentStatusAuthorized,
+			wantOrder:  models.OrderStatusPending,
+		},
+		{
+			name:       "declined",
+			req:        models.PaymentRequest{Source: "fake_declined"},
+			wantStatus: models.PaymentStatusFailed,
+			wantOrder:  models.OrderStatusPending,
+		},
+		{
+			name:       "waiting for the gateway",
+			req:        models.PaymentRequest{Source: "fake_async"},
+			wantStatus: models.PaymentStatusPending,
+			wantOrder:  models.OrderStatusPending,
+		},
+		{
+			name:       "gateway down",
+			req:        models.PaymentRequest{Source: "fake_error"},
+			wantStatus: models.PaymentStatusFailed,
+			wantOrder:  models.OrderStatusPending,
+			wantErr:    models.ErrGatewayFailure,
+		},
+		{
+			name:    "more than the balance",
+			req:     models.PaymentRequest{Amount: testOrderTotal + 1},
+			wantErr: models.ErrInvalidInput, wantNoPayment: true,
+			wantOrder: models.OrderStatusPending,
+		},
+		{
+			name:    "someone else's order",
+			req:     models.PaymentRequest{UserID: 2},
+			wantErr: models.ErrNotFound, wantNoPayment: true,
+			wantOrder: models.OrderStatusPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			uc, repo := newPaymentTest(t)
+			req := tt.req
+			req.OrderID = testOrderID
+			if req.UserID == 0 {
+				req.UserID = testOrderUser
+			}
+
+			payment, err := uc.CreatePayment(ctx, req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreatePayment() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("CreatePayment() failed: %v", err)
+			}
+
+			stored, err := repo.GetPaymentsByOrder(ctx, testOrderID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantNoPayment {
+				if len(stored) != 0 {
+					t.Errorf("stored %d payments, want none", len(stored))
+				}
+			} else {
+				if payment.Status != tt.wantStatus || payment.Captured != tt.wantCaptured {
+					t.Errorf("payment is %s with %d captured, want %s with %d",
+						payment.Status, payment.Captured, tt.wantStatus, tt.wantCaptured)
+				}
+				// The stored payment is the one returned
+				if len(stored) != 1 || stored[0].Status != payment.Status || stored[0].Reference != payment.Reference {
+					t.Errorf("stored payments = %+v, want %+v", stored, payment)
+				}
+			}
+			if got := orderStatus(t, repo, testOrderID); got != tt.wantOrder {
+				t.Errorf("order is %s, want %s", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentIdempotent(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newPaymentTest(t)
+	req := models.PaymentRequest{OrderID: testOrderID, UserID: testOrderUser, Amount: 500, IdempotencyKey: "retry-me"}
+
+	first, err := uc.CreatePayment(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := uc.CreatePayment(ctx, req)
+	if err != nil {
+		t.Fatalf("retried CreatePayment() failed: %v", err)
+	}
+	if again.ID != first.ID || again.Reference != first.Reference {
+		t.Errorf("retry created payment %d, want %d", again.ID, first.ID)
+	}
+	stored, _ := repo.GetPaymentsByOrder(ctx, testOrderID)
+	if len(stored) != 1 {
+		t.Errorf("stored %d payments, want 1", len(stored))
+	}
+}
+
+func TestCreatePaymentConcurrently(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newPaymentTest(t)
+
+	// Each payment fits the balance on its own, but only one fits with another
+	const n = 8
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, n)
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.CreatePayment(ctx, models.PaymentRequest{OrderID: testOrderID, UserID: testOrderUser, Amount: 1000, Source: "tok_visa"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, models.ErrPaymentConflict) && !errors.Is(err, models.ErrInvalidInput):
+			t.Errorf("CreatePayment() error = %v, want a conflict", err)
+		}
+	}
+	stored, _ := repo.GetPaymentsByOrder(ctx, testOrderID)
+	captured := 0
+	for _, p := range stored {
+		captured += p.Captured
+	}
+	if succeeded != 1 || len(stored) != 1 || captured != 1000 {
+		t.Errorf("%d payments succeeded, %d stored capturing %d, want one of 1000", succeeded, len(stored), captured)
+	}
+}
+
+// unsavedPayments fails to store what the gateway answered.
+type unsavedPayments struct {
+	interfaces.Repository
+	saved models.Payment
+}
+
+func (r *unsavedPayments) UpdatePayment(ctx context.Context, p models.Payment, from models.PaymentStatus) error {
+	r.saved = p
+	return errors.New("database is gone")
+}
+
+func TestCreatePaymentReleasesUnsaved(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.PaymentRequest
+		want models.PaymentStatus
+	}{
+		{"held", models.PaymentRequest{Source: "tok_visa", AuthorizeOnly: true}, models.PaymentStatusVoided},
+		{"captured", models.PaymentRequest{Source: "tok_visa"}, models.PaymentStatusRefunded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &unsavedPayments{Repository: memrepo.NewRepository()}
+			gateway := newTestGateway()
+			uc := NewPaymentUsecase(repo, gateway, "USD", newTestBus(t))
+
+			tt.req.OrderID, tt.req.UserID = testOrderID, testOrderUser
+			if _, err := uc.CreatePayment(ctx, tt.req); err == nil {
+				t.Fatal("CreatePayment() succeeded without saving the payment")
+			}
+			// Voiding again reports where the payment ended up
+			result, err := gateway.Void(ctx, repo.saved.Reference)
+			if err != nil || result.Status != tt.want {
+				t.Errorf("gateway has the payment %s, %v; want %s", result.Status, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureAndVoidPayment(t *testing.T) {
+	ctx := context.Background()
+	hold := models.PaymentRequest{OrderID: testOrderID, UserID: testOrderUser, AuthorizeOnly: true}
+
+	t.Run("capture a hold", func(t *testing.T) {
+		uc, repo := newPaymentTest(t)
+		payment, err := uc.CreatePayment(ctx, hold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := uc.CapturePayment(ctx, payment.ID, testOrderTotal+1); !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("capturing more than held: %v, want ErrInvalidInput", err)
+		}
+		captured, err := uc.CapturePayment(ctx, payment.ID, 0)
+		if err != nil {
+			t.Fatalf("CapturePayment() failed: %v", err)
+		}
+		if captured.Status != models.PaymentStatusCaptured || captured.Captured != testOrderTotal {
+			t.Errorf("captured payment = %+v", captured)
+		}
+		if got := orderStatus(t, repo, testOrderID); got != models.OrderStatusPaid {
+			t.Errorf("order is %s, want paid", got)
+		}
+		if _, err := uc.CapturePayment(ctx, payment.ID, 0); !errors.Is(err, models.ErrPaymentConflict) {
+			t.Errorf("capturing twice: %v, want ErrPaymentConflict", err)
+		}
+		if _, err := uc.VoidPayment(ctx, payment.ID); !errors.Is(err, models.ErrPaymentConflict) {
+			t.Errorf("voiding a captured payment: %v, want ErrPaymentConflict", err)
+		}
+	})
+
+	t.Run("void a hold", func(t *testing.T) {
+		uc, repo := newPaymentTest(t)
+		payment, err := uc.CreatePayment(ctx, hold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		voided, err := uc.VoidPayment(ctx, payment.ID)
+		if err != nil {
+			t.Fatalf("VoidPayment() failed: %v", err)
+		}
+		if voided.Status != models.PaymentStatusVoided {
+			t.Errorf("voided payment is %s", voided.Status)
+		}
+		if _, err := uc.CapturePayment(ctx, payment.ID, 0); !errors.Is(err, models.ErrPaymentConflict) {
+			t.Errorf("capturing a voided payment: %v, want ErrPaymentConflict", err)
+		}
+		// The held amount is free to pay again
+		if _, err := uc.CreatePayment(ctx, models.PaymentRequest{OrderID: testOrderID, UserID: testOrderUser}); err != nil {
+			t.Fatalf("paying after the void failed: %v", err)
+		}
+		if got := orderStatus(t, repo, testOrderID); got != models.OrderStatusPaid {
+			t.Errorf("order is %s, want paid", got)
+		}
+	})
+}
+
+// webhook signs a fake gateway event for a payment.
+func webhook(eventId string, reference string, status models.PaymentStatus, amount int) ([]byte, http.Header) {
+	body := []byte(fmt.Sprintf(`{"id":%q,"type":"payment.%s","data":{"reference":%q,"status":%q,"amount":%d}}`,
+		eventId, status, reference, status, amount))
+	header := http.Header{}
+	header.Set(payments.SignatureHeader, payments.Sign(testWebhookKey, body, time.Now()))
+	return body, header
+}
+
+func TestHandleWebhook(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newPaymentTest(t)
+	payment, err := uc.CreatePayment(ctx, models.PaymentRequest{
+		OrderID: testOrderID, UserID: testOrderUser, Source: "fake_async",
+	})
+	if err != nil || payment.Status != models.PaymentStatusPending {
+		t.Fatalf("CreatePayment() = %+v, %v, want pending", payment, err)
+	}
+	current := func() models.Payment {
+		p, err := repo.GetPaymentById(ctx, payment.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	body, header := webhook("evt_1", payment.Reference, models.PaymentStatusCaptured, testOrderTotal)
+	if err := uc.HandleWebhook(ctx, "fake", body, header); err != nil {
+		t.Fatalf("HandleWebhook() failed: %v", err)
+	}
+	if p := current(); p.Status != models.PaymentStatusCaptured || p.Captured != testOrderTotal {
+		t.Fatalf("payment after the webhook = %+v", p)
+	}
+	if got := orderStatus(t, repo, testOrderID); got != models.OrderStatusPaid {
+		t.Errorf("order is %s, want paid", got)
+	}
+
+	body, header = webhook("evt_2", payment.Reference, models.PaymentStatusPartiallyRefunded, 400)
+	if err := uc.HandleWebhook(ctx, "fake", body, header); err != nil {
+		t.Fatalf("HandleWebhook() failed: %v", err)
+	}
+	if p := current(); p.Status != models.PaymentStatusPartiallyRefunded || p.Refunded != 400 {
+		t.Fatalf("payment after the refund webhook = %+v", p)
+	}
+
+	// Redelivering either event is acknowledged and changes nothing
+	for _, id := range []string{"evt_1", "evt_2"} {
+		status := models.PaymentStatusCaptured
+		if id == "evt_2" {
+			status = models.PaymentStatusPartiallyRefunded
+		}
+		body, header := webhook(id, payment.Reference, status, testOrderTotal)
+		if err := uc.HandleWebhook(ctx, "fake", body, header); err != nil {
+			t.Fatalf("redelivered %s: %v", id, err)
+		}
+		processed, err := repo.RecordWebhookEvent(ctx, models.PaymentWebhookEvent{Provider: "fake", EventID: id})
+		if err != nil || !processed {
+			t.Errorf("%s is not recorded as processed: %v", id, err)
+		}
+	}
+	if p := current(); p.Status != models.PaymentStatusPartiallyRefunded || p.Refunded != 400 || p.Captured != testOrderTotal {
+		t.Errorf("payment after redeliveries = %+v", p)
+	}
+
+	// Out of order events leave the payment alone
+	body, header = webhook("evt_0", payment.Reference, models.PaymentStatusFailed, 0)
+	if err := uc.HandleWebhook(ctx, "fake", body, header); err != nil {
+		t.Fatalf("stale event: %v", err)
+	}
+	if p := current(); p.Status != models.PaymentStatusPartiallyRefunded {
+		t.Errorf("stale event moved the payment to %s", p.Status)
+	}
+}
+
+func TestHandleWebhookRejects(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newPaymentTest(t)
+	body, header := webhook("evt_1", "fake_pay_nope", models.PaymentStatusCaptured, 100)
+
+	if err := uc.HandleWebhook(ctx, "stripe", body, header); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("unknown provider: %v, want ErrNotFound", err)
+	}
+	forged := http.Header{}
+	forged.Set(payments.SignatureHeader, payments.Sign("guess", body, time.Now()))
+	if err := uc.HandleWebhook(ctx, "fake", body, forged); !errors.Is(err, models.ErrInvalidSignature) {
+		t.Errorf("forged signature: %v, want ErrInvalidSignature", err)
+	}
+	if err := uc.HandleWebhook(ctx, "fake", body, header); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("unknown payment: %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+// ScheduleMenuItemPrice records a price change. A zero EffectiveFrom means
+// the change applies immediately; otherwise it must not lie in the past.
+func (m *MenuUsecase) ScheduleMenuItemPrice(ctx context.Context, price models.MenuItemPrice) (int, error) {
+	if price.Price <= 0 {
+		return 0, fmt.Errorf("%w: price must be positive", models.ErrInvalidInput)
+	}
+	now := time.Now()
+	if price.EffectiveFrom.IsZero() {
+		price.EffectiveFrom = now
+	} else if price.EffectiveFrom.Before(now.Add(-time.Minute)) {
+		return 0, fmt.Errorf("%w: effective date must not be in the past", models.ErrInvalidInput)
+	}
+
+	if _, err := m.repo.GetMenuItemById(ctx, price.MenuItemID); err != nil {
+		return 0, err
+	}
+	return m.repo.CreateMenuItemPrice(ctx, price)
+}
+
+func (m *MenuUsecase) GetPriceTimeline(ctx context.Context, menuItemID int) (models.PriceTimeline, error) {
+	item, err := m.repo.GetMenuItemById(ctx, menuItemID)
+	if err != nil {
+		return models.PriceTimeline{}, err
+	}
+	prices, err := m.repo.GetMenuItemPrices(ctx, menuItemID)
+	if err != nil {
+		return models.PriceTimeline{}, err
+	}
+	return models.NewPriceTimeline(menuItemID, item.Price, prices, time.Now()), nil
+}
+
+func (m *MenuUsecase) CancelScheduledPrice(ctx context.Context, menuItemID, priceID int) error {
+	return m.repo.DeleteScheduledMenuItemPrice(ctx, menuItemID, priceID, time.Now())
+}
+
+// priceOrderItems sets the price of every line from the menu prices in
+// effect at the given moment. Prices sent by the client are ignored.
+func priceOrderItems(ctx context.Context, repo interfaces.Repository, items []models.OrderItem, at time.Time) error {
+	var ids []int
+	for _, item := range items {
+		if item.IsBundle() {
+			for _, choice := range item.Choices {
+				ids = append(ids, choice.MenuItemID)
+			}
+			continue
+		}
+		ids = append(ids, item.MenuItemID)
+	}
+
+	prices, err := repo.GetMenuItemPricesAt(ctx, ids, at)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.Quantity < 1 {
+			return fmt.Errorf("%w: quantity must be positive", models.ErrInvalidInput)
+		}
+		if item.IsBundle() {
+			if err := priceBundleLine(ctx, repo, item, prices); err != nil {
+				return err
+			}
+			continue
+		}
+		price, ok := prices[item.MenuItemID]
+		if !ok {
+			return fmt.Errorf("%w: menu item %d is not on the menu", models.ErrInvalidInput, item.MenuItemID)
+		}
+		item.Price = price
+	}
+	return nil
+}
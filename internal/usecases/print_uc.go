@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/printing"
+	"github.com/abdullahnettoor/tastybites/internal/receipts"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type PrintIUsecase interface {
+	// PrintKitchenTickets prints the order's kitchen tickets on the printer
+	// of each station.
+	PrintKitchenTickets(ctx context.Context, orderId int) ([]models.PrintJob, error)
+	PrintReceipt(ctx context.Context, orderId int) (models.PrintJob, error)
+	// Reprint prints the order's kitchen tickets, optionally for one station
+	// only, or its receipt again.
+	Reprint(ctx context.Context, orderId int, kind models.PrintJobKind, station string) ([]models.PrintJob, error)
+	// GetPrintJobs lists recent jobs, of one order when orderId is not zero.
+	GetPrintJobs(ctx context.Context, orderId int) ([]models.PrintJob, error)
+}
+
+type PrintUsecase struct {
+	repo     interfaces.Repository
+	spooler  *printing.Spooler
+	receipts ReceiptIUsecase
+}
+
+func NewPrintUsecase(repo interfaces.Repository, spooler *printing.Spooler, receipts ReceiptIUsecase) PrintIUsecase {
+	return &PrintUsecase{
+		repo:     repo,
+		spooler:  spooler,
+		receipts: receipts,
+	}
+}
+
+func (p *PrintUsecase) PrintKitchenTickets(ctx context.Context, orderId int) ([]models.PrintJob, error) {
+	return p.printTickets(ctx, orderId, false, "")
+}
+
+func (p *PrintUsecase) printTickets(ctx context.Context, orderId int, reprint bool, station string) ([]models.PrintJob, error) {
+	tickets, err := p.repo.GetKitchenTicketsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		// Hides orders that do not exist as well
+		if _, err := p.repo.GetOrderById(ctx, orderId); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: order %d was not sent to the kitchen", models.ErrInvalidInput, orderId)
+	}
+
+	now := time.Now()
+	jobs := make([]models.PrintJob, 0, len(tickets))
+	for _, ticket := range tickets {
+		if station != "" && ticket.Station != station {
+			continue
+		}
+		ticket.Reprint = reprint
+		job, err := p.spooler.Submit(orderId, models.PrintJobKitchen, ticket.Station, printing.TicketBytes(ticket, now))
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	if station != "" && len(jobs) == 0 {
+		return nil, fmt.Errorf("%w: order %d has nothing for station %q", models.ErrInvalidInput, orderId, station)
+	}
+	return jobs, nil
+}
+
+func (p *PrintUsecase) PrintReceipt(ctx context.Context, orderId int) (models.PrintJob, error) {
+	receipt, err := p.receipts.GetReceipt(ctx, orderId, 0)
+	if err != nil {
+		return models.PrintJob{}, err
+	}
+	return p.spooler.Submit(orderId, models.PrintJobReceipt, "", receipts.ESCPOSBytes(receipt))
+}
+
+func (p *PrintUsecase) Reprint(ctx context.Context, orderId int, kind models.PrintJobKind, station string) ([]models.PrintJob, error) {
+	switch kind {
+	case models.PrintJobKitchen:
+		return p.printTickets(ctx, orderId, true, station)
+	case models.PrintJobReceipt:
+		job, err := p.PrintReceipt(ctx, orderId)
+		if err != nil {
+			return nil, err
+		}
+		return []models.PrintJob{job}, nil
+	}
+	return nil, fmt.Errorf("%w: kind must be kitchen or receipt", models.ErrInvalidInput)
+}
+
+func (p *PrintUsecase) GetPrintJobs(ctx context.Context, orderId int) ([]models.PrintJob, error) {
+	return p.spooler.Jobs(orderId), nil
+}
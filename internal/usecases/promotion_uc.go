@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/promotions"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type PromotionIUsecase interface {
+	CreatePromotion(ctx context.Context, promotion models.Promotion) (int, error)
+	GetAllPromotions(ctx context.Context) ([]models.Promotion, error)
+	SetPromotionActive(ctx context.Context, id int, active bool) error
+}
+
+type PromotionUsecase struct {
+	repo interfaces.Repository
+}
+
+func NewPromotionUsecase(repo interfaces.Repository) PromotionIUsecase {
+	return &PromotionUsecase{
+		repo: repo,
+	}
+}
+
+func (p *PromotionUsecase) CreatePromotion(ctx context.Context, promotion models.Promotion) (int, error) {
+	promotion.Code = models.NormalizeCouponCode(promotion.Code)
+	if err := promotion.Validate(); err != nil {
+		return 0, err
+	}
+	if promotion.IsCoupon() {
+		if _, err := p.repo.GetPromotionByCode(ctx, promotion.Code); err == nil {
+			return 0, fmt.Errorf("%w: coupon code %s is already in use", models.ErrInvalidInput, promotion.Code)
+		}
+	}
+	return p.repo.CreatePromotion(ctx, promotion)
+}
+
+func (p *PromotionUsecase) GetAllPromotions(ctx context.Context) ([]models.Promotion, error) {
+	return p.repo.GetAllPromotions(ctx)
+}
+
+func (p *PromotionUsecase) SetPromotionActive(ctx context.Context, id int, active bool) error {
+	return p.repo.SetPromotionActive(ctx, id, active)
+}
+
+// applyPromotions works out the discounts of a priced order, with the coupon
+// in order.CouponCode if any.
+func applyPromotions(ctx context.Context, repo interfaces.Repository, order *models.Order, menuItems map[int]models.MenuItem, orderedAt, now time.Time) error {
+	automatic, err := repo.GetAutomaticPromotions(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(automatic)+1)
+	for _, p := range automatic {
+		ids = append(ids, p.ID)
+	}
+
+	var coupon *models.Promotion
+	order.CouponCode = models.NormalizeCouponCode(order.CouponCode)
+	if order.CouponCode != "" {
+		p, err := repo.GetPromotionByCode(ctx, order.CouponCode)
+		if err != nil {
+			return err
+		}
+		coupon = &p
+		ids = append(ids, p.ID)
+	}
+
+	usage, err := repo.GetPromotionUsage(ctx, ids, order.UserID, order.ID)
+	if err != nil {
+		return err
+	}
+
+	discounts, err := promotions.Apply(promotions.Input{
+		Order:     *order,
+		MenuItems: menuItems,
+		Automatic: automatic,
+		Coupon:    coupon,
+		Usage:     usage,
+		OrderedAt: orderedAt,
+		Now:       now,
+	})
+	if err != nil {
+		return err
+	}
+	order.Discounts = discounts
+	return nil
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/mailer"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/receipts"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type ReceiptIUsecase interface {
+	// GetReceipt collects what goes on the receipt of an order; a non-zero
+	// userId must own the order.
+	GetReceipt(ctx context.Context, orderId, userId int) (models.Receipt, error)
+	// IssueInvoice gives a paid order the next invoice number of the current
+	// fiscal year. Issuing again returns the existing invoice.
+	IssueInvoice(ctx context.Context, orderId, userId int, invoice models.Invoice) (models.Invoice, error)
+	// EmailReceipt mails the receipt with the PDF attached.
+	EmailReceipt(ctx context.Context, orderId, userId int, to string) error
+}
+
+type ReceiptUsecase struct {
+	repo             interfaces.Repository
+	restaurant       models.RestaurantDetails
+	fiscalStartMonth int
+	currency         string
+	mailer           mailer.Mailer
+}
+
+func NewReceiptUsecase(repo interfaces.Repository, restaurant models.RestaurantDetails, fiscalStartMonth int, currency string, mailer mailer.Mailer) ReceiptIUsecase {
+	return &ReceiptUsecase{
+		repo:             repo,
+		restaurant:       restaurant,
+		fiscalStartMonth: fiscalStartMonth,
+		currency:         currency,
+		mailer:           mailer,
+	}
+}
+
+func (r *ReceiptUsecase) GetReceipt(ctx context.Context, orderId, userId int) (models.Receipt, error) {
+	order, err := r.ownedOrder(ctx, orderId, userId)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+	lines, err := r.lines(ctx, order)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+	paymentList, err := r.repo.GetPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+	refunds, err := r.repo.GetRefundsByOrder(ctx, orderId)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+
+	receipt := models.Receipt{
+		Restaurant: r.restaurant,
+		Order:      order,
+		Lines:      lines,
+		Payments:   []models.Payment{},
+		Refunds:    []models.Refund{},
+		Balance:    order.Balance(paymentList),
+		Currency:   r.currency,
+		PrintedAt:  time.Now(),
+	}
+	for _, p := range paymentList {
+		if p.Captured > 0 {
+			receipt.Payments = append(receipt.Payments, p)
+			receipt.Paid += p.Captured
+		}
+	}
+	for _, f := range refunds {
+		if f.Status == models.RefundStatusCompleted {
+			receipt.Refunds = append(receipt.Refunds, f)
+		}
+	}
+
+	invoice, err := r.repo.GetInvoiceByOrder(ctx, orderId)
+	switch {
+	case err == nil:
+		receipt.Invoice = &invoice
+	case !errors.Is(err, models.ErrNotFound):
+		return models.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+// lines names the order's items, listing what was chosen for bundles.
+func (r *ReceiptUsecase) lines(ctx context.Context, order models.Order) ([]models.ReceiptLine, error) {
+	menuItems, err := menuItemsByID(ctx, r.repo)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := r.repo.GetAllBundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundleNames := make(map[int]string, len(bundles))
+	for _, b := range bundles {
+		bundleNames[b.ID] = b.Name
+	}
+	itemName := func(id int) string {
+		if item, ok := menuItems[id]; ok {
+			return item.Name
+		}
+		return fmt.Sprintf("Item #%d", id)
+	}
+
+	lines := make([]models.ReceiptLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		line := models.ReceiptLine{
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Total:     item.Price * item.Quantity,
+		}
+		if item.IsBundle() {
+			line.Name = bundleNames[*item.BundleID]
+			if line.Name == "" {
+				line.Name = fmt.Sprintf("Bundle #%d", *item.BundleID)
+			}
+			for _, c := range item.Components {
+				line.Components = append(line.Components, fmt.Sprintf("%d x %s", c.Quantity, itemName(c.MenuItemID)))
+			}
+		} else {
+			line.Name = itemName(item.MenuItemID)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (r *ReceiptUsecase) IssueInvoice(ctx context.Context, orderId, userId int, invoice models.Invoice) (models.Invoice, error) {
+	if err := invoice.Validate(); err != nil {
+		return models.Invoice{}, err
+	}
+	order, err := r.ownedOrder(ctx, orderId, userId)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if order.Status != models.OrderStatusPaid && order.Status != models.OrderStatusCompleted {
+		return models.Invoice{}, fmt.Errorf("%w: invoices are only issued once the order is paid", models.ErrOrderNotPaid)
+	}
+
+	invoice.OrderID = orderId
+	invoice.FiscalYear = models.FiscalYear(time.Now(), r.fiscalStartMonth)
+	return r.repo.CreateInvoice(ctx, invoice)
+}
+
+func (r *ReceiptUsecase) EmailReceipt(ctx context.Context, orderId, userId int, to string) error {
+	address, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("%w: invalid email address", models.ErrInvalidInput)
+	}
+	receipt, err := r.GetReceipt(ctx, orderId, userId)
+	if err != nil {
+		return err
+	}
+
+	var html, pdf bytes.Buffer
+	if err := receipts.HTML(&html, receipt); err != nil {
+		return err
+	}
+	if err := receipts.PDF(&pdf, receipt); err != nil {
+		return fmt.Errorf("failed to render receipt: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      []string{address.Address},
+		Subject: fmt.Sprintf("%s from %s", receipts.Title(receipt), r.restaurant.Name),
+		Text:    receipts.Text(receipt),
+		HTML:    html.String(),
+		Attachments: []mailer.Attachment{{
+			Filename:    receipts.Filename(receipt, receipts.FormatPDF),
+			ContentType: receipts.FormatPDF.ContentType(),
+			Data:        pdf.Bytes(),
+		}},
+	}
+	return r.mailer.Send(ctx, msg)
+}
+
+// ownedOrder loads an order, hiding it from anyone but its owner unless
+// userId is zero.
+func (r *ReceiptUsecase) ownedOrder(ctx context.Context, orderId, userId int) (models.Order, error) {
+	order, err := r.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if userId != 0 && order.UserID != userId {
+		return models.Order{}, fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	return order, nil
+}
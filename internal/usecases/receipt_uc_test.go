@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+func TestIssueInvoiceByFiscalYear(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := memrepo.NewRepository()
+	restaurant := models.RestaurantDetails{Name: "TastyBites"}
+	issue := func(startMonth, orderId int) models.Invoice {
+		t.Helper()
+		invoice, err := NewReceiptUsecase(repo, restaurant, startMonth, "USD", nil).
+			IssueInvoice(ctx, orderId, 0, models.Invoice{CustomerName: "John Doe"})
+		if err != nil {
+			t.Fatalf("IssueInvoice(%d) with the year starting in month %d: %v", orderId, startMonth, err)
+		}
+		return invoice
+	}
+
+	if now.Month() == time.December {
+		t.Skip("no month after this one starts a fiscal year in the previous calendar year")
+	}
+
+	// The sample orders 1 and 4 are completed
+	started := issue(int(now.Month())+1, 1)
+	if want := models.InvoiceNumber(now.Year()-1, 1); started.FiscalYear != now.Year()-1 || started.Number != want {
+		t.Errorf("a year starting next month gave %s in %d, want %s", started.Number, started.FiscalYear, want)
+	}
+	current := issue(int(now.Month()), 4)
+	if want := models.InvoiceNumber(now.Year(), 1); current.FiscalYear != now.Year() || current.Number != want {
+		t.Errorf("a year starting this month gave %s in %d, want %s", current.Number, current.FiscalYear, want)
+	}
+	if again := issue(1, 1); again.Number != started.Number {
+		t.Errorf("issuing again gave %s, want the existing %s", again.Number, started.Number)
+	}
+}
+
+func TestIssueInvoiceRejects(t *testing.T) {
+	ctx := context.Background()
+	receipts := NewReceiptUsecase(memrepo.NewRepository(), models.RestaurantDetails{Name: "TastyBites"}, 4, "USD", nil)
+	tests := []struct {
+		name    string
+		orderId int
+		userId  int
+		invoice models.Invoice
+		wantErr error
+	}{
+		{"no customer name", 1, 0, models.Invoice{CustomerName: "  "}, models.ErrInvalidInput},
+		{"not paid", 2, 0, models.Invoice{CustomerName: "John Doe"}, models.ErrOrderNotPaid},
+		{"someone else's order", 1, 3, models.Invoice{CustomerName: "John Doe"}, models.ErrNotFound},
+		{"no such order", 999, 0, models.Invoice{CustomerName: "John Doe"}, models.ErrNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := receipts.IssueInvoice(ctx, tt.orderId, tt.userId, tt.invoice); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
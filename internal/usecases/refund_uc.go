@@ -0,0 +1,334 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/events"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type RefundIUsecase interface {
+	// CreateRefund records a refund and processes it straight away, unless
+	// it is above the approval threshold.
+	CreateRefund(ctx context.Context, refund models.Refund) (models.Refund, error)
+	// ApproveRefund processes a refund waiting for approval. Nobody can
+	// approve their own refund.
+	ApproveRefund(ctx context.Context, refundId, approverId int) (models.Refund, error)
+	RejectRefund(ctx context.Context, refundId, approverId int, note string) (models.Refund, error)
+	GetOrderRefunds(ctx context.Context, orderId int) ([]models.Refund, error)
+	GetRefunds(ctx context.Context, status models.RefundStatus) ([]models.Refund, error)
+	GetSalesReport(ctx context.Context, from, to time.Time) (models.SalesReport, error)
+}
+
+type RefundUsecase struct {
+	repo              interfaces.Repository
+	gateway           payments.PaymentGateway
+	pricing           pricing.Settings
+	approvalThreshold int
+	events            *events.Bus
+}
+
+func NewRefundUsecase(repo interfaces.Repository, gateway payments.PaymentGateway, settings pricing.Settings, approvalThreshold int, bus *events.Bus) RefundIUsecase {
+	return &RefundUsecase{
+		repo:              repo,
+		gateway:           gateway,
+		pricing:           settings,
+		approvalThreshold: approvalThreshold,
+		events:            bus,
+	}
+}
+
+func (u *RefundUsecase) CreateRefund(ctx context.Context, refund models.Refund) (models.Refund, error) {
+	if err := refund.Validate(); err != nil {
+		return models.Refund{}, err
+	}
+	order, err := u.repo.GetOrderById(ctx, refund.OrderID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	paymentList, err := u.repo.GetPaymentsByOrder(ctx, order.ID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	previous, err := u.repo.GetRefundsByOrder(ctx, order.ID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+
+	// Line refunds are worth what the lines were charged
+	if len(refund.Items) > 0 {
+		linesTotal, err := u.priceItems(ctx, order, refund.Items, previous)
+		if err != nil {
+			return models.Refund{}, err
+		}
+		if refund.Amount == 0 {
+			refund.Amount = linesTotal
+		}
+		if refund.Amount > linesTotal {
+			return models.Refund{}, fmt.Errorf("%w: the lines are worth %d cents", models.ErrInvalidInput, linesTotal)
+		}
+	}
+
+	// Open refunds count as given back already
+	refundable := order.Refundable(paymentList)
+	for _, r := range previous {
+		if r.Open() {
+			refundable -= r.Amount
+		}
+	}
+	if refund.Amount == 0 {
+		refund.Amount = refundable
+	}
+	if refundable <= 0 {
+		return models.Refund{}, fmt.Errorf("%w: nothing is left to refund on order %d", models.ErrRefundNotAllowed, order.ID)
+	}
+	if refund.Amount <= 0 || refund.Amount > refundable {
+		return models.Refund{}, fmt.Errorf("%w: amount must be between 1 and %d cents", models.ErrInvalidInput, refundable)
+	}
+
+	if refund.Method == models.RefundMethodGateway {
+		payment, err := refundablePayment(paymentList, previous, refund)
+		if err != nil {
+			return models.Refund{}, err
+		}
+		refund.PaymentID = &payment.ID
+	}
+
+	refund.Status = models.RefundStatusProcessing
+	if refund.Amount > u.approvalThreshold {
+		refund.Status = models.RefundStatusPendingApproval
+	}
+	if refund.ID, err = u.repo.CreateRefund(ctx, refund); err != nil {
+		return models.Refund{}, err
+	}
+	if refund.Status == models.RefundStatusPendingApproval {
+		return u.repo.GetRefundById(ctx, refund.ID)
+	}
+	return u.process(ctx, refund, order)
+}
+
+// priceItems checks the lines against what is left of them to refund and
+// prices each one.
+func (u *RefundUsecase) priceItems(ctx context.Context, order models.Order, items []models.RefundItem, previous []models.Refund) (int, error) {
+	menuItems, err := menuItemsByID(ctx, u.repo)
+	if err != nil {
+		return 0, err
+	}
+	rates, err := u.repo.GetTaxRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := make(map[int]models.OrderItem, len(order.Items))
+	for _, line := range order.Items {
+		lines[line.ID] = line
+	}
+	refunded := models.RefundedQuantities(previous)
+
+	total := 0
+	seen := make(map[int]bool, len(items))
+	for i, item := range items {
+		line, ok := lines[item.OrderItemID]
+		if !ok {
+			return 0, fmt.Errorf("%w: order item %d is not on the order", models.ErrInvalidInput, item.OrderItemID)
+		}
+		if seen[item.OrderItemID] {
+			return 0, fmt.Errorf("%w: order item %d is listed twice", models.ErrInvalidInput, item.OrderItemID)
+		}
+		seen[item.OrderItemID] = true
+		if left := line.Quantity - refunded[line.ID]; item.Quantity > left {
+			return 0, fmt.Errorf("%w: only %d of order item %d are left to refund", models.ErrInvalidInput, left, line.ID)
+		}
+		items[i].Amount = pricing.LineRefund(order, line, item.Quantity, menuItems, rates, u.pricing)
+		total += items[i].Amount
+	}
+	return total, nil
+}
+
+// refundablePayment picks the payment a gateway refund goes back to: the one
+// asked for, or the latest that can take the whole amount.
+func refundablePayment(paymentList []models.Payment, previous []models.Refund, refund models.Refund) (models.Payment, error) {
+	open := make(map[int]int)
+	for _, r := range previous {
+		if r.Open() && r.PaymentID != nil {
+			open[*r.PaymentID] += r.Amount
+		}
+	}
+	left := func(p models.Payment) int {
+		return p.Captured - p.Refunded - open[p.ID]
+	}
+
+	if refund.PaymentID != nil {
+		for _, p := range paymentList {
+			if p.ID != *refund.PaymentID {
+				continue
+			}
+			if left(p) < refund.Amount {
+				return models.Payment{}, fmt.Errorf("%w: payment %d has %d cents left to refund", models.ErrRefundNotAllowed, p.ID, max(left(p), 0))
+			}
+			return p, nil
+		}
+		return models.Payment{}, fmt.Errorf("payment not found with id %d: %w", *refund.PaymentID, models.ErrNotFound)
+	}
+
+	for i := len(paymentList) - 1; i >= 0; i-- {
+		if left(paymentList[i]) >= refund.Amount {
+			return paymentList[i], nil
+		}
+	}
+	return models.Payment{}, fmt.Errorf("%w: no single payment covers %d cents, refund by payment or in cash", models.ErrRefundNotAllowed, refund.Amount)
+}
+
+func (u *RefundUsecase) ApproveRefund(ctx context.Context, refundId, approverId int) (models.Refund, error) {
+	refund, err := u.pendingRefund(ctx, refundId, approverId)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	refund.Status = models.RefundStatusProcessing
+	refund.ApprovedBy = approverId
+	if err := u.repo.UpdateRefundStatus(ctx, refund, models.RefundStatusPendingApproval); err != nil {
+		return models.Refund{}, err
+	}
+	order, err := u.repo.GetOrderById(ctx, refund.OrderID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	return u.process(ctx, refund, order)
+}
+
+func (u *RefundUsecase) RejectRefund(ctx context.Context, refundId, approverId int, note string) (models.Refund, error) {
+	refund, err := u.pendingRefund(ctx, refundId, approverId)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	refund.Status = models.RefundStatusRejected
+	refund.ApprovedBy = approverId
+	refund.FailureReason = note
+	if err := u.repo.UpdateRefundStatus(ctx, refund, models.RefundStatusPendingApproval); err != nil {
+		return models.Refund{}, err
+	}
+	return u.repo.GetRefundById(ctx, refundId)
+}
+
+func (u *RefundUsecase) pendingRefund(ctx context.Context, refundId, approverId int) (models.Refund, error) {
+	refund, err := u.repo.GetRefundById(ctx, refundId)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	if refund.Status != models.RefundStatusPendingApproval {
+		return models.Refund{}, fmt.Errorf("%w: refund is %s", models.ErrRefundNotAllowed, refund.Status)
+	}
+	if refund.RequestedBy == approverId {
+		return models.Refund{}, fmt.Errorf("%w: refunds need approval from someone else", models.ErrRefundNotAllowed)
+	}
+	return refund, nil
+}
+
+// process gives the money back and records the refund on the order. A refund
+// the gateway turns down is marked failed and returned without an error.
+func (u *RefundUsecase) process(ctx context.Context, refund models.Refund, order models.Order) (models.Refund, error) {
+	if refund.Method == models.RefundMethodGateway {
+		if err := u.refundPayment(ctx, *refund.PaymentID, refund.Amount); err != nil {
+			if !errors.Is(err, models.ErrGatewayFailure) && !errors.Is(err, models.ErrRefundNotAllowed) {
+				return models.Refund{}, err
+			}
+			refund.Status = models.RefundStatusFailed
+			refund.FailureReason = err.Error()
+			if err := u.repo.UpdateRefundStatus(ctx, refund, models.RefundStatusProcessing); err != nil {
+				return models.Refund{}, err
+			}
+			return u.repo.GetRefundById(ctx, refund.ID)
+		}
+	}
+
+	if err := u.repo.CompleteRefund(ctx, refund, restockMovements(order, refund)); err != nil {
+		return models.Refund{}, err
+	}
+	if err := syncOrderStatus(ctx, u.repo, u.events, refund.OrderID); err != nil {
+		return models.Refund{}, err
+	}
+	return u.repo.GetRefundById(ctx, refund.ID)
+}
+
+// refundPayment refunds part of a captured payment through the gateway.
+func (u *RefundUsecase) refundPayment(ctx context.Context, paymentId, amount int) error {
+	payment, err := u.repo.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return err
+	}
+	if payment.Status != models.PaymentStatusCaptured && payment.Status != models.PaymentStatusPartiallyRefunded {
+		return fmt.Errorf("%w: payment is %s", models.ErrRefundNotAllowed, payment.Status)
+	}
+	if amount > payment.Captured-payment.Refunded {
+		return fmt.Errorf("%w: payment has %d cents left to refund", models.ErrRefundNotAllowed, payment.Captured-payment.Refunded)
+	}
+
+	result, err := u.gateway.Refund(ctx, payment.Reference, amount)
+	if err != nil {
+		return err
+	}
+	if result.Status != models.PaymentStatusRefunded && result.Status != models.PaymentStatusPartiallyRefunded {
+		return fmt.Errorf("%w: %s", models.ErrRefundNotAllowed, result.FailureReason)
+	}
+	from := payment.Status
+	payment.Refunded += result.Amount
+	payment.Status = models.PaymentStatusPartiallyRefunded
+	if payment.Refunded == payment.Captured {
+		payment.Status = models.PaymentStatusRefunded
+	}
+	return u.repo.UpdatePayment(ctx, payment, from)
+}
+
+// restockMovements lists the menu items a restocking refund puts back, with
+// bundle lines expanded into their components.
+func restockMovements(order models.Order, refund models.Refund) []models.StockMovement {
+	if !refund.Restock {
+		return nil
+	}
+	quantities := make(map[int]int, len(refund.Items))
+	for _, item := range refund.Items {
+		quantities[item.OrderItemID] = item.Quantity
+	}
+
+	var refunded models.Order
+	for _, line := range order.Items {
+		if q, ok := quantities[line.ID]; ok {
+			line.Quantity = q
+			refunded.Items = append(refunded.Items, line)
+		}
+	}
+	var movements []models.StockMovement
+	for _, item := range refunded.KitchenItems() {
+		movements = append(movements, models.StockMovement{
+			MenuItemID: item.MenuItemID,
+			Quantity:   item.Quantity,
+			Reason:     "refund",
+			RefundID:   refund.ID,
+		})
+	}
+	return movements
+}
+
+func (u *RefundUsecase) GetOrderRefunds(ctx context.Context, orderId int) ([]models.Refund, error) {
+	if _, err := u.repo.GetOrderById(ctx, orderId); err != nil {
+		return nil, err
+	}
+	return u.repo.GetRefundsByOrder(ctx, orderId)
+}
+
+func (u *RefundUsecase) GetRefunds(ctx context.Context, status models.RefundStatus) ([]models.Refund, error) {
+	return u.repo.GetRefunds(ctx, status)
+}
+
+func (u *RefundUsecase) GetSalesReport(ctx context.Context, from, to time.Time) (models.SalesReport, error) {
+	if !from.Before(to) {
+		return models.SalesReport{}, fmt.Errorf("%w: from must be before to", models.ErrInvalidInput)
+	}
+	return u.repo.GetSalesReport(ctx, from, to)
+}
@@ -0,0 +1,332 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+// The only line of the sample order 2: one menu item 3 at 14.99.
+const (
+	testOrderLine     = 3
+	testOrderMenuItem = 3
+	testRequester     = 1
+	testManager       = 5
+)
+
+type refundTest struct {
+	refunds RefundIUsecase
+	repo    interfaces.Repository
+	payment models.Payment
+}
+
+// newRefundTest pays order 2 in full through the fake gateway.
+func newRefundTest(t *testing.T, approvalThreshold int) refundTest {
+	t.Helper()
+	repo := memrepo.NewRepository()
+	gateway := newTestGateway()
+	bus := newTestBus(t)
+	payment, err := NewPaymentUsecase(repo, gateway, "USD", bus).CreatePayment(context.Background(), models.PaymentRequest{
+		OrderID: testOrderID, UserID: testOrderUser, Source: "tok_visa",
+	})
+	if err != nil || payment.Status != models.PaymentStatusCaptured {
+		t.Fatalf("paying the order: %+v, %v", payment, err)
+	}
+	settings := pricing.Settings{Rounding: models.RoundHalfUp}
+	return refundTest{
+		refunds: NewRefundUsecase(repo, gateway, settings, approvalThreshold, bus),
+		repo:    repo,
+		payment: payment,
+	}
+}
+
+func (rt refundTest) order(t *testing.T) models.Order {
+	t.Helper()
+	order, err := rt.repo.GetOrderById(context.Background(), testOrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func (rt refundTest) currentPayment(t *testing.T) models.Payment {
+	t.Helper()
+	payment, err := rt.repo.GetPaymentById(context.Background(), rt.payment.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payment
+}
+
+func amountRefund(method models.RefundMethod, amount int) models.Refund {
+	return models.Refund{
+		OrderID:     testOrderID,
+		Method:      method,
+		Reason:      models.RefundReasonQuality,
+		Amount:      amount,
+		RequestedBy: testRequester,
+	}
+}
+
+func TestPartialRefunds(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 100_000)
+
+	refund, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 500))
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	if refund.Status != models.RefundStatusCompleted || refund.PaymentID == nil || *refund.PaymentID != rt.payment.ID {
+		t.Fatalf("refund = %+v, want completed against payment %d", refund, rt.payment.ID)
+	}
+	if p := rt.currentPayment(t); p.Status != models.PaymentStatusPartiallyRefunded || p.Refunded != 500 {
+		t.Errorf("payment = %s with %d refunded, want partially_refunded with 500", p.Status, p.Refunded)
+	}
+	if o := rt.order(t); o.Status != models.OrderStatusPaid || o.RefundedTotal != 500 {
+		t.Errorf("order = %s with %d refunded, want paid with 500", o.Status, o.RefundedTotal)
+	}
+
+	// More than is left is refused
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, testOrderTotal-500+1)); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("over-refund: %v, want ErrInvalidInput", err)
+	}
+
+	// No amount refunds the rest, and the order goes from paid to refunded
+	refund, err = rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 0))
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	if refund.Amount != testOrderTotal-500 || refund.Status != models.RefundStatusCompleted {
+		t.Errorf("refund of the rest = %+v", refund)
+	}
+	if p := rt.currentPayment(t); p.Status != models.PaymentStatusRefunded || p.Refunded != testOrderTotal {
+		t.Errorf("payment = %s with %d refunded, want refunded in full", p.Status, p.Refunded)
+	}
+	if o := rt.order(t); o.Status != models.OrderStatusRefunded || o.RefundedTotal != testOrderTotal {
+		t.Errorf("order = %s with %d refunded, want refunded in full", o.Status, o.RefundedTotal)
+	}
+
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 1)); !errors.Is(err, models.ErrRefundNotAllowed) {
+		t.Errorf("refund of a refunded order: %v, want ErrRefundNotAllowed", err)
+	}
+}
+
+func TestRefundRejectsInvalid(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 100_000)
+	paymentID := rt.payment.ID
+
+	tests := []struct {
+		name   string
+		refund func(r *models.Refund)
+		want   error
+	}{
+		{"unknown reason", func(r *models.Refund) { r.Reason = "bored" }, models.ErrInvalidInput},
+		{"other without a note", func(r *models.Refund) { r.Reason = models.RefundReasonOther }, models.ErrInvalidInput},
+		{"unknown method", func(r *models.Refund) { r.Method = "cheque" }, models.ErrInvalidInput},
+		{"cash against a payment", func(r *models.Refund) { r.Method, r.PaymentID = models.RefundMethodCash, &paymentID }, models.ErrInvalidInput},
+		{"negative amount", func(r *models.Refund) { r.Amount = -1 }, models.ErrInvalidInput},
+		{"more than was paid", func(r *models.Refund) { r.Amount = testOrderTotal + 1 }, models.ErrInvalidInput},
+		{"restock without lines", func(r *models.Refund) { r.Restock = true }, models.ErrInvalidInput},
+		{"line not on the order", func(r *models.Refund) {
+			r.Items = []models.RefundItem{{OrderItemID: 1, Quantity: 1}}
+		}, models.ErrInvalidInput},
+		{"more units than ordered", func(r *models.Refund) {
+			r.Items = []models.RefundItem{{OrderItemID: testOrderLine, Quantity: 2}}
+		}, models.ErrInvalidInput},
+		{"more than the lines are worth", func(r *models.Refund) {
+			r.Items = []models.RefundItem{{OrderItemID: testOrderLine, Quantity: 1}}
+			r.Amount = testOrderTotal + 1
+		}, models.ErrInvalidInput},
+		{"unknown order", func(r *models.Refund) { r.OrderID = 999 }, models.ErrNotFound},
+		{"unpaid order", func(r *models.Refund) { r.OrderID = 3 }, models.ErrRefundNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := amountRefund(models.RefundMethodGateway, 100)
+			tt.refund(&refund)
+			if _, err := rt.refunds.CreateRefund(ctx, refund); !errors.Is(err, tt.want) {
+				t.Errorf("CreateRefund() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if p := rt.currentPayment(t); p.Refunded != 0 {
+		t.Errorf("rejected refunds refunded %d", p.Refunded)
+	}
+}
+
+func TestRefundApproval(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 1000)
+
+	// At the threshold a refund goes straight through
+	small, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 1000))
+	if err != nil || small.Status != models.RefundStatusCompleted {
+		t.Fatalf("refund at the threshold = %+v, %v, want completed", small, err)
+	}
+
+	rt = newRefundTest(t, 1000)
+	large, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 1200))
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	if large.Status != models.RefundStatusPendingApproval {
+		t.Fatalf("refund over the threshold is %s, want pending_approval", large.Status)
+	}
+	if p := rt.currentPayment(t); p.Refunded != 0 {
+		t.Errorf("money went back before approval: %d", p.Refunded)
+	}
+
+	// The pending refund holds its amount
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 300)); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("refund beyond the pending one: %v, want ErrInvalidInput", err)
+	}
+	pending, err := rt.refunds.GetRefunds(ctx, models.RefundStatusPendingApproval)
+	if err != nil || len(pending) != 1 || pending[0].ID != large.ID {
+		t.Errorf("pending refunds = %+v, %v", pending, err)
+	}
+
+	if _, err := rt.refunds.ApproveRefund(ctx, large.ID, testRequester); !errors.Is(err, models.ErrRefundNotAllowed) {
+		t.Errorf("approving your own refund: %v, want ErrRefundNotAllowed", err)
+	}
+	approved, err := rt.refunds.ApproveRefund(ctx, large.ID, testManager)
+	if err != nil {
+		t.Fatalf("ApproveRefund() failed: %v", err)
+	}
+	if approved.Status != models.RefundStatusCompleted || approved.ApprovedBy != testManager {
+		t.Errorf("approved refund = %+v", approved)
+	}
+	if p := rt.currentPayment(t); p.Refunded != 1200 {
+		t.Errorf("payment refunded %d, want 1200", p.Refunded)
+	}
+	if _, err := rt.refunds.ApproveRefund(ctx, large.ID, testManager); !errors.Is(err, models.ErrRefundNotAllowed) {
+		t.Errorf("approving twice: %v, want ErrRefundNotAllowed", err)
+	}
+}
+
+func TestRefundRejection(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 1000)
+	large, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 1499))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejected, err := rt.refunds.RejectRefund(ctx, large.ID, testManager, "food was eaten")
+	if err != nil {
+		t.Fatalf("RejectRefund() failed: %v", err)
+	}
+	if rejected.Status != models.RefundStatusRejected || rejected.FailureReason != "food was eaten" {
+		t.Errorf("rejected refund = %+v", rejected)
+	}
+	if o := rt.order(t); o.Status != models.OrderStatusPaid || o.RefundedTotal != 0 {
+		t.Errorf("order = %s with %d refunded, want paid with nothing refunded", o.Status, o.RefundedTotal)
+	}
+	// A rejected refund no longer holds its amount
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, 800)); err != nil {
+		t.Errorf("refund after the rejection: %v", err)
+	}
+}
+
+func TestCashRefund(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 100_000)
+
+	refund, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodCash, 300))
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	if refund.Status != models.RefundStatusCompleted || refund.PaymentID != nil {
+		t.Errorf("cash refund = %+v", refund)
+	}
+	// The card payment is untouched, the order records the cash
+	if p := rt.currentPayment(t); p.Refunded != 0 || p.Status != models.PaymentStatusCaptured {
+		t.Errorf("payment = %s with %d refunded, want captured with nothing refunded", p.Status, p.Refunded)
+	}
+	if o := rt.order(t); o.CashRefunded != 300 || o.RefundedTotal != 300 || o.Status != models.OrderStatusPaid {
+		t.Errorf("order = %s, refunded %d, cash %d", o.Status, o.RefundedTotal, o.CashRefunded)
+	}
+
+	// Cash and card together cannot give back more than was taken
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodGateway, testOrderTotal-300+1)); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("over-refund after cash: %v, want ErrInvalidInput", err)
+	}
+	if _, err := rt.refunds.CreateRefund(ctx, amountRefund(models.RefundMethodCash, testOrderTotal-300)); err != nil {
+		t.Fatalf("cash refund of the rest: %v", err)
+	}
+	if o := rt.order(t); o.Status != models.OrderStatusRefunded {
+		t.Errorf("order is %s, want refunded", o.Status)
+	}
+}
+
+func TestLineRefundRestocks(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 100_000)
+
+	// Track the stock of the refunded menu item
+	item, err := rt.repo.GetMenuItemById(ctx, testOrderMenuItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stock := 5
+	item.Stock = &stock
+	err = rt.repo.ImportMenu(ctx, []models.MenuImportChange{{
+		SKU: item.SKU, Action: models.MenuImportUpdate, Fields: []string{"stock"}, Item: item,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refund := amountRefund(models.RefundMethodGateway, 0)
+	refund.Reason = models.RefundReasonWrongItem
+	refund.Items = []models.RefundItem{{OrderItemID: testOrderLine, Quantity: 1}}
+	refund.Restock = true
+	refund, err = rt.refunds.CreateRefund(ctx, refund)
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	// The line is worth what it was charged
+	if refund.Amount != testOrderTotal || len(refund.Items) != 1 || refund.Items[0].Amount != testOrderTotal {
+		t.Errorf("line refund = %+v, want %d for the line", refund, testOrderTotal)
+	}
+	item, err = rt.repo.GetMenuItemById(ctx, testOrderMenuItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Stock == nil || *item.Stock != 6 {
+		t.Errorf("stock after the refund = %v, want 6", item.Stock)
+	}
+	if o := rt.order(t); o.Status != models.OrderStatusRefunded {
+		t.Errorf("order is %s, want refunded", o.Status)
+	}
+
+	// The line cannot be refunded twice
+	again := amountRefund(models.RefundMethodCash, 0)
+	again.Items = []models.RefundItem{{OrderItemID: testOrderLine, Quantity: 1}}
+	if _, err := rt.refunds.CreateRefund(ctx, again); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("refunding the line twice: %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestLineRefundWithoutRestock(t *testing.T) {
+	ctx := context.Background()
+	rt := newRefundTest(t, 100_000)
+	refund := amountRefund(models.RefundMethodGateway, 1000)
+	refund.Items = []models.RefundItem{{OrderItemID: testOrderLine, Quantity: 1}}
+	refund, err := rt.refunds.CreateRefund(ctx, refund)
+	if err != nil {
+		t.Fatalf("CreateRefund() failed: %v", err)
+	}
+	// A lower amount than the line is worth is kept
+	if refund.Amount != 1000 || refund.Status != models.RefundStatusCompleted {
+		t.Errorf("refund = %+v", refund)
+	}
+	item, _ := rt.repo.GetMenuItemById(ctx, testOrderMenuItem)
+	if item.Stock != nil {
+		t.Errorf("untracked stock became %d", *item.Stock)
+	}
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type SplitIUsecase interface {
+	// PreviewSplit works out the checks without saving them.
+	PreviewSplit(ctx context.Context, orderId, userId int, req models.SplitRequest) ([]models.Check, error)
+	// SplitOrder replaces the checks of an order, which must not have
+	// payments yet.
+	SplitOrder(ctx context.Context, orderId, userId int, req models.SplitRequest) ([]models.Check, error)
+	// GetOrderChecks lists the checks of an order with what was paid on
+	// each; a non-zero userId must own the order.
+	GetOrderChecks(ctx context.Context, orderId, userId int) ([]models.Check, error)
+	// MergeChecks removes the split so the order is paid as a whole again,
+	// with the tips of the checks as its tip. The order must be pending and
+	// not have payments.
+	MergeChecks(ctx context.Context, orderId, userId int) error
+}
+
+type SplitUsecase struct {
+	repo    interfaces.Repository
+	pricing pricing.Settings
+}
+
+func NewSplitUsecase(repo interfaces.Repository, settings pricing.Settings) SplitIUsecase {
+	return &SplitUsecase{
+		repo:    repo,
+		pricing: settings,
+	}
+}
+
+func (s *SplitUsecase) PreviewSplit(ctx context.Context, orderId, userId int, req models.SplitRequest) ([]models.Check, error) {
+	_, checks, err := s.split(ctx, orderId, userId, req)
+	return checks, err
+}
+
+func (s *SplitUsecase) SplitOrder(ctx context.Context, orderId, userId int, req models.SplitRequest) ([]models.Check, error) {
+	order, checks, err := s.split(ctx, orderId, userId, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateOrderChecks(ctx, order, checks); err != nil {
+		return nil, err
+	}
+	return s.GetOrderChecks(ctx, orderId, 0)
+}
+
+// split works out the checks for an order the user owns and can still split.
+func (s *SplitUsecase) split(ctx context.Context, orderId, userId int, req models.SplitRequest) (models.Order, []models.Check, error) {
+	order, err := s.ownedOrder(ctx, orderId, userId)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	if order.Status != models.OrderStatusPending {
+		return models.Order{}, nil, fmt.Errorf("%w: only pending orders can be split", models.ErrInvalidInput)
+	}
+	payments, err := s.repo.GetPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	if order.Balance(payments) < order.TotalPrice {
+		return models.Order{}, nil, fmt.Errorf("%w: the order already has payments", models.ErrInvalidInput)
+	}
+
+	menuItems, err := menuItemsByID(ctx, s.repo)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	rates, err := s.repo.GetTaxRates(ctx)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	checks, err := pricing.Split(&order, req, menuItems, rates, s.pricing)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	return order, checks, nil
+}
+
+func (s *SplitUsecase) GetOrderChecks(ctx context.Context, orderId, userId int) ([]models.Check, error) {
+	if _, err := s.ownedOrder(ctx, orderId, userId); err != nil {
+		return nil, err
+	}
+	checks, err := s.repo.GetOrderChecks(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.repo.GetPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range checks {
+		checks[i].ApplyPayments(payments)
+	}
+	return checks, nil
+}
+
+func (s *SplitUsecase) MergeChecks(ctx context.Context, orderId, userId int) error {
+	order, err := s.ownedOrder(ctx, orderId, userId)
+	if err != nil {
+		return err
+	}
+	if order.Status != models.OrderStatusPending {
+		return fmt.Errorf("%w: only pending orders can be merged", models.ErrInvalidInput)
+	}
+	return s.repo.DeleteOrderChecks(ctx, orderId)
+}
+
+// ownedOrder loads an order, hiding it from anyone but its owner unless
+// userId is zero.
+func (s *SplitUsecase) ownedOrder(ctx context.Context, orderId, userId int) (models.Order, error) {
+	order, err := s.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if userId != 0 && order.UserID != userId {
+		return models.Order{}, fmt.Errorf("order not found with id %d: %w", orderId, models.ErrNotFound)
+	}
+	return order, nil
+}
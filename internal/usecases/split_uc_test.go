@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/pricing"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+func TestMergeChecksKeepsTips(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	uc := NewSplitUsecase(repo, pricing.Settings{Rounding: models.RoundHalfUp})
+
+	checks, err := uc.SplitOrder(ctx, testOrderID, testOrderUser, models.SplitRequest{
+		Mode:   models.SplitEvenly,
+		Checks: []models.CheckRequest{{Tip: 200}, {TipPercent: 10}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].Tip != 200 || checks[1].Tip != 75 {
+		t.Fatalf("checks %+v, want tips of 2.00 and 10%%", checks)
+	}
+	const tip = 275
+
+	if err := uc.MergeChecks(ctx, testOrderID, testOrderUser+1); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("merging someone else's order: %v, want ErrNotFound", err)
+	}
+	if err := uc.MergeChecks(ctx, testOrderID, testOrderUser); err != nil {
+		t.Fatal(err)
+	}
+	if checks, err := uc.GetOrderChecks(ctx, testOrderID, 0); err != nil || len(checks) != 0 {
+		t.Errorf("checks after merging: %+v, %v", checks, err)
+	}
+	order, err := repo.GetOrderById(ctx, testOrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Tip != tip || order.TotalPrice != testOrderTotal+tip {
+		t.Errorf("merged order tip %d, total %d; want %d and %d", order.Tip, order.TotalPrice, tip, testOrderTotal+tip)
+	}
+
+	// The order is paid as a whole, tips included
+	payment, err := NewPaymentUsecase(repo, newTestGateway(), "USD", newTestBus(t)).CreatePayment(ctx, models.PaymentRequest{
+		OrderID: testOrderID, UserID: testOrderUser, Source: "tok_visa",
+	})
+	if err != nil || payment.Captured != testOrderTotal+tip {
+		t.Errorf("paying the merged order: %+v, %v; want %d captured", payment, err, testOrderTotal+tip)
+	}
+}
+
+func TestMergeChecksRejects(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	uc := NewSplitUsecase(repo, pricing.Settings{Rounding: models.RoundHalfUp})
+	split := models.SplitRequest{Mode: models.SplitEvenly, Count: 2}
+
+	// Order 1 of the sample data is completed
+	if err := uc.MergeChecks(ctx, 1, 0); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("merging a completed order: %v, want ErrInvalidInput", err)
+	}
+	if err := uc.MergeChecks(ctx, 999, 0); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("merging a missing order: %v, want ErrNotFound", err)
+	}
+
+	// Once a check is paid the split stays
+	checks, err := uc.SplitOrder(ctx, testOrderID, 0, split)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payments := NewPaymentUsecase(repo, newTestGateway(), "USD", newTestBus(t))
+	if _, err := payments.CreatePayment(ctx, models.PaymentRequest{OrderID: testOrderID, CheckID: checks[0].ID, UserID: testOrderUser, Source: "tok_visa"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.MergeChecks(ctx, testOrderID, 0); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("merging a part-paid order: %v, want ErrInvalidInput", err)
+	}
+	if checks, _ := uc.GetOrderChecks(ctx, testOrderID, 0); len(checks) != 2 {
+		t.Errorf("%d checks left, want the split kept", len(checks))
+	}
+
+	// and so it does once the order is no longer pending
+	if err := repo.UpdateOrderStatus(ctx, 3, models.OrderStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.MergeChecks(ctx, 3, 0); !errors.Is(err, models.ErrInvalidInput) {
+		t.Errorf("merging a cancelled order: %v, want ErrInvalidInput", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/abdullahnettoor/tastybites/internal/events"
 	"github.com/abdullahnettoor/tastybites/internal/models"
 	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
 )
@@ -16,12 +17,14 @@ type TableIUsecase interface {
 }
 
 type TableUsecase struct {
-	repo interfaces.Repository
+	repo   interfaces.Repository
+	events *events.Bus
 }
 
-func NewTableUsecase(repo interfaces.Repository) TableIUsecase {
+func NewTableUsecase(repo interfaces.Repository, bus *events.Bus) TableIUsecase {
 	return &TableUsecase{
-		repo: repo,
+		repo:   repo,
+		events: bus,
 	}
 }
 
@@ -61,15 +64,18 @@ func (m *TableUsecase) ResetTableStatus(ctx context.Context, tableID int) error
 		return fmt.Errorf("table not found: %w", err)
 	}
 
-	err = m.repo.UpdateOrderStatusByTableId(ctx, tableID, models.OrderStatusCompleted)
-	if err != nil {
-		return fmt.Errorf("failed to complete orders for table: %w", err)
-	}
-
-	err = m.repo.ResetTableToAvailable(ctx, tableID)
+	orderIDs, err := m.repo.ResetTable(ctx, tableID)
 	if err != nil {
 		return fmt.Errorf("failed to reset table status: %w", err)
 	}
 
+	m.events.Publish(events.TableEvent(events.TableReset, tableID))
+	for _, id := range orderIDs {
+		order, err := m.repo.GetOrderById(ctx, id)
+		if err != nil {
+			return err
+		}
+		m.events.Publish(events.OrderEvent(events.OrderStatusChanged, order))
+	}
 	return nil
 }
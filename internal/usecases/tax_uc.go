@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+type TaxIUsecase interface {
+	CreateTaxRate(ctx context.Context, rate models.TaxRate) (int, error)
+	GetTaxRates(ctx context.Context) ([]models.TaxRate, error)
+}
+
+type TaxUsecase struct {
+	repo interfaces.Repository
+}
+
+func NewTaxUsecase(repo interfaces.Repository) TaxIUsecase {
+	return &TaxUsecase{
+		repo: repo,
+	}
+}
+
+func (t *TaxUsecase) CreateTaxRate(ctx context.Context, rate models.TaxRate) (int, error) {
+	rate.Name = strings.TrimSpace(rate.Name)
+	categories := make([]string, 0, len(rate.Categories))
+	seen := make(map[string]bool)
+	for _, category := range rate.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		categories = append(categories, category)
+	}
+	rate.Categories = categories
+	if err := rate.Validate(); err != nil {
+		return 0, err
+	}
+
+	rates, err := t.repo.GetTaxRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range rates {
+		if strings.EqualFold(existing.Name, rate.Name) {
+			return 0, fmt.Errorf("%w: tax rate %q already exists", models.ErrInvalidInput, rate.Name)
+		}
+	}
+	return t.repo.CreateTaxRate(ctx, rate)
+}
+
+func (t *TaxUsecase) GetTaxRates(ctx context.Context) ([]models.TaxRate, error) {
+	return t.repo.GetTaxRates(ctx)
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/abdullahnettoor/tastybites/internal/auth"
 	"github.com/abdullahnettoor/tastybites/internal/models"
@@ -14,6 +15,7 @@ type UserIUsecase interface {
 	LoginUser(ctx context.Context, email, password string) (models.User, error)
 	GetUser(ctx context.Context, id int) (models.User, error)
 	UpdateUser(ctx context.Context, user models.User) error
+	ResetPassword(ctx context.Context, email, password string) error
 	DeleteUser(ctx context.Context, id int) error
 
 	// User operations
@@ -21,6 +23,7 @@ type UserIUsecase interface {
 
 	// Admin operations
 	GetOrderByTableId(ctx context.Context, tableId int) (models.Order, error)
+	GetOrdersBetween(ctx context.Context, from, to time.Time) ([]models.Order, error)
 }
 
 type UserUsecase struct {
@@ -79,6 +82,20 @@ func (u *UserUsecase) UpdateUser(ctx context.Context, user models.User) error {
 	return u.repo.UpdateUser(ctx, user)
 }
 
+// ResetPassword sets a new password for the user with the email, e.g. when
+// an operator recovers a locked out admin account.
+func (u *UserUsecase) ResetPassword(ctx context.Context, email, password string) error {
+	user, err := u.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return u.repo.UpdateUserPassword(ctx, user.ID, hash)
+}
+
 func (u *UserUsecase) DeleteUser(ctx context.Context, id int) error {
 	return u.repo.DeleteUser(ctx, id)
 }
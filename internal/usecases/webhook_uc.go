@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+// deliveriesLimit is how many of an endpoint's latest deliveries are listed.
+const deliveriesLimit = 50
+
+type WebhookIUsecase interface {
+	// CreateEndpoint registers an endpoint with a new signing secret. The
+	// secret is only returned here.
+	CreateEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error)
+	// SetEndpointActive pauses or resumes an endpoint. Events that happen
+	// while it is paused are not sent to it later.
+	SetEndpointActive(ctx context.Context, endpointId int, active bool) (models.WebhookEndpoint, error)
+
+	// GetDeliveries lists an endpoint's latest deliveries with their
+	// attempts, optionally only those of one status.
+	GetDeliveries(ctx context.Context, endpointId int, status models.WebhookDeliveryStatus) ([]models.WebhookDelivery, error)
+	// RetryDelivery sends a dead or waiting delivery again right away with
+	// a fresh set of attempts.
+	RetryDelivery(ctx context.Context, deliveryId int64) error
+}
+
+type WebhookUsecase struct {
+	repo interfaces.Repository
+}
+
+func NewWebhookUsecase(repo interfaces.Repository) WebhookIUsecase {
+	return &WebhookUsecase{
+		repo: repo,
+	}
+}
+
+func (wh *WebhookUsecase) CreateEndpoint(ctx context.Context, endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	if err := endpoint.Validate(); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return models.WebhookEndpoint{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	endpoint.Secret = "whsec_" + hex.EncodeToString(b)
+	endpoint.Active = true
+
+	id, err := wh.repo.CreateWebhookEndpoint(ctx, endpoint)
+	if err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	created, err := wh.repo.GetWebhookEndpointById(ctx, id)
+	if err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	created.Secret = endpoint.Secret
+	return created, nil
+}
+
+func (wh *WebhookUsecase) GetEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	return wh.repo.GetWebhookEndpoints(ctx)
+}
+
+func (wh *WebhookUsecase) SetEndpointActive(ctx context.Context, endpointId int, active bool) (models.WebhookEndpoint, error) {
+	if err := wh.repo.SetWebhookEndpointActive(ctx, endpointId, active); err != nil {
+		return models.WebhookEndpoint{}, err
+	}
+	return wh.repo.GetWebhookEndpointById(ctx, endpointId)
+}
+
+func (wh *WebhookUsecase) GetDeliveries(ctx context.Context, endpointId int, status models.WebhookDeliveryStatus) ([]models.WebhookDelivery, error) {
+	if status != "" && !status.Valid() {
+		return nil, fmt.Errorf("%w: unknown delivery status %q", models.ErrInvalidInput, status)
+	}
+	if _, err := wh.repo.GetWebhookEndpointById(ctx, endpointId); err != nil {
+		return nil, err
+	}
+	return wh.repo.GetWebhookDeliveries(ctx, endpointId, status, deliveriesLimit)
+}
+
+func (wh *WebhookUsecase) RetryDelivery(ctx context.Context, deliveryId int64) error {
+	return wh.repo.RetryWebhookDelivery(ctx, deliveryId)
+}
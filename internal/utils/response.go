@@ -43,3 +43,11 @@ func GetUserIDFromContext(r *http.Request) (int, error) {
 	}
 	return userID, nil
 }
+
+func GetRoleFromContext(r *http.Request) (string, error) {
+	role, ok := r.Context().Value("role").(string)
+	if !ok {
+		return "", errors.New("role not found in context")
+	}
+	return role, nil
+}
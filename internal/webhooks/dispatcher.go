@@ -0,0 +1,258 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+)
+
+const (
+	batchSize     = 100           // outbox events and deliveries taken per query
+	concurrency   = 8             // deliveries sent at once
+	maxRetryWait  = 6 * time.Hour // longest wait between two attempts
+	leaseMargin   = 30 * time.Second
+	maxErrorBytes = 512 // of a failed response kept in the delivery log
+)
+
+// Headers sent with every delivery besides payments.SignatureHeader.
+const (
+	EventHeader    = "Tastybites-Event"
+	DeliveryHeader = "Tastybites-Delivery"
+)
+
+// Dispatcher turns outbox events into webhook deliveries and sends them.
+// Deliveries live in the database, so a restart picks up where it left off
+// and several instances can run side by side.
+type Dispatcher struct {
+	repo         interfaces.WebhookRepository
+	client       *http.Client
+	maxAttempts  int
+	retryDelay   time.Duration
+	pollInterval time.Duration
+	timeout      time.Duration
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	beat    atomic.Int64 // when the loop last made progress, in Unix nanoseconds
+	stopped atomic.Bool
+}
+
+// NewDispatcher starts polling the outbox in the background.
+func NewDispatcher(repo interfaces.WebhookRepository, cfg *config.WebhooksConfig) (*Dispatcher, error) {
+	if cfg.MaxAttempts < 1 {
+		return nil, fmt.Errorf("webhook attempts must be at least 1, got %d", cfg.MaxAttempts)
+	}
+	if cfg.RetryDelay <= 0 {
+		return nil, fmt.Errorf("webhook retry delay must be positive, got %s", cfg.RetryDelay)
+	}
+	if cfg.PollInterval <= 0 {
+		return nil, fmt.Errorf("webhook poll interval must be positive, got %s", cfg.PollInterval)
+	}
+	if cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("webhook timeout must be positive, got %s", cfg.Timeout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		repo:         repo,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		maxAttempts:  cfg.MaxAttempts,
+		retryDelay:   cfg.RetryDelay,
+		pollInterval: cfg.PollInterval,
+		timeout:      cfg.Timeout,
+		cancel:       cancel,
+	}
+	d.alive()
+	d.wg.Add(1)
+	go d.run(ctx)
+	return d, nil
+}
+
+// Close stops polling and waits for deliveries in flight. Deliveries that
+// were claimed but not sent are retried once their lease runs out.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Check fails once the dispatcher has stopped, or when its loop has made no
+// progress for longer than sending a whole batch may take, e.g. because a
+// query hangs.
+func (d *Dispatcher) Check(ctx context.Context) error {
+	if d.stopped.Load() {
+		return errors.New("webhook dispatcher is stopped")
+	}
+	if since := time.Since(time.Unix(0, d.beat.Load())); since > d.lease()+2*d.pollInterval {
+		return fmt.Errorf("webhook dispatcher is stuck, no progress for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (d *Dispatcher) alive() {
+	d.beat.Store(time.Now().UnixNano())
+}
+
+// lease is long enough to send a whole batch before anyone else may take
+// it.
+func (d *Dispatcher) lease() time.Duration {
+	return d.timeout*time.Duration((batchSize+concurrency-1)/concurrency) + leaseMargin
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer d.wg.Done()
+	defer d.stopped.Store(true)
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		d.alive()
+		d.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fans new outbox events out to endpoints, then sends what is due.
+func (d *Dispatcher) poll(ctx context.Context) {
+	for {
+		n, err := d.repo.DispatchOutbox(ctx, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("failed to dispatch outbox: %v", err)
+			}
+			break
+		}
+		if n < batchSize {
+			break
+		}
+		d.alive()
+	}
+
+	for ctx.Err() == nil {
+		d.alive()
+		deliveries, err := d.repo.ClaimWebhookDeliveries(ctx, batchSize, d.lease())
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("failed to claim webhook deliveries: %v", err)
+			}
+			return
+		}
+		d.sendAll(ctx, deliveries)
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) sendAll(ctx context.Context, deliveries []models.WebhookDelivery) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+// deliver makes one attempt and records its outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	attempt := models.WebhookAttempt{Attempt: delivery.Attempts + 1}
+	start := time.Now()
+	attempt.StatusCode, attempt.Error = d.send(ctx, delivery)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+	if ctx.Err() != nil {
+		// Shutting down; the lease runs out and the delivery is tried again
+		return
+	}
+
+	status := models.WebhookDeliveryDelivered
+	var retryIn time.Duration
+	switch {
+	case attempt.Error == "":
+	case attempt.Attempt >= d.maxAttempts:
+		status = models.WebhookDeliveryDead
+		log.Printf("webhook delivery %d to %s failed for good after %d attempts: %s", delivery.ID, delivery.URL, attempt.Attempt, attempt.Error)
+	default:
+		status = models.WebhookDeliveryRetrying
+		retryIn = d.backoff(attempt.Attempt)
+	}
+
+	// Recorded even if the dispatcher is closed meanwhile, so a delivered
+	// event is not sent twice
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.timeout)
+	defer cancel()
+	if err := d.repo.RecordWebhookAttempt(recordCtx, delivery.ID, attempt, status, retryIn); err != nil {
+		log.Printf("failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// backoff is the wait after the given failed attempt: the retry delay,
+// doubled after each attempt up to maxRetryWait.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.retryDelay
+	for i := 1; i < attempt && wait < maxRetryWait; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryWait)
+}
+
+// send posts the event and returns the response status and, unless the
+// endpoint answered with a 2xx, what went wrong.
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, string) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, fmt.Sprintf("failed to encode event: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Sprintf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TastyBites-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event.Type)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(payments.SignatureHeader, payments.Sign(delivery.Secret, body, time.Now()))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return resp.StatusCode, ""
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+	msg := resp.Status
+	if s := bytes.TrimSpace(snippet); len(s) > 0 {
+		msg += ": " + strings.ToValidUTF8(string(s), "?")
+	}
+	return resp.StatusCode, msg
+}
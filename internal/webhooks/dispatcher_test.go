@@ -0,0 +1,304 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abdullahnettoor/tastybites/internal/config"
+	"github.com/abdullahnettoor/tastybites/internal/models"
+	"github.com/abdullahnettoor/tastybites/internal/payments"
+	"github.com/abdullahnettoor/tastybites/internal/repo/interfaces"
+	memrepo "github.com/abdullahnettoor/tastybites/internal/repo/memory"
+)
+
+const (
+	testSecret   = "whsec_test"
+	testOrderID  = 2 // a pending order of the sample data
+	testRetryGap = 20 * time.Millisecond
+)
+
+// receiver is a webhook endpoint that checks every request the way a
+// subscriber would and answers 500 to the first failures of them.
+type receiver struct {
+	t        *testing.T
+	failures int
+
+	mu       sync.Mutex
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	at       time.Time
+	event    models.OutboxEvent
+	delivery string
+}
+
+func newReceiver(t *testing.T, failures int) (*receiver, *httptest.Server) {
+	rc := &receiver{t: t, failures: failures}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	return rc, srv
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := payments.Verify(testSecret, body, r.Header, time.Now()); err != nil {
+		rc.t.Errorf("delivery is not signed: %v", err)
+		http.Error(w, "bad signature", http.StatusBadRequest)
+		return
+	}
+	var event models.OutboxEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		rc.t.Errorf("delivery is not an event: %v", err)
+		http.Error(w, "bad event", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || r.Header.Get(EventHeader) != event.Type {
+		rc.t.Errorf("unexpected %s request with headers %v", r.Method, r.Header)
+	}
+
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, receivedRequest{at: time.Now(), event: event, delivery: r.Header.Get(DeliveryHeader)})
+	n := len(rc.requests)
+	rc.mu.Unlock()
+
+	if n <= rc.failures {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}
+}
+
+func (rc *receiver) received() []receivedRequest {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]receivedRequest{}, rc.requests...)
+}
+
+func newTestDispatcher(t *testing.T, repo interfaces.WebhookRepository, maxAttempts int) *Dispatcher {
+	t.Helper()
+	d, err := NewDispatcher(repo, &config.WebhooksConfig{
+		MaxAttempts:  maxAttempts,
+		RetryDelay:   testRetryGap,
+		PollInterval: 5 * time.Millisecond,
+		Timeout:      2 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
+
+// setup subscribes an endpoint at url to status changes and changes the
+// status of an order.
+func setup(t *testing.T, url string) (interfaces.Repository, int) {
+	t.Helper()
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	endpointId, err := repo.CreateWebhookEndpoint(ctx, models.WebhookEndpoint{
+		URL:        url,
+		Secret:     testSecret,
+		EventTypes: []string{models.OutboxOrderStatusChanged},
+		Active:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateOrderStatus(ctx, testOrderID, models.OrderStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	return repo, endpointId
+}
+
+// waitForDelivery polls the endpoint's only delivery until it has the
+// status.
+func waitForDelivery(t *testing.T, repo interfaces.Repository, endpointId int, status models.WebhookDeliveryStatus) models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := repo.GetWebhookDeliveries(context.Background(), endpointId, "", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) > 1 {
+			t.Fatalf("endpoint has %d deliveries, want 1", len(deliveries))
+		}
+		if len(deliveries) == 1 && deliveries[0].Status == status {
+			return deliveries[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery did not become %s: %+v", status, deliveries)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	rc, srv := newReceiver(t, 0)
+	repo, endpointId := setup(t, srv.URL)
+	other, otherSrv := newReceiver(t, 0)
+	if _, err := repo.CreateWebhookEndpoint(context.Background(), models.WebhookEndpoint{
+		URL:        otherSrv.URL,
+		Secret:     testSecret,
+		EventTypes: []string{models.OutboxTableReset},
+		Active:     true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	newTestDispatcher(t, repo, 3)
+
+	delivery := waitForDelivery(t, repo, endpointId, models.WebhookDeliveryDelivered)
+	if delivery.Attempts != 1 || delivery.DeliveredAt == "" || delivery.LastError != "" ||
+		len(delivery.Log) != 1 || delivery.Log[0].StatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v", delivery)
+	}
+
+	requests := rc.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.delivery != strconv.FormatInt(delivery.ID, 10) || req.event.Type != models.OutboxOrderStatusChanged {
+		t.Errorf("received delivery %s of %s", req.delivery, req.event.Type)
+	}
+	var change models.OrderChange
+	if err := json.Unmarshal(req.event.Data, &change); err != nil || change.OrderID != testOrderID || change.Status != models.OrderStatusCancelled {
+		t.Errorf("received change %+v, %v", change, err)
+	}
+
+	if n := len(other.received()); n != 0 {
+		t.Errorf("an endpoint not subscribed to the event got %d requests", n)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	rc, srv := newReceiver(t, 2)
+	repo, endpointId := setup(t, srv.URL)
+	newTestDispatcher(t, repo, 5)
+
+	delivery := waitForDelivery(t, repo, endpointId, models.WebhookDeliveryDelivered)
+	if delivery.Attempts != 3 || delivery.LastError != "" || len(delivery.Log) != 3 {
+		t.Fatalf("delivery = %+v, want delivered on the third attempt", delivery)
+	}
+	for i, a := range delivery.Log {
+		wantCode := http.StatusInternalServerError
+		if i == 2 {
+			wantCode = http.StatusOK
+		}
+		if a.Attempt != i+1 || a.StatusCode != wantCode || (wantCode != http.StatusOK && a.Error != "500 Internal Server Error: boom") {
+			t.Errorf("attempt %d = %+v", i+1, a)
+		}
+	}
+
+	// Each retry waits twice as long as the one before
+	requests := rc.received()
+	if len(requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(requests))
+	}
+	for i, want := range []time.Duration{testRetryGap, 2 * testRetryGap} {
+		if gap := requests[i+1].at.Sub(requests[i].at); gap < want {
+			t.Errorf("retry %d came after %s, want at least %s", i+1, gap, want)
+		}
+		if requests[i+1].delivery != requests[0].delivery {
+			t.Errorf("retry %d sent as delivery %s, want %s", i+1, requests[i+1].delivery, requests[0].delivery)
+		}
+	}
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	rc, srv := newReceiver(t, 1000)
+	repo, endpointId := setup(t, srv.URL)
+	newTestDispatcher(t, repo, 3)
+
+	delivery := waitForDelivery(t, repo, endpointId, models.WebhookDeliveryDead)
+	if delivery.Attempts != 3 || !strings.HasPrefix(delivery.LastError, "500") || delivery.NextAttemptAt != "" || len(delivery.Log) != 3 {
+		t.Errorf("dead delivery = %+v", delivery)
+	}
+
+	// Given up for good: nothing more is sent
+	time.Sleep(10 * testRetryGap)
+	if n := len(rc.received()); n != 3 {
+		t.Errorf("receiver got %d requests, want 3", n)
+	}
+
+	// Until an operator asks for another series of attempts
+	if err := repo.RetryWebhookDelivery(context.Background(), delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitForDelivery(t, repo, endpointId, models.WebhookDeliveryDead)
+	if n := len(rc.received()); n != 6 {
+		t.Errorf("receiver got %d requests after the retry, want 6", n)
+	}
+}
+
+func TestDispatcherUnreachableEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+	repo, endpointId := setup(t, url)
+	newTestDispatcher(t, repo, 2)
+
+	delivery := waitForDelivery(t, repo, endpointId, models.WebhookDeliveryDead)
+	if delivery.Attempts != 2 || delivery.Log[0].StatusCode != 0 || delivery.Log[0].Error == "" {
+		t.Errorf("delivery = %+v, want two attempts that got no response", delivery)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{retryDelay: 30 * time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxRetryWait},
+		{1000, maxRetryWait},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcherCheck(t *testing.T) {
+	d := newTestDispatcher(t, memrepo.NewRepository(), 3)
+	if err := d.Check(context.Background()); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	d.Close()
+	if err := d.Check(context.Background()); err == nil {
+		t.Error("Check() after Close succeeded")
+	}
+}
+
+func TestNewDispatcherRejects(t *testing.T) {
+	valid := config.WebhooksConfig{MaxAttempts: 3, RetryDelay: 30 * time.Second, PollInterval: 2 * time.Second, Timeout: 10 * time.Second}
+	tests := []struct {
+		name   string
+		change func(*config.WebhooksConfig)
+	}{
+		{"no attempts", func(c *config.WebhooksConfig) { c.MaxAttempts = 0 }},
+		{"no retry delay", func(c *config.WebhooksConfig) { c.RetryDelay = 0 }},
+		{"no poll interval", func(c *config.WebhooksConfig) { c.PollInterval = 0 }},
+		{"negative timeout", func(c *config.WebhooksConfig) { c.Timeout = -time.Second }},
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.change(&cfg)
+		if d, err := NewDispatcher(memrepo.NewRepository(), &cfg); err == nil {
+			d.Close()
+			t.Errorf("%s: NewDispatcher() succeeded", tt.name)
+		}
+	}
+}
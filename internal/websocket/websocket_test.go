@@ -0,0 +1,330 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The example handshake of RFC 6455, section 1.3.
+const (
+	testKey    = "dGhlIHNhbXBsZSBub25jZQ=="
+	testAccept = "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+)
+
+func TestAcceptKey(t *testing.T) {
+	if got := acceptKey(testKey); got != testAccept {
+		t.Errorf("acceptKey() = %q, want %q", got, testAccept)
+	}
+}
+
+func upgradeRequest(origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", testKey)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	return r
+}
+
+func TestUpgradeRejects(t *testing.T) {
+	allowed := []string{"https://tastybites.com"}
+	tests := []struct {
+		name   string
+		change func(r *http.Request)
+		want   int
+	}{
+		{"not a GET", func(r *http.Request) { r.Method = http.MethodPost }, http.StatusUpgradeRequired},
+		{"no upgrade", func(r *http.Request) { r.Header.Del("Upgrade") }, http.StatusUpgradeRequired},
+		{"no connection upgrade", func(r *http.Request) { r.Header.Set("Connection", "keep-alive") }, http.StatusUpgradeRequired},
+		{"old version", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }, http.StatusUpgradeRequired},
+		{"no key", func(r *http.Request) { r.Header.Del("Sec-WebSocket-Key") }, http.StatusBadRequest},
+		{"short key", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "c2hvcnQ=") }, http.StatusBadRequest},
+		{"other site", func(r *http.Request) { r.Header.Set("Origin", "https://evil.example") }, http.StatusForbidden},
+		{"other scheme", func(r *http.Request) { r.Header.Set("Origin", "http://tastybites.com") }, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := upgradeRequest("")
+		tt.change(r)
+		w := httptest.NewRecorder()
+		if conn, err := Upgrade(w, r, allowed); err == nil {
+			conn.Close()
+			t.Errorf("%s: Upgrade() succeeded", tt.name)
+			continue
+		}
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+// serve runs handle on every upgraded connection of a test server.
+func serve(t *testing.T, allowedOrigins []string, handle func(c *Conn)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, allowedOrigins)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		handle(c)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// client is the browser's end of a connection.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dial sends the opening handshake and returns the response, and the
+// connection if it was upgraded.
+func dial(t *testing.T, srv *httptest.Server, origin string) (*client, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := "GET /ws HTTP/1.1\r\nHost: " + srv.Listener.Addr().String() + "\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + testKey + "\r\nSec-WebSocket-Version: 13\r\n"
+	if origin != "" {
+		req += "Origin: " + origin + "\r\n"
+	}
+	if _, err := io.WriteString(conn, req+"\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, resp
+	}
+	return &client{t: t, conn: conn, br: br}, resp
+}
+
+// send writes a masked frame, as clients have to.
+func (c *client) send(fin bool, op byte, payload []byte) {
+	c.t.Helper()
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	first := op
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = binary.BigEndian.AppendUint16(append(frame, 0x80|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 0x80|127), uint64(n))
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// receive reads the raw bytes of a server frame and checks it is final and
+// unmasked.
+func (c *client) receive() (op byte, payload []byte) {
+	c.t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		c.t.Fatalf("failed to read frame: %v", err)
+	}
+	if header[0]&0x80 == 0 || header[1]&0x80 != 0 {
+		c.t.Fatalf("server frame header % x, want final and unmasked", header)
+	}
+	length := uint64(header[1])
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.br, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatalf("failed to read payload: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+// expectClose reads the close frame the server ends with.
+func (c *client) expectClose(code uint16) {
+	c.t.Helper()
+	op, payload := c.receive()
+	if op != OpClose || len(payload) != 2 || binary.BigEndian.Uint16(payload) != code {
+		c.t.Errorf("got frame %d % x, want close %d", op, payload, code)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	allowed := []string{"https://tastybites.com"}
+	srv := serve(t, allowed, func(c *Conn) {})
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusSwitchingProtocols}, // not a browser
+		{"https://tastybites.com", http.StatusSwitchingProtocols},
+		{"https://evil.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		_, resp := dial(t, srv, tt.origin)
+		if resp.StatusCode != tt.want {
+			t.Errorf("origin %q: status %d, want %d", tt.origin, resp.StatusCode, tt.want)
+			continue
+		}
+		if tt.want != http.StatusSwitchingProtocols {
+			continue
+		}
+		if resp.Header.Get("Sec-WebSocket-Accept") != testAccept ||
+			!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+			!strings.EqualFold(resp.Header.Get("Connection"), "upgrade") {
+			t.Errorf("origin %q: handshake headers %v", tt.origin, resp.Header)
+		}
+	}
+
+	anyOrigin := serve(t, []string{"*"}, func(c *Conn) {})
+	if _, resp := dial(t, anyOrigin, "https://evil.example"); resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("with * allowed, status %d", resp.StatusCode)
+	}
+}
+
+func TestFraming(t *testing.T) {
+	// The server greets, then echoes every message until the client closes
+	done := make(chan error, 1)
+	srv := serve(t, nil, func(c *Conn) {
+		if err := c.WriteText([]byte("hi")); err != nil {
+			done <- err
+			return
+		}
+		for {
+			_, msg, err := c.ReadMessage()
+			if err != nil {
+				done <- err
+				return
+			}
+			c.WriteText(msg)
+		}
+	})
+	c, _ := dial(t, srv, "")
+
+	// The greeting is a single unmasked text frame
+	raw := make([]byte, 4)
+	if _, err := io.ReadFull(c.br, raw); err != nil || !bytes.Equal(raw, []byte{0x81, 0x02, 'h', 'i'}) {
+		t.Fatalf("greeting % x, %v", raw, err)
+	}
+
+	c.send(true, OpText, []byte("hello"))
+	if op, msg := c.receive(); op != OpText || string(msg) != "hello" {
+		t.Errorf("echo %d %q", op, msg)
+	}
+
+	// Pings are answered with the same payload, between fragments too
+	c.send(false, OpText, []byte("frag"))
+	c.send(true, OpPing, []byte("are you there"))
+	if op, msg := c.receive(); op != OpPong || string(msg) != "are you there" {
+		t.Errorf("ping answered with %d %q", op, msg)
+	}
+	c.send(false, OpContinuation, []byte("men"))
+	c.send(true, OpContinuation, []byte("ted"))
+	if op, msg := c.receive(); op != OpText || string(msg) != "fragmented" {
+		t.Errorf("reassembled %d %q", op, msg)
+	}
+
+	// Longer payloads use the 16 bit length
+	long := bytes.Repeat([]byte("x"), 300)
+	c.send(true, OpText, long)
+	if op, msg := c.receive(); op != OpText || !bytes.Equal(msg, long) {
+		t.Errorf("echo of 300 bytes: %d, %d bytes", op, len(msg))
+	}
+
+	// The close is answered with the client's code
+	c.send(true, OpClose, binary.BigEndian.AppendUint16(nil, CloseGoingAway))
+	c.expectClose(CloseGoingAway)
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("ReadMessage() after close = %v, want ErrClosed", err)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(c *client)
+		code uint16
+	}{
+		{"unmasked frame", func(c *client) { c.conn.Write([]byte{0x81, 0x01, 'x'}) }, CloseProtocolError},
+		{"reserved bits", func(c *client) { c.conn.Write([]byte{0xC1, 0x80, 0, 0, 0, 0}) }, CloseProtocolError},
+		{"continuation first", func(c *client) { c.send(true, OpContinuation, []byte("x")) }, CloseProtocolError},
+		{"message inside a message", func(c *client) {
+			c.send(false, OpText, []byte("a"))
+			c.send(true, OpText, []byte("b"))
+		}, CloseProtocolError},
+		{"fragmented ping", func(c *client) { c.send(false, OpPing, nil) }, CloseProtocolError},
+		{"unknown opcode", func(c *client) { c.send(true, 0x3, nil) }, CloseProtocolError},
+		{"frame too big", func(c *client) {
+			header := binary.BigEndian.AppendUint64([]byte{0x82, 0x80 | 127}, MaxMessageSize+1)
+			c.conn.Write(header)
+		}, CloseTooBig},
+		{"message too big", func(c *client) {
+			c.send(false, OpBinary, make([]byte, MaxMessageSize))
+			c.send(true, OpContinuation, []byte("x"))
+		}, CloseTooBig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan error, 1)
+			srv := serve(t, nil, func(c *Conn) {
+				_, _, err := c.ReadMessage()
+				done <- err
+			})
+			c, _ := dial(t, srv, "")
+			tt.send(c)
+			c.expectClose(tt.code)
+			if err := <-done; err == nil || errors.Is(err, ErrClosed) {
+				t.Errorf("ReadMessage() = %v, want a protocol error", err)
+			}
+		})
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	done := make(chan error, 1)
+	srv := serve(t, nil, func(c *Conn) {
+		c.Close()
+		done <- c.WriteText([]byte("late"))
+	})
+	c, _ := dial(t, srv, "")
+	c.expectClose(CloseNormal)
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("WriteText() after Close = %v, want ErrClosed", err)
+	}
+}